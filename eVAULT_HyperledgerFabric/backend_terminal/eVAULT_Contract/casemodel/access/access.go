@@ -0,0 +1,306 @@
+// Package access authorizes eVAULT transactions from attributes in the caller's X.509
+// certificate rather than from the MSP ID alone.
+//
+// Every identity must be enrolled with a "role" attribute (lawyer, registrar,
+// stampreporter, benchclerk or judge) issued by its own organization's CA. Judges also
+// carry a "judgeId" attribute and lawyers a "lawyerId" attribute, for example:
+//
+//	fabric-ca-client register --id.attrs 'role=judge:ecert,judgeId=J001:ecert'
+//
+// Members who may initialize a contract and change its routing also carry "admin=true".
+package access
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+
+	"casemodel"
+)
+
+// Certificate attributes read by the access layer
+const (
+	AttrRole     = "role"
+	AttrJudgeID  = "judgeId"
+	AttrLawyerID = "lawyerId"
+	AttrAdmin    = "admin"
+)
+
+// Roles that can be carried in the role attribute
+const (
+	RoleLawyer        = "lawyer"
+	RoleRegistrar     = "registrar"
+	RoleStampReporter = "stampreporter"
+	RoleBenchClerk    = "benchclerk"
+	RoleJudge         = "judge"
+)
+
+// roleOrgs is the only organization whose CA may issue each role
+var roleOrgs = map[string]string{
+	RoleLawyer:        casemodel.OrgLawyers,
+	RoleRegistrar:     casemodel.OrgRegistrars,
+	RoleStampReporter: casemodel.OrgStampReporters,
+	RoleBenchClerk:    casemodel.OrgBenchClerks,
+	RoleJudge:         casemodel.OrgJudges,
+}
+
+// Caller is the identity submitting a transaction
+type Caller struct {
+	Name     string
+	Org      string
+	Role     string
+	JudgeID  string
+	LawyerID string
+}
+
+// AccessError is returned when a caller is not allowed to perform an action
+type AccessError struct {
+	Action string
+	Role   string
+	Org    string
+	Reason string
+}
+
+func (e *AccessError) Error() string {
+	return fmt.Sprintf("access denied for %s (role %q, org %q): %s", e.Action, e.Role, e.Org, e.Reason)
+}
+
+// GetCaller reads the submitting identity's organization and role attributes
+func GetCaller(ctx contractapi.TransactionContextInterface) (*Caller, error) {
+	org, err := casemodel.ClientOrg(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	identity := ctx.GetClientIdentity()
+	role, found, err := identity.GetAttributeValue(AttrRole)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s attribute: %v", AttrRole, err)
+	}
+	if !found || role == "" {
+		return nil, fmt.Errorf("client certificate from %s has no %s attribute", org, AttrRole)
+	}
+	if roleOrgs[role] != org {
+		return nil, fmt.Errorf("role %q cannot be held by a member of %s", role, org)
+	}
+
+	caller := &Caller{Org: org, Role: role}
+	if cert, err := identity.GetX509Certificate(); err == nil && cert != nil {
+		caller.Name = cert.Subject.CommonName
+	}
+	switch role {
+	case RoleJudge:
+		caller.JudgeID, found, err = identity.GetAttributeValue(AttrJudgeID)
+		if err != nil || !found || caller.JudgeID == "" {
+			return nil, fmt.Errorf("judge certificate has no %s attribute", AttrJudgeID)
+		}
+	case RoleLawyer:
+		caller.LawyerID, found, err = identity.GetAttributeValue(AttrLawyerID)
+		if err != nil || !found || caller.LawyerID == "" {
+			return nil, fmt.Errorf("lawyer certificate has no %s attribute", AttrLawyerID)
+		}
+	}
+	return caller, nil
+}
+
+// Policy maps each transaction name of a contract to the roles allowed to call it.
+// Transactions missing from the policy are denied.
+type Policy map[string][]string
+
+// BeforeTransaction checks the caller against the policy. Set it as the contract's
+// BeforeTransaction hook so that every transaction is authorized before it runs.
+func (p Policy) BeforeTransaction(ctx contractapi.TransactionContextInterface) error {
+	function, _ := ctx.GetStub().GetFunctionAndParameters()
+	if i := strings.LastIndex(function, ":"); i >= 0 {
+		function = function[i+1:]
+	}
+
+	roles, ok := p[function]
+	if !ok {
+		return &AccessError{Action: function, Reason: "no access policy is defined for this transaction"}
+	}
+
+	caller, err := GetCaller(ctx)
+	if err != nil {
+		return err
+	}
+	for _, role := range roles {
+		if role == caller.Role {
+			return nil
+		}
+	}
+	return &AccessError{Action: function, Role: caller.Role, Org: caller.Org, Reason: "role is not permitted"}
+}
+
+// RequireAssignedJudge allows only the judge the case is assigned to
+func RequireAssignedJudge(ctx contractapi.TransactionContextInterface, c *casemodel.Case) error {
+	caller, err := GetCaller(ctx)
+	if err != nil {
+		return err
+	}
+	if caller.Role != RoleJudge || caller.JudgeID != c.AssociatedJudge {
+		return &AccessError{Action: "case " + c.ID, Role: caller.Role, Org: caller.Org, Reason: "case is not assigned to this judge"}
+	}
+	return nil
+}
+
+// RequireAdmin allows only callers whose certificate carries admin=true to perform action
+func RequireAdmin(ctx contractapi.TransactionContextInterface, action string) error {
+	caller, err := GetCaller(ctx)
+	if err != nil {
+		return err
+	}
+	admin, found, err := ctx.GetClientIdentity().GetAttributeValue(AttrAdmin)
+	if err != nil {
+		return fmt.Errorf("failed to read %s attribute: %v", AttrAdmin, err)
+	}
+	if !found || admin != "true" {
+		return &AccessError{Action: action, Role: caller.Role, Org: caller.Org, Reason: "caller is not an administrator"}
+	}
+	return nil
+}
+
+// RequireCaseLawyer allows only lawyers listed in the case's AssociatedLawyers
+func RequireCaseLawyer(ctx contractapi.TransactionContextInterface, c *casemodel.Case) error {
+	caller, err := GetCaller(ctx)
+	if err != nil {
+		return err
+	}
+	if caller.Role == RoleLawyer {
+		for _, lawyerID := range c.AssociatedLawyers {
+			if lawyerID == caller.LawyerID {
+				return nil
+			}
+		}
+	}
+	return &AccessError{Action: "case " + c.ID, Role: caller.Role, Org: caller.Org, Reason: "lawyer is not associated with the case"}
+}
+
+// Principal returns how the caller is named in a sealed case's access list: its role and
+// its judge or lawyer ID, or the common name of its certificate for other roles, such as
+// "judge:J001" or "registrar:registrar1"
+func (c *Caller) Principal() string {
+	switch c.Role {
+	case RoleJudge:
+		return RoleJudge + ":" + c.JudgeID
+	case RoleLawyer:
+		return RoleLawyer + ":" + c.LawyerID
+	}
+	return c.Role + ":" + c.Name
+}
+
+// ParsePrincipal checks that an access list entry names a role and an identity
+func ParsePrincipal(principal string) (role string, id string, err error) {
+	role, id, found := strings.Cut(principal, ":")
+	if _, ok := roleOrgs[role]; !ok || !found || id == "" {
+		return "", "", fmt.Errorf("invalid principal %q, want <role>:<id> such as judge:J001", principal)
+	}
+	return role, id, nil
+}
+
+// CanView reports whether the caller may see c in full: c is not sealed, or the caller
+// is on its access list
+func CanView(ctx contractapi.TransactionContextInterface, c *casemodel.Case) (bool, error) {
+	if !c.Sealed {
+		return true, nil
+	}
+	caller, err := GetCaller(ctx)
+	if err != nil {
+		return false, err
+	}
+	principal := caller.Principal()
+	for _, allowed := range c.AccessList {
+		if allowed == principal {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// CanViewStored is CanView for a case as stored in the world state, for counts that
+// depend on what a case contains
+func CanViewStored(ctx contractapi.TransactionContextInterface, value []byte) (bool, error) {
+	c, err := casemodel.DecodeCase(value)
+	if err != nil {
+		return false, err
+	}
+	return CanView(ctx, c)
+}
+
+// RequireCaseView allows only callers who may see c in full
+func RequireCaseView(ctx contractapi.TransactionContextInterface, c *casemodel.Case) error {
+	ok, err := CanView(ctx, c)
+	if err != nil || ok {
+		return err
+	}
+	caller, err := GetCaller(ctx)
+	if err != nil {
+		return err
+	}
+	return &AccessError{Action: "case " + c.ID, Role: caller.Role, Org: caller.Org, Reason: "case is sealed"}
+}
+
+// RedactSealed replaces each sealed case the caller may not see in full with its
+// redacted stub, for list queries. Redaction only filters what a transaction returns:
+// every peer of the channel still holds the full case in its world state, so details
+// that must stay hidden from an organization belong in a private data collection it is
+// not a member of.
+func RedactSealed(ctx contractapi.TransactionContextInterface, cases []*casemodel.Case) ([]*casemodel.Case, error) {
+	for i, c := range cases {
+		ok, err := CanView(ctx, c)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			cases[i] = c.Redacted()
+		}
+	}
+	return cases, nil
+}
+
+// RedactSealedTransfers redacts the case each transfer carries like RedactSealed, for
+// listing an outbox
+func RedactSealedTransfers(ctx contractapi.TransactionContextInterface, transfers []*casemodel.Transfer) ([]*casemodel.Transfer, error) {
+	for _, t := range transfers {
+		if t.Case == nil {
+			continue
+		}
+		ok, err := CanView(ctx, t.Case)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			t.Case = t.Case.Redacted()
+		}
+	}
+	return transfers, nil
+}
+
+// RedactSealedPage redacts the records of a page of cases like RedactSealed
+func RedactSealedPage(ctx contractapi.TransactionContextInterface, page *casemodel.CasePage) (*casemodel.CasePage, error) {
+	records, err := RedactSealed(ctx, page.Records)
+	if err != nil {
+		return nil, err
+	}
+	page.Records = records
+	return page, nil
+}
+
+// RequireStoredCaseView allows only callers who may see the stored copy of case caseID
+// in full, for transactions that reveal a case without returning it. A case that is
+// not stored is left for the transaction to report.
+func RequireStoredCaseView(ctx contractapi.TransactionContextInterface, caseID string) error {
+	caseJSON, err := ctx.GetStub().GetState(caseID)
+	if err != nil {
+		return fmt.Errorf("failed to read case: %v", err)
+	}
+	if caseJSON == nil {
+		return nil
+	}
+	c, err := casemodel.DecodeCase(caseJSON)
+	if err != nil {
+		return err
+	}
+	return RequireCaseView(ctx, c)
+}
@@ -0,0 +1,210 @@
+package access
+
+import (
+	"crypto/x509"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+
+	"casemodel"
+)
+
+// fakeIdentity is a client identity with a fixed MSP ID and attributes
+type fakeIdentity struct {
+	mspID string
+	attrs map[string]string
+}
+
+func (f fakeIdentity) GetID() (string, error)    { return "", nil }
+func (f fakeIdentity) GetMSPID() (string, error) { return f.mspID, nil }
+func (f fakeIdentity) GetAttributeValue(name string) (string, bool, error) {
+	value, found := f.attrs[name]
+	return value, found, nil
+}
+func (f fakeIdentity) AssertAttributeValue(string, string) error      { return nil }
+func (f fakeIdentity) GetX509Certificate() (*x509.Certificate, error) { return nil, nil }
+
+// fakeStub only reports the transaction name being invoked
+type fakeStub struct {
+	shim.ChaincodeStubInterface
+	function string
+}
+
+func (f fakeStub) GetFunctionAndParameters() (string, []string) { return f.function, nil }
+
+func contextFor(function string, mspID string, attrs map[string]string) *contractapi.TransactionContext {
+	ctx := new(contractapi.TransactionContext)
+	ctx.SetStub(fakeStub{function: function})
+	ctx.SetClientIdentity(fakeIdentity{mspID: mspID, attrs: attrs})
+	return ctx
+}
+
+var (
+	judgeJ001  = map[string]string{AttrRole: RoleJudge, AttrJudgeID: "J001"}
+	lawyerL001 = map[string]string{AttrRole: RoleLawyer, AttrLawyerID: "L001"}
+)
+
+func TestGetCaller(t *testing.T) {
+	tests := []struct {
+		name    string
+		mspID   string
+		attrs   map[string]string
+		want    Caller
+		wantErr string
+	}{
+		{"judge", "JudgesOrgMSP", judgeJ001, Caller{Org: casemodel.OrgJudges, Role: RoleJudge, JudgeID: "J001"}, ""},
+		{"lawyer", "LawyersOrg", lawyerL001, Caller{Org: casemodel.OrgLawyers, Role: RoleLawyer, LawyerID: "L001"}, ""},
+		{"registrar", "RegistrarsOrgMSP", map[string]string{AttrRole: RoleRegistrar}, Caller{Org: casemodel.OrgRegistrars, Role: RoleRegistrar}, ""},
+		{"no role", "JudgesOrgMSP", nil, Caller{}, "no role attribute"},
+		{"role from another org", "LawyersOrgMSP", judgeJ001, Caller{}, "cannot be held by a member of LawyersOrg"},
+		{"judge without id", "JudgesOrgMSP", map[string]string{AttrRole: RoleJudge}, Caller{}, "no judgeId attribute"},
+		{"lawyer without id", "LawyersOrgMSP", map[string]string{AttrRole: RoleLawyer}, Caller{}, "no lawyerId attribute"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			caller, err := GetCaller(contextFor("", tt.mspID, tt.attrs))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if *caller != tt.want {
+				t.Errorf("caller = %+v, want %+v", *caller, tt.want)
+			}
+		})
+	}
+}
+
+func TestPolicyBeforeTransaction(t *testing.T) {
+	policy := Policy{
+		"RecordJudgment": {RoleJudge},
+		"StoreCase":      {RoleJudge, RoleBenchClerk},
+	}
+
+	tests := []struct {
+		name     string
+		function string
+		mspID    string
+		attrs    map[string]string
+		allowed  bool
+	}{
+		{"allowed role", "RecordJudgment", "JudgesOrgMSP", judgeJ001, true},
+		{"contract prefixed name", "JudgeContract:RecordJudgment", "JudgesOrgMSP", judgeJ001, true},
+		{"cross channel caller", "StoreCase", "BenchClerksOrgMSP", map[string]string{AttrRole: RoleBenchClerk}, true},
+		{"role not permitted", "RecordJudgment", "BenchClerksOrgMSP", map[string]string{AttrRole: RoleBenchClerk}, false},
+		{"transaction without policy", "PutState", "JudgesOrgMSP", judgeJ001, false},
+		{"missing role attribute", "RecordJudgment", "JudgesOrgMSP", nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := policy.BeforeTransaction(contextFor(tt.function, tt.mspID, tt.attrs))
+			if tt.allowed && err != nil {
+				t.Fatalf("expected access, got %v", err)
+			}
+			if !tt.allowed && err == nil {
+				t.Fatal("expected access to be denied")
+			}
+		})
+	}
+
+	var accessErr *AccessError
+	err := policy.BeforeTransaction(contextFor("RecordJudgment", "LawyersOrgMSP", lawyerL001))
+	if !errors.As(err, &accessErr) || accessErr.Role != RoleLawyer || accessErr.Action != "RecordJudgment" {
+		t.Errorf("expected *AccessError for the lawyer, got %v", err)
+	}
+}
+
+func TestRequireAssignedJudge(t *testing.T) {
+	c := &casemodel.Case{ID: "CASE_001", AssociatedJudge: "J001"}
+
+	if err := RequireAssignedJudge(contextFor("", "JudgesOrgMSP", judgeJ001), c); err != nil {
+		t.Errorf("assigned judge was denied: %v", err)
+	}
+	other := map[string]string{AttrRole: RoleJudge, AttrJudgeID: "J002"}
+	if err := RequireAssignedJudge(contextFor("", "JudgesOrgMSP", other), c); err == nil {
+		t.Error("a judge not assigned to the case was allowed")
+	}
+}
+
+func TestRequireAdmin(t *testing.T) {
+	admin := map[string]string{AttrRole: RoleJudge, AttrJudgeID: "J001", AttrAdmin: "true"}
+	if err := RequireAdmin(contextFor("", "JudgesOrgMSP", admin), "SetRoutingConfig"); err != nil {
+		t.Errorf("administrator was denied: %v", err)
+	}
+	var accessErr *AccessError
+	if err := RequireAdmin(contextFor("", "JudgesOrgMSP", judgeJ001), "SetRoutingConfig"); !errors.As(err, &accessErr) || accessErr.Action != "SetRoutingConfig" {
+		t.Errorf("expected *AccessError for a judge who is not an administrator, got %v", err)
+	}
+}
+
+func TestRequireCaseLawyer(t *testing.T) {
+	c := &casemodel.Case{ID: "CASE_001", AssociatedLawyers: []string{"L001", "L002"}}
+
+	if err := RequireCaseLawyer(contextFor("", "LawyersOrgMSP", lawyerL001), c); err != nil {
+		t.Errorf("associated lawyer was denied: %v", err)
+	}
+	other := map[string]string{AttrRole: RoleLawyer, AttrLawyerID: "L003"}
+	if err := RequireCaseLawyer(contextFor("", "LawyersOrgMSP", other), c); err == nil {
+		t.Error("a lawyer not on the case was allowed")
+	}
+	if err := RequireCaseLawyer(contextFor("", "JudgesOrgMSP", judgeJ001), c); err == nil {
+		t.Error("a judge was allowed to act as a lawyer on the case")
+	}
+}
+
+func TestParsePrincipal(t *testing.T) {
+	if role, id, err := ParsePrincipal("judge:J001"); err != nil || role != RoleJudge || id != "J001" {
+		t.Errorf("ParsePrincipal = %q, %q, %v", role, id, err)
+	}
+	for _, principal := range []string{"J001", "judge:", "clerk:C001", ""} {
+		if _, _, err := ParsePrincipal(principal); err == nil {
+			t.Errorf("ParsePrincipal(%q) was accepted", principal)
+		}
+	}
+}
+
+func TestSealedCaseVisibility(t *testing.T) {
+	open := &casemodel.Case{ID: "CASE_001", Status: casemodel.StatusPendingJudgeReview, Title: "Land dispute"}
+	sealed := &casemodel.Case{ID: "CASE_002", Status: casemodel.StatusPendingJudgeReview, CurrentOrg: casemodel.OrgJudges, Title: "In re minor A", Sealed: true, AccessList: []string{"judge:J001"}}
+	judge := contextFor("", "JudgesOrgMSP", judgeJ001)
+	lawyer := contextFor("", "LawyersOrgMSP", lawyerL001)
+
+	if err := RequireCaseView(judge, sealed); err != nil {
+		t.Errorf("listed judge was denied: %v", err)
+	}
+	if err := RequireCaseView(lawyer, open); err != nil {
+		t.Errorf("open case was denied: %v", err)
+	}
+	var accessErr *AccessError
+	if err := RequireCaseView(lawyer, sealed); !errors.As(err, &accessErr) || accessErr.Reason != "case is sealed" {
+		t.Errorf("unlisted lawyer: %v", err)
+	}
+
+	cases, err := RedactSealed(lawyer, []*casemodel.Case{open, sealed})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cases[0] != open || cases[1].Title != "" || cases[1].ID != "CASE_002" || cases[1].Status != sealed.Status || cases[1].CurrentOrg != casemodel.OrgJudges {
+		t.Errorf("redacted cases = %+v, %+v", cases[0], cases[1])
+	}
+	if cases, _ := RedactSealed(judge, []*casemodel.Case{sealed}); cases[0] != sealed {
+		t.Errorf("listed judge got %+v", cases[0])
+	}
+
+	transfers, err := RedactSealedTransfers(lawyer, []*casemodel.Transfer{{Sequence: 1, CaseID: "CASE_001", Case: open}, {Sequence: 2, CaseID: "CASE_002", Case: sealed}, {Sequence: 3}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if transfers[0].Case != open || transfers[1].Case.Title != "" || transfers[1].Case.ID != "CASE_002" || transfers[2].Case != nil {
+		t.Errorf("redacted transfers = %+v, %+v, %+v", transfers[0], transfers[1], transfers[2])
+	}
+}
@@ -0,0 +1,193 @@
+// Package casemodel holds the case record shared by the lawyer, registrar, stamp reporter,
+// bench clerk and judge chaincodes. Every contract stores and exchanges cases in this
+// layout, so a case keeps all of its fields as it moves from channel to channel.
+//
+// Each chaincode module pulls this package in through a replace directive, so run
+// `go mod vendor` in the chaincode directory before packaging it for a peer.
+package casemodel
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// CurrentSchemaVersion is the Case layout written by this version of the package.
+// Bump it whenever a field is added, renamed or changes meaning.
+const CurrentSchemaVersion = 16
+
+// Case represents a legal case in the system
+type Case struct {
+	SchemaVersion     int           `json:"schemaVersion"`
+	ID                string        `json:"id"`
+	CaseNumber        string        `json:"caseNumber"`
+	Title             string        `json:"title"`
+	Type              string        `json:"type"`
+	Description       string        `json:"description"`
+	Status            string        `json:"status"`
+	CurrentOrg        string        `json:"currentOrg"`
+	UIDParty1         string        `json:"uidParty1"`
+	UIDParty2         string        `json:"uidParty2"`
+	FiledDate         string        `json:"filedDate"`
+	AssociatedLawyers []string      `json:"associatedLawyers"`
+	AssociatedJudge   string        `json:"associatedJudge"`
+	CaseSubject       string        `json:"caseSubject"`
+	ClientName        string        `json:"clientName"`
+	Department        string        `json:"department"`
+	Documents         []Document    `json:"documents"`
+	History           []HistoryItem `json:"history"`
+	CreatedBy         string        `json:"createdBy"`
+	CreatedAt         string        `json:"createdAt"`
+	LastModified      string        `json:"lastModified"`
+	Hearings          []Hearing     `json:"hearings"`
+	Judgment          *Judgment     `json:"judgment,omitempty" metadata:",optional"`  // only set once a judgment is issued
+	Decision          string        `json:"decision"`                                 // For backward compatibility
+	HashChain         []HashLink    `json:"hashChain,omitempty" metadata:",optional"` // one link per transaction that stored the case, added in version 2
+	Proof             *CaseProof    `json:"proof,omitempty" metadata:",optional"`     // signed proposal of the transaction that added the last link
+	// PrivateHashes holds the hash of the case's record in each private data collection,
+	// added in version 4. The fields kept there are left empty on cases written since.
+	PrivateHashes map[string]string `json:"privateHashes,omitempty" metadata:",optional"`
+	// Sealed marks a case heard in camera, added in version 5. Only the identities in
+	// AccessList may see a sealed case in full; everyone else gets Redacted.
+	Sealed     bool     `json:"sealed,omitempty" metadata:",optional"`
+	AccessList []string `json:"accessList,omitempty" metadata:",optional"` // principals such as "judge:J001", see access.Caller.Principal
+	// ClaimValue is the value of the relief claimed in paise, which the court fee is
+	// assessed on, added in version 10. Fees is the last assessment the stamp reporter
+	// stored, see RecordFeePayment; fees are always assessed from the ledger's records.
+	ClaimValue int64          `json:"claimValue,omitempty" metadata:",optional"`
+	Fees       *FeeAssessment `json:"fees,omitempty" metadata:",optional"`
+	// Defects are the corrections the stamp reporter asked for when putting the case on
+	// hold, added in version 11, see RaiseDefect and CureDefect
+	Defects []Defect `json:"defects,omitempty" metadata:",optional"`
+	// ResubmissionCount is how many times the lawyer has filed the case again after the
+	// registrar rejected it, added in version 12. Resubmissions records what changed on
+	// each attempt, see Resubmit.
+	ResubmissionCount int            `json:"resubmissionCount,omitempty" metadata:",optional"`
+	Resubmissions     []Resubmission `json:"resubmissions,omitempty" metadata:",optional"`
+}
+
+// Document represents a case document. Its file is kept off the ledger in a content
+// store, see package contentstore, and anchored by ContentHash. Since version 14 the
+// content hash is kept in the sealedDetails private record and the public case only
+// carries ContentDigest, see Anchor. Since version 7 a replaced document stays on the
+// case, superseded by its next version.
+type Document struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	Type string `json:"type"`
+	// Hash is the reference the client supplied before version 6, moved to private data
+	// since version 4. Stamp reporters also wrote their latest signature hash over it.
+	Hash             string              `json:"hash"`
+	ContentHash      string              `json:"contentHash,omitempty" metadata:",optional"`   // hex SHA-256 of the file, set once at upload, added in version 6
+	ContentDigest    string              `json:"contentDigest,omitempty" metadata:",optional"` // hex SHA-256 of ContentHash, see ContentDigest, added in version 14
+	SignatureHash    string              `json:"signatureHash,omitempty" metadata:",optional"` // latest stamp reporter signature, see DocumentSignature, added in version 6
+	Version          int                 `json:"version,omitempty" metadata:",optional"`       // 1 for the first upload, added in version 7
+	SupersedesID     string              `json:"supersedesId,omitempty" metadata:",optional"`  // ID of the version this one replaced
+	Status           string              `json:"status,omitempty" metadata:",optional"`        // DocumentActive, DocumentSuperseded or DocumentWithdrawn
+	Validated        bool                `json:"validated"`
+	Verdict          string              `json:"verdict,omitempty" metadata:",optional"`         // stamp reporter's verdict on this version, see RecordVerdict, added in version 9
+	ReasonCode       string              `json:"reasonCode,omitempty" metadata:",optional"`      // why the document was not accepted
+	VerdictComments  string              `json:"verdictComments,omitempty" metadata:",optional"` // stamp reporter's comments on the verdict
+	UploadedAt       string              `json:"uploadedAt"`
+	SignatureHistory []DocumentSignature `json:"signatureHistory"`
+}
+
+// DocumentSignature represents a digital signature applied to a document. Since version
+// 8 SignatureHash is a base64 ECDSA or Ed25519 signature over the hash the document
+// anchors, see Document.Anchor, checked against PublicKey when it is recorded, see
+// SignDocument. Before that it was whatever the stamp reporter submitted.
+// VerifySignatures resolves the key from the signer's certificate or registered key
+// rather than trusting PublicKey.
+type DocumentSignature struct {
+	SignatureHash     string `json:"signatureHash"`
+	StampReporterID   string `json:"stampReporterId"`
+	Timestamp         string `json:"timestamp"`
+	Comments          string `json:"comments"`
+	Algorithm         string `json:"algorithm,omitempty" metadata:",optional"`         // SignatureECDSA or SignatureEd25519
+	SignerFingerprint string `json:"signerFingerprint,omitempty" metadata:",optional"` // hex SHA-256 of the signer's certificate or registered key
+	PublicKey         string `json:"publicKey,omitempty" metadata:",optional"`         // PEM public key the signature was checked against
+	Certificate       string `json:"certificate,omitempty" metadata:",optional"`       // PEM certificate of a signer who signed with its key, added in version 13
+	// Signer and Signature are the field names the lawyer contract used before the
+	// shared model; they are kept so older lawyer-side records still round-trip.
+	Signer    string `json:"signer,omitempty" metadata:",optional"`
+	Signature string `json:"signature,omitempty" metadata:",optional"`
+}
+
+// HistoryItem represents a case status change. Since version 3 each entry is chained to
+// the one before it when the case is stored, see Seal.
+type HistoryItem struct {
+	Status       string `json:"status"`
+	Organization string `json:"organization"`
+	Timestamp    string `json:"timestamp"`
+	Comments     string `json:"comments"`
+	PrevHash     string `json:"prevHash,omitempty" metadata:",optional"`    // Hash of the entry before, empty for the first chained entry
+	Hash         string `json:"hash,omitempty" metadata:",optional"`        // hash over the other fields
+	TxID         string `json:"txId,omitempty" metadata:",optional"`        // transaction that chained the entry
+	SubmittedBy  string `json:"submittedBy,omitempty" metadata:",optional"` // client identity ID of that transaction's submitter
+}
+
+// Hearing represents a court hearing
+type Hearing struct {
+	Date     string `json:"date"`
+	Time     string `json:"time"`
+	Location string `json:"location"`
+	Status   string `json:"status"`
+	Notes    string `json:"notes"`
+}
+
+// Judgment represents a judge's final decision on a case
+type Judgment struct {
+	Decision  string `json:"decision"`
+	Reasoning string `json:"reasoning"`
+	Date      string `json:"date"`
+	JudgeID   string `json:"judgeId"`
+	IssuedAt  string `json:"issuedAt"`
+	Status    string `json:"status"`
+}
+
+// DecodeCase unmarshals a case read from the ledger or received from another channel.
+// Cases written with a newer schema are rejected rather than silently losing the
+// fields this version does not know about.
+func DecodeCase(data []byte) (*Case, error) {
+	var c Case
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal case data: %v", err)
+	}
+	if c.SchemaVersion > CurrentSchemaVersion {
+		return nil, fmt.Errorf("case %s uses schema version %d, this chaincode supports up to %d", c.ID, c.SchemaVersion, CurrentSchemaVersion)
+	}
+	c.Normalize()
+	return &c, nil
+}
+
+// Redacted returns the stub of c shown to those who may not see it in full: its ID,
+// status and holding organization only
+func (c *Case) Redacted() *Case {
+	stub := &Case{SchemaVersion: c.SchemaVersion, ID: c.ID, Status: c.Status, CurrentOrg: c.CurrentOrg, Sealed: c.Sealed}
+	stub.Normalize()
+	return stub
+}
+
+// Normalize initializes nil collections and stamps cases written before schema
+// versioning with the current version
+func (c *Case) Normalize() {
+	if c.SchemaVersion == 0 {
+		c.SchemaVersion = CurrentSchemaVersion
+	}
+	if c.Documents == nil {
+		c.Documents = make([]Document, 0)
+	}
+	for i := range c.Documents {
+		if c.Documents[i].SignatureHistory == nil {
+			c.Documents[i].SignatureHistory = make([]DocumentSignature, 0)
+		}
+	}
+	if c.History == nil {
+		c.History = make([]HistoryItem, 0)
+	}
+	if c.AssociatedLawyers == nil {
+		c.AssociatedLawyers = make([]string, 0)
+	}
+	if c.Hearings == nil {
+		c.Hearings = make([]Hearing, 0)
+	}
+}
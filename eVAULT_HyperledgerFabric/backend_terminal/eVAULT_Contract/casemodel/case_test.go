@@ -0,0 +1,272 @@
+package casemodel
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// fullCase returns a case with every field populated, including nested ones
+func fullCase() Case {
+	return Case{
+		SchemaVersion:     CurrentSchemaVersion,
+		ID:                "CASE_001",
+		CaseNumber:        "CIV/2024/001",
+		Title:             "Sharma vs Patel",
+		Type:              "Civil",
+		Description:       "Property dispute",
+		Status:            "DECISION_CONFIRMED",
+		CurrentOrg:        "LawyersOrg",
+		UIDParty1:         "UID-1",
+		UIDParty2:         "UID-2",
+		FiledDate:         "2024-01-02",
+		AssociatedLawyers: []string{"L001", "L002"},
+		AssociatedJudge:   "J001",
+		CaseSubject:       "Land",
+		ClientName:        "A. Sharma",
+		Department:        "Civil",
+		Documents: []Document{{
+			ID:              "DOC_1",
+			Name:            "plaint.pdf",
+			Type:            "PLAINT",
+			Hash:            "abc123",
+			ContentHash:     "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08",
+			ContentDigest:   "954d5a49fd70d9b8bcdb35d252267829957f7ef7fa6c74f88419bdc5e82209f4",
+			SignatureHash:   "sig-1",
+			Version:         2,
+			SupersedesID:    "DOC_0",
+			Status:          DocumentActive,
+			Validated:       true,
+			Verdict:         VerdictAccepted,
+			ReasonCode:      ReasonOther,
+			VerdictComments: "in order",
+			UploadedAt:      "2024-01-02T10:00:00Z",
+			SignatureHistory: []DocumentSignature{{
+				SignatureHash:     "sig-1",
+				StampReporterID:   "SR001",
+				Timestamp:         "2024-01-03T10:00:00Z",
+				Comments:          "stamped",
+				Algorithm:         SignatureECDSA,
+				SignerFingerprint: "cert-fingerprint",
+				PublicKey:         "-----BEGIN PUBLIC KEY-----",
+				Certificate:       "-----BEGIN CERTIFICATE-----",
+				Signer:            "L001",
+				Signature:         "lawyer-sig",
+			}},
+		}},
+		History: []HistoryItem{{
+			Status:       "CREATED",
+			Organization: "LawyersOrg",
+			Timestamp:    "2024-01-02T10:00:00Z",
+			Comments:     "filed",
+			PrevHash:     "entry-0",
+			Hash:         "entry-1",
+			TxID:         "tx1",
+			SubmittedBy:  "x509::CN=lawyer1::CN=ca",
+		}},
+		CreatedBy:    "L001",
+		CreatedAt:    "2024-01-02T10:00:00Z",
+		LastModified: "2024-02-01T10:00:00Z",
+		Hearings: []Hearing{{
+			Date:     "2024-01-20",
+			Time:     "10:30",
+			Location: "Court 4",
+			Status:   "COMPLETED",
+			Notes:    "arguments heard",
+		}},
+		Judgment: &Judgment{
+			Decision:  "Decree granted",
+			Reasoning: "Title proven",
+			Date:      "2024-01-30",
+			JudgeID:   "J001",
+			IssuedAt:  "2024-01-30T12:00:00Z",
+			Status:    "FINAL",
+		},
+		Decision: "Decree granted",
+		HashChain: []HashLink{{
+			CaseHash: "case-hash",
+			Previous: "link-0",
+			Hash:     "link-1",
+			TxID:     "tx1",
+			Channel:  "benchclerk-lawyer-channel",
+			Org:      "BenchClerksOrg",
+		}},
+		Proof:         &CaseProof{TxID: "tx1", Proposal: "cHJvcG9zYWw=", HistoryHead: "h2", Seal: "c2VhbA=="},
+		PrivateHashes: map[string]string{CollectionSealedDetails: "sealed-hash"},
+		Sealed:        true,
+		AccessList:    []string{"judge:J001", "lawyer:L001"},
+		ClaimValue:    5000000,
+		Fees: &FeeAssessment{
+			CaseID:     "CASE_001",
+			CaseType:   "Civil",
+			ClaimValue: 5000000,
+			CourtFee:   50000,
+			StampDuty:  5000,
+			Total:      55000,
+			Paid:       20000,
+			Balance:    35000,
+			Payments:   []FeePayment{{ReceiptRef: "GRN001", Amount: 20000, RecordedAt: "2024-05-15T10:00:00Z", RecordedBy: "stampreporter1"}},
+		},
+		Defects: []Defect{{
+			ID:             "DEF_1",
+			DocumentID:     "DOC_1",
+			ReasonCode:     ReasonUnsigned,
+			Description:    "Vakalatnama not signed by the client",
+			Deadline:       "2024-05-22T10:00:00Z",
+			Status:         DefectCured,
+			RaisedAt:       "2024-05-15T10:00:00Z",
+			CuredAt:        "2024-05-23T10:00:00Z",
+			CureDocumentID: "DOC_1_V2",
+			CureComments:   "Signed copy attached",
+			Late:           true,
+		}},
+		ResubmissionCount: 1,
+		Resubmissions: []Resubmission{{
+			Attempt:         1,
+			RejectedAt:      "2024-05-14T10:00:00Z",
+			RejectionReason: "Cause of action not stated",
+			ResubmittedAt:   "2024-05-15T09:00:00Z",
+			Changes:         []FieldChange{{Field: "description", Before: `"Land dispute"`, After: `"Land dispute over survey no. 42"`}},
+		}},
+	}
+}
+
+// assertPopulated fails if any field reachable from v holds its zero value, so that a
+// field added to the model without being covered here makes the round-trip test fail
+func assertPopulated(t *testing.T, path string, v reflect.Value) {
+	t.Helper()
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			t.Errorf("%s is nil in the test fixture", path)
+			return
+		}
+		assertPopulated(t, path, v.Elem())
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			assertPopulated(t, path+"."+v.Type().Field(i).Name, v.Field(i))
+		}
+	case reflect.Slice:
+		if v.Len() == 0 {
+			t.Errorf("%s is empty in the test fixture", path)
+		}
+		for i := 0; i < v.Len(); i++ {
+			assertPopulated(t, path+"[]", v.Index(i))
+		}
+	default:
+		if v.IsZero() {
+			t.Errorf("%s is zero in the test fixture", path)
+		}
+	}
+}
+
+func TestFixtureCoversEveryField(t *testing.T) {
+	assertPopulated(t, "Case", reflect.ValueOf(fullCase()))
+}
+
+func TestCaseRoundTripAcrossChannels(t *testing.T) {
+	// Hops a case takes between the channels configured in MICROFAB.txt
+	hops := []string{
+		"lawyer-registrar-channel",
+		"registrar-stampreporter-channel",
+		"stampreporter-lawyer-channel",
+		"stampreporter-benchclerk-channel",
+		"benchclerk-judge-channel",
+		"benchclerk-lawyer-channel",
+	}
+
+	original := fullCase()
+	payload, err := json.Marshal(original)
+	if err != nil {
+		t.Fatalf("failed to marshal case: %v", err)
+	}
+	var originalFields map[string]interface{}
+	if err := json.Unmarshal(payload, &originalFields); err != nil {
+		t.Fatalf("failed to unmarshal case into map: %v", err)
+	}
+
+	for _, hop := range hops {
+		received, err := DecodeCase(payload)
+		if err != nil {
+			t.Fatalf("%s: failed to decode case: %v", hop, err)
+		}
+		if !reflect.DeepEqual(*received, original) {
+			t.Fatalf("%s: case changed in transit\n got: %+v\nwant: %+v", hop, *received, original)
+		}
+		payload, err = json.Marshal(received)
+		if err != nil {
+			t.Fatalf("%s: failed to re-marshal case: %v", hop, err)
+		}
+		var fields map[string]interface{}
+		if err := json.Unmarshal(payload, &fields); err != nil {
+			t.Fatalf("%s: failed to unmarshal case into map: %v", hop, err)
+		}
+		if !reflect.DeepEqual(fields, originalFields) {
+			t.Fatalf("%s: JSON fields differ after hop\n got: %v\nwant: %v", hop, fields, originalFields)
+		}
+	}
+}
+
+func TestDecodeCaseKeepsLegacySignatureLayouts(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload string
+		want    DocumentSignature
+	}{
+		{
+			name:    "lawyer layout",
+			payload: `{"id":"C1","documents":[{"id":"D1","signatureHistory":[{"signer":"L001","signature":"s1","timestamp":"t1"}]}]}`,
+			want:    DocumentSignature{Signer: "L001", Signature: "s1", Timestamp: "t1"},
+		},
+		{
+			name:    "stamp reporter layout",
+			payload: `{"id":"C1","documents":[{"id":"D1","signatureHistory":[{"signatureHash":"h1","stampReporterId":"SR1","timestamp":"t1","comments":"ok"}]}]}`,
+			want:    DocumentSignature{SignatureHash: "h1", StampReporterID: "SR1", Timestamp: "t1", Comments: "ok"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := DecodeCase([]byte(tt.payload))
+			if err != nil {
+				t.Fatalf("failed to decode case: %v", err)
+			}
+			got := c.Documents[0].SignatureHistory[0]
+			if got != tt.want {
+				t.Fatalf("signature = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecodeCaseVersioning(t *testing.T) {
+	legacy, err := DecodeCase([]byte(`{"id":"C1","status":"CREATED"}`))
+	if err != nil {
+		t.Fatalf("failed to decode legacy case: %v", err)
+	}
+	if legacy.SchemaVersion != CurrentSchemaVersion {
+		t.Errorf("legacy case schema version = %d, want %d", legacy.SchemaVersion, CurrentSchemaVersion)
+	}
+	if legacy.Documents == nil || legacy.History == nil || legacy.AssociatedLawyers == nil || legacy.Hearings == nil {
+		t.Errorf("legacy case collections were not initialized: %+v", legacy)
+	}
+
+	_, err = DecodeCase([]byte(`{"id":"C1","schemaVersion":99}`))
+	if err == nil || !strings.Contains(err.Error(), "schema version 99") {
+		t.Fatalf("expected newer schema to be rejected, got %v", err)
+	}
+
+	if _, err := DecodeCase([]byte(`{"id":`)); err == nil {
+		t.Fatal("expected malformed JSON to be rejected")
+	}
+}
+
+func TestRedactedKeepsOnlyIdentifyingFields(t *testing.T) {
+	c := fullCase()
+	want := &Case{SchemaVersion: CurrentSchemaVersion, ID: c.ID, Status: c.Status, CurrentOrg: c.CurrentOrg, Sealed: true}
+	want.Normalize()
+	if got := c.Redacted(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Redacted() = %+v\nwant %+v", got, want)
+	}
+}
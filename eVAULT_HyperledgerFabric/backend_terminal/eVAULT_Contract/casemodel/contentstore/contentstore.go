@@ -0,0 +1,138 @@
+// Package contentstore keeps the files behind case documents off the ledger. Files are
+// addressed by the hex SHA-256 of their content, the value a Document is filed with as
+// its ContentHash, so a backend uploads a file, files the hash with the document, and
+// later fetches the file by it. The hash is kept in the case's sealed details; the
+// public case only carries its digest, see casemodel.ContentDigest.
+//
+// The chaincodes never use this package; it is for the clients that call them.
+package contentstore
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"path/filepath"
+
+	"casemodel"
+)
+
+// ErrNotFound is returned for content the store does not hold
+var ErrNotFound = errors.New("content not found")
+
+// Store is a content-addressed file store
+type Store interface {
+	// Put stores the content read from r and returns its hash. Storing content that is
+	// already held is not an error.
+	Put(r io.Reader) (string, error)
+	// Get returns the content with the given hash. Reading it fails if the stored bytes no
+	// longer match the hash.
+	Get(hash string) (io.ReadCloser, error)
+	// Has reports whether the store holds content with the given hash
+	Has(hash string) (bool, error)
+}
+
+// FileStore is a Store in a local directory. Each file is kept at
+// <root>/<first two hex digits>/<hash>.
+type FileStore struct {
+	root string
+}
+
+// NewFileStore returns a store in root, creating the directory if needed
+func NewFileStore(root string) (*FileStore, error) {
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create content store: %v", err)
+	}
+	return &FileStore{root: root}, nil
+}
+
+func (s *FileStore) path(hash string) (string, error) {
+	if !casemodel.ValidContentHash(hash) {
+		return "", fmt.Errorf("%q is not a hex SHA-256", hash)
+	}
+	return filepath.Join(s.root, hash[:2], hash), nil
+}
+
+// Put writes the content to a temporary file while hashing it, then moves it into place
+func (s *FileStore) Put(r io.Reader) (string, error) {
+	tmp, err := os.CreateTemp(s.root, "upload-*")
+	if err != nil {
+		return "", fmt.Errorf("failed to create upload file: %v", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	h := sha256.New()
+	_, err = io.Copy(io.MultiWriter(tmp, h), r)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to write upload: %v", err)
+	}
+
+	hash := hex.EncodeToString(h.Sum(nil))
+	path, err := s.path(hash)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return "", fmt.Errorf("failed to create content directory: %v", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return "", fmt.Errorf("failed to store content %s: %v", hash, err)
+	}
+	return hash, nil
+}
+
+// Get opens the file stored under hash
+func (s *FileStore) Get(hash string) (io.ReadCloser, error) {
+	path, err := s.path(hash)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, hash)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open content %s: %v", hash, err)
+	}
+	return &verifyingReader{file: f, hash: hash, h: sha256.New()}, nil
+}
+
+// Has reports whether a file is stored under hash
+func (s *FileStore) Has(hash string) (bool, error) {
+	path, err := s.path(hash)
+	if err != nil {
+		return false, err
+	}
+	_, err = os.Stat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// verifyingReader hashes a stored file as it is read and fails at the end if the file
+// was changed on disk
+type verifyingReader struct {
+	file *os.File
+	hash string
+	h    hash.Hash
+}
+
+func (r *verifyingReader) Read(p []byte) (int, error) {
+	n, err := r.file.Read(p)
+	r.h.Write(p[:n])
+	if err == io.EOF && hex.EncodeToString(r.h.Sum(nil)) != r.hash {
+		return n, fmt.Errorf("content %s does not match its hash", r.hash)
+	}
+	return n, err
+}
+
+func (r *verifyingReader) Close() error {
+	return r.file.Close()
+}
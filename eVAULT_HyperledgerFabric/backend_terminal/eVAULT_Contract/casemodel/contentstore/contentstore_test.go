@@ -0,0 +1,79 @@
+package contentstore
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"casemodel"
+)
+
+func TestFileStore(t *testing.T) {
+	root := filepath.Join(t.TempDir(), "content")
+	store, err := NewFileStore(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	plaint := "%PDF-1.4 plaint"
+
+	hash, err := store.Put(strings.NewReader(plaint))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hash != casemodel.ContentHash([]byte(plaint)) {
+		t.Errorf("hash = %s", hash)
+	}
+	if again, err := store.Put(strings.NewReader(plaint)); err != nil || again != hash {
+		t.Errorf("storing the same content again = %s, %v", again, err)
+	}
+	if ok, err := store.Has(hash); !ok || err != nil {
+		t.Errorf("Has = %v, %v", ok, err)
+	}
+
+	r, err := store.Get(hash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	content, err := io.ReadAll(r)
+	r.Close()
+	if err != nil || string(content) != plaint {
+		t.Errorf("Get = %q, %v", content, err)
+	}
+
+	missing := casemodel.ContentHash([]byte("missing"))
+	if ok, err := store.Has(missing); ok || err != nil {
+		t.Errorf("Has missing = %v, %v", ok, err)
+	}
+	if _, err := store.Get(missing); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get missing: %v", err)
+	}
+	if _, err := store.Get("../../etc/passwd"); err == nil || !strings.Contains(err.Error(), "is not a hex SHA-256") {
+		t.Errorf("Get outside the store: %v", err)
+	}
+}
+
+func TestFileStoreDetectsChangedContent(t *testing.T) {
+	store, err := NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	hash, err := store.Put(strings.NewReader("%PDF-1.4 plaint"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(store.root, hash[:2], hash), []byte("%PDF-1.4 altered"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := store.Get(hash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	if _, err := io.ReadAll(r); err == nil || !strings.Contains(err.Error(), "does not match its hash") {
+		t.Errorf("reading changed content: %v", err)
+	}
+}
@@ -0,0 +1,282 @@
+package casemodel
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Defect statuses
+const (
+	DefectOpen  = "OPEN"
+	DefectCured = "CURED"
+)
+
+// DefaultCurePeriod is how long the lawyer has to cure a defect the stamp reporter did
+// not give a deadline for
+const DefaultCurePeriod = 7 * 24 * time.Hour
+
+// Defect is a correction the stamp reporter asks for before an on-hold case can be
+// validated. It is raised on a document that needs correction and cured when the lawyer
+// files a corrected version of that document, see CureDefect.
+type Defect struct {
+	ID             string `json:"id"`         // DEF_1, DEF_2, ... in the order raised on the case
+	DocumentID     string `json:"documentId"` // document the defect was found in
+	ReasonCode     string `json:"reasonCode"`
+	Description    string `json:"description"`
+	Deadline       string `json:"deadline"` // RFC 3339 time the defect must be cured by
+	Status         string `json:"status"`   // DefectOpen or DefectCured
+	RaisedAt       string `json:"raisedAt"`
+	CuredAt        string `json:"curedAt,omitempty" metadata:",optional"`
+	CureDocumentID string `json:"cureDocumentId,omitempty" metadata:",optional"` // corrected version the lawyer filed
+	CureComments   string `json:"cureComments,omitempty" metadata:",optional"`
+	Late           bool   `json:"late,omitempty" metadata:",optional"` // cured after the deadline
+}
+
+// Defect returns the defect of c with the given ID, or nil
+func (c *Case) Defect(id string) *Defect {
+	for i := range c.Defects {
+		if c.Defects[i].ID == id {
+			return &c.Defects[i]
+		}
+	}
+	return nil
+}
+
+// RaiseDefect records a defect on c for doc, which the stamp reporter found needs
+// correction. The defect is due by deadline, or DefaultCurePeriod after raisedAt when
+// deadline is empty.
+func RaiseDefect(c *Case, doc *Document, deadline string, raisedAt string) (*Defect, error) {
+	if doc.Verdict != VerdictNeedsCorrection {
+		return nil, fmt.Errorf("document %s is %s, defects are only raised on documents that need correction", doc.ID, doc.Verdict)
+	}
+	raised, err := time.Parse(time.RFC3339, raisedAt)
+	if err != nil {
+		return nil, fmt.Errorf("invalid defect time %q: %v", raisedAt, err)
+	}
+	if deadline == "" {
+		deadline = raised.Add(DefaultCurePeriod).Format(time.RFC3339)
+	} else if due, err := time.Parse(time.RFC3339, deadline); err != nil {
+		return nil, fmt.Errorf("deadline for document %s must be an RFC 3339 time, got %q", doc.ID, deadline)
+	} else if !due.After(raised) {
+		return nil, fmt.Errorf("deadline for document %s must be after %s", doc.ID, raisedAt)
+	}
+
+	description := doc.VerdictComments
+	if description == "" {
+		description = doc.ReasonCode
+	}
+	c.Defects = append(c.Defects, Defect{
+		ID:          fmt.Sprintf("DEF_%d", len(c.Defects)+1),
+		DocumentID:  doc.ID,
+		ReasonCode:  doc.ReasonCode,
+		Description: description,
+		Deadline:    deadline,
+		Status:      DefectOpen,
+		RaisedAt:    raisedAt,
+	})
+	return &c.Defects[len(c.Defects)-1], nil
+}
+
+// CureDefect cures open defect id of c by filing doc as the next version of the document
+// the defect was raised on. A defect cured after its deadline is marked late.
+func CureDefect(c *Case, id string, doc Document, comments string, curedAt string) error {
+	defect := c.Defect(id)
+	if defect == nil {
+		return fmt.Errorf("defect %s not found in case %s", id, c.ID)
+	}
+	if defect.Status != DefectOpen {
+		return fmt.Errorf("defect %s of case %s is %s", id, c.ID, defect.Status)
+	}
+	if _, err := time.Parse(time.RFC3339, curedAt); err != nil {
+		return fmt.Errorf("invalid cure time %q: %v", curedAt, err)
+	}
+	if err := ReplaceDocument(c, defect.DocumentID, doc, curedAt); err != nil {
+		return err
+	}
+	return markCured(defect, doc.ID, comments, curedAt)
+}
+
+// CureReplacedDefects cures each open defect of c whose document the lawyer replaced with
+// ReplaceDocument by the latest version of that document. It returns the IDs of the
+// defects cured.
+func CureReplacedDefects(c *Case, curedAt string) ([]string, error) {
+	cured := make([]string, 0)
+	for i := range c.Defects {
+		defect := &c.Defects[i]
+		if defect.Status != DefectOpen {
+			continue
+		}
+		latest := latestVersion(c, defect.DocumentID)
+		if latest == nil || latest.ID == defect.DocumentID {
+			continue
+		}
+		if err := markCured(defect, latest.ID, "", curedAt); err != nil {
+			return nil, err
+		}
+		cured = append(cured, defect.ID)
+	}
+	return cured, nil
+}
+
+// markCured marks defect cured by document docID, and late if curedAt is after its
+// deadline
+func markCured(defect *Defect, docID string, comments string, curedAt string) error {
+	cured, err := time.Parse(time.RFC3339, curedAt)
+	if err != nil {
+		return fmt.Errorf("invalid cure time %q: %v", curedAt, err)
+	}
+	defect.Status = DefectCured
+	defect.CuredAt = curedAt
+	defect.CureDocumentID = docID
+	defect.CureComments = comments
+	if due, err := time.Parse(time.RFC3339, defect.Deadline); err == nil {
+		defect.Late = cured.After(due)
+	}
+	return nil
+}
+
+// ApplyCures rebuilds the case a lawyer returns with its defects cured from stored, the
+// copy the stamp reporter put on hold. Each open defect of stored must be cured in
+// received, and the corrected version of its document is filed on the rebuilt case as
+// CureDefect filed it on the lawyer's ledger. The status, history, private data hashes
+// and proof are taken from received, which must only append to the history; any other
+// change, such as a payment, verdict or claim value, is rejected. It returns the rebuilt
+// case and the IDs of the defects cured.
+func ApplyCures(stored *Case, received *Case) (*Case, []string, error) {
+	rebuilt, err := copyCase(stored)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	cured := make([]string, 0)
+	for _, defect := range OpenDefects(stored) {
+		cure := received.Defect(defect.ID)
+		if cure == nil || cure.Status != DefectCured {
+			return nil, nil, fmt.Errorf("defect %s of case %s is not cured", defect.ID, stored.ID)
+		}
+		doc := received.Document(cure.CureDocumentID)
+		if doc == nil {
+			return nil, nil, fmt.Errorf("corrected document %s of defect %s not found in case %s", cure.CureDocumentID, defect.ID, stored.ID)
+		}
+		if err := CureDefect(rebuilt, defect.ID, *doc, cure.CureComments, cure.CuredAt); err != nil {
+			return nil, nil, err
+		}
+		cured = append(cured, defect.ID)
+	}
+
+	if err := takeReturned(rebuilt, stored, received, "only defect cures may change an on-hold case"); err != nil {
+		return nil, nil, err
+	}
+	return rebuilt, cured, nil
+}
+
+// ApplyReplacements rebuilds the case a lawyer resubmits after a rejection from stored,
+// the copy the stamp reporter rejected. Each document of stored that was rejected or
+// sent back for correction must be replaced or withdrawn in received. The new versions
+// are filed on the rebuilt case in the order received has them, and an open defect on a
+// replaced document is cured as CureReplacedDefects cured it on the lawyer's ledger. The
+// rest is taken from received as in ApplyCures. It returns the rebuilt case and the IDs
+// of the documents replaced or withdrawn.
+func ApplyReplacements(stored *Case, received *Case) (*Case, []string, error) {
+	rebuilt, err := copyCase(stored)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rejected := RejectedDocuments(stored)
+	replaceable := make(map[string]bool)
+	for _, doc := range rejected {
+		replaceable[doc.ID] = true
+	}
+	for _, doc := range received.Documents {
+		if stored.Document(doc.ID) != nil {
+			continue
+		}
+		if !replaceable[doc.SupersedesID] {
+			return nil, nil, fmt.Errorf("document %s of case %s does not replace a rejected document", doc.ID, stored.ID)
+		}
+		if err := ReplaceDocument(rebuilt, doc.SupersedesID, doc, doc.UploadedAt); err != nil {
+			return nil, nil, err
+		}
+		// A new version may itself be replaced before the case is resubmitted
+		replaceable[doc.ID] = true
+	}
+
+	returned := make([]string, 0, len(rejected))
+	for _, doc := range rejected {
+		if own := received.Document(doc.ID); own != nil && own.Status == DocumentWithdrawn && rebuilt.Document(doc.ID).Active() {
+			if err := WithdrawDocument(rebuilt, doc.ID); err != nil {
+				return nil, nil, err
+			}
+		}
+		if rebuilt.Document(doc.ID).Active() {
+			return nil, nil, fmt.Errorf("document %s of case %s is %s and was not replaced", doc.ID, stored.ID, doc.Verdict)
+		}
+		returned = append(returned, doc.ID)
+	}
+	for _, defect := range OpenDefects(stored) {
+		latest := latestVersion(rebuilt, defect.DocumentID)
+		if latest == nil || latest.ID == defect.DocumentID {
+			continue
+		}
+		cure := received.Defect(defect.ID)
+		if cure == nil || cure.Status != DefectCured {
+			return nil, nil, fmt.Errorf("defect %s of case %s is not cured", defect.ID, stored.ID)
+		}
+		if err := markCured(rebuilt.Defect(defect.ID), latest.ID, cure.CureComments, cure.CuredAt); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if err := takeReturned(rebuilt, stored, received, "only replacing the rejected documents may change a rejected case"); err != nil {
+		return nil, nil, err
+	}
+	return rebuilt, returned, nil
+}
+
+// takeReturned takes the status, history, private data hashes and proof of the case a
+// lawyer returns from received into rebuilt. received must only append to the history of
+// stored and match rebuilt otherwise; allowed says what may change when it does not.
+func takeReturned(rebuilt *Case, stored *Case, received *Case, allowed string) error {
+	if len(received.History) < len(stored.History) {
+		return fmt.Errorf("case %s is missing history entries", stored.ID)
+	}
+	for i, item := range stored.History {
+		if received.History[i] != item {
+			return fmt.Errorf("history entry %d of case %s was changed", i, stored.ID)
+		}
+	}
+	rebuilt.Status, rebuilt.CurrentOrg, rebuilt.LastModified = received.Status, received.CurrentOrg, received.LastModified
+	rebuilt.History, rebuilt.PrivateHashes = received.History, received.PrivateHashes
+	rebuilt.HashChain, rebuilt.Proof = received.HashChain, received.Proof
+
+	changes, err := DiffCases(rebuilt, received, nil)
+	if err != nil {
+		return err
+	}
+	if len(changes) > 0 {
+		return fmt.Errorf("case %s changes %s, %s", stored.ID, changes[0].Field, allowed)
+	}
+	return nil
+}
+
+// copyCase returns a deep copy of c
+func copyCase(c *Case) (*Case, error) {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal case: %v", err)
+	}
+	return DecodeCase(data)
+}
+
+// OpenDefects returns the defects of c the lawyer has not cured yet
+func OpenDefects(c *Case) []Defect {
+	defects := make([]Defect, 0)
+	for _, defect := range c.Defects {
+		if defect.Status == DefectOpen {
+			defects = append(defects, defect)
+		}
+	}
+	return defects
+}
@@ -0,0 +1,269 @@
+package casemodel
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestRaiseDefect(t *testing.T) {
+	c := &Case{ID: "CASE_001", Documents: []Document{
+		{ID: "DOC_1", Verdict: VerdictNeedsCorrection, ReasonCode: ReasonUnsigned, VerdictComments: "Vakalatnama not signed"},
+		{ID: "DOC_2", Verdict: VerdictNeedsCorrection, ReasonCode: ReasonIllegible},
+		{ID: "DOC_3", Verdict: VerdictAccepted},
+	}}
+
+	first, err := RaiseDefect(c, &c.Documents[0], "", "2024-05-15T10:00:00Z")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *first != (Defect{ID: "DEF_1", DocumentID: "DOC_1", ReasonCode: ReasonUnsigned, Description: "Vakalatnama not signed", Deadline: "2024-05-22T10:00:00Z", Status: DefectOpen, RaisedAt: "2024-05-15T10:00:00Z"}) {
+		t.Errorf("defect = %+v", *first)
+	}
+	second, err := RaiseDefect(c, &c.Documents[1], "2024-05-17T10:00:00Z", "2024-05-15T10:00:00Z")
+	if err != nil || second.ID != "DEF_2" || second.Description != ReasonIllegible || second.Deadline != "2024-05-17T10:00:00Z" {
+		t.Errorf("defect = %+v, %v", second, err)
+	}
+
+	for _, tt := range []struct {
+		name     string
+		doc      *Document
+		deadline string
+		err      string
+	}{
+		{"accepted document", &c.Documents[2], "", "document DOC_3 is ACCEPTED, defects are only raised on documents that need correction"},
+		{"deadline not a time", &c.Documents[0], "next week", `deadline for document DOC_1 must be an RFC 3339 time, got "next week"`},
+		{"deadline passed", &c.Documents[0], "2024-05-15T10:00:00Z", "deadline for document DOC_1 must be after 2024-05-15T10:00:00Z"},
+	} {
+		if _, err := RaiseDefect(c, tt.doc, tt.deadline, "2024-05-15T10:00:00Z"); err == nil || err.Error() != tt.err {
+			t.Errorf("%s: err = %v, want %q", tt.name, err, tt.err)
+		}
+	}
+	if len(c.Defects) != 2 {
+		t.Errorf("defects = %+v", c.Defects)
+	}
+}
+
+func TestCureDefect(t *testing.T) {
+	c := &Case{
+		ID:        "CASE_001",
+		Documents: []Document{{ID: "DOC_1", Version: 1, Status: DocumentActive}, {ID: "DOC_2", Version: 1, Status: DocumentActive}},
+		Defects: []Defect{
+			{ID: "DEF_1", DocumentID: "DOC_1", Deadline: "2024-05-22T10:00:00Z", Status: DefectOpen},
+			{ID: "DEF_2", DocumentID: "DOC_2", Deadline: "2024-05-17T10:00:00Z", Status: DefectOpen},
+		},
+	}
+	corrected := Document{ID: "DOC_1_V2", ContentHash: ContentHash([]byte("%PDF-1.4 signed vakalatnama"))}
+
+	if err := CureDefect(c, "DEF_1", corrected, "Signed copy attached", "2024-05-20T10:00:00Z"); err != nil {
+		t.Fatal(err)
+	}
+	defect := c.Defect("DEF_1")
+	if defect.Status != DefectCured || defect.CureDocumentID != "DOC_1_V2" || defect.CuredAt != "2024-05-20T10:00:00Z" || defect.CureComments != "Signed copy attached" || defect.Late {
+		t.Errorf("defect = %+v", defect)
+	}
+	if doc := c.Document("DOC_1_V2"); doc == nil || doc.SupersedesID != "DOC_1" || c.Document("DOC_1").Status != DocumentSuperseded {
+		t.Errorf("documents = %+v", c.Documents)
+	}
+	if open := OpenDefects(c); len(open) != 1 || open[0].ID != "DEF_2" {
+		t.Errorf("open defects = %+v", open)
+	}
+
+	late := Document{ID: "DOC_2_V2", ContentHash: ContentHash([]byte("%PDF-1.4 legible affidavit"))}
+	if err := CureDefect(c, "DEF_2", late, "", "2024-05-20T10:00:00Z"); err != nil || !c.Defect("DEF_2").Late {
+		t.Errorf("late cure = %+v, %v", c.Defect("DEF_2"), err)
+	}
+
+	for _, tt := range []struct {
+		id, err string
+	}{
+		{"DEF_9", "defect DEF_9 not found in case CASE_001"},
+		{"DEF_1", "defect DEF_1 of case CASE_001 is CURED"},
+	} {
+		if err := CureDefect(c, tt.id, corrected, "", "2024-05-20T10:00:00Z"); err == nil || err.Error() != tt.err {
+			t.Errorf("CureDefect(%s) = %v, want %q", tt.id, err, tt.err)
+		}
+	}
+}
+
+func TestApplyCures(t *testing.T) {
+	stored := &Case{
+		ID:         "CASE_001",
+		Status:     StatusForwardedToLawyerOnHold,
+		CurrentOrg: OrgLawyers,
+		ClaimValue: 5000000,
+		Fees:       &FeeAssessment{CaseID: "CASE_001", Total: 1000, Payments: []FeePayment{}},
+		Documents: []Document{
+			{ID: "DOC_1", Version: 1, Status: DocumentActive, Verdict: VerdictAccepted},
+			{ID: "DOC_2", Version: 1, Status: DocumentActive, Verdict: VerdictNeedsCorrection},
+		},
+		Defects: []Defect{{ID: "DEF_1", DocumentID: "DOC_2", Deadline: "2024-05-22T10:00:00Z", Status: DefectOpen}},
+		History: []HistoryItem{{Status: StatusForwardedToLawyerOnHold, Organization: "StampReportersOrg", Timestamp: "2024-05-15T10:00:00Z"}},
+	}
+	// returned is stored as the lawyer returns it with DEF_1 cured
+	returned := func() *Case {
+		data, err := json.Marshal(stored)
+		if err != nil {
+			t.Fatal(err)
+		}
+		c, err := DecodeCase(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		corrected := Document{ID: "DOC_2_V2", ContentHash: ContentHash([]byte("%PDF-1.4 legible affidavit"))}
+		if err := CureDefect(c, "DEF_1", corrected, "Clean scan", "2024-05-20T10:00:00Z"); err != nil {
+			t.Fatal(err)
+		}
+		c.Status, c.CurrentOrg = StatusPendingStampReporterReview, OrgStampReporters
+		c.History = append(c.History, HistoryItem{Status: "DEFECTS_CURED", Organization: "LawyersOrg", Timestamp: "2024-05-20T10:00:00Z"})
+		return c
+	}
+
+	rebuilt, cured, err := ApplyCures(stored, returned())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cured) != 1 || cured[0] != "DEF_1" || rebuilt.Status != StatusPendingStampReporterReview || rebuilt.Document("DOC_2_V2") == nil || len(rebuilt.History) != 2 {
+		t.Errorf("rebuilt = %+v, cured %v", rebuilt, cured)
+	}
+	if stored.Document("DOC_2").Status != DocumentActive || stored.Defect("DEF_1").Status != DefectOpen {
+		t.Errorf("stored case was changed: %+v", stored)
+	}
+
+	for _, tt := range []struct {
+		name   string
+		change func(c *Case)
+		err    string
+	}{
+		{"defect open", func(c *Case) { c.Defects[0].Status = DefectOpen }, "defect DEF_1 of case CASE_001 is not cured"},
+		{"corrected document missing", func(c *Case) { c.Documents = c.Documents[:2] }, "corrected document DOC_2_V2 of defect DEF_1 not found in case CASE_001"},
+		{"cure not late", func(c *Case) { c.Defects[0].CuredAt = "2024-05-25T10:00:00Z" }, "case CASE_001 changes defects[0].late, only defect cures may change an on-hold case"},
+		{"claim value", func(c *Case) { c.ClaimValue = 100 }, "case CASE_001 changes claimValue, only defect cures may change an on-hold case"},
+		{"payment", func(c *Case) { c.Fees.Payments = []FeePayment{{ReceiptRef: "TR-1", Amount: 1000}} }, "case CASE_001 changes fees.payments[0], only defect cures may change an on-hold case"},
+		{"verdict", func(c *Case) { c.Documents[1].Verdict = VerdictAccepted }, "case CASE_001 changes documents[1].verdict, only defect cures may change an on-hold case"},
+		{"history rewritten", func(c *Case) { c.History[0].Comments = "no defects" }, "history entry 0 of case CASE_001 was changed"},
+		{"history dropped", func(c *Case) { c.History = nil }, "case CASE_001 is missing history entries"},
+	} {
+		c := returned()
+		tt.change(c)
+		if _, _, err := ApplyCures(stored, c); err == nil || err.Error() != tt.err {
+			t.Errorf("%s: err = %v, want %q", tt.name, err, tt.err)
+		}
+	}
+}
+
+func TestCureReplacedDefects(t *testing.T) {
+	c := &Case{
+		ID: "CASE_001",
+		Documents: []Document{
+			{ID: "DOC_1", Version: 1, Status: DocumentActive, Verdict: VerdictNeedsCorrection},
+			{ID: "DOC_2", Version: 1, Status: DocumentActive, Verdict: VerdictNeedsCorrection},
+		},
+		Defects: []Defect{
+			{ID: "DEF_1", DocumentID: "DOC_1", Deadline: "2024-05-22T10:00:00Z", Status: DefectOpen},
+			{ID: "DEF_2", DocumentID: "DOC_2", Deadline: "2024-05-22T10:00:00Z", Status: DefectOpen},
+		},
+	}
+	// DOC_1 is replaced twice, DOC_2 not at all
+	if err := ReplaceDocument(c, "DOC_1", Document{ID: "DOC_1_V2", ContentHash: ContentHash([]byte("%PDF-1.4 unsigned vakalatnama"))}, "2024-05-20T10:00:00Z"); err != nil {
+		t.Fatal(err)
+	}
+	if err := ReplaceDocument(c, "DOC_1_V2", Document{ID: "DOC_1_V3", ContentHash: ContentHash([]byte("%PDF-1.4 signed vakalatnama"))}, "2024-05-21T10:00:00Z"); err != nil {
+		t.Fatal(err)
+	}
+
+	cured, err := CureReplacedDefects(c, "2024-05-23T10:00:00Z")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cured) != 1 || cured[0] != "DEF_1" {
+		t.Errorf("cured = %v", cured)
+	}
+	defect := c.Defect("DEF_1")
+	if defect.Status != DefectCured || defect.CureDocumentID != "DOC_1_V3" || defect.CuredAt != "2024-05-23T10:00:00Z" || !defect.Late {
+		t.Errorf("defect = %+v", defect)
+	}
+	if c.Defect("DEF_2").Status != DefectOpen {
+		t.Errorf("defect of a document not replaced was cured: %+v", c.Defect("DEF_2"))
+	}
+}
+
+func TestApplyReplacements(t *testing.T) {
+	stored := &Case{
+		ID:         "CASE_001",
+		Status:     StatusForwardedToLawyerRejected,
+		CurrentOrg: OrgLawyers,
+		Documents: []Document{
+			{ID: "DOC_1", Version: 1, Status: DocumentActive, Verdict: VerdictAccepted},
+			{ID: "DOC_2", Version: 1, Status: DocumentActive, Verdict: VerdictRejected, ReasonCode: ReasonWrongType},
+			{ID: "DOC_3", Version: 1, Status: DocumentActive, Verdict: VerdictNeedsCorrection, ReasonCode: ReasonUnsigned},
+		},
+		Defects: []Defect{{ID: "DEF_1", DocumentID: "DOC_3", Deadline: "2024-05-22T10:00:00Z", Status: DefectOpen}},
+		History: []HistoryItem{{Status: StatusForwardedToLawyerRejected, Organization: "StampReportersOrg", Timestamp: "2024-05-15T10:00:00Z"}},
+	}
+	// returned is stored as the lawyer resubmits it with DOC_3 and then DOC_2 replaced
+	returned := func() *Case {
+		c, err := copyCase(stored)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := ReplaceDocument(c, "DOC_3", Document{ID: "DOC_3_V2", ContentHash: ContentHash([]byte("%PDF-1.4 signed affidavit"))}, "2024-05-18T10:00:00Z"); err != nil {
+			t.Fatal(err)
+		}
+		if err := ReplaceDocument(c, "DOC_2", Document{ID: "DOC_2_V2", ContentHash: ContentHash([]byte("%PDF-1.4 plaint"))}, "2024-05-19T10:00:00Z"); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := CureReplacedDefects(c, "2024-05-20T10:00:00Z"); err != nil {
+			t.Fatal(err)
+		}
+		c.Status, c.CurrentOrg = StatusPendingStampReporterReview, OrgStampReporters
+		c.History = append(c.History, HistoryItem{Status: "RESUBMITTED_TO_STAMP_REPORTER", Organization: "LawyersOrg", Timestamp: "2024-05-20T10:00:00Z"})
+		return c
+	}
+
+	rebuilt, replaced, err := ApplyReplacements(stored, returned())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(replaced) != 2 || replaced[0] != "DOC_2" || replaced[1] != "DOC_3" {
+		t.Errorf("replaced = %v", replaced)
+	}
+	if rebuilt.Status != StatusPendingStampReporterReview || rebuilt.Documents[3].ID != "DOC_3_V2" || rebuilt.Defect("DEF_1").CureDocumentID != "DOC_3_V2" || len(RejectedDocuments(rebuilt)) != 0 {
+		t.Errorf("rebuilt = %+v", rebuilt)
+	}
+	if stored.Document("DOC_2").Status != DocumentActive || stored.Defect("DEF_1").Status != DefectOpen {
+		t.Errorf("stored case was changed: %+v", stored)
+	}
+
+	// A rejected document may be withdrawn instead of replaced
+	withdrawn := returned()
+	withdrawn.Documents = withdrawn.Documents[:4]
+	withdrawn.Document("DOC_2").Status = DocumentWithdrawn
+	if _, replaced, err := ApplyReplacements(stored, withdrawn); err != nil || len(replaced) != 2 {
+		t.Errorf("withdrawn: replaced = %v, %v", replaced, err)
+	}
+
+	for _, tt := range []struct {
+		name   string
+		change func(c *Case)
+		err    string
+	}{
+		{"not replaced", func(c *Case) {
+			c.Documents = c.Documents[:4]
+			c.Document("DOC_2").Status = DocumentActive
+		}, "document DOC_2 of case CASE_001 is REJECTED and was not replaced"},
+		{"accepted document replaced", func(c *Case) {
+			c.Documents[0].Status = DocumentSuperseded
+			c.Documents = append(c.Documents, Document{ID: "DOC_1_V2", SupersedesID: "DOC_1"})
+		}, "document DOC_1_V2 of case CASE_001 does not replace a rejected document"},
+		{"new document", func(c *Case) { c.Documents = append(c.Documents, Document{ID: "DOC_4"}) }, "document DOC_4 of case CASE_001 does not replace a rejected document"},
+		{"defect open", func(c *Case) { c.Defects[0].Status = DefectOpen }, "defect DEF_1 of case CASE_001 is not cured"},
+		{"verdict", func(c *Case) { c.Documents[3].Verdict = VerdictAccepted }, "case CASE_001 changes documents[3].verdict, only replacing the rejected documents may change a rejected case"},
+		{"history rewritten", func(c *Case) { c.History[0].Comments = "accepted" }, "history entry 0 of case CASE_001 was changed"},
+	} {
+		c := returned()
+		tt.change(c)
+		if _, _, err := ApplyReplacements(stored, c); err == nil || err.Error() != tt.err {
+			t.Errorf("%s: err = %v, want %q", tt.name, err, tt.err)
+		}
+	}
+}
@@ -0,0 +1,235 @@
+package casemodel
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// Document statuses. Documents uploaded before version 7 have no status and count as
+// active.
+const (
+	DocumentActive     = "ACTIVE"
+	DocumentSuperseded = "SUPERSEDED"
+	DocumentWithdrawn  = "WITHDRAWN"
+)
+
+// ContentHash returns the hex SHA-256 of a document's file, the form Document.ContentHash
+// and the content store use
+func ContentHash(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// ContentDigest returns the hex SHA-256 of the digest contentHash encodes, the form
+// Document.ContentDigest uses. It lets a case prove which file a document was uploaded
+// with without carrying the file's own hash.
+func ContentDigest(contentHash string) string {
+	raw, _ := hex.DecodeString(contentHash)
+	sum := sha256.Sum256(raw)
+	return hex.EncodeToString(sum[:])
+}
+
+// ValidContentHash reports whether hash is a lowercase hex SHA-256
+func ValidContentHash(hash string) bool {
+	if len(hash) != sha256.Size*2 {
+		return false
+	}
+	for _, r := range hash {
+		if !('0' <= r && r <= '9' || 'a' <= r && r <= 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+// PrepareUpload checks a document added to a case and resets the fields only later
+// stages may set. The client must give the content hash of the file it put in the
+// content store, from which the content digest is set. A document received from
+// another organization after its content hash was moved to private data, see
+// SetPrivateDetails, is accepted with its content digest alone.
+func PrepareUpload(doc *Document, uploadedAt string) error {
+	if doc.ID == "" {
+		return fmt.Errorf("document must have an id")
+	}
+	switch {
+	case ValidContentHash(doc.ContentHash):
+		doc.ContentDigest = ContentDigest(doc.ContentHash)
+	case doc.ContentHash == "" && ValidContentHash(doc.ContentDigest):
+	default:
+		return fmt.Errorf("document %s must have a contentHash, the hex SHA-256 of its file, got %q", doc.ID, doc.ContentHash)
+	}
+	doc.SignatureHash = ""
+	doc.Validated = false
+	doc.Verdict, doc.ReasonCode, doc.VerdictComments = "", "", ""
+	doc.SignatureHistory = make([]DocumentSignature, 0)
+	doc.UploadedAt = uploadedAt
+	doc.Version, doc.SupersedesID, doc.Status = 1, "", DocumentActive
+	return nil
+}
+
+// Active reports whether d is the latest version of a document and not withdrawn. Only
+// active documents are validated by the stamp reporter.
+func (d *Document) Active() bool {
+	return d.Status == "" || d.Status == DocumentActive
+}
+
+// Anchor returns the hash d anchors its file with on the public case and signatures are
+// made over: its content digest, or its content hash if it was uploaded before version
+// 14. It is empty for documents uploaded before version 6.
+func (d *Document) Anchor() string {
+	if d.ContentDigest != "" {
+		return d.ContentDigest
+	}
+	return d.ContentHash
+}
+
+// Anchors reports whether contentHash is the hash of the file d was uploaded with
+func (d *Document) Anchors(contentHash string) bool {
+	if d.ContentDigest != "" {
+		return ContentDigest(contentHash) == d.ContentDigest
+	}
+	return d.ContentHash != "" && contentHash == d.ContentHash
+}
+
+// Document returns the document of c with the given ID, or nil
+func (c *Case) Document(id string) *Document {
+	for i := range c.Documents {
+		if c.Documents[i].ID == id {
+			return &c.Documents[i]
+		}
+	}
+	return nil
+}
+
+// AddDocument prepares doc and adds it to c as the first version of a new document
+func AddDocument(c *Case, doc Document, uploadedAt string) error {
+	if err := PrepareUpload(&doc, uploadedAt); err != nil {
+		return err
+	}
+	if c.Document(doc.ID) != nil {
+		return fmt.Errorf("document %s already exists in case %s, use ReplaceDocument to file a new version", doc.ID, c.ID)
+	}
+	c.Documents = append(c.Documents, doc)
+	return nil
+}
+
+// ReplaceDocument prepares doc and adds it to c as the next version of document oldID,
+// which is superseded. The new version needs an ID of its own.
+func ReplaceDocument(c *Case, oldID string, doc Document, uploadedAt string) error {
+	old, err := activeDocument(c, oldID)
+	if err != nil {
+		return err
+	}
+	if err := PrepareUpload(&doc, uploadedAt); err != nil {
+		return err
+	}
+	if c.Document(doc.ID) != nil {
+		return fmt.Errorf("document %s already exists in case %s, give the new version its own id", doc.ID, c.ID)
+	}
+
+	doc.Version = old.Version + 1
+	if old.Version == 0 {
+		doc.Version = 2 // uploaded before versioning
+	}
+	doc.SupersedesID = old.ID
+	old.Status = DocumentSuperseded
+	c.Documents = append(c.Documents, doc)
+	return nil
+}
+
+// WithdrawDocument withdraws document id from c. It stays on the case but is no longer
+// validated.
+func WithdrawDocument(c *Case, id string) error {
+	doc, err := activeDocument(c, id)
+	if err != nil {
+		return err
+	}
+	doc.Status = DocumentWithdrawn
+	return nil
+}
+
+// activeDocument returns document id of c, which must be active
+func activeDocument(c *Case, id string) (*Document, error) {
+	doc := c.Document(id)
+	if doc == nil {
+		return nil, fmt.Errorf("document %s not found in case %s", id, c.ID)
+	}
+	if !doc.Active() {
+		return nil, fmt.Errorf("document %s of case %s is %s, only the latest active version can be changed", id, c.ID, doc.Status)
+	}
+	return doc, nil
+}
+
+// latestVersion returns the latest version of document id of c, following the versions
+// that replaced it, or nil if c has no document id
+func latestVersion(c *Case, id string) *Document {
+	doc := c.Document(id)
+	for doc != nil && doc.Status == DocumentSuperseded {
+		next := doc
+		for i := range c.Documents {
+			if c.Documents[i].SupersedesID == doc.ID {
+				next = &c.Documents[i]
+				break
+			}
+		}
+		if next == doc {
+			break
+		}
+		doc = next
+	}
+	return doc
+}
+
+// DocumentVerification is the result of checking a file against the hash a document
+// anchors on the ledger. Documents uploaded since version 14 anchor their content
+// digest, older ones their content hash.
+type DocumentVerification struct {
+	CaseID        string `json:"caseId"`
+	DocumentID    string `json:"documentId"`
+	ContentHash   string `json:"contentHash,omitempty" metadata:",optional"`   // as anchored on the ledger before version 14
+	ContentDigest string `json:"contentDigest,omitempty" metadata:",optional"` // as anchored on the ledger
+	Submitted     string `json:"submitted"`                                    // hash of the file being checked
+	Match         bool   `json:"match"`
+}
+
+// VerifyDocument reads a case from the world state and checks whether a file with the
+// hex SHA-256 submitted is the one document docID was uploaded with
+func VerifyDocument(ctx contractapi.TransactionContextInterface, caseID string, docID string, submitted string) (*DocumentVerification, error) {
+	caseJSON, err := ctx.GetStub().GetState(caseID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read case: %v", err)
+	}
+	if caseJSON == nil {
+		return nil, fmt.Errorf("case does not exist: %s", caseID)
+	}
+	c, err := DecodeCase(caseJSON)
+	if err != nil {
+		return nil, err
+	}
+
+	submitted = strings.ToLower(submitted)
+	if !ValidContentHash(submitted) {
+		return nil, fmt.Errorf("%q is not a hex SHA-256", submitted)
+	}
+	for _, doc := range c.Documents {
+		if doc.ID != docID {
+			continue
+		}
+		if doc.Anchor() == "" {
+			return nil, fmt.Errorf("document %s of case %s was uploaded without a content hash", docID, caseID)
+		}
+		return &DocumentVerification{
+			CaseID:        caseID,
+			DocumentID:    docID,
+			ContentHash:   doc.ContentHash,
+			ContentDigest: doc.ContentDigest,
+			Submitted:     submitted,
+			Match:         doc.Anchors(submitted),
+		}, nil
+	}
+	return nil, fmt.Errorf("document %s not found in case %s", docID, caseID)
+}
@@ -0,0 +1,162 @@
+package casemodel
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+
+	"casemodel/mockstub"
+)
+
+func TestPrepareUpload(t *testing.T) {
+	plaint := ContentHash([]byte("%PDF-1.4 plaint"))
+	doc := Document{ID: "DOC_1", ContentHash: plaint, SignatureHash: "forged", Validated: true, Verdict: VerdictAccepted, SignatureHistory: []DocumentSignature{{SignatureHash: "forged"}}}
+	if err := PrepareUpload(&doc, "2024-05-15T10:00:00Z"); err != nil {
+		t.Fatal(err)
+	}
+	if doc.SignatureHash != "" || doc.Validated || doc.Verdict != "" || len(doc.SignatureHistory) != 0 || doc.UploadedAt != "2024-05-15T10:00:00Z" {
+		t.Errorf("document = %+v", doc)
+	}
+	if doc.ContentDigest != ContentDigest(plaint) || doc.Anchor() != doc.ContentDigest || !doc.Anchors(plaint) {
+		t.Errorf("content digest = %q", doc.ContentDigest)
+	}
+	// A document received after its content hash went to private data keeps its digest
+	received := Document{ID: "DOC_1", ContentDigest: ContentDigest(plaint)}
+	if err := PrepareUpload(&received, ""); err != nil || received.ContentDigest != ContentDigest(plaint) {
+		t.Errorf("received document = %+v, %v", received, err)
+	}
+
+	for _, tt := range []struct {
+		doc     Document
+		wantErr string
+	}{
+		{Document{ContentHash: plaint}, "document must have an id"},
+		{Document{ID: "DOC_1", Hash: plaint}, "document DOC_1 must have a contentHash"},
+		{Document{ID: "DOC_1", ContentHash: "9f2c"}, "document DOC_1 must have a contentHash"},
+		{Document{ID: "DOC_1", ContentHash: strings.ToUpper(plaint)}, "document DOC_1 must have a contentHash"},
+		{Document{ID: "DOC_1", ContentDigest: "9f2c"}, "document DOC_1 must have a contentHash"},
+	} {
+		if err := PrepareUpload(&tt.doc, ""); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("PrepareUpload(%+v) = %v, want %q", tt.doc, err, tt.wantErr)
+		}
+	}
+}
+
+func TestVerifyDocument(t *testing.T) {
+	n := mockstub.NewNetwork(time.Date(2024, time.May, 15, 10, 0, 0, 0, time.UTC))
+	plaint := ContentHash([]byte("%PDF-1.4 plaint"))
+	c := &Case{ID: "CASE_001", Documents: []Document{{ID: "DOC_1", ContentHash: plaint}, {ID: "DOC_2", Hash: "9f2c"}, {ID: "DOC_3", ContentDigest: ContentDigest(plaint)}}}
+	value, _ := json.Marshal(c)
+	n.PutState(ChannelLawyerRegistrar, "lawyer", c.ID, value)
+
+	verify := func(caseID string, docID string, submitted string) (*DocumentVerification, error) {
+		var v *DocumentVerification
+		err := inTransaction(t, n, "lawyer", lawyer, "", func(ctx contractapi.TransactionContextInterface) error {
+			var err error
+			v, err = VerifyDocument(ctx, caseID, docID, submitted)
+			return err
+		})
+		return v, err
+	}
+
+	v, err := verify("CASE_001", "DOC_1", strings.ToUpper(plaint))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (DocumentVerification{CaseID: "CASE_001", DocumentID: "DOC_1", ContentHash: plaint, Submitted: plaint, Match: true}); *v != want {
+		t.Errorf("verification = %+v", v)
+	}
+	altered := ContentHash([]byte("%PDF-1.4 altered"))
+	if v, err := verify("CASE_001", "DOC_1", altered); err != nil || v.Match || v.Submitted != altered {
+		t.Errorf("altered file = %+v, %v", v, err)
+	}
+	// Documents whose content hash is private are checked against their digest
+	v, err = verify("CASE_001", "DOC_3", plaint)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (DocumentVerification{CaseID: "CASE_001", DocumentID: "DOC_3", ContentDigest: ContentDigest(plaint), Submitted: plaint, Match: true}); *v != want {
+		t.Errorf("verification against the digest = %+v", v)
+	}
+	if v, err := verify("CASE_001", "DOC_3", ContentDigest(plaint)); err != nil || v.Match {
+		t.Errorf("digest submitted as the file hash = %+v, %v", v, err)
+	}
+
+	for _, tt := range []struct {
+		caseID, docID, submitted, wantErr string
+	}{
+		{"CASE_001", "DOC_1", "9f2c", `"9f2c" is not a hex SHA-256`},
+		{"CASE_001", "DOC_2", plaint, "document DOC_2 of case CASE_001 was uploaded without a content hash"},
+		{"CASE_001", "DOC_9", plaint, "document DOC_9 not found in case CASE_001"},
+		{"CASE_404", "DOC_1", plaint, "case does not exist: CASE_404"},
+	} {
+		if _, err := verify(tt.caseID, tt.docID, tt.submitted); err == nil || err.Error() != tt.wantErr {
+			t.Errorf("VerifyDocument(%s, %s) = %v, want %q", tt.caseID, tt.docID, err, tt.wantErr)
+		}
+	}
+}
+
+func TestDocumentVersions(t *testing.T) {
+	plaint := ContentHash([]byte("%PDF-1.4 plaint"))
+	amended := ContentHash([]byte("%PDF-1.4 amended plaint"))
+	c := &Case{ID: "CASE_001", Documents: []Document{{ID: "DOC_0", Hash: "9f2c"}}}
+
+	if err := AddDocument(c, Document{ID: "DOC_1", ContentHash: plaint}, "2024-05-15T10:00:00Z"); err != nil {
+		t.Fatal(err)
+	}
+	if err := ReplaceDocument(c, "DOC_1", Document{ID: "DOC_1_V2", ContentHash: amended}, "2024-05-16T10:00:00Z"); err != nil {
+		t.Fatal(err)
+	}
+	if err := ReplaceDocument(c, "DOC_0", Document{ID: "DOC_0_V2", ContentHash: amended}, "2024-05-16T10:00:00Z"); err != nil {
+		t.Fatal(err)
+	}
+	if err := WithdrawDocument(c, "DOC_0_V2"); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []struct {
+		id, supersedes, status string
+		version                int
+		active                 bool
+	}{
+		{"DOC_0", "", DocumentSuperseded, 0, false},
+		{"DOC_1", "", DocumentSuperseded, 1, false},
+		{"DOC_1_V2", "DOC_1", DocumentActive, 2, true},
+		{"DOC_0_V2", "DOC_0", DocumentWithdrawn, 2, false},
+	}
+	if len(c.Documents) != len(want) {
+		t.Fatalf("documents = %+v", c.Documents)
+	}
+	for i, w := range want {
+		d := c.Documents[i]
+		if d.ID != w.id || d.SupersedesID != w.supersedes || d.Status != w.status || d.Version != w.version || d.Active() != w.active {
+			t.Errorf("document %d = %+v, want %+v", i, d, w)
+		}
+	}
+	if !(&Document{}).Active() {
+		t.Error("a document uploaded before versioning should be active")
+	}
+
+	for _, tt := range []struct {
+		name    string
+		change  func() error
+		wantErr string
+	}{
+		{"add an existing id", func() error { return AddDocument(c, Document{ID: "DOC_1", ContentHash: plaint}, "") }, "document DOC_1 already exists in case CASE_001, use ReplaceDocument"},
+		{"replace a superseded version", func() error { return ReplaceDocument(c, "DOC_1", Document{ID: "DOC_1_V3", ContentHash: plaint}, "") }, "document DOC_1 of case CASE_001 is SUPERSEDED"},
+		{"replace with an existing id", func() error { return ReplaceDocument(c, "DOC_1_V2", Document{ID: "DOC_1", ContentHash: plaint}, "") }, "document DOC_1 already exists in case CASE_001, give the new version its own id"},
+		{"replace without a content hash", func() error { return ReplaceDocument(c, "DOC_1_V2", Document{ID: "DOC_1_V3"}, "") }, "document DOC_1_V3 must have a contentHash"},
+		{"withdraw twice", func() error { return WithdrawDocument(c, "DOC_0_V2") }, "document DOC_0_V2 of case CASE_001 is WITHDRAWN"},
+		{"withdraw a missing document", func() error { return WithdrawDocument(c, "DOC_9") }, "document DOC_9 not found in case CASE_001"},
+	} {
+		if err := tt.change(); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("%s: err = %v, want %q", tt.name, err, tt.wantErr)
+		}
+	}
+	if len(c.Documents) != len(want) || c.Documents[2].Status != DocumentActive {
+		t.Errorf("failed changes altered the case: %+v", c.Documents)
+	}
+}
@@ -0,0 +1,117 @@
+package casemodel
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// StatusChangedEventPrefix starts the name of the event emitted by a transaction that
+// changes the status of cases. The organizations now holding them are appended, so
+// JudgesOrg listeners look for "JudgesOrg" among the parts of names starting with
+// "CaseStatusChanged.", see StatusChangedEventName.
+const StatusChangedEventPrefix = "CaseStatusChanged."
+
+// StatusChangedEvent is one status change in the payload of a status change event, which
+// is a JSON array of the changes a transaction made, in order
+type StatusChangedEvent struct {
+	CaseID     string `json:"caseId"`
+	FromStatus string `json:"fromStatus"`
+	ToStatus   string `json:"toStatus"`
+	Org        string `json:"org"`
+	CurrentOrg string `json:"currentOrg"`
+	Actor      string `json:"actor"`
+	TxID       string `json:"txId"`
+}
+
+// StatusChangedEventName returns the name of the event for status changes handing cases
+// to the given organizations: the prefix followed by each of them, sorted and separated
+// by dots
+func StatusChangedEventName(orgs ...string) string {
+	unique := make([]string, 0, len(orgs))
+	seen := make(map[string]bool)
+	for _, org := range orgs {
+		if !seen[org] {
+			seen[org] = true
+			unique = append(unique, org)
+		}
+	}
+	sort.Strings(unique)
+	return StatusChangedEventPrefix + strings.Join(unique, ".")
+}
+
+// TransactionContext is the transaction context of every contract. Fabric only keeps the
+// last event a transaction sets, so ApplyTransition collects status changes here and
+// EmitStatusChanges sends them in one event once the transaction function has returned
+// without error. Set it as the contract's TransactionContextHandler and EmitStatusChanges
+// as its AfterTransaction hook. It also counts the transfers the transaction adds to the
+// outbox, which the ledger does not return until the transaction commits.
+type TransactionContext struct {
+	contractapi.TransactionContext
+	statusChanges    []StatusChangedEvent
+	transferSequence uint64 // last outbox sequence number this transaction took, 0 if none
+}
+
+// clientName returns the common name of the submitting certificate, or its ID if the
+// certificate cannot be read
+func clientName(ctx contractapi.TransactionContextInterface) string {
+	identity := ctx.GetClientIdentity()
+	if cert, err := identity.GetX509Certificate(); err == nil && cert != nil && cert.Subject.CommonName != "" {
+		return cert.Subject.CommonName
+	}
+	id, _ := identity.GetID()
+	return id
+}
+
+// recordStatusChanged adds a status change to those ctx emits once the transaction
+// succeeds. A context that does not collect them, as in a contract without
+// TransactionContext, gets the change set as its event straight away.
+func recordStatusChanged(ctx contractapi.TransactionContextInterface, c *Case, from string, org string) error {
+	event := StatusChangedEvent{
+		CaseID:     c.ID,
+		FromStatus: from,
+		ToStatus:   c.Status,
+		Org:        org,
+		CurrentOrg: c.CurrentOrg,
+		Actor:      clientName(ctx),
+		TxID:       ctx.GetStub().GetTxID(),
+	}
+	if tc, ok := ctx.(*TransactionContext); ok {
+		tc.statusChanges = append(tc.statusChanges, event)
+		return nil
+	}
+	return setStatusChangedEvent(ctx, []StatusChangedEvent{event})
+}
+
+// EmitStatusChanges sets one event carrying every status change the transaction made, if
+// it made any. Contracts run it as their AfterTransaction hook, which Fabric only calls
+// when the transaction function succeeded, so changes to cases a failed transaction did
+// not store are never announced.
+func EmitStatusChanges(ctx contractapi.TransactionContextInterface) error {
+	tc, ok := ctx.(*TransactionContext)
+	if !ok || len(tc.statusChanges) == 0 {
+		return nil
+	}
+	changes := tc.statusChanges
+	tc.statusChanges = nil
+	return setStatusChangedEvent(ctx, changes)
+}
+
+// setStatusChangedEvent sets the status change event of the transaction
+func setStatusChangedEvent(ctx contractapi.TransactionContextInterface, changes []StatusChangedEvent) error {
+	orgs := make([]string, 0, len(changes))
+	for _, change := range changes {
+		orgs = append(orgs, change.CurrentOrg)
+	}
+	payload, err := json.Marshal(changes)
+	if err != nil {
+		return fmt.Errorf("failed to marshal status change event: %v", err)
+	}
+	if err := ctx.GetStub().SetEvent(StatusChangedEventName(orgs...), payload); err != nil {
+		return fmt.Errorf("failed to set status change event: %v", err)
+	}
+	return nil
+}
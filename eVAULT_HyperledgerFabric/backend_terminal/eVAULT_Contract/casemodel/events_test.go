@@ -0,0 +1,137 @@
+package casemodel
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+
+	"casemodel/mockstub"
+)
+
+func TestApplyTransitionEmitsEvent(t *testing.T) {
+	ctx := contextFor("RegistrarsOrgMSP")
+	c := &Case{ID: "CASE_001", Status: StatusVerifiedByRegistrar, CurrentOrg: OrgRegistrars}
+
+	if err := ApplyTransition(ctx, c, StatusPendingStampReporterReview); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if c.CurrentOrg != OrgStampReporters {
+		t.Errorf("current org = %s, want %s", c.CurrentOrg, OrgStampReporters)
+	}
+
+	stub := ctx.GetStub().(*eventStub)
+	if stub.name != "CaseStatusChanged.StampReportersOrg" {
+		t.Errorf("event name = %q", stub.name)
+	}
+	var events []StatusChangedEvent
+	if err := json.Unmarshal(stub.payload, &events); err != nil {
+		t.Fatalf("failed to unmarshal event: %v", err)
+	}
+	want := StatusChangedEvent{
+		CaseID:     "CASE_001",
+		FromStatus: StatusVerifiedByRegistrar,
+		ToStatus:   StatusPendingStampReporterReview,
+		Org:        OrgRegistrars,
+		CurrentOrg: OrgStampReporters,
+		TxID:       "tx1",
+	}
+	if len(events) != 1 || events[0] != want {
+		t.Errorf("events = %+v, want %+v", events, want)
+	}
+}
+
+func TestRejectedTransitionEmitsNoEvent(t *testing.T) {
+	ctx := contextFor("JudgesOrgMSP")
+	c := &Case{ID: "CASE_001", Status: StatusCreated, CurrentOrg: OrgLawyers}
+
+	if err := ApplyTransition(ctx, c, StatusJudgmentIssued); err == nil {
+		t.Fatal("expected the transition to be rejected")
+	}
+	if stub := ctx.GetStub().(*eventStub); stub.name != "" {
+		t.Errorf("unexpected event %q", stub.name)
+	}
+}
+
+// reviewer is a registrar contract that reviews several cases in one transaction
+type reviewer struct {
+	contractapi.Contract
+}
+
+// Review verifies or rejects each case under review and stores it, failing after the
+// transitions when fail is set
+func (r *reviewer) Review(ctx contractapi.TransactionContextInterface, verdicts string, fail bool) error {
+	var verified map[string]bool
+	if err := json.Unmarshal([]byte(verdicts), &verified); err != nil {
+		return err
+	}
+	for _, id := range []string{"CASE_001", "CASE_002"} {
+		c := &Case{ID: id, Status: StatusPendingRegistrarReview, CurrentOrg: OrgRegistrars}
+		to := StatusRejectedByRegistrar
+		if verified[id] {
+			to = StatusVerifiedByRegistrar
+		}
+		if err := ApplyTransition(ctx, c, to); err != nil {
+			return err
+		}
+		value, _ := json.Marshal(c)
+		if err := ctx.GetStub().PutState(id, value); err != nil {
+			return err
+		}
+	}
+	if fail {
+		return fmt.Errorf("review failed")
+	}
+	return nil
+}
+
+func TestStatusChangesAreEmittedTogether(t *testing.T) {
+	contract := &reviewer{}
+	contract.TransactionContextHandler = new(TransactionContext)
+	contract.AfterTransaction = EmitStatusChanges
+	cc, err := contractapi.NewChaincode(contract)
+	if err != nil {
+		t.Fatal(err)
+	}
+	n := mockstub.NewNetwork(time.Date(2024, time.May, 15, 10, 0, 0, 0, time.UTC))
+	n.Install("registrar", cc)
+	reviewer := mockstub.Identity{MSPID: "RegistrarsOrgMSP", Name: "registrar1"}
+
+	if _, err := n.Submit(ChannelLawyerRegistrar, "registrar", reviewer, "Review", `{"CASE_001":true}`, "true"); err == nil {
+		t.Fatal("expected the review to fail")
+	}
+	if events := n.Events(); len(events) != 0 {
+		t.Errorf("failed transaction emitted %+v", events)
+	}
+
+	if _, err := n.Submit(ChannelLawyerRegistrar, "registrar", reviewer, "Review", `{"CASE_001":true}`, "false"); err != nil {
+		t.Fatal(err)
+	}
+	events := n.Events()
+	if len(events) != 1 || events[0].Name != "CaseStatusChanged.LawyersOrg.RegistrarsOrg" {
+		t.Fatalf("events = %+v", events)
+	}
+	var changes []StatusChangedEvent
+	if err := json.Unmarshal(events[0].Payload, &changes); err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 2 || changes[0].CaseID != "CASE_001" || changes[0].ToStatus != StatusVerifiedByRegistrar || changes[1].CaseID != "CASE_002" || changes[1].CurrentOrg != OrgLawyers || changes[0].TxID != changes[1].TxID || changes[0].Actor != "registrar1" {
+		t.Errorf("changes = %+v", changes)
+	}
+}
+
+func TestStatusChangedEventName(t *testing.T) {
+	for _, tt := range []struct {
+		orgs []string
+		want string
+	}{
+		{[]string{OrgJudges}, "CaseStatusChanged.JudgesOrg"},
+		{[]string{OrgRegistrars, OrgLawyers, OrgRegistrars}, "CaseStatusChanged.LawyersOrg.RegistrarsOrg"},
+	} {
+		if got := StatusChangedEventName(tt.orgs...); got != tt.want {
+			t.Errorf("StatusChangedEventName(%v) = %q, want %q", tt.orgs, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,355 @@
+package casemodel
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/bits"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// Composite key object types of the fee schedules, of the fee receipts recorded against
+// cases and of the payments recorded on each case, so that none shows up as a case. A
+// feeReceipt names the case its receipt was recorded against; a feePayment is keyed by
+// case and receipt and holds the payment.
+const (
+	feeScheduleType = "feeSchedule"
+	feeReceiptType  = "feeReceipt"
+	feePaymentType  = "feePayment"
+)
+
+// basisPoints is the denominator of FeeSlab.Rate
+const basisPoints = 10000
+
+// MaxAmount is the highest claim value, fee or stamp duty in paise a case or fee schedule
+// may carry, ₹10 lakh crore, so that no fee worked out from them overflows
+const MaxAmount int64 = 1e15
+
+// FeeSlab is one band of claim values in a court fee schedule. Amounts are in paise.
+type FeeSlab struct {
+	UpTo  int64 `json:"upTo"`  // highest claim value in the band, 0 for the last band
+	Fixed int64 `json:"fixed"` // fee for a claim at the bottom of the band
+	Rate  int64 `json:"rate"`  // basis points charged on the part of the claim above the bottom of the band
+}
+
+// FeeSchedule is the court fee and stamp duty charged on cases of one type. The court
+// fee depends on the claim value, see CourtFee. Amounts are in paise.
+type FeeSchedule struct {
+	CaseType  string    `json:"caseType"`
+	Slabs     []FeeSlab `json:"slabs"`
+	StampDuty int64     `json:"stampDuty"` // charged once on every case
+	UpdatedAt string    `json:"updatedAt" metadata:",optional"`
+}
+
+// FeePayment is a court fee or stamp duty payment recorded against a case
+type FeePayment struct {
+	ReceiptRef string `json:"receiptRef"` // reference of the treasury or e-court fee receipt
+	Amount     int64  `json:"amount"`     // in paise
+	RecordedAt string `json:"recordedAt"`
+	RecordedBy string `json:"recordedBy"` // client identity ID of the stamp reporter who recorded it
+}
+
+// FeeAssessment is the court fee and stamp duty due on a case and what has been paid.
+// Amounts are in paise.
+type FeeAssessment struct {
+	CaseID     string       `json:"caseId"`
+	CaseType   string       `json:"caseType"`
+	ClaimValue int64        `json:"claimValue"`
+	CourtFee   int64        `json:"courtFee"`
+	StampDuty  int64        `json:"stampDuty"`
+	Total      int64        `json:"total"`
+	Paid       int64        `json:"paid"`
+	Balance    int64        `json:"balance"` // still to pay, never negative
+	Payments   []FeePayment `json:"payments"`
+}
+
+// Validate checks that the schedule names a case type and that its bands rise in claim
+// value, with only the last one open-ended
+func (s *FeeSchedule) Validate() error {
+	if s.CaseType == "" {
+		return fmt.Errorf("fee schedule must have a caseType")
+	}
+	if len(s.Slabs) == 0 {
+		return fmt.Errorf("fee schedule for %s has no slabs", s.CaseType)
+	}
+	if s.StampDuty < 0 {
+		return fmt.Errorf("fee schedule for %s has a negative stamp duty", s.CaseType)
+	}
+	if s.StampDuty > MaxAmount {
+		return fmt.Errorf("fee schedule for %s has a stamp duty above %d paise", s.CaseType, MaxAmount)
+	}
+	var bottom int64
+	for i, slab := range s.Slabs {
+		last := i == len(s.Slabs)-1
+		switch {
+		case slab.Fixed < 0 || slab.Rate < 0 || slab.Rate > basisPoints:
+			return fmt.Errorf("slab %d of the %s fee schedule must have a non-negative fixed fee and a rate of 0 to %d basis points", i, s.CaseType, basisPoints)
+		case slab.Fixed > MaxAmount || slab.UpTo > MaxAmount:
+			return fmt.Errorf("slab %d of the %s fee schedule must have a fixed fee and upper bound of at most %d paise", i, s.CaseType, MaxAmount)
+		case slab.UpTo == 0 && !last:
+			return fmt.Errorf("slab %d of the %s fee schedule has no upper bound, only the last slab may be open-ended", i, s.CaseType)
+		case slab.UpTo != 0 && slab.UpTo <= bottom:
+			return fmt.Errorf("slab %d of the %s fee schedule must end above %d", i, s.CaseType, bottom)
+		}
+		bottom = slab.UpTo
+	}
+	return nil
+}
+
+// CourtFee returns the court fee on a claim of the given value: the fixed fee of the band
+// the claim falls in plus its rate on the part of the claim above the bottom of the band,
+// rounded up to the paisa. A claim above the last band is charged as the top of that band.
+// It returns an error if the fee does not fit in an int64.
+func (s *FeeSchedule) CourtFee(claimValue int64) (int64, error) {
+	var bottom int64
+	for i, slab := range s.Slabs {
+		if slab.UpTo == 0 || claimValue <= slab.UpTo || i == len(s.Slabs)-1 {
+			if slab.UpTo != 0 && claimValue > slab.UpTo {
+				claimValue = slab.UpTo
+			}
+			fee, ok := ceilRate(claimValue-bottom, slab.Rate)
+			if !ok || fee > MaxAmount || slab.Fixed > MaxAmount {
+				return 0, fmt.Errorf("court fee on a claim of %d paise is above %d paise", claimValue, MaxAmount)
+			}
+			return slab.Fixed + fee, nil
+		}
+		bottom = slab.UpTo
+	}
+	return 0, nil
+}
+
+// ceilRate returns rate basis points of amount, rounded up, and false if that does not
+// fit in an int64
+func ceilRate(amount int64, rate int64) (int64, bool) {
+	if amount <= 0 || rate <= 0 {
+		return 0, true
+	}
+	hi, lo := bits.Mul64(uint64(amount), uint64(rate))
+	lo, carry := bits.Add64(lo, basisPoints-1, 0)
+	hi += carry
+	if hi >= basisPoints {
+		return 0, false
+	}
+	quotient, _ := bits.Div64(hi, lo, basisPoints)
+	if quotient > uint64(MaxAmount) {
+		return 0, false
+	}
+	return int64(quotient), true
+}
+
+// CheckClaimValue returns an error unless c claims a value from 0 to MaxAmount
+func (c *Case) CheckClaimValue() error {
+	if c.ClaimValue < 0 {
+		return fmt.Errorf("case %s has a negative claim value", c.ID)
+	}
+	if c.ClaimValue > MaxAmount {
+		return fmt.Errorf("case %s has a claim value above %d paise", c.ID, MaxAmount)
+	}
+	return nil
+}
+
+func feeScheduleKey(ctx contractapi.TransactionContextInterface, caseType string) (string, error) {
+	key, err := ctx.GetStub().CreateCompositeKey(feeScheduleType, []string{caseType})
+	if err != nil {
+		return "", fmt.Errorf("failed to create fee schedule key: %v", err)
+	}
+	return key, nil
+}
+
+// PutFeeSchedule validates a fee schedule and stores it on the ledger, replacing the one
+// for the same case type
+func PutFeeSchedule(ctx contractapi.TransactionContextInterface, scheduleJSON string) (*FeeSchedule, error) {
+	var schedule FeeSchedule
+	if err := json.Unmarshal([]byte(scheduleJSON), &schedule); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal fee schedule: %v", err)
+	}
+	if err := schedule.Validate(); err != nil {
+		return nil, err
+	}
+	txTime, err := TxTime(ctx)
+	if err != nil {
+		return nil, err
+	}
+	schedule.UpdatedAt = txTime.Format(time.RFC3339)
+
+	data, err := json.Marshal(schedule)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal fee schedule: %v", err)
+	}
+	key, err := feeScheduleKey(ctx, schedule.CaseType)
+	if err != nil {
+		return nil, err
+	}
+	if err := ctx.GetStub().PutState(key, data); err != nil {
+		return nil, fmt.Errorf("failed to store fee schedule: %v", err)
+	}
+	return &schedule, nil
+}
+
+// GetFeeSchedule returns the fee schedule stored for a case type
+func GetFeeSchedule(ctx contractapi.TransactionContextInterface, caseType string) (*FeeSchedule, error) {
+	key, err := feeScheduleKey(ctx, caseType)
+	if err != nil {
+		return nil, err
+	}
+	data, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read fee schedule: %v", err)
+	}
+	if data == nil {
+		return nil, fmt.Errorf("no fee schedule for case type %q", caseType)
+	}
+	var schedule FeeSchedule
+	if err := json.Unmarshal(data, &schedule); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal fee schedule: %v", err)
+	}
+	return &schedule, nil
+}
+
+// AssessFees works out the court fee and stamp duty due on c from the schedule for its
+// type, less the payments recorded against it on the ledger. c.Fees is not trusted, as
+// it arrives with the case from other organizations.
+func AssessFees(ctx contractapi.TransactionContextInterface, c *Case) (*FeeAssessment, error) {
+	return assessFees(ctx, c, nil)
+}
+
+// assessFees is AssessFees counting recorded as well, a payment written in this
+// transaction that the ledger does not return yet
+func assessFees(ctx contractapi.TransactionContextInterface, c *Case, recorded *FeePayment) (*FeeAssessment, error) {
+	if err := c.CheckClaimValue(); err != nil {
+		return nil, err
+	}
+	schedule, err := GetFeeSchedule(ctx, c.Type)
+	if err != nil {
+		return nil, err
+	}
+	// the schedule is checked again, as it could have been stored before it was bounded
+	if err := schedule.Validate(); err != nil {
+		return nil, err
+	}
+	courtFee, err := schedule.CourtFee(c.ClaimValue)
+	if err != nil {
+		return nil, fmt.Errorf("failed to assess fees on case %s: %v", c.ID, err)
+	}
+	assessment := &FeeAssessment{
+		CaseID:     c.ID,
+		CaseType:   c.Type,
+		ClaimValue: c.ClaimValue,
+		CourtFee:   courtFee,
+		StampDuty:  schedule.StampDuty,
+		Payments:   make([]FeePayment, 0),
+	}
+	assessment.Total = assessment.CourtFee + assessment.StampDuty
+	if assessment.Total <= 0 {
+		return nil, fmt.Errorf("fee schedule for %s charges nothing on case %s", c.Type, c.ID)
+	}
+	if assessment.Payments, err = feePayments(ctx, c.ID); err != nil {
+		return nil, err
+	}
+	if recorded != nil {
+		assessment.Payments = append(assessment.Payments, *recorded)
+	}
+	for _, payment := range assessment.Payments {
+		assessment.Paid += payment.Amount
+	}
+	if assessment.Paid < assessment.Total {
+		assessment.Balance = assessment.Total - assessment.Paid
+	}
+	return assessment, nil
+}
+
+// feePayments returns the payments recorded against a case on the ledger, in receipt order
+func feePayments(ctx contractapi.TransactionContextInterface, caseID string) ([]FeePayment, error) {
+	iterator, err := ctx.GetStub().GetStateByPartialCompositeKey(feePaymentType, []string{caseID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read fee payments of case %s: %v", caseID, err)
+	}
+	defer iterator.Close()
+	payments := make([]FeePayment, 0)
+	for iterator.HasNext() {
+		result, err := iterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read fee payments of case %s: %v", caseID, err)
+		}
+		var payment FeePayment
+		if err := json.Unmarshal(result.Value, &payment); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal fee payment: %v", err)
+		}
+		payments = append(payments, payment)
+	}
+	return payments, nil
+}
+
+// RecordFeePayment records a payment against c and stores the updated assessment on it.
+// A receipt can only be recorded once on the channel.
+func RecordFeePayment(ctx contractapi.TransactionContextInterface, c *Case, receiptRef string, amount int64) (*FeeAssessment, error) {
+	if receiptRef == "" {
+		return nil, fmt.Errorf("fee payment must have a receipt reference")
+	}
+	if amount <= 0 || amount > MaxAmount {
+		return nil, fmt.Errorf("fee payment %s must have a positive amount of at most %d paise", receiptRef, MaxAmount)
+	}
+
+	key, err := ctx.GetStub().CreateCompositeKey(feeReceiptType, []string{receiptRef})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create fee receipt key: %v", err)
+	}
+	recorded, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read fee receipt: %v", err)
+	}
+	if recorded != nil {
+		return nil, fmt.Errorf("receipt %s is already recorded against case %s", receiptRef, string(recorded))
+	}
+
+	txTime, err := TxTime(ctx)
+	if err != nil {
+		return nil, err
+	}
+	recordedBy, err := ctx.GetClientIdentity().GetID()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get client identity: %v", err)
+	}
+	payment := FeePayment{
+		ReceiptRef: receiptRef,
+		Amount:     amount,
+		RecordedAt: txTime.Format(time.RFC3339),
+		RecordedBy: recordedBy,
+	}
+	assessment, err := assessFees(ctx, c, &payment)
+	if err != nil {
+		return nil, err
+	}
+	c.Fees = assessment
+
+	if err := ctx.GetStub().PutState(key, []byte(c.ID)); err != nil {
+		return nil, fmt.Errorf("failed to store fee receipt: %v", err)
+	}
+	paymentKey, err := ctx.GetStub().CreateCompositeKey(feePaymentType, []string{c.ID, receiptRef})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create fee payment key: %v", err)
+	}
+	data, err := json.Marshal(payment)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal fee payment: %v", err)
+	}
+	if err := ctx.GetStub().PutState(paymentKey, data); err != nil {
+		return nil, fmt.Errorf("failed to store fee payment: %v", err)
+	}
+	return assessment, nil
+}
+
+// RequireFeesPaid assesses the fees on c, stores the assessment on it and returns an
+// error while any of them are unpaid
+func RequireFeesPaid(ctx contractapi.TransactionContextInterface, c *Case) error {
+	assessment, err := AssessFees(ctx, c)
+	if err != nil {
+		return err
+	}
+	if assessment.Balance > 0 {
+		return fmt.Errorf("case %s has %d paise of court fee and stamp duty unpaid", c.ID, assessment.Balance)
+	}
+	c.Fees = assessment
+	return nil
+}
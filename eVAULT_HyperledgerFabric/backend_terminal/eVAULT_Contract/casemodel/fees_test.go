@@ -0,0 +1,151 @@
+package casemodel
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+
+	"casemodel/mockstub"
+)
+
+// civil charges 1% of claims up to ₹1,00,000 and ₹1,000 plus 0.5% of the rest up to
+// ₹10,00,000, with ₹50 stamp duty. Amounts are in paise.
+var civil = FeeSchedule{
+	CaseType:  "Civil",
+	Slabs:     []FeeSlab{{UpTo: 10000000, Rate: 100}, {UpTo: 100000000, Fixed: 100000, Rate: 50}},
+	StampDuty: 5000,
+}
+
+func TestFeeScheduleValidate(t *testing.T) {
+	if err := civil.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range []struct {
+		name     string
+		schedule FeeSchedule
+		wantErr  string
+	}{
+		{"no case type", FeeSchedule{Slabs: civil.Slabs}, "fee schedule must have a caseType"},
+		{"no slabs", FeeSchedule{CaseType: "Civil"}, "fee schedule for Civil has no slabs"},
+		{"negative stamp duty", FeeSchedule{CaseType: "Civil", Slabs: civil.Slabs, StampDuty: -1}, "negative stamp duty"},
+		{"rate above 100%", FeeSchedule{CaseType: "Civil", Slabs: []FeeSlab{{Rate: 10001}}}, "slab 0 of the Civil fee schedule must have a non-negative fixed fee"},
+		{"open-ended middle slab", FeeSchedule{CaseType: "Civil", Slabs: []FeeSlab{{Rate: 100}, {UpTo: 100, Rate: 100}}}, "slab 0 of the Civil fee schedule has no upper bound"},
+		{"falling slabs", FeeSchedule{CaseType: "Civil", Slabs: []FeeSlab{{UpTo: 100}, {UpTo: 100}}}, "slab 1 of the Civil fee schedule must end above 100"},
+		{"stamp duty too high", FeeSchedule{CaseType: "Civil", Slabs: civil.Slabs, StampDuty: MaxAmount + 1}, "fee schedule for Civil has a stamp duty above"},
+		{"fixed fee too high", FeeSchedule{CaseType: "Civil", Slabs: []FeeSlab{{Fixed: MaxAmount + 1}}}, "slab 0 of the Civil fee schedule must have a fixed fee and upper bound of at most"},
+		{"upper bound too high", FeeSchedule{CaseType: "Civil", Slabs: []FeeSlab{{UpTo: MaxAmount + 1}}}, "slab 0 of the Civil fee schedule must have a fixed fee and upper bound of at most"},
+	} {
+		if err := tt.schedule.Validate(); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("%s: err = %v, want %q", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestCourtFee(t *testing.T) {
+	for _, tt := range []struct {
+		claim, want int64
+	}{
+		{0, 0},
+		{5000000, 50000},
+		{5000001, 50001}, // rounded up to the paisa
+		{10000000, 100000},
+		{20000000, 150000},
+		{500000000, 550000}, // charged as the top of the last slab
+	} {
+		if got, err := civil.CourtFee(tt.claim); err != nil || got != tt.want {
+			t.Errorf("CourtFee(%d) = %d, %v, want %d", tt.claim, got, err, tt.want)
+		}
+	}
+
+	// the whole of the largest claim at 100% fits, a claim past it at 100% does not
+	whole := FeeSchedule{CaseType: "Civil", Slabs: []FeeSlab{{Rate: basisPoints}}}
+	if got, err := whole.CourtFee(MaxAmount); err != nil || got != MaxAmount {
+		t.Errorf("CourtFee(MaxAmount) = %d, %v", got, err)
+	}
+	if got, err := whole.CourtFee(9e17); err == nil {
+		t.Errorf("CourtFee(9e17) = %d, want an error", got)
+	}
+}
+
+func TestRecordFeePayment(t *testing.T) {
+	n := mockstub.NewNetwork(time.Date(2024, time.May, 15, 10, 0, 0, 0, time.UTC))
+	reporter := mockstub.Identity{MSPID: "StampReportersOrgMSP", Name: "stampreporter1"}
+	c := &Case{ID: "CASE_001", Type: "Civil", ClaimValue: 5000000}
+	other := &Case{ID: "CASE_002", Type: "Civil", ClaimValue: 5000000}
+	transact := func(f func(ctx contractapi.TransactionContextInterface) error) error {
+		return inTransaction(t, n, "stampreporter", reporter, "", f)
+	}
+
+	if err := transact(func(ctx contractapi.TransactionContextInterface) error { return RequireFeesPaid(ctx, c) }); err == nil || err.Error() != `no fee schedule for case type "Civil"` {
+		t.Errorf("RequireFeesPaid without a schedule = %v", err)
+	}
+	err := transact(func(ctx contractapi.TransactionContextInterface) error {
+		_, err := PutFeeSchedule(ctx, `{"caseType":"Civil","slabs":[{"upTo":10000000,"rate":100},{"upTo":100000000,"fixed":100000,"rate":50}],"stampDuty":5000}`)
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pay := func(c *Case, receipt string, amount int64) (*FeeAssessment, error) {
+		var assessment *FeeAssessment
+		err := transact(func(ctx contractapi.TransactionContextInterface) error {
+			var err error
+			assessment, err = RecordFeePayment(ctx, c, receipt, amount)
+			return err
+		})
+		return assessment, err
+	}
+	assessment, err := pay(c, "GRN001", 50000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if assessment.Total != 55000 || assessment.Paid != 50000 || assessment.Balance != 5000 || c.Fees != assessment {
+		t.Errorf("assessment = %+v", assessment)
+	}
+	if err := transact(func(ctx contractapi.TransactionContextInterface) error { return RequireFeesPaid(ctx, c) }); err == nil || err.Error() != "case CASE_001 has 5000 paise of court fee and stamp duty unpaid" {
+		t.Errorf("RequireFeesPaid with a balance = %v", err)
+	}
+	// payments on the case itself do not count, only those recorded on the ledger
+	forged := *c
+	forged.Fees = &FeeAssessment{Payments: []FeePayment{{ReceiptRef: "GRN999", Amount: 55000}}}
+	if err := transact(func(ctx contractapi.TransactionContextInterface) error { return RequireFeesPaid(ctx, &forged) }); err == nil || err.Error() != "case CASE_001 has 5000 paise of court fee and stamp duty unpaid" {
+		t.Errorf("RequireFeesPaid with a forged payment = %v", err)
+	}
+	if _, err := pay(other, "GRN001", 5000); err == nil || err.Error() != "receipt GRN001 is already recorded against case CASE_001" {
+		t.Errorf("recording a receipt twice = %v", err)
+	}
+	if _, err := pay(c, "GRN002", -5000); err == nil || !strings.Contains(err.Error(), "fee payment GRN002 must have a positive amount") {
+		t.Errorf("recording a negative payment = %v", err)
+	}
+	if _, err := pay(c, "GRN002", MaxAmount+1); err == nil || !strings.Contains(err.Error(), "fee payment GRN002 must have a positive amount of at most") {
+		t.Errorf("recording a payment above the largest amount = %v", err)
+	}
+	huge := &Case{ID: "CASE_003", Type: "Civil", ClaimValue: 9e17}
+	if err := transact(func(ctx contractapi.TransactionContextInterface) error { return RequireFeesPaid(ctx, huge) }); err == nil || !strings.Contains(err.Error(), "case CASE_003 has a claim value above") {
+		t.Errorf("RequireFeesPaid on a claim above the largest value = %v", err)
+	}
+
+	// overpaying leaves no balance
+	if assessment, err = pay(c, "GRN002", 10000); err != nil || assessment.Balance != 0 || len(assessment.Payments) != 2 {
+		t.Errorf("assessment = %+v, %v", assessment, err)
+	}
+	if err := transact(func(ctx contractapi.TransactionContextInterface) error { return RequireFeesPaid(ctx, c) }); err != nil {
+		t.Errorf("RequireFeesPaid = %v", err)
+	}
+
+	// a schedule that charges nothing does not pass a case as paid
+	free := &Case{ID: "CASE_004", Type: "Free"}
+	err = transact(func(ctx contractapi.TransactionContextInterface) error {
+		_, err := PutFeeSchedule(ctx, `{"caseType":"Free","slabs":[{"upTo":0,"rate":0}]}`)
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := transact(func(ctx contractapi.TransactionContextInterface) error { return RequireFeesPaid(ctx, free) }); err == nil || err.Error() != "fee schedule for Free charges nothing on case CASE_004" {
+		t.Errorf("RequireFeesPaid with a schedule that charges nothing = %v", err)
+	}
+}
@@ -0,0 +1,198 @@
+package casemodel
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+
+	"casemodel/query"
+)
+
+// Time ranges accepted by CaseFilter.TimeRange
+const (
+	TimeRangeToday     = "Today"
+	TimeRangeThisWeek  = "ThisWeek"
+	TimeRangeThisMonth = "ThisMonth"
+	TimeRangeCustom    = "Custom"
+)
+
+// dateLayout is the date-only form accepted for custom ranges
+const dateLayout = "2006-01-02"
+
+// sortFields are the fields cases can be sorted on. Each needs a CouchDB index.
+var sortFields = map[string]bool{
+	"createdAt":    true,
+	"lastModified": true,
+}
+
+// searchFields are matched by CaseFilter.SearchText. The client name and description are
+// kept in private data, see SetPrivateDetails, and are empty on the public case.
+var searchFields = []string{"title", "caseNumber"}
+
+// CaseFilter is the filter accepted by the case list queries of every contract
+type CaseFilter struct {
+	TimeRange  string   `json:"timeRange"` // Today, ThisWeek, ThisMonth or Custom
+	From       string   `json:"from"`      // start of a Custom range, RFC3339 or YYYY-MM-DD
+	To         string   `json:"to"`        // end of a Custom range; a date includes the whole day
+	Status     []string `json:"status"`
+	Department string   `json:"department"`
+	CaseType   string   `json:"caseType"`
+	SearchText string   `json:"searchText"`
+	SortBy     string   `json:"sortBy"`    // createdAt or lastModified
+	SortOrder  string   `json:"sortOrder"` // asc or desc, defaults to desc
+}
+
+// ParseCaseFilter decodes a JSON case filter. An empty string is an empty filter.
+func ParseCaseFilter(filter string) (*CaseFilter, error) {
+	f := &CaseFilter{}
+	if strings.TrimSpace(filter) == "" {
+		return f, nil
+	}
+	if err := json.Unmarshal([]byte(filter), f); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal filter: %v", err)
+	}
+	if f.SortBy != "" && !sortFields[f.SortBy] {
+		return nil, fmt.Errorf("cannot sort cases by %q", f.SortBy)
+	}
+	switch strings.ToLower(f.SortOrder) {
+	case "", string(query.Asc), string(query.Desc):
+	default:
+		return nil, fmt.Errorf("sort order must be asc or desc, got %q", f.SortOrder)
+	}
+	return f, nil
+}
+
+// ResolveCaseFilter parses a JSON case filter and builds its conditions at the
+// transaction timestamp
+func ResolveCaseFilter(ctx contractapi.TransactionContextInterface, filter string) (*CaseFilter, query.Selector, error) {
+	f, err := ParseCaseFilter(filter)
+	if err != nil {
+		return nil, query.Selector{}, err
+	}
+	now, err := TxTime(ctx)
+	if err != nil {
+		return nil, query.Selector{}, err
+	}
+	selector, err := f.Selector(now)
+	if err != nil {
+		return nil, query.Selector{}, err
+	}
+	return f, selector, nil
+}
+
+// Selector returns the conditions of the filter. Time ranges are relative to now,
+// which should be the transaction timestamp.
+func (f *CaseFilter) Selector(now time.Time) (query.Selector, error) {
+	from, to, err := f.timeBounds(now.UTC())
+	if err != nil {
+		return query.Selector{}, err
+	}
+
+	var statuses query.Selector
+	if len(f.Status) > 0 {
+		statuses = query.In("status", f.Status...)
+	}
+
+	var search query.Selector
+	if text := strings.TrimSpace(f.SearchText); text != "" {
+		pattern := "(?i)" + regexp.QuoteMeta(text)
+		var matches []query.Selector
+		for _, name := range searchFields {
+			matches = append(matches, query.Regex(name, pattern))
+		}
+		search = query.Or(matches...)
+	}
+
+	return query.And(
+		statuses,
+		query.EqIfSet("department", f.Department),
+		query.EqIfSet("type", f.CaseType),
+		query.Range("createdAt", from, to),
+		search,
+	), nil
+}
+
+// Sort applies the filter's sort order to q
+func (f *CaseFilter) Sort(q *query.Query) *query.Query {
+	if f.SortBy == "" {
+		return q
+	}
+	direction := query.Desc
+	if strings.ToLower(f.SortOrder) == string(query.Asc) {
+		direction = query.Asc
+	}
+	return q.Sort(f.SortBy, direction)
+}
+
+// timeBounds returns the RFC3339 bounds of the filter's time range
+func (f *CaseFilter) timeBounds(now time.Time) (string, string, error) {
+	var from, to time.Time
+	switch strings.ToLower(f.TimeRange) {
+	case "":
+		return "", "", nil
+	case strings.ToLower(TimeRangeToday):
+		from = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+		to = from.AddDate(0, 0, 1)
+	case strings.ToLower(TimeRangeThisWeek):
+		// Weeks start on Monday
+		daysSinceMonday := (int(now.Weekday()) + 6) % 7
+		from = time.Date(now.Year(), now.Month(), now.Day()-daysSinceMonday, 0, 0, 0, 0, time.UTC)
+		to = from.AddDate(0, 0, 7)
+	case strings.ToLower(TimeRangeThisMonth):
+		from = time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+		to = from.AddDate(0, 1, 0)
+	case strings.ToLower(TimeRangeCustom):
+		var err error
+		if from, err = parseBound(f.From, false); err != nil {
+			return "", "", err
+		}
+		if to, err = parseBound(f.To, true); err != nil {
+			return "", "", err
+		}
+		if !from.IsZero() && !to.IsZero() && !from.Before(to) {
+			return "", "", fmt.Errorf("time range starts at %s, after it ends at %s", f.From, f.To)
+		}
+	default:
+		return "", "", fmt.Errorf("unknown time range %q", f.TimeRange)
+	}
+	return formatBound(from), formatBound(to), nil
+}
+
+// parseBound parses an RFC3339 time or a date. A date used as an end bound covers the
+// whole day.
+func parseBound(value string, end bool) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t.UTC(), nil
+	}
+	t, err := time.Parse(dateLayout, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time %q, use RFC3339 or YYYY-MM-DD", value)
+	}
+	if end {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t, nil
+}
+
+func formatBound(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
+// TxTime returns the transaction timestamp in UTC
+func TxTime(ctx contractapi.TransactionContextInterface) (time.Time, error) {
+	txTimestamp, err := ctx.GetStub().GetTxTimestamp()
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to get transaction timestamp: %v", err)
+	}
+	return time.Unix(txTimestamp.Seconds, int64(txTimestamp.Nanos)).UTC(), nil
+}
@@ -0,0 +1,106 @@
+package casemodel
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"casemodel/query"
+)
+
+// filterQuery builds the query for a JSON filter at a fixed time, a Wednesday
+func filterQuery(t *testing.T, filter string) (string, error) {
+	t.Helper()
+	f, err := ParseCaseFilter(filter)
+	if err != nil {
+		return "", err
+	}
+	now := time.Date(2024, time.May, 15, 10, 30, 0, 0, time.UTC)
+	selector, err := f.Selector(now)
+	if err != nil {
+		return "", err
+	}
+	return f.Sort(query.New(query.And(query.Eq("currentOrg", OrgLawyers), selector))).String(), nil
+}
+
+func TestCaseFilterSelector(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter string
+		want   string
+	}{
+		{"empty", "", `{"selector":{"currentOrg":"LawyersOrg"}}`},
+		{
+			"statuses and department",
+			`{"status":["CREATED","PENDING_REGISTRAR_REVIEW"],"department":"Civil"}`,
+			`{"selector":{"currentOrg":"LawyersOrg","department":"Civil","status":{"$in":["CREATED","PENDING_REGISTRAR_REVIEW"]}}}`,
+		},
+		{
+			"today",
+			`{"timeRange":"Today"}`,
+			`{"selector":{"createdAt":{"$gte":"2024-05-15T00:00:00Z","$lt":"2024-05-16T00:00:00Z"},"currentOrg":"LawyersOrg"}}`,
+		},
+		{
+			"this week starts on monday",
+			`{"timeRange":"thisweek"}`,
+			`{"selector":{"createdAt":{"$gte":"2024-05-13T00:00:00Z","$lt":"2024-05-20T00:00:00Z"},"currentOrg":"LawyersOrg"}}`,
+		},
+		{
+			"this month",
+			`{"timeRange":"ThisMonth"}`,
+			`{"selector":{"createdAt":{"$gte":"2024-05-01T00:00:00Z","$lt":"2024-06-01T00:00:00Z"},"currentOrg":"LawyersOrg"}}`,
+		},
+		{
+			"custom dates include the last day",
+			`{"timeRange":"Custom","from":"2024-01-01","to":"2024-01-31"}`,
+			`{"selector":{"createdAt":{"$gte":"2024-01-01T00:00:00Z","$lt":"2024-02-01T00:00:00Z"},"currentOrg":"LawyersOrg"}}`,
+		},
+		{
+			"custom open ended",
+			`{"timeRange":"Custom","from":"2024-01-01T05:30:00+05:30"}`,
+			`{"selector":{"createdAt":{"$gte":"2024-01-01T00:00:00Z"},"currentOrg":"LawyersOrg"}}`,
+		},
+		{
+			"search is case insensitive and literal",
+			`{"searchText":" land (east) "}`,
+			`{"selector":{"$or":[{"title":{"$regex":"(?i)land \\(east\\)"}},{"caseNumber":{"$regex":"(?i)land \\(east\\)"}}],"currentOrg":"LawyersOrg"}}`,
+		},
+		{
+			"sorted",
+			`{"caseType":"Civil","sortBy":"createdAt","sortOrder":"ASC"}`,
+			`{"selector":{"createdAt":{"$gt":null},"currentOrg":"LawyersOrg","type":"Civil"},"sort":[{"createdAt":"asc"}]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := filterQuery(t, tt.filter)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got  %s\nwant %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCaseFilterErrors(t *testing.T) {
+	tests := []struct {
+		filter  string
+		wantErr string
+	}{
+		{`{"timeRange":"Yesterday"}`, "unknown time range"},
+		{`{"timeRange":"Custom","from":"15/05/2024"}`, "invalid time"},
+		{`{"timeRange":"Custom","from":"2024-02-01","to":"2024-01-01"}`, "after it ends"},
+		{`{"sortBy":"title"}`, "cannot sort"},
+		{`{"sortBy":"createdAt","sortOrder":"up"}`, "sort order"},
+		{`{"status":"CREATED"}`, "failed to unmarshal filter"},
+	}
+
+	for _, tt := range tests {
+		if _, err := filterQuery(t, tt.filter); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("%s: expected error containing %q, got %v", tt.filter, tt.wantErr, err)
+		}
+	}
+}
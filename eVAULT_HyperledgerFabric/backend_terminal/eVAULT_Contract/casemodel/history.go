@@ -0,0 +1,122 @@
+package casemodel
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// Schema versions at which history chaining began and at which the case proof started
+// recording the head of the chain. Entries of a case last stored before chaining began
+// have no hash; Seal chains them all the next time the case is stored.
+const (
+	chainedHistoryVersion = 3
+	historyHeadVersion    = 15
+)
+
+// HistoryVerification is the result of checking a case's history chain
+type HistoryVerification struct {
+	CaseID   string `json:"caseId"`
+	Valid    bool   `json:"valid"`
+	Entries  int    `json:"entries"`
+	Chained  int    `json:"chained"`                     // entries covered by the chain, the ones before it predate chaining
+	BrokenAt int    `json:"brokenAt"`                    // index of the first broken entry, -1 when the chain is intact
+	Reason   string `json:"reason" metadata:",optional"` // why the entry at BrokenAt does not verify
+}
+
+// historyHash returns the hash an entry is referred to by from the next one
+func historyHash(h HistoryItem) string {
+	sum := sha256.Sum256([]byte(strings.Join([]string{h.PrevHash, h.Status, h.Organization, h.Timestamp, h.Comments, h.TxID, h.SubmittedBy}, "\n")))
+	return hex.EncodeToString(sum[:])
+}
+
+// chainHistory links the entries appended to c's history since it was last stored to the
+// entries before them, recording the current transaction and its submitter
+func chainHistory(ctx contractapi.TransactionContextInterface, c *Case) error {
+	first := len(c.History)
+	for first > 0 && c.History[first-1].Hash == "" {
+		first--
+	}
+	if first == len(c.History) {
+		return nil
+	}
+	submitter, err := ctx.GetClientIdentity().GetID()
+	if err != nil {
+		return fmt.Errorf("failed to get client identity: %v", err)
+	}
+
+	previous := ""
+	if first > 0 {
+		previous = c.History[first-1].Hash
+	}
+	for i := first; i < len(c.History); i++ {
+		h := &c.History[i]
+		h.PrevHash, h.TxID, h.SubmittedBy = previous, ctx.GetStub().GetTxID(), submitter
+		h.Hash = historyHash(*h)
+		previous = h.Hash
+	}
+	return nil
+}
+
+// VerifyHistory recomputes c's history chain. Entries without a hash are only accepted
+// at the start of the history of a case not stored since chaining began, which has a
+// schema version before 3 or no hash chain at all. Since version 15 the chain must also
+// end at the head recorded on the case proof.
+func VerifyHistory(c *Case) *HistoryVerification {
+	result := &HistoryVerification{CaseID: c.ID, Valid: true, Entries: len(c.History), BrokenAt: -1}
+	first := 0
+	if c.SchemaVersion < chainedHistoryVersion || len(c.HashChain) == 0 {
+		for first < len(c.History) && c.History[first].Hash == "" && c.History[first].PrevHash == "" {
+			first++
+		}
+	}
+	result.Chained = len(c.History) - first
+
+	previous := ""
+	for i := first; i < len(c.History); i++ {
+		h := c.History[i]
+		reason := ""
+		switch {
+		case h.Hash == "":
+			reason = "entry is not chained"
+		case h.PrevHash != previous:
+			reason = "entry does not link to the entry before it"
+		case h.Hash != historyHash(h):
+			reason = "entry does not match its hash"
+		}
+		if reason != "" {
+			result.Valid, result.BrokenAt, result.Reason = false, i, reason
+			return result
+		}
+		previous = h.Hash
+	}
+
+	if c.SchemaVersion >= historyHeadVersion && len(c.HashChain) > 0 {
+		switch {
+		case c.Proof == nil:
+			result.Valid, result.BrokenAt, result.Reason = false, len(c.History)-1, "case has no proof to anchor its history"
+		case c.Proof.HistoryHead != previous:
+			result.Valid, result.BrokenAt, result.Reason = false, len(c.History)-1, "history does not end at the head recorded on the case proof"
+		}
+	}
+	return result
+}
+
+// VerifyCaseHistory reads a case from the world state and checks its history chain
+func VerifyCaseHistory(ctx contractapi.TransactionContextInterface, caseID string) (*HistoryVerification, error) {
+	caseJSON, err := ctx.GetStub().GetState(caseID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read case: %v", err)
+	}
+	if caseJSON == nil {
+		return nil, fmt.Errorf("case does not exist: %s", caseID)
+	}
+	c, err := DecodeCase(caseJSON)
+	if err != nil {
+		return nil, err
+	}
+	return VerifyHistory(c), nil
+}
@@ -0,0 +1,116 @@
+package casemodel
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"casemodel/mockstub"
+)
+
+func TestSealChainsHistory(t *testing.T) {
+	n := mockstub.NewNetwork(time.Date(2024, time.May, 15, 10, 0, 0, 0, time.UTC))
+	n.Install("sealer", sealer)
+	c := &Case{ID: "CASE_001", History: []HistoryItem{{Status: "CREATED"}, {Status: "SUBMITTED_TO_REGISTRAR"}}}
+
+	first := seal(t, n, ChannelBenchClerkJudge, judge, "seal", c)
+	first.History = append(first.History, HistoryItem{Status: "JUDGMENT_RECEIVED", Organization: OrgBenchClerks})
+	second := seal(t, n, ChannelBenchClerkJudge, benchClerk, "seal", first)
+
+	if v := VerifyHistory(second); !v.Valid || v.Chained != 3 || v.BrokenAt != -1 {
+		t.Fatalf("verification = %+v", v)
+	}
+	h := second.History
+	if h[0].PrevHash != "" || h[1].PrevHash != h[0].Hash || h[2].PrevHash != h[1].Hash {
+		t.Errorf("history is not linked: %+v", h)
+	}
+	if h[0].TxID != first.Proof.TxID || h[1].TxID != first.Proof.TxID || h[2].TxID != second.Proof.TxID {
+		t.Errorf("transactions = %s, %s, %s", h[0].TxID, h[1].TxID, h[2].TxID)
+	}
+	if h[0].SubmittedBy == "" || h[0].SubmittedBy == h[2].SubmittedBy {
+		t.Errorf("submitters = %q, %q", h[0].SubmittedBy, h[2].SubmittedBy)
+	}
+}
+
+func TestVerifyHistoryFindsFirstBrokenEntry(t *testing.T) {
+	n := mockstub.NewNetwork(time.Date(2024, time.May, 15, 10, 0, 0, 0, time.UTC))
+	n.Install("sealer", sealer)
+	sealed := seal(t, n, ChannelBenchClerkJudge, judge, "seal", &Case{ID: "CASE_001", History: []HistoryItem{
+		{Status: "CREATED", Comments: "filed"},
+		{Status: "SUBMITTED_TO_REGISTRAR"},
+		{Status: "VERIFIED_BY_REGISTRAR"},
+	}})
+
+	tests := []struct {
+		name     string
+		tamper   func(c *Case)
+		brokenAt int
+		reason   string
+	}{
+		{"untouched", func(c *Case) {}, -1, ""},
+		{"edited comments", func(c *Case) { c.History[0].Comments = "withdrawn" }, 0, "entry does not match its hash"},
+		{"removed entry", func(c *Case) { c.History = append(c.History[:1], c.History[2:]...) }, 1, "entry does not link to the entry before it"},
+		{"unchained entry", func(c *Case) { c.History[1].Hash = "" }, 1, "entry is not chained"},
+		{"appended without storing", func(c *Case) { c.History = append(c.History, HistoryItem{Status: "CLOSED"}) }, 3, "entry is not chained"},
+		{"rewritten entry", func(c *Case) {
+			c.History[1].Status = "REJECTED_BY_REGISTRAR"
+			c.History[1].Hash = historyHash(c.History[1])
+		}, 2, "entry does not link to the entry before it"},
+		{"every hash stripped", func(c *Case) {
+			for i := range c.History {
+				c.History[i].PrevHash, c.History[i].Hash = "", ""
+			}
+		}, 0, "entry is not chained"},
+		{"rechained from a rewritten entry", func(c *Case) {
+			c.History[1].Status = "REJECTED_BY_REGISTRAR"
+			for i := 1; i < len(c.History); i++ {
+				c.History[i].PrevHash = c.History[i-1].Hash
+				c.History[i].Hash = historyHash(c.History[i])
+			}
+		}, 2, "history does not end at the head recorded on the case proof"},
+		{"truncated", func(c *Case) { c.History = c.History[:2] }, 1, "history does not end at the head recorded on the case proof"},
+		{"proof removed", func(c *Case) { c.Proof = nil }, 2, "case has no proof to anchor its history"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value, _ := json.Marshal(sealed)
+			c, _ := DecodeCase(value)
+			tt.tamper(c)
+			v := VerifyHistory(c)
+			if v.BrokenAt != tt.brokenAt || v.Reason != tt.reason || v.Valid != (tt.brokenAt == -1) {
+				t.Errorf("verification = %+v, want broken at %d: %q", v, tt.brokenAt, tt.reason)
+			}
+		})
+	}
+}
+
+func TestVerifyHistoryAcceptsEntriesBeforeChaining(t *testing.T) {
+	n := mockstub.NewNetwork(time.Date(2024, time.May, 15, 10, 0, 0, 0, time.UTC))
+	n.Install("sealer", sealer)
+	legacy, err := DecodeCase([]byte(`{"id":"CASE_001","schemaVersion":2,"history":[{"status":"CREATED"},{"status":"SUBMITTED_TO_REGISTRAR"}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := VerifyHistory(legacy); !v.Valid || v.Chained != 0 || v.Entries != 2 {
+		t.Errorf("legacy verification = %+v", v)
+	}
+
+	sealed := seal(t, n, ChannelBenchClerkJudge, judge, "seal", legacy)
+	sealed.History = append(sealed.History, HistoryItem{Status: "VERIFIED_BY_REGISTRAR"})
+	sealed = seal(t, n, ChannelBenchClerkJudge, judge, "seal", sealed)
+	if v := VerifyHistory(sealed); !v.Valid || v.Chained != 3 {
+		t.Errorf("verification = %+v", v)
+	}
+	if sealed.Proof.HistoryHead != sealed.History[2].Hash {
+		t.Errorf("history head = %q", sealed.Proof.HistoryHead)
+	}
+
+	// Once stored since chaining began a case may no longer carry entries without a hash
+	stripped, err := DecodeCase([]byte(`{"id":"CASE_001","schemaVersion":3,"history":[{"status":"CREATED"}],"hashChain":[{"caseHash":"a","hash":"b","txId":"tx1"}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := VerifyHistory(stripped); v.Valid || v.BrokenAt != 0 || v.Reason != "entry is not chained" {
+		t.Errorf("stripped verification = %+v", v)
+	}
+}
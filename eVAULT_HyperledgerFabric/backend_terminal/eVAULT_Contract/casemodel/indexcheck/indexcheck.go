@@ -0,0 +1,407 @@
+// Package indexcheck verifies that every CouchDB selector written in a chaincode's Go
+// source can be served by one of the indexes packaged under
+// META-INF/statedb/couchdb/indexes. Selectors are read from JSON string literals and
+// from selectors built inline in calls to query.New from casemodel/query.
+//
+// An index serves a selector when all of the index's fields are constrained by the
+// selector. Fields compared with $ne, $nin, $regex, $not or $exists:false cannot use an
+// index and are ignored. A selector whose only indexable fields sit under $or needs an
+// index for each branch. Optional conditions (query.EqIfSet, query.Range) and
+// selectors built elsewhere and passed to query.And only narrow a query, so they are
+// not relied on. Array fields matched with $elemMatch are indexed on the array
+// field itself, since CouchDB JSON indexes do not reach into array elements.
+package indexcheck
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// IndexDir is where Fabric expects CouchDB indexes inside a chaincode package
+const IndexDir = "META-INF/statedb/couchdb/indexes"
+
+// Selector is a CouchDB selector found in Go source
+type Selector struct {
+	Pos string
+	// FieldSets lists the sets of fields an index must be chosen from. Each set needs
+	// its own index.
+	FieldSets [][]string
+}
+
+// Index is a CouchDB index definition
+type Index struct {
+	File   string
+	Name   string
+	Fields []string
+}
+
+// formatVerb matches the fmt verbs used to build selectors with fmt.Sprintf
+var formatVerb = regexp.MustCompile(`%[-+# 0-9.]*[a-zA-Z]`)
+
+// queryPackage is the import path of the selector builder
+const queryPackage = "casemodel/query"
+
+// Selectors parses the Go files in dir and its subdirectories, excluding tests, and
+// returns every string literal that is a CouchDB query with a selector and every
+// query.New call
+func Selectors(dir string) ([]Selector, error) {
+	fset := token.NewFileSet()
+	files, err := goFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+	// Positions are reported relative to dir
+	relative := func(pos token.Position) token.Position {
+		if rel, err := filepath.Rel(dir, pos.Filename); err == nil {
+			pos.Filename = filepath.ToSlash(rel)
+		}
+		return pos
+	}
+
+	var selectors []Selector
+	for _, file := range files {
+		if strings.HasSuffix(file, "_test.go") {
+			continue
+		}
+		parsed, err := parser.ParseFile(fset, file, nil, 0)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %v", file, err)
+		}
+
+		builder := importName(parsed, queryPackage)
+		var parseErr error
+		ast.Inspect(parsed, func(n ast.Node) bool {
+			if parseErr != nil {
+				return false
+			}
+			if call, ok := n.(*ast.CallExpr); ok && builder != "" && isCall(call, builder, "New") {
+				pos := relative(fset.Position(call.Pos()))
+				if len(call.Args) != 1 {
+					parseErr = fmt.Errorf("%s: query.New takes one selector", pos)
+					return false
+				}
+				selector, err := builtSelector(fset, relative, call.Args[0], builder)
+				if err != nil {
+					parseErr = err
+					return false
+				}
+				selectors = append(selectors, Selector{Pos: pos.String(), FieldSets: fieldSets(selector)})
+				return true
+			}
+			lit, ok := n.(*ast.BasicLit)
+			if !ok || lit.Kind != token.STRING {
+				return true
+			}
+			value, err := strconv.Unquote(lit.Value)
+			if err != nil || !strings.Contains(value, `"selector"`) {
+				return true
+			}
+			pos := relative(fset.Position(lit.Pos()))
+
+			var query struct {
+				Selector map[string]interface{} `json:"selector"`
+			}
+			if err := json.Unmarshal([]byte(formatVerb.ReplaceAllString(value, "x")), &query); err != nil {
+				parseErr = fmt.Errorf("%s: selector is not valid JSON: %v", pos, err)
+				return true
+			}
+			selectors = append(selectors, Selector{Pos: pos.String(), FieldSets: fieldSets(query.Selector)})
+			return true
+		})
+		if parseErr != nil {
+			return nil, parseErr
+		}
+	}
+	return selectors, nil
+}
+
+// goFiles returns the Go files under dir, skipping the chaincode's META-INF, vendored
+// code and test data
+func goFiles(dir string) ([]string, error) {
+	var files []string
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			switch entry.Name() {
+			case "META-INF", "vendor", "testdata":
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if strings.HasSuffix(path, ".go") {
+			files = append(files, path)
+		}
+		return nil
+	})
+	return files, err
+}
+
+// importName returns the name a file uses for an imported package, or "" if the file
+// does not import it
+func importName(file *ast.File, path string) string {
+	for _, spec := range file.Imports {
+		if importPath, _ := strconv.Unquote(spec.Path.Value); importPath == path {
+			if spec.Name != nil {
+				return spec.Name.Name
+			}
+			return filepath.Base(path)
+		}
+	}
+	return ""
+}
+
+// isCall reports whether call calls function from the package imported as pkg
+func isCall(call *ast.CallExpr, pkg string, function string) bool {
+	selector, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok || selector.Sel.Name != function {
+		return false
+	}
+	ident, ok := selector.X.(*ast.Ident)
+	return ok && ident.Name == pkg
+}
+
+// builtSelector turns a selector built with the query package into the JSON selector
+// it produces. Values are not needed to find the fields, so they are left empty.
+func builtSelector(fset *token.FileSet, relative func(token.Position) token.Position, expr ast.Expr, pkg string) (map[string]interface{}, error) {
+	call, ok := expr.(*ast.CallExpr)
+	fail := func(reason string) (map[string]interface{}, error) {
+		return nil, fmt.Errorf("%s: %s", relative(fset.Position(expr.Pos())), reason)
+	}
+	if !ok {
+		return fail("selectors passed to query.New must be built inline so their fields can be checked")
+	}
+	selector, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok || !isIdent(selector.X, pkg) {
+		return fail("selectors passed to query.New must be built inline so their fields can be checked")
+	}
+	if call.Ellipsis.IsValid() {
+		return fail("selectors cannot be spread into query.And or query.Or")
+	}
+
+	switch function := selector.Sel.Name; function {
+	case "And", "Or":
+		var branches []interface{}
+		for _, arg := range call.Args {
+			if function == "And" && !isPackageCall(arg, pkg) {
+				continue
+			}
+			branch, err := builtSelector(fset, relative, arg, pkg)
+			if err != nil {
+				return nil, err
+			}
+			branches = append(branches, branch)
+		}
+		return map[string]interface{}{"$" + strings.ToLower(function): branches}, nil
+	}
+
+	if len(call.Args) == 0 {
+		return fail("missing field name")
+	}
+	lit, ok := call.Args[0].(*ast.BasicLit)
+	if !ok || lit.Kind != token.STRING {
+		return fail("field names must be string literals")
+	}
+	name, _ := strconv.Unquote(lit.Value)
+
+	var condition interface{}
+	switch selector.Sel.Name {
+	case "Eq":
+		condition = ""
+	case "EqIfSet", "Range":
+		// Optional fields may be missing from the query, so they cannot be relied on
+		return map[string]interface{}{}, nil
+	case "In":
+		condition = map[string]interface{}{"$in": []interface{}{}}
+	case "Includes", "ElemMatch":
+		condition = map[string]interface{}{"$elemMatch": map[string]interface{}{}}
+	case "Exists":
+		exists := len(call.Args) < 2 || !isIdent(call.Args[1], "false")
+		condition = map[string]interface{}{"$exists": exists}
+	case "Ne", "NotNull":
+		condition = map[string]interface{}{"$ne": nil}
+	case "Regex":
+		condition = map[string]interface{}{"$regex": ""}
+	default:
+		return fail("unknown selector function query." + selector.Sel.Name)
+	}
+	return map[string]interface{}{name: condition}, nil
+}
+
+// isPackageCall reports whether expr calls a function of the package imported as pkg
+func isPackageCall(expr ast.Expr, pkg string) bool {
+	call, ok := expr.(*ast.CallExpr)
+	if !ok {
+		return false
+	}
+	selector, ok := call.Fun.(*ast.SelectorExpr)
+	return ok && isIdent(selector.X, pkg)
+}
+
+func isIdent(expr ast.Expr, name string) bool {
+	ident, ok := expr.(*ast.Ident)
+	return ok && ident.Name == name
+}
+
+// fieldSets returns the sets of indexable fields constrained by a selector
+func fieldSets(selector map[string]interface{}) [][]string {
+	fields, branches := splitFields(selector)
+	if len(fields) > 0 {
+		sort.Strings(fields)
+		return [][]string{fields}
+	}
+	return branches
+}
+
+// splitFields returns the fields a selector constrains directly and the field sets of
+// its $or branches
+func splitFields(selector map[string]interface{}) ([]string, [][]string) {
+	var fields []string
+	var branches [][]string
+	for key, condition := range selector {
+		switch key {
+		case "$and":
+			for _, branch := range subSelectors(condition) {
+				branchFields, branchSets := splitFields(branch)
+				fields = append(fields, branchFields...)
+				branches = append(branches, branchSets...)
+			}
+		case "$or":
+			for _, branch := range subSelectors(condition) {
+				branches = append(branches, fieldSets(branch)...)
+			}
+		default:
+			if !strings.HasPrefix(key, "$") && indexable(condition) {
+				fields = append(fields, key)
+			}
+		}
+	}
+	return fields, branches
+}
+
+func subSelectors(condition interface{}) []map[string]interface{} {
+	list, _ := condition.([]interface{})
+	var selectors []map[string]interface{}
+	for _, item := range list {
+		if selector, ok := item.(map[string]interface{}); ok {
+			selectors = append(selectors, selector)
+		}
+	}
+	return selectors
+}
+
+// indexable reports whether a field condition can be answered from an index
+func indexable(condition interface{}) bool {
+	operators, ok := condition.(map[string]interface{})
+	if !ok {
+		return true
+	}
+	for operator, operand := range operators {
+		switch operator {
+		case "$ne", "$nin", "$regex", "$not":
+			return false
+		case "$exists":
+			if operand == false {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// Indexes reads the index definitions packaged with the chaincode in dir
+func Indexes(dir string) ([]Index, error) {
+	files, err := filepath.Glob(filepath.Join(dir, IndexDir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	var indexes []Index
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		var definition struct {
+			Index struct {
+				Fields []interface{} `json:"fields"`
+			} `json:"index"`
+			Name string `json:"name"`
+			Type string `json:"type"`
+		}
+		if err := json.Unmarshal(data, &definition); err != nil {
+			return nil, fmt.Errorf("failed to parse index %s: %v", file, err)
+		}
+		if definition.Type != "json" || definition.Name == "" || len(definition.Index.Fields) == 0 {
+			return nil, fmt.Errorf("index %s must be a named json index with at least one field", file)
+		}
+
+		index := Index{File: filepath.Base(file), Name: definition.Name}
+		for _, field := range definition.Index.Fields {
+			switch field := field.(type) {
+			case string:
+				index.Fields = append(index.Fields, field)
+			case map[string]interface{}:
+				// Sorted fields are written as {"field": "asc"}
+				for name := range field {
+					index.Fields = append(index.Fields, name)
+				}
+			}
+		}
+		indexes = append(indexes, index)
+	}
+	return indexes, nil
+}
+
+// Check returns a description of every selector in dir that no packaged index serves
+func Check(dir string) ([]string, error) {
+	selectors, err := Selectors(dir)
+	if err != nil {
+		return nil, err
+	}
+	indexes, err := Indexes(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var problems []string
+	for _, selector := range selectors {
+		for _, fields := range selector.FieldSets {
+			if !served(fields, indexes) {
+				problems = append(problems, fmt.Sprintf("%s: no index in %s covers fields %v", selector.Pos, IndexDir, fields))
+			}
+		}
+	}
+	return problems, nil
+}
+
+func served(fields []string, indexes []Index) bool {
+	constrained := make(map[string]bool)
+	for _, field := range fields {
+		constrained[field] = true
+	}
+	for _, index := range indexes {
+		usable := true
+		for _, field := range index.Fields {
+			if !constrained[field] {
+				usable = false
+				break
+			}
+		}
+		if usable {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,173 @@
+package indexcheck
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const source = "package main\n\nimport \"fmt\"\n\n" +
+	"const pending = `{\"selector\":{\"status\":\"PENDING\",\"currentOrg\":\"RegistrarsOrg\"}}`\n\n" +
+	"func byType(caseType string) string {\n" +
+	"\treturn fmt.Sprintf(`{\"selector\":{\"status\":\"PENDING\",\"type\":\"%s\"}}`, caseType)\n}\n\n" +
+	"const either = `{\"selector\":{\"$or\":[{\"createdBy\":\"L1\"},{\"hearings\":{\"$elemMatch\":{\"status\":\"SCHEDULED\"}}}]}}`\n\n" +
+	"const judged = `{\"selector\":{\"judgment\":{\"$ne\":null}}}`\n"
+
+func writeChaincode(t *testing.T, indexes map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "cc.go"), []byte(source), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	indexDir := filepath.Join(dir, IndexDir)
+	if err := os.MkdirAll(indexDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range indexes {
+		if err := os.WriteFile(filepath.Join(indexDir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestSelectors(t *testing.T) {
+	selectors, err := Selectors(writeChaincode(t, nil))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := [][][]string{
+		{{"currentOrg", "status"}},
+		{{"status", "type"}},
+		{{"createdBy"}, {"hearings"}},
+		nil,
+	}
+	if len(selectors) != len(want) {
+		t.Fatalf("found %d selectors, want %d", len(selectors), len(want))
+	}
+	for i, selector := range selectors {
+		if !reflect.DeepEqual(selector.FieldSets, want[i]) {
+			t.Errorf("%s: field sets = %v, want %v", selector.Pos, selector.FieldSets, want[i])
+		}
+	}
+}
+
+func TestSelectorsInSubpackages(t *testing.T) {
+	dir := writeChaincode(t, nil)
+	pkg := filepath.Join(dir, "chaincode")
+	if err := os.MkdirAll(pkg, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	sub := "package chaincode\n\nconst lawyer = `{\"selector\":{\"createdBy\":\"L1\"}}`\n"
+	if err := os.WriteFile(filepath.Join(pkg, "cc.go"), []byte(sub), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	selectors, err := Selectors(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(selectors) != 5 {
+		t.Fatalf("found %d selectors, want 5", len(selectors))
+	}
+	if last := selectors[4]; !strings.HasPrefix(last.Pos, "chaincode/cc.go:") || !reflect.DeepEqual(last.FieldSets, [][]string{{"createdBy"}}) {
+		t.Errorf("subpackage selector = %+v", last)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	status := `{"index":{"fields":["status"]},"ddoc":"indexStatusDoc","name":"indexStatus","type":"json"}`
+	createdBy := `{"index":{"fields":[{"createdBy":"asc"}]},"ddoc":"indexCreatedByDoc","name":"indexCreatedBy","type":"json"}`
+	hearings := `{"index":{"fields":["hearings"]},"ddoc":"indexHearingsDoc","name":"indexHearings","type":"json"}`
+
+	problems, err := Check(writeChaincode(t, map[string]string{"a.json": status, "b.json": createdBy, "c.json": hearings}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(problems) != 0 {
+		t.Errorf("unexpected problems: %v", problems)
+	}
+
+	problems, err = Check(writeChaincode(t, map[string]string{"a.json": status, "b.json": createdBy}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(problems) != 1 || !strings.Contains(problems[0], "[hearings]") {
+		t.Errorf("expected the hearings branch to be reported, got %v", problems)
+	}
+
+	if _, err := Check(writeChaincode(t, map[string]string{"a.json": `{"index":{"fields":[]},"type":"json"}`})); err == nil {
+		t.Error("expected an error for an index without fields")
+	}
+}
+
+const builderSource = `package main
+
+import q "casemodel/query"
+
+func pending(caseType string) string {
+	return q.New(q.And(q.Eq("status", "P"), q.Eq("currentOrg", "RegistrarsOrg"), q.EqIfSet("type", caseType))).String()
+}
+
+func byLawyer(lawyerID string) string {
+	return q.New(q.Or(q.Includes("associatedLawyers", lawyerID), q.Eq("createdBy", lawyerID))).String()
+}
+
+func scheduled(filter q.Selector) string {
+	return q.New(q.And(q.In("status", "A", "B"), q.Or(q.Eq("currentOrg", "JudgesOrg"), q.Exists("hearings", false)), filter)).String()
+}
+
+func judged() string {
+	return q.New(q.NotNull("judgment")).String()
+}
+`
+
+func TestBuiltSelectors(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "cc.go"), []byte(builderSource), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	selectors, err := Selectors(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := [][][]string{
+		{{"currentOrg", "status"}},
+		{{"associatedLawyers"}, {"createdBy"}},
+		{{"status"}},
+		nil,
+	}
+	if len(selectors) != len(want) {
+		t.Fatalf("found %d selectors, want %d", len(selectors), len(want))
+	}
+	for i, selector := range selectors {
+		if !reflect.DeepEqual(selector.FieldSets, want[i]) {
+			t.Errorf("%s: field sets = %v, want %v", selector.Pos, selector.FieldSets, want[i])
+		}
+	}
+}
+
+func TestBuiltSelectorsMustBeInline(t *testing.T) {
+	sources := map[string]string{
+		"variable": "selector := query.Eq(\"status\", \"P\")\n\treturn query.New(selector).String()",
+		"spread":   "return query.New(query.And(conditions...)).String()",
+		"or":       "return query.New(query.Or(query.Eq(\"status\", \"P\"), selector)).String()",
+		"field":    "return query.New(query.Eq(statusField, \"P\")).String()",
+	}
+	for name, body := range sources {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			source := "package main\n\nimport \"casemodel/query\"\n\nfunc build() string {\n\t" + body + "\n}\n"
+			if err := os.WriteFile(filepath.Join(dir, "cc.go"), []byte(source), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := Selectors(dir); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package casemodel
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/hyperledger/fabric-protos-go/peer"
+)
+
+// InvokingChaincode returns the chaincode and transaction named in the client's signed
+// proposal. When a transaction arrives through InvokeChaincode these identify the
+// chaincode that made the call rather than the one that is running.
+func InvokingChaincode(ctx contractapi.TransactionContextInterface) (string, string, error) {
+	signedProposal, err := ctx.GetStub().GetSignedProposal()
+	if err != nil {
+		return "", "", fmt.Errorf("failed to get signed proposal: %v", err)
+	}
+	if signedProposal == nil {
+		return "", "", fmt.Errorf("transaction has no signed proposal")
+	}
+
+	proposal := &peer.Proposal{}
+	if err := proto.Unmarshal(signedProposal.ProposalBytes, proposal); err != nil {
+		return "", "", fmt.Errorf("failed to unmarshal proposal: %v", err)
+	}
+	payload := &peer.ChaincodeProposalPayload{}
+	if err := proto.Unmarshal(proposal.Payload, payload); err != nil {
+		return "", "", fmt.Errorf("failed to unmarshal proposal payload: %v", err)
+	}
+	invocation := &peer.ChaincodeInvocationSpec{}
+	if err := proto.Unmarshal(payload.Input, invocation); err != nil {
+		return "", "", fmt.Errorf("failed to unmarshal invocation spec: %v", err)
+	}
+
+	spec := invocation.GetChaincodeSpec()
+	function := ""
+	if args := spec.GetInput().GetArgs(); len(args) > 0 {
+		function = string(args[0])
+		if i := strings.LastIndex(function, ":"); i >= 0 {
+			function = function[i+1:]
+		}
+	}
+	return spec.GetChaincodeId().GetName(), function, nil
+}
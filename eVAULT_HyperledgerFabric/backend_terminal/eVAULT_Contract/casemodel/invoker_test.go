@@ -0,0 +1,77 @@
+package casemodel
+
+import (
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/hyperledger/fabric-protos-go/peer"
+)
+
+// proposalStub only serves a signed proposal
+type proposalStub struct {
+	shim.ChaincodeStubInterface
+	proposal *peer.SignedProposal
+}
+
+func (p proposalStub) GetSignedProposal() (*peer.SignedProposal, error) { return p.proposal, nil }
+
+func signedProposalFor(t *testing.T, chaincode string, args ...string) *peer.SignedProposal {
+	t.Helper()
+	input := &peer.ChaincodeInput{}
+	for _, arg := range args {
+		input.Args = append(input.Args, []byte(arg))
+	}
+	invocation, err := proto.Marshal(&peer.ChaincodeInvocationSpec{
+		ChaincodeSpec: &peer.ChaincodeSpec{
+			ChaincodeId: &peer.ChaincodeID{Name: chaincode},
+			Input:       input,
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	payload, err := proto.Marshal(&peer.ChaincodeProposalPayload{Input: invocation})
+	if err != nil {
+		t.Fatal(err)
+	}
+	proposal, err := proto.Marshal(&peer.Proposal{Payload: payload})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &peer.SignedProposal{ProposalBytes: proposal}
+}
+
+func TestInvokingChaincode(t *testing.T) {
+	tests := []struct {
+		name         string
+		args         []string
+		wantFunction string
+	}{
+		{"plain function", []string{"FetchAndStoreCaseFromLawyerChannel", "CASE_001"}, "FetchAndStoreCaseFromLawyerChannel"},
+		{"contract prefixed function", []string{"RegistrarContract:SyncCase", "{}"}, "SyncCase"},
+		{"no arguments", nil, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := new(contractapi.TransactionContext)
+			ctx.SetStub(proposalStub{proposal: signedProposalFor(t, "registrar", tt.args...)})
+
+			chaincode, function, err := InvokingChaincode(ctx)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if chaincode != "registrar" || function != tt.wantFunction {
+				t.Errorf("got (%q, %q), want (%q, %q)", chaincode, function, "registrar", tt.wantFunction)
+			}
+		})
+	}
+
+	ctx := new(contractapi.TransactionContext)
+	ctx.SetStub(proposalStub{})
+	if _, _, err := InvokingChaincode(ctx); err == nil {
+		t.Error("expected an error without a signed proposal")
+	}
+}
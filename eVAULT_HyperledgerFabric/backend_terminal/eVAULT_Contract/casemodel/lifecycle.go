@@ -0,0 +1,242 @@
+package casemodel
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// Organizations that take part in the case lifecycle
+const (
+	OrgLawyers        = "LawyersOrg"
+	OrgRegistrars     = "RegistrarsOrg"
+	OrgStampReporters = "StampReportersOrg"
+	OrgBenchClerks    = "BenchClerksOrg"
+	OrgJudges         = "JudgesOrg"
+)
+
+// Case statuses
+const (
+	StatusCreated                    = "CREATED"
+	StatusPendingRegistrarReview     = "PENDING_REGISTRAR_REVIEW"
+	StatusVerifiedByRegistrar        = "VERIFIED_BY_REGISTRAR"
+	StatusRejectedByRegistrar        = "REJECTED_BY_REGISTRAR"
+	StatusTransferredToStampReporter = "TRANSFERRED_TO_STAMPREPORTER"
+	StatusPendingStampReporterReview = "PENDING_STAMP_REPORTER_REVIEW"
+	StatusValidatedByStampReporter   = "VALIDATED_BY_STAMP_REPORTER"
+	StatusRejectedByStampReporter    = "REJECTED_BY_STAMP_REPORTER"
+	StatusOnHoldByStampReporter      = "ON_HOLD_BY_STAMP_REPORTER"
+	StatusForwardedToBenchClerk      = "FORWARDED_TO_BENCHCLERK"
+	StatusForwardedToLawyerRejected  = "FORWARDED_TO_LAWYER_REJECTED"
+	StatusForwardedToLawyerOnHold    = "FORWARDED_TO_LAWYER_ON_HOLD"
+	StatusRejectionReceived          = "REJECTION_RECEIVED"
+	StatusOnHoldReceived             = "ON_HOLD_RECEIVED"
+	StatusPendingJudgeReview         = "PENDING_JUDGE_REVIEW"
+	StatusReceivedByJudge            = "RECEIVED_BY_JUDGE"
+	StatusJudgmentIssued             = "JUDGMENT_ISSUED"
+	StatusJudgmentReceived           = "JUDGMENT_RECEIVED"
+	StatusDecisionConfirmed          = "DECISION_CONFIRMED"
+)
+
+// Transition is a status change an organization is allowed to make
+type Transition struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	Org  string `json:"org"`
+}
+
+// transitions is the case lifecycle. Any status change not listed here is rejected.
+var transitions = []Transition{
+	// Filing
+	{StatusCreated, StatusPendingRegistrarReview, OrgLawyers},
+	{StatusRejectedByRegistrar, StatusPendingRegistrarReview, OrgLawyers}, // resubmission, see Resubmit
+
+	// Registrar review
+	{StatusPendingRegistrarReview, StatusVerifiedByRegistrar, OrgRegistrars},
+	{StatusPendingRegistrarReview, StatusRejectedByRegistrar, OrgRegistrars},
+	{StatusVerifiedByRegistrar, StatusTransferredToStampReporter, OrgRegistrars},
+	{StatusVerifiedByRegistrar, StatusPendingStampReporterReview, OrgRegistrars},
+
+	// Stamp reporter validation
+	{StatusPendingStampReporterReview, StatusValidatedByStampReporter, OrgStampReporters},
+	{StatusPendingStampReporterReview, StatusRejectedByStampReporter, OrgStampReporters},
+	{StatusPendingStampReporterReview, StatusOnHoldByStampReporter, OrgStampReporters},
+	{StatusValidatedByStampReporter, StatusForwardedToBenchClerk, OrgStampReporters},
+	{StatusRejectedByStampReporter, StatusForwardedToLawyerRejected, OrgStampReporters},
+	{StatusOnHoldByStampReporter, StatusForwardedToLawyerOnHold, OrgStampReporters},
+
+	// Lawyer acknowledges a returned case
+	{StatusRejectedByStampReporter, StatusRejectionReceived, OrgLawyers},
+	{StatusForwardedToLawyerRejected, StatusRejectionReceived, OrgLawyers},
+	{StatusOnHoldByStampReporter, StatusOnHoldReceived, OrgLawyers},
+	{StatusForwardedToLawyerOnHold, StatusOnHoldReceived, OrgLawyers},
+
+	// Lawyer cures the defects of an on-hold case and returns it to the stamp reporter
+	{StatusForwardedToLawyerOnHold, StatusPendingStampReporterReview, OrgLawyers},
+	{StatusOnHoldReceived, StatusPendingStampReporterReview, OrgLawyers},
+
+	// Lawyer replaces the rejected documents of a rejected case and resubmits it to the
+	// stamp reporter
+	{StatusForwardedToLawyerRejected, StatusPendingStampReporterReview, OrgLawyers},
+	{StatusRejectionReceived, StatusPendingStampReporterReview, OrgLawyers},
+
+	// Bench clerk assigns a judge
+	{StatusValidatedByStampReporter, StatusPendingJudgeReview, OrgBenchClerks},
+	{StatusForwardedToBenchClerk, StatusPendingJudgeReview, OrgBenchClerks},
+
+	// Judgment
+	{StatusPendingJudgeReview, StatusReceivedByJudge, OrgJudges},
+	{StatusPendingJudgeReview, StatusJudgmentIssued, OrgJudges},
+	{StatusReceivedByJudge, StatusJudgmentIssued, OrgJudges},
+
+	// Bench clerk confirms the decision
+	{StatusJudgmentIssued, StatusJudgmentReceived, OrgBenchClerks},
+	{StatusJudgmentIssued, StatusDecisionConfirmed, OrgBenchClerks},
+	{StatusJudgmentReceived, StatusDecisionConfirmed, OrgBenchClerks},
+}
+
+// holders is the organization that holds a case once it reaches each status
+var holders = map[string]string{
+	StatusCreated:                    OrgLawyers,
+	StatusPendingRegistrarReview:     OrgRegistrars,
+	StatusVerifiedByRegistrar:        OrgRegistrars,
+	StatusRejectedByRegistrar:        OrgLawyers,
+	StatusTransferredToStampReporter: OrgStampReporters,
+	StatusPendingStampReporterReview: OrgStampReporters,
+	StatusValidatedByStampReporter:   OrgBenchClerks,
+	StatusRejectedByStampReporter:    OrgLawyers,
+	StatusOnHoldByStampReporter:      OrgLawyers,
+	StatusForwardedToBenchClerk:      OrgBenchClerks,
+	StatusForwardedToLawyerRejected:  OrgLawyers,
+	StatusForwardedToLawyerOnHold:    OrgLawyers,
+	StatusRejectionReceived:          OrgLawyers,
+	StatusOnHoldReceived:             OrgLawyers,
+	StatusPendingJudgeReview:         OrgJudges,
+	StatusReceivedByJudge:            OrgJudges,
+	StatusJudgmentIssued:             OrgBenchClerks,
+	StatusJudgmentReceived:           OrgBenchClerks,
+	StatusDecisionConfirmed:          OrgLawyers,
+}
+
+// TransitionError is returned when a status change is not allowed by the lifecycle
+type TransitionError struct {
+	CaseID string
+	From   string
+	To     string
+	Org    string
+}
+
+func (e *TransitionError) Error() string {
+	if e.Org == "" {
+		return fmt.Sprintf("case %s cannot move from %s to %s", e.CaseID, e.From, e.To)
+	}
+	return fmt.Sprintf("case %s cannot move from %s to %s by %s", e.CaseID, e.From, e.To, e.Org)
+}
+
+// OrgFromMSPID maps an MSP ID such as "JudgesOrgMSP" to its organization name
+func OrgFromMSPID(mspID string) string {
+	return strings.TrimSuffix(mspID, "MSP")
+}
+
+// ClientOrg returns the organization of the identity submitting the transaction
+func ClientOrg(ctx contractapi.TransactionContextInterface) (string, error) {
+	mspID, err := ctx.GetClientIdentity().GetMSPID()
+	if err != nil {
+		return "", fmt.Errorf("failed to get client MSP ID: %v", err)
+	}
+	return OrgFromMSPID(mspID), nil
+}
+
+// AllowedTransitions lists the transitions out of a status that org may make.
+// An empty org returns the transitions for every organization.
+func AllowedTransitions(status string, org string) []Transition {
+	allowed := make([]Transition, 0)
+	for _, t := range transitions {
+		if t.From == status && (org == "" || t.Org == org) {
+			allowed = append(allowed, t)
+		}
+	}
+	return allowed
+}
+
+// CheckTransition returns a *TransitionError unless org may move c to the given status
+func CheckTransition(c *Case, to string, org string) error {
+	for _, t := range transitions {
+		if t.From == c.Status && t.To == to && t.Org == org {
+			return nil
+		}
+	}
+	return &TransitionError{CaseID: c.ID, From: c.Status, To: to, Org: org}
+}
+
+// Holder returns the organization that holds a case in the given status, or "" for a
+// status outside the lifecycle
+func Holder(status string) string {
+	return holders[status]
+}
+
+// CheckProgress returns a *TransitionError unless the given status is c's current status
+// or can be reached from it through the lifecycle, whichever organizations act on the way.
+// It is used when a copy of a case arrives from another channel, to reject stale copies
+// that would move the case backwards.
+func CheckProgress(c *Case, to string) error {
+	if to == c.Status {
+		return nil
+	}
+	reached := map[string]bool{c.Status: true}
+	pending := []string{c.Status}
+	for len(pending) > 0 {
+		status := pending[0]
+		pending = pending[1:]
+		for _, t := range transitions {
+			if t.From != status || reached[t.To] {
+				continue
+			}
+			if t.To == to {
+				return nil
+			}
+			reached[t.To] = true
+			pending = append(pending, t.To)
+		}
+	}
+	return &TransitionError{CaseID: c.ID, From: c.Status, To: to}
+}
+
+// ApplyTransition moves c to the given status on behalf of the submitting organization,
+// hands it to the organization that holds cases in that status and records a
+// StatusChangedEvent for that organization's listeners, see TransactionContext
+func ApplyTransition(ctx contractapi.TransactionContextInterface, c *Case, to string) error {
+	org, err := ClientOrg(ctx)
+	if err != nil {
+		return err
+	}
+	if err := CheckTransition(c, to, org); err != nil {
+		return err
+	}
+	from := c.Status
+	c.Status = to
+	c.CurrentOrg = Holder(to)
+	return recordStatusChanged(ctx, c, from, org)
+}
+
+// GetAllowedTransitions reads a case from the world state and lists the transitions the
+// submitting organization may make from its current status
+func GetAllowedTransitions(ctx contractapi.TransactionContextInterface, caseID string) ([]Transition, error) {
+	caseJSON, err := ctx.GetStub().GetState(caseID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read case: %v", err)
+	}
+	if caseJSON == nil {
+		return nil, fmt.Errorf("case does not exist: %s", caseID)
+	}
+	c, err := DecodeCase(caseJSON)
+	if err != nil {
+		return nil, err
+	}
+	org, err := ClientOrg(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return AllowedTransitions(c.Status, org), nil
+}
@@ -0,0 +1,211 @@
+package casemodel
+
+import (
+	"crypto/x509"
+	"errors"
+	"testing"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// fakeIdentity is a client identity that only reports an MSP ID
+type fakeIdentity struct {
+	mspID string
+}
+
+func (f fakeIdentity) GetID() (string, error)                         { return "", nil }
+func (f fakeIdentity) GetMSPID() (string, error)                      { return f.mspID, nil }
+func (f fakeIdentity) GetAttributeValue(string) (string, bool, error) { return "", false, nil }
+func (f fakeIdentity) AssertAttributeValue(string, string) error      { return nil }
+func (f fakeIdentity) GetX509Certificate() (*x509.Certificate, error) { return nil, nil }
+
+// eventStub records the event set by a transaction
+type eventStub struct {
+	shim.ChaincodeStubInterface
+	name    string
+	payload []byte
+}
+
+func (e *eventStub) GetTxID() string { return "tx1" }
+func (e *eventStub) SetEvent(name string, payload []byte) error {
+	e.name, e.payload = name, payload
+	return nil
+}
+
+func contextFor(mspID string) *contractapi.TransactionContext {
+	ctx := new(contractapi.TransactionContext)
+	ctx.SetStub(&eventStub{})
+	ctx.SetClientIdentity(fakeIdentity{mspID: mspID})
+	return ctx
+}
+
+func TestHappyPathFollowsLifecycle(t *testing.T) {
+	steps := []struct {
+		to  string
+		org string
+	}{
+		{StatusPendingRegistrarReview, OrgLawyers},
+		{StatusVerifiedByRegistrar, OrgRegistrars},
+		{StatusPendingStampReporterReview, OrgRegistrars},
+		{StatusValidatedByStampReporter, OrgStampReporters},
+		{StatusForwardedToBenchClerk, OrgStampReporters},
+		{StatusPendingJudgeReview, OrgBenchClerks},
+		{StatusReceivedByJudge, OrgJudges},
+		{StatusJudgmentIssued, OrgJudges},
+		{StatusJudgmentReceived, OrgBenchClerks},
+		{StatusDecisionConfirmed, OrgBenchClerks},
+	}
+
+	c := &Case{ID: "CASE_001", Status: StatusCreated}
+	for _, step := range steps {
+		if err := ApplyTransition(contextFor(step.org+"MSP"), c, step.to); err != nil {
+			t.Fatalf("%s -> %s by %s: %v", c.Status, step.to, step.org, err)
+		}
+		if c.Status != step.to {
+			t.Fatalf("status = %s, want %s", c.Status, step.to)
+		}
+	}
+}
+
+func TestIllegalTransitionsAreRejected(t *testing.T) {
+	tests := []struct {
+		name string
+		from string
+		to   string
+		org  string
+	}{
+		{"resubmit pending case", StatusPendingRegistrarReview, StatusPendingRegistrarReview, OrgLawyers},
+		{"judgment before assignment", StatusForwardedToBenchClerk, StatusJudgmentIssued, OrgJudges},
+		{"wrong organization", StatusCreated, StatusPendingRegistrarReview, OrgJudges},
+		{"skip registrar review", StatusCreated, StatusVerifiedByRegistrar, OrgRegistrars},
+		{"reopen confirmed decision", StatusDecisionConfirmed, StatusPendingJudgeReview, OrgBenchClerks},
+		{"stamp reporter resubmits a rejected case", StatusRejectionReceived, StatusPendingStampReporterReview, OrgStampReporters},
+		{"unknown status", StatusCreated, "ARCHIVED", OrgLawyers},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Case{ID: "CASE_001", Status: tt.from}
+			err := ApplyTransition(contextFor(tt.org), c, tt.to)
+
+			var transitionErr *TransitionError
+			if !errors.As(err, &transitionErr) {
+				t.Fatalf("expected *TransitionError, got %v", err)
+			}
+			want := TransitionError{CaseID: "CASE_001", From: tt.from, To: tt.to, Org: tt.org}
+			if *transitionErr != want {
+				t.Errorf("error = %+v, want %+v", *transitionErr, want)
+			}
+			if c.Status != tt.from {
+				t.Errorf("status changed to %s on a rejected transition", c.Status)
+			}
+		})
+	}
+}
+
+func TestAllowedTransitions(t *testing.T) {
+	got := AllowedTransitions(StatusPendingStampReporterReview, OrgStampReporters)
+	want := map[string]bool{
+		StatusValidatedByStampReporter: true,
+		StatusRejectedByStampReporter:  true,
+		StatusOnHoldByStampReporter:    true,
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d transitions, want %d: %+v", len(got), len(want), got)
+	}
+	for _, tr := range got {
+		if !want[tr.To] || tr.Org != OrgStampReporters {
+			t.Errorf("unexpected transition %+v", tr)
+		}
+	}
+
+	if got := AllowedTransitions(StatusPendingStampReporterReview, OrgJudges); len(got) != 0 {
+		t.Errorf("judges should have no transitions from %s, got %+v", StatusPendingStampReporterReview, got)
+	}
+	if got := AllowedTransitions(StatusOnHoldReceived, OrgLawyers); len(got) != 1 || got[0].To != StatusPendingStampReporterReview {
+		t.Errorf("lawyers should only return an on-hold case for review, got %+v", got)
+	}
+	if got := AllowedTransitions(StatusRejectionReceived, OrgLawyers); len(got) != 1 || got[0].To != StatusPendingStampReporterReview {
+		t.Errorf("lawyers should only resubmit a case the stamp reporter rejected, got %+v", got)
+	}
+	if got := AllowedTransitions(StatusForwardedToLawyerRejected, OrgLawyers); len(got) != 2 || got[1].To != StatusPendingStampReporterReview {
+		t.Errorf("lawyers should acknowledge or resubmit a forwarded rejection, got %+v", got)
+	}
+	if got := AllowedTransitions(StatusRejectedByRegistrar, OrgLawyers); len(got) != 1 || got[0].To != StatusPendingRegistrarReview {
+		t.Errorf("lawyers should only resubmit a case the registrar rejected, got %+v", got)
+	}
+	if got := AllowedTransitions(StatusJudgmentIssued, ""); len(got) != 2 {
+		t.Errorf("expected 2 transitions out of %s for any org, got %+v", StatusJudgmentIssued, got)
+	}
+	if got := AllowedTransitions(StatusDecisionConfirmed, ""); got == nil || len(got) != 0 {
+		t.Errorf("expected an empty, non-nil list for a final status, got %#v", got)
+	}
+}
+
+func TestLifecycleTable(t *testing.T) {
+	seen := make(map[Transition]bool)
+	reachable := map[string]bool{StatusCreated: true}
+	for _, tr := range transitions {
+		if seen[tr] {
+			t.Errorf("duplicate transition %+v", tr)
+		}
+		seen[tr] = true
+		if tr.From == tr.To {
+			t.Errorf("self transition %+v", tr)
+		}
+		if Holder(tr.To) == "" {
+			t.Errorf("no holding organization for status %s", tr.To)
+		}
+	}
+
+	// Every status in the table must be reachable from CREATED
+	for changed := true; changed; {
+		changed = false
+		for _, tr := range transitions {
+			if reachable[tr.From] && !reachable[tr.To] {
+				reachable[tr.To] = true
+				changed = true
+			}
+		}
+	}
+	for _, tr := range transitions {
+		if !reachable[tr.From] {
+			t.Errorf("status %s is unreachable from %s", tr.From, StatusCreated)
+		}
+	}
+}
+
+func TestOrgFromMSPID(t *testing.T) {
+	for mspID, want := range map[string]string{
+		"JudgesOrgMSP": OrgJudges,
+		"JudgesOrg":    OrgJudges,
+		"LawyersOrg":   OrgLawyers,
+	} {
+		if got := OrgFromMSPID(mspID); got != want {
+			t.Errorf("OrgFromMSPID(%q) = %q, want %q", mspID, got, want)
+		}
+	}
+}
+
+func TestCheckProgress(t *testing.T) {
+	c := &Case{ID: "CASE_001", Status: StatusVerifiedByRegistrar}
+
+	for _, to := range []string{
+		StatusVerifiedByRegistrar,
+		StatusTransferredToStampReporter,
+		StatusPendingStampReporterReview,
+		StatusDecisionConfirmed,
+	} {
+		if err := CheckProgress(c, to); err != nil {
+			t.Errorf("%s -> %s should be forward progress: %v", c.Status, to, err)
+		}
+	}
+
+	for _, to := range []string{StatusCreated, StatusPendingRegistrarReview, StatusRejectedByRegistrar, "ARCHIVED"} {
+		var transitionErr *TransitionError
+		if err := CheckProgress(c, to); !errors.As(err, &transitionErr) {
+			t.Errorf("%s -> %s should be rejected as a regression, got %v", c.Status, to, err)
+		}
+	}
+}
@@ -0,0 +1,234 @@
+// Package contracttest runs table tests of a contract deployed on a mockstub network and
+// holds the helpers the contract tests share. It lives apart from mockstub because it
+// works with cases, and the casemodel tests import mockstub.
+package contracttest
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/hyperledger/fabric-protos-go/peer"
+
+	"casemodel"
+	"casemodel/mockstub"
+)
+
+// Contract is a contract under test and how every test deploys it
+type Contract struct {
+	Name     string   // chaincode name the contract is installed as
+	Channels []string // channels it runs on; tests run on the first unless they name another
+	New      func() contractapi.ContractInterface
+	Start    time.Time                 // clock of every network
+	Admin    mockstub.Identity         // administrator who changes the routing
+	Client   mockstub.Identity         // client who lists the outbox
+	Trusted  []string                  // organizations whose root CAs are trusted on every channel
+	Deploy   func(n *mockstub.Network) // further setup of a new network, such as collections
+}
+
+// TxTest is one transaction submitted to the contract
+type TxTest struct {
+	Name     string
+	Channel  string                            // defaults to the contract's first channel
+	Seed     []*casemodel.Case                 // cases on the contract's ledger on Channel before the call
+	Setup    func(n *mockstub.Network)         // runs after seeding, before the call
+	Peers    map[string]mockstub.ChaincodeFunc // fake chaincodes the contract invokes
+	Caller   mockstub.Identity
+	Function string
+	Args     []string
+	Details  string // private details passed in the transient field
+	WantErr  string // part of the expected error
+	Check    func(t *testing.T, n *mockstub.Network, payload []byte)
+}
+
+// NewNetwork installs the contract and the fake peers, with the default routes, the
+// trusted roots and clients that seal the cases they hand off
+func (c *Contract) NewNetwork(t *testing.T, peers map[string]mockstub.ChaincodeFunc) *mockstub.Network {
+	t.Helper()
+	cc, err := contractapi.NewChaincode(c.New())
+	if err != nil {
+		t.Fatal(err)
+	}
+	n := mockstub.NewNetwork(c.Start)
+	n.Install(c.Name, cc)
+	n.SignWrites = casemodel.SignCaseSeals
+	for _, channel := range c.Channels {
+		n.PutState(channel, c.Name, casemodel.RoutingKey, MustJSON(casemodel.DefaultRouting()))
+		for _, org := range c.Trusted {
+			n.PutState(channel, c.Name, casemodel.OrgRootsKey(org), []byte(mockstub.RootCertificate(org+"MSP")))
+		}
+	}
+	if c.Deploy != nil {
+		c.Deploy(n)
+	}
+	for name, fake := range peers {
+		n.Install(name, fake)
+	}
+	return n
+}
+
+// Run runs each test on a new network
+func (c *Contract) Run(t *testing.T, tests []TxTest) {
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			channel := tt.Channel
+			if channel == "" {
+				channel = c.Channels[0]
+			}
+			n := c.NewNetwork(t, tt.Peers)
+			c.Seed(n, channel, tt.Seed...)
+			if tt.Setup != nil {
+				tt.Setup(n)
+			}
+
+			var transient map[string][]byte
+			if tt.Details != "" {
+				transient = map[string][]byte{casemodel.TransientPrivateDetails: []byte(tt.Details)}
+			}
+			payload, err := n.SubmitTransient(channel, c.Name, tt.Caller, transient, tt.Function, tt.Args...)
+			if tt.WantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.WantErr) {
+					t.Fatalf("error = %v, want %q", err, tt.WantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.Check != nil {
+				tt.Check(t, n, payload)
+			}
+		})
+	}
+}
+
+// Seed puts cases on the contract's ledger on channel
+func (c *Contract) Seed(n *mockstub.Network, channel string, cases ...*casemodel.Case) {
+	for _, s := range cases {
+		n.PutState(channel, c.Name, s.ID, MustJSON(s))
+	}
+}
+
+// Reroute returns a setup that replaces the default route for hop
+func (c *Contract) Reroute(hop string, route casemodel.Route) func(n *mockstub.Network) {
+	return func(n *mockstub.Network) {
+		config := casemodel.DefaultRouting()
+		config.Routes[hop] = route
+		if _, err := n.Submit(c.Channels[0], c.Name, c.Admin, "SetRoutingConfig", string(MustJSON(config))); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// Outbox lists the transfers the contract has not had claimed
+func (c *Contract) Outbox(t *testing.T, n *mockstub.Network) []*casemodel.Transfer {
+	t.Helper()
+	payload, err := n.Evaluate(c.Channels[0], c.Name, c.Client, "ListPendingTransfers")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var transfers []*casemodel.Transfer
+	Decode(t, payload, &transfers)
+	return transfers
+}
+
+// Sealed returns s as by would hand it over after storing it on channel, with the hash
+// chain and proof the receiving contract verifies
+func (c *Contract) Sealed(s *casemodel.Case, by mockstub.Identity, channel string) *casemodel.Case {
+	n := mockstub.NewNetwork(c.Start)
+	n.SignWrites = casemodel.SignCaseSeals
+	n.Install("sealer", mockstub.ChaincodeFunc(func(stub shim.ChaincodeStubInterface) peer.Response {
+		_, args := stub.GetFunctionAndParameters()
+		s, err := casemodel.DecodeCase([]byte(args[0]))
+		if err != nil {
+			return shim.Error(err.Error())
+		}
+		if err := casemodel.Seal(stub.(*mockstub.Stub).Context(), s); err != nil {
+			return shim.Error(err.Error())
+		}
+		if err := stub.PutState(s.ID, MustJSON(s)); err != nil {
+			return shim.Error(err.Error())
+		}
+		return shim.Success(MustJSON(s))
+	}))
+	payload, err := n.Submit(channel, "sealer", by, "Seal", string(MustJSON(s)))
+	if err != nil {
+		panic(err)
+	}
+	sealed, err := casemodel.DecodeCase(payload)
+	if err != nil {
+		panic(err)
+	}
+	return sealed
+}
+
+// NewCase returns a case of lawyer L001 in the given status held by org, normalized as
+// cases arrive from another organization
+func NewCase(id string, status string, org string) *casemodel.Case {
+	c := &casemodel.Case{ID: id, Title: "Case " + id, Status: status, CurrentOrg: org, AssociatedLawyers: []string{"L001"}}
+	c.Normalize()
+	return c
+}
+
+// Fake returns a chaincode that answers each function with a fixed response
+func Fake(responses map[string]peer.Response) mockstub.ChaincodeFunc {
+	return func(stub shim.ChaincodeStubInterface) peer.Response {
+		function, _ := stub.GetFunctionAndParameters()
+		if response, ok := responses[function]; ok {
+			return response
+		}
+		return shim.Error("Function " + function + " not found")
+	}
+}
+
+// Recorder returns a chaincode that accepts every case it is sent and keeps it in cases.
+// A transaction resent to carry its seals sends its cases again, so only the cases of its
+// last run, the one committed, are kept.
+func Recorder(cases *[]*casemodel.Case) mockstub.ChaincodeFunc {
+	var txID string
+	var run, from int
+	return func(stub shim.ChaincodeStubInterface) peer.Response {
+		_, args := stub.GetFunctionAndParameters()
+		c, err := casemodel.DecodeCase([]byte(args[0]))
+		if err != nil {
+			return shim.Error(err.Error())
+		}
+		if s := stub.(*mockstub.Stub); s.GetTxID() != txID {
+			txID, run, from = s.GetTxID(), s.Run(), len(*cases)
+		} else if s.Run() != run {
+			run, *cases = s.Run(), (*cases)[:from]
+		}
+		*cases = append(*cases, c)
+		return shim.Success(nil)
+	}
+}
+
+// MustJSON marshals a value for a transaction argument or a fake response
+func MustJSON(v interface{}) []byte {
+	value, err := json.Marshal(v)
+	if err != nil {
+		panic(err)
+	}
+	return value
+}
+
+// Decode unmarshals a transaction's payload
+func Decode(t *testing.T, payload []byte, v interface{}) {
+	t.Helper()
+	if err := json.Unmarshal(payload, v); err != nil {
+		t.Fatalf("failed to decode %s: %v", payload, err)
+	}
+}
+
+// Changed returns the change a case version records for field
+func Changed(v casemodel.CaseVersion, field string) casemodel.FieldChange {
+	for _, c := range v.Changes {
+		if c.Field == field {
+			return c
+		}
+	}
+	return casemodel.FieldChange{}
+}
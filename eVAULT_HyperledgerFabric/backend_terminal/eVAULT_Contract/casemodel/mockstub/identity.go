@@ -0,0 +1,135 @@
+package mockstub
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric-chaincode-go/pkg/attrmgr"
+	"github.com/hyperledger/fabric-protos-go/msp"
+)
+
+// Identity is a client identity as enrolled by a Fabric CA
+type Identity struct {
+	MSPID string
+	Name  string            // common name of the certificate
+	Attrs map[string]string // attributes the CA adds to the certificate
+	Key   *ecdsa.PrivateKey // key the certificate is issued for, a new one for each transaction when nil
+	// SelfSigned issues the certificate to itself instead of from the CA of MSPID, as
+	// someone forging a member of the organization would
+	SelfSigned bool
+}
+
+// authority is the root CA of an MSP
+type authority struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+var (
+	authoritiesMu sync.Mutex
+	authorities   = make(map[string]*authority)
+)
+
+// ca returns the root CA of mspID, creating it the first time it is needed
+func ca(mspID string) (*authority, error) {
+	authoritiesMu.Lock()
+	defer authoritiesMu.Unlock()
+	if a, ok := authorities[mspID]; ok {
+		return a, nil
+	}
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate CA key for %s: %v", mspID, err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "ca." + mspID, Organization: []string{mspID}},
+		NotBefore:             time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC),
+		NotAfter:              time.Date(2100, time.January, 1, 0, 0, 0, 0, time.UTC),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create CA certificate for %s: %v", mspID, err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, err
+	}
+	a := &authority{cert: cert, key: key}
+	authorities[mspID] = a
+	return a, nil
+}
+
+// RootCertificate returns the PEM root CA certificate of mspID, which every identity of
+// the MSP is enrolled by unless it is SelfSigned
+func RootCertificate(mspID string) string {
+	a, err := ca(mspID)
+	if err != nil {
+		panic(err)
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: a.cert.Raw}))
+}
+
+// Creator returns the serialized identity Fabric hands to chaincode as the creator of a
+// transaction, with a certificate issued by the MSP's CA.
+func (id Identity) Creator() ([]byte, error) {
+	creator, _, err := id.enroll()
+	return creator, err
+}
+
+// enroll returns the serialized identity with the key its certificate was issued for
+func (id Identity) enroll() ([]byte, *ecdsa.PrivateKey, error) {
+	key := id.Key
+	if key == nil {
+		var err error
+		if key, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader); err != nil {
+			return nil, nil, fmt.Errorf("failed to generate key for %s: %v", id.Name, err)
+		}
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: id.Name, OrganizationalUnit: []string{"client"}},
+		NotBefore:    time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC),
+		NotAfter:     time.Date(2100, time.January, 1, 0, 0, 0, 0, time.UTC),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+	}
+	if len(id.Attrs) > 0 {
+		if err := attrmgr.New().AddAttributesToCert(&attrmgr.Attributes{Attrs: id.Attrs}, template); err != nil {
+			return nil, nil, fmt.Errorf("failed to add attributes for %s: %v", id.Name, err)
+		}
+		// CreateCertificate only writes extensions listed in ExtraExtensions
+		template.ExtraExtensions, template.Extensions = template.Extensions, nil
+	}
+
+	parent, signer := template, key
+	if !id.SelfSigned {
+		a, err := ca(id.MSPID)
+		if err != nil {
+			return nil, nil, err
+		}
+		parent, signer = a.cert, a.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, signer)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create certificate for %s: %v", id.Name, err)
+	}
+	cert := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	creator, err := proto.Marshal(&msp.SerializedIdentity{Mspid: id.MSPID, IdBytes: cert})
+	if err != nil {
+		return nil, nil, err
+	}
+	return creator, key, nil
+}
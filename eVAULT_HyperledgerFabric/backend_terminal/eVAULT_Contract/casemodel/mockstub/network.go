@@ -0,0 +1,444 @@
+// Package mockstub runs chaincode against an in-memory Fabric network for tests. Unlike
+// shimtest.MockStub it follows the peer's transaction rules closely enough to test
+// contracts that call each other:
+//
+//   - reads see committed state only, never the transaction's own writes
+//   - a chaincode invoked on the same channel joins the caller's transaction, while one
+//     invoked on another channel can only read: its writes are dropped
+//   - a chaincode cannot call itself on a channel where it is already running
+//   - only the last event set by the invoked chaincode is committed
+//   - a transaction that ran a paginated query cannot commit writes
+//   - private data can only be read by members of its collection; everyone else can
+//     only read its hash
+//   - rich queries are evaluated against the JSON values in the ledger, with CouchDB's
+//     Mango operators and collation
+//
+// Transactions are timestamped from the network's clock, so a test that submits the same
+// transactions always sees the same times. Their IDs are computed from a random nonce and
+// the creator, as Fabric computes them. A Network is not safe for concurrent use.
+package mockstub
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-protos-go/ledger/queryresult"
+	"github.com/hyperledger/fabric-protos-go/peer"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Router returns the chaincode that serves an invocation of name on channel
+type Router func(name string, channel string) (shim.Chaincode, error)
+
+// WriteSigner is how a client signs over what a transaction writes. It is given the ID of
+// the simulated transaction, the values it writes to the channel it was submitted to, and
+// a function signing a digest with the client's key. It returns the transient data the
+// client adds to the proposal before sending it again.
+type WriteSigner func(txID string, values [][]byte, sign func(digest []byte) ([]byte, error)) (map[string][]byte, error)
+
+// maxSigningRounds bounds how often a client resends a transaction whose writes change
+// with the signatures it adds
+const maxSigningRounds = 3
+
+// ChaincodeFunc adapts a function to shim.Chaincode, for fake chaincodes in tests
+type ChaincodeFunc func(stub shim.ChaincodeStubInterface) peer.Response
+
+// Init calls f
+func (f ChaincodeFunc) Init(stub shim.ChaincodeStubInterface) peer.Response {
+	return f(stub)
+}
+
+// Invoke calls f
+func (f ChaincodeFunc) Invoke(stub shim.ChaincodeStubInterface) peer.Response {
+	return f(stub)
+}
+
+// Event is a chaincode event committed with a transaction
+type Event struct {
+	Channel   string
+	Chaincode string
+	TxID      string
+	Name      string
+	Payload   []byte
+}
+
+// Proposal is a transaction proposal sent by a client
+type Proposal struct {
+	Channel   string
+	Chaincode string
+	Identity  Identity
+	Function  string
+	Args      []string
+	Transient map[string][]byte
+}
+
+// Network is a set of channels with chaincodes installed on them. Each chaincode has
+// its own world state on each channel.
+type Network struct {
+	// Route finds the chaincode for an invocation. It defaults to Installed and can be
+	// replaced to fake or fail calls to other chaincodes.
+	Route Router
+	// Step is how far the clock moves after each committed transaction
+	Step time.Duration
+	// SignWrites, when set, is run on every transaction submitted for commit. The client
+	// resends the transaction with the same ID and the transient data it returns until
+	// that data no longer changes, as clients that sign over their simulation results do.
+	SignWrites WriteSigner
+
+	clock      time.Time
+	chaincodes map[string]installation
+	ledgers    map[ledgerKey]*ledger
+	members    map[collectionKey]map[string]bool // MSP IDs of each collection's members
+	events     []Event
+}
+
+type installation struct {
+	chaincode shim.Chaincode
+	channels  map[string]bool // nil when installed on every channel
+}
+
+type ledgerKey struct {
+	channel   string
+	chaincode string
+}
+
+type ledger struct {
+	state   map[string][]byte
+	history map[string][]*queryresult.KeyModification
+	private map[string]map[string][]byte // by collection
+}
+
+// NewNetwork returns an empty network whose clock starts at start
+func NewNetwork(start time.Time) *Network {
+	n := &Network{
+		Step:       time.Second,
+		clock:      start.UTC(),
+		chaincodes: make(map[string]installation),
+		ledgers:    make(map[ledgerKey]*ledger),
+		members:    make(map[collectionKey]map[string]bool),
+	}
+	n.Route = n.Installed
+	return n
+}
+
+// Install makes a chaincode available under name on the given channels, or on every
+// channel when none are given
+func (n *Network) Install(name string, chaincode shim.Chaincode, channels ...string) {
+	install := installation{chaincode: chaincode}
+	if len(channels) > 0 {
+		install.channels = make(map[string]bool)
+		for _, channel := range channels {
+			install.channels[channel] = true
+		}
+	}
+	n.chaincodes[name] = install
+}
+
+// Installed returns the chaincode installed under name if it is available on channel
+func (n *Network) Installed(name string, channel string) (shim.Chaincode, error) {
+	install, ok := n.chaincodes[name]
+	if !ok {
+		return nil, fmt.Errorf("chaincode %s is not installed", name)
+	}
+	if install.channels != nil && !install.channels[channel] {
+		return nil, fmt.Errorf("chaincode %s is not defined on channel %s", name, channel)
+	}
+	return install.chaincode, nil
+}
+
+// Now returns the timestamp the next transaction will get
+func (n *Network) Now() time.Time {
+	return n.clock
+}
+
+// SetNow moves the clock to t
+func (n *Network) SetNow(t time.Time) {
+	n.clock = t.UTC()
+}
+
+// Submit sends a proposal for function to chaincode on channel and commits the result
+// if the chaincode succeeds. It returns the response payload, or the chaincode's error
+// message as an error.
+func (n *Network) Submit(channel string, chaincode string, id Identity, function string, args ...string) ([]byte, error) {
+	return n.result(n.Invoke(Proposal{Channel: channel, Chaincode: chaincode, Identity: id, Function: function, Args: args}, true))
+}
+
+// SubmitTransient is Submit with transient data, which the chaincode can read but which is
+// not recorded in the transaction
+func (n *Network) SubmitTransient(channel string, chaincode string, id Identity, transient map[string][]byte, function string, args ...string) ([]byte, error) {
+	return n.result(n.Invoke(Proposal{Channel: channel, Chaincode: chaincode, Identity: id, Function: function, Args: args, Transient: transient}, true))
+}
+
+// Evaluate runs function like Submit but never commits, as a query through a gateway
+func (n *Network) Evaluate(channel string, chaincode string, id Identity, function string, args ...string) ([]byte, error) {
+	return n.result(n.Invoke(Proposal{Channel: channel, Chaincode: chaincode, Identity: id, Function: function, Args: args}, false))
+}
+
+func (n *Network) result(response peer.Response) ([]byte, error) {
+	if response.Status >= shim.ERRORTHRESHOLD {
+		return nil, fmt.Errorf("%s", response.Message)
+	}
+	return response.Payload, nil
+}
+
+// Invoke runs a proposal and returns the chaincode's response. With commit set, a
+// successful transaction is committed; if committing fails the response is turned
+// into an error.
+func (n *Network) Invoke(p Proposal, commit bool) peer.Response {
+	chaincode, err := n.Route(p.Chaincode, p.Channel)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	stub, err := n.NewTransaction(p)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+
+	response := chaincode.Invoke(stub)
+	if commit && n.SignWrites != nil {
+		var added map[string][]byte
+		for i := 0; i < maxSigningRounds && response.Status < shim.ERRORTHRESHOLD; i++ {
+			signatures, err := n.SignWrites(stub.tx.id, stub.tx.written(), stub.tx.sign)
+			if err != nil {
+				return shim.Error(fmt.Sprintf("failed to sign the writes of transaction %s: %v", stub.tx.id, err))
+			}
+			if sameData(signatures, added) {
+				break
+			}
+			added = signatures
+			if stub, err = n.resend(stub, p, added); err != nil {
+				return shim.Error(err.Error())
+			}
+			response = chaincode.Invoke(stub)
+		}
+	}
+	if commit && response.Status < shim.ERRORTHRESHOLD {
+		if err := n.Commit(stub); err != nil {
+			return shim.Error(err.Error())
+		}
+	}
+	return response
+}
+
+// NewTransaction returns the stub for a new transaction, for calling contract functions
+// directly. Its writes are only kept if it is passed to Commit.
+func (n *Network) NewTransaction(p Proposal) (*Stub, error) {
+	creator, key, err := p.Identity.enroll()
+	if err != nil {
+		return nil, err
+	}
+	args := [][]byte{[]byte(p.Function)}
+	for _, arg := range p.Args {
+		args = append(args, []byte(arg))
+	}
+
+	nonce := make([]byte, 24)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %v", err)
+	}
+	id := sha256.Sum256(append(append([]byte(nil), nonce...), creator...))
+	tx := &transaction{
+		id:        hex.EncodeToString(id[:]),
+		channel:   p.Channel,
+		timestamp: timestamppb.New(n.clock),
+		nonce:     nonce,
+		creator:   creator,
+		key:       key,
+		transient: p.Transient,
+	}
+	return n.start(tx, p.Chaincode, args)
+}
+
+// start signs the proposal of tx and returns the stub the invoked chaincode runs with
+func (n *Network) start(tx *transaction, chaincode string, args [][]byte) (*Stub, error) {
+	tx.writes = make(map[ledgerKey]map[string][]byte)
+	tx.private = make(map[collectionKey]map[string][]byte)
+	tx.running = map[ledgerKey]bool{{channel: tx.channel, chaincode: chaincode}: true}
+	var err error
+	if tx.proposal, err = signedProposal(tx, chaincode, args); err != nil {
+		return nil, err
+	}
+	return &Stub{network: n, tx: tx, chaincode: chaincode, args: args}, nil
+}
+
+// resend returns the stub for sending the transaction of stub again with added merged
+// into the transient data of proposal p. It keeps the transaction's ID and timestamp.
+func (n *Network) resend(stub *Stub, p Proposal, added map[string][]byte) (*Stub, error) {
+	transient := make(map[string][]byte, len(p.Transient)+len(added))
+	for k, v := range p.Transient {
+		transient[k] = v
+	}
+	for k, v := range added {
+		transient[k] = v
+	}
+	tx := &transaction{
+		id:        stub.tx.id,
+		run:       stub.tx.run + 1,
+		channel:   stub.tx.channel,
+		timestamp: stub.tx.timestamp,
+		nonce:     stub.tx.nonce,
+		creator:   stub.tx.creator,
+		key:       stub.tx.key,
+		signed:    stub.tx.signed,
+		transient: transient,
+	}
+	return n.start(tx, stub.chaincode, stub.args)
+}
+
+// written returns the values the transaction writes on its own channel, by chaincode and
+// key. Deleted keys are left out.
+func (tx *transaction) written() [][]byte {
+	ledgers := make([]ledgerKey, 0, len(tx.writes))
+	for key := range tx.writes {
+		ledgers = append(ledgers, key)
+	}
+	sort.Slice(ledgers, func(i, j int) bool { return ledgers[i].chaincode < ledgers[j].chaincode })
+	var values [][]byte
+	for _, l := range ledgers {
+		writes := tx.writes[l]
+		names := make([]string, 0, len(writes))
+		for name := range writes {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if writes[name] != nil {
+				values = append(values, writes[name])
+			}
+		}
+	}
+	return values
+}
+
+// sign signs a digest with the key the client signed the transaction's proposal with. A
+// digest is signed once per transaction, so that resending it does not change what it
+// carries.
+func (tx *transaction) sign(digest []byte) ([]byte, error) {
+	if signature, ok := tx.signed[string(digest)]; ok {
+		return signature, nil
+	}
+	signature, err := ecdsa.SignASN1(rand.Reader, tx.key, digest)
+	if err != nil {
+		return nil, err
+	}
+	if tx.signed == nil {
+		tx.signed = make(map[string][]byte)
+	}
+	tx.signed[string(digest)] = signature
+	return signature, nil
+}
+
+// sameData reports whether two sets of transient data hold the same entries
+func sameData(a map[string][]byte, b map[string][]byte) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k, v := range a {
+		if w, ok := b[k]; !ok || !bytes.Equal(v, w) {
+			return false
+		}
+	}
+	return true
+}
+
+// Commit writes a transaction's changes and event to the ledger and advances the clock
+func (n *Network) Commit(stub *Stub) error {
+	tx := stub.tx
+	if tx.paginated && (len(tx.writes) > 0 || len(tx.private) > 0) {
+		return fmt.Errorf("transaction %s ran a paginated query, which is only allowed in read-only transactions", tx.id)
+	}
+
+	for key, writes := range tx.writes {
+		ledger := n.ledger(key.channel, key.chaincode)
+		names := make([]string, 0, len(writes))
+		for name := range writes {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			value := writes[name]
+			if value == nil {
+				delete(ledger.state, name)
+			} else {
+				ledger.state[name] = value
+			}
+			ledger.history[name] = append(ledger.history[name], &queryresult.KeyModification{
+				TxId:      tx.id,
+				Value:     value,
+				Timestamp: tx.timestamp,
+				IsDelete:  value == nil,
+			})
+		}
+	}
+	for key, writes := range tx.private {
+		collection := n.collection(key)
+		for name, value := range writes {
+			if value == nil {
+				delete(collection, name)
+			} else {
+				collection[name] = value
+			}
+		}
+	}
+	if stub.event != nil {
+		n.events = append(n.events, Event{
+			Channel:   tx.channel,
+			Chaincode: stub.chaincode,
+			TxID:      tx.id,
+			Name:      stub.event.EventName,
+			Payload:   stub.event.Payload,
+		})
+	}
+	n.clock = n.clock.Add(n.Step)
+	return nil
+}
+
+// Events returns the events committed so far, oldest first
+func (n *Network) Events() []Event {
+	return append([]Event(nil), n.events...)
+}
+
+// GetState returns the committed value of key in chaincode's state on channel, or nil
+func (n *Network) GetState(channel string, chaincode string, key string) []byte {
+	return n.ledger(channel, chaincode).state[key]
+}
+
+// PutState writes key straight into chaincode's state on channel, for seeding tests
+func (n *Network) PutState(channel string, chaincode string, key string, value []byte) {
+	n.ledger(channel, chaincode).state[key] = value
+}
+
+// Keys returns the committed keys of chaincode's state on channel in key order
+func (n *Network) Keys(channel string, chaincode string) []string {
+	return n.ledger(channel, chaincode).keys()
+}
+
+func (n *Network) ledger(channel string, chaincode string) *ledger {
+	key := ledgerKey{channel: channel, chaincode: chaincode}
+	l, ok := n.ledgers[key]
+	if !ok {
+		l = &ledger{
+			state:   make(map[string][]byte),
+			history: make(map[string][]*queryresult.KeyModification),
+			private: make(map[string]map[string][]byte),
+		}
+		n.ledgers[key] = l
+	}
+	return l
+}
+
+func (l *ledger) keys() []string {
+	keys := make([]string, 0, len(l.state))
+	for key := range l.state {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
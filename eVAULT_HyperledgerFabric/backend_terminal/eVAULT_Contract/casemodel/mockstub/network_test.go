@@ -0,0 +1,388 @@
+package mockstub
+
+import (
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-protos-go/common"
+	"github.com/hyperledger/fabric-protos-go/peer"
+)
+
+var start = time.Date(2024, time.May, 15, 10, 0, 0, 0, time.UTC)
+
+var client = Identity{MSPID: "LawyersOrgMSP", Name: "lawyer1", Attrs: map[string]string{"role": "lawyer", "lawyerId": "L001"}}
+
+// kvChaincode is a small key-value chaincode used to exercise the network
+var kvChaincode = ChaincodeFunc(func(stub shim.ChaincodeStubInterface) peer.Response {
+	function, args := stub.GetFunctionAndParameters()
+	switch function {
+	case "put":
+		if err := stub.PutState(args[0], []byte(args[1])); err != nil {
+			return shim.Error(err.Error())
+		}
+		value, _ := stub.GetState(args[0])
+		return shim.Success(value)
+	case "get":
+		value, _ := stub.GetState(args[0])
+		return shim.Success(value)
+	case "event":
+		_ = stub.SetEvent(args[0], []byte(stub.GetTxID()))
+		return shim.Success(nil)
+	case "call":
+		// call <chaincode> <channel> <function> <args...>
+		callArgs := [][]byte{}
+		for _, arg := range args[2:] {
+			callArgs = append(callArgs, []byte(arg))
+		}
+		return stub.InvokeChaincode(args[0], callArgs, args[1])
+	case "page":
+		iterator, metadata, err := stub.GetStateByRangeWithPagination("", "", 1, "")
+		if err != nil {
+			return shim.Error(err.Error())
+		}
+		defer iterator.Close()
+		if err := stub.PutState("paged", []byte(metadata.Bookmark)); err != nil {
+			return shim.Error(err.Error())
+		}
+		return shim.Success([]byte(metadata.Bookmark))
+	case "putPrivate":
+		if err := stub.PutPrivateData(args[0], args[1], []byte(args[2])); err != nil {
+			return shim.Error(err.Error())
+		}
+		return shim.Success(nil)
+	case "getPrivate":
+		value, err := stub.GetPrivateData(args[0], args[1])
+		if err != nil {
+			return shim.Error(err.Error())
+		}
+		return shim.Success(value)
+	case "hashPrivate":
+		hash, err := stub.GetPrivateDataHash(args[0], args[1])
+		if err != nil {
+			return shim.Error(err.Error())
+		}
+		return shim.Success(hash)
+	case "sealed":
+		// sealed <key> <value> stores value with the signature the client sent for it and
+		// returns the run of the transaction
+		transient, _ := stub.GetTransient()
+		if err := stub.PutState(args[0], append([]byte(args[1]+"|"), transient["signature"]...)); err != nil {
+			return shim.Error(err.Error())
+		}
+		return shim.Success([]byte(strconv.Itoa(stub.(*Stub).Run())))
+	case "fail":
+		_ = stub.PutState(args[0], []byte("failed"))
+		return shim.Error("failed on purpose")
+	}
+	return shim.Error("unknown function " + function)
+})
+
+func newTestNetwork() *Network {
+	n := NewNetwork(start)
+	n.Install("kv", kvChaincode)
+	n.Install("helper", kvChaincode)
+	n.Install("other", kvChaincode, "second")
+	return n
+}
+
+func TestSubmitCommitsAfterTheTransaction(t *testing.T) {
+	n := newTestNetwork()
+
+	value, err := n.Submit("first", "kv", client, "put", "a", "1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(value) != 0 {
+		t.Errorf("write was visible inside its own transaction: %q", value)
+	}
+	if got := string(n.GetState("first", "kv", "a")); got != "1" {
+		t.Errorf("committed value = %q, want 1", got)
+	}
+	if n.GetState("second", "kv", "a") != nil {
+		t.Error("write leaked to another channel")
+	}
+
+	if _, err := n.Evaluate("first", "kv", client, "put", "a", "2"); err != nil {
+		t.Fatal(err)
+	}
+	if got := string(n.GetState("first", "kv", "a")); got != "1" {
+		t.Errorf("Evaluate committed a write: %q", got)
+	}
+
+	if _, err := n.Submit("first", "kv", client, "fail", "a"); err == nil || err.Error() != "failed on purpose" {
+		t.Errorf("expected the chaincode error, got %v", err)
+	}
+	if got := string(n.GetState("first", "kv", "a")); got != "1" {
+		t.Errorf("failed transaction committed a write: %q", got)
+	}
+}
+
+func TestInvokeChaincode(t *testing.T) {
+	n := newTestNetwork()
+
+	if _, err := n.Submit("first", "kv", client, "call", "helper", "", "put", "same", "1"); err != nil {
+		t.Fatal(err)
+	}
+	if got := string(n.GetState("first", "helper", "same")); got != "1" {
+		t.Errorf("same channel call did not join the transaction: %q", got)
+	}
+
+	if _, err := n.Submit("first", "kv", client, "call", "other", "second", "put", "cross", "1"); err != nil {
+		t.Fatal(err)
+	}
+	if got := n.GetState("second", "other", "cross"); got != nil {
+		t.Errorf("cross channel call committed a write: %q", got)
+	}
+
+	_, err := n.Submit("first", "kv", client, "call", "kv", "", "get", "a")
+	if err == nil || !strings.Contains(err.Error(), "(first) exists") {
+		t.Errorf("expected re-entry to fail, got %v", err)
+	}
+	if _, err := n.Submit("first", "kv", client, "call", "other", "second", "call", "kv", "first", "get", "a"); err == nil {
+		t.Error("expected re-entry through another channel to fail")
+	}
+	if _, err := n.Submit("first", "kv", client, "call", "other", "second", "call", "kv", "second", "get", "a"); err != nil {
+		t.Errorf("calling a chaincode on another channel failed: %v", err)
+	}
+
+	_, err = n.Submit("first", "kv", client, "call", "other", "third", "get", "a")
+	if err == nil || !strings.Contains(err.Error(), "not defined on channel third") {
+		t.Errorf("expected a routing error, got %v", err)
+	}
+
+	n.Route = func(name string, channel string) (shim.Chaincode, error) {
+		return ChaincodeFunc(func(shim.ChaincodeStubInterface) peer.Response { return shim.Success([]byte(name + "@" + channel)) }), nil
+	}
+	value, err := n.Submit("first", "kv", client, "get", "a")
+	if err != nil || string(value) != "kv@first" {
+		t.Errorf("custom router was not used: %q, %v", value, err)
+	}
+}
+
+func TestEventsAndTimestamps(t *testing.T) {
+	n := newTestNetwork()
+
+	if _, err := n.Submit("first", "kv", client, "event", "Top"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := n.Submit("first", "kv", client, "call", "helper", "", "event", "Nested"); err != nil {
+		t.Fatal(err)
+	}
+	events := n.Events()
+	if len(events) != 1 || events[0].Name != "Top" || events[0].Chaincode != "kv" || string(events[0].Payload) != events[0].TxID {
+		t.Errorf("events = %+v, want only the top level event", events)
+	}
+
+	stub, err := n.NewTransaction(Proposal{Channel: "first", Chaincode: "kv", Identity: client, Function: "get"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts, _ := stub.GetTxTimestamp()
+	if want := start.Add(2 * time.Second); !ts.AsTime().Equal(want) {
+		t.Errorf("timestamp = %v, want %v", ts.AsTime(), want)
+	}
+}
+
+func TestPaginatedQueriesAreReadOnly(t *testing.T) {
+	n := newTestNetwork()
+	n.PutState("first", "kv", "a", []byte("1"))
+	n.PutState("first", "kv", "b", []byte("2"))
+
+	_, err := n.Submit("first", "kv", client, "page")
+	if err == nil || !strings.Contains(err.Error(), "read-only") {
+		t.Errorf("expected paginated update to fail, got %v", err)
+	}
+	bookmark, err := n.Evaluate("first", "kv", client, "page")
+	if err != nil || string(bookmark) != "a" {
+		t.Errorf("bookmark = %q, %v, want a", bookmark, err)
+	}
+}
+
+func TestRangeSkipsCompositeKeys(t *testing.T) {
+	n := newTestNetwork()
+	stub, err := n.NewTransaction(Proposal{Channel: "first", Chaincode: "kv", Identity: client})
+	if err != nil {
+		t.Fatal(err)
+	}
+	composite, _ := stub.CreateCompositeKey("lawyer~case", []string{"L001", "CASE_001"})
+	n.PutState("first", "kv", composite, []byte("x"))
+	n.PutState("first", "kv", "CASE_001", []byte("{}"))
+
+	iterator, _ := stub.GetStateByRange("", "")
+	if kv, _ := iterator.Next(); kv.Key != "CASE_001" || iterator.HasNext() {
+		t.Errorf("range returned %q and more: %v", kv.Key, iterator.HasNext())
+	}
+
+	iterator, _ = stub.GetStateByPartialCompositeKey("lawyer~case", []string{"L001"})
+	kv, _ := iterator.Next()
+	objectType, attributes, err := stub.SplitCompositeKey(kv.Key)
+	if err != nil || objectType != "lawyer~case" || len(attributes) != 2 || attributes[1] != "CASE_001" {
+		t.Errorf("split %q = %s %v %v", kv.Key, objectType, attributes, err)
+	}
+}
+
+func TestHistoryForKey(t *testing.T) {
+	n := newTestNetwork()
+	for _, value := range []string{"1", "2"} {
+		if _, err := n.Submit("first", "kv", client, "put", "a", value); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	stub, _ := n.NewTransaction(Proposal{Channel: "first", Chaincode: "kv", Identity: client})
+	iterator, err := stub.GetHistoryForKey("a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var values []string
+	for iterator.HasNext() {
+		change, _ := iterator.Next()
+		values = append(values, string(change.Value))
+	}
+	if strings.Join(values, ",") != "2,1" {
+		t.Errorf("history = %v, want newest first", values)
+	}
+}
+
+func TestClientIdentity(t *testing.T) {
+	n := newTestNetwork()
+	stub, err := n.NewTransaction(Proposal{Channel: "first", Chaincode: "kv", Identity: client, Function: "get", Args: []string{"a"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	identity := stub.Context().GetClientIdentity()
+	if identity == nil {
+		t.Fatal("context has no client identity")
+	}
+	if mspID, _ := identity.GetMSPID(); mspID != "LawyersOrgMSP" {
+		t.Errorf("MSP ID = %q", mspID)
+	}
+	if lawyerID, found, _ := identity.GetAttributeValue("lawyerId"); !found || lawyerID != "L001" {
+		t.Errorf("lawyerId = %q, %v", lawyerID, found)
+	}
+	if cert, _ := identity.GetX509Certificate(); cert.Subject.CommonName != "lawyer1" {
+		t.Errorf("common name = %q", cert.Subject.CommonName)
+	}
+	if function, args := stub.GetFunctionAndParameters(); function != "get" || len(args) != 1 {
+		t.Errorf("function = %s %v", function, args)
+	}
+}
+
+func TestProposalIsSigned(t *testing.T) {
+	n := newTestNetwork()
+	stub, err := n.NewTransaction(Proposal{Channel: "first", Chaincode: "kv", Identity: client, Function: "get", Args: []string{"a"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	proposal, _ := stub.GetSignedProposal()
+	cert, _ := stub.Context().GetClientIdentity().GetX509Certificate()
+	digest := sha256.Sum256(proposal.ProposalBytes)
+	if !ecdsa.VerifyASN1(cert.PublicKey.(*ecdsa.PublicKey), digest[:], proposal.Signature) {
+		t.Error("proposal signature does not verify with the client's certificate")
+	}
+}
+
+func TestTransactionID(t *testing.T) {
+	n := newTestNetwork()
+	stub, err := n.NewTransaction(Proposal{Channel: "first", Chaincode: "kv", Identity: client, Function: "get", Args: []string{"a"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	signedProposal, _ := stub.GetSignedProposal()
+	proposal := &peer.Proposal{}
+	header := &common.Header{}
+	signatureHeader := &common.SignatureHeader{}
+	if err := proto.Unmarshal(signedProposal.ProposalBytes, proposal); err != nil {
+		t.Fatal(err)
+	}
+	if err := proto.Unmarshal(proposal.Header, header); err != nil {
+		t.Fatal(err)
+	}
+	if err := proto.Unmarshal(header.SignatureHeader, signatureHeader); err != nil {
+		t.Fatal(err)
+	}
+	id := sha256.Sum256(append(signatureHeader.Nonce, signatureHeader.Creator...))
+	if stub.GetTxID() != hex.EncodeToString(id[:]) || len(signatureHeader.Nonce) == 0 {
+		t.Errorf("transaction ID %s is not the hash of the nonce and creator", stub.GetTxID())
+	}
+}
+
+func TestSignWrites(t *testing.T) {
+	n := newTestNetwork()
+	rounds := 0
+	n.SignWrites = func(txID string, values [][]byte, sign func(digest []byte) ([]byte, error)) (map[string][]byte, error) {
+		rounds++
+		value := strings.SplitN(string(values[0]), "|", 2)[0]
+		digest := sha256.Sum256([]byte(txID + value))
+		signature, err := sign(digest[:])
+		return map[string][]byte{"signature": signature}, err
+	}
+
+	run, err := n.Submit("first", "kv", client, "sealed", "a", "1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rounds != 2 {
+		t.Errorf("signed %d times, want once for the first run and once to see nothing changed", rounds)
+	}
+	if string(run) != "1" {
+		t.Errorf("committed run %s, want the resent run 1", run)
+	}
+	stored := strings.SplitN(string(n.GetState("first", "kv", "a")), "|", 2)
+	if stored[0] != "1" || stored[1] == "" {
+		t.Errorf("stored %q, want the value with its signature", n.GetState("first", "kv", "a"))
+	}
+
+	if _, err := n.Evaluate("first", "kv", client, "get", "a"); err != nil || rounds != 2 {
+		t.Errorf("evaluate signed writes: %v, %d rounds", err, rounds)
+	}
+}
+
+func TestPrivateData(t *testing.T) {
+	n := newTestNetwork()
+	n.DefineCollection("first", "kv", "lawyers", "LawyersOrgMSP")
+	judge := Identity{MSPID: "JudgesOrgMSP", Name: "judge1"}
+
+	if _, err := n.Submit("first", "kv", client, "putPrivate", "missing", "a", "1"); err == nil || !strings.Contains(err.Error(), "collection missing is not defined") {
+		t.Errorf("write to an undefined collection: %v", err)
+	}
+	if _, err := n.Submit("first", "kv", judge, "putPrivate", "lawyers", "a", "secret"); err != nil {
+		t.Fatalf("a non-member could not write: %v", err)
+	}
+	if got := string(n.GetPrivateData("first", "kv", "lawyers", "a")); got != "secret" {
+		t.Errorf("committed value = %q, want secret", got)
+	}
+	if n.GetState("first", "kv", "a") != nil {
+		t.Error("private write leaked to the world state")
+	}
+
+	if value, err := n.Evaluate("first", "kv", client, "getPrivate", "lawyers", "a"); err != nil || string(value) != "secret" {
+		t.Errorf("member read = %q, %v", value, err)
+	}
+	if _, err := n.Evaluate("first", "kv", judge, "getPrivate", "lawyers", "a"); err == nil || !strings.Contains(err.Error(), "does not have read access") {
+		t.Errorf("non-member read: %v", err)
+	}
+	want := sha256.Sum256([]byte("secret"))
+	if hash, err := n.Evaluate("first", "kv", judge, "hashPrivate", "lawyers", "a"); err != nil || string(hash) != string(want[:]) {
+		t.Errorf("hash = %x, %v", hash, err)
+	}
+
+	// Private writes from another channel are dropped like any other write
+	n.DefineCollection("second", "other", "lawyers", "LawyersOrgMSP")
+	if _, err := n.Submit("first", "kv", client, "call", "other", "second", "putPrivate", "lawyers", "b", "2"); err != nil {
+		t.Fatal(err)
+	}
+	if n.GetPrivateData("second", "other", "lawyers", "b") != nil {
+		t.Error("private write on another channel was committed")
+	}
+}
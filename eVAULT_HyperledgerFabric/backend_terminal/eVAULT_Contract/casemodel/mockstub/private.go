@@ -0,0 +1,121 @@
+package mockstub
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric-protos-go/msp"
+)
+
+type collectionKey struct {
+	ledgerKey
+	name string
+}
+
+// DefineCollection adds a private data collection to chaincode on channel. Only clients
+// of the member MSPs can read its data, as with memberOnlyRead in a collection config;
+// anyone can write to it.
+func (n *Network) DefineCollection(channel string, chaincode string, name string, members ...string) {
+	key := collectionKey{ledgerKey: ledgerKey{channel: channel, chaincode: chaincode}, name: name}
+	n.members[key] = make(map[string]bool)
+	for _, member := range members {
+		n.members[key][member] = true
+	}
+}
+
+// GetPrivateData returns the committed value of key in a collection of chaincode on
+// channel, or nil
+func (n *Network) GetPrivateData(channel string, chaincode string, collection string, key string) []byte {
+	return n.collection(collectionKey{ledgerKey: ledgerKey{channel: channel, chaincode: chaincode}, name: collection})[key]
+}
+
+func (n *Network) collection(key collectionKey) map[string][]byte {
+	ledger := n.ledger(key.channel, key.chaincode)
+	values, ok := ledger.private[key.name]
+	if !ok {
+		values = make(map[string][]byte)
+		ledger.private[key.name] = values
+	}
+	return values
+}
+
+// collectionKey returns the key of a collection of this chaincode, or an error if it is
+// not defined
+func (s *Stub) collectionKey(collection string) (collectionKey, error) {
+	if collection == "" {
+		return collectionKey{}, errors.New("collection must not be an empty string")
+	}
+	key := collectionKey{ledgerKey: s.ledgerKey(), name: collection}
+	if _, ok := s.network.members[key]; !ok {
+		return collectionKey{}, fmt.Errorf("collection %s is not defined for chaincode %s on channel %s", collection, s.chaincode, s.tx.channel)
+	}
+	return key, nil
+}
+
+// GetPrivateData returns the committed value of key in collection. Only members of the
+// collection can read it.
+func (s *Stub) GetPrivateData(collection string, key string) ([]byte, error) {
+	ck, err := s.collectionKey(collection)
+	if err != nil {
+		return nil, err
+	}
+	var creator msp.SerializedIdentity
+	if err := proto.Unmarshal(s.tx.creator, &creator); err != nil {
+		return nil, fmt.Errorf("failed to read creator: %v", err)
+	}
+	if !s.network.members[ck][creator.Mspid] {
+		return nil, fmt.Errorf("tx creator does not have read access permission on privatedata in chaincodeName:%s collectionName: %s", s.chaincode, collection)
+	}
+	value, ok := s.network.collection(ck)[key]
+	if !ok {
+		return nil, nil
+	}
+	return append([]byte(nil), value...), nil
+}
+
+// GetPrivateDataHash returns the SHA-256 hash of the committed value of key in
+// collection, or nil. Anyone can read it.
+func (s *Stub) GetPrivateDataHash(collection string, key string) ([]byte, error) {
+	ck, err := s.collectionKey(collection)
+	if err != nil {
+		return nil, err
+	}
+	value, ok := s.network.collection(ck)[key]
+	if !ok {
+		return nil, nil
+	}
+	hash := sha256.Sum256(value)
+	return hash[:], nil
+}
+
+// PutPrivateData records a write to collection to be committed with the transaction
+func (s *Stub) PutPrivateData(collection string, key string, value []byte) error {
+	if len(value) == 0 {
+		return s.DelPrivateData(collection, key)
+	}
+	return s.writePrivate(collection, key, append([]byte(nil), value...))
+}
+
+// DelPrivateData records a delete from collection to be committed with the transaction
+func (s *Stub) DelPrivateData(collection string, key string) error {
+	return s.writePrivate(collection, key, nil)
+}
+
+func (s *Stub) writePrivate(collection string, key string, value []byte) error {
+	ck, err := s.collectionKey(collection)
+	if err != nil {
+		return err
+	}
+	if key == "" {
+		return errors.New("key must not be an empty string")
+	}
+	writes, ok := s.tx.private[ck]
+	if !ok {
+		writes = make(map[string][]byte)
+		s.tx.private[ck] = writes
+	}
+	writes[key] = value
+	return nil
+}
@@ -0,0 +1,442 @@
+package mockstub
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// record is a key and its JSON value
+type record struct {
+	key   string
+	value []byte
+}
+
+// richQuery is a parsed CouchDB Mango query
+type richQuery struct {
+	selector map[string]interface{}
+	sort     []sortField
+	fields   []string
+	limit    int
+	skip     int
+}
+
+type sortField struct {
+	name string
+	desc bool
+}
+
+// parseQuery parses the query JSON passed to GetQueryResult
+func parseQuery(query string) (*richQuery, error) {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(query), &raw); err != nil {
+		return nil, fmt.Errorf("invalid query %s: %v", query, err)
+	}
+	if _, ok := raw["selector"]; !ok {
+		return nil, fmt.Errorf("query %s has no selector", query)
+	}
+
+	q := &richQuery{}
+	for name, value := range raw {
+		var err error
+		switch name {
+		case "selector":
+			err = json.Unmarshal(value, &q.selector)
+			if err == nil && q.selector == nil {
+				err = fmt.Errorf("selector must be an object")
+			}
+		case "sort":
+			q.sort, err = parseSort(value)
+		case "fields":
+			err = json.Unmarshal(value, &q.fields)
+		case "limit":
+			err = json.Unmarshal(value, &q.limit)
+		case "skip":
+			err = json.Unmarshal(value, &q.skip)
+		case "use_index", "bookmark":
+		default:
+			err = fmt.Errorf("unsupported query field %q", name)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid query %s: %v", query, err)
+		}
+	}
+	return q, nil
+}
+
+// parseSort parses a sort list, whose entries are field names or {"field": "asc|desc"}
+func parseSort(data []byte) ([]sortField, error) {
+	var entries []interface{}
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, err
+	}
+	var fields []sortField
+	for _, entry := range entries {
+		switch entry := entry.(type) {
+		case string:
+			fields = append(fields, sortField{name: entry})
+		case map[string]interface{}:
+			if len(entry) != 1 {
+				return nil, fmt.Errorf("sort entry %v must name one field", entry)
+			}
+			for name, direction := range entry {
+				switch direction {
+				case "asc":
+					fields = append(fields, sortField{name: name})
+				case "desc":
+					fields = append(fields, sortField{name: name, desc: true})
+				default:
+					return nil, fmt.Errorf("sort direction for %s must be asc or desc", name)
+				}
+			}
+		default:
+			return nil, fmt.Errorf("invalid sort entry %v", entry)
+		}
+	}
+	return fields, nil
+}
+
+// run returns the records that match the query, sorted, paged and projected. Records
+// must be in key order, which is the order of unsorted results.
+func (q *richQuery) run(records []record) ([]record, error) {
+	type document struct {
+		record
+		doc map[string]interface{}
+	}
+	var matched []document
+	for _, r := range records {
+		var doc map[string]interface{}
+		if err := json.Unmarshal(r.value, &doc); err != nil || doc == nil {
+			// CouchDB only queries JSON objects
+			continue
+		}
+		ok, err := matchCondition(doc, true, q.selector)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			matched = append(matched, document{record: r, doc: doc})
+		}
+	}
+
+	if len(q.sort) > 0 {
+		sort.SliceStable(matched, func(i, j int) bool {
+			for _, field := range q.sort {
+				a, _ := lookup(matched[i].doc, true, field.name)
+				b, _ := lookup(matched[j].doc, true, field.name)
+				if c := collate(a, b); c != 0 {
+					return (c < 0) != field.desc
+				}
+			}
+			return false
+		})
+	}
+
+	if q.skip > 0 {
+		if q.skip >= len(matched) {
+			matched = nil
+		} else {
+			matched = matched[q.skip:]
+		}
+	}
+	if q.limit > 0 && len(matched) > q.limit {
+		matched = matched[:q.limit]
+	}
+
+	results := make([]record, len(matched))
+	for i, m := range matched {
+		results[i] = m.record
+		if len(q.fields) > 0 {
+			value, err := json.Marshal(project(m.doc, q.fields))
+			if err != nil {
+				return nil, err
+			}
+			results[i].value = value
+		}
+	}
+	return results, nil
+}
+
+// project returns the listed fields of doc
+func project(doc map[string]interface{}, fields []string) map[string]interface{} {
+	projected := make(map[string]interface{})
+	for _, field := range fields {
+		value, found := lookup(doc, true, field)
+		if !found {
+			continue
+		}
+		target := projected
+		path := strings.Split(field, ".")
+		for _, name := range path[:len(path)-1] {
+			next, ok := target[name].(map[string]interface{})
+			if !ok {
+				next = make(map[string]interface{})
+				target[name] = next
+			}
+			target = next
+		}
+		target[path[len(path)-1]] = value
+	}
+	return projected
+}
+
+// lookup returns the value of a dotted field path
+func lookup(value interface{}, found bool, field string) (interface{}, bool) {
+	for _, name := range strings.Split(field, ".") {
+		object, ok := value.(map[string]interface{})
+		if !found || !ok {
+			return nil, false
+		}
+		value, found = object[name]
+	}
+	return value, found
+}
+
+// matchCondition reports whether a value satisfies a selector or field condition.
+// found is false when the field is missing from the document.
+func matchCondition(value interface{}, found bool, condition interface{}) (bool, error) {
+	object, ok := condition.(map[string]interface{})
+	if !ok {
+		return matchOperator(value, found, "$eq", condition)
+	}
+	keys := make([]string, 0, len(object))
+	for key := range object {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		argument := object[key]
+		var ok bool
+		var err error
+		if strings.HasPrefix(key, "$") {
+			ok, err = matchOperator(value, found, key, argument)
+		} else {
+			field, fieldFound := lookup(value, found, key)
+			ok, err = matchCondition(field, fieldFound, argument)
+		}
+		if err != nil || !ok {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// matchOperator applies one Mango operator. As in CouchDB, every operator but
+// $exists fails on a missing field.
+func matchOperator(value interface{}, found bool, operator string, argument interface{}) (bool, error) {
+	if operator == "$exists" {
+		exists, ok := argument.(bool)
+		if !ok {
+			return false, fmt.Errorf("$exists takes a boolean, got %v", argument)
+		}
+		return found == exists, nil
+	}
+	if !found {
+		return false, nil
+	}
+
+	switch operator {
+	case "$eq":
+		return collate(value, argument) == 0, nil
+	case "$ne":
+		return collate(value, argument) != 0, nil
+	case "$gt":
+		return collate(value, argument) > 0, nil
+	case "$gte":
+		return collate(value, argument) >= 0, nil
+	case "$lt":
+		return collate(value, argument) < 0, nil
+	case "$lte":
+		return collate(value, argument) <= 0, nil
+	case "$in", "$nin":
+		list, ok := argument.([]interface{})
+		if !ok {
+			return false, fmt.Errorf("%s takes an array, got %v", operator, argument)
+		}
+		in := contains(list, value)
+		if elements, ok := value.([]interface{}); ok {
+			for _, element := range elements {
+				in = in || contains(list, element)
+			}
+		}
+		return in == (operator == "$in"), nil
+	case "$and", "$or", "$nor":
+		list, ok := argument.([]interface{})
+		if !ok {
+			return false, fmt.Errorf("%s takes an array, got %v", operator, argument)
+		}
+		matches := 0
+		for _, condition := range list {
+			ok, err := matchCondition(value, found, condition)
+			if err != nil {
+				return false, err
+			}
+			if ok {
+				matches++
+			}
+		}
+		switch operator {
+		case "$and":
+			return matches == len(list), nil
+		case "$or":
+			return matches > 0, nil
+		default:
+			return matches == 0, nil
+		}
+	case "$not":
+		ok, err := matchCondition(value, found, argument)
+		return !ok, err
+	case "$elemMatch", "$allMatch":
+		elements, ok := value.([]interface{})
+		if !ok || len(elements) == 0 {
+			return false, nil
+		}
+		matches := 0
+		for _, element := range elements {
+			ok, err := matchCondition(element, true, argument)
+			if err != nil {
+				return false, err
+			}
+			if ok {
+				matches++
+			}
+		}
+		if operator == "$elemMatch" {
+			return matches > 0, nil
+		}
+		return matches == len(elements), nil
+	case "$all":
+		list, ok := argument.([]interface{})
+		if !ok {
+			return false, fmt.Errorf("$all takes an array, got %v", argument)
+		}
+		elements, ok := value.([]interface{})
+		if !ok {
+			return false, nil
+		}
+		for _, item := range list {
+			if !contains(elements, item) {
+				return false, nil
+			}
+		}
+		return true, nil
+	case "$size":
+		size, ok := argument.(float64)
+		if !ok {
+			return false, fmt.Errorf("$size takes a number, got %v", argument)
+		}
+		elements, ok := value.([]interface{})
+		return ok && float64(len(elements)) == size, nil
+	case "$mod":
+		operands, ok := argument.([]interface{})
+		if !ok || len(operands) != 2 {
+			return false, fmt.Errorf("$mod takes [divisor, remainder], got %v", argument)
+		}
+		divisor, ok1 := operands[0].(float64)
+		remainder, ok2 := operands[1].(float64)
+		if !ok1 || !ok2 || divisor == 0 {
+			return false, fmt.Errorf("$mod takes [divisor, remainder], got %v", argument)
+		}
+		number, ok := value.(float64)
+		return ok && number == math.Trunc(number) && math.Mod(number, divisor) == remainder, nil
+	case "$type":
+		name, ok := argument.(string)
+		if !ok {
+			return false, fmt.Errorf("$type takes a string, got %v", argument)
+		}
+		return typeName(value) == name, nil
+	case "$regex":
+		pattern, ok := argument.(string)
+		if !ok {
+			return false, fmt.Errorf("$regex takes a string, got %v", argument)
+		}
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return false, fmt.Errorf("invalid $regex %q: %v", pattern, err)
+		}
+		text, ok := value.(string)
+		return ok && re.MatchString(text), nil
+	}
+	return false, fmt.Errorf("unsupported operator %s", operator)
+}
+
+func contains(list []interface{}, value interface{}) bool {
+	for _, item := range list {
+		if collate(item, value) == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func typeName(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	default:
+		return "object"
+	}
+}
+
+// typeRank orders JSON types as CouchDB collates them
+var typeRank = map[string]int{"null": 0, "boolean": 1, "number": 2, "string": 3, "array": 4, "object": 5}
+
+// collate compares two JSON values in CouchDB view collation order: null, false, true,
+// numbers, strings, arrays, objects. Strings compare by code point rather than by
+// CouchDB's ICU collation, which only differs for mixed case and accented text.
+func collate(a interface{}, b interface{}) int {
+	if rankA, rankB := typeRank[typeName(a)], typeRank[typeName(b)]; rankA != rankB {
+		return rankA - rankB
+	}
+	switch a := a.(type) {
+	case bool:
+		if a == b.(bool) {
+			return 0
+		}
+		if !a {
+			return -1
+		}
+		return 1
+	case float64:
+		switch b := b.(float64); {
+		case a < b:
+			return -1
+		case a > b:
+			return 1
+		}
+		return 0
+	case string:
+		return strings.Compare(a, b.(string))
+	case []interface{}:
+		b := b.([]interface{})
+		for i := 0; i < len(a) && i < len(b); i++ {
+			if c := collate(a[i], b[i]); c != 0 {
+				return c
+			}
+		}
+		return len(a) - len(b)
+	case map[string]interface{}:
+		if reflect.DeepEqual(a, b) {
+			return 0
+		}
+		encodedA, _ := json.Marshal(a)
+		encodedB, _ := json.Marshal(b)
+		if c := bytes.Compare(encodedA, encodedB); c != 0 {
+			return c
+		}
+	}
+	return 0
+}
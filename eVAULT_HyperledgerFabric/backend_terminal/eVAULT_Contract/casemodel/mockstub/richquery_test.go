@@ -0,0 +1,106 @@
+package mockstub
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var queryRecords = []record{
+	{"CASE_001", []byte(`{"id":"CASE_001","status":"CREATED","currentOrg":"LawyersOrg","createdAt":"2024-05-02T09:00:00Z","associatedLawyers":["L001"],"judgment":null}`)},
+	{"CASE_002", []byte(`{"id":"CASE_002","status":"VERIFIED_BY_REGISTRAR","currentOrg":"RegistrarsOrg","createdAt":"2024-05-01T09:00:00Z","associatedLawyers":["L001","L002"],"title":"Land dispute","hearings":[{"status":"SCHEDULED"}]}`)},
+	{"CASE_003", []byte(`{"id":"CASE_003","status":"JUDGMENT_ISSUED","currentOrg":"BenchClerksOrg","createdAt":"2024-05-03T09:00:00Z","associatedLawyers":[],"judgment":{"decision":"Allowed"},"fee":250}`)},
+	{"JUDGE_J001", []byte(`{"id":"J001","name":"Justice Rao"}`)},
+	{"COUNTER", []byte(`42`)},
+}
+
+func TestRichQuery(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  []string
+	}{
+		{"implicit equality", `{"selector":{"currentOrg":"LawyersOrg"}}`, []string{"CASE_001"}},
+		{"several fields", `{"selector":{"status":"VERIFIED_BY_REGISTRAR","currentOrg":"RegistrarsOrg"}}`, []string{"CASE_002"}},
+		{"empty selector matches every object", `{"selector":{}}`, []string{"CASE_001", "CASE_002", "CASE_003", "JUDGE_J001"}},
+		{"in", `{"selector":{"status":{"$in":["CREATED","JUDGMENT_ISSUED"]}}}`, []string{"CASE_001", "CASE_003"}},
+		{"nin skips missing fields", `{"selector":{"status":{"$nin":["CREATED"]}}}`, []string{"CASE_002", "CASE_003"}},
+		{"ne null needs a value", `{"selector":{"judgment":{"$ne":null}}}`, []string{"CASE_003"}},
+		{"exists", `{"selector":{"title":{"$exists":false},"status":{"$exists":true}}}`, []string{"CASE_001", "CASE_003"}},
+		{"range", `{"selector":{"createdAt":{"$gte":"2024-05-02T00:00:00Z","$lt":"2024-05-03T00:00:00Z"}}}`, []string{"CASE_001"}},
+		{"regex", `{"selector":{"title":{"$regex":"(?i)^land"}}}`, []string{"CASE_002"}},
+		{"elemMatch eq", `{"selector":{"associatedLawyers":{"$elemMatch":{"$eq":"L002"}}}}`, []string{"CASE_002"}},
+		{"elemMatch on objects", `{"selector":{"hearings":{"$elemMatch":{"status":"SCHEDULED"}}}}`, []string{"CASE_002"}},
+		{"in matches array elements", `{"selector":{"associatedLawyers":{"$in":["L001"]}}}`, []string{"CASE_001", "CASE_002"}},
+		{"size", `{"selector":{"associatedLawyers":{"$size":0}}}`, []string{"CASE_003"}},
+		{"nested field", `{"selector":{"judgment.decision":"Allowed"}}`, []string{"CASE_003"}},
+		{"nested object", `{"selector":{"judgment":{"decision":"Allowed"}}}`, []string{"CASE_003"}},
+		{"or", `{"selector":{"$or":[{"currentOrg":"LawyersOrg"},{"title":"Land dispute"}]}}`, []string{"CASE_001", "CASE_002"}},
+		{"and", `{"selector":{"$and":[{"createdAt":{"$gt":"2024-05-01T12:00:00Z"}},{"createdAt":{"$lt":"2024-05-03T00:00:00Z"}}]}}`, []string{"CASE_001"}},
+		{"nor", `{"selector":{"id":{"$exists":true},"$nor":[{"status":"CREATED"},{"name":{"$exists":true}}]}}`, []string{"CASE_002", "CASE_003"}},
+		{"not", `{"selector":{"status":{"$exists":true},"$not":{"currentOrg":"LawyersOrg"}}}`, []string{"CASE_002", "CASE_003"}},
+		{"numbers sort before strings", `{"selector":{"fee":{"$lt":"0"}}}`, []string{"CASE_003"}},
+		{"type", `{"selector":{"judgment":{"$type":"object"}}}`, []string{"CASE_003"}},
+		{"sort desc", `{"selector":{"createdAt":{"$gt":null}},"sort":[{"createdAt":"desc"}]}`, []string{"CASE_003", "CASE_001", "CASE_002"}},
+		{"sort asc with skip and limit", `{"selector":{"createdAt":{"$gt":null}},"sort":["createdAt"],"skip":1,"limit":1}`, []string{"CASE_001"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, err := parseQuery(tt.query)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			results, err := q.run(queryRecords)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			var keys []string
+			for _, r := range results {
+				keys = append(keys, r.key)
+			}
+			if !reflect.DeepEqual(keys, tt.want) {
+				t.Errorf("got %v, want %v", keys, tt.want)
+			}
+		})
+	}
+}
+
+func TestRichQueryFields(t *testing.T) {
+	q, err := parseQuery(`{"selector":{"id":"CASE_003"},"fields":["id","judgment.decision"]}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	results, err := q.run(queryRecords)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 || string(results[0].value) != `{"id":"CASE_003","judgment":{"decision":"Allowed"}}` {
+		t.Errorf("got %q", results)
+	}
+}
+
+func TestRichQueryErrors(t *testing.T) {
+	tests := []struct {
+		query   string
+		wantErr string
+	}{
+		{`{"status":"CREATED"}`, "has no selector"},
+		{`{"selector":"status"}`, "invalid query"},
+		{`{"selector":{},"limits":1}`, "unsupported query field"},
+		{`{"selector":{"status":{"$like":"CREATED"}}}`, "unsupported operator $like"},
+		{`{"selector":{"status":{"$in":"CREATED"}}}`, "$in takes an array"},
+		{`{"selector":{"title":{"$regex":"("}}}`, "invalid $regex"},
+		{`{"selector":{},"sort":[{"createdAt":"up"}]}`, "asc or desc"},
+	}
+
+	for _, tt := range tests {
+		q, err := parseQuery(tt.query)
+		if err == nil {
+			_, err = q.run(queryRecords)
+		}
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("%s: expected error containing %q, got %v", tt.query, tt.wantErr, err)
+		}
+	}
+}
@@ -0,0 +1,538 @@
+package mockstub
+
+import (
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes/timestamp"
+	"github.com/hyperledger/fabric-chaincode-go/pkg/cid"
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/hyperledger/fabric-protos-go/common"
+	"github.com/hyperledger/fabric-protos-go/ledger/queryresult"
+	"github.com/hyperledger/fabric-protos-go/peer"
+)
+
+// Composite keys are built the same way as the shim builds them
+const (
+	compositeKeyNamespace = "\x00"
+	minUnicodeRuneValue   = 0
+	maxUnicodeRuneValue   = utf8.MaxRune
+)
+
+// errUnsupported is returned by the stub functions the network does not model
+var errUnsupported = errors.New("not supported by mockstub")
+
+// transaction holds the state shared by a chaincode and the chaincodes it invokes on the
+// same channel
+type transaction struct {
+	id        string
+	run       int // times the client sent the transaction before
+	channel   string
+	timestamp *timestamp.Timestamp
+	nonce     []byte
+	creator   []byte
+	key       *ecdsa.PrivateKey // key the client signs the proposal with
+	signed    map[string][]byte // signatures the client made for the transaction, by digest
+	transient map[string][]byte
+	proposal  *peer.SignedProposal
+	writes    map[ledgerKey]map[string][]byte // a nil value deletes the key
+	private   map[collectionKey]map[string][]byte
+	paginated bool
+	running   map[ledgerKey]bool // chaincodes executing the transaction, on every channel
+}
+
+// onChannel returns a transaction for a call to another channel. It shares everything
+// with tx but its writes, which are never committed.
+func (tx *transaction) onChannel(channel string) *transaction {
+	return &transaction{
+		id:        tx.id,
+		run:       tx.run,
+		channel:   channel,
+		timestamp: tx.timestamp,
+		nonce:     tx.nonce,
+		creator:   tx.creator,
+		key:       tx.key,
+		transient: tx.transient,
+		proposal:  tx.proposal,
+		writes:    make(map[ledgerKey]map[string][]byte),
+		private:   make(map[collectionKey]map[string][]byte),
+		running:   tx.running,
+	}
+}
+
+// Stub is the shim.ChaincodeStubInterface one chaincode sees during a transaction
+type Stub struct {
+	network   *Network
+	tx        *transaction
+	chaincode string
+	args      [][]byte
+	event     *peer.ChaincodeEvent
+}
+
+var _ shim.ChaincodeStubInterface = (*Stub)(nil)
+
+// Context returns a contract API transaction context around the stub, set up the way
+// the contract API sets it up before calling a contract function
+func (s *Stub) Context() *contractapi.TransactionContext {
+	ctx := new(contractapi.TransactionContext)
+	ctx.SetStub(s)
+	if identity, err := cid.New(s); err == nil {
+		ctx.SetClientIdentity(identity)
+	}
+	return ctx
+}
+
+// signedProposal builds the proposal a client would send for invoking chaincode with args,
+// signed with the client's key as Fabric SDKs sign it
+func signedProposal(tx *transaction, chaincode string, args [][]byte) (*peer.SignedProposal, error) {
+	channelHeader, err := proto.Marshal(&common.ChannelHeader{
+		Type:      int32(common.HeaderType_ENDORSER_TRANSACTION),
+		ChannelId: tx.channel,
+		TxId:      tx.id,
+		Timestamp: tx.timestamp,
+	})
+	if err != nil {
+		return nil, err
+	}
+	signatureHeader, err := proto.Marshal(&common.SignatureHeader{Creator: tx.creator, Nonce: tx.nonce})
+	if err != nil {
+		return nil, err
+	}
+	header, err := proto.Marshal(&common.Header{ChannelHeader: channelHeader, SignatureHeader: signatureHeader})
+	if err != nil {
+		return nil, err
+	}
+	input, err := proto.Marshal(&peer.ChaincodeInvocationSpec{ChaincodeSpec: &peer.ChaincodeSpec{
+		Type:        peer.ChaincodeSpec_GOLANG,
+		ChaincodeId: &peer.ChaincodeID{Name: chaincode},
+		Input:       &peer.ChaincodeInput{Args: args},
+	}})
+	if err != nil {
+		return nil, err
+	}
+	payload, err := proto.Marshal(&peer.ChaincodeProposalPayload{Input: input, TransientMap: tx.transient})
+	if err != nil {
+		return nil, err
+	}
+	proposal, err := proto.Marshal(&peer.Proposal{Header: header, Payload: payload})
+	if err != nil {
+		return nil, err
+	}
+	digest := sha256.Sum256(proposal)
+	signature, err := ecdsa.SignASN1(rand.Reader, tx.key, digest[:])
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign proposal: %v", err)
+	}
+	return &peer.SignedProposal{ProposalBytes: proposal, Signature: signature}, nil
+}
+
+// GetArgs returns the function name and arguments
+func (s *Stub) GetArgs() [][]byte {
+	return s.args
+}
+
+// GetStringArgs returns the function name and arguments as strings
+func (s *Stub) GetStringArgs() []string {
+	args := make([]string, len(s.args))
+	for i, arg := range s.args {
+		args[i] = string(arg)
+	}
+	return args
+}
+
+// GetFunctionAndParameters returns the function name and its arguments
+func (s *Stub) GetFunctionAndParameters() (string, []string) {
+	args := s.GetStringArgs()
+	if len(args) == 0 {
+		return "", []string{}
+	}
+	return args[0], args[1:]
+}
+
+// GetArgsSlice returns the arguments joined into one slice
+func (s *Stub) GetArgsSlice() ([]byte, error) {
+	var joined []byte
+	for _, arg := range s.args {
+		joined = append(joined, arg...)
+	}
+	return joined, nil
+}
+
+// GetTxID returns the transaction ID
+func (s *Stub) GetTxID() string {
+	return s.tx.id
+}
+
+// Run returns how many times the transaction was sent before this run. A client that
+// signs the writes of a transaction sends it again to carry the signatures, so the
+// chaincodes it calls see it more than once; only the last run is committed.
+func (s *Stub) Run() int {
+	return s.tx.run
+}
+
+// GetChannelID returns the channel the chaincode runs on
+func (s *Stub) GetChannelID() string {
+	return s.tx.channel
+}
+
+// InvokeChaincode calls another chaincode through the network's router. A call on the
+// same channel joins this transaction; a call on another channel can only read.
+func (s *Stub) InvokeChaincode(chaincodeName string, args [][]byte, channel string) peer.Response {
+	if channel == "" {
+		channel = s.tx.channel
+	}
+	chaincode, err := s.network.Route(chaincodeName, channel)
+	if err != nil {
+		return shim.Error(fmt.Sprintf("failed to invoke chaincode %s on channel %s: %v", chaincodeName, channel, err))
+	}
+
+	// The peer keeps one context per chaincode, channel and transaction, so a chaincode
+	// cannot be re-entered on a channel where it is already running
+	key := ledgerKey{channel: channel, chaincode: chaincodeName}
+	if s.tx.running[key] {
+		return shim.Error(fmt.Sprintf("failed to invoke chaincode %s on channel %s: txid: %s(%s) exists", chaincodeName, channel, s.tx.id, channel))
+	}
+	s.tx.running[key] = true
+	defer delete(s.tx.running, key)
+
+	tx := s.tx
+	if channel != s.tx.channel {
+		tx = s.tx.onChannel(channel)
+	}
+	return chaincode.Invoke(&Stub{network: s.network, tx: tx, chaincode: chaincodeName, args: args})
+}
+
+// ledgerKey returns the key of the state this chaincode reads and writes
+func (s *Stub) ledgerKey() ledgerKey {
+	return ledgerKey{channel: s.tx.channel, chaincode: s.chaincode}
+}
+
+func (s *Stub) ledger() *ledger {
+	return s.network.ledger(s.tx.channel, s.chaincode)
+}
+
+// GetState returns the committed value of key. Writes made earlier in the transaction
+// are not visible, as on a peer.
+func (s *Stub) GetState(key string) ([]byte, error) {
+	value, ok := s.ledger().state[key]
+	if !ok {
+		return nil, nil
+	}
+	return append([]byte(nil), value...), nil
+}
+
+// PutState records a write to be committed with the transaction
+func (s *Stub) PutState(key string, value []byte) error {
+	if key == "" {
+		return errors.New("key must not be an empty string")
+	}
+	if len(value) == 0 {
+		// The peer treats an empty value as a delete
+		s.write(key, nil)
+		return nil
+	}
+	s.write(key, append([]byte(nil), value...))
+	return nil
+}
+
+// DelState records a delete to be committed with the transaction
+func (s *Stub) DelState(key string) error {
+	if key == "" {
+		return errors.New("key must not be an empty string")
+	}
+	s.write(key, nil)
+	return nil
+}
+
+func (s *Stub) write(key string, value []byte) {
+	writes, ok := s.tx.writes[s.ledgerKey()]
+	if !ok {
+		writes = make(map[string][]byte)
+		s.tx.writes[s.ledgerKey()] = writes
+	}
+	writes[key] = value
+}
+
+// SetStateValidationParameter is not supported
+func (s *Stub) SetStateValidationParameter(key string, ep []byte) error {
+	return errUnsupported
+}
+
+// GetStateValidationParameter is not supported
+func (s *Stub) GetStateValidationParameter(key string) ([]byte, error) {
+	return nil, errUnsupported
+}
+
+// GetStateByRange returns the committed keys from startKey up to but excluding endKey.
+// An empty endKey leaves the range open.
+func (s *Stub) GetStateByRange(startKey string, endKey string) (shim.StateQueryIteratorInterface, error) {
+	if err := validateSimpleKeys(startKey, endKey); err != nil {
+		return nil, err
+	}
+	return &stateIterator{results: s.rangeResults(startKey, endKey, false)}, nil
+}
+
+// GetStateByRangeWithPagination returns a page of GetStateByRange. The bookmark is the
+// key of the last record returned.
+func (s *Stub) GetStateByRangeWithPagination(startKey string, endKey string, pageSize int32, bookmark string) (shim.StateQueryIteratorInterface, *peer.QueryResponseMetadata, error) {
+	if err := validateSimpleKeys(startKey, endKey); err != nil {
+		return nil, nil, err
+	}
+	s.tx.paginated = true
+	return page(s.rangeResults(startKey, endKey, false), pageSize, bookmark)
+}
+
+// GetStateByPartialCompositeKey returns the committed composite keys that start with
+// objectType and keys
+func (s *Stub) GetStateByPartialCompositeKey(objectType string, keys []string) (shim.StateQueryIteratorInterface, error) {
+	startKey, err := s.CreateCompositeKey(objectType, keys)
+	if err != nil {
+		return nil, err
+	}
+	return &stateIterator{results: s.rangeResults(startKey, startKey+string(rune(maxUnicodeRuneValue)), true)}, nil
+}
+
+// GetStateByPartialCompositeKeyWithPagination returns a page of
+// GetStateByPartialCompositeKey
+func (s *Stub) GetStateByPartialCompositeKeyWithPagination(objectType string, keys []string, pageSize int32, bookmark string) (shim.StateQueryIteratorInterface, *peer.QueryResponseMetadata, error) {
+	startKey, err := s.CreateCompositeKey(objectType, keys)
+	if err != nil {
+		return nil, nil, err
+	}
+	s.tx.paginated = true
+	return page(s.rangeResults(startKey, startKey+string(rune(maxUnicodeRuneValue)), true), pageSize, bookmark)
+}
+
+// rangeResults returns the committed records in [startKey, endKey). Range queries on
+// simple keys skip composite keys.
+func (s *Stub) rangeResults(startKey string, endKey string, composite bool) []*queryresult.KV {
+	l := s.ledger()
+	var results []*queryresult.KV
+	for _, key := range l.keys() {
+		if !composite && strings.HasPrefix(key, compositeKeyNamespace) {
+			continue
+		}
+		if key < startKey || (endKey != "" && key >= endKey) {
+			continue
+		}
+		results = append(results, &queryresult.KV{Namespace: s.chaincode, Key: key, Value: l.state[key]})
+	}
+	return results
+}
+
+func validateSimpleKeys(keys ...string) error {
+	for _, key := range keys {
+		if strings.HasPrefix(key, compositeKeyNamespace) {
+			return fmt.Errorf("first character of the key [%s] contains a null character which is not allowed", key)
+		}
+	}
+	return nil
+}
+
+// CreateCompositeKey builds a composite key as the shim does
+func (s *Stub) CreateCompositeKey(objectType string, attributes []string) (string, error) {
+	return shim.CreateCompositeKey(objectType, attributes)
+}
+
+// SplitCompositeKey splits a composite key into its object type and attributes
+func (s *Stub) SplitCompositeKey(compositeKey string) (string, []string, error) {
+	if !strings.HasPrefix(compositeKey, compositeKeyNamespace) {
+		return "", nil, fmt.Errorf("%q is not a composite key", compositeKey)
+	}
+	parts := strings.Split(compositeKey[len(compositeKeyNamespace):], string(rune(minUnicodeRuneValue)))
+	if len(parts) < 2 || parts[len(parts)-1] != "" {
+		return "", nil, fmt.Errorf("%q is not a composite key", compositeKey)
+	}
+	return parts[0], parts[1 : len(parts)-1], nil
+}
+
+// GetQueryResult runs a CouchDB rich query against the committed JSON values
+func (s *Stub) GetQueryResult(query string) (shim.StateQueryIteratorInterface, error) {
+	results, err := s.queryResults(query)
+	if err != nil {
+		return nil, err
+	}
+	return &stateIterator{results: results}, nil
+}
+
+// GetQueryResultWithPagination returns a page of GetQueryResult. The bookmark is the key
+// of the last record returned.
+func (s *Stub) GetQueryResultWithPagination(query string, pageSize int32, bookmark string) (shim.StateQueryIteratorInterface, *peer.QueryResponseMetadata, error) {
+	results, err := s.queryResults(query)
+	if err != nil {
+		return nil, nil, err
+	}
+	s.tx.paginated = true
+	return page(results, pageSize, bookmark)
+}
+
+func (s *Stub) queryResults(query string) ([]*queryresult.KV, error) {
+	q, err := parseQuery(query)
+	if err != nil {
+		return nil, err
+	}
+	l := s.ledger()
+	var records []record
+	for _, key := range l.keys() {
+		records = append(records, record{key: key, value: l.state[key]})
+	}
+	matched, err := q.run(records)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]*queryresult.KV, len(matched))
+	for i, r := range matched {
+		results[i] = &queryresult.KV{Namespace: s.chaincode, Key: r.key, Value: r.value}
+	}
+	return results, nil
+}
+
+// page returns the records after bookmark, at most pageSize of them
+func page(results []*queryresult.KV, pageSize int32, bookmark string) (shim.StateQueryIteratorInterface, *peer.QueryResponseMetadata, error) {
+	if pageSize <= 0 {
+		return nil, nil, fmt.Errorf("page size must be positive, got %d", pageSize)
+	}
+	if bookmark != "" {
+		start := -1
+		for i, result := range results {
+			if result.Key == bookmark {
+				start = i + 1
+				break
+			}
+		}
+		if start < 0 {
+			return nil, nil, fmt.Errorf("invalid bookmark %q", bookmark)
+		}
+		results = results[start:]
+	}
+	if len(results) > int(pageSize) {
+		results = results[:pageSize]
+	}
+	if len(results) > 0 {
+		bookmark = results[len(results)-1].Key
+	}
+	metadata := &peer.QueryResponseMetadata{FetchedRecordsCount: int32(len(results)), Bookmark: bookmark}
+	return &stateIterator{results: results}, metadata, nil
+}
+
+// GetHistoryForKey returns the committed changes to key, newest first
+func (s *Stub) GetHistoryForKey(key string) (shim.HistoryQueryIteratorInterface, error) {
+	changes := s.ledger().history[key]
+	history := make([]*queryresult.KeyModification, len(changes))
+	for i, change := range changes {
+		history[len(changes)-1-i] = change
+	}
+	return &historyIterator{results: history}, nil
+}
+
+// PurgePrivateData is not supported
+func (s *Stub) PurgePrivateData(collection string, key string) error {
+	return errUnsupported
+}
+
+// SetPrivateDataValidationParameter is not supported
+func (s *Stub) SetPrivateDataValidationParameter(collection string, key string, ep []byte) error {
+	return errUnsupported
+}
+
+// GetPrivateDataValidationParameter is not supported
+func (s *Stub) GetPrivateDataValidationParameter(collection string, key string) ([]byte, error) {
+	return nil, errUnsupported
+}
+
+// GetPrivateDataByRange is not supported
+func (s *Stub) GetPrivateDataByRange(collection string, startKey string, endKey string) (shim.StateQueryIteratorInterface, error) {
+	return nil, errUnsupported
+}
+
+// GetPrivateDataByPartialCompositeKey is not supported
+func (s *Stub) GetPrivateDataByPartialCompositeKey(collection string, objectType string, keys []string) (shim.StateQueryIteratorInterface, error) {
+	return nil, errUnsupported
+}
+
+// GetPrivateDataQueryResult is not supported
+func (s *Stub) GetPrivateDataQueryResult(collection string, query string) (shim.StateQueryIteratorInterface, error) {
+	return nil, errUnsupported
+}
+
+// GetCreator returns the serialized identity of the client
+func (s *Stub) GetCreator() ([]byte, error) {
+	return s.tx.creator, nil
+}
+
+// GetTransient returns the transient data sent with the proposal
+func (s *Stub) GetTransient() (map[string][]byte, error) {
+	return s.tx.transient, nil
+}
+
+// GetBinding returns a hash binding the proposal to the creator
+func (s *Stub) GetBinding() ([]byte, error) {
+	binding := sha256.Sum256(append(append([]byte(nil), s.tx.nonce...), s.tx.creator...))
+	return binding[:], nil
+}
+
+// GetDecorations returns no decorations
+func (s *Stub) GetDecorations() map[string][]byte {
+	return map[string][]byte{}
+}
+
+// GetSignedProposal returns the proposal sent by the client
+func (s *Stub) GetSignedProposal() (*peer.SignedProposal, error) {
+	return s.tx.proposal, nil
+}
+
+// GetTxTimestamp returns the transaction timestamp, taken from the network's clock
+func (s *Stub) GetTxTimestamp() (*timestamp.Timestamp, error) {
+	return s.tx.timestamp, nil
+}
+
+// SetEvent sets the event committed with the transaction. Only the event of the
+// chaincode the client invoked is kept, and a later call replaces an earlier one.
+func (s *Stub) SetEvent(name string, payload []byte) error {
+	if name == "" {
+		return errors.New("event name can not be empty string")
+	}
+	s.event = &peer.ChaincodeEvent{ChaincodeId: s.chaincode, TxId: s.tx.id, EventName: name, Payload: payload}
+	return nil
+}
+
+// stateIterator iterates over query results
+type stateIterator struct {
+	results []*queryresult.KV
+}
+
+func (i *stateIterator) HasNext() bool { return len(i.results) > 0 }
+func (i *stateIterator) Close() error  { return nil }
+
+func (i *stateIterator) Next() (*queryresult.KV, error) {
+	if len(i.results) == 0 {
+		return nil, errors.New("no more results")
+	}
+	next := i.results[0]
+	i.results = i.results[1:]
+	return next, nil
+}
+
+// historyIterator iterates over the changes to a key
+type historyIterator struct {
+	results []*queryresult.KeyModification
+}
+
+func (i *historyIterator) HasNext() bool { return len(i.results) > 0 }
+func (i *historyIterator) Close() error  { return nil }
+
+func (i *historyIterator) Next() (*queryresult.KeyModification, error) {
+	if len(i.results) == 0 {
+		return nil, errors.New("no more results")
+	}
+	next := i.results[0]
+	i.results = i.results[1:]
+	return next, nil
+}
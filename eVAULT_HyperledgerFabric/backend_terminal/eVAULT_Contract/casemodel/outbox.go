@@ -0,0 +1,463 @@
+package casemodel
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/hyperledger/fabric-protos-go/peer"
+)
+
+// Transfer statuses
+const (
+	TransferPending = "PENDING"
+	TransferClaimed = "CLAIMED"
+)
+
+// receiveFunction is the transaction receivers claim their transfers through, see
+// ReceiveTransfers
+const receiveFunction = "ReceiveTransfers"
+
+// Composite key object types of the outbox. Composite keys are skipped by range
+// queries, so transfers never show up as cases.
+const (
+	transferType         = "transfer"
+	transferSequenceType = "transferSequence"
+)
+
+// Transfer is a case handed to another organization, kept in the sender's outbox until
+// the receiver has stored it
+type Transfer struct {
+	Sequence  uint64 `json:"sequence"`
+	CaseID    string `json:"caseId"`
+	Hop       string `json:"hop"`
+	Function  string `json:"function"` // transaction of the receiving chaincode that stores the case
+	From      string `json:"from"`
+	To        string `json:"to"`
+	Channel   string `json:"channel" metadata:",optional"` // channel the receiver stores the case on
+	Case      *Case  `json:"case"`
+	Status    string `json:"status"`
+	Attempts  int    `json:"attempts"`
+	LastError string `json:"lastError" metadata:",optional"`
+	CreatedAt string `json:"createdAt"`
+	ClaimedAt string `json:"claimedAt" metadata:",optional"`
+	ClaimedBy string `json:"claimedBy" metadata:",optional"`
+}
+
+// invocation returns the chaincode and transaction the client's proposal invoked, which
+// differ from the running ones when the transaction reached this chaincode through
+// another one
+func invocation(ctx contractapi.TransactionContextInterface) (string, string, error) {
+	signedProposal, err := ctx.GetStub().GetSignedProposal()
+	if err != nil || signedProposal == nil {
+		return "", "", fmt.Errorf("failed to get signed proposal: %v", err)
+	}
+	proposal := &peer.Proposal{}
+	if err := proto.Unmarshal(signedProposal.ProposalBytes, proposal); err != nil {
+		return "", "", fmt.Errorf("failed to unmarshal proposal: %v", err)
+	}
+	payload := &peer.ChaincodeProposalPayload{}
+	if err := proto.Unmarshal(proposal.Payload, payload); err != nil {
+		return "", "", fmt.Errorf("failed to unmarshal proposal payload: %v", err)
+	}
+	spec := &peer.ChaincodeInvocationSpec{}
+	if err := proto.Unmarshal(payload.Input, spec); err != nil {
+		return "", "", fmt.Errorf("failed to unmarshal chaincode invocation: %v", err)
+	}
+	if spec.ChaincodeSpec == nil || spec.ChaincodeSpec.ChaincodeId == nil || spec.ChaincodeSpec.Input == nil || len(spec.ChaincodeSpec.Input.Args) == 0 {
+		return "", "", fmt.Errorf("proposal does not name a chaincode function")
+	}
+	function := string(spec.ChaincodeSpec.Input.Args[0])
+	if i := strings.LastIndex(function, ":"); i >= 0 {
+		function = function[i+1:]
+	}
+	return spec.ChaincodeSpec.ChaincodeId.Name, function, nil
+}
+
+func transferKey(ctx contractapi.TransactionContextInterface, sequence uint64) (string, error) {
+	key, err := ctx.GetStub().CreateCompositeKey(transferType, []string{fmt.Sprintf("%020d", sequence)})
+	if err != nil {
+		return "", fmt.Errorf("failed to create transfer key: %v", err)
+	}
+	return key, nil
+}
+
+// nextTransferSequence increments the outbox sequence number and returns it. The ledger
+// does not return the number a transaction stored until it commits, so a second transfer
+// in the same transaction continues from the number TransactionContext kept.
+func nextTransferSequence(ctx contractapi.TransactionContextInterface) (uint64, error) {
+	key, err := ctx.GetStub().CreateCompositeKey(transferSequenceType, []string{})
+	if err != nil {
+		return 0, fmt.Errorf("failed to create transfer sequence key: %v", err)
+	}
+	tc, _ := ctx.(*TransactionContext)
+	var sequence uint64
+	if tc != nil && tc.transferSequence != 0 {
+		sequence = tc.transferSequence
+	} else {
+		value, err := ctx.GetStub().GetState(key)
+		if err != nil {
+			return 0, fmt.Errorf("failed to read transfer sequence: %v", err)
+		}
+		if value != nil {
+			if sequence, err = strconv.ParseUint(string(value), 10, 64); err != nil {
+				return 0, fmt.Errorf("failed to parse transfer sequence: %v", err)
+			}
+		}
+	}
+	sequence++
+	if err := ctx.GetStub().PutState(key, []byte(strconv.FormatUint(sequence, 10))); err != nil {
+		return 0, fmt.Errorf("failed to store transfer sequence: %v", err)
+	}
+	if tc != nil {
+		tc.transferSequence = sequence
+	}
+	return sequence, nil
+}
+
+func putTransfer(ctx contractapi.TransactionContextInterface, t *Transfer) error {
+	key, err := transferKey(ctx, t.Sequence)
+	if err != nil {
+		return err
+	}
+	value, err := json.Marshal(t)
+	if err != nil {
+		return fmt.Errorf("failed to marshal transfer: %v", err)
+	}
+	if err := ctx.GetStub().PutState(key, value); err != nil {
+		return fmt.Errorf("failed to store transfer %d: %v", t.Sequence, err)
+	}
+	return nil
+}
+
+// GetTransfer reads a transfer from the outbox
+func GetTransfer(ctx contractapi.TransactionContextInterface, sequence uint64) (*Transfer, error) {
+	key, err := transferKey(ctx, sequence)
+	if err != nil {
+		return nil, err
+	}
+	value, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read transfer %d: %v", sequence, err)
+	}
+	if value == nil {
+		return nil, fmt.Errorf("transfer %d does not exist", sequence)
+	}
+	var t Transfer
+	if err := json.Unmarshal(value, &t); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal transfer %d: %v", sequence, err)
+	}
+	return &t, nil
+}
+
+// Handoff records c in the outbox as a transfer to the organization now holding it, then
+// delivers it by invoking function on the chaincode routed for hop. A transfer that cannot
+// be delivered stays pending until the receiver claims it or it is retried, so the
+// sender's update commits either way.
+func Handoff(ctx contractapi.TransactionContextInterface, hop string, function string, c *Case) (*Transfer, error) {
+	return handoff(ctx, hop, function, c, c.CurrentOrg)
+}
+
+// Sync records c in the outbox as a transfer to the sender's own organization, for its
+// copy of the case on the channel hop is routed to. Writes made through a call to another
+// channel are discarded, so that copy pulls the case with ReceiveTransfers instead.
+func Sync(ctx contractapi.TransactionContextInterface, hop string, c *Case) (*Transfer, error) {
+	org, err := ClientOrg(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return handoff(ctx, hop, receiveFunction, c, org)
+}
+
+func handoff(ctx contractapi.TransactionContextInterface, hop string, function string, c *Case, to string) (*Transfer, error) {
+	value, err := json.Marshal(c)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal case: %v", err)
+	}
+	snapshot, err := DecodeCase(value)
+	if err != nil {
+		return nil, err
+	}
+	from, err := ClientOrg(ctx)
+	if err != nil {
+		return nil, err
+	}
+	timestamp, err := TxTime(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sequence, err := nextTransferSequence(ctx)
+	if err != nil {
+		return nil, err
+	}
+	config, err := GetRouting(ctx)
+	if err != nil {
+		return nil, err
+	}
+	channel := ""
+	if route, ok := config.Routes[hop]; ok {
+		if channel = route.Channel; channel == "" {
+			channel = ctx.GetStub().GetChannelID()
+		}
+	}
+
+	t := &Transfer{
+		Sequence:  sequence,
+		CaseID:    c.ID,
+		Hop:       hop,
+		Function:  function,
+		From:      from,
+		To:        to,
+		Channel:   channel,
+		Case:      snapshot,
+		Status:    TransferPending,
+		CreatedAt: timestamp.Format(time.RFC3339),
+	}
+	if err := deliver(ctx, t); err != nil {
+		return nil, err
+	}
+	return t, putTransfer(ctx, t)
+}
+
+// deliver pushes a pending transfer to its receiver. Writes made through a call to another
+// channel are discarded by Fabric, so a transfer routed to another channel is left for
+// the receiver to pull, and only marked claimed once the receiver's copy of the case
+// holds it.
+func deliver(ctx contractapi.TransactionContextInterface, t *Transfer) error {
+	config, err := GetRouting(ctx)
+	if err != nil {
+		return err
+	}
+	route, ok := config.Routes[t.Hop]
+	if !ok {
+		t.LastError = fmt.Sprintf("route %s is not configured", t.Hop)
+		return nil
+	}
+	if route.Channel != "" && route.Channel != ctx.GetStub().GetChannelID() {
+		response := ctx.GetStub().InvokeChaincode(route.Chaincode, [][]byte{[]byte("GetCaseById"), []byte(t.CaseID)}, route.Channel)
+		if response.Status != shim.OK || !holds(response.Payload, t.Case) {
+			t.LastError = fmt.Sprintf("%s is on channel %s, waiting for it to claim the transfer", route.Chaincode, route.Channel)
+			return nil
+		}
+		timestamp, err := TxTime(ctx)
+		if err != nil {
+			return err
+		}
+		t.Status, t.ClaimedAt, t.ClaimedBy, t.LastError = TransferClaimed, timestamp.Format(time.RFC3339), t.To, ""
+		return nil
+	}
+
+	value, err := json.Marshal(t.Case)
+	if err != nil {
+		return fmt.Errorf("failed to marshal case: %v", err)
+	}
+	t.Attempts++
+	response := ctx.GetStub().InvokeChaincode(route.Chaincode, [][]byte{[]byte(t.Function), value}, route.Channel)
+	if response.Status != shim.OK {
+		t.LastError = response.Message
+		return nil
+	}
+	timestamp, err := TxTime(ctx)
+	if err != nil {
+		return err
+	}
+	t.Status, t.ClaimedAt, t.ClaimedBy, t.LastError = TransferClaimed, timestamp.Format(time.RFC3339), t.To, ""
+	return nil
+}
+
+// holds reports whether the stored case caseJSON already contains the last link of c's
+// hash chain, that is whether it was stored from c or from a later copy
+func holds(caseJSON []byte, c *Case) bool {
+	if caseJSON == nil || c == nil || len(c.HashChain) == 0 {
+		return false
+	}
+	stored, err := DecodeCase(caseJSON)
+	if err != nil {
+		return false
+	}
+	head := c.HashChain[len(c.HashChain)-1].Hash
+	for _, l := range stored.HashChain {
+		if l.Hash == head {
+			return true
+		}
+	}
+	return false
+}
+
+// ListPendingTransfers returns the transfers in the outbox that have not been claimed, in
+// the order they were made
+func ListPendingTransfers(ctx contractapi.TransactionContextInterface) ([]*Transfer, error) {
+	iterator, err := ctx.GetStub().GetStateByPartialCompositeKey(transferType, []string{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query transfers: %v", err)
+	}
+	defer iterator.Close()
+
+	transfers := make([]*Transfer, 0)
+	for iterator.HasNext() {
+		result, err := iterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read transfer: %v", err)
+		}
+		var t Transfer
+		if err := json.Unmarshal(result.Value, &t); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal transfer: %v", err)
+		}
+		if t.Status == TransferPending {
+			transfers = append(transfers, &t)
+		}
+	}
+	return transfers, nil
+}
+
+// ClaimTransfer acknowledges a pending transfer on behalf of the receiving organization
+// and returns it with the case. A claim only stores the case when it is made by
+// ReceiveTransfers, so the transaction must have been submitted to ReceiveTransfers of
+// the chaincode the transfer's hop is routed to; a claim submitted directly would mark
+// the transfer claimed with nobody holding the case.
+func ClaimTransfer(ctx contractapi.TransactionContextInterface, sequence uint64) (*Transfer, error) {
+	t, err := GetTransfer(ctx, sequence)
+	if err != nil {
+		return nil, err
+	}
+	if t.Status != TransferPending {
+		return nil, fmt.Errorf("transfer %d was already claimed by %s", sequence, t.ClaimedBy)
+	}
+	org, err := ClientOrg(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if org != t.To {
+		return nil, fmt.Errorf("transfer %d is addressed to %s, not %s", sequence, t.To, org)
+	}
+	config, err := GetRouting(ctx)
+	if err != nil {
+		return nil, err
+	}
+	chaincode, function, err := invocation(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if receiver := config.Routes[t.Hop].Chaincode; chaincode != receiver || function != receiveFunction {
+		return nil, fmt.Errorf("transfer %d can only be claimed by %s of %s, not %s of %s", sequence, receiveFunction, receiver, function, chaincode)
+	}
+	timestamp, err := TxTime(ctx)
+	if err != nil {
+		return nil, err
+	}
+	t.Status, t.ClaimedAt, t.ClaimedBy, t.LastError = TransferClaimed, timestamp.Format(time.RFC3339), org, ""
+	if err := putTransfer(ctx, t); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// RetryTransfer delivers a pending transfer again, recording the outcome
+func RetryTransfer(ctx contractapi.TransactionContextInterface, sequence uint64) (*Transfer, error) {
+	t, err := GetTransfer(ctx, sequence)
+	if err != nil {
+		return nil, err
+	}
+	if t.Status != TransferPending {
+		return nil, fmt.Errorf("transfer %d was already claimed by %s", sequence, t.ClaimedBy)
+	}
+	if err := deliver(ctx, t); err != nil {
+		return nil, err
+	}
+	if err := putTransfer(ctx, t); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// ReceiveTransfers claims the transfers addressed to the caller's organization on this
+// channel from the outboxes of the chaincodes routed for hops, and passes each case to
+// store. It returns the IDs of the cases received. Claims are written to the sender's
+// ledger, which a call to another channel cannot do, so hops routed to another channel
+// are skipped, except those to the running chaincode's own outbox there: cases synced
+// from it are stored unless this channel already holds them, and it marks the transfers
+// claimed when it retries them.
+func ReceiveTransfers(ctx contractapi.TransactionContextInterface, store func(caseJSON string) error, hops ...string) ([]string, error) {
+	config, err := GetRouting(ctx)
+	if err != nil {
+		return nil, err
+	}
+	org, err := ClientOrg(ctx)
+	if err != nil {
+		return nil, err
+	}
+	running, _, err := invocation(ctx)
+	if err != nil {
+		return nil, err
+	}
+	channel := ctx.GetStub().GetChannelID()
+
+	received := make([]string, 0)
+	for _, hop := range hops {
+		route, ok := config.Routes[hop]
+		if !ok {
+			return nil, fmt.Errorf("route %s is not configured", hop)
+		}
+		// A chaincode cannot call itself on its own channel
+		remote := route.Channel != "" && route.Channel != channel
+		if own := route.Chaincode == running; remote != own {
+			continue
+		}
+
+		response := ctx.GetStub().InvokeChaincode(route.Chaincode, [][]byte{[]byte("ListPendingTransfers")}, route.Channel)
+		if response.Status != shim.OK {
+			return nil, fmt.Errorf("failed to list pending transfers of %s: %s", route.Chaincode, response.Message)
+		}
+		var pending []*Transfer
+		if err := json.Unmarshal(response.Payload, &pending); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal pending transfers: %v", err)
+		}
+
+		for _, t := range pending {
+			if t.To != org || (t.Channel != "" && t.Channel != channel) || (remote && t.Channel == "") {
+				continue
+			}
+			if remote {
+				storedJSON, err := ctx.GetStub().GetState(t.CaseID)
+				if err != nil {
+					return nil, fmt.Errorf("failed to read case: %v", err)
+				}
+				if holds(storedJSON, t.Case) {
+					continue
+				}
+				value, err := json.Marshal(t.Case)
+				if err != nil {
+					return nil, fmt.Errorf("failed to marshal case: %v", err)
+				}
+				if err := store(string(value)); err != nil {
+					return nil, fmt.Errorf("failed to store case %s from transfer %d: %v", t.CaseID, t.Sequence, err)
+				}
+				received = append(received, t.CaseID)
+				continue
+			}
+			sequence := strconv.FormatUint(t.Sequence, 10)
+			response := ctx.GetStub().InvokeChaincode(route.Chaincode, [][]byte{[]byte("ClaimTransfer"), []byte(sequence)}, route.Channel)
+			if response.Status != shim.OK {
+				return nil, fmt.Errorf("failed to claim transfer %d from %s: %s", t.Sequence, route.Chaincode, response.Message)
+			}
+			var claimed Transfer
+			if err := json.Unmarshal(response.Payload, &claimed); err != nil {
+				return nil, fmt.Errorf("failed to unmarshal transfer %d: %v", t.Sequence, err)
+			}
+			value, err := json.Marshal(claimed.Case)
+			if err != nil {
+				return nil, fmt.Errorf("failed to marshal case: %v", err)
+			}
+			if err := store(string(value)); err != nil {
+				return nil, fmt.Errorf("failed to store case %s from transfer %d: %v", claimed.CaseID, t.Sequence, err)
+			}
+			received = append(received, claimed.CaseID)
+		}
+	}
+	return received, nil
+}
@@ -0,0 +1,369 @@
+package casemodel
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+
+	"casemodel/mockstub"
+)
+
+// outbox is a sending contract that hands cases to the bench clerk
+type outbox struct {
+	contractapi.Contract
+}
+
+func (o *outbox) Send(ctx contractapi.TransactionContextInterface, caseJSON string) (*Transfer, error) {
+	c, err := DecodeCase([]byte(caseJSON))
+	if err != nil {
+		return nil, err
+	}
+	return Handoff(ctx, HopJudgeToBenchClerk, "StoreCase", c)
+}
+
+// SendAll hands several cases to the bench clerk in one transaction
+func (o *outbox) SendAll(ctx contractapi.TransactionContextInterface, casesJSON string) ([]*Transfer, error) {
+	var cases []*Case
+	if err := json.Unmarshal([]byte(casesJSON), &cases); err != nil {
+		return nil, err
+	}
+	transfers := make([]*Transfer, 0, len(cases))
+	for _, c := range cases {
+		transfer, err := Handoff(ctx, HopJudgeToBenchClerk, "StoreCase", c)
+		if err != nil {
+			return nil, err
+		}
+		transfers = append(transfers, transfer)
+	}
+	return transfers, nil
+}
+
+// Sync leaves a case for the sender's copy on the lawyer's channel, installed as the
+// registrar
+func (o *outbox) Sync(ctx contractapi.TransactionContextInterface, caseJSON string) (*Transfer, error) {
+	c, err := DecodeCase([]byte(caseJSON))
+	if err != nil {
+		return nil, err
+	}
+	return Sync(ctx, HopRegistrarToLawyerChannel, c)
+}
+
+func (o *outbox) GetCaseById(ctx contractapi.TransactionContextInterface, caseID string) (*Case, error) {
+	value, err := ctx.GetStub().GetState(caseID)
+	if err != nil || value == nil {
+		return nil, fmt.Errorf("case not found: %s", caseID)
+	}
+	return DecodeCase(value)
+}
+
+func (o *outbox) ReceiveTransfers(ctx contractapi.TransactionContextInterface) ([]string, error) {
+	store := func(caseJSON string) error {
+		c, err := DecodeCase([]byte(caseJSON))
+		if err != nil {
+			return err
+		}
+		return ctx.GetStub().PutState(c.ID, []byte(caseJSON))
+	}
+	return ReceiveTransfers(ctx, store, HopRegistrarToStampReporterChannel)
+}
+
+func (o *outbox) SetRoutingConfig(ctx contractapi.TransactionContextInterface, config string) error {
+	return PutRouting(ctx, config)
+}
+
+func (o *outbox) ListPendingTransfers(ctx contractapi.TransactionContextInterface) ([]*Transfer, error) {
+	return ListPendingTransfers(ctx)
+}
+
+func (o *outbox) ClaimTransfer(ctx contractapi.TransactionContextInterface, sequence uint64) (*Transfer, error) {
+	return ClaimTransfer(ctx, sequence)
+}
+
+func (o *outbox) RetryTransfer(ctx contractapi.TransactionContextInterface, sequence uint64) (*Transfer, error) {
+	return RetryTransfer(ctx, sequence)
+}
+
+// inbox is a receiving contract that refuses cases while reject is set
+type inbox struct {
+	contractapi.Contract
+	reject *string
+}
+
+func (i *inbox) StoreCase(ctx contractapi.TransactionContextInterface, caseJSON string) error {
+	if *i.reject != "" {
+		return fmt.Errorf(*i.reject)
+	}
+	c, err := DecodeCase([]byte(caseJSON))
+	if err != nil {
+		return err
+	}
+	return ctx.GetStub().PutState(c.ID, []byte(caseJSON))
+}
+
+func (i *inbox) ReceiveTransfers(ctx contractapi.TransactionContextInterface) ([]string, error) {
+	store := func(caseJSON string) error {
+		return i.StoreCase(ctx, caseJSON)
+	}
+	return ReceiveTransfers(ctx, store, HopBenchClerkToJudge)
+}
+
+var (
+	judge      = mockstub.Identity{MSPID: "JudgesOrgMSP", Name: "judge1"}
+	benchClerk = mockstub.Identity{MSPID: "BenchClerksOrgMSP", Name: "benchclerk1"}
+)
+
+// outboxNetwork installs the judge outbox and the bench clerk inbox, and returns the
+// switch that makes the bench clerk refuse cases
+func outboxNetwork(t *testing.T) (*mockstub.Network, *string) {
+	t.Helper()
+	reject := new(string)
+	o := &outbox{}
+	o.TransactionContextHandler = new(TransactionContext)
+	sender, err := contractapi.NewChaincode(o)
+	if err != nil {
+		t.Fatal(err)
+	}
+	receiver, err := contractapi.NewChaincode(&inbox{reject: reject})
+	if err != nil {
+		t.Fatal(err)
+	}
+	n := mockstub.NewNetwork(time.Date(2024, time.May, 15, 10, 0, 0, 0, time.UTC))
+	n.Install("judge", sender)
+	n.Install("benchclerk", receiver)
+	routing, _ := json.Marshal(DefaultRouting())
+	n.PutState(ChannelBenchClerkJudge, "judge", RoutingKey, routing)
+	n.PutState(ChannelBenchClerkJudge, "benchclerk", RoutingKey, routing)
+	return n, reject
+}
+
+func submit(t *testing.T, n *mockstub.Network, chaincode string, id mockstub.Identity, function string, args ...string) []byte {
+	t.Helper()
+	payload, err := n.Submit(ChannelBenchClerkJudge, chaincode, id, function, args...)
+	if err != nil {
+		t.Fatalf("%s: %v", function, err)
+	}
+	return payload
+}
+
+func send(t *testing.T, n *mockstub.Network, id string) *Transfer {
+	t.Helper()
+	c := &Case{ID: id, Status: StatusJudgmentIssued, CurrentOrg: OrgBenchClerks}
+	value, _ := json.Marshal(c)
+	var transfer Transfer
+	if err := json.Unmarshal(submit(t, n, "judge", judge, "Send", string(value)), &transfer); err != nil {
+		t.Fatal(err)
+	}
+	return &transfer
+}
+
+func pending(t *testing.T, n *mockstub.Network) []*Transfer {
+	t.Helper()
+	var transfers []*Transfer
+	if err := json.Unmarshal(submit(t, n, "judge", benchClerk, "ListPendingTransfers"), &transfers); err != nil {
+		t.Fatal(err)
+	}
+	return transfers
+}
+
+func TestHandoffDelivered(t *testing.T) {
+	n, _ := outboxNetwork(t)
+	transfer := send(t, n, "CASE_001")
+	if transfer.Sequence != 1 || transfer.Status != TransferClaimed || transfer.ClaimedBy != OrgBenchClerks || transfer.Attempts != 1 {
+		t.Errorf("transfer = %+v", transfer)
+	}
+	if transfer.From != OrgJudges || transfer.To != OrgBenchClerks || transfer.Case.ID != "CASE_001" {
+		t.Errorf("transfer = %+v", transfer)
+	}
+	if n.GetState(ChannelBenchClerkJudge, "benchclerk", "CASE_001") == nil {
+		t.Error("bench clerk did not store the case")
+	}
+	if got := pending(t, n); len(got) != 0 {
+		t.Errorf("pending = %+v", got)
+	}
+}
+
+func TestHandoffPendingUntilRetried(t *testing.T) {
+	n, reject := outboxNetwork(t)
+	*reject = "ledger unavailable"
+	first := send(t, n, "CASE_001")
+	second := send(t, n, "CASE_002")
+	if first.Status != TransferPending || first.LastError != "ledger unavailable" || first.Attempts != 1 {
+		t.Errorf("transfer = %+v", first)
+	}
+	if second.Sequence != 2 {
+		t.Errorf("second sequence = %d", second.Sequence)
+	}
+	if got := pending(t, n); len(got) != 2 || got[0].CaseID != "CASE_001" || got[1].CaseID != "CASE_002" {
+		t.Fatalf("pending = %+v", got)
+	}
+
+	*reject = ""
+	var retried Transfer
+	if err := json.Unmarshal(submit(t, n, "judge", judge, "RetryTransfer", "1"), &retried); err != nil {
+		t.Fatal(err)
+	}
+	if retried.Status != TransferClaimed || retried.Attempts != 2 || retried.LastError != "" {
+		t.Errorf("retried = %+v", retried)
+	}
+	if got := pending(t, n); len(got) != 1 || got[0].Sequence != 2 {
+		t.Errorf("pending = %+v", got)
+	}
+	if _, err := n.Submit(ChannelBenchClerkJudge, "judge", judge, "RetryTransfer", "1"); err == nil || !strings.Contains(err.Error(), "transfer 1 was already claimed by BenchClerksOrg") {
+		t.Errorf("err = %v", err)
+	}
+}
+
+func TestHandoffsInOneTransaction(t *testing.T) {
+	n, reject := outboxNetwork(t)
+	*reject = "ledger unavailable"
+	cases, _ := json.Marshal([]*Case{
+		{ID: "CASE_001", Status: StatusJudgmentIssued, CurrentOrg: OrgBenchClerks},
+		{ID: "CASE_002", Status: StatusJudgmentIssued, CurrentOrg: OrgBenchClerks},
+	})
+	var transfers []*Transfer
+	if err := json.Unmarshal(submit(t, n, "judge", judge, "SendAll", string(cases)), &transfers); err != nil {
+		t.Fatal(err)
+	}
+	if len(transfers) != 2 || transfers[0].Sequence != 1 || transfers[1].Sequence != 2 {
+		t.Fatalf("transfers = %+v", transfers)
+	}
+	if got := pending(t, n); len(got) != 2 || got[0].CaseID != "CASE_001" || got[1].CaseID != "CASE_002" {
+		t.Errorf("pending = %+v", got)
+	}
+	if third := send(t, n, "CASE_003"); third.Sequence != 3 {
+		t.Errorf("third sequence = %d", third.Sequence)
+	}
+}
+
+func TestReceiveTransfers(t *testing.T) {
+	n, reject := outboxNetwork(t)
+	*reject = "ledger unavailable"
+	if transfer := send(t, n, "CASE_001"); transfer.Status != TransferPending || transfer.Channel != ChannelBenchClerkJudge {
+		t.Errorf("transfer = %+v", transfer)
+	}
+	*reject = ""
+
+	var received []string
+	if err := json.Unmarshal(submit(t, n, "benchclerk", benchClerk, "ReceiveTransfers"), &received); err != nil {
+		t.Fatal(err)
+	}
+	if len(received) != 1 || received[0] != "CASE_001" {
+		t.Errorf("received = %v", received)
+	}
+	if n.GetState(ChannelBenchClerkJudge, "benchclerk", "CASE_001") == nil {
+		t.Error("bench clerk did not store the case")
+	}
+	if got := pending(t, n); len(got) != 0 {
+		t.Errorf("pending = %+v", got)
+	}
+	if payload := submit(t, n, "benchclerk", benchClerk, "ReceiveTransfers"); string(payload) != "[]" {
+		t.Errorf("second receive = %s", payload)
+	}
+}
+
+func TestSyncFromAnotherChannel(t *testing.T) {
+	n, _ := outboxNetwork(t)
+	sender, err := contractapi.NewChaincode(&outbox{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	n.Install("registrar", sender)
+	routing, _ := json.Marshal(DefaultRouting())
+	n.PutState(ChannelRegistrarStampReporter, "registrar", RoutingKey, routing)
+	n.PutState(ChannelLawyerRegistrar, "registrar", RoutingKey, routing)
+	registrar := mockstub.Identity{MSPID: "RegistrarsOrgMSP", Name: "registrar1"}
+	at := func(channel string, function string, args ...string) string {
+		t.Helper()
+		payload, err := n.Submit(channel, "registrar", registrar, function, args...)
+		if err != nil {
+			t.Fatalf("%s: %v", function, err)
+		}
+		return string(payload)
+	}
+
+	// writes to the lawyer's channel would be discarded, so the registrar leaves the case
+	// in its outbox
+	c := &Case{ID: "CASE_001", Status: StatusTransferredToStampReporter, CurrentOrg: OrgStampReporters, HashChain: []HashLink{{Hash: "h1"}}}
+	value, _ := json.Marshal(c)
+	var transfer Transfer
+	if err := json.Unmarshal([]byte(at(ChannelRegistrarStampReporter, "Sync", string(value))), &transfer); err != nil {
+		t.Fatal(err)
+	}
+	if transfer.Status != TransferPending || transfer.To != OrgRegistrars || transfer.Channel != ChannelLawyerRegistrar || !strings.Contains(transfer.LastError, "waiting for it to claim") {
+		t.Errorf("transfer = %+v", transfer)
+	}
+	if got := at(ChannelRegistrarStampReporter, "ReceiveTransfers"); got != "[]" {
+		t.Errorf("receive on the sending channel = %s", got)
+	}
+
+	if got := at(ChannelLawyerRegistrar, "ReceiveTransfers"); got != `["CASE_001"]` {
+		t.Errorf("received = %s", got)
+	}
+	if n.GetState(ChannelLawyerRegistrar, "registrar", "CASE_001") == nil {
+		t.Error("registrar did not store its copy on the lawyer's channel")
+	}
+	if got := at(ChannelLawyerRegistrar, "ReceiveTransfers"); got != "[]" {
+		t.Errorf("second receive = %s, want the stored case skipped", got)
+	}
+
+	// the claim cannot be written across channels, so the registrar confirms it on retry
+	var retried Transfer
+	if err := json.Unmarshal([]byte(at(ChannelRegistrarStampReporter, "RetryTransfer", "1")), &retried); err != nil {
+		t.Fatal(err)
+	}
+	if retried.Status != TransferClaimed || retried.ClaimedBy != OrgRegistrars {
+		t.Errorf("retried = %+v", retried)
+	}
+}
+
+func TestReceiveTransfersStoreFailure(t *testing.T) {
+	n, reject := outboxNetwork(t)
+	*reject = "ledger unavailable"
+	send(t, n, "CASE_001")
+
+	_, err := n.Submit(ChannelBenchClerkJudge, "benchclerk", benchClerk, "ReceiveTransfers")
+	if err == nil || !strings.Contains(err.Error(), "failed to store case CASE_001 from transfer 1: ledger unavailable") {
+		t.Errorf("err = %v", err)
+	}
+	if got := pending(t, n); len(got) != 1 {
+		t.Errorf("pending = %+v, want the claim rolled back", got)
+	}
+}
+
+func TestClaimTransferErrors(t *testing.T) {
+	n, reject := outboxNetwork(t)
+	*reject = "ledger unavailable"
+	send(t, n, "CASE_001")
+
+	tests := []struct {
+		name     string
+		caller   mockstub.Identity
+		sequence string
+		wantErr  string
+	}{
+		{"missing transfer", benchClerk, "7", "transfer 7 does not exist"},
+		{"another organization", judge, "1", "transfer 1 is addressed to BenchClerksOrg, not JudgesOrg"},
+		{"outside ReceiveTransfers", benchClerk, "1", "transfer 1 can only be claimed by ReceiveTransfers of benchclerk, not ClaimTransfer of judge"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := n.Submit(ChannelBenchClerkJudge, "judge", tt.caller, "ClaimTransfer", tt.sequence)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("err = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+
+	if got := pending(t, n); len(got) != 1 {
+		t.Fatalf("pending = %+v, want the transfer left for the bench clerk", got)
+	}
+	*reject = ""
+	submit(t, n, "benchclerk", benchClerk, "ReceiveTransfers")
+	if _, err := n.Submit(ChannelBenchClerkJudge, "judge", benchClerk, "ClaimTransfer", "1"); err == nil || !strings.Contains(err.Error(), "transfer 1 was already claimed by BenchClerksOrg") {
+		t.Errorf("err = %v", err)
+	}
+}
@@ -0,0 +1,72 @@
+package casemodel
+
+import (
+	"fmt"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/hyperledger/fabric-protos-go/peer"
+)
+
+// MaxPageSize is the largest page a paginated query may request
+const MaxPageSize = 500
+
+// CasePage is one page of a paginated case query. Pass Bookmark back to fetch the
+// next page; a page with fewer records than requested is the last one.
+type CasePage struct {
+	Records      []*Case `json:"records"`
+	FetchedCount int32   `json:"fetchedCount"`
+	Bookmark     string  `json:"bookmark"`
+}
+
+// CheckPageSize rejects page sizes outside 1..MaxPageSize
+func CheckPageSize(pageSize int32) error {
+	if pageSize < 1 || pageSize > MaxPageSize {
+		return fmt.Errorf("page size must be between 1 and %d, got %d", MaxPageSize, pageSize)
+	}
+	return nil
+}
+
+// QueryCasesWithPagination runs a CouchDB query and returns one page of cases
+func QueryCasesWithPagination(ctx contractapi.TransactionContextInterface, query string, pageSize int32, bookmark string) (*CasePage, error) {
+	if err := CheckPageSize(pageSize); err != nil {
+		return nil, err
+	}
+	iterator, metadata, err := ctx.GetStub().GetQueryResultWithPagination(query, pageSize, bookmark)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute query: %v", err)
+	}
+	return readCasePage(iterator, metadata)
+}
+
+// GetCasesByRangeWithPagination returns one page of cases stored between startKey and endKey
+func GetCasesByRangeWithPagination(ctx contractapi.TransactionContextInterface, startKey string, endKey string, pageSize int32, bookmark string) (*CasePage, error) {
+	if err := CheckPageSize(pageSize); err != nil {
+		return nil, err
+	}
+	iterator, metadata, err := ctx.GetStub().GetStateByRangeWithPagination(startKey, endKey, pageSize, bookmark)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get state by range: %v", err)
+	}
+	return readCasePage(iterator, metadata)
+}
+
+func readCasePage(iterator shim.StateQueryIteratorInterface, metadata *peer.QueryResponseMetadata) (*CasePage, error) {
+	defer iterator.Close()
+
+	page := &CasePage{Records: make([]*Case, 0)}
+	for iterator.HasNext() {
+		result, err := iterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read next result: %v", err)
+		}
+		c, err := DecodeCase(result.Value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode case %s: %v", result.Key, err)
+		}
+		page.Records = append(page.Records, c)
+	}
+	page.FetchedCount = metadata.GetFetchedRecordsCount()
+	page.Bookmark = metadata.GetBookmark()
+	return page, nil
+}
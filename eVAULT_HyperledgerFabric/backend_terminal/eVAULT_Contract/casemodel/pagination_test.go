@@ -0,0 +1,101 @@
+package casemodel
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/hyperledger/fabric-protos-go/ledger/queryresult"
+	"github.com/hyperledger/fabric-protos-go/peer"
+)
+
+// sliceIterator iterates over a fixed set of results
+type sliceIterator struct {
+	results []*queryresult.KV
+}
+
+func (s *sliceIterator) HasNext() bool { return len(s.results) > 0 }
+func (s *sliceIterator) Close() error  { return nil }
+func (s *sliceIterator) Next() (*queryresult.KV, error) {
+	next := s.results[0]
+	s.results = s.results[1:]
+	return next, nil
+}
+
+// pageStub serves a single page of results for any paginated query
+type pageStub struct {
+	shim.ChaincodeStubInterface
+	results  []*queryresult.KV
+	bookmark string
+}
+
+func (p pageStub) GetQueryResultWithPagination(string, int32, string) (shim.StateQueryIteratorInterface, *peer.QueryResponseMetadata, error) {
+	return p.page()
+}
+
+func (p pageStub) GetStateByRangeWithPagination(string, string, int32, string) (shim.StateQueryIteratorInterface, *peer.QueryResponseMetadata, error) {
+	return p.page()
+}
+
+func (p pageStub) page() (shim.StateQueryIteratorInterface, *peer.QueryResponseMetadata, error) {
+	metadata := &peer.QueryResponseMetadata{FetchedRecordsCount: int32(len(p.results)), Bookmark: p.bookmark}
+	return &sliceIterator{results: p.results}, metadata, nil
+}
+
+func caseKV(t *testing.T, c Case) *queryresult.KV {
+	t.Helper()
+	value, err := json.Marshal(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &queryresult.KV{Key: c.ID, Value: value}
+}
+
+func TestCasePages(t *testing.T) {
+	ctx := new(contractapi.TransactionContext)
+	ctx.SetStub(pageStub{
+		results:  []*queryresult.KV{caseKV(t, Case{ID: "CASE_001"}), caseKV(t, Case{ID: "CASE_002"})},
+		bookmark: "next",
+	})
+
+	byQuery, err := QueryCasesWithPagination(ctx, `{"selector":{}}`, 2, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	byRange, err := GetCasesByRangeWithPagination(ctx, "CASE_", "", 2, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, page := range []*CasePage{byQuery, byRange} {
+		if page.FetchedCount != 2 || page.Bookmark != "next" || len(page.Records) != 2 {
+			t.Fatalf("page = %+v", page)
+		}
+		if page.Records[1].ID != "CASE_002" || page.Records[1].History == nil {
+			t.Errorf("records were not decoded: %+v", page.Records[1])
+		}
+	}
+
+	ctx.SetStub(pageStub{})
+	empty, err := QueryCasesWithPagination(ctx, `{"selector":{}}`, 10, "next")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out, _ := json.Marshal(empty); string(out) != `{"records":[],"fetchedCount":0,"bookmark":""}` {
+		t.Errorf("empty page = %s", out)
+	}
+}
+
+func TestCheckPageSize(t *testing.T) {
+	for _, size := range []int32{1, 50, MaxPageSize} {
+		if err := CheckPageSize(size); err != nil {
+			t.Errorf("page size %d: %v", size, err)
+		}
+	}
+	for _, size := range []int32{-1, 0, MaxPageSize + 1} {
+		if err := CheckPageSize(size); err == nil || !strings.Contains(err.Error(), "page size") {
+			t.Errorf("page size %d: expected an error, got %v", size, err)
+		}
+	}
+}
@@ -0,0 +1,393 @@
+package casemodel
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// Private data collections that hold a case's sensitive fields. Collections are scoped
+// to a chaincode on a channel, and lawyers and judges never share a channel, so each
+// chaincode hosts the collections of its own organization with that organization as
+// the only member. The public case only keeps a hash of each collection's record. The
+// lawyer files the case and hosts every collection, so it can read the details back
+// with GetCasePrivateDetails and pass them to whoever receives the case next.
+const (
+	CollectionSealedDetails   = "sealedDetails"   // client name, description, document hashes and content hashes
+	CollectionPartyIdentities = "partyIdentities" // party UIDs
+)
+
+// TransientPrivateDetails is the transient field clients pass a case's CasePrivateDetails
+// in, so that they never appear in the transaction. A transaction that receives several
+// cases, such as ReceiveTransfers, takes a JSON array with the details of each.
+const TransientPrivateDetails = "privateDetails"
+
+// PrivateCollection is a private data collection and the organizations whose
+// chaincodes host it
+type PrivateCollection struct {
+	Name string
+	Orgs []string
+}
+
+// PrivateCollections lists every collection a case's private details are split across
+var PrivateCollections = []PrivateCollection{
+	{Name: CollectionSealedDetails, Orgs: []string{OrgLawyers, OrgJudges}},
+	{Name: CollectionPartyIdentities, Orgs: []string{OrgLawyers, OrgRegistrars}},
+}
+
+// CasePrivateDetails are the fields of a case kept in private data collections. Each
+// collection stores the fields that belong to it under the case ID.
+type CasePrivateDetails struct {
+	CaseID         string            `json:"caseId"`
+	UIDParty1      string            `json:"uidParty1,omitempty" metadata:",optional"`
+	UIDParty2      string            `json:"uidParty2,omitempty" metadata:",optional"`
+	ClientName     string            `json:"clientName,omitempty" metadata:",optional"`
+	Description    string            `json:"description,omitempty" metadata:",optional"`
+	DocumentHashes map[string]string `json:"documentHashes,omitempty" metadata:",optional"` // by document ID
+	ContentHashes  map[string]string `json:"contentHashes,omitempty" metadata:",optional"`  // by document ID, see Document.ContentDigest
+	Salts          map[string]string `json:"salts,omitempty" metadata:",optional"`          // by collection, see privateHash
+}
+
+// MinSaltSize is the least number of random bytes in the salt of a private record
+const MinSaltSize = 16
+
+// hosts reports whether org's chaincode hosts the collection
+func (p PrivateCollection) hosts(org string) bool {
+	for _, o := range p.Orgs {
+		if o == org {
+			return true
+		}
+	}
+	return false
+}
+
+// record returns the fields of d stored in the collection
+func (p PrivateCollection) record(d *CasePrivateDetails) *CasePrivateDetails {
+	record := &CasePrivateDetails{CaseID: d.CaseID}
+	switch p.Name {
+	case CollectionSealedDetails:
+		record.ClientName, record.Description = d.ClientName, d.Description
+		if len(d.DocumentHashes) > 0 {
+			record.DocumentHashes = make(map[string]string)
+			for id, hash := range d.DocumentHashes {
+				record.DocumentHashes[id] = hash
+			}
+		}
+		if len(d.ContentHashes) > 0 {
+			record.ContentHashes = make(map[string]string)
+			for id, hash := range d.ContentHashes {
+				record.ContentHashes[id] = hash
+			}
+		}
+	case CollectionPartyIdentities:
+		record.UIDParty1, record.UIDParty2 = d.UIDParty1, d.UIDParty2
+	}
+	if salt := d.Salts[p.Name]; salt != "" {
+		record.Salts = map[string]string{p.Name: salt}
+	}
+	return record
+}
+
+// NewSalts returns a random salt for the record of each collection in
+// PrivateCollections, for a client to pass in the salts of the private details it files
+func NewSalts() (map[string]string, error) {
+	salts := make(map[string]string)
+	for _, collection := range PrivateCollections {
+		raw := make([]byte, MinSaltSize)
+		if _, err := rand.Read(raw); err != nil {
+			return nil, fmt.Errorf("failed to generate salt: %v", err)
+		}
+		salts[collection.Name] = base64.StdEncoding.EncodeToString(raw)
+	}
+	return salts, nil
+}
+
+// salt returns the salt of the collection's record r, which must be the base64 of at
+// least MinSaltSize random bytes
+func (p PrivateCollection) salt(r *CasePrivateDetails) (string, error) {
+	salt := r.Salts[p.Name]
+	if salt == "" {
+		return "", fmt.Errorf("private details of case %s for %s need a salt, pass the base64 of %d random bytes or more in salts.%s of the %s transient field", r.CaseID, p.Name, MinSaltSize, p.Name, TransientPrivateDetails)
+	}
+	if raw, err := base64.StdEncoding.DecodeString(salt); err != nil || len(raw) < MinSaltSize {
+		return "", fmt.Errorf("salt of the private details of case %s for %s must be the base64 of %d random bytes or more", r.CaseID, p.Name, MinSaltSize)
+	}
+	return salt, nil
+}
+
+// empty reports whether d has no fields set besides its case ID and salts
+func (d *CasePrivateDetails) empty() bool {
+	return d.UIDParty1 == "" && d.UIDParty2 == "" && d.ClientName == "" && d.Description == "" && len(d.DocumentHashes) == 0 && len(d.ContentHashes) == 0
+}
+
+// merge copies the fields set in other over d
+func (d *CasePrivateDetails) merge(other *CasePrivateDetails) {
+	for _, f := range []struct{ to, from *string }{
+		{&d.UIDParty1, &other.UIDParty1},
+		{&d.UIDParty2, &other.UIDParty2},
+		{&d.ClientName, &other.ClientName},
+		{&d.Description, &other.Description},
+	} {
+		if *f.from != "" {
+			*f.to = *f.from
+		}
+	}
+	for id, hash := range other.DocumentHashes {
+		if d.DocumentHashes == nil {
+			d.DocumentHashes = make(map[string]string)
+		}
+		d.DocumentHashes[id] = hash
+	}
+	for id, hash := range other.ContentHashes {
+		if d.ContentHashes == nil {
+			d.ContentHashes = make(map[string]string)
+		}
+		d.ContentHashes[id] = hash
+	}
+	for collection, salt := range other.Salts {
+		if d.Salts == nil {
+			d.Salts = make(map[string]string)
+		}
+		d.Salts[collection] = salt
+	}
+}
+
+// privateHash returns the hex SHA-256 of a stored record, which is also what Fabric
+// returns from GetPrivateDataHash. Every record holds a random salt of its own, which
+// never leaves the collection, so that the hash on the public case cannot be matched by
+// hashing guessed values such as a party's UID.
+func privateHash(value []byte) string {
+	sum := sha256.Sum256(value)
+	return hex.EncodeToString(sum[:])
+}
+
+// transientDetails returns the private details passed with the transaction for case
+// caseID, or nil if there are none
+func transientDetails(ctx contractapi.TransactionContextInterface, caseID string) (*CasePrivateDetails, error) {
+	transient, err := ctx.GetStub().GetTransient()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transient data: %v", err)
+	}
+	value, ok := transient[TransientPrivateDetails]
+	if !ok {
+		return nil, nil
+	}
+	if trimmed := bytes.TrimSpace(value); len(trimmed) > 0 && trimmed[0] == '[' {
+		var all []*CasePrivateDetails
+		if err := json.Unmarshal(trimmed, &all); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal private details: %v", err)
+		}
+		for _, details := range all {
+			if details.CaseID == "" {
+				return nil, fmt.Errorf("private details passed for several cases must each name their case")
+			}
+			if details.CaseID == caseID {
+				return details, nil
+			}
+		}
+		return nil, nil
+	}
+	var details CasePrivateDetails
+	if err := json.Unmarshal(value, &details); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal private details: %v", err)
+	}
+	if details.CaseID != "" && details.CaseID != caseID {
+		return nil, fmt.Errorf("private details are for case %s, not %s", details.CaseID, caseID)
+	}
+	details.CaseID = caseID
+	return &details, nil
+}
+
+// SetPrivateDetails moves c's sensitive fields out of the public case. Values passed in
+// the transient field take precedence over any still set on c, which are cleared along
+// with the hashes of the documents in documentIDs. The content hashes of those
+// documents move to the sealedDetails record, leaving their content digest in place.
+// Records of collections org hosts are merged with what they already hold and written;
+// for the others the record is made of the values given, and their hash is left alone
+// when none are. Each record needs a salt, passed in the transient field unless the
+// record held already has one. c.PrivateHashes is updated for every collection that
+// changes. Call it before Seal.
+func SetPrivateDetails(ctx contractapi.TransactionContextInterface, c *Case, org string, documentIDs ...string) error {
+	details, err := transientDetails(ctx, c.ID)
+	if err != nil {
+		return err
+	}
+	if details == nil {
+		details = &CasePrivateDetails{CaseID: c.ID}
+	}
+
+	public := &CasePrivateDetails{CaseID: c.ID, UIDParty1: c.UIDParty1, UIDParty2: c.UIDParty2, ClientName: c.ClientName, Description: c.Description}
+	c.UIDParty1, c.UIDParty2, c.ClientName, c.Description = "", "", "", ""
+	for _, id := range documentIDs {
+		for i := range c.Documents {
+			if c.Documents[i].ID == id && c.Documents[i].Hash != "" {
+				public.merge(&CasePrivateDetails{DocumentHashes: map[string]string{id: c.Documents[i].Hash}})
+				c.Documents[i].Hash = ""
+			}
+		}
+	}
+	if !public.empty() {
+		log.Printf("Case %s has private details in its public fields; pass them in the %s transient field instead", c.ID, TransientPrivateDetails)
+		public.merge(details)
+		details = public
+	}
+	for _, id := range documentIDs {
+		doc := c.Document(id)
+		if doc == nil || doc.ContentHash == "" {
+			continue
+		}
+		details.merge(&CasePrivateDetails{ContentHashes: map[string]string{id: doc.ContentHash}})
+		doc.ContentDigest, doc.ContentHash = ContentDigest(doc.ContentHash), ""
+	}
+
+	for _, collection := range PrivateCollections {
+		record := collection.record(details)
+		if record.empty() {
+			continue
+		}
+		if collection.hosts(org) {
+			existing, err := readPrivateRecord(ctx, collection.Name, c.ID)
+			if err != nil {
+				return err
+			}
+			if existing != nil {
+				existing.merge(record)
+				record = existing
+			}
+		}
+		if _, err := collection.salt(record); err != nil {
+			return err
+		}
+		value, err := json.Marshal(record)
+		if err != nil {
+			return fmt.Errorf("failed to marshal private details: %v", err)
+		}
+		if collection.hosts(org) {
+			if err := ctx.GetStub().PutPrivateData(collection.Name, c.ID, value); err != nil {
+				return fmt.Errorf("failed to store private details of case %s: %v", c.ID, err)
+			}
+		}
+		if c.PrivateHashes == nil {
+			c.PrivateHashes = make(map[string]string)
+		}
+		c.PrivateHashes[collection.Name] = privateHash(value)
+	}
+	return nil
+}
+
+// ReceivePrivateDetails stores the private details passed with a transaction that
+// receives c from another organization in the collections org hosts. Each record must
+// match the hash c carries for it. For every collection org hosts that c carries a hash
+// for, the record must be passed unless org already holds it, as when a case returns to
+// an organization that handled it before.
+func ReceivePrivateDetails(ctx contractapi.TransactionContextInterface, c *Case, org string) error {
+	details, err := transientDetails(ctx, c.ID)
+	if err != nil {
+		return err
+	}
+	if details == nil {
+		details = &CasePrivateDetails{CaseID: c.ID}
+	}
+
+	for _, collection := range PrivateCollections {
+		if !collection.hosts(org) {
+			continue
+		}
+		record := collection.record(details)
+		if record.empty() {
+			if c.PrivateHashes[collection.Name] == "" {
+				continue
+			}
+			held, err := ctx.GetStub().GetPrivateData(collection.Name, c.ID)
+			if err != nil {
+				return fmt.Errorf("failed to read private details of case %s: %v", c.ID, err)
+			}
+			if held == nil || privateHash(held) != c.PrivateHashes[collection.Name] {
+				return fmt.Errorf("case %s carries private details for %s, pass them in the %s transient field", c.ID, collection.Name, TransientPrivateDetails)
+			}
+			continue
+		}
+		value, err := json.Marshal(record)
+		if err != nil {
+			return fmt.Errorf("failed to marshal private details: %v", err)
+		}
+		if privateHash(value) != c.PrivateHashes[collection.Name] {
+			return fmt.Errorf("private details for %s do not match the hash on case %s", collection.Name, c.ID)
+		}
+		if err := ctx.GetStub().PutPrivateData(collection.Name, c.ID, value); err != nil {
+			return fmt.Errorf("failed to store private details of case %s: %v", c.ID, err)
+		}
+	}
+	return nil
+}
+
+// GetCasePrivateDetails returns the private details of a case held in the collections
+// org hosts. Only clients of org may read them, and each record is checked against the
+// hash on the public case.
+func GetCasePrivateDetails(ctx contractapi.TransactionContextInterface, caseID string, org string) (*CasePrivateDetails, error) {
+	clientOrg, err := ClientOrg(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if clientOrg != org {
+		return nil, fmt.Errorf("organization %s is not authorised to read the private details of case %s", clientOrg, caseID)
+	}
+
+	caseJSON, err := ctx.GetStub().GetState(caseID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read case: %v", err)
+	}
+	if caseJSON == nil {
+		return nil, fmt.Errorf("case does not exist: %s", caseID)
+	}
+	c, err := DecodeCase(caseJSON)
+	if err != nil {
+		return nil, err
+	}
+
+	details := &CasePrivateDetails{CaseID: caseID}
+	for _, collection := range PrivateCollections {
+		if !collection.hosts(org) {
+			continue
+		}
+		value, err := ctx.GetStub().GetPrivateData(collection.Name, caseID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read private details of case %s: %v", caseID, err)
+		}
+		if value == nil {
+			continue
+		}
+		if privateHash(value) != c.PrivateHashes[collection.Name] {
+			return nil, fmt.Errorf("private details for %s do not match the hash on case %s", collection.Name, caseID)
+		}
+		var record CasePrivateDetails
+		if err := json.Unmarshal(value, &record); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal private details: %v", err)
+		}
+		details.merge(&record)
+	}
+	return details, nil
+}
+
+// readPrivateRecord returns the record of case caseID in a collection, or nil
+func readPrivateRecord(ctx contractapi.TransactionContextInterface, collection string, caseID string) (*CasePrivateDetails, error) {
+	value, err := ctx.GetStub().GetPrivateData(collection, caseID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read private details of case %s: %v", caseID, err)
+	}
+	if value == nil {
+		return nil, nil
+	}
+	var record CasePrivateDetails
+	if err := json.Unmarshal(value, &record); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal private details: %v", err)
+	}
+	return &record, nil
+}
@@ -0,0 +1,240 @@
+package casemodel
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+
+	"casemodel/mockstub"
+)
+
+var (
+	lawyer    = mockstub.Identity{MSPID: "LawyersOrgMSP", Name: "lawyer1"}
+	registrar = mockstub.Identity{MSPID: "RegistrarsOrgMSP", Name: "registrar1"}
+)
+
+// Salts of the records the tests write, and the same as a transient salts field
+const (
+	sealedSalt = "c2VhbGVkLWRldGFpbHMtc2FsdA==" // "sealed-details-salt"
+	partySalt  = "cGFydHktaWRlbnRpdGllcy1zYWx0" // "party-identities-salt"
+	salts      = `"salts":{"sealedDetails":"` + sealedSalt + `","partyIdentities":"` + partySalt + `"}`
+)
+
+// privateNetwork defines the lawyer's and the registrar's collections on their channel
+func privateNetwork() *mockstub.Network {
+	n := mockstub.NewNetwork(time.Date(2024, time.May, 15, 10, 0, 0, 0, time.UTC))
+	n.DefineCollection(ChannelLawyerRegistrar, "lawyer", CollectionSealedDetails, "LawyersOrgMSP")
+	n.DefineCollection(ChannelLawyerRegistrar, "lawyer", CollectionPartyIdentities, "LawyersOrgMSP")
+	n.DefineCollection(ChannelLawyerRegistrar, "registrar", CollectionPartyIdentities, "RegistrarsOrgMSP")
+	return n
+}
+
+// inTransaction runs f in a transaction of chaincode with transient private details, and
+// commits it if f succeeds
+func inTransaction(t *testing.T, n *mockstub.Network, chaincode string, id mockstub.Identity, details string, f func(ctx contractapi.TransactionContextInterface) error) error {
+	t.Helper()
+	p := mockstub.Proposal{Channel: ChannelLawyerRegistrar, Chaincode: chaincode, Identity: id}
+	if details != "" {
+		p.Transient = map[string][]byte{TransientPrivateDetails: []byte(details)}
+	}
+	stub, err := n.NewTransaction(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := f(stub.Context()); err != nil {
+		return err
+	}
+	return n.Commit(stub)
+}
+
+func TestSetPrivateDetails(t *testing.T) {
+	n := privateNetwork()
+	plaint := ContentHash([]byte("%PDF-1.4 plaint"))
+	c := &Case{ID: "CASE_001", ClientName: "Ravi Sharma", Documents: []Document{{ID: "DOC_1", Hash: "abc", ContentHash: plaint}, {ID: "DOC_2", Hash: "signature", ContentHash: plaint}}}
+	err := inTransaction(t, n, "lawyer", lawyer, `{"uidParty1":"P1","uidParty2":"P2","description":"Boundary wall",`+salts+`}`, func(ctx contractapi.TransactionContextInterface) error {
+		return SetPrivateDetails(ctx, c, OrgLawyers, "DOC_1")
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if c.UIDParty1 != "" || c.UIDParty2 != "" || c.ClientName != "" || c.Description != "" || c.Documents[0].Hash != "" || c.Documents[0].ContentHash != "" {
+		t.Errorf("private fields left on the public case: %+v", c)
+	}
+	if c.Documents[0].ContentDigest != ContentDigest(plaint) {
+		t.Errorf("content digest = %q", c.Documents[0].ContentDigest)
+	}
+	if c.Documents[1].Hash != "signature" || c.Documents[1].ContentHash != plaint {
+		t.Errorf("hash of a document not being added was moved: %+v", c.Documents[1])
+	}
+	sealed := n.GetPrivateData(ChannelLawyerRegistrar, "lawyer", CollectionSealedDetails, c.ID)
+	if string(sealed) != `{"caseId":"CASE_001","clientName":"Ravi Sharma","description":"Boundary wall","documentHashes":{"DOC_1":"abc"},"contentHashes":{"DOC_1":"`+plaint+`"},"salts":{"sealedDetails":"`+sealedSalt+`"}}` {
+		t.Errorf("sealed details = %s", sealed)
+	}
+	if c.PrivateHashes[CollectionSealedDetails] != privateHash(sealed) {
+		t.Errorf("sealed details hash = %q", c.PrivateHashes[CollectionSealedDetails])
+	}
+	// The lawyer keeps the party identities too, so that it can pass them on
+	parties := privateHash([]byte(`{"caseId":"CASE_001","uidParty1":"P1","uidParty2":"P2","salts":{"partyIdentities":"` + partySalt + `"}}`))
+	if c.PrivateHashes[CollectionPartyIdentities] != parties || privateHash(n.GetPrivateData(ChannelLawyerRegistrar, "lawyer", CollectionPartyIdentities, c.ID)) != parties {
+		t.Errorf("party identities hash = %q", c.PrivateHashes[CollectionPartyIdentities])
+	}
+	if n.GetPrivateData(ChannelLawyerRegistrar, "registrar", CollectionPartyIdentities, c.ID) != nil {
+		t.Error("party identities were written to the registrar's collection")
+	}
+
+	// An update merges with the stored record, keeping its salt, and leaves other
+	// collections alone
+	err = inTransaction(t, n, "lawyer", lawyer, `{"clientName":"R. Sharma"}`, func(ctx contractapi.TransactionContextInterface) error {
+		return SetPrivateDetails(ctx, c, OrgLawyers)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sealed = n.GetPrivateData(ChannelLawyerRegistrar, "lawyer", CollectionSealedDetails, c.ID)
+	if string(sealed) != `{"caseId":"CASE_001","clientName":"R. Sharma","description":"Boundary wall","documentHashes":{"DOC_1":"abc"},"contentHashes":{"DOC_1":"`+plaint+`"},"salts":{"sealedDetails":"`+sealedSalt+`"}}` {
+		t.Errorf("updated sealed details = %s", sealed)
+	}
+	if c.PrivateHashes[CollectionSealedDetails] != privateHash(sealed) || c.PrivateHashes[CollectionPartyIdentities] != parties {
+		t.Errorf("hashes after update = %v", c.PrivateHashes)
+	}
+
+	err = inTransaction(t, n, "lawyer", lawyer, `{"caseId":"CASE_002"}`, func(ctx contractapi.TransactionContextInterface) error {
+		return SetPrivateDetails(ctx, c, OrgLawyers)
+	})
+	if err == nil || err.Error() != "private details are for case CASE_002, not CASE_001" {
+		t.Errorf("err = %v", err)
+	}
+}
+
+func TestPrivateDetailsSalts(t *testing.T) {
+	set := func(details string) (*Case, error) {
+		c := &Case{ID: "CASE_001"}
+		return c, inTransaction(t, privateNetwork(), "lawyer", lawyer, details, func(ctx contractapi.TransactionContextInterface) error {
+			return SetPrivateDetails(ctx, c, OrgLawyers)
+		})
+	}
+
+	if _, err := set(`{"uidParty1":"123456789012"}`); err == nil || err.Error() != "private details of case CASE_001 for partyIdentities need a salt, pass the base64 of 16 random bytes or more in salts.partyIdentities of the privateDetails transient field" {
+		t.Errorf("unsalted: %v", err)
+	}
+	if _, err := set(`{"uidParty1":"123456789012","salts":{"partyIdentities":"c2hvcnQ="}}`); err == nil || err.Error() != "salt of the private details of case CASE_001 for partyIdentities must be the base64 of 16 random bytes or more" {
+		t.Errorf("short salt: %v", err)
+	}
+
+	// The same UID under another salt hashes differently, so a UID cannot be confirmed
+	// by hashing it without the record's salt
+	first, err := set(`{"uidParty1":"123456789012",` + salts + `}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := set(`{"uidParty1":"123456789012","salts":{"partyIdentities":"` + sealedSalt + `"}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	generated, err := NewSalts()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, collection := range PrivateCollections {
+		if _, err := collection.salt(&CasePrivateDetails{Salts: generated}); err != nil {
+			t.Errorf("generated salt: %v", err)
+		}
+	}
+
+	unsalted := privateHash([]byte(`{"caseId":"CASE_001","uidParty1":"123456789012"}`))
+	if h := first.PrivateHashes[CollectionPartyIdentities]; h == second.PrivateHashes[CollectionPartyIdentities] || h == unsalted {
+		t.Errorf("hashes = %v, %v", first.PrivateHashes, second.PrivateHashes)
+	}
+}
+
+func TestReceivePrivateDetails(t *testing.T) {
+	n := privateNetwork()
+	c := &Case{ID: "CASE_001", PrivateHashes: map[string]string{
+		CollectionPartyIdentities: privateHash([]byte(`{"caseId":"CASE_001","uidParty1":"P1","uidParty2":"P2","salts":{"partyIdentities":"` + partySalt + `"}}`)),
+	}}
+	receive := func(details string) error {
+		return inTransaction(t, n, "registrar", registrar, details, func(ctx contractapi.TransactionContextInterface) error {
+			return ReceivePrivateDetails(ctx, c, OrgRegistrars)
+		})
+	}
+
+	if err := receive(""); err == nil || err.Error() != "case CASE_001 carries private details for partyIdentities, pass them in the privateDetails transient field" {
+		t.Errorf("case without private details: %v", err)
+	}
+	if err := receive(`[{"caseId":"CASE_002","uidParty1":"P1","uidParty2":"P2",` + salts + `}]`); err == nil || !strings.Contains(err.Error(), "pass them in the privateDetails transient field") {
+		t.Errorf("details of another case only: %v", err)
+	}
+	if err := receive(`[{"uidParty1":"P1","uidParty2":"P2"}]`); err == nil || err.Error() != "private details passed for several cases must each name their case" {
+		t.Errorf("details without a case: %v", err)
+	}
+	err := receive(`{"uidParty1":"P9","uidParty2":"P2","clientName":"Ravi Sharma",` + salts + `}`)
+	if err == nil || !strings.Contains(err.Error(), "do not match the hash") {
+		t.Errorf("tampered details: %v", err)
+	}
+	if n.GetPrivateData(ChannelLawyerRegistrar, "registrar", CollectionPartyIdentities, c.ID) != nil {
+		t.Error("tampered details were stored")
+	}
+	// Fields of collections the registrar does not host are ignored
+	if err := receive(`{"uidParty1":"P1","uidParty2":"P2","clientName":"Ravi Sharma",` + salts + `}`); err != nil {
+		t.Fatal(err)
+	}
+	if got := string(n.GetPrivateData(ChannelLawyerRegistrar, "registrar", CollectionPartyIdentities, c.ID)); got != `{"caseId":"CASE_001","uidParty1":"P1","uidParty2":"P2","salts":{"partyIdentities":"`+partySalt+`"}}` {
+		t.Errorf("party identities = %s", got)
+	}
+	// A case the registrar already holds the details of may come back without them, and
+	// the details of each case received in one transaction are picked by case ID
+	if err := receive(""); err != nil {
+		t.Errorf("case returned without private details: %v", err)
+	}
+	if err := receive(`[{"caseId":"CASE_002","uidParty1":"P7"},{"caseId":"CASE_001","uidParty1":"P1","uidParty2":"P2",` + salts + `}]`); err != nil {
+		t.Errorf("details of several cases: %v", err)
+	}
+}
+
+func TestGetCasePrivateDetails(t *testing.T) {
+	n := privateNetwork()
+	c := &Case{ID: "CASE_001"}
+	err := inTransaction(t, n, "lawyer", lawyer, `{"uidParty1":"P1","clientName":"Ravi Sharma","documentHashes":{"DOC_1":"abc"},`+salts+`}`, func(ctx contractapi.TransactionContextInterface) error {
+		return SetPrivateDetails(ctx, c, OrgLawyers)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	value, _ := json.Marshal(c)
+	n.PutState(ChannelLawyerRegistrar, "lawyer", c.ID, value)
+
+	read := func(id mockstub.Identity, caseID string) (*CasePrivateDetails, error) {
+		var details *CasePrivateDetails
+		err := inTransaction(t, n, "lawyer", id, "", func(ctx contractapi.TransactionContextInterface) error {
+			var err error
+			details, err = GetCasePrivateDetails(ctx, caseID, OrgLawyers)
+			return err
+		})
+		return details, err
+	}
+
+	details, err := read(lawyer, c.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if details.ClientName != "Ravi Sharma" || details.DocumentHashes["DOC_1"] != "abc" || details.UIDParty1 != "P1" || details.Salts[CollectionPartyIdentities] != partySalt {
+		t.Errorf("details = %+v", details)
+	}
+	if _, err := read(registrar, c.ID); err == nil || err.Error() != "organization RegistrarsOrg is not authorised to read the private details of case CASE_001" {
+		t.Errorf("registrar read: %v", err)
+	}
+	if _, err := read(lawyer, "CASE_404"); err == nil || err.Error() != "case does not exist: CASE_404" {
+		t.Errorf("missing case: %v", err)
+	}
+
+	c.PrivateHashes[CollectionSealedDetails] = privateHash([]byte("something else"))
+	value, _ = json.Marshal(c)
+	n.PutState(ChannelLawyerRegistrar, "lawyer", c.ID, value)
+	if _, err := read(lawyer, c.ID); err == nil || !strings.Contains(err.Error(), "do not match the hash") {
+		t.Errorf("mismatched hash: %v", err)
+	}
+}
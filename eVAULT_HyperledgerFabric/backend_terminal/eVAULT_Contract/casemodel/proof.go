@@ -0,0 +1,375 @@
+package casemodel
+
+import (
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"strings"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/hyperledger/fabric-protos-go/common"
+	"github.com/hyperledger/fabric-protos-go/msp"
+	"github.com/hyperledger/fabric-protos-go/peer"
+)
+
+// HashLink is one entry of a case's hash chain. A link is added by every transaction
+// that stores the case, on whichever channel it runs.
+type HashLink struct {
+	CaseHash string `json:"caseHash"`                      // CaseHash of the case as the transaction stored it
+	Previous string `json:"previous" metadata:",optional"` // Hash of the link before, empty for the first
+	Hash     string `json:"hash"`                          // hash over the other fields, referred to by the next link
+	TxID     string `json:"txId"`
+	Channel  string `json:"channel"`
+	Org      string `json:"org"` // organization of the client that submitted the transaction
+}
+
+// CaseProof is the proposal of the transaction that added a case's latest link, and the
+// submitter's seal over it and the case that transaction stored. The proposal is kept
+// without its transient data, as the peer keeps it in the transaction, so the private
+// details a client passes never reach the public case. The submitter's signature over
+// the proposal it sent covers that transient data, so only the seal is kept.
+type CaseProof struct {
+	TxID        string `json:"txId"`
+	Proposal    string `json:"proposal"`                                   // base64 proposal bytes without transient data, which carry the submitter's certificate
+	HistoryHead string `json:"historyHead,omitempty" metadata:",optional"` // Hash of the last history entry, see VerifyHistory, added in version 15
+	Seal        string `json:"seal,omitempty" metadata:",optional"`        // base64 signature of the submitter over SealDigest of the link and the proposal, added in version 16
+}
+
+// TransientCaseSeals is the transient field clients pass their seals in: a JSON object
+// mapping the CaseHash of each case the transaction stores to the base64 signature over
+// its SealDigest. Clients sign the cases a simulation of the transaction stored and send
+// it again with the same ID, see SignCaseSeals.
+const TransientCaseSeals = "caseSeals"
+
+// SealDigest returns the digest a submitter signs to seal case hash caseHash as stored by
+// the transaction of proposal, the proposal bytes of a CaseProof. The proposal names the
+// transaction, its channel and time, and its submitter.
+func SealDigest(caseHash string, proposal []byte) []byte {
+	sum := sha256.Sum256(append([]byte(caseHash+"\n"), proposal...))
+	return sum[:]
+}
+
+// SignCaseSeals is run by a client on the values a simulation of transaction txID writes.
+// It signs the SealDigest of every case among them, or held in them such as the case of
+// a transfer, that the transaction sealed, and returns the transient data to send the
+// transaction again with. The proposal of the proofs must be the one the client sent,
+// without its transient data.
+func SignCaseSeals(txID string, values [][]byte, sign func(digest []byte) ([]byte, error)) (map[string][]byte, error) {
+	proposals := make(map[string]string)
+	for _, value := range values {
+		var v interface{}
+		if err := json.Unmarshal(value, &v); err == nil {
+			sealedCaseHashes(v, txID, proposals)
+		}
+	}
+	if len(proposals) == 0 {
+		return nil, nil
+	}
+	seals := make(map[string]string, len(proposals))
+	for hash, proposal := range proposals {
+		proposalBytes, err := base64.StdEncoding.DecodeString(proposal)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode the proof of case hash %s: %v", hash, err)
+		}
+		signature, err := sign(SealDigest(hash, proposalBytes))
+		if err != nil {
+			return nil, fmt.Errorf("failed to seal case hash %s: %v", hash, err)
+		}
+		seals[hash] = base64.StdEncoding.EncodeToString(signature)
+	}
+	value, err := json.Marshal(seals)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal seals: %v", err)
+	}
+	return map[string][]byte{TransientCaseSeals: value}, nil
+}
+
+// sealedCaseHashes adds to proposals the case hash and proof proposal of every object in
+// v whose hash chain ends with a link added by transaction txID
+func sealedCaseHashes(v interface{}, txID string, proposals map[string]string) {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		if chain, ok := v["hashChain"].([]interface{}); ok && len(chain) > 0 {
+			last, _ := chain[len(chain)-1].(map[string]interface{})
+			proof, _ := v["proof"].(map[string]interface{})
+			if last != nil && proof != nil && last["txId"] == txID {
+				hash, _ := last["caseHash"].(string)
+				proposal, _ := proof["proposal"].(string)
+				if hash != "" && proposal != "" {
+					proposals[hash] = proposal
+				}
+			}
+		}
+		for _, child := range v {
+			sealedCaseHashes(child, txID, proposals)
+		}
+	case []interface{}:
+		for _, child := range v {
+			sealedCaseHashes(child, txID, proposals)
+		}
+	}
+}
+
+// transientSeals returns the seals passed with the transaction, by case hash
+func transientSeals(ctx contractapi.TransactionContextInterface) (map[string]string, error) {
+	transient, err := ctx.GetStub().GetTransient()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transient data: %v", err)
+	}
+	value, ok := transient[TransientCaseSeals]
+	if !ok {
+		return nil, nil
+	}
+	var seals map[string]string
+	if err := json.Unmarshal(value, &seals); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal case seals: %v", err)
+	}
+	return seals, nil
+}
+
+// CaseHash returns the hex SHA-256 of the canonical JSON of c, leaving out its hash chain
+// and proof
+func CaseHash(c *Case) (string, error) {
+	canonical := *c
+	canonical.Normalize()
+	canonical.HashChain, canonical.Proof = nil, nil
+	value, err := json.Marshal(&canonical)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal case: %v", err)
+	}
+	sum := sha256.Sum256(value)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// linkHash returns the hash a link is referred to by
+func linkHash(l HashLink) string {
+	sum := sha256.Sum256([]byte(strings.Join([]string{l.Previous, l.CaseHash, l.TxID, l.Channel, l.Org}, "\n")))
+	return hex.EncodeToString(sum[:])
+}
+
+// Seal chains the history entries appended since c was last stored, adds a link for the
+// current transaction to c's hash chain and attaches the transaction's proposal, without
+// its transient data, as its proof, along with the head of the history chain and the
+// submitter's seal over the case, if the transaction carries one; see README.md for how
+// clients seal. Call it right before storing c. Storing the case twice in one
+// transaction replaces the transaction's link rather than adding one.
+func Seal(ctx contractapi.TransactionContextInterface, c *Case) error {
+	signedProposal, err := ctx.GetStub().GetSignedProposal()
+	if err != nil {
+		return fmt.Errorf("failed to get signed proposal: %v", err)
+	}
+	if signedProposal == nil {
+		return fmt.Errorf("transaction has no signed proposal")
+	}
+	proposal, err := withoutTransient(signedProposal.ProposalBytes)
+	if err != nil {
+		return err
+	}
+	org, err := ClientOrg(ctx)
+	if err != nil {
+		return err
+	}
+	seals, err := transientSeals(ctx)
+	if err != nil {
+		return err
+	}
+
+	c.SchemaVersion = CurrentSchemaVersion
+	if err := chainHistory(ctx, c); err != nil {
+		return err
+	}
+	link := HashLink{TxID: ctx.GetStub().GetTxID(), Channel: ctx.GetStub().GetChannelID(), Org: org}
+	if n := len(c.HashChain); n > 0 && c.HashChain[n-1].TxID == link.TxID && c.HashChain[n-1].Channel == link.Channel {
+		c.HashChain = c.HashChain[:n-1]
+	}
+	if n := len(c.HashChain); n > 0 {
+		link.Previous = c.HashChain[n-1].Hash
+	}
+	if link.CaseHash, err = CaseHash(c); err != nil {
+		return err
+	}
+	link.Hash = linkHash(link)
+	c.HashChain = append(c.HashChain, link)
+	c.Proof = &CaseProof{
+		TxID:     link.TxID,
+		Proposal: base64.StdEncoding.EncodeToString(proposal),
+		Seal:     seals[link.CaseHash],
+	}
+	if n := len(c.History); n > 0 {
+		c.Proof.HistoryHead = c.History[n-1].Hash
+	}
+	return nil
+}
+
+// withoutTransient returns proposal bytes with the transient data of their payload left
+// out, as the peer records a proposal in the transaction
+func withoutTransient(proposalBytes []byte) ([]byte, error) {
+	proposal := &peer.Proposal{}
+	if err := proto.Unmarshal(proposalBytes, proposal); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal proposal: %v", err)
+	}
+	payload := &peer.ChaincodeProposalPayload{}
+	if err := proto.Unmarshal(proposal.Payload, payload); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal proposal payload: %v", err)
+	}
+	payload.TransientMap = nil
+	var err error
+	if proposal.Payload, err = proto.Marshal(payload); err != nil {
+		return nil, fmt.Errorf("failed to marshal proposal payload: %v", err)
+	}
+	value, err := proto.Marshal(proposal)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal proposal: %v", err)
+	}
+	return value, nil
+}
+
+// VerifyHandoff checks a case fetched from another channel before it is stored. The hash
+// chain must be unbroken and end with the case as received, and the transaction that added
+// the last link must have been submitted by a client of source, whose certificate was
+// issued by a root CA trusted for source on this channel, and who sealed the case.
+func VerifyHandoff(ctx contractapi.TransactionContextInterface, c *Case, source string) error {
+	if len(c.HashChain) == 0 {
+		return fmt.Errorf("case %s has no hash chain", c.ID)
+	}
+	for i, l := range c.HashChain {
+		previous := ""
+		if i > 0 {
+			previous = c.HashChain[i-1].Hash
+		}
+		if l.Previous != previous || l.Hash != linkHash(l) {
+			return fmt.Errorf("hash chain of case %s is broken at link %d", c.ID, i)
+		}
+	}
+
+	last := c.HashChain[len(c.HashChain)-1]
+	hash, err := CaseHash(c)
+	if err != nil {
+		return err
+	}
+	if hash != last.CaseHash {
+		return fmt.Errorf("case %s does not match the hash recorded by transaction %s", c.ID, last.TxID)
+	}
+	if last.Org != source {
+		return fmt.Errorf("case %s was last stored by %s, not %s", c.ID, last.Org, source)
+	}
+	if c.Proof == nil {
+		return fmt.Errorf("case %s has no proof", c.ID)
+	}
+	if c.Proof.TxID != last.TxID {
+		return fmt.Errorf("proof of case %s is for transaction %s, not %s", c.ID, c.Proof.TxID, last.TxID)
+	}
+	return verifyProposal(ctx, c.ID, c.Proof, last)
+}
+
+// VerifyReceived checks a case another organization hands to receiver before receiver
+// stores it. The case must pass VerifyHandoff as last stored by one of senders, be held
+// by receiver, and reach its status from that of receiver's stored copy, if there is one,
+// through the lifecycle.
+func VerifyReceived(ctx contractapi.TransactionContextInterface, c *Case, receiver string, senders ...string) error {
+	if len(senders) == 0 {
+		return fmt.Errorf("no organization may hand case %s to %s", c.ID, receiver)
+	}
+	source := senders[0]
+	if n := len(c.HashChain); n > 0 {
+		for _, sender := range senders {
+			if c.HashChain[n-1].Org == sender {
+				source = sender
+			}
+		}
+	}
+	if err := VerifyHandoff(ctx, c, source); err != nil {
+		return err
+	}
+	if c.CurrentOrg != receiver {
+		return fmt.Errorf("case %s is not currently assigned to %s", c.ID, receiver)
+	}
+
+	storedJSON, err := ctx.GetStub().GetState(c.ID)
+	if err != nil {
+		return fmt.Errorf("failed to read case: %v", err)
+	}
+	if storedJSON == nil {
+		return nil
+	}
+	stored, err := DecodeCase(storedJSON)
+	if err != nil {
+		return err
+	}
+	return CheckProgress(stored, c.Status)
+}
+
+// verifyProposal checks that proof is a proposal for the transaction that added link,
+// submitted with a certificate issued to a client of link's organization, and that the
+// same client sealed the proposal and the case hash of link
+func verifyProposal(ctx contractapi.TransactionContextInterface, caseID string, proof *CaseProof, link HashLink) error {
+	proposalBytes, err := base64.StdEncoding.DecodeString(proof.Proposal)
+	if err != nil {
+		return fmt.Errorf("failed to decode proof of case %s: %v", caseID, err)
+	}
+
+	proposal := &peer.Proposal{}
+	if err := proto.Unmarshal(proposalBytes, proposal); err != nil {
+		return fmt.Errorf("failed to unmarshal proposal: %v", err)
+	}
+	header := &common.Header{}
+	if err := proto.Unmarshal(proposal.Header, header); err != nil {
+		return fmt.Errorf("failed to unmarshal proposal header: %v", err)
+	}
+	channelHeader := &common.ChannelHeader{}
+	if err := proto.Unmarshal(header.ChannelHeader, channelHeader); err != nil {
+		return fmt.Errorf("failed to unmarshal channel header: %v", err)
+	}
+	if channelHeader.TxId != link.TxID || channelHeader.ChannelId != link.Channel {
+		return fmt.Errorf("proof of case %s is for transaction %s on %s, not %s on %s", caseID, channelHeader.TxId, channelHeader.ChannelId, link.TxID, link.Channel)
+	}
+	signatureHeader := &common.SignatureHeader{}
+	if err := proto.Unmarshal(header.SignatureHeader, signatureHeader); err != nil {
+		return fmt.Errorf("failed to unmarshal signature header: %v", err)
+	}
+	sum := sha256.Sum256(append(append([]byte(nil), signatureHeader.Nonce...), signatureHeader.Creator...))
+	if txID := hex.EncodeToString(sum[:]); txID != channelHeader.TxId {
+		return fmt.Errorf("proof of case %s names transaction %s, but its nonce and creator give %s", caseID, channelHeader.TxId, txID)
+	}
+	creator := &msp.SerializedIdentity{}
+	if err := proto.Unmarshal(signatureHeader.Creator, creator); err != nil {
+		return fmt.Errorf("failed to unmarshal proposal creator: %v", err)
+	}
+	if org := OrgFromMSPID(creator.Mspid); org != link.Org {
+		return fmt.Errorf("proof of case %s was signed by %s, not %s", caseID, org, link.Org)
+	}
+
+	block, _ := pem.Decode(creator.IdBytes)
+	if block == nil {
+		return fmt.Errorf("proposal creator of case %s has no PEM certificate", caseID)
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return fmt.Errorf("failed to parse proposal creator certificate: %v", err)
+	}
+	if err := VerifyOrgCertificate(ctx, link.Org, cert, channelHeader.Timestamp.AsTime()); err != nil {
+		return fmt.Errorf("proposal creator of case %s is not a client of %s: %v", caseID, link.Org, err)
+	}
+	key, ok := cert.PublicKey.(*ecdsa.PublicKey)
+	if !ok {
+		return fmt.Errorf("proposal creator of case %s does not have an ECDSA key", caseID)
+	}
+
+	if proof.Seal == "" {
+		return fmt.Errorf("proof of case %s is not sealed by its submitter", caseID)
+	}
+	seal, err := base64.StdEncoding.DecodeString(proof.Seal)
+	if err != nil {
+		return fmt.Errorf("failed to decode proof seal of case %s: %v", caseID, err)
+	}
+	if !ecdsa.VerifyASN1(key, SealDigest(link.CaseHash, proposalBytes), seal) {
+		return fmt.Errorf("seal on the proof of case %s does not verify", caseID)
+	}
+	return nil
+}
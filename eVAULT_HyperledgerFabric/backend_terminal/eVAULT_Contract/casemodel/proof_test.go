@@ -0,0 +1,285 @@
+package casemodel
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/hyperledger/fabric-protos-go/common"
+	"github.com/hyperledger/fabric-protos-go/peer"
+
+	"casemodel/mockstub"
+)
+
+// sealer seals the case it is sent, stores it and returns it. "sealTwice" seals it twice
+// in the same transaction.
+var sealer = mockstub.ChaincodeFunc(func(stub shim.ChaincodeStubInterface) peer.Response {
+	function, args := stub.GetFunctionAndParameters()
+	c, err := DecodeCase([]byte(args[0]))
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	ctx := stub.(*mockstub.Stub).Context()
+	times := 1
+	if function == "sealTwice" {
+		times = 2
+	}
+	for i := 0; i < times; i++ {
+		c.LastModified = time.Unix(int64(i), 0).UTC().Format(time.RFC3339)
+		if err := Seal(ctx, c); err != nil {
+			return shim.Error(err.Error())
+		}
+	}
+	value, _ := json.Marshal(c)
+	if err := stub.PutState(c.ID, value); err != nil {
+		return shim.Error(err.Error())
+	}
+	return shim.Success(value)
+})
+
+// proofNetwork installs the sealer with clients that seal what they store, and trusts
+// the roots of every organization on the verifier's ledger on ChannelBenchClerkLawyer
+func proofNetwork() *mockstub.Network {
+	n := mockstub.NewNetwork(time.Date(2024, time.May, 15, 10, 0, 0, 0, time.UTC))
+	n.Install("sealer", sealer)
+	n.SignWrites = SignCaseSeals
+	trustRoots(n, ChannelBenchClerkLawyer, "verifier")
+	return n
+}
+
+// trustRoots stores the root CA of every organization on chaincode's ledger on channel
+func trustRoots(n *mockstub.Network, channel string, chaincode string) {
+	for _, org := range Organizations() {
+		n.PutState(channel, chaincode, OrgRootsKey(org), []byte(mockstub.RootCertificate(org+"MSP")))
+	}
+}
+
+// verifyHandoff runs VerifyHandoff in a transaction of the verifier on channel
+func verifyHandoff(t *testing.T, n *mockstub.Network, channel string, c *Case, source string) error {
+	t.Helper()
+	stub, err := n.NewTransaction(mockstub.Proposal{Channel: channel, Chaincode: "verifier", Identity: benchClerk})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return VerifyHandoff(stub.Context(), c, source)
+}
+
+// seal has id seal c on channel
+func seal(t *testing.T, n *mockstub.Network, channel string, id mockstub.Identity, function string, c *Case) *Case {
+	t.Helper()
+	value, _ := json.Marshal(c)
+	payload, err := n.Submit(channel, "sealer", id, function, string(value))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sealed, err := DecodeCase(payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return sealed
+}
+
+func TestSealBuildsHashChain(t *testing.T) {
+	n := proofNetwork()
+	c := &Case{ID: "CASE_001", Status: StatusJudgmentReceived, CurrentOrg: OrgBenchClerks}
+
+	first := seal(t, n, ChannelBenchClerkJudge, judge, "seal", c)
+	if err := verifyHandoff(t, n, ChannelBenchClerkLawyer, first, OrgJudges); err != nil {
+		t.Fatal(err)
+	}
+	if len(first.HashChain) != 1 || first.HashChain[0].Previous != "" || first.HashChain[0].Channel != ChannelBenchClerkJudge || first.SchemaVersion != CurrentSchemaVersion {
+		t.Errorf("first = %+v", first.HashChain)
+	}
+
+	second := seal(t, n, ChannelBenchClerkLawyer, benchClerk, "sealTwice", first)
+	if err := verifyHandoff(t, n, ChannelBenchClerkLawyer, second, OrgBenchClerks); err != nil {
+		t.Fatal(err)
+	}
+	if len(second.HashChain) != 2 || second.HashChain[1].Previous != first.HashChain[0].Hash || second.Proof.TxID != second.HashChain[1].TxID {
+		t.Errorf("second = %+v", second.HashChain)
+	}
+}
+
+func TestVerifyHandoffDetectsTampering(t *testing.T) {
+	n := proofNetwork()
+	sealed := seal(t, n, ChannelBenchClerkJudge, judge, "seal", &Case{ID: "CASE_001", Title: "Land dispute", CurrentOrg: OrgBenchClerks})
+	other := seal(t, n, ChannelBenchClerkJudge, judge, "seal", &Case{ID: "CASE_002", CurrentOrg: OrgBenchClerks})
+	forger := mockstub.Identity{MSPID: "JudgesOrgMSP", Name: "judge1", SelfSigned: true}
+	selfSigned := seal(t, n, ChannelBenchClerkJudge, forger, "seal", &Case{ID: "CASE_001", Title: "Land dispute", CurrentOrg: OrgBenchClerks})
+
+	tests := []struct {
+		name    string
+		tamper  func(c *Case)
+		source  string
+		wantErr string
+	}{
+		{"untouched", func(c *Case) {}, OrgJudges, ""},
+		{"another source", func(c *Case) {}, OrgStampReporters, "case CASE_001 was last stored by JudgesOrg, not StampReportersOrg"},
+		{"edited case", func(c *Case) { c.Title = "Settled" }, OrgJudges, "case CASE_001 does not match the hash recorded by transaction"},
+		{"edited link", func(c *Case) { c.HashChain[0].Org = OrgStampReporters }, OrgStampReporters, "hash chain of case CASE_001 is broken at link 0"},
+		{"relinked chain", func(c *Case) {
+			c.HashChain[0].Org = OrgStampReporters
+			c.HashChain[0].Hash = linkHash(c.HashChain[0])
+		}, OrgStampReporters, "proof of case CASE_001 was signed by JudgesOrg, not StampReportersOrg"},
+		{"no chain", func(c *Case) { c.HashChain = nil }, OrgJudges, "case CASE_001 has no hash chain"},
+		{"no proof", func(c *Case) { c.Proof = nil }, OrgJudges, "case CASE_001 has no proof"},
+		{"proof of another case", func(c *Case) { c.Proof = other.Proof }, OrgJudges, "proof of case CASE_001 is for transaction"},
+		{"forged seal", func(c *Case) {
+			seal, _ := base64.StdEncoding.DecodeString(c.Proof.Seal)
+			seal[len(seal)-1] ^= 0xff
+			c.Proof.Seal = base64.StdEncoding.EncodeToString(seal)
+		}, OrgJudges, "seal on the proof of case CASE_001 does not verify"},
+		{"proof of another case on a made-up chain", func(c *Case) {
+			// the chain ends with a link naming the transaction of the real proof
+			c.Title = "Settled"
+			link := other.HashChain[0]
+			link.CaseHash, _ = CaseHash(c)
+			link.Hash = linkHash(link)
+			c.HashChain, c.Proof = []HashLink{link}, other.Proof
+		}, OrgJudges, "seal on the proof of case CASE_001 does not verify"},
+		{"not sealed", func(c *Case) { c.Proof.Seal = "" }, OrgJudges, "proof of case CASE_001 is not sealed by its submitter"},
+		{"self-signed creator", func(c *Case) { c.HashChain, c.Proof = selfSigned.HashChain, selfSigned.Proof }, OrgJudges, "proposal creator of case CASE_001 is not a client of JudgesOrg"},
+		{"transaction ID not from the nonce", func(c *Case) {
+			proposalBytes, _ := base64.StdEncoding.DecodeString(c.Proof.Proposal)
+			proposal := &peer.Proposal{}
+			header := &common.Header{}
+			channelHeader := &common.ChannelHeader{}
+			_ = proto.Unmarshal(proposalBytes, proposal)
+			_ = proto.Unmarshal(proposal.Header, header)
+			_ = proto.Unmarshal(header.ChannelHeader, channelHeader)
+			channelHeader.TxId = "forged"
+			header.ChannelHeader, _ = proto.Marshal(channelHeader)
+			proposal.Header, _ = proto.Marshal(header)
+			proposalBytes, _ = proto.Marshal(proposal)
+			c.Proof.Proposal, c.Proof.TxID = base64.StdEncoding.EncodeToString(proposalBytes), "forged"
+			c.HashChain[0].TxID = "forged"
+			c.HashChain[0].Hash = linkHash(c.HashChain[0])
+		}, OrgJudges, "proof of case CASE_001 names transaction forged, but its nonce and creator give"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value, _ := json.Marshal(sealed)
+			c, _ := DecodeCase(value)
+			tt.tamper(c)
+			err := verifyHandoff(t, n, ChannelBenchClerkLawyer, c, tt.source)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("err = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSealLeavesOutTransientData(t *testing.T) {
+	n := proofNetwork()
+	value, _ := json.Marshal(&Case{ID: "CASE_001", CurrentOrg: OrgBenchClerks})
+	details := map[string][]byte{TransientPrivateDetails: []byte(`{"clientName":"Ravi Sharma","uidParty1":"123456789012"}`)}
+	payload, err := n.SubmitTransient(ChannelBenchClerkJudge, "sealer", judge, details, "seal", string(value))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sealed, err := DecodeCase(payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	proposal, _ := base64.StdEncoding.DecodeString(sealed.Proof.Proposal)
+	if strings.Contains(string(proposal), "Ravi Sharma") || strings.Contains(string(proposal), "123456789012") {
+		t.Error("proof carries the private details passed with the transaction")
+	}
+	if err := verifyHandoff(t, n, ChannelBenchClerkLawyer, sealed, OrgJudges); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestVerifyHandoffNeedsTrustedRoots(t *testing.T) {
+	n := proofNetwork()
+	sealed := seal(t, n, ChannelBenchClerkJudge, judge, "seal", &Case{ID: "CASE_001", CurrentOrg: OrgBenchClerks})
+	err := verifyHandoff(t, n, ChannelBenchClerkJudge, sealed, OrgJudges)
+	if err == nil || !strings.Contains(err.Error(), "no root certificates are trusted for JudgesOrg on this channel") {
+		t.Errorf("err = %v", err)
+	}
+}
+
+func TestSignCaseSeals(t *testing.T) {
+	transfer := `{"sequence":1,"case":{"id":"CASE_002","hashChain":[{"caseHash":"c2","txId":"tx9"}],"proof":{"txId":"tx9","proposal":"cDk="}}}`
+	values := [][]byte{
+		[]byte(`{"id":"CASE_001","hashChain":[{"caseHash":"c0","txId":"tx8"},{"caseHash":"c1","txId":"tx9"}],"proof":{"txId":"tx9","proposal":"cDk="}}`),
+		[]byte(transfer),
+		[]byte(`{"id":"CASE_003","hashChain":[{"caseHash":"c3","txId":"tx8"}],"proof":{"txId":"tx8","proposal":"cDg="}}`),
+		[]byte(`{"id":"CASE_004","hashChain":[{"caseHash":"c4","txId":"tx9"}]}`),
+		[]byte("42"),
+	}
+	signed := make(map[string]bool)
+	sign := func(digest []byte) ([]byte, error) {
+		signed[string(digest)] = true
+		return []byte("signature"), nil
+	}
+	transient, err := SignCaseSeals("tx9", values, sign)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var seals map[string]string
+	if err := json.Unmarshal(transient[TransientCaseSeals], &seals); err != nil {
+		t.Fatal(err)
+	}
+	if len(seals) != 2 || seals["c1"] == "" || seals["c2"] == "" || !signed[string(SealDigest("c1", []byte("p9")))] || !signed[string(SealDigest("c2", []byte("p9")))] {
+		t.Errorf("seals = %v", seals)
+	}
+
+	if transient, err := SignCaseSeals("tx7", values, sign); err != nil || transient != nil {
+		t.Errorf("transient = %v, %v, want nothing to seal", transient, err)
+	}
+}
+
+func TestVerifyReceived(t *testing.T) {
+	n := proofNetwork()
+	sealed := seal(t, n, ChannelBenchClerkJudge, judge, "seal", &Case{ID: "CASE_001", Status: StatusJudgmentIssued, CurrentOrg: OrgBenchClerks})
+
+	tests := []struct {
+		name     string
+		stored   string // status of the bench clerk's copy, none when empty
+		receiver string
+		senders  []string
+		wantErr  string
+	}{
+		{"first copy", "", OrgBenchClerks, []string{OrgJudges}, ""},
+		{"later status", StatusPendingJudgeReview, OrgBenchClerks, []string{OrgStampReporters, OrgJudges}, ""},
+		{"same status", StatusJudgmentIssued, OrgBenchClerks, []string{OrgJudges}, ""},
+		{"another sender", "", OrgBenchClerks, []string{OrgStampReporters}, "case CASE_001 was last stored by JudgesOrg, not StampReportersOrg"},
+		{"another receiver", "", OrgLawyers, []string{OrgJudges}, "case CASE_001 is not currently assigned to LawyersOrg"},
+		{"stale copy", StatusDecisionConfirmed, OrgBenchClerks, []string{OrgJudges}, "case CASE_001 cannot move from DECISION_CONFIRMED to JUDGMENT_ISSUED"},
+		{"no senders", "", OrgBenchClerks, nil, "no organization may hand case CASE_001 to BenchClerksOrg"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n := privateNetwork()
+			trustRoots(n, ChannelLawyerRegistrar, "benchclerk")
+			if tt.stored != "" {
+				value, _ := json.Marshal(&Case{ID: "CASE_001", Status: tt.stored, CurrentOrg: OrgBenchClerks})
+				n.PutState(ChannelLawyerRegistrar, "benchclerk", "CASE_001", value)
+			}
+			err := inTransaction(t, n, "benchclerk", judge, "", func(ctx contractapi.TransactionContextInterface) error {
+				return VerifyReceived(ctx, sealed, tt.receiver, tt.senders...)
+			})
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("err = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,149 @@
+package casemodel
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// provenanceSkipped are case fields left out of provenance diffs. Seal rewrites them on
+// every store, so they would show up as changed in every version.
+var provenanceSkipped = map[string]bool{"hashChain": true, "proof": true}
+
+// CaseProvenance lists every committed version of a case key, oldest first
+type CaseProvenance struct {
+	CaseID   string        `json:"caseId"`
+	Versions []CaseVersion `json:"versions"`
+}
+
+// CaseVersion is one committed write to a case key
+type CaseVersion struct {
+	TxID      string        `json:"txId"`
+	Timestamp string        `json:"timestamp"`
+	IsDelete  bool          `json:"isDelete"`
+	Case      *Case         `json:"case,omitempty" metadata:",optional"` // nil for deletions
+	Changes   []FieldChange `json:"changes"`                             // against the version before; the first and a deletion are diffed with an empty case
+}
+
+// FieldChange is a field whose value differs between two versions of a case. Field is a
+// path into the case JSON such as "title", "judgment.decision" or "documents[1].hash".
+// Before and After hold the JSON values and are empty where the field is absent.
+type FieldChange struct {
+	Field  string `json:"field"`
+	Before string `json:"before" metadata:",optional"`
+	After  string `json:"after" metadata:",optional"`
+}
+
+// GetCaseProvenance reads the ledger history of a case key and diffs each version
+// against the one before it
+func GetCaseProvenance(ctx contractapi.TransactionContextInterface, caseID string) (*CaseProvenance, error) {
+	iterator, err := ctx.GetStub().GetHistoryForKey(caseID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get history for case %s: %v", caseID, err)
+	}
+	defer iterator.Close()
+
+	// Fabric returns the newest change first
+	versions := make([]CaseVersion, 0)
+	values := make([][]byte, 0)
+	for iterator.HasNext() {
+		modification, err := iterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read history for case %s: %v", caseID, err)
+		}
+		version := CaseVersion{TxID: modification.TxId, IsDelete: modification.IsDelete}
+		if modification.Timestamp != nil {
+			version.Timestamp = time.Unix(modification.Timestamp.Seconds, int64(modification.Timestamp.Nanos)).Format(time.RFC3339)
+		}
+		if !modification.IsDelete {
+			if version.Case, err = DecodeCase(modification.Value); err != nil {
+				return nil, fmt.Errorf("version %s: %v", modification.TxId, err)
+			}
+		}
+		versions = append([]CaseVersion{version}, versions...)
+		values = append([][]byte{modification.Value}, values...)
+	}
+	if len(versions) == 0 {
+		return nil, fmt.Errorf("case does not exist: %s", caseID)
+	}
+
+	previous := map[string]interface{}{}
+	for i := range versions {
+		current := map[string]interface{}{}
+		if !versions[i].IsDelete {
+			if err := json.Unmarshal(values[i], &current); err != nil {
+				return nil, fmt.Errorf("failed to unmarshal version %s: %v", versions[i].TxID, err)
+			}
+		}
+		for field := range provenanceSkipped {
+			delete(current, field)
+		}
+		versions[i].Changes = make([]FieldChange, 0)
+		diffValues("", previous, current, &versions[i].Changes)
+		previous = current
+	}
+	return &CaseProvenance{CaseID: caseID, Versions: versions}, nil
+}
+
+// diffValues appends a change for each value that differs between before and after.
+// Objects are compared key by key and arrays index by index; anything else, including a
+// field that is added or removed, is reported whole.
+func diffValues(path string, before interface{}, after interface{}, changes *[]FieldChange) {
+	if reflect.DeepEqual(before, after) {
+		return
+	}
+	beforeMap, beforeIsMap := before.(map[string]interface{})
+	afterMap, afterIsMap := after.(map[string]interface{})
+	if beforeIsMap && afterIsMap {
+		keys := make([]string, 0)
+		for key := range beforeMap {
+			keys = append(keys, key)
+		}
+		for key := range afterMap {
+			if _, ok := beforeMap[key]; !ok {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			field := key
+			if path != "" {
+				field = path + "." + key
+			}
+			diffValues(field, beforeMap[key], afterMap[key], changes)
+		}
+		return
+	}
+	beforeList, beforeIsList := before.([]interface{})
+	afterList, afterIsList := after.([]interface{})
+	if beforeIsList && afterIsList {
+		for i := 0; i < len(beforeList) || i < len(afterList); i++ {
+			var b, a interface{}
+			if i < len(beforeList) {
+				b = beforeList[i]
+			}
+			if i < len(afterList) {
+				a = afterList[i]
+			}
+			diffValues(fmt.Sprintf("%s[%d]", path, i), b, a, changes)
+		}
+		return
+	}
+	*changes = append(*changes, FieldChange{Field: path, Before: jsonValue(before), After: jsonValue(after)})
+}
+
+// jsonValue returns v as compact JSON, or an empty string for an absent value
+func jsonValue(v interface{}) string {
+	if v == nil {
+		return ""
+	}
+	value, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	return string(value)
+}
@@ -0,0 +1,112 @@
+package casemodel
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-protos-go/peer"
+
+	"casemodel/mockstub"
+)
+
+// writer stores ("put"), deletes ("delete") and reports the provenance of ("provenance")
+// cases. Writes return the ID of their transaction.
+var writer = mockstub.ChaincodeFunc(func(stub shim.ChaincodeStubInterface) peer.Response {
+	function, args := stub.GetFunctionAndParameters()
+	switch function {
+	case "put":
+		c, err := DecodeCase([]byte(args[0]))
+		if err != nil {
+			return shim.Error(err.Error())
+		}
+		if err := stub.PutState(c.ID, []byte(args[0])); err != nil {
+			return shim.Error(err.Error())
+		}
+	case "delete":
+		if err := stub.DelState(args[0]); err != nil {
+			return shim.Error(err.Error())
+		}
+	case "provenance":
+		provenance, err := GetCaseProvenance(stub.(*mockstub.Stub).Context(), args[0])
+		if err != nil {
+			return shim.Error(err.Error())
+		}
+		value, _ := json.Marshal(provenance)
+		return shim.Success(value)
+	}
+	return shim.Success([]byte(stub.GetTxID()))
+})
+
+func TestGetCaseProvenance(t *testing.T) {
+	start := time.Date(2024, time.May, 15, 10, 0, 0, 0, time.UTC)
+	n := mockstub.NewNetwork(start)
+	n.Step = time.Minute
+	n.Install("writer", writer)
+	var txIDs []string
+	submit := func(function string, arg string) {
+		t.Helper()
+		txID, err := n.Submit(ChannelBenchClerkJudge, "writer", judge, function, arg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		txIDs = append(txIDs, string(txID))
+	}
+
+	submit("put", `{"id":"CASE_001","title":"Land dispute","documents":[]}`)
+	submit("put", `{"id":"CASE_001","title":"Sharma vs Patel","documents":[{"id":"DOC_1","hash":"abc"}],"judgment":{"decision":"Decree granted"},"proof":{"txId":"tx2"}}`)
+	submit("put", `{"id":"CASE_001","title":"Sharma vs Patel","documents":[{"id":"DOC_1","hash":"def"}],"judgment":{"decision":"Suit dismissed"},"proof":{"txId":"tx3"}}`)
+	submit("delete", "CASE_001")
+	submit("put", `{"id":"CASE_001","title":"Sharma vs Patel"}`)
+
+	payload, err := n.Evaluate(ChannelBenchClerkJudge, "writer", judge, "provenance", "CASE_001")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var provenance CaseProvenance
+	if err := json.Unmarshal(payload, &provenance); err != nil {
+		t.Fatal(err)
+	}
+
+	// changes renders each version's changes as "field:before>after"
+	changes := func(v CaseVersion) string {
+		var list []string
+		for _, c := range v.Changes {
+			list = append(list, c.Field+":"+c.Before+">"+c.After)
+		}
+		return strings.Join(list, " ")
+	}
+	want := []struct {
+		txID     string
+		isDelete bool
+		changes  string
+	}{
+		{txIDs[0], false, `documents:>[] id:>"CASE_001" title:>"Land dispute"`},
+		{txIDs[1], false, `documents[0]:>{"hash":"abc","id":"DOC_1"} judgment:>{"decision":"Decree granted"} title:"Land dispute">"Sharma vs Patel"`},
+		{txIDs[2], false, `documents[0].hash:"abc">"def" judgment.decision:"Decree granted">"Suit dismissed"`},
+		{txIDs[3], true, `documents:[{"hash":"def","id":"DOC_1"}]> id:"CASE_001"> judgment:{"decision":"Suit dismissed"}> title:"Sharma vs Patel">`},
+		{txIDs[4], false, `id:>"CASE_001" title:>"Sharma vs Patel"`},
+	}
+	if len(provenance.Versions) != len(want) {
+		t.Fatalf("got %d versions, want %d", len(provenance.Versions), len(want))
+	}
+	for i, w := range want {
+		v := provenance.Versions[i]
+		if v.TxID != w.txID || v.IsDelete != w.isDelete || changes(v) != w.changes {
+			t.Errorf("version %d = %s delete=%v %s\nwant %s delete=%v %s", i, v.TxID, v.IsDelete, changes(v), w.txID, w.isDelete, w.changes)
+		}
+		if (v.Case == nil) != w.isDelete {
+			t.Errorf("version %d case = %+v", i, v.Case)
+		}
+	}
+	first, last := time.Unix(start.Unix(), 0).Format(time.RFC3339), time.Unix(start.Add(4*time.Minute).Unix(), 0).Format(time.RFC3339)
+	if provenance.Versions[0].Timestamp != first || provenance.Versions[4].Timestamp != last {
+		t.Errorf("timestamps = %s, %s", provenance.Versions[0].Timestamp, provenance.Versions[4].Timestamp)
+	}
+
+	if _, err := n.Evaluate(ChannelBenchClerkJudge, "writer", judge, "provenance", "CASE_404"); err == nil || err.Error() != "case does not exist: CASE_404" {
+		t.Errorf("err = %v", err)
+	}
+}
@@ -0,0 +1,203 @@
+// Package query builds CouchDB rich queries. Every value passes through encoding/json,
+// so caller input such as a lawyer ID or a filter field cannot change the structure of
+// the selector.
+//
+// Build selectors inline in the call to New, for example
+//
+//	query.New(query.And(
+//		query.Eq("status", casemodel.StatusPendingRegistrarReview),
+//		query.EqIfSet("type", filter.CaseType),
+//	)).String()
+//
+// so that the index check in casemodel/indexcheck can read the fields being queried.
+package query
+
+import (
+	"encoding/json"
+)
+
+// Selector is a CouchDB Mango selector. The zero Selector matches every document and
+// is dropped when combined with And or Or.
+type Selector struct {
+	clauses map[string]interface{}
+}
+
+// MarshalJSON encodes the selector as a JSON object
+func (s Selector) MarshalJSON() ([]byte, error) {
+	if s.clauses == nil {
+		return []byte("{}"), nil
+	}
+	return json.Marshal(s.clauses)
+}
+
+// IsEmpty reports whether the selector has no conditions
+func (s Selector) IsEmpty() bool {
+	return len(s.clauses) == 0
+}
+
+func field(name string, condition interface{}) Selector {
+	return Selector{clauses: map[string]interface{}{name: condition}}
+}
+
+// Eq matches documents whose field equals value
+func Eq(name string, value string) Selector {
+	return field(name, value)
+}
+
+// EqIfSet is Eq when value is not empty and the empty selector otherwise. Use it for
+// optional filter fields.
+func EqIfSet(name string, value string) Selector {
+	if value == "" {
+		return Selector{}
+	}
+	return Eq(name, value)
+}
+
+// Ne matches documents whose field does not equal value
+func Ne(name string, value string) Selector {
+	return field(name, map[string]interface{}{"$ne": value})
+}
+
+// NotNull matches documents whose field is present and not null
+func NotNull(name string) Selector {
+	return field(name, map[string]interface{}{"$ne": nil})
+}
+
+// In matches documents whose field equals one of values
+func In(name string, values ...string) Selector {
+	if values == nil {
+		values = []string{}
+	}
+	return field(name, map[string]interface{}{"$in": values})
+}
+
+// Range matches documents whose field is at least from and less than to. An empty
+// bound is left open, and with both bounds empty Range is the empty selector.
+func Range(name string, from string, to string) Selector {
+	condition := make(map[string]interface{})
+	if from != "" {
+		condition["$gte"] = from
+	}
+	if to != "" {
+		condition["$lt"] = to
+	}
+	if len(condition) == 0 {
+		return Selector{}
+	}
+	return field(name, condition)
+}
+
+// Exists matches documents that do or do not have the field
+func Exists(name string, exists bool) Selector {
+	return field(name, map[string]interface{}{"$exists": exists})
+}
+
+// Regex matches documents whose field matches a regular expression. Quote untrusted
+// input with regexp.QuoteMeta before using it in a pattern.
+func Regex(name string, pattern string) Selector {
+	return field(name, map[string]interface{}{"$regex": pattern})
+}
+
+// Includes matches documents whose array field contains value
+func Includes(name string, value string) Selector {
+	return field(name, map[string]interface{}{"$elemMatch": map[string]interface{}{"$eq": value}})
+}
+
+// ElemMatch matches documents whose array field has an element matching match
+func ElemMatch(name string, match Selector) Selector {
+	return field(name, map[string]interface{}{"$elemMatch": match})
+}
+
+// And matches documents that match every selector. Selectors on distinct fields are
+// merged into one object; otherwise they are combined with $and.
+func And(selectors ...Selector) Selector {
+	selectors = nonEmpty(selectors)
+	if len(selectors) == 1 {
+		return selectors[0]
+	}
+
+	merged := make(map[string]interface{})
+	for _, selector := range selectors {
+		for name, condition := range selector.clauses {
+			if _, exists := merged[name]; exists {
+				return field("$and", selectors)
+			}
+			merged[name] = condition
+		}
+	}
+	return Selector{clauses: merged}
+}
+
+// Or matches documents that match at least one selector
+func Or(selectors ...Selector) Selector {
+	selectors = nonEmpty(selectors)
+	if len(selectors) == 1 {
+		return selectors[0]
+	}
+	if len(selectors) == 0 {
+		return Selector{}
+	}
+	return field("$or", selectors)
+}
+
+func nonEmpty(selectors []Selector) []Selector {
+	var kept []Selector
+	for _, selector := range selectors {
+		if !selector.IsEmpty() {
+			kept = append(kept, selector)
+		}
+	}
+	return kept
+}
+
+// Direction is a sort order
+type Direction string
+
+// Sort orders
+const (
+	Asc  Direction = "asc"
+	Desc Direction = "desc"
+)
+
+// Query is a CouchDB rich query
+type Query struct {
+	selector Selector
+	sort     []map[string]Direction
+	fields   []string
+}
+
+// New returns a query for documents matching selector
+func New(selector Selector) *Query {
+	return &Query{selector: selector}
+}
+
+// Sort orders results by a field. CouchDB only sorts on indexed fields that the
+// selector constrains, so the selector is narrowed to documents that have the field.
+func (q *Query) Sort(name string, direction Direction) *Query {
+	q.selector = And(q.selector, field(name, map[string]interface{}{"$gt": nil}))
+	q.sort = append(q.sort, map[string]Direction{name: direction})
+	return q
+}
+
+// Fields limits the fields returned for each document
+func (q *Query) Fields(names ...string) *Query {
+	q.fields = append(q.fields, names...)
+	return q
+}
+
+// MarshalJSON encodes the query in the form expected by GetQueryResult
+func (q *Query) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Selector Selector               `json:"selector"`
+		Sort     []map[string]Direction `json:"sort,omitempty"`
+		Fields   []string               `json:"fields,omitempty"`
+	}{q.selector, q.sort, q.fields})
+}
+
+// String returns the query JSON
+func (q *Query) String() string {
+	// Selectors only hold strings, booleans, nil and nested selectors, all of which
+	// encoding/json can marshal
+	data, _ := json.Marshal(q)
+	return string(data)
+}
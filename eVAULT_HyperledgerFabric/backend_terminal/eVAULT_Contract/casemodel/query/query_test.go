@@ -0,0 +1,153 @@
+package query
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestQueryJSON(t *testing.T) {
+	tests := []struct {
+		name  string
+		query *Query
+		want  string
+	}{
+		{
+			"equality",
+			New(And(Eq("status", "PENDING_REGISTRAR_REVIEW"), Eq("currentOrg", "RegistrarsOrg"))),
+			`{"selector":{"currentOrg":"RegistrarsOrg","status":"PENDING_REGISTRAR_REVIEW"}}`,
+		},
+		{
+			"optional field omitted",
+			New(And(Eq("status", "VERIFIED_BY_REGISTRAR"), EqIfSet("department", ""))),
+			`{"selector":{"status":"VERIFIED_BY_REGISTRAR"}}`,
+		},
+		{
+			"in and exists",
+			New(And(In("status", "A", "B"), Exists("associatedLawyers", true))),
+			`{"selector":{"associatedLawyers":{"$exists":true},"status":{"$in":["A","B"]}}}`,
+		},
+		{
+			"or with array matches",
+			New(Or(Includes("associatedLawyers", "L001"), Eq("createdBy", "L001"), ElemMatch("hearings", Eq("status", "SCHEDULED")))),
+			`{"selector":{"$or":[{"associatedLawyers":{"$elemMatch":{"$eq":"L001"}}},{"createdBy":"L001"},{"hearings":{"$elemMatch":{"status":"SCHEDULED"}}}]}}`,
+		},
+		{
+			"repeated field falls back to $and",
+			New(And(Regex("title", "^Land"), Regex("title", "dispute$"))),
+			`{"selector":{"$and":[{"title":{"$regex":"^Land"}},{"title":{"$regex":"dispute$"}}]}}`,
+		},
+		{
+			"not null",
+			New(NotNull("judgment")),
+			`{"selector":{"judgment":{"$ne":null}}}`,
+		},
+		{
+			"range",
+			New(And(Range("createdAt", "2024-01-01T00:00:00Z", "2024-02-01T00:00:00Z"), Range("lastModified", "", ""))),
+			`{"selector":{"createdAt":{"$gte":"2024-01-01T00:00:00Z","$lt":"2024-02-01T00:00:00Z"}}}`,
+		},
+		{
+			"empty selector",
+			New(Or()).Fields("id", "status"),
+			`{"selector":{},"fields":["id","status"]}`,
+		},
+		{
+			"sort constrains the sort field",
+			New(Eq("status", "A")).Sort("lastModified", Desc),
+			`{"selector":{"lastModified":{"$gt":null},"status":"A"},"sort":[{"lastModified":"desc"}]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.query.String(); got != tt.want {
+				t.Errorf("got  %s\nwant %s", got, tt.want)
+			}
+		})
+	}
+}
+
+// selectorOf parses the selector of a built query
+func selectorOf(t *testing.T, q *Query) map[string]interface{} {
+	t.Helper()
+	var parsed struct {
+		Selector map[string]interface{} `json:"selector"`
+	}
+	if err := json.Unmarshal([]byte(q.String()), &parsed); err != nil {
+		t.Fatalf("query is not valid JSON: %v", err)
+	}
+	return parsed.Selector
+}
+
+// encoded returns value as it reads back after a JSON round trip
+func encoded(value string) string {
+	if utf8.ValidString(value) {
+		return value
+	}
+	data, _ := json.Marshal(value)
+	var decoded string
+	_ = json.Unmarshal(data, &decoded)
+	return decoded
+}
+
+func FuzzEqCannotAddClauses(f *testing.F) {
+	f.Add("L001")
+	f.Add(`L001"}, "$or": [{"status": {"$exists": true}}], "x": {"`)
+	f.Add(`\"},{"currentOrg":"LawyersOrg`)
+	f.Add("\x00\xff\"\\")
+	f.Fuzz(func(t *testing.T, value string) {
+		selector := selectorOf(t, New(And(Eq("status", "PENDING_REGISTRAR_REVIEW"), Eq("createdBy", value))))
+
+		want := map[string]interface{}{"status": "PENDING_REGISTRAR_REVIEW", "createdBy": encoded(value)}
+		if !reflect.DeepEqual(selector, want) {
+			t.Errorf("selector = %#v, want %#v", selector, want)
+		}
+	})
+}
+
+func FuzzFilterCannotChangeStructure(f *testing.F) {
+	f.Add("L001", "CIVIL", "land")
+	f.Add(`"}]}`, `", "$where": "1`, `.*`)
+	f.Add("", "", "")
+	f.Fuzz(func(t *testing.T, lawyerID string, caseType string, search string) {
+		selector := selectorOf(t, New(And(
+			Eq("status", "VERIFIED_BY_REGISTRAR"),
+			EqIfSet("type", caseType),
+			Regex("title", search),
+			Or(Includes("associatedLawyers", lawyerID), Eq("createdBy", lawyerID)),
+		)))
+
+		keys := []string{"$or", "status", "title"}
+		if caseType != "" {
+			keys = append(keys, "type")
+		}
+		if len(selector) != len(keys) {
+			t.Fatalf("selector has keys %v, want %v", selector, keys)
+		}
+		for _, key := range keys {
+			if _, ok := selector[key]; !ok {
+				t.Fatalf("selector is missing %q: %v", key, selector)
+			}
+		}
+		if caseType != "" && selector["type"] != encoded(caseType) {
+			t.Errorf("type = %#v, want %q", selector["type"], caseType)
+		}
+		if title := selector["title"].(map[string]interface{}); len(title) != 1 || title["$regex"] != encoded(search) {
+			t.Errorf("title = %#v", title)
+		}
+
+		branches, ok := selector["$or"].([]interface{})
+		if !ok || len(branches) != 2 {
+			t.Fatalf("$or = %#v", selector["$or"])
+		}
+		wantBranches := []interface{}{
+			map[string]interface{}{"associatedLawyers": map[string]interface{}{"$elemMatch": map[string]interface{}{"$eq": encoded(lawyerID)}}},
+			map[string]interface{}{"createdBy": encoded(lawyerID)},
+		}
+		if !reflect.DeepEqual(branches, wantBranches) {
+			t.Errorf("$or = %#v, want %#v", branches, wantBranches)
+		}
+	})
+}
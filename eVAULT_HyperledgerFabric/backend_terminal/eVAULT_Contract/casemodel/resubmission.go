@@ -0,0 +1,147 @@
+package casemodel
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// rejectionType is the composite key object type of the copy of a case the lawyer keeps
+// as the registrar rejected it, so that it does not show up as a case
+const rejectionType = "registrarRejection"
+
+// resubmissionSkipped are case fields left out of a resubmission diff on top of
+// provenanceSkipped. Rejecting and resubmitting a case changes them every time.
+var resubmissionSkipped = map[string]bool{
+	"status":            true,
+	"currentOrg":        true,
+	"history":           true,
+	"lastModified":      true,
+	"resubmissionCount": true,
+	"resubmissions":     true,
+}
+
+// Resubmission is one attempt by the lawyer to file a case again after the registrar
+// rejected it
+type Resubmission struct {
+	Attempt         int           `json:"attempt"` // 1 for the first resubmission
+	RejectedAt      string        `json:"rejectedAt"`
+	RejectionReason string        `json:"rejectionReason"` // registrar's comments on the rejection
+	ResubmittedAt   string        `json:"resubmittedAt"`
+	Changes         []FieldChange `json:"changes"` // what the lawyer changed since the rejection
+}
+
+func rejectionKey(ctx contractapi.TransactionContextInterface, caseID string) (string, error) {
+	key, err := ctx.GetStub().CreateCompositeKey(rejectionType, []string{caseID})
+	if err != nil {
+		return "", fmt.Errorf("failed to create rejection key: %v", err)
+	}
+	return key, nil
+}
+
+// RecordRejection keeps a copy of c, which the registrar has just rejected, for the next
+// resubmission to be diffed against, see Resubmit
+func RecordRejection(ctx contractapi.TransactionContextInterface, c *Case) error {
+	if c.Status != StatusRejectedByRegistrar {
+		return fmt.Errorf("case %s is %s, not %s", c.ID, c.Status, StatusRejectedByRegistrar)
+	}
+	data, err := json.Marshal(c)
+	if err != nil {
+		return fmt.Errorf("failed to marshal case: %v", err)
+	}
+	key, err := rejectionKey(ctx, c.ID)
+	if err != nil {
+		return err
+	}
+	if err := ctx.GetStub().PutState(key, data); err != nil {
+		return fmt.Errorf("failed to store rejection of case %s: %v", c.ID, err)
+	}
+	return nil
+}
+
+// GetRejection returns the copy of a case kept when the registrar last rejected it
+func GetRejection(ctx contractapi.TransactionContextInterface, caseID string) (*Case, error) {
+	key, err := rejectionKey(ctx, caseID)
+	if err != nil {
+		return nil, err
+	}
+	data, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read rejection of case %s: %v", caseID, err)
+	}
+	if data == nil {
+		return nil, fmt.Errorf("case %s has no registrar rejection to resubmit", caseID)
+	}
+	return DecodeCase(data)
+}
+
+// Resubmit records a resubmission of c, listing what changed since the registrar rejected
+// it, and bumps its resubmission counter. A case that has not changed cannot be
+// resubmitted. The rejection copy is removed so that it is only used once.
+func Resubmit(ctx contractapi.TransactionContextInterface, c *Case, resubmittedAt string) (*Resubmission, error) {
+	rejected, err := GetRejection(ctx, c.ID)
+	if err != nil {
+		return nil, err
+	}
+	changes, err := DiffCases(rejected, c, resubmissionSkipped)
+	if err != nil {
+		return nil, err
+	}
+	if len(changes) == 0 {
+		return nil, fmt.Errorf("case %s has not changed since the registrar rejected it", c.ID)
+	}
+
+	resubmission := Resubmission{
+		Attempt:       c.ResubmissionCount + 1,
+		RejectedAt:    rejected.LastModified,
+		ResubmittedAt: resubmittedAt,
+		Changes:       changes,
+	}
+	for _, item := range rejected.History {
+		if item.Status == StatusRejectedByRegistrar {
+			resubmission.RejectedAt, resubmission.RejectionReason = item.Timestamp, item.Comments
+		}
+	}
+	c.Resubmissions = append(c.Resubmissions, resubmission)
+	c.ResubmissionCount = resubmission.Attempt
+
+	key, err := rejectionKey(ctx, c.ID)
+	if err != nil {
+		return nil, err
+	}
+	if err := ctx.GetStub().DelState(key); err != nil {
+		return nil, fmt.Errorf("failed to remove rejection of case %s: %v", c.ID, err)
+	}
+	return &c.Resubmissions[len(c.Resubmissions)-1], nil
+}
+
+// DiffCases lists the fields that differ between two cases once both are normalized,
+// leaving out the ones provenance diffs skip and those in skip
+func DiffCases(before *Case, after *Case, skip map[string]bool) ([]FieldChange, error) {
+	values := make([]map[string]interface{}, 2)
+	for i, c := range []*Case{before, after} {
+		data, err := json.Marshal(c)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal case: %v", err)
+		}
+		normalized, err := DecodeCase(data)
+		if err != nil {
+			return nil, err
+		}
+		if data, err = json.Marshal(normalized); err != nil {
+			return nil, fmt.Errorf("failed to marshal case: %v", err)
+		}
+		if err := json.Unmarshal(data, &values[i]); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal case: %v", err)
+		}
+		for field := range values[i] {
+			if provenanceSkipped[field] || skip[field] {
+				delete(values[i], field)
+			}
+		}
+	}
+	changes := make([]FieldChange, 0)
+	diffValues("", values[0], values[1], &changes)
+	return changes, nil
+}
@@ -0,0 +1,86 @@
+package casemodel
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+func TestDiffCases(t *testing.T) {
+	before := &Case{ID: "CASE_001", Title: "Land dispute", Status: StatusRejectedByRegistrar, Documents: []Document{{ID: "DOC_1", Hash: "abc"}}, HashChain: []HashLink{{TxID: "tx1"}}}
+	after := &Case{ID: "CASE_001", Title: "Sharma vs Patel", Status: StatusPendingRegistrarReview, Documents: []Document{{ID: "DOC_1", Hash: "abc"}, {ID: "DOC_2", Hash: "def"}}, HashChain: []HashLink{{TxID: "tx1"}, {TxID: "tx2"}}}
+
+	changes, err := DiffCases(before, after, map[string]bool{"status": true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The hash chain and the skipped status are left out
+	if len(changes) != 2 || changes[0].Field != "documents[1]" || changes[0].Before != "" || changes[1] != (FieldChange{Field: "title", Before: `"Land dispute"`, After: `"Sharma vs Patel"`}) {
+		t.Errorf("changes = %+v", changes)
+	}
+}
+
+func TestResubmit(t *testing.T) {
+	n := privateNetwork()
+	transact := func(f func(ctx contractapi.TransactionContextInterface) error) error {
+		return inTransaction(t, n, "lawyer", lawyer, "", f)
+	}
+	rejected := &Case{
+		ID:           "CASE_001",
+		Description:  "Land dispute",
+		Status:       StatusRejectedByRegistrar,
+		CurrentOrg:   OrgLawyers,
+		LastModified: "2024-05-14T10:00:05Z",
+		History: []HistoryItem{
+			{Status: "SUBMITTED_TO_REGISTRAR", Organization: OrgLawyers, Timestamp: "2024-05-14T09:00:00Z"},
+			{Status: StatusRejectedByRegistrar, Organization: OrgRegistrars, Timestamp: "2024-05-14T10:00:00Z", Comments: "Cause of action not stated"},
+		},
+	}
+
+	resubmit := func(c *Case) (*Resubmission, error) {
+		var resubmission *Resubmission
+		err := transact(func(ctx contractapi.TransactionContextInterface) error {
+			var err error
+			resubmission, err = Resubmit(ctx, c, "2024-05-15T09:00:00Z")
+			return err
+		})
+		return resubmission, err
+	}
+	c := *rejected
+	if _, err := resubmit(&c); err == nil || err.Error() != "case CASE_001 has no registrar rejection to resubmit" {
+		t.Errorf("Resubmit without a rejection = %v", err)
+	}
+	if err := transact(func(ctx contractapi.TransactionContextInterface) error { return RecordRejection(ctx, rejected) }); err != nil {
+		t.Fatal(err)
+	}
+
+	// Only the workflow fields have moved on
+	c.Status, c.CurrentOrg, c.LastModified = StatusPendingRegistrarReview, OrgRegistrars, "2024-05-15T09:00:00Z"
+	c.History = append(c.History, HistoryItem{Status: "RESUBMITTED_TO_REGISTRAR"})
+	if _, err := resubmit(&c); err == nil || err.Error() != "case CASE_001 has not changed since the registrar rejected it" {
+		t.Errorf("Resubmit unchanged = %v", err)
+	}
+
+	c.Description = "Land dispute over survey no. 42"
+	resubmission, err := resubmit(&c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resubmission.Attempt != 1 || resubmission.RejectedAt != "2024-05-14T10:00:00Z" || resubmission.RejectionReason != "Cause of action not stated" || resubmission.ResubmittedAt != "2024-05-15T09:00:00Z" {
+		t.Errorf("resubmission = %+v", resubmission)
+	}
+	if len(resubmission.Changes) != 1 || resubmission.Changes[0] != (FieldChange{Field: "description", Before: `"Land dispute"`, After: `"Land dispute over survey no. 42"`}) {
+		t.Errorf("changes = %+v", resubmission.Changes)
+	}
+	if c.ResubmissionCount != 1 || len(c.Resubmissions) != 1 {
+		t.Errorf("case = %+v", c)
+	}
+
+	// The rejection is used up
+	if _, err := resubmit(&c); err == nil || err.Error() != "case CASE_001 has no registrar rejection to resubmit" {
+		t.Errorf("second Resubmit = %v", err)
+	}
+	if err := transact(func(ctx contractapi.TransactionContextInterface) error { return RecordRejection(ctx, &c) }); err == nil || err.Error() != "case CASE_001 is PENDING_REGISTRAR_REVIEW, not REJECTED_BY_REGISTRAR" {
+		t.Errorf("RecordRejection of a pending case = %v", err)
+	}
+}
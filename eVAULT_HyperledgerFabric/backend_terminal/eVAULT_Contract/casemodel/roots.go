@@ -0,0 +1,195 @@
+package casemodel
+
+import (
+	"bytes"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// orgRootsType is the composite key object type the root certificates trusted for each
+// organization are stored under, so that they never show up as cases
+const orgRootsType = "orgRoots"
+
+// Organizations returns the organizations that take part in the case lifecycle
+func Organizations() []string {
+	return []string{OrgLawyers, OrgRegistrars, OrgStampReporters, OrgBenchClerks, OrgJudges}
+}
+
+// PutOrgRoots stores the PEM root CA certificates of org's MSP on this channel, replacing
+// those stored before. Certificates they issued are trusted as identities of org, see
+// VerifyOrgCertificate. Only a member of org may store its roots, so that no organization
+// can vouch for another's identities; see ImportOrgRoots for the channels org has not
+// joined.
+func PutOrgRoots(ctx contractapi.TransactionContextInterface, org string, certificates string) error {
+	if err := checkOrg(org); err != nil {
+		return err
+	}
+	caller, err := ClientOrg(ctx)
+	if err != nil {
+		return err
+	}
+	if caller != org {
+		return fmt.Errorf("a member of %s cannot set the root certificates of %s", caller, org)
+	}
+	return putOrgRoots(ctx, org, certificates)
+}
+
+// ImportOrgRoots copies the root CA certificates trusted for org from the chaincode
+// routed for hop, through its GetOrgRoots transaction. An organization that has not
+// joined this channel cannot store its roots here, so they are taken from a channel where
+// its own administrator stored them.
+func ImportOrgRoots(ctx contractapi.TransactionContextInterface, org string, hop string) error {
+	if err := checkOrg(org); err != nil {
+		return err
+	}
+	response := InvokeHop(ctx, hop, [][]byte{[]byte("GetOrgRoots"), []byte(org)})
+	if response.Status != shim.OK {
+		return fmt.Errorf("failed to read root certificates of %s through %s: %s", org, hop, response.Message)
+	}
+	return putOrgRoots(ctx, org, string(response.Payload))
+}
+
+// checkOrg returns an error unless org takes part in the case lifecycle
+func checkOrg(org string) error {
+	for _, o := range Organizations() {
+		if o == org {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown organization %q", org)
+}
+
+// putOrgRoots checks that certificates are self-signed CA certificates and stores them as
+// the roots of org
+func putOrgRoots(ctx contractapi.TransactionContextInterface, org string, certificates string) error {
+	roots, err := parseRoots([]byte(certificates))
+	if err != nil {
+		return fmt.Errorf("root certificates of %s: %v", org, err)
+	}
+	var bundle []byte
+	for _, root := range roots {
+		if !root.IsCA {
+			return fmt.Errorf("root certificates of %s: %s is not a CA certificate", org, root.Subject)
+		}
+		if !bytes.Equal(root.RawIssuer, root.RawSubject) || root.CheckSignatureFrom(root) != nil {
+			return fmt.Errorf("root certificates of %s: %s is not self-signed, intermediate CAs are not supported", org, root.Subject)
+		}
+		bundle = append(bundle, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: root.Raw})...)
+	}
+	key, err := orgRootsKey(ctx, org)
+	if err != nil {
+		return err
+	}
+	if err := ctx.GetStub().PutState(key, bundle); err != nil {
+		return fmt.Errorf("failed to store root certificates of %s: %v", org, err)
+	}
+	return nil
+}
+
+// GetOrgRoots returns the PEM root CA certificates trusted for org on this channel, or an
+// empty string when none are
+func GetOrgRoots(ctx contractapi.TransactionContextInterface, org string) (string, error) {
+	key, err := orgRootsKey(ctx, org)
+	if err != nil {
+		return "", err
+	}
+	bundle, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return "", fmt.Errorf("failed to read root certificates of %s: %v", org, err)
+	}
+	return string(bundle), nil
+}
+
+// orgRootPool returns the root CAs trusted for org on this channel, or nil when none are
+func orgRootPool(ctx contractapi.TransactionContextInterface, org string) (*x509.CertPool, error) {
+	bundle, err := GetOrgRoots(ctx, org)
+	if err != nil || bundle == "" {
+		return nil, err
+	}
+	roots, err := parseRoots([]byte(bundle))
+	if err != nil {
+		return nil, fmt.Errorf("stored root certificates of %s: %v", org, err)
+	}
+	pool := x509.NewCertPool()
+	for _, root := range roots {
+		pool.AddCert(root)
+	}
+	return pool, nil
+}
+
+// VerifyOrgCertificate checks that cert was issued by one of the root CAs trusted for org
+// on this channel and was valid at the given time
+func VerifyOrgCertificate(ctx contractapi.TransactionContextInterface, org string, cert *x509.Certificate, at time.Time) error {
+	pool, err := orgRootPool(ctx, org)
+	if err != nil {
+		return err
+	}
+	return verifyChain(pool, org, cert, at)
+}
+
+// verifyChain checks that cert was issued directly by one of the roots in pool, which
+// holds the root CAs of org. Intermediate CAs are not supported: a certificate an
+// intermediate CA issued is rejected, as is a CA certificate presented as a client's.
+func verifyChain(pool *x509.CertPool, org string, cert *x509.Certificate, at time.Time) error {
+	if pool == nil {
+		return fmt.Errorf("no root certificates are trusted for %s on this channel", org)
+	}
+	if cert.IsCA {
+		return fmt.Errorf("certificate %s is a CA certificate, not a client of %s", cert.Subject, org)
+	}
+	opts := x509.VerifyOptions{Roots: pool, CurrentTime: at, KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageAny}}
+	chains, err := cert.Verify(opts)
+	if err != nil {
+		return fmt.Errorf("certificate %s was not issued by %s: %v", cert.Subject, org, err)
+	}
+	for _, chain := range chains {
+		if len(chain) == 2 {
+			return nil
+		}
+	}
+	return fmt.Errorf("certificate %s was not issued directly by a root CA of %s", cert.Subject, org)
+}
+
+// parseRoots parses a bundle of PEM certificates, which must hold at least one
+func parseRoots(bundle []byte) ([]*x509.Certificate, error) {
+	var roots []*x509.Certificate
+	for {
+		var block *pem.Block
+		block, bundle = pem.Decode(bundle)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			return nil, fmt.Errorf("unexpected PEM block %s", block.Type)
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse certificate: %v", err)
+		}
+		roots = append(roots, cert)
+	}
+	if len(roots) == 0 {
+		return nil, fmt.Errorf("no PEM encoded CERTIFICATE found")
+	}
+	return roots, nil
+}
+
+// OrgRootsKey returns the world state key the root certificates trusted for org are
+// stored under
+func OrgRootsKey(org string) string {
+	key, _ := shim.CreateCompositeKey(orgRootsType, []string{org})
+	return key
+}
+
+func orgRootsKey(ctx contractapi.TransactionContextInterface, org string) (string, error) {
+	key, err := ctx.GetStub().CreateCompositeKey(orgRootsType, []string{org})
+	if err != nil {
+		return "", fmt.Errorf("failed to create root certificates key: %v", err)
+	}
+	return key, nil
+}
@@ -0,0 +1,205 @@
+package casemodel
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/hyperledger/fabric-protos-go/peer"
+
+	"casemodel/mockstub"
+)
+
+func TestPutOrgRoots(t *testing.T) {
+	n := mockstub.NewNetwork(time.Date(2024, time.May, 15, 10, 0, 0, 0, time.UTC))
+	root := mockstub.RootCertificate("StampReportersOrgMSP")
+	member := mockstub.Identity{MSPID: "StampReportersOrgMSP", Name: "stampreporter1"}
+	forged := mockstub.Identity{MSPID: "StampReportersOrgMSP", Name: "stampreporter1", SelfSigned: true}
+	certificate := func(id mockstub.Identity) *x509.Certificate {
+		var cert *x509.Certificate
+		err := inTransaction(t, n, "stampreporter", id, "", func(ctx contractapi.TransactionContextInterface) error {
+			var err error
+			cert, err = ctx.GetClientIdentity().GetX509Certificate()
+			return err
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return cert
+	}
+	leaf := certificate(member)
+
+	for _, tt := range []struct {
+		name, org, certificates, wantErr string
+	}{
+		{"unknown organization", "CourtsOrg", root, `unknown organization "CourtsOrg"`},
+		{"another organization", OrgLawyers, mockstub.RootCertificate("LawyersOrgMSP"), "a member of StampReportersOrg cannot set the root certificates of LawyersOrg"},
+		{"not PEM", OrgStampReporters, "MIIB", "root certificates of StampReportersOrg: no PEM encoded CERTIFICATE found"},
+		{"not a CA", OrgStampReporters, string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: leaf.Raw})), "CN=stampreporter1,OU=client is not a CA certificate"},
+	} {
+		err := inTransaction(t, n, "stampreporter", member, "", func(ctx contractapi.TransactionContextInterface) error {
+			return PutOrgRoots(ctx, tt.org, tt.certificates)
+		})
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("%s: err = %v, want %q", tt.name, err, tt.wantErr)
+		}
+	}
+
+	err := inTransaction(t, n, "stampreporter", member, "", func(ctx contractapi.TransactionContextInterface) error {
+		if err := VerifyOrgCertificate(ctx, OrgStampReporters, leaf, leaf.NotBefore); err == nil || err.Error() != "no root certificates are trusted for StampReportersOrg on this channel" {
+			t.Errorf("before roots are stored: err = %v", err)
+		}
+		return PutOrgRoots(ctx, OrgStampReporters, root)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	at := time.Date(2024, time.May, 15, 10, 0, 0, 0, time.UTC)
+	for _, tt := range []struct {
+		name    string
+		org     string
+		cert    *x509.Certificate
+		at      time.Time
+		wantErr string
+	}{
+		{"member", OrgStampReporters, leaf, at, ""},
+		{"self-signed", OrgStampReporters, certificate(forged), at, "was not issued by StampReportersOrg"},
+		{"member of another organization", OrgStampReporters, certificate(mockstub.Identity{MSPID: "LawyersOrgMSP", Name: "lawyer1"}), at, "was not issued by StampReportersOrg"},
+		{"before the certificate was issued", OrgStampReporters, leaf, time.Date(2019, time.January, 1, 0, 0, 0, 0, time.UTC), "was not issued by StampReportersOrg"},
+		{"organization without roots", OrgLawyers, leaf, at, "no root certificates are trusted for LawyersOrg on this channel"},
+	} {
+		err := inTransaction(t, n, "stampreporter", member, "", func(ctx contractapi.TransactionContextInterface) error {
+			return VerifyOrgCertificate(ctx, tt.org, tt.cert, tt.at)
+		})
+		if (tt.wantErr == "") != (err == nil) || (err != nil && !strings.Contains(err.Error(), tt.wantErr)) {
+			t.Errorf("%s: err = %v, want %q", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+// issue returns a certificate for name signed by parent's key, or self-signed when parent
+// is nil, with its key
+func issue(t *testing.T, name string, isCA bool, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (*x509.Certificate, *ecdsa.PrivateKey) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC),
+		NotAfter:              time.Date(2100, time.January, 1, 0, 0, 0, 0, time.UTC),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  isCA,
+	}
+	if parent == nil {
+		parent, parentKey = template, key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert, key
+}
+
+func TestIntermediateCAs(t *testing.T) {
+	root, rootKey := issue(t, "ca.judges", true, nil, nil)
+	intermediate, intermediateKey := issue(t, "ica.judges", true, root, rootKey)
+	direct, _ := issue(t, "judge1", false, root, rootKey)
+	indirect, _ := issue(t, "judge2", false, intermediate, intermediateKey)
+	encode := func(certs ...*x509.Certificate) string {
+		var bundle []byte
+		for _, cert := range certs {
+			bundle = append(bundle, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw})...)
+		}
+		return string(bundle)
+	}
+
+	n := mockstub.NewNetwork(time.Date(2024, time.May, 15, 10, 0, 0, 0, time.UTC))
+	admin := mockstub.Identity{MSPID: "JudgesOrgMSP", Name: "judgeadmin"}
+	err := inTransaction(t, n, "judge", admin, "", func(ctx contractapi.TransactionContextInterface) error {
+		return PutOrgRoots(ctx, OrgJudges, encode(root, intermediate))
+	})
+	if err == nil || err.Error() != "root certificates of JudgesOrg: CN=ica.judges is not self-signed, intermediate CAs are not supported" {
+		t.Errorf("storing an intermediate CA: err = %v", err)
+	}
+
+	pool := x509.NewCertPool()
+	pool.AddCert(root)
+	at := time.Date(2024, time.May, 15, 10, 0, 0, 0, time.UTC)
+	for _, tt := range []struct {
+		name    string
+		cert    *x509.Certificate
+		wantErr string
+	}{
+		{"issued by the root", direct, ""},
+		{"issued by an intermediate", indirect, "certificate CN=judge2 was not issued by JudgesOrg"},
+		{"the intermediate itself", intermediate, "certificate CN=ica.judges is a CA certificate, not a client of JudgesOrg"},
+		{"the root itself", root, "certificate CN=ca.judges is a CA certificate, not a client of JudgesOrg"},
+	} {
+		err := verifyChain(pool, OrgJudges, tt.cert, at)
+		if (tt.wantErr == "") != (err == nil) || (err != nil && !strings.Contains(err.Error(), tt.wantErr)) {
+			t.Errorf("%s: err = %v, want %q", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestImportOrgRoots(t *testing.T) {
+	n := mockstub.NewNetwork(time.Date(2024, time.May, 15, 10, 0, 0, 0, time.UTC))
+	root := mockstub.RootCertificate("RegistrarsOrgMSP")
+	// the registrar's chaincode on the channel the registrars joined holds their roots
+	n.Install("registrar", mockstub.ChaincodeFunc(func(stub shim.ChaincodeStubInterface) peer.Response {
+		function, args := stub.GetFunctionAndParameters()
+		if function != "GetOrgRoots" || stub.GetChannelID() != ChannelRegistrarStampReporter {
+			return shim.Error("unexpected call to " + function + " on " + stub.GetChannelID())
+		}
+		if args[0] != OrgRegistrars {
+			return shim.Success(nil)
+		}
+		return shim.Success([]byte(root))
+	}))
+	routing, _ := json.Marshal(DefaultRouting())
+	n.PutState(ChannelLawyerRegistrar, "stampreporter", RoutingKey, routing)
+	reporter := mockstub.Identity{MSPID: "StampReportersOrgMSP", Name: "stampreporteradmin"}
+	importRoots := func(org string, hop string) error {
+		return inTransaction(t, n, "stampreporter", reporter, "", func(ctx contractapi.TransactionContextInterface) error {
+			return ImportOrgRoots(ctx, org, hop)
+		})
+	}
+
+	for _, tt := range []struct {
+		name, org, hop, wantErr string
+	}{
+		{"unknown organization", "CourtsOrg", HopStampReporterToRegistrar, `unknown organization "CourtsOrg"`},
+		{"unrouted hop", OrgRegistrars, "stampreporter->court", "failed to read root certificates of RegistrarsOrg through stampreporter->court: route stampreporter->court is not configured"},
+		{"no roots stored there", OrgJudges, HopStampReporterToRegistrar, "root certificates of JudgesOrg: no PEM encoded CERTIFICATE found"},
+	} {
+		if err := importRoots(tt.org, tt.hop); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("%s: err = %v, want %q", tt.name, err, tt.wantErr)
+		}
+	}
+
+	if err := importRoots(OrgRegistrars, HopStampReporterToRegistrar); err != nil {
+		t.Fatal(err)
+	}
+	if got := string(n.GetState(ChannelLawyerRegistrar, "stampreporter", OrgRootsKey(OrgRegistrars))); got != root {
+		t.Errorf("imported roots = %q", got)
+	}
+}
@@ -0,0 +1,175 @@
+package casemodel
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/hyperledger/fabric-protos-go/peer"
+)
+
+// Channels of the eVAULT network, as configured in MICROFAB.txt
+const (
+	ChannelLawyerRegistrar         = "lawyer-registrar-channel"
+	ChannelRegistrarStampReporter  = "registrar-stampreporter-channel"
+	ChannelStampReporterLawyer     = "stampreporter-lawyer-channel"
+	ChannelStampReporterBenchClerk = "stampreporter-benchclerk-channel"
+	ChannelBenchClerkJudge         = "benchclerk-judge-channel"
+	ChannelBenchClerkLawyer        = "benchclerk-lawyer-channel"
+)
+
+// Hops are the calls one contract makes to another. The registrar also reads and updates
+// its own copy of a case on the channel it shares with the lawyer or the stamp reporter.
+const (
+	HopLawyerToRegistrar               = "lawyer->registrar"
+	HopLawyerToStampReporter           = "lawyer->stampreporter"
+	HopLawyerToBenchClerk              = "lawyer->benchclerk"
+	HopRegistrarToLawyerChannel        = "registrar->registrar@lawyer"
+	HopRegistrarToStampReporterChannel = "registrar->registrar@stampreporter"
+	HopRegistrarToStampReporter        = "registrar->stampreporter"
+	HopRegistrarToLawyer               = "registrar->lawyer"
+	HopStampReporterToRegistrar        = "stampreporter->registrar"
+	HopStampReporterToLawyer           = "stampreporter->lawyer"
+	HopStampReporterToBenchClerk       = "stampreporter->benchclerk"
+	HopBenchClerkToStampReporter       = "benchclerk->stampreporter"
+	HopBenchClerkToJudge               = "benchclerk->judge"
+	HopBenchClerkToLawyer              = "benchclerk->lawyer"
+	HopJudgeToBenchClerk               = "judge->benchclerk"
+)
+
+// routingConfigType is the composite key object type the routing table is stored under.
+// Composite keys are skipped by range queries, so the table never shows up as a case.
+const routingConfigType = "config"
+
+// RoutingKey is the world state key the routing table is stored under
+var RoutingKey, _ = shim.CreateCompositeKey(routingConfigType, []string{"routing"})
+
+// Route is the chaincode and channel a hop invokes. An empty channel is the channel the
+// calling transaction runs on.
+type Route struct {
+	Chaincode string `json:"chaincode"`
+	Channel   string `json:"channel" metadata:",optional"`
+}
+
+// RoutingConfig maps each hop to the route it takes
+type RoutingConfig struct {
+	Routes map[string]Route `json:"routes"`
+}
+
+// DefaultRouting returns the routes of the Microfab network, which InitRouting stores
+// when it is not given a routing table
+func DefaultRouting() *RoutingConfig {
+	return &RoutingConfig{Routes: map[string]Route{
+		HopLawyerToRegistrar:               {"registrar", ChannelLawyerRegistrar},
+		HopLawyerToStampReporter:           {"stampreporter", ChannelStampReporterLawyer},
+		HopLawyerToBenchClerk:              {"benchclerk", ChannelBenchClerkLawyer},
+		HopRegistrarToLawyerChannel:        {"registrar", ChannelLawyerRegistrar},
+		HopRegistrarToStampReporterChannel: {"registrar", ChannelRegistrarStampReporter},
+		HopRegistrarToStampReporter:        {"stampreporter", ChannelRegistrarStampReporter},
+		HopRegistrarToLawyer:               {"lawyer", ChannelLawyerRegistrar},
+		HopStampReporterToRegistrar:        {"registrar", ChannelRegistrarStampReporter},
+		HopStampReporterToLawyer:           {"lawyer", ChannelStampReporterLawyer},
+		HopStampReporterToBenchClerk:       {"benchclerk", ChannelStampReporterBenchClerk},
+		HopBenchClerkToStampReporter:       {"stampreporter", ChannelStampReporterBenchClerk},
+		HopBenchClerkToJudge:               {"judge", ChannelBenchClerkJudge},
+		HopBenchClerkToLawyer:              {"lawyer", ChannelBenchClerkLawyer},
+		HopJudgeToBenchClerk:               {"benchclerk", ChannelBenchClerkJudge},
+	}}
+}
+
+// Validate checks that the table only names known hops, that every route names a
+// chaincode and that each of the required hops has a route
+func (r *RoutingConfig) Validate(required ...string) error {
+	known := DefaultRouting().Routes
+	hops := make([]string, 0, len(r.Routes))
+	for hop := range r.Routes {
+		hops = append(hops, hop)
+	}
+	sort.Strings(hops)
+	for _, hop := range hops {
+		if _, ok := known[hop]; !ok {
+			return fmt.Errorf("unknown route %s", hop)
+		}
+		if r.Routes[hop].Chaincode == "" {
+			return fmt.Errorf("route %s has no chaincode", hop)
+		}
+	}
+	for _, hop := range required {
+		if _, ok := r.Routes[hop]; !ok {
+			return fmt.Errorf("route %s is not configured", hop)
+		}
+	}
+	return nil
+}
+
+// ParseRoutingConfig decodes a routing table and validates it against the required hops
+func ParseRoutingConfig(data []byte, required ...string) (*RoutingConfig, error) {
+	var config RoutingConfig
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal routing config: %v", err)
+	}
+	if err := config.Validate(required...); err != nil {
+		return nil, fmt.Errorf("invalid routing config: %v", err)
+	}
+	return &config, nil
+}
+
+// GetRouting returns the routing table stored on the ledger. A contract whose ledger has
+// no routing table has not been initialized, and routes nothing.
+func GetRouting(ctx contractapi.TransactionContextInterface) (*RoutingConfig, error) {
+	data, err := ctx.GetStub().GetState(RoutingKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read routing config: %v", err)
+	}
+	if data == nil {
+		return nil, fmt.Errorf("routing is not configured on channel %s, run InitLedger", ctx.GetStub().GetChannelID())
+	}
+	return ParseRoutingConfig(data)
+}
+
+// InitRouting stores the routing table a contract is initialized with, or DefaultRouting
+// when configJSON is empty. The table is validated against the hops the contract uses,
+// so that a contract missing a route fails to initialize rather than on its first call.
+func InitRouting(ctx contractapi.TransactionContextInterface, configJSON string, required ...string) error {
+	if configJSON == "" {
+		data, err := json.Marshal(DefaultRouting())
+		if err != nil {
+			return fmt.Errorf("failed to marshal routing config: %v", err)
+		}
+		configJSON = string(data)
+	}
+	return PutRouting(ctx, configJSON, required...)
+}
+
+// PutRouting validates a routing table against the hops the contract uses and stores it
+// on the ledger, replacing the one stored before
+func PutRouting(ctx contractapi.TransactionContextInterface, configJSON string, required ...string) error {
+	config, err := ParseRoutingConfig([]byte(configJSON), required...)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(config)
+	if err != nil {
+		return fmt.Errorf("failed to marshal routing config: %v", err)
+	}
+	if err := ctx.GetStub().PutState(RoutingKey, data); err != nil {
+		return fmt.Errorf("failed to store routing config: %v", err)
+	}
+	return nil
+}
+
+// InvokeHop invokes the chaincode routed for hop. A hop that cannot be routed returns an
+// error response, so callers handle it like a failed invocation.
+func InvokeHop(ctx contractapi.TransactionContextInterface, hop string, args [][]byte) peer.Response {
+	config, err := GetRouting(ctx)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	route, ok := config.Routes[hop]
+	if !ok {
+		return shim.Error(fmt.Sprintf("route %s is not configured", hop))
+	}
+	return ctx.GetStub().InvokeChaincode(route.Chaincode, args, route.Channel)
+}
@@ -0,0 +1,127 @@
+package casemodel
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/hyperledger/fabric-protos-go/peer"
+)
+
+// routingStub keeps state in a map and records the chaincode invocations made through it
+type routingStub struct {
+	shim.ChaincodeStubInterface
+	state   map[string][]byte
+	invoked []string
+}
+
+func (r *routingStub) GetChannelID() string                { return "benchclerk-judge-channel" }
+func (r *routingStub) GetState(key string) ([]byte, error) { return r.state[key], nil }
+func (r *routingStub) PutState(key string, value []byte) error {
+	r.state[key] = value
+	return nil
+}
+func (r *routingStub) InvokeChaincode(name string, args [][]byte, channel string) peer.Response {
+	r.invoked = append(r.invoked, name+"@"+channel)
+	return shim.Success(nil)
+}
+
+func routingContext() (*contractapi.TransactionContext, *routingStub) {
+	stub := &routingStub{state: make(map[string][]byte)}
+	ctx := new(contractapi.TransactionContext)
+	ctx.SetStub(stub)
+	return ctx, stub
+}
+
+func TestDefaultRoutingIsValid(t *testing.T) {
+	config := DefaultRouting()
+	hops := make([]string, 0, len(config.Routes))
+	for hop := range config.Routes {
+		hops = append(hops, hop)
+	}
+	if err := config.Validate(hops...); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestParseRoutingConfigErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   string
+		required []string
+		wantErr  string
+	}{
+		{"malformed", `{"routes":`, nil, "failed to unmarshal routing config"},
+		{"unknown hop", `{"routes":{"lawyer->judge":{"chaincode":"judge","channel":"c"}}}`, nil, "unknown route lawyer->judge"},
+		{"no chaincode", `{"routes":{"judge->benchclerk":{"channel":"c"}}}`, nil, "route judge->benchclerk has no chaincode"},
+		{"missing hop", `{"routes":{"judge->benchclerk":{"chaincode":"benchclerk"}}}`, []string{HopJudgeToBenchClerk, HopBenchClerkToJudge}, "route benchclerk->judge is not configured"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseRoutingConfig([]byte(tt.config), tt.required...)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("err = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestInitRouting(t *testing.T) {
+	ctx, _ := routingContext()
+
+	if _, err := GetRouting(ctx); err == nil || err.Error() != "routing is not configured on channel benchclerk-judge-channel, run InitLedger" {
+		t.Errorf("GetRouting before InitRouting = %v", err)
+	}
+	if response := InvokeHop(ctx, HopJudgeToBenchClerk, nil); response.Status == shim.OK {
+		t.Error("invoked a hop before InitRouting")
+	}
+
+	if err := InitRouting(ctx, "", HopJudgeToBenchClerk); err != nil {
+		t.Fatal(err)
+	}
+	config, err := GetRouting(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(config, DefaultRouting()) {
+		t.Errorf("config = %+v, want the defaults", config)
+	}
+
+	if err := InitRouting(ctx, `{"routes":{"judge->benchclerk":{"chaincode":"clerk"}}}`, HopJudgeToBenchClerk, HopBenchClerkToJudge); err == nil || err.Error() != "invalid routing config: route benchclerk->judge is not configured" {
+		t.Errorf("InitRouting with a route missing = %v", err)
+	}
+}
+
+func TestStoredRoutingReplacesDefaults(t *testing.T) {
+	ctx, stub := routingContext()
+	if err := InitRouting(ctx, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := PutRouting(ctx, `{"routes":{"judge->benchclerk":{"chaincode":"clerk"}}}`, HopJudgeToBenchClerk); err != nil {
+		t.Fatal(err)
+	}
+	if InvokeHop(ctx, HopJudgeToBenchClerk, nil).Status != shim.OK {
+		t.Fatal("invocation failed")
+	}
+	if want := []string{"clerk@"}; !reflect.DeepEqual(stub.invoked, want) {
+		t.Errorf("invoked = %v, want %v", stub.invoked, want)
+	}
+
+	response := InvokeHop(ctx, HopBenchClerkToJudge, nil)
+	if response.Status == shim.OK || response.Message != "route benchclerk->judge is not configured" {
+		t.Errorf("response = %d %s", response.Status, response.Message)
+	}
+}
+
+func TestPutRoutingRejectsInvalidConfig(t *testing.T) {
+	ctx, stub := routingContext()
+	if err := PutRouting(ctx, `{"routes":{}}`, HopJudgeToBenchClerk); err == nil {
+		t.Fatal("expected an error")
+	}
+	if len(stub.state) != 0 {
+		t.Errorf("state = %v, want nothing stored", stub.state)
+	}
+}
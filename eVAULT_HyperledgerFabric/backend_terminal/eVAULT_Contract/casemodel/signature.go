@@ -0,0 +1,347 @@
+package casemodel
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// Algorithms documents may be signed with
+const (
+	SignatureECDSA   = "ECDSA"
+	SignatureEd25519 = "Ed25519"
+)
+
+// signingKeyType is the composite key object type registered signing keys are stored
+// under, so that they never show up as cases
+const signingKeyType = "signingKey"
+
+// SigningKey is a public key a stamp reporter registered to sign documents with, for
+// signing with a key other than the one their certificate was issued for
+type SigningKey struct {
+	Fingerprint  string `json:"fingerprint"` // hex SHA-256 of the DER public key
+	PublicKey    string `json:"publicKey"`   // PEM
+	Algorithm    string `json:"algorithm"`
+	Owner        string `json:"owner"` // client identity ID of the registrant, the only one who may sign with it
+	RegisteredAt string `json:"registeredAt"`
+}
+
+// SignatureCheck is the result of rechecking one entry of a document's signature history
+type SignatureCheck struct {
+	DocumentID        string `json:"documentId"`
+	Index             int    `json:"index"` // position in the document's signature history
+	StampReporterID   string `json:"stampReporterId"`
+	SignerFingerprint string `json:"signerFingerprint" metadata:",optional"`
+	Valid             bool   `json:"valid"`
+	Reason            string `json:"reason" metadata:",optional"` // why the signature does not verify
+}
+
+// SignatureVerification is the result of rechecking every signature on a case's documents
+type SignatureVerification struct {
+	CaseID     string           `json:"caseId"`
+	Valid      bool             `json:"valid"` // every signature verifies
+	Signatures []SignatureCheck `json:"signatures"`
+}
+
+// RegisterSigningKey registers a PEM public key for the submitting identity to sign
+// documents with. Registering a key again returns it as it was registered.
+func RegisterSigningKey(ctx contractapi.TransactionContextInterface, publicKey string) (*SigningKey, error) {
+	block, _ := pem.Decode([]byte(publicKey))
+	if block == nil || block.Type != "PUBLIC KEY" {
+		return nil, fmt.Errorf("signing key must be a PEM encoded PUBLIC KEY")
+	}
+	pub, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse signing key: %v", err)
+	}
+	algorithm, err := keyAlgorithm(pub)
+	if err != nil {
+		return nil, err
+	}
+	owner, err := ctx.GetClientIdentity().GetID()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get client identity: %v", err)
+	}
+
+	sum := sha256.Sum256(block.Bytes)
+	fingerprint := hex.EncodeToString(sum[:])
+	existing, err := readSigningKey(ctx, fingerprint)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		if existing.Owner != owner {
+			return nil, fmt.Errorf("signing key %s is registered to another identity", fingerprint)
+		}
+		return existing, nil
+	}
+
+	txTime, err := TxTime(ctx)
+	if err != nil {
+		return nil, err
+	}
+	key := &SigningKey{
+		Fingerprint:  fingerprint,
+		PublicKey:    string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: block.Bytes})),
+		Algorithm:    algorithm,
+		Owner:        owner,
+		RegisteredAt: txTime.Format(time.RFC3339),
+	}
+	value, err := json.Marshal(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal signing key: %v", err)
+	}
+	storageKey, err := signingKeyKey(ctx, fingerprint)
+	if err != nil {
+		return nil, err
+	}
+	if err := ctx.GetStub().PutState(storageKey, value); err != nil {
+		return nil, fmt.Errorf("failed to store signing key: %v", err)
+	}
+	return key, nil
+}
+
+// SignDocument checks a stamp reporter's signature over the hash doc anchors, see
+// Document.Anchor, and adds it to doc's signature history, marking doc validated.
+// sig.SignatureHash holds the base64 signature. It is checked against the registered key with fingerprint keyFingerprint,
+// which must belong to the submitting identity, or against the key of the submitting
+// identity's certificate when keyFingerprint is empty, in which case the certificate is
+// recorded with the signature.
+func SignDocument(ctx contractapi.TransactionContextInterface, doc *Document, sig DocumentSignature, keyFingerprint string) error {
+	if doc.Anchor() == "" {
+		return fmt.Errorf("document %s was uploaded without a content hash and cannot be signed", doc.ID)
+	}
+
+	var pub crypto.PublicKey
+	if keyFingerprint == "" {
+		cert, err := ctx.GetClientIdentity().GetX509Certificate()
+		if err != nil || cert == nil {
+			return fmt.Errorf("failed to read client certificate: %v", err)
+		}
+		sum := sha256.Sum256(cert.Raw)
+		pub, sig.SignerFingerprint = cert.PublicKey, hex.EncodeToString(sum[:])
+		sig.Certificate = string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw}))
+	} else {
+		key, err := readSigningKey(ctx, keyFingerprint)
+		if err != nil {
+			return err
+		}
+		if key == nil {
+			return fmt.Errorf("signing key %s is not registered", keyFingerprint)
+		}
+		owner, err := ctx.GetClientIdentity().GetID()
+		if err != nil {
+			return fmt.Errorf("failed to get client identity: %v", err)
+		}
+		if key.Owner != owner {
+			return fmt.Errorf("signing key %s is registered to another identity", keyFingerprint)
+		}
+		if pub, err = parsePublicKey(key.PublicKey); err != nil {
+			return err
+		}
+		sig.SignerFingerprint, sig.Certificate = key.Fingerprint, ""
+	}
+
+	algorithm, err := verifySignature(pub, doc.Anchor(), sig.SignatureHash)
+	if err != nil {
+		return fmt.Errorf("signature on document %s is invalid: %v", doc.ID, err)
+	}
+	der, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		return fmt.Errorf("failed to marshal signer key: %v", err)
+	}
+	sig.Algorithm = algorithm
+	sig.PublicKey = string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+
+	doc.SignatureHistory = append(doc.SignatureHistory, sig)
+	doc.SignatureHash = sig.SignatureHash
+	doc.Validated = true
+	return nil
+}
+
+// VerifySignatures rechecks every signature in the signature history of c's documents.
+// The key is never taken from the signature itself: a signature made with a certificate
+// key is checked against that certificate, which must have been issued by a root CA
+// trusted for StampReportersOrg on this channel, see PutOrgRoots, and one made with a
+// registered key against the key registered on this channel under its fingerprint.
+// Signatures recorded before they were checked do not verify.
+func VerifySignatures(ctx contractapi.TransactionContextInterface, c *Case) (*SignatureVerification, error) {
+	result := &SignatureVerification{CaseID: c.ID, Valid: true, Signatures: make([]SignatureCheck, 0)}
+	var roots *x509.CertPool
+	rootsRead := false
+	for _, doc := range c.Documents {
+		for i, sig := range doc.SignatureHistory {
+			check := SignatureCheck{DocumentID: doc.ID, Index: i, StampReporterID: sig.StampReporterID, SignerFingerprint: sig.SignerFingerprint, Valid: true}
+			var pub crypto.PublicKey
+			var reason string
+			switch {
+			case sig.Certificate != "":
+				if !rootsRead {
+					var err error
+					if roots, err = orgRootPool(ctx, OrgStampReporters); err != nil {
+						return nil, err
+					}
+					rootsRead = true
+				}
+				pub, reason = signerCertificateKey(roots, sig)
+			case sig.SignerFingerprint != "":
+				key, err := readSigningKey(ctx, sig.SignerFingerprint)
+				if err != nil {
+					return nil, err
+				}
+				if key == nil {
+					reason = fmt.Sprintf("signing key %s is not registered on this channel", sig.SignerFingerprint)
+				} else if pub, err = parsePublicKey(key.PublicKey); err != nil {
+					reason = err.Error()
+				}
+			default:
+				reason = "signature was recorded without a signer to check it against"
+			}
+			if reason == "" {
+				if algorithm, err := verifySignature(pub, doc.Anchor(), sig.SignatureHash); err != nil {
+					reason = err.Error()
+				} else if algorithm != sig.Algorithm {
+					reason = fmt.Sprintf("signature is recorded as %s but was made with %s", sig.Algorithm, algorithm)
+				}
+			}
+			check.Valid, check.Reason = reason == "", reason
+			result.Valid = result.Valid && check.Valid
+			result.Signatures = append(result.Signatures, check)
+		}
+	}
+	return result, nil
+}
+
+// signerCertificateKey returns the key of the certificate sig was recorded with, or why
+// it cannot be trusted: the certificate must match the signer fingerprint and have been
+// issued by one of roots, the root CAs of StampReportersOrg, when the signature was made
+func signerCertificateKey(roots *x509.CertPool, sig DocumentSignature) (crypto.PublicKey, string) {
+	block, _ := pem.Decode([]byte(sig.Certificate))
+	if block == nil || block.Type != "CERTIFICATE" {
+		return nil, "signer certificate is not PEM encoded"
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, fmt.Sprintf("failed to parse signer certificate: %v", err)
+	}
+	if sum := sha256.Sum256(cert.Raw); hex.EncodeToString(sum[:]) != sig.SignerFingerprint {
+		return nil, "signer certificate does not match the signer fingerprint"
+	}
+	signedAt, err := time.Parse(time.RFC3339, sig.Timestamp)
+	if err != nil {
+		return nil, fmt.Sprintf("signature timestamp %q is not an RFC 3339 time", sig.Timestamp)
+	}
+	if err := verifyChain(roots, OrgStampReporters, cert, signedAt); err != nil {
+		return nil, err.Error()
+	}
+	return cert.PublicKey, ""
+}
+
+// VerifyDocumentSignatures reads a case from the world state and rechecks the signatures
+// on its documents
+func VerifyDocumentSignatures(ctx contractapi.TransactionContextInterface, caseID string) (*SignatureVerification, error) {
+	caseJSON, err := ctx.GetStub().GetState(caseID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read case: %v", err)
+	}
+	if caseJSON == nil {
+		return nil, fmt.Errorf("case does not exist: %s", caseID)
+	}
+	c, err := DecodeCase(caseJSON)
+	if err != nil {
+		return nil, err
+	}
+	return VerifySignatures(ctx, c)
+}
+
+// verifySignature checks a base64 signature over the SHA-256 digest a document's anchor
+// encodes, see Document.Anchor, and returns the algorithm it was made with
+func verifySignature(pub crypto.PublicKey, anchor string, signature string) (string, error) {
+	if !ValidContentHash(anchor) {
+		return "", fmt.Errorf("document has no content hash to check the signature against")
+	}
+	digest, _ := hex.DecodeString(anchor)
+	raw, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil || len(raw) == 0 {
+		return "", fmt.Errorf("signature is not base64")
+	}
+
+	algorithm, err := keyAlgorithm(pub)
+	if err != nil {
+		return "", err
+	}
+	valid := false
+	switch key := pub.(type) {
+	case *ecdsa.PublicKey:
+		valid = ecdsa.VerifyASN1(key, digest, raw)
+	case ed25519.PublicKey:
+		valid = ed25519.Verify(key, digest, raw)
+	}
+	if !valid {
+		return "", fmt.Errorf("%s signature does not match the content hash", algorithm)
+	}
+	return algorithm, nil
+}
+
+// keyAlgorithm returns the signature algorithm of a public key, which must be ECDSA or
+// Ed25519
+func keyAlgorithm(pub crypto.PublicKey) (string, error) {
+	switch pub.(type) {
+	case *ecdsa.PublicKey:
+		return SignatureECDSA, nil
+	case ed25519.PublicKey:
+		return SignatureEd25519, nil
+	}
+	return "", fmt.Errorf("%T keys are not supported, sign with ECDSA or Ed25519", pub)
+}
+
+// parsePublicKey parses a PEM public key
+func parsePublicKey(publicKey string) (crypto.PublicKey, error) {
+	block, _ := pem.Decode([]byte(publicKey))
+	if block == nil {
+		return nil, fmt.Errorf("public key is not PEM encoded")
+	}
+	pub, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse public key: %v", err)
+	}
+	return pub, nil
+}
+
+func signingKeyKey(ctx contractapi.TransactionContextInterface, fingerprint string) (string, error) {
+	key, err := ctx.GetStub().CreateCompositeKey(signingKeyType, []string{fingerprint})
+	if err != nil {
+		return "", fmt.Errorf("failed to create signing key key: %v", err)
+	}
+	return key, nil
+}
+
+// readSigningKey returns the registered key with the given fingerprint, or nil
+func readSigningKey(ctx contractapi.TransactionContextInterface, fingerprint string) (*SigningKey, error) {
+	storageKey, err := signingKeyKey(ctx, fingerprint)
+	if err != nil {
+		return nil, err
+	}
+	value, err := ctx.GetStub().GetState(storageKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read signing key: %v", err)
+	}
+	if value == nil {
+		return nil, nil
+	}
+	var key SigningKey
+	if err := json.Unmarshal(value, &key); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal signing key: %v", err)
+	}
+	return &key, nil
+}
@@ -0,0 +1,285 @@
+package casemodel
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+
+	"casemodel/mockstub"
+)
+
+// publicKeyPEM encodes a public key the way RegisterSigningKey takes it
+func publicKeyPEM(t *testing.T, pub interface{}) string {
+	t.Helper()
+	der, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+}
+
+func TestSignDocument(t *testing.T) {
+	n := mockstub.NewNetwork(time.Date(2024, time.May, 15, 10, 0, 0, 0, time.UTC))
+	certKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	otherKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	edPublic, edPrivate, _ := ed25519.GenerateKey(rand.Reader)
+	reporter := mockstub.Identity{MSPID: "StampReportersOrgMSP", Name: "stampreporter1", Key: certKey}
+	other := mockstub.Identity{MSPID: "StampReportersOrgMSP", Name: "stampreporter2", Key: otherKey}
+
+	plaint := ContentHash([]byte("%PDF-1.4 plaint"))
+	digest, _ := hex.DecodeString(plaint)
+	ecdsaSignature := func(key *ecdsa.PrivateKey) string {
+		sig, err := ecdsa.SignASN1(rand.Reader, key, digest)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return base64.StdEncoding.EncodeToString(sig)
+	}
+	edSignature := base64.StdEncoding.EncodeToString(ed25519.Sign(edPrivate, digest))
+
+	var registered *SigningKey
+	err := inTransaction(t, n, "stampreporter", reporter, "", func(ctx contractapi.TransactionContextInterface) error {
+		var err error
+		registered, err = RegisterSigningKey(ctx, publicKeyPEM(t, edPublic))
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if registered.Algorithm != SignatureEd25519 || !ValidContentHash(registered.Fingerprint) || registered.RegisteredAt != "2024-05-15T10:00:00Z" {
+		t.Errorf("registered key = %+v", registered)
+	}
+
+	sign := func(id mockstub.Identity, doc *Document, signature string, fingerprint string) error {
+		return inTransaction(t, n, "stampreporter", id, "", func(ctx contractapi.TransactionContextInterface) error {
+			return SignDocument(ctx, doc, DocumentSignature{SignatureHash: signature, StampReporterID: "SR001"}, fingerprint)
+		})
+	}
+	doc := &Document{ID: "DOC_1", ContentHash: plaint, SignatureHistory: make([]DocumentSignature, 0)}
+	if err := sign(reporter, doc, ecdsaSignature(certKey), ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := sign(reporter, doc, edSignature, registered.Fingerprint); err != nil {
+		t.Fatal(err)
+	}
+	if !doc.Validated || doc.SignatureHash != edSignature || len(doc.SignatureHistory) != 2 {
+		t.Fatalf("document = %+v", doc)
+	}
+	byCert, byKey := doc.SignatureHistory[0], doc.SignatureHistory[1]
+	if byCert.Algorithm != SignatureECDSA || !ValidContentHash(byCert.SignerFingerprint) || byCert.PublicKey != publicKeyPEM(t, &certKey.PublicKey) || byCert.StampReporterID != "SR001" || !strings.HasPrefix(byCert.Certificate, "-----BEGIN CERTIFICATE-----") {
+		t.Errorf("signature with the certificate key = %+v", byCert)
+	}
+	if byKey.Algorithm != SignatureEd25519 || byKey.SignerFingerprint != registered.Fingerprint || byKey.PublicKey != registered.PublicKey || byKey.Certificate != "" {
+		t.Errorf("signature with the registered key = %+v", byKey)
+	}
+
+	for _, tt := range []struct {
+		name        string
+		id          mockstub.Identity
+		doc         *Document
+		signature   string
+		fingerprint string
+		wantErr     string
+	}{
+		{"signed by another key", reporter, doc, ecdsaSignature(otherKey), "", "signature on document DOC_1 is invalid: ECDSA signature does not match the content hash"},
+		{"signed over another hash", reporter, &Document{ID: "DOC_2", ContentHash: ContentHash([]byte("other"))}, ecdsaSignature(certKey), "", "ECDSA signature does not match the content hash"},
+		{"registered key of another identity", other, doc, edSignature, registered.Fingerprint, "signing key " + registered.Fingerprint + " is registered to another identity"},
+		{"unregistered key", reporter, doc, edSignature, strings.Repeat("0", 64), "signing key " + strings.Repeat("0", 64) + " is not registered"},
+		{"not base64", reporter, doc, "sig-1", "", "signature is not base64"},
+		{"no content hash", reporter, &Document{ID: "DOC_3", Hash: "9f2c"}, edSignature, registered.Fingerprint, "document DOC_3 was uploaded without a content hash"},
+		{"signed over the content hash of a document anchoring its digest", reporter, &Document{ID: "DOC_4", ContentDigest: ContentDigest(plaint)}, edSignature, registered.Fingerprint, "Ed25519 signature does not match the content hash"},
+	} {
+		if err := sign(tt.id, tt.doc, tt.signature, tt.fingerprint); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("%s: err = %v, want %q", tt.name, err, tt.wantErr)
+		}
+	}
+	if len(doc.SignatureHistory) != 2 {
+		t.Errorf("rejected signatures were recorded: %+v", doc.SignatureHistory)
+	}
+
+	// A document uploaded since version 14 is signed over its content digest
+	anchored, _ := hex.DecodeString(ContentDigest(plaint))
+	digested := &Document{ID: "DOC_5", ContentDigest: ContentDigest(plaint)}
+	if err := sign(reporter, digested, base64.StdEncoding.EncodeToString(ed25519.Sign(edPrivate, anchored)), registered.Fingerprint); err != nil || !digested.Validated {
+		t.Errorf("signature over the content digest = %+v, %v", digested, err)
+	}
+}
+
+func TestRegisterSigningKey(t *testing.T) {
+	n := mockstub.NewNetwork(time.Date(2024, time.May, 15, 10, 0, 0, 0, time.UTC))
+	reporter := mockstub.Identity{MSPID: "StampReportersOrgMSP", Name: "stampreporter1"}
+	other := mockstub.Identity{MSPID: "StampReportersOrgMSP", Name: "stampreporter2"}
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 1024)
+
+	register := func(id mockstub.Identity, publicKey string) (*SigningKey, error) {
+		var key *SigningKey
+		err := inTransaction(t, n, "stampreporter", id, "", func(ctx contractapi.TransactionContextInterface) error {
+			var err error
+			key, err = RegisterSigningKey(ctx, publicKey)
+			return err
+		})
+		return key, err
+	}
+
+	first, err := register(reporter, publicKeyPEM(t, &ecKey.PublicKey))
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, err := register(reporter, publicKeyPEM(t, &ecKey.PublicKey))
+	if err != nil || *again != *first || first.Algorithm != SignatureECDSA {
+		t.Errorf("registering again = %+v, %v, want %+v", again, err, first)
+	}
+
+	for _, tt := range []struct {
+		name      string
+		id        mockstub.Identity
+		publicKey string
+		wantErr   string
+	}{
+		{"key of another identity", other, publicKeyPEM(t, &ecKey.PublicKey), "is registered to another identity"},
+		{"RSA key", reporter, publicKeyPEM(t, &rsaKey.PublicKey), "*rsa.PublicKey keys are not supported"},
+		{"not PEM", reporter, "MFkwEwYHKoZIzj0CAQYIKoZIzj0DAQcDQgAE", "signing key must be a PEM encoded PUBLIC KEY"},
+	} {
+		if _, err := register(tt.id, tt.publicKey); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("%s: err = %v, want %q", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestVerifySignatures(t *testing.T) {
+	n := mockstub.NewNetwork(time.Date(2024, time.May, 15, 10, 0, 0, 0, time.UTC))
+	certKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	attackerKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	edPublic, edPrivate, _ := ed25519.GenerateKey(rand.Reader)
+	reporter := mockstub.Identity{MSPID: "StampReportersOrgMSP", Name: "stampreporter1", Key: certKey}
+	selfSigned := mockstub.Identity{MSPID: "StampReportersOrgMSP", Name: "stampreporter1", Key: attackerKey, SelfSigned: true}
+	outsider := mockstub.Identity{MSPID: "LawyersOrgMSP", Name: "lawyer1", Key: attackerKey}
+
+	plaint := ContentHash([]byte("%PDF-1.4 plaint"))
+	digest, _ := hex.DecodeString(plaint)
+	ecdsaSignature := func(key *ecdsa.PrivateKey) string {
+		raw, err := ecdsa.SignASN1(rand.Reader, key, digest)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return base64.StdEncoding.EncodeToString(raw)
+	}
+
+	var registered *SigningKey
+	err := inTransaction(t, n, "stampreporter", reporter, "", func(ctx contractapi.TransactionContextInterface) error {
+		var err error
+		registered, err = RegisterSigningKey(ctx, publicKeyPEM(t, edPublic))
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sign := func(id mockstub.Identity, signature string, fingerprint string) DocumentSignature {
+		doc := &Document{ID: "DOC_1", ContentHash: plaint}
+		err := inTransaction(t, n, "stampreporter", id, "", func(ctx contractapi.TransactionContextInterface) error {
+			return SignDocument(ctx, doc, DocumentSignature{SignatureHash: signature, StampReporterID: "SR001", Timestamp: "2024-05-15T10:00:00Z"}, fingerprint)
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return doc.SignatureHistory[0]
+	}
+	byCert := sign(reporter, ecdsaSignature(certKey), "")
+	byKey := sign(reporter, base64.StdEncoding.EncodeToString(ed25519.Sign(edPrivate, digest)), registered.Fingerprint)
+	bySelfSigned := sign(selfSigned, ecdsaSignature(attackerKey), "")
+	byOutsider := sign(outsider, ecdsaSignature(attackerKey), "")
+
+	// rekeyed claims the genuine signer but carries the attacker's key and signature
+	rekeyed := byCert
+	rekeyed.SignatureHash, rekeyed.PublicKey = ecdsaSignature(attackerKey), publicKeyPEM(t, &attackerKey.PublicKey)
+	unregistered := byKey
+	unregistered.SignerFingerprint = strings.Repeat("0", 64)
+	mismatched := byCert
+	mismatched.Certificate = bySelfSigned.Certificate
+	relabelled := byCert
+	relabelled.Algorithm = SignatureEd25519
+
+	c := &Case{ID: "CASE_001", Documents: []Document{
+		{ID: "DOC_1", ContentHash: plaint, SignatureHistory: []DocumentSignature{{SignatureHash: "sig-1", StampReporterID: "SR000"}, byCert, byKey}},
+		{ID: "DOC_2", ContentHash: plaint, SignatureHistory: []DocumentSignature{bySelfSigned, byOutsider, rekeyed, unregistered, mismatched, relabelled}},
+		{ID: "DOC_3", ContentHash: ContentHash([]byte("other")), SignatureHistory: []DocumentSignature{byCert}},
+	}}
+	verify := func() *SignatureVerification {
+		var v *SignatureVerification
+		err := inTransaction(t, n, "stampreporter", reporter, "", func(ctx contractapi.TransactionContextInterface) error {
+			var err error
+			v, err = VerifySignatures(ctx, c)
+			return err
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return v
+	}
+
+	if s := verify().Signatures[1]; s.Valid || s.Reason != "no root certificates are trusted for StampReportersOrg on this channel" {
+		t.Errorf("signature without trusted roots = %+v", s)
+	}
+	err = inTransaction(t, n, "stampreporter", reporter, "", func(ctx contractapi.TransactionContextInterface) error {
+		return PutOrgRoots(ctx, OrgStampReporters, mockstub.RootCertificate("StampReportersOrgMSP"))
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := verify()
+	want := []struct {
+		doc    string
+		index  int
+		valid  bool
+		reason string
+	}{
+		{"DOC_1", 0, false, "signature was recorded without a signer to check it against"},
+		{"DOC_1", 1, true, ""},
+		{"DOC_1", 2, true, ""},
+		{"DOC_2", 0, false, "was not issued by StampReportersOrg"},
+		{"DOC_2", 1, false, "was not issued by StampReportersOrg"},
+		{"DOC_2", 2, false, "ECDSA signature does not match the content hash"},
+		{"DOC_2", 3, false, "signing key " + strings.Repeat("0", 64) + " is not registered on this channel"},
+		{"DOC_2", 4, false, "signer certificate does not match the signer fingerprint"},
+		{"DOC_2", 5, false, "signature is recorded as Ed25519 but was made with ECDSA"},
+		{"DOC_3", 0, false, "ECDSA signature does not match the content hash"},
+	}
+	if got.Valid || len(got.Signatures) != len(want) {
+		t.Fatalf("verification = %+v", got)
+	}
+	for i, w := range want {
+		s := got.Signatures[i]
+		if s.DocumentID != w.doc || s.Index != w.index || s.Valid != w.valid || !strings.Contains(s.Reason, w.reason) || (w.reason == "") != (s.Reason == "") {
+			t.Errorf("signature %d = %+v, want %+v", i, s, w)
+		}
+	}
+
+	c.Documents = c.Documents[:1]
+	c.Documents[0].SignatureHistory = c.Documents[0].SignatureHistory[1:]
+	value, _ := json.Marshal(c)
+	n.PutState(ChannelLawyerRegistrar, "stampreporter", c.ID, value)
+	err = inTransaction(t, n, "stampreporter", reporter, "", func(ctx contractapi.TransactionContextInterface) error {
+		v, err := VerifyDocumentSignatures(ctx, "CASE_001")
+		if err == nil && (!v.Valid || len(v.Signatures) != 2) {
+			t.Errorf("stored case verification = %+v", v)
+		}
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
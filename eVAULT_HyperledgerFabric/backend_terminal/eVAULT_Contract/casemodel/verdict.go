@@ -0,0 +1,106 @@
+package casemodel
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Verdicts a stamp reporter gives a document
+const (
+	VerdictAccepted        = "ACCEPTED"
+	VerdictRejected        = "REJECTED"
+	VerdictNeedsCorrection = "NEEDS_CORRECTION"
+)
+
+// Reason codes for a document that was not accepted
+const (
+	ReasonCourtFeeDeficit  = "COURT_FEE_DEFICIT"
+	ReasonStampDutyDeficit = "STAMP_DUTY_DEFICIT"
+	ReasonUnsigned         = "UNSIGNED"
+	ReasonNotAttested      = "NOT_ATTESTED"
+	ReasonIllegible        = "ILLEGIBLE"
+	ReasonIncomplete       = "INCOMPLETE"
+	ReasonWrongType        = "WRONG_DOCUMENT_TYPE"
+	ReasonOther            = "OTHER"
+)
+
+// reasonCodes are the reason codes RecordVerdict accepts
+var reasonCodes = map[string]bool{
+	ReasonCourtFeeDeficit:  true,
+	ReasonStampDutyDeficit: true,
+	ReasonUnsigned:         true,
+	ReasonNotAttested:      true,
+	ReasonIllegible:        true,
+	ReasonIncomplete:       true,
+	ReasonWrongType:        true,
+	ReasonOther:            true,
+}
+
+// RecordVerdict records a stamp reporter's verdict on doc, which must be active. A
+// document that is not accepted needs a reason code and is no longer validated; an
+// accepted one is validated once its signature is added, see SignDocument.
+func RecordVerdict(doc *Document, verdict string, reasonCode string, comments string) error {
+	if !doc.Active() {
+		return fmt.Errorf("document %s is %s, only the latest active version of a document can be validated", doc.ID, doc.Status)
+	}
+	switch verdict {
+	case VerdictAccepted:
+		if reasonCode != "" {
+			return fmt.Errorf("document %s is accepted and cannot have a reason code", doc.ID)
+		}
+	case VerdictRejected, VerdictNeedsCorrection:
+		if !reasonCodes[reasonCode] {
+			return fmt.Errorf("document %s is %s and needs a reason code, got %q", doc.ID, verdict, reasonCode)
+		}
+		doc.Validated = false
+	default:
+		return fmt.Errorf("document %s has verdict %q, must be %s, %s or %s", doc.ID, verdict, VerdictAccepted, VerdictRejected, VerdictNeedsCorrection)
+	}
+	doc.Verdict, doc.ReasonCode, doc.VerdictComments = verdict, reasonCode, comments
+	return nil
+}
+
+// ReviewStatus derives the status a stamp reporter's review moves c to from the verdicts
+// on its active documents: rejected if any document is rejected, on hold if any needs
+// correction and validated otherwise. Every active document must have a verdict.
+func ReviewStatus(c *Case) (string, error) {
+	status := StatusValidatedByStampReporter
+	for _, doc := range c.Documents {
+		if !doc.Active() {
+			continue
+		}
+		switch doc.Verdict {
+		case "":
+			return "", fmt.Errorf("document %s of case %s has no verdict", doc.ID, c.ID)
+		case VerdictRejected:
+			status = StatusRejectedByStampReporter
+		case VerdictNeedsCorrection:
+			if status != StatusRejectedByStampReporter {
+				status = StatusOnHoldByStampReporter
+			}
+		}
+	}
+	return status, nil
+}
+
+// RejectedDocuments returns the active documents of c the stamp reporter rejected or sent
+// back for correction, the ones the lawyer has to replace
+func RejectedDocuments(c *Case) []Document {
+	docs := make([]Document, 0)
+	for _, doc := range c.Documents {
+		if doc.Active() && (doc.Verdict == VerdictRejected || doc.Verdict == VerdictNeedsCorrection) {
+			docs = append(docs, doc)
+		}
+	}
+	return docs
+}
+
+// RejectionSummary lists the documents RejectedDocuments returns with their reason codes,
+// for the case history
+func RejectionSummary(c *Case) string {
+	var parts []string
+	for _, doc := range RejectedDocuments(c) {
+		parts = append(parts, fmt.Sprintf("%s %s (%s)", doc.ID, doc.Verdict, doc.ReasonCode))
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,77 @@
+package casemodel
+
+import (
+	"testing"
+)
+
+func TestRecordVerdict(t *testing.T) {
+	doc := Document{ID: "DOC_1", Validated: true}
+	if err := RecordVerdict(&doc, VerdictNeedsCorrection, ReasonIllegible, "page 3 unreadable"); err != nil {
+		t.Fatal(err)
+	}
+	if doc.Validated || doc.Verdict != VerdictNeedsCorrection || doc.ReasonCode != ReasonIllegible || doc.VerdictComments != "page 3 unreadable" {
+		t.Errorf("document = %+v", doc)
+	}
+	if err := RecordVerdict(&doc, VerdictAccepted, "", ""); err != nil || doc.Verdict != VerdictAccepted || doc.ReasonCode != "" {
+		t.Errorf("accepted document = %+v, %v", doc, err)
+	}
+
+	for _, tt := range []struct {
+		doc                  Document
+		verdict, reason, err string
+	}{
+		{Document{ID: "DOC_1"}, "APPROVED", "", `document DOC_1 has verdict "APPROVED", must be ACCEPTED, REJECTED or NEEDS_CORRECTION`},
+		{Document{ID: "DOC_1"}, VerdictAccepted, ReasonOther, "document DOC_1 is accepted and cannot have a reason code"},
+		{Document{ID: "DOC_1"}, VerdictRejected, "", `document DOC_1 is REJECTED and needs a reason code, got ""`},
+		{Document{ID: "DOC_1"}, VerdictNeedsCorrection, "TYPO", `document DOC_1 is NEEDS_CORRECTION and needs a reason code, got "TYPO"`},
+		{Document{ID: "DOC_1", Status: DocumentWithdrawn}, VerdictAccepted, "", "document DOC_1 is WITHDRAWN, only the latest active version of a document can be validated"},
+	} {
+		if err := RecordVerdict(&tt.doc, tt.verdict, tt.reason, ""); err == nil || err.Error() != tt.err {
+			t.Errorf("RecordVerdict(%s, %s) = %v, want %q", tt.verdict, tt.reason, err, tt.err)
+		}
+	}
+}
+
+func TestReviewStatus(t *testing.T) {
+	accepted := Document{ID: "DOC_1", Verdict: VerdictAccepted}
+	rejected := Document{ID: "DOC_2", Verdict: VerdictRejected, ReasonCode: ReasonUnsigned}
+	correct := Document{ID: "DOC_3", Verdict: VerdictNeedsCorrection, ReasonCode: ReasonIllegible}
+	superseded := Document{ID: "DOC_0", Status: DocumentSuperseded, Verdict: VerdictRejected, ReasonCode: ReasonIllegible}
+
+	for _, tt := range []struct {
+		name string
+		docs []Document
+		want string
+	}{
+		{"no documents", nil, StatusValidatedByStampReporter},
+		{"all accepted", []Document{accepted, superseded}, StatusValidatedByStampReporter},
+		{"needs correction", []Document{accepted, correct}, StatusOnHoldByStampReporter},
+		{"rejected", []Document{correct, rejected}, StatusRejectedByStampReporter},
+	} {
+		c := &Case{ID: "CASE_001", Documents: tt.docs}
+		if got, err := ReviewStatus(c); err != nil || got != tt.want {
+			t.Errorf("%s: ReviewStatus = %s, %v, want %s", tt.name, got, err, tt.want)
+		}
+	}
+
+	c := &Case{ID: "CASE_001", Documents: []Document{accepted, {ID: "DOC_4"}}}
+	if _, err := ReviewStatus(c); err == nil || err.Error() != "document DOC_4 of case CASE_001 has no verdict" {
+		t.Errorf("ReviewStatus without a verdict = %v", err)
+	}
+}
+
+func TestRejectedDocuments(t *testing.T) {
+	c := &Case{ID: "CASE_001", Documents: []Document{
+		{ID: "DOC_1", Verdict: VerdictAccepted},
+		{ID: "DOC_2", Status: DocumentSuperseded, Verdict: VerdictRejected, ReasonCode: ReasonUnsigned},
+		{ID: "DOC_2_V2", Status: DocumentActive, Verdict: VerdictRejected, ReasonCode: ReasonNotAttested},
+		{ID: "DOC_3", Verdict: VerdictNeedsCorrection, ReasonCode: ReasonCourtFeeDeficit},
+	}}
+	docs := RejectedDocuments(c)
+	if len(docs) != 2 || docs[0].ID != "DOC_2_V2" || docs[1].ID != "DOC_3" {
+		t.Errorf("rejected documents = %+v", docs)
+	}
+	if got := RejectionSummary(c); got != "DOC_2_V2 REJECTED (NOT_ATTESTED), DOC_3 NEEDS_CORRECTION (COURT_FEE_DEFICIT)" {
+		t.Errorf("summary = %q", got)
+	}
+}
@@ -1,4 +1,4 @@
-package main
+package chaincode
 
 import (
 	"encoding/json"
@@ -8,32 +8,21 @@ import (
 	"time"
 
 	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+
+	"casemodel"
+	"casemodel/access"
+	"casemodel/query"
 )
 
-// Case represents a legal case in the system
-type Case struct {
-	ID                string        `json:"id"`
-	CaseNumber        string        `json:"caseNumber"`
-	Title             string        `json:"title"`
-	Type              string        `json:"type"`
-	Description       string        `json:"description"`
-	Status            string        `json:"status"`
-	CurrentOrg        string        `json:"currentOrg"`
-	UIDParty1         string        `json:"uidParty1"`
-	UIDParty2         string        `json:"uidParty2"`
-	FiledDate         string        `json:"filedDate"`
-	AssociatedLawyers []string      `json:"associatedLawyers"`
-	AssociatedJudge   string        `json:"associatedJudge"`
-	CaseSubject       string        `json:"caseSubject"`
-	ClientName        string        `json:"clientName"`
-	Department        string        `json:"department"`
-	Documents         []Document    `json:"documents"`
-	History           []HistoryItem `json:"history"`
-	CreatedBy         string        `json:"createdBy"`
-	CreatedAt         string        `json:"createdAt"`
-	LastModified      string        `json:"lastModified"`
-	Decision          string        `json:"decision"`
-}
+// The case record is shared by every eVAULT contract so that no field is lost as a
+// case moves between channels
+type (
+	Case              = casemodel.Case
+	Document          = casemodel.Document
+	DocumentSignature = casemodel.DocumentSignature
+	HistoryItem       = casemodel.HistoryItem
+	CasePage          = casemodel.CasePage
+)
 
 // Judge represents a judicial officer in the system
 type Judge struct {
@@ -42,40 +31,74 @@ type Judge struct {
 	Division string `json:"division"`
 }
 
-// Document represents a case document
-type Document struct {
-	ID               string              `json:"id"`
-	Name             string              `json:"name"`
-	Type             string              `json:"type"`
-	Hash             string              `json:"hash"`
-	Validated        bool                `json:"validated"`
-	UploadedAt       string              `json:"uploadedAt"`
-	SignatureHistory []DocumentSignature `json:"signatureHistory"`
+// JudgePage is one page of a paginated judge query
+type JudgePage struct {
+	Records      []*Judge `json:"records"`
+	FetchedCount int32    `json:"fetchedCount"`
+	Bookmark     string   `json:"bookmark"`
 }
 
-// DocumentSignature represents a digital signature applied by a stamp reporter
-type DocumentSignature struct {
-	SignatureHash   string `json:"signatureHash"`
-	StampReporterID string `json:"stampReporterId"`
-	Timestamp       string `json:"timestamp"`
-	Comments        string `json:"comments"`
+// BenchClerkContract provides functions for managing bench clerk activities in eVAULT
+type BenchClerkContract struct {
+	contractapi.Contract
 }
 
-// HistoryItem represents a case status change
-type HistoryItem struct {
-	Status       string `json:"status"`
-	Organization string `json:"organization"`
-	Timestamp    string `json:"timestamp"`
-	Comments     string `json:"comments"`
+// accessPolicy lists the roles allowed to call each transaction. Roles from other
+// organizations appear where a transaction is invoked across channels.
+var accessPolicy = access.Policy{
+	"InitLedger":                                {access.RoleBenchClerk},
+	"ForwardToJudge":                            {access.RoleBenchClerk},
+	"UpdateHearingDetails":                      {access.RoleBenchClerk},
+	"NotifyLawyer":                              {access.RoleBenchClerk},
+	"GetCaseDetails":                            {access.RoleBenchClerk},
+	"GetAllCases":                               {access.RoleBenchClerk},
+	"GetAllJudges":                              {access.RoleBenchClerk},
+	"ConfirmJudgeDecision":                      {access.RoleBenchClerk},
+	"QueryCasesByStatus":                        {access.RoleBenchClerk},
+	"GetAllCasesWithPagination":                 {access.RoleBenchClerk},
+	"GetAllJudgesWithPagination":                {access.RoleBenchClerk},
+	"QueryCasesByStatusWithPagination":          {access.RoleBenchClerk},
+	"GetCaseById":                               {access.RoleBenchClerk, access.RoleJudge, access.RoleLawyer}, // read by the judge and lawyer across channels
+	"QueryStats":                                {access.RoleBenchClerk},
+	"StoreCase":                                 {access.RoleStampReporter, access.RoleJudge}, // written by the stamp reporter and the judge when forwarding
+	"ForwardCaseToLawyer":                       {access.RoleBenchClerk},
+	"FetchAndStoreCaseFromJudgeChannel":         {access.RoleBenchClerk},
+	"FetchAndStoreCaseFromStampReporterChannel": {access.RoleBenchClerk},
+	"GetAllowedTransitions":                     {access.RoleBenchClerk},
+	"VerifyCaseHistory":                         {access.RoleBenchClerk},
+	"VerifyDocument":                            {access.RoleBenchClerk},
+	"VerifyDocumentSignatures":                  {access.RoleBenchClerk},
+	"GetCaseProvenance":                         {access.RoleBenchClerk},
+	"SetRoutingConfig":                          {access.RoleBenchClerk},
+	"GetRoutingConfig":                          {access.RoleBenchClerk},
+	"SetOrgRoots":                               {access.RoleLawyer, access.RoleRegistrar, access.RoleStampReporter, access.RoleBenchClerk, access.RoleJudge},
+	"ImportOrgRoots":                            {access.RoleBenchClerk},
+	"GetOrgRoots":                               {access.RoleLawyer, access.RoleRegistrar, access.RoleStampReporter, access.RoleBenchClerk, access.RoleJudge},
+	"ListPendingTransfers":                      {access.RoleBenchClerk, access.RoleJudge, access.RoleLawyer}, // read by the judge and lawyer when claiming transfers
+	"ClaimTransfer":                             {access.RoleJudge, access.RoleLawyer},                        // invoked through ReceiveTransfers of the judge and lawyer only
+	"RetryTransfer":                             {access.RoleBenchClerk},
+	"ReceiveTransfers":                          {access.RoleBenchClerk},
 }
 
-// BenchClerkContract provides functions for managing bench clerk activities in eVAULT
-type BenchClerkContract struct {
-	contractapi.Contract
+// hops are the calls this contract makes to other chaincodes. A stored routing table
+// must route all of them.
+var hops = []string{
+	casemodel.HopBenchClerkToStampReporter,
+	casemodel.HopBenchClerkToJudge,
+	casemodel.HopBenchClerkToLawyer,
 }
 
-// InitLedger initializes the bench clerk contract ledger with sample data
-func (bc *BenchClerkContract) InitLedger(ctx contractapi.TransactionContextInterface) error {
+// InitLedger initializes the bench clerk contract ledger with sample data and the routing
+// table of the calls this contract makes, or the default routes when routingConfig is
+// empty. Run it as the init transaction on every channel the contract is deployed on.
+func (bc *BenchClerkContract) InitLedger(ctx contractapi.TransactionContextInterface, routingConfig string) error {
+	if err := access.RequireAdmin(ctx, "InitLedger"); err != nil {
+		return err
+	}
+	if err := casemodel.InitRouting(ctx, routingConfig, hops...); err != nil {
+		return err
+	}
+
 	// Sample judges
 	judges := []Judge{
 		{ID: "J001", Name: "Hon. Justice Patel", Division: "Civil"},
@@ -100,17 +123,6 @@ func (bc *BenchClerkContract) InitLedger(ctx contractapi.TransactionContextInter
 func (s *BenchClerkContract) ForwardToJudge(ctx contractapi.TransactionContextInterface, caseID string, assignmentDetails string) error {
 	log.Printf("ForwardToJudge called for case ID: %s", caseID)
 
-	// Get MSP ID of the submitting client
-	clientOrgID, err := ctx.GetClientIdentity().GetMSPID()
-	if err != nil {
-		return fmt.Errorf("failed to get client MSP ID: %v", err)
-	}
-
-	// Only BenchClerksOrg members should call this function
-	if clientOrgID != "BenchClerksOrg" && clientOrgID != "BenchClerksOrgMSP" {
-		return fmt.Errorf("this function can only be called by members of BenchClerksOrg")
-	}
-
 	// Get the case
 	caseJSON, err := ctx.GetStub().GetState(caseID)
 	if err != nil {
@@ -165,8 +177,9 @@ func (s *BenchClerkContract) ForwardToJudge(ctx contractapi.TransactionContextIn
 	timestamp := time.Unix(txTimestamp.Seconds, 0).Format(time.RFC3339)
 
 	// Update case status and judge assignment
-	caseObj.Status = "PENDING_JUDGE_REVIEW"
-	caseObj.CurrentOrg = "JudgesOrg"
+	if err := casemodel.ApplyTransition(ctx, &caseObj, casemodel.StatusPendingJudgeReview); err != nil {
+		return err
+	}
 	caseObj.AssociatedJudge = details.JudgeID
 	caseObj.History = append(caseObj.History, HistoryItem{
 		Status:       "FORWARDED_TO_JUDGE",
@@ -177,6 +190,9 @@ func (s *BenchClerkContract) ForwardToJudge(ctx contractapi.TransactionContextIn
 
 	// Save updated case
 	caseObj.LastModified = timestamp
+	if err := casemodel.Seal(ctx, &caseObj); err != nil {
+		return err
+	}
 	updatedCaseJSON, err := json.Marshal(caseObj)
 	if err != nil {
 		return fmt.Errorf("failed to marshal updated case: %v", err)
@@ -188,23 +204,15 @@ func (s *BenchClerkContract) ForwardToJudge(ctx contractapi.TransactionContextIn
 		return fmt.Errorf("failed to update case in BenchClerk's ledger: %v", err)
 	}
 
-	// Forward the case to Judge through cross-channel invocation
-	caseJSONStr := string(updatedCaseJSON)
-	args := [][]byte{[]byte("StoreCase"), []byte(caseJSONStr)}
-	response := ctx.GetStub().InvokeChaincode("judge", args, "benchclerk-judge-channel")
-
-	// Check if the Judge chaincode response is successful
-	if response.Status != 200 {
-		errMsg := fmt.Sprintf("Failed to forward case to Judge: %s", string(response.Message))
-		log.Printf(errMsg)
-
-		// Revert the status change in case of failure
-		caseObj.Status = "RECEIVED_BY_BENCHCLERK"
-		caseObj.CurrentOrg = "BenchClerksOrg"
-		revertJSON, _ := json.Marshal(caseObj)
-		ctx.GetStub().PutState(caseID, revertJSON)
-
-		return fmt.Errorf(errMsg)
+	// Hand the case to Judge through the outbox. If Judge cannot store it now, the
+	// transfer stays pending until Judge claims it or it is retried.
+	transfer, err := casemodel.Handoff(ctx, casemodel.HopBenchClerkToJudge, "StoreCase", &caseObj)
+	if err != nil {
+		return err
+	}
+	if transfer.Status == casemodel.TransferPending {
+		log.Printf("Case %s is pending in transfer %d to Judge: %s", caseID, transfer.Sequence, transfer.LastError)
+		return nil
 	}
 
 	log.Printf("Successfully forwarded case %s to Judge", caseID)
@@ -280,6 +288,9 @@ func (s *BenchClerkContract) UpdateHearingDetails(ctx contractapi.TransactionCon
 
 	// Save updated case
 	caseObj.LastModified = timestamp
+	if err := casemodel.Seal(ctx, &caseObj); err != nil {
+		return err
+	}
 	caseJSON, err = json.Marshal(caseObj)
 	if err != nil {
 		log.Printf("Failed to marshal updated case: %v", err)
@@ -352,6 +363,9 @@ func (s *BenchClerkContract) NotifyLawyer(ctx contractapi.TransactionContextInte
 
 	// Save updated case
 	caseObj.LastModified = timestamp
+	if err := casemodel.Seal(ctx, &caseObj); err != nil {
+		return err
+	}
 	caseJSON, err = json.Marshal(caseObj)
 	if err != nil {
 		log.Printf("Failed to marshal updated case: %v", err)
@@ -393,6 +407,9 @@ func (bc *BenchClerkContract) GetCaseDetails(ctx contractapi.TransactionContextI
 		caseData.AssociatedLawyers = make([]string, 0)
 	}
 
+	if err := access.RequireCaseView(ctx, &caseData); err != nil {
+		return nil, err
+	}
 	log.Printf("Successfully retrieved case with ID: %s", caseID)
 	return &caseData, nil
 }
@@ -419,7 +436,7 @@ func (bc *BenchClerkContract) GetAllCases(ctx contractapi.TransactionContextInte
 		cases = append(cases, &caseData)
 	}
 
-	return cases, nil
+	return access.RedactSealed(ctx, cases)
 }
 
 // GetAllJudges retrieves all judges from the ledger
@@ -447,6 +464,45 @@ func (bc *BenchClerkContract) GetAllJudges(ctx contractapi.TransactionContextInt
 	return judges, nil
 }
 
+// GetAllCasesWithPagination retrieves one page of the cases on the ledger
+func (bc *BenchClerkContract) GetAllCasesWithPagination(ctx contractapi.TransactionContextInterface, pageSize int32, bookmark string) (*CasePage, error) {
+	page, err := casemodel.GetCasesByRangeWithPagination(ctx, "CASE_", "", pageSize, bookmark)
+	if err != nil {
+		return nil, err
+	}
+	return access.RedactSealedPage(ctx, page)
+}
+
+// GetAllJudgesWithPagination retrieves one page of the judges on the ledger
+func (bc *BenchClerkContract) GetAllJudgesWithPagination(ctx contractapi.TransactionContextInterface, pageSize int32, bookmark string) (*JudgePage, error) {
+	if err := casemodel.CheckPageSize(pageSize); err != nil {
+		return nil, err
+	}
+	resultsIterator, metadata, err := ctx.GetStub().GetStateByRangeWithPagination("JUDGE_", "", pageSize, bookmark)
+	if err != nil {
+		return nil, err
+	}
+	defer resultsIterator.Close()
+
+	page := &JudgePage{Records: make([]*Judge, 0)}
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		var judge Judge
+		if err := json.Unmarshal(queryResponse.Value, &judge); err != nil {
+			return nil, err
+		}
+		page.Records = append(page.Records, &judge)
+	}
+	page.FetchedCount = metadata.FetchedRecordsCount
+	page.Bookmark = metadata.Bookmark
+
+	return page, nil
+}
+
 // ConfirmJudgeDecision confirms and forwards the judge's decision to relevant parties
 func (bc *BenchClerkContract) ConfirmJudgeDecision(ctx contractapi.TransactionContextInterface, caseID string) error {
 	log.Printf("ConfirmJudgeDecision called for case ID: %s", caseID)
@@ -479,7 +535,7 @@ func (bc *BenchClerkContract) ConfirmJudgeDecision(ctx contractapi.TransactionCo
 	}
 	// Check if the case has a judgment to confirm
 	// Looking for JUDGMENT_ISSUED status instead of checking Decision field
-	if caseData.Status != "JUDGMENT_ISSUED" {
+	if caseData.Status != casemodel.StatusJudgmentIssued {
 		log.Printf("Case %s has no judgment to confirm (status: %s)", caseID, caseData.Status)
 		return fmt.Errorf("case %s has no judgment to confirm, status must be JUDGMENT_ISSUED", caseID)
 	}
@@ -491,10 +547,9 @@ func (bc *BenchClerkContract) ConfirmJudgeDecision(ctx contractapi.TransactionCo
 		return fmt.Errorf("failed to get transaction timestamp: %v", err)
 	}
 	timestamp := time.Unix(txTimestamp.Seconds, 0).Format(time.RFC3339) // Update case status to indicate decision is confirmed
-	caseData.Status = "DECISION_CONFIRMED"
-
-	// Forward the confirmed decision to lawyers by changing the current organization
-	caseData.CurrentOrg = "LawyersOrg"
+	if err := casemodel.ApplyTransition(ctx, &caseData, casemodel.StatusDecisionConfirmed); err != nil {
+		return err
+	}
 
 	// Add to history
 	caseData.History = append(caseData.History, HistoryItem{
@@ -521,6 +576,10 @@ func (bc *BenchClerkContract) ConfirmJudgeDecision(ctx contractapi.TransactionCo
 	// Update last modified timestamp
 	caseData.LastModified = timestamp
 
+	if err := casemodel.Seal(ctx, &caseData); err != nil {
+		return err
+	}
+
 	// Save updated case
 	updatedCaseAsBytes, err := json.Marshal(caseData)
 	if err != nil {
@@ -532,9 +591,14 @@ func (bc *BenchClerkContract) ConfirmJudgeDecision(ctx contractapi.TransactionCo
 	return ctx.GetStub().PutState(caseID, updatedCaseAsBytes)
 }
 
+// casesByStatusQuery selects cases with the given status
+func casesByStatusQuery(status string) string {
+	return query.New(query.Eq("status", status)).String()
+}
+
 // QueryCasesByStatus retrieves cases filtered by their status
 func (bc *BenchClerkContract) QueryCasesByStatus(ctx contractapi.TransactionContextInterface, status string) ([]*Case, error) {
-	queryString := fmt.Sprintf(`{"selector":{"status":"%s"}}`, status)
+	queryString := casesByStatusQuery(status)
 
 	resultsIterator, err := ctx.GetStub().GetQueryResult(queryString)
 	if err != nil {
@@ -556,7 +620,16 @@ func (bc *BenchClerkContract) QueryCasesByStatus(ctx contractapi.TransactionCont
 		cases = append(cases, &caseData)
 	}
 
-	return cases, nil
+	return access.RedactSealed(ctx, cases)
+}
+
+// QueryCasesByStatusWithPagination retrieves one page of the cases with the given status
+func (bc *BenchClerkContract) QueryCasesByStatusWithPagination(ctx contractapi.TransactionContextInterface, status string, pageSize int32, bookmark string) (*CasePage, error) {
+	page, err := casemodel.QueryCasesWithPagination(ctx, casesByStatusQuery(status), pageSize, bookmark)
+	if err != nil {
+		return nil, err
+	}
+	return access.RedactSealedPage(ctx, page)
 }
 
 // GetCaseById retrieves a specific case by its ID
@@ -593,6 +666,9 @@ func (bc *BenchClerkContract) GetCaseById(ctx contractapi.TransactionContextInte
 		caseObj.AssociatedLawyers = make([]string, 0)
 	}
 
+	if err := access.RequireCaseView(ctx, &caseObj); err != nil {
+		return nil, err
+	}
 	log.Printf("Successfully retrieved case with ID: %s", caseID)
 	return &caseObj, nil
 }
@@ -609,7 +685,10 @@ func (bc *BenchClerkContract) QueryStats(ctx contractapi.TransactionContextInter
 	}{}
 
 	// Count pending cases
-	pendingIterator, err := ctx.GetStub().GetQueryResult(`{"selector":{"status":"VALIDATED_BY_STAMP_REPORTER","currentOrg":"BenchClerksOrg"}}`)
+	pendingIterator, err := ctx.GetStub().GetQueryResult(query.New(query.And(
+		query.Eq("status", casemodel.StatusValidatedByStampReporter),
+		query.Eq("currentOrg", casemodel.OrgBenchClerks),
+	)).String())
 	if err == nil {
 		for pendingIterator.HasNext() {
 			stats.PendingCases++
@@ -619,7 +698,10 @@ func (bc *BenchClerkContract) QueryStats(ctx contractapi.TransactionContextInter
 	}
 
 	// Count cases forwarded to judges
-	forwardedIterator, err := ctx.GetStub().GetQueryResult(`{"selector":{"status":"PENDING_JUDGE_REVIEW","currentOrg":"JudgesOrg"}}`)
+	forwardedIterator, err := ctx.GetStub().GetQueryResult(query.New(query.And(
+		query.Eq("status", casemodel.StatusPendingJudgeReview),
+		query.Eq("currentOrg", casemodel.OrgJudges),
+	)).String())
 	if err == nil {
 		for forwardedIterator.HasNext() {
 			stats.ForwardedToJudge++
@@ -628,18 +710,27 @@ func (bc *BenchClerkContract) QueryStats(ctx contractapi.TransactionContextInter
 		forwardedIterator.Close()
 	}
 
-	// Count cases with hearings scheduled
-	hearingQuery := fmt.Sprintf(`{"selector":{"$or":[{"status":"HEARING_SCHEDULED"},{"history":{"$elemMatch":{"status":"HEARING_SCHEDULED"}}}]}}`)
+	// Count cases with hearings scheduled. The hearings of a sealed case are only
+	// counted for callers on its access list.
+	hearingQuery := query.New(query.Or(
+		query.Eq("status", "HEARING_SCHEDULED"),
+		query.ElemMatch("history", query.Eq("status", "HEARING_SCHEDULED")),
+	)).String()
 	hearingIterator, err := ctx.GetStub().GetQueryResult(hearingQuery)
 	if err == nil {
 		for hearingIterator.HasNext() {
-			stats.HearingsScheduled++
-			_, _ = hearingIterator.Next()
+			response, err := hearingIterator.Next()
+			if err != nil {
+				break
+			}
+			if visible, err := access.CanViewStored(ctx, response.Value); err == nil && visible {
+				stats.HearingsScheduled++
+			}
 		}
 		hearingIterator.Close()
 	}
 	// Count confirmed decisions
-	decisionIterator, err := ctx.GetStub().GetQueryResult(`{"selector":{"status":"DECISION_CONFIRMED"}}`)
+	decisionIterator, err := ctx.GetStub().GetQueryResult(query.New(query.Eq("status", casemodel.StatusDecisionConfirmed)).String())
 	if err == nil {
 		for decisionIterator.HasNext() {
 			stats.DecisionsConfirmed++
@@ -664,32 +755,30 @@ func (bc *BenchClerkContract) StoreCase(ctx contractapi.TransactionContextInterf
 	log.Printf("StoreCase called with payload length: %d bytes", len(caseJSON))
 
 	// Parse case data
-	var newCase Case
-	err := json.Unmarshal([]byte(caseJSON), &newCase)
+	newCase, err := casemodel.DecodeCase([]byte(caseJSON))
 	if err != nil {
-		log.Printf("Failed to unmarshal case data: %v", err)
-		return fmt.Errorf("failed to unmarshal case data: %v", err)
+		log.Printf("Failed to decode case data: %v", err)
+		return err
 	}
 
 	log.Printf("Successfully parsed case with ID: %s, Title: %s", newCase.ID, newCase.Title)
 
-	// Initialize empty arrays if they are null
-	if newCase.Documents == nil {
-		newCase.Documents = make([]Document, 0)
-	}
-	if newCase.History == nil {
-		newCase.History = make([]HistoryItem, 0)
-	}
-	if newCase.AssociatedLawyers == nil {
-		newCase.AssociatedLawyers = make([]string, 0)
-	}
-
 	// Verify required fields
 	if newCase.ID == "" {
 		log.Printf("Case ID is required")
 		return fmt.Errorf("case ID is required")
 	}
 
+	// Only store the case as the stamp reporter or the judge handed it to the bench clerks
+	if err := casemodel.VerifyReceived(ctx, newCase, casemodel.OrgBenchClerks, casemodel.OrgStampReporters, casemodel.OrgJudges); err != nil {
+		log.Printf("Case %s failed verification: %v", newCase.ID, err)
+		return err
+	}
+
+	if err := casemodel.Seal(ctx, newCase); err != nil {
+		return err
+	}
+
 	// Store the case in the ledger
 	updatedCaseJSON, err := json.Marshal(newCase)
 	if err != nil {
@@ -711,17 +800,6 @@ func (bc *BenchClerkContract) StoreCase(ctx contractapi.TransactionContextInterf
 func (bc *BenchClerkContract) ForwardCaseToLawyer(ctx contractapi.TransactionContextInterface, caseID string) error {
 	log.Printf("ForwardCaseToLawyer called for case ID: %s", caseID)
 
-	// Get MSP ID of the submitting client
-	clientOrgID, err := ctx.GetClientIdentity().GetMSPID()
-	if err != nil {
-		return fmt.Errorf("failed to get client MSP ID: %v", err)
-	}
-
-	// Only BenchClerksOrg members should call this function
-	if clientOrgID != "BenchClerksOrg" && clientOrgID != "BenchClerksOrgMSP" {
-		return fmt.Errorf("this function can only be called by members of BenchClerksOrg")
-	}
-
 	// Get the case from BenchClerk's ledger
 	caseJSON, err := ctx.GetStub().GetState(caseID)
 	if err != nil {
@@ -738,7 +816,7 @@ func (bc *BenchClerkContract) ForwardCaseToLawyer(ctx contractapi.TransactionCon
 	}
 
 	// Verify case status - only forward cases with received judgments
-	if caseObj.Status != "JUDGMENT_RECEIVED" {
+	if caseObj.Status != casemodel.StatusJudgmentReceived {
 		return fmt.Errorf("case status must be JUDGMENT_RECEIVED for forwarding to Lawyer, current status: %s", caseObj.Status)
 	}
 
@@ -750,8 +828,9 @@ func (bc *BenchClerkContract) ForwardCaseToLawyer(ctx contractapi.TransactionCon
 	timestamp := time.Unix(txTimestamp.Seconds, 0).Format(time.RFC3339)
 
 	// Update case status for forwarding to lawyer
-	caseObj.Status = "DECISION_CONFIRMED"
-	caseObj.CurrentOrg = "LawyersOrg"
+	if err := casemodel.ApplyTransition(ctx, &caseObj, casemodel.StatusDecisionConfirmed); err != nil {
+		return err
+	}
 	caseObj.LastModified = timestamp
 
 	// Add history item
@@ -762,6 +841,10 @@ func (bc *BenchClerkContract) ForwardCaseToLawyer(ctx contractapi.TransactionCon
 		Comments:     "Judgment confirmed and forwarded to lawyer",
 	})
 
+	if err := casemodel.Seal(ctx, &caseObj); err != nil {
+		return err
+	}
+
 	// Marshal the updated case data
 	updatedCaseJSON, err := json.Marshal(caseObj)
 	if err != nil {
@@ -774,24 +857,15 @@ func (bc *BenchClerkContract) ForwardCaseToLawyer(ctx contractapi.TransactionCon
 		return fmt.Errorf("failed to update case in BenchClerk's ledger: %v", err)
 	}
 
-	// Forward the case to lawyer through cross-channel invocation
-	// Convert the JSON byte array to string as the lawyer expects a string parameter
-	caseJSONStr := string(updatedCaseJSON)
-	args := [][]byte{[]byte("StoreCase"), []byte(caseJSONStr)}
-	response := ctx.GetStub().InvokeChaincode("lawyer", args, "benchclerk-lawyer-channel")
-
-	// Check if the lawyer chaincode response is successful
-	if response.Status != 200 {
-		errMsg := fmt.Sprintf("Failed to forward case to lawyer: %s", string(response.Message))
-		log.Printf(errMsg)
-
-		// Revert the status change in case of failure
-		caseObj.Status = "JUDGMENT_RECEIVED"
-		caseObj.CurrentOrg = "BenchClerksOrg"
-		revertJSON, _ := json.Marshal(caseObj)
-		ctx.GetStub().PutState(caseID, revertJSON)
-
-		return fmt.Errorf(errMsg)
+	// Hand the case to lawyer through the outbox. If lawyer cannot store it now, the
+	// transfer stays pending until lawyer claims it or it is retried.
+	transfer, err := casemodel.Handoff(ctx, casemodel.HopBenchClerkToLawyer, "StoreCase", &caseObj)
+	if err != nil {
+		return err
+	}
+	if transfer.Status == casemodel.TransferPending {
+		log.Printf("Case %s is pending in transfer %d to lawyer: %s", caseID, transfer.Sequence, transfer.LastError)
+		return nil
 	}
 
 	log.Printf("Successfully forwarded case %s to lawyer", caseID)
@@ -802,20 +876,9 @@ func (bc *BenchClerkContract) ForwardCaseToLawyer(ctx contractapi.TransactionCon
 func (bc *BenchClerkContract) FetchAndStoreCaseFromJudgeChannel(ctx contractapi.TransactionContextInterface) error {
 	log.Printf("FetchAndStoreCaseFromJudgeChannel called")
 
-	// Get MSP ID of the submitting client
-	clientOrgID, err := ctx.GetClientIdentity().GetMSPID()
-	if err != nil {
-		return fmt.Errorf("failed to get client MSP ID: %v", err)
-	}
-
-	// Only BenchClerksOrg members should call this function
-	if clientOrgID != "BenchClerksOrg" && clientOrgID != "BenchClerksOrgMSP" {
-		return fmt.Errorf("this function can only be called by members of BenchClerksOrg")
-	}
-
 	// Invoke the GetJudgedCases function in the Judge chaincode to get all cases with judgment
 	args := [][]byte{[]byte("GetJudgedCases")}
-	response := ctx.GetStub().InvokeChaincode("judge", args, "benchclerk-judge-channel")
+	response := casemodel.InvokeHop(ctx, casemodel.HopBenchClerkToJudge, args)
 
 	// Check if the Judge chaincode response is successful
 	if response.Status != 200 {
@@ -832,7 +895,7 @@ func (bc *BenchClerkContract) FetchAndStoreCaseFromJudgeChannel(ctx contractapi.
 
 	// Parse the response payload which should be a JSON array of cases
 	var judgedCases []Case
-	err = json.Unmarshal(response.Payload, &judgedCases)
+	err := json.Unmarshal(response.Payload, &judgedCases)
 	if err != nil {
 		return fmt.Errorf("failed to unmarshal judged cases: %v", err)
 	}
@@ -842,21 +905,29 @@ func (bc *BenchClerkContract) FetchAndStoreCaseFromJudgeChannel(ctx contractapi.
 	// Store each case in BenchClerk's ledger
 	for _, caseObj := range judgedCases {
 		// Verify the case has a judgment and the current org is BenchClerksOrg
-		if caseObj.Status != "JUDGMENT_ISSUED" || caseObj.CurrentOrg != "BenchClerksOrg" {
+		if caseObj.CurrentOrg != casemodel.OrgBenchClerks {
 			log.Printf("Skipping case %s: status=%s, currentOrg=%s", caseObj.ID, caseObj.Status, caseObj.CurrentOrg)
 			continue
 		}
+		// Skip cases the judge did not hand over in the state received
+		if err := casemodel.VerifyHandoff(ctx, &caseObj, casemodel.OrgJudges); err != nil {
+			log.Printf("Skipping case %s: %v", caseObj.ID, err)
+			continue
+		}
+		if err := casemodel.ApplyTransition(ctx, &caseObj, casemodel.StatusJudgmentReceived); err != nil {
+			log.Printf("Skipping case %s: %v", caseObj.ID, err)
+			continue
+		}
 
-		// Get current timestamp
+		// A moved case is stored or the transaction fails, so that its status change is
+		// never announced without it
 		txTimestamp, err := ctx.GetStub().GetTxTimestamp()
 		if err != nil {
 			log.Printf("Failed to get transaction timestamp for case %s: %v", caseObj.ID, err)
-			continue
+			return fmt.Errorf("failed to get transaction timestamp for case %s: %v", caseObj.ID, err)
 		}
 		timestamp := time.Unix(txTimestamp.Seconds, 0).Format(time.RFC3339)
 
-		// Update case status to indicate judgment has been received
-		caseObj.Status = "JUDGMENT_RECEIVED"
 		caseObj.LastModified = timestamp
 
 		// Add history item
@@ -867,18 +938,23 @@ func (bc *BenchClerkContract) FetchAndStoreCaseFromJudgeChannel(ctx contractapi.
 			Comments:     fmt.Sprintf("Judgment received from Judge: %s", caseObj.Decision),
 		})
 
+		if err := casemodel.Seal(ctx, &caseObj); err != nil {
+			log.Printf("Failed to seal case %s: %v", caseObj.ID, err)
+			return fmt.Errorf("failed to seal case %s: %v", caseObj.ID, err)
+		}
+
 		// Marshal the updated case
 		caseJSON, err := json.Marshal(caseObj)
 		if err != nil {
 			log.Printf("Failed to marshal case %s: %v", caseObj.ID, err)
-			continue
+			return fmt.Errorf("failed to marshal case %s: %v", caseObj.ID, err)
 		}
 
 		// Store the case in BenchClerk's ledger
 		err = ctx.GetStub().PutState(caseObj.ID, caseJSON)
 		if err != nil {
 			log.Printf("Failed to store case %s: %v", caseObj.ID, err)
-			continue
+			return fmt.Errorf("failed to store case %s: %v", caseObj.ID, err)
 		}
 
 		log.Printf("Successfully stored case %s with judgment in BenchClerk's ledger", caseObj.ID)
@@ -891,20 +967,9 @@ func (bc *BenchClerkContract) FetchAndStoreCaseFromJudgeChannel(ctx contractapi.
 func (bc *BenchClerkContract) FetchAndStoreCaseFromStampReporterChannel(ctx contractapi.TransactionContextInterface, caseID string) (*Case, error) {
 	log.Printf("FetchAndStoreCaseFromStampReporterChannel called for case ID: %s", caseID)
 
-	// Get MSP ID of the submitting client
-	clientOrgID, err := ctx.GetClientIdentity().GetMSPID()
-	if err != nil {
-		return nil, fmt.Errorf("failed to get client MSP ID: %v", err)
-	}
-
-	// Only BenchClerksOrg members should call this function
-	if clientOrgID != "BenchClerksOrg" && clientOrgID != "BenchClerksOrgMSP" {
-		return nil, fmt.Errorf("this function can only be called by members of BenchClerksOrg")
-	}
-
 	// Invoke the stampreporter chaincode on the stampreporter-benchclerk-channel to get the case
 	args := [][]byte{[]byte("GetCaseById"), []byte(caseID)}
-	response := ctx.GetStub().InvokeChaincode("stampreporter", args, "stampreporter-benchclerk-channel")
+	response := casemodel.InvokeHop(ctx, casemodel.HopBenchClerkToStampReporter, args)
 
 	// Check the response status
 	if response.Status != 200 {
@@ -924,9 +989,15 @@ func (bc *BenchClerkContract) FetchAndStoreCaseFromStampReporterChannel(ctx cont
 	caseBytes := response.Payload
 
 	// Unmarshal the case data
-	var caseData Case
-	if err := json.Unmarshal(caseBytes, &caseData); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal case data: %v", err)
+	caseData, err := casemodel.DecodeCase(caseBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	// Only store the case in the state the stamp reporter handed it over in
+	if err := casemodel.VerifyHandoff(ctx, caseData, casemodel.OrgStampReporters); err != nil {
+		log.Printf("Case %s failed verification: %v", caseData.ID, err)
+		return nil, err
 	}
 
 	// Verify case status should be for BenchClerk
@@ -934,17 +1005,6 @@ func (bc *BenchClerkContract) FetchAndStoreCaseFromStampReporterChannel(ctx cont
 		return nil, fmt.Errorf("case %s is not currently assigned to BenchClerksOrg", caseID)
 	}
 
-	// Initialize empty arrays if they are null
-	if caseData.Documents == nil {
-		caseData.Documents = make([]Document, 0)
-	}
-	if caseData.History == nil {
-		caseData.History = make([]HistoryItem, 0)
-	}
-	if caseData.AssociatedLawyers == nil {
-		caseData.AssociatedLawyers = make([]string, 0)
-	}
-
 	// Get current timestamp
 	txTimestamp, err := ctx.GetStub().GetTxTimestamp()
 	if err != nil {
@@ -963,6 +1023,10 @@ func (bc *BenchClerkContract) FetchAndStoreCaseFromStampReporterChannel(ctx cont
 	// Update last modified timestamp
 	caseData.LastModified = timestamp
 
+	if err := casemodel.Seal(ctx, caseData); err != nil {
+		return nil, err
+	}
+
 	// Store the case in BenchClerk's ledger
 	updatedCaseBytes, err := json.Marshal(caseData)
 	if err != nil {
@@ -974,18 +1038,132 @@ func (bc *BenchClerkContract) FetchAndStoreCaseFromStampReporterChannel(ctx cont
 	}
 
 	log.Printf("Successfully fetched and stored case %s from StampReporter channel", caseID)
-	return &caseData, nil
+	return caseData, nil
 }
 
 // This is the correct implementation - removed duplicate declaration
 
-func main() {
-	benchClerkChaincode, err := contractapi.NewChaincode(&BenchClerkContract{})
+// GetAllowedTransitions returns the status changes the caller's organization can make on a case right now
+func (bc *BenchClerkContract) GetAllowedTransitions(ctx contractapi.TransactionContextInterface, caseID string) ([]casemodel.Transition, error) {
+	return casemodel.GetAllowedTransitions(ctx, caseID)
+}
+
+// VerifyCaseHistory recomputes the hash chain of a case's history and reports the first
+// entry that does not verify
+func (bc *BenchClerkContract) VerifyCaseHistory(ctx contractapi.TransactionContextInterface, caseID string) (*casemodel.HistoryVerification, error) {
+	return casemodel.VerifyCaseHistory(ctx, caseID)
+}
+
+// VerifyDocument checks whether a file with the hex SHA-256 contentHash is the one a
+// case document was uploaded with
+func (bc *BenchClerkContract) VerifyDocument(ctx contractapi.TransactionContextInterface, caseID string, documentID string, contentHash string) (*casemodel.DocumentVerification, error) {
+	if err := access.RequireStoredCaseView(ctx, caseID); err != nil {
+		return nil, err
+	}
+	return casemodel.VerifyDocument(ctx, caseID, documentID, contentHash)
+}
+
+// VerifyDocumentSignatures rechecks every signature on a case's documents
+func (bc *BenchClerkContract) VerifyDocumentSignatures(ctx contractapi.TransactionContextInterface, caseID string) (*casemodel.SignatureVerification, error) {
+	if err := access.RequireStoredCaseView(ctx, caseID); err != nil {
+		return nil, err
+	}
+	return casemodel.VerifyDocumentSignatures(ctx, caseID)
+}
+
+// GetCaseProvenance returns every committed version of a case on this channel with the
+// fields each transaction changed
+func (bc *BenchClerkContract) GetCaseProvenance(ctx contractapi.TransactionContextInterface, caseID string) (*casemodel.CaseProvenance, error) {
+	if err := access.RequireStoredCaseView(ctx, caseID); err != nil {
+		return nil, err
+	}
+	return casemodel.GetCaseProvenance(ctx, caseID)
+}
+
+// SetRoutingConfig replaces the chaincode and channel of each call this contract makes,
+// as stored by InitLedger. Only an administrator may change the routes.
+func (bc *BenchClerkContract) SetRoutingConfig(ctx contractapi.TransactionContextInterface, config string) error {
+	if err := access.RequireAdmin(ctx, "SetRoutingConfig"); err != nil {
+		return err
+	}
+	return casemodel.PutRouting(ctx, config, hops...)
+}
+
+// GetRoutingConfig returns the routing table this contract uses
+func (bc *BenchClerkContract) GetRoutingConfig(ctx contractapi.TransactionContextInterface) (*casemodel.RoutingConfig, error) {
+	return casemodel.GetRouting(ctx)
+}
+
+// SetOrgRoots replaces the root CA certificates trusted for an organization on this
+// channel, which signer certificates are checked against. Only an administrator of that
+// organization may change them.
+func (bc *BenchClerkContract) SetOrgRoots(ctx contractapi.TransactionContextInterface, org string, certificates string) error {
+	if err := access.RequireAdmin(ctx, "SetOrgRoots"); err != nil {
+		return err
+	}
+	return casemodel.PutOrgRoots(ctx, org, certificates)
+}
+
+// ImportOrgRoots copies the root CA certificates trusted for an organization that has not
+// joined this channel from the chaincode routed for hop, where that organization's
+// administrator stored them. Only an administrator may import them.
+func (bc *BenchClerkContract) ImportOrgRoots(ctx contractapi.TransactionContextInterface, org string, hop string) error {
+	if err := access.RequireAdmin(ctx, "ImportOrgRoots"); err != nil {
+		return err
+	}
+	return casemodel.ImportOrgRoots(ctx, org, hop)
+}
+
+// GetOrgRoots returns the PEM root CA certificates trusted for an organization on this
+// channel, or an empty string when none are
+func (bc *BenchClerkContract) GetOrgRoots(ctx contractapi.TransactionContextInterface, org string) (string, error) {
+	return casemodel.GetOrgRoots(ctx, org)
+}
+
+// ListPendingTransfers returns the cases handed to other organizations that they have
+// not claimed yet, with the sealed cases the caller may not see redacted
+func (bc *BenchClerkContract) ListPendingTransfers(ctx contractapi.TransactionContextInterface) ([]*casemodel.Transfer, error) {
+	transfers, err := casemodel.ListPendingTransfers(ctx)
 	if err != nil {
-		log.Panicf("Error creating bench clerk chaincode: %v", err)
+		return nil, err
 	}
+	return access.RedactSealedTransfers(ctx, transfers)
+}
 
-	if err := benchClerkChaincode.Start(); err != nil {
-		log.Panicf("Error starting bench clerk chaincode: %v", err)
+// ClaimTransfer acknowledges a pending transfer for the organization receiving the case
+// and returns it
+func (bc *BenchClerkContract) ClaimTransfer(ctx contractapi.TransactionContextInterface, sequence uint64) (*casemodel.Transfer, error) {
+	return casemodel.ClaimTransfer(ctx, sequence)
+}
+
+// RetryTransfer delivers a pending transfer again
+func (bc *BenchClerkContract) RetryTransfer(ctx contractapi.TransactionContextInterface, sequence uint64) (*casemodel.Transfer, error) {
+	t, err := casemodel.RetryTransfer(ctx, sequence)
+	if err != nil {
+		return nil, err
+	}
+	transfers, err := access.RedactSealedTransfers(ctx, []*casemodel.Transfer{t})
+	if err != nil {
+		return nil, err
+	}
+	return transfers[0], nil
+}
+
+// ReceiveTransfers claims the cases handed to the bench clerk on this channel that were not
+// delivered when they were sent, and stores them. It returns the IDs of the cases received.
+func (bc *BenchClerkContract) ReceiveTransfers(ctx contractapi.TransactionContextInterface) ([]string, error) {
+	store := func(caseJSON string) error {
+		return bc.StoreCase(ctx, caseJSON)
 	}
+	return casemodel.ReceiveTransfers(ctx, store, casemodel.HopBenchClerkToStampReporter, casemodel.HopBenchClerkToJudge)
+}
+
+// New returns the bench clerk contract with its access policy applied and the status changes
+// of each transaction emitted together once it succeeds
+func New() *BenchClerkContract {
+	contract := new(BenchClerkContract)
+	contract.BeforeTransaction = accessPolicy.BeforeTransaction
+	contract.TransactionContextHandler = new(casemodel.TransactionContext)
+	contract.AfterTransaction = casemodel.EmitStatusChanges
+	return contract
 }
@@ -0,0 +1,865 @@
+package chaincode
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/hyperledger/fabric-protos-go/peer"
+
+	"casemodel"
+	"casemodel/mockstub"
+	"casemodel/mockstub/contracttest"
+)
+
+const channel = "stampreporter-benchclerk-channel"
+
+var start = time.Date(2024, time.May, 15, 10, 0, 0, 0, time.UTC)
+
+// stamp is how the contract formats the timestamp of the first transaction
+var stamp = time.Unix(start.Unix(), 0).Format(time.RFC3339)
+
+var (
+	lawyer          = mockstub.Identity{MSPID: "LawyersOrgMSP", Name: "lawyer1", Attrs: map[string]string{"role": "lawyer", "lawyerId": "L001"}}
+	stampReporter   = mockstub.Identity{MSPID: "StampReportersOrgMSP", Name: "stampreporter1", Attrs: map[string]string{"role": "stampreporter"}}
+	benchClerk      = mockstub.Identity{MSPID: "BenchClerksOrgMSP", Name: "benchclerk1", Attrs: map[string]string{"role": "benchclerk"}}
+	benchClerkAdmin = mockstub.Identity{MSPID: "BenchClerksOrgMSP", Name: "benchclerkadmin", Attrs: map[string]string{"role": "benchclerk", "admin": "true"}}
+	judge           = mockstub.Identity{MSPID: "JudgesOrgMSP", Name: "judge1", Attrs: map[string]string{"role": "judge", "judgeId": "J001"}}
+)
+
+// contract is how every test deploys the bench clerk contract
+var contract = &contracttest.Contract{
+	Name:     "benchclerk",
+	Channels: []string{channel},
+	New:      func() contractapi.ContractInterface { return New() },
+	Start:    start,
+	Admin:    benchClerkAdmin,
+	Client:   benchClerk,
+	// the organizations that hand cases to the bench clerk
+	Trusted: []string{casemodel.OrgJudges, casemodel.OrgStampReporters},
+}
+
+// stored reads a case from the bench clerk's ledger
+func stored(t *testing.T, n *mockstub.Network, id string) *Case {
+	t.Helper()
+	value := n.GetState(channel, "benchclerk", id)
+	if value == nil {
+		t.Fatalf("case %s is not on the ledger", id)
+	}
+	c, err := casemodel.DecodeCase(value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+// ids lists the IDs of cases
+func ids(cases []*Case) string {
+	var list []string
+	for _, c := range cases {
+		list = append(list, c.ID)
+	}
+	return strings.Join(list, ",")
+}
+
+// lastHistory returns a case's latest history entry
+func lastHistory(c *Case) HistoryItem {
+	if len(c.History) == 0 {
+		return HistoryItem{}
+	}
+	return c.History[len(c.History)-1]
+}
+
+// seedJudges writes the judges InitLedger would
+func seedJudges(n *mockstub.Network) {
+	for _, j := range []Judge{{ID: "J001", Name: "Hon. Justice Patel", Division: "Civil"}, {ID: "J002", Name: "Hon. Justice Sharma", Division: "Criminal"}} {
+		n.PutState(channel, "benchclerk", "JUDGE_"+j.ID, contracttest.MustJSON(j))
+	}
+}
+
+func TestBenchClerkContract(t *testing.T) {
+	// routing moves one route to a new chaincode name and keeps the other defaults
+	routingConfig := casemodel.DefaultRouting()
+	routingConfig.Routes[casemodel.HopBenchClerkToJudge] = casemodel.Route{Chaincode: "judge-v2", Channel: casemodel.ChannelBenchClerkJudge}
+	routing := string(contracttest.MustJSON(routingConfig))
+
+	validated := contracttest.NewCase("CASE_001", casemodel.StatusValidatedByStampReporter, casemodel.OrgBenchClerks)
+	pendingJudge := contracttest.NewCase("CASE_002", casemodel.StatusPendingJudgeReview, casemodel.OrgJudges)
+	pendingJudge.AssociatedJudge = "J001"
+	issued := contracttest.NewCase("CASE_003", casemodel.StatusJudgmentIssued, casemodel.OrgBenchClerks)
+	issued.Decision = "Suit decreed"
+	received := contracttest.NewCase("CASE_004", casemodel.StatusJudgmentReceived, casemodel.OrgBenchClerks)
+	confirmed := contracttest.NewCase("CASE_005", casemodel.StatusDecisionConfirmed, casemodel.OrgLawyers)
+	scheduled := contracttest.NewCase("CASE_006", casemodel.StatusPendingJudgeReview, casemodel.OrgJudges)
+	scheduled.History = []HistoryItem{{Status: "HEARING_SCHEDULED", Organization: "BenchClerksOrg"}}
+	stillWithJudge := contracttest.NewCase("CASE_007", casemodel.StatusJudgmentIssued, casemodel.OrgJudges)
+	unsealed := contracttest.NewCase("CASE_008", casemodel.StatusJudgmentIssued, casemodel.OrgBenchClerks)
+	// inCamera is scheduled heard in camera, visible in full to judge J001 only
+	inCamera := *scheduled
+	inCamera.Sealed, inCamera.AccessList = true, []string{"judge:J001"}
+
+	// cases as the stamp reporter hands them over
+	fromStampReporter := contract.Sealed(validated, stampReporter, casemodel.ChannelStampReporterBenchClerk)
+
+	var sentToJudge, sentToLawyer []*Case
+
+	// transfers waiting in the stamp reporter's outbox
+	forBenchClerk := &casemodel.Transfer{Sequence: 2, CaseID: "CASE_001", Hop: casemodel.HopStampReporterToBenchClerk, Function: "StoreCase", From: casemodel.OrgStampReporters, To: casemodel.OrgBenchClerks, Case: fromStampReporter, Status: casemodel.TransferPending}
+	forLawyer := &casemodel.Transfer{Sequence: 1, CaseID: "CASE_005", To: casemodel.OrgLawyers, Case: confirmed, Status: casemodel.TransferPending}
+
+	// chained is issued as the contract stores it, with its history chained; rewritten has
+	// an entry edited afterwards
+	withHistory := *issued
+	withHistory.History = []HistoryItem{{Status: "CREATED", Comments: "filed"}, {Status: issued.Status, Comments: "recorded"}}
+	chained := contract.Sealed(&withHistory, benchClerk, channel)
+	rewritten := contract.Sealed(&withHistory, benchClerk, channel)
+	rewritten.History[0].Comments = "withdrawn"
+
+	tests := []contracttest.TxTest{
+		{
+			Name:     "InitLedger",
+			Caller:   benchClerkAdmin,
+			Function: "InitLedger",
+			Args:     []string{""},
+			Check: func(t *testing.T, n *mockstub.Network, _ []byte) {
+				for _, id := range []string{"JUDGE_J001", "JUDGE_J002", "JUDGE_J003"} {
+					if n.GetState(channel, "benchclerk", id) == nil {
+						t.Errorf("%s is not on the ledger", id)
+					}
+				}
+			},
+		},
+		{
+			Name:     "InitLedger by a bench clerk who is not an administrator",
+			Caller:   benchClerk,
+			Function: "InitLedger",
+			Args:     []string{""},
+			WantErr:  "access denied for InitLedger",
+		},
+		{
+			Name:     "SetRoutingConfig by a bench clerk who is not an administrator",
+			Caller:   benchClerk,
+			Function: "SetRoutingConfig",
+			Args:     []string{`{"routes":{}}`},
+			WantErr:  "access denied for SetRoutingConfig",
+		},
+		{
+			Name:     "SetOrgRoots by a bench clerk who is not an administrator",
+			Caller:   benchClerk,
+			Function: "SetOrgRoots",
+			Args:     []string{casemodel.OrgStampReporters, mockstub.RootCertificate("StampReportersOrgMSP")},
+			WantErr:  "access denied for SetOrgRoots",
+		},
+		{
+			Name:     "SetOrgRoots of an unknown organization",
+			Caller:   benchClerkAdmin,
+			Function: "SetOrgRoots",
+			Args:     []string{"CourtsOrg", mockstub.RootCertificate("StampReportersOrgMSP")},
+			WantErr:  `unknown organization "CourtsOrg"`,
+		},
+		{
+			Name:     "SetOrgRoots of another organization",
+			Caller:   benchClerkAdmin,
+			Function: "SetOrgRoots",
+			Args:     []string{casemodel.OrgJudges, mockstub.RootCertificate("JudgesOrgMSP")},
+			WantErr:  "a member of BenchClerksOrg cannot set the root certificates of JudgesOrg",
+		},
+		{
+			Name:     "ForwardToJudge",
+			Seed:     []*Case{validated},
+			Peers:    map[string]mockstub.ChaincodeFunc{"judge": contracttest.Recorder(&sentToJudge)},
+			Setup:    contract.Reroute(casemodel.HopBenchClerkToJudge, casemodel.Route{Chaincode: "judge"}),
+			Caller:   benchClerk,
+			Function: "ForwardToJudge",
+			Args:     []string{"CASE_001", `{"judgeId":"J002","comments":"Urgent"}`},
+			Check: func(t *testing.T, n *mockstub.Network, _ []byte) {
+				c := stored(t, n, "CASE_001")
+				if c.Status != casemodel.StatusPendingJudgeReview || c.CurrentOrg != casemodel.OrgJudges || c.AssociatedJudge != "J002" {
+					t.Errorf("case = %+v", c)
+				}
+				if got := lastHistory(c).Comments; got != "Case forwarded to judge (ID: J002). Urgent" {
+					t.Errorf("history comment = %q", got)
+				}
+				if len(sentToJudge) != 1 || sentToJudge[0].AssociatedJudge != "J002" {
+					t.Errorf("judge received %+v", sentToJudge)
+				}
+				if pending := contract.Outbox(t, n); len(pending) != 0 {
+					t.Errorf("pending transfers = %+v", pending)
+				}
+			},
+		},
+		{
+			Name: "ForwardToJudge fetches from stamp reporter",
+			Peers: map[string]mockstub.ChaincodeFunc{
+				"stampreporter": contracttest.Fake(map[string]peer.Response{"GetCaseById": shim.Success(contracttest.MustJSON(fromStampReporter))}),
+				"judge":         contracttest.Fake(map[string]peer.Response{"StoreCase": shim.Success(nil)}),
+			},
+			Caller:   benchClerk,
+			Function: "ForwardToJudge",
+			Args:     []string{"CASE_001", `{"judgeId":"J001"}`},
+			Check: func(t *testing.T, n *mockstub.Network, _ []byte) {
+				c := stored(t, n, "CASE_001")
+				if c.Status != casemodel.StatusPendingJudgeReview || len(c.History) != 2 || c.History[0].Status != "RECEIVED_BY_BENCHCLERK" {
+					t.Errorf("case = %+v", c)
+				}
+			},
+		},
+		{
+			Name:     "ForwardToJudge rejected by judge",
+			Seed:     []*Case{validated},
+			Peers:    map[string]mockstub.ChaincodeFunc{"judge": contracttest.Fake(map[string]peer.Response{"StoreCase": shim.Error("ledger unavailable")})},
+			Setup:    contract.Reroute(casemodel.HopBenchClerkToJudge, casemodel.Route{Chaincode: "judge"}),
+			Caller:   benchClerk,
+			Function: "ForwardToJudge",
+			Args:     []string{"CASE_001", `{"judgeId":"J001"}`},
+			Check: func(t *testing.T, n *mockstub.Network, _ []byte) {
+				if c := stored(t, n, "CASE_001"); c.Status != casemodel.StatusPendingJudgeReview {
+					t.Errorf("case is %s", c.Status)
+				}
+				pending := contract.Outbox(t, n)
+				if len(pending) != 1 || pending[0].To != casemodel.OrgJudges || pending[0].Attempts != 1 || pending[0].LastError != "ledger unavailable" {
+					t.Errorf("pending transfers = %+v", pending)
+				}
+			},
+		},
+		{
+			Name:     "ForwardToJudge on another channel",
+			Seed:     []*Case{validated},
+			Caller:   benchClerk,
+			Function: "ForwardToJudge",
+			Args:     []string{"CASE_001", `{"judgeId":"J001"}`},
+			Check: func(t *testing.T, n *mockstub.Network, _ []byte) {
+				pending := contract.Outbox(t, n)
+				if len(pending) != 1 || pending[0].Attempts != 0 || !strings.Contains(pending[0].LastError, casemodel.ChannelBenchClerkJudge) {
+					t.Errorf("pending transfers = %+v", pending)
+				}
+			},
+		},
+		{
+			Name:     "ForwardToJudge twice",
+			Seed:     []*Case{pendingJudge},
+			Caller:   benchClerk,
+			Function: "ForwardToJudge",
+			Args:     []string{"CASE_002", `{"judgeId":"J001"}`},
+			WantErr:  "cannot move from PENDING_JUDGE_REVIEW to PENDING_JUDGE_REVIEW",
+		},
+		{
+			Name:     "ForwardToJudge by judge",
+			Seed:     []*Case{validated},
+			Caller:   judge,
+			Function: "ForwardToJudge",
+			Args:     []string{"CASE_001", `{"judgeId":"J001"}`},
+			WantErr:  "access denied for ForwardToJudge",
+		},
+		{
+			Name:     "UpdateHearingDetails",
+			Seed:     []*Case{pendingJudge},
+			Caller:   benchClerk,
+			Function: "UpdateHearingDetails",
+			Args:     []string{"CASE_002", `{"hearingDate":"2024-06-01","comments":"Courtroom 4"}`},
+			Check: func(t *testing.T, n *mockstub.Network, _ []byte) {
+				h := lastHistory(stored(t, n, "CASE_002"))
+				if h.Status != "HEARING_SCHEDULED" || h.Comments != "Hearing scheduled for 2024-06-01. Courtroom 4" || h.Timestamp != stamp {
+					t.Errorf("history = %+v", h)
+				}
+			},
+		},
+		{
+			Name:     "UpdateHearingDetails missing case",
+			Peers:    map[string]mockstub.ChaincodeFunc{"stampreporter": contracttest.Fake(map[string]peer.Response{"GetCaseById": shim.Error("case does not exist: CASE_404")})},
+			Caller:   benchClerk,
+			Function: "UpdateHearingDetails",
+			Args:     []string{"CASE_404", `{"hearingDate":"2024-06-01"}`},
+			WantErr:  "failed to fetch case from StampReporter channel",
+		},
+		{
+			Name:     "NotifyLawyer",
+			Seed:     []*Case{pendingJudge},
+			Caller:   benchClerk,
+			Function: "NotifyLawyer",
+			Args:     []string{"CASE_002", `{"notificationType":"HEARING","message":"Hearing on 1 June"}`},
+			Check: func(t *testing.T, n *mockstub.Network, _ []byte) {
+				if h := lastHistory(stored(t, n, "CASE_002")); h.Status != "NOTIFICATION_HEARING" || h.Comments != "Hearing on 1 June" {
+					t.Errorf("history = %+v", h)
+				}
+			},
+		},
+		{
+			Name:     "NotifyLawyer missing case",
+			Caller:   benchClerk,
+			Function: "NotifyLawyer",
+			Args:     []string{"CASE_404", `{"notificationType":"HEARING"}`},
+			WantErr:  "case does not exist: CASE_404",
+		},
+		{
+			Name:     "GetCaseDetails",
+			Seed:     []*Case{validated},
+			Caller:   benchClerk,
+			Function: "GetCaseDetails",
+			Args:     []string{"CASE_001"},
+			Check: func(t *testing.T, _ *mockstub.Network, payload []byte) {
+				var c Case
+				contracttest.Decode(t, payload, &c)
+				if c.ID != "CASE_001" {
+					t.Errorf("case = %+v", c)
+				}
+			},
+		},
+		{
+			Name:     "GetCaseDetails missing case",
+			Caller:   benchClerk,
+			Function: "GetCaseDetails",
+			Args:     []string{"CASE_404"},
+			WantErr:  "case CASE_404 does not exist",
+		},
+		{
+			Name:     "GetAllCases",
+			Seed:     []*Case{validated, pendingJudge},
+			Caller:   benchClerk,
+			Function: "GetAllCases",
+			Check: func(t *testing.T, _ *mockstub.Network, payload []byte) {
+				var cases []*Case
+				contracttest.Decode(t, payload, &cases)
+				if got := ids(cases); got != "CASE_001,CASE_002" {
+					t.Errorf("cases = %s", got)
+				}
+			},
+		},
+		{
+			Name:     "GetAllCases sealed",
+			Seed:     []*Case{validated, &inCamera},
+			Caller:   benchClerk,
+			Function: "GetAllCases",
+			Check: func(t *testing.T, _ *mockstub.Network, payload []byte) {
+				var cases []*Case
+				contracttest.Decode(t, payload, &cases)
+				if ids(cases) != "CASE_001,CASE_006" || !cases[1].Sealed || len(cases[1].History) != 0 || cases[1].Title != "" {
+					t.Errorf("cases = %s", payload)
+				}
+			},
+		},
+		{
+			Name:     "GetCaseById sealed by a listed judge",
+			Seed:     []*Case{&inCamera},
+			Caller:   judge,
+			Function: "GetCaseById",
+			Args:     []string{"CASE_006"},
+			Check: func(t *testing.T, _ *mockstub.Network, payload []byte) {
+				var c Case
+				contracttest.Decode(t, payload, &c)
+				if len(c.History) != 1 {
+					t.Errorf("case = %s", payload)
+				}
+			},
+		},
+		{
+			Name:     "GetCaseById sealed by lawyer",
+			Seed:     []*Case{&inCamera},
+			Caller:   lawyer,
+			Function: "GetCaseById",
+			Args:     []string{"CASE_006"},
+			WantErr:  "case is sealed",
+		},
+		{
+			Name:     "GetAllJudges",
+			Setup:    seedJudges,
+			Caller:   benchClerk,
+			Function: "GetAllJudges",
+			Check: func(t *testing.T, _ *mockstub.Network, payload []byte) {
+				var judges []*Judge
+				contracttest.Decode(t, payload, &judges)
+				if len(judges) != 2 || judges[0].ID != "J001" || judges[1].Division != "Criminal" {
+					t.Errorf("judges = %s", payload)
+				}
+			},
+		},
+		{
+			Name:     "GetAllCasesWithPagination",
+			Seed:     []*Case{validated, pendingJudge, issued},
+			Caller:   benchClerk,
+			Function: "GetAllCasesWithPagination",
+			Args:     []string{"2", "CASE_001"},
+			Check: func(t *testing.T, _ *mockstub.Network, payload []byte) {
+				var page CasePage
+				contracttest.Decode(t, payload, &page)
+				if ids(page.Records) != "CASE_002,CASE_003" || page.FetchedCount != 2 || page.Bookmark != "CASE_003" {
+					t.Errorf("page = %+v", page)
+				}
+			},
+		},
+		{
+			Name:     "GetAllJudgesWithPagination",
+			Setup:    seedJudges,
+			Caller:   benchClerk,
+			Function: "GetAllJudgesWithPagination",
+			Args:     []string{"1", ""},
+			Check: func(t *testing.T, _ *mockstub.Network, payload []byte) {
+				var page JudgePage
+				contracttest.Decode(t, payload, &page)
+				if len(page.Records) != 1 || page.Records[0].ID != "J001" || page.Bookmark != "JUDGE_J001" {
+					t.Errorf("page = %s", payload)
+				}
+			},
+		},
+		{
+			Name:     "GetAllJudgesWithPagination invalid page size",
+			Caller:   benchClerk,
+			Function: "GetAllJudgesWithPagination",
+			Args:     []string{"0", ""},
+			WantErr:  "page size",
+		},
+		{
+			Name:     "ConfirmJudgeDecision",
+			Seed:     []*Case{issued},
+			Caller:   benchClerk,
+			Function: "ConfirmJudgeDecision",
+			Args:     []string{"CASE_003"},
+			Check: func(t *testing.T, n *mockstub.Network, _ []byte) {
+				c := stored(t, n, "CASE_003")
+				if c.Status != casemodel.StatusDecisionConfirmed || c.CurrentOrg != casemodel.OrgLawyers {
+					t.Errorf("case is %s at %s", c.Status, c.CurrentOrg)
+				}
+				if len(c.History) != 2 || c.History[0].Status != "DECISION_CONFIRMED" || c.History[1].Comments != "Decision confirmed and forwarded to associated lawyers: L001" {
+					t.Errorf("history = %+v", c.History)
+				}
+			},
+		},
+		{
+			Name:     "ConfirmJudgeDecision without judgment",
+			Seed:     []*Case{pendingJudge},
+			Caller:   benchClerk,
+			Function: "ConfirmJudgeDecision",
+			Args:     []string{"CASE_002"},
+			WantErr:  "case CASE_002 has no judgment to confirm, status must be JUDGMENT_ISSUED",
+		},
+		{
+			Name:     "QueryCasesByStatus",
+			Seed:     []*Case{validated, pendingJudge, scheduled},
+			Caller:   benchClerk,
+			Function: "QueryCasesByStatus",
+			Args:     []string{casemodel.StatusPendingJudgeReview},
+			Check: func(t *testing.T, _ *mockstub.Network, payload []byte) {
+				var cases []*Case
+				contracttest.Decode(t, payload, &cases)
+				if got := ids(cases); got != "CASE_002,CASE_006" {
+					t.Errorf("cases = %s", got)
+				}
+			},
+		},
+		{
+			Name:     "QueryCasesByStatusWithPagination",
+			Seed:     []*Case{validated, pendingJudge, scheduled},
+			Caller:   benchClerk,
+			Function: "QueryCasesByStatusWithPagination",
+			Args:     []string{casemodel.StatusPendingJudgeReview, "1", ""},
+			Check: func(t *testing.T, _ *mockstub.Network, payload []byte) {
+				var page CasePage
+				contracttest.Decode(t, payload, &page)
+				if ids(page.Records) != "CASE_002" || page.Bookmark != "CASE_002" {
+					t.Errorf("page = %+v", page)
+				}
+			},
+		},
+		{
+			Name:     "GetCaseById by lawyer",
+			Seed:     []*Case{confirmed},
+			Caller:   lawyer,
+			Function: "GetCaseById",
+			Args:     []string{"CASE_005"},
+			Check: func(t *testing.T, _ *mockstub.Network, payload []byte) {
+				var c Case
+				contracttest.Decode(t, payload, &c)
+				if c.ID != "CASE_005" || c.Status != casemodel.StatusDecisionConfirmed {
+					t.Errorf("case = %+v", c)
+				}
+			},
+		},
+		{
+			Name:     "GetCaseById fetches from stamp reporter",
+			Peers:    map[string]mockstub.ChaincodeFunc{"stampreporter": contracttest.Fake(map[string]peer.Response{"GetCaseById": shim.Success(contracttest.MustJSON(fromStampReporter))})},
+			Caller:   benchClerk,
+			Function: "GetCaseById",
+			Args:     []string{"CASE_001"},
+			Check: func(t *testing.T, n *mockstub.Network, _ []byte) {
+				if h := lastHistory(stored(t, n, "CASE_001")); h.Status != "RECEIVED_BY_BENCHCLERK" {
+					t.Errorf("history = %+v", h)
+				}
+			},
+		},
+		{
+			Name:     "QueryStats",
+			Seed:     []*Case{validated, pendingJudge, scheduled, confirmed},
+			Caller:   benchClerk,
+			Function: "QueryStats",
+			Check: func(t *testing.T, _ *mockstub.Network, payload []byte) {
+				if want := `{"pendingCases":1,"forwardedToJudge":2,"hearingsScheduled":1,"decisionsConfirmed":1}`; string(payload) != want {
+					t.Errorf("QueryStats = %s, want %s", payload, want)
+				}
+			},
+		},
+		{
+			Name:     "QueryStats with a sealed case",
+			Seed:     []*Case{validated, &inCamera},
+			Caller:   benchClerk,
+			Function: "QueryStats",
+			Check: func(t *testing.T, _ *mockstub.Network, payload []byte) {
+				if want := `{"pendingCases":1,"forwardedToJudge":1,"hearingsScheduled":0,"decisionsConfirmed":0}`; string(payload) != want {
+					t.Errorf("QueryStats = %s, want %s", payload, want)
+				}
+			},
+		},
+		{
+			Name:     "StoreCase by stamp reporter",
+			Caller:   stampReporter,
+			Function: "StoreCase",
+			Args:     []string{string(contracttest.MustJSON(fromStampReporter))},
+			Check: func(t *testing.T, n *mockstub.Network, _ []byte) {
+				stored(t, n, "CASE_001")
+			},
+		},
+		{
+			Name:     "StoreCase by judge",
+			Caller:   judge,
+			Function: "StoreCase",
+			Args:     []string{string(contracttest.MustJSON(contract.Sealed(issued, judge, casemodel.ChannelBenchClerkJudge)))},
+			Check: func(t *testing.T, n *mockstub.Network, _ []byte) {
+				if c := stored(t, n, "CASE_003"); c.Decision != "Suit decreed" {
+					t.Errorf("case = %+v", c)
+				}
+			},
+		},
+		{
+			Name:     "StoreCase not handed over",
+			Caller:   stampReporter,
+			Function: "StoreCase",
+			Args:     []string{string(contracttest.MustJSON(validated))},
+			WantErr:  "case CASE_001 has no hash chain",
+		},
+		{
+			Name:     "StoreCase sealed by another organization",
+			Caller:   stampReporter,
+			Function: "StoreCase",
+			Args:     []string{string(contracttest.MustJSON(contract.Sealed(validated, lawyer, casemodel.ChannelStampReporterBenchClerk)))},
+			WantErr:  "case CASE_001 was last stored by LawyersOrg, not StampReportersOrg",
+		},
+		{
+			Name:     "StoreCase behind the stored copy",
+			Seed:     []*Case{contracttest.NewCase("CASE_001", casemodel.StatusDecisionConfirmed, casemodel.OrgLawyers)},
+			Caller:   stampReporter,
+			Function: "StoreCase",
+			Args:     []string{string(contracttest.MustJSON(fromStampReporter))},
+			WantErr:  "case CASE_001 cannot move from DECISION_CONFIRMED to VALIDATED_BY_STAMP_REPORTER",
+		},
+		{
+			Name:     "StoreCase by bench clerk",
+			Caller:   benchClerk,
+			Function: "StoreCase",
+			Args:     []string{string(contracttest.MustJSON(fromStampReporter))},
+			WantErr:  "access denied for StoreCase",
+		},
+		{
+			Name:     "StoreCase without ID",
+			Caller:   stampReporter,
+			Function: "StoreCase",
+			Args:     []string{`{"title":"Untitled"}`},
+			WantErr:  "case ID is required",
+		},
+		{
+			Name:     "StoreCase by lawyer",
+			Caller:   lawyer,
+			Function: "StoreCase",
+			Args:     []string{string(contracttest.MustJSON(validated))},
+			WantErr:  "access denied for StoreCase",
+		},
+		{
+			Name:     "ForwardCaseToLawyer",
+			Seed:     []*Case{received},
+			Peers:    map[string]mockstub.ChaincodeFunc{"lawyer": contracttest.Recorder(&sentToLawyer)},
+			Setup:    contract.Reroute(casemodel.HopBenchClerkToLawyer, casemodel.Route{Chaincode: "lawyer"}),
+			Caller:   benchClerk,
+			Function: "ForwardCaseToLawyer",
+			Args:     []string{"CASE_004"},
+			Check: func(t *testing.T, n *mockstub.Network, _ []byte) {
+				c := stored(t, n, "CASE_004")
+				if c.Status != casemodel.StatusDecisionConfirmed || c.CurrentOrg != casemodel.OrgLawyers || lastHistory(c).Status != "DECISION_CONFIRMED" {
+					t.Errorf("case = %+v", c)
+				}
+				if len(sentToLawyer) != 1 || sentToLawyer[0].Status != casemodel.StatusDecisionConfirmed {
+					t.Errorf("lawyer received %+v", sentToLawyer)
+				}
+			},
+		},
+		{
+			Name:     "ForwardCaseToLawyer rejected by lawyer",
+			Seed:     []*Case{received},
+			Peers:    map[string]mockstub.ChaincodeFunc{"lawyer": contracttest.Fake(nil)},
+			Setup:    contract.Reroute(casemodel.HopBenchClerkToLawyer, casemodel.Route{Chaincode: "lawyer"}),
+			Caller:   benchClerk,
+			Function: "ForwardCaseToLawyer",
+			Args:     []string{"CASE_004"},
+			Check: func(t *testing.T, n *mockstub.Network, _ []byte) {
+				pending := contract.Outbox(t, n)
+				if len(pending) != 1 || pending[0].To != casemodel.OrgLawyers || pending[0].LastError != "Function StoreCase not found" {
+					t.Errorf("pending transfers = %+v", pending)
+				}
+			},
+		},
+		{
+			Name: "ClaimTransfer by judge outside ReceiveTransfers",
+			Seed: []*Case{validated},
+			Setup: func(n *mockstub.Network) {
+				if _, err := n.Submit(channel, "benchclerk", benchClerk, "ForwardToJudge", "CASE_001", `{"judgeId":"J001"}`); err != nil {
+					panic(err)
+				}
+			},
+			Caller:   judge,
+			Function: "ClaimTransfer",
+			Args:     []string{"1"},
+			WantErr:  "transfer 1 can only be claimed by ReceiveTransfers of judge, not ClaimTransfer of benchclerk",
+		},
+		{
+			Name:     "ClaimTransfer by stamp reporter",
+			Caller:   stampReporter,
+			Function: "ClaimTransfer",
+			Args:     []string{"1"},
+			WantErr:  "access denied for ClaimTransfer",
+		},
+		{
+			Name:  "RetryTransfer claimed transfer",
+			Seed:  []*Case{validated},
+			Peers: map[string]mockstub.ChaincodeFunc{"judge": contracttest.Fake(map[string]peer.Response{"StoreCase": shim.Success(nil)})},
+			Setup: func(n *mockstub.Network) {
+				contract.Reroute(casemodel.HopBenchClerkToJudge, casemodel.Route{Chaincode: "judge"})(n)
+				if _, err := n.Submit(channel, "benchclerk", benchClerk, "ForwardToJudge", "CASE_001", `{"judgeId":"J001"}`); err != nil {
+					panic(err)
+				}
+			},
+			Caller:   benchClerk,
+			Function: "RetryTransfer",
+			Args:     []string{"1"},
+			WantErr:  "transfer 1 was already claimed by JudgesOrg",
+		},
+		{
+			Name:     "ReceiveTransfers",
+			Peers:    map[string]mockstub.ChaincodeFunc{"stampreporter": contracttest.Fake(map[string]peer.Response{"ListPendingTransfers": shim.Success(contracttest.MustJSON([]*casemodel.Transfer{forLawyer, forBenchClerk})), "ClaimTransfer": shim.Success(contracttest.MustJSON(forBenchClerk))})},
+			Caller:   benchClerk,
+			Function: "ReceiveTransfers",
+			Check: func(t *testing.T, n *mockstub.Network, payload []byte) {
+				// the judge's outbox is on the bench clerk-judge channel, so only the stamp reporter's is read
+				if string(payload) != `["CASE_001"]` {
+					t.Errorf("received = %s", payload)
+				}
+				if c := stored(t, n, "CASE_001"); c.Status != casemodel.StatusValidatedByStampReporter {
+					t.Errorf("case = %+v", c)
+				}
+			},
+		},
+		{
+			Name:     "ReceiveTransfers by judge",
+			Caller:   judge,
+			Function: "ReceiveTransfers",
+			WantErr:  "access denied for ReceiveTransfers",
+		},
+		{
+			Name:     "ForwardCaseToLawyer before judgment is received",
+			Seed:     []*Case{issued},
+			Caller:   benchClerk,
+			Function: "ForwardCaseToLawyer",
+			Args:     []string{"CASE_003"},
+			WantErr:  "case status must be JUDGMENT_RECEIVED for forwarding to Lawyer, current status: JUDGMENT_ISSUED",
+		},
+		{
+			Name: "FetchAndStoreCaseFromJudgeChannel",
+			Peers: map[string]mockstub.ChaincodeFunc{"judge": contracttest.Fake(map[string]peer.Response{
+				"GetJudgedCases": shim.Success(contracttest.MustJSON([]*Case{contract.Sealed(issued, judge, casemodel.ChannelBenchClerkJudge), stillWithJudge, unsealed})),
+			})},
+			Caller:   benchClerk,
+			Function: "FetchAndStoreCaseFromJudgeChannel",
+			Check: func(t *testing.T, n *mockstub.Network, _ []byte) {
+				c := stored(t, n, "CASE_003")
+				if c.Status != casemodel.StatusJudgmentReceived || lastHistory(c).Comments != "Judgment received from Judge: Suit decreed" {
+					t.Errorf("case = %+v", c)
+				}
+				if n.GetState(channel, "benchclerk", "CASE_007") != nil {
+					t.Error("case still held by the judge was stored")
+				}
+				if n.GetState(channel, "benchclerk", "CASE_008") != nil {
+					t.Error("case without a hash chain was stored")
+				}
+			},
+		},
+		{
+			Name:     "FetchAndStoreCaseFromJudgeChannel nothing judged",
+			Peers:    map[string]mockstub.ChaincodeFunc{"judge": contracttest.Fake(map[string]peer.Response{"GetJudgedCases": shim.Success([]byte("[]"))})},
+			Caller:   benchClerk,
+			Function: "FetchAndStoreCaseFromJudgeChannel",
+		},
+		{
+			Name:     "FetchAndStoreCaseFromJudgeChannel judge unavailable",
+			Peers:    map[string]mockstub.ChaincodeFunc{"judge": contracttest.Fake(nil)},
+			Caller:   benchClerk,
+			Function: "FetchAndStoreCaseFromJudgeChannel",
+			WantErr:  "Failed to fetch judged cases from Judge: Function GetJudgedCases not found",
+		},
+		{
+			Name:     "FetchAndStoreCaseFromStampReporterChannel",
+			Peers:    map[string]mockstub.ChaincodeFunc{"stampreporter": contracttest.Fake(map[string]peer.Response{"GetCaseById": shim.Success(contracttest.MustJSON(fromStampReporter))})},
+			Caller:   benchClerk,
+			Function: "FetchAndStoreCaseFromStampReporterChannel",
+			Args:     []string{"CASE_001"},
+			Check: func(t *testing.T, n *mockstub.Network, payload []byte) {
+				var c Case
+				contracttest.Decode(t, payload, &c)
+				if c.ID != "CASE_001" || lastHistory(&c).Status != "RECEIVED_BY_BENCHCLERK" || c.LastModified != stamp {
+					t.Errorf("case = %+v", c)
+				}
+				stored(t, n, "CASE_001")
+			},
+		},
+		{
+			Name:     "FetchAndStoreCaseFromStampReporterChannel not yet validated",
+			Peers:    map[string]mockstub.ChaincodeFunc{"stampreporter": contracttest.Fake(map[string]peer.Response{"GetCaseById": shim.Success(contracttest.MustJSON(contract.Sealed(confirmed, stampReporter, casemodel.ChannelStampReporterBenchClerk)))})},
+			Caller:   benchClerk,
+			Function: "FetchAndStoreCaseFromStampReporterChannel",
+			Args:     []string{"CASE_005"},
+			WantErr:  "case CASE_005 is not currently assigned to BenchClerksOrg",
+		},
+		{
+			Name:     "FetchAndStoreCaseFromStampReporterChannel sealed by another org",
+			Peers:    map[string]mockstub.ChaincodeFunc{"stampreporter": contracttest.Fake(map[string]peer.Response{"GetCaseById": shim.Success(contracttest.MustJSON(contract.Sealed(validated, judge, casemodel.ChannelStampReporterBenchClerk)))})},
+			Caller:   benchClerk,
+			Function: "FetchAndStoreCaseFromStampReporterChannel",
+			Args:     []string{"CASE_001"},
+			WantErr:  "case CASE_001 was last stored by JudgesOrg, not StampReportersOrg",
+		},
+		{
+			Name:     "FetchAndStoreCaseFromStampReporterChannel missing case",
+			Peers:    map[string]mockstub.ChaincodeFunc{"stampreporter": contracttest.Fake(map[string]peer.Response{"GetCaseById": shim.Error("case does not exist: CASE_404")})},
+			Caller:   benchClerk,
+			Function: "FetchAndStoreCaseFromStampReporterChannel",
+			Args:     []string{"CASE_404"},
+			WantErr:  "Failed to fetch case from StampReporter channel: case does not exist: CASE_404",
+		},
+		{
+			Name:     "GetAllowedTransitions",
+			Seed:     []*Case{issued},
+			Caller:   benchClerk,
+			Function: "GetAllowedTransitions",
+			Args:     []string{"CASE_003"},
+			Check: func(t *testing.T, _ *mockstub.Network, payload []byte) {
+				var transitions []casemodel.Transition
+				contracttest.Decode(t, payload, &transitions)
+				if len(transitions) != 2 {
+					t.Errorf("transitions = %+v", transitions)
+				}
+			},
+		},
+		{
+			Name:     "VerifyCaseHistory",
+			Seed:     []*Case{chained},
+			Caller:   benchClerk,
+			Function: "VerifyCaseHistory",
+			Args:     []string{"CASE_003"},
+			Check: func(t *testing.T, _ *mockstub.Network, payload []byte) {
+				var v casemodel.HistoryVerification
+				contracttest.Decode(t, payload, &v)
+				if !v.Valid || v.Chained != 2 || v.BrokenAt != -1 {
+					t.Errorf("verification = %+v", v)
+				}
+			},
+		},
+		{
+			Name:     "VerifyCaseHistory edited entry",
+			Seed:     []*Case{rewritten},
+			Caller:   benchClerk,
+			Function: "VerifyCaseHistory",
+			Args:     []string{"CASE_003"},
+			Check: func(t *testing.T, _ *mockstub.Network, payload []byte) {
+				var v casemodel.HistoryVerification
+				contracttest.Decode(t, payload, &v)
+				if v.Valid || v.BrokenAt != 0 || v.Reason != "entry does not match its hash" {
+					t.Errorf("verification = %+v", v)
+				}
+			},
+		},
+		{
+			Name: "GetCaseProvenance",
+			Seed: []*Case{pendingJudge},
+			Setup: func(n *mockstub.Network) {
+				if _, err := n.Submit(channel, "benchclerk", benchClerk, "UpdateHearingDetails", "CASE_002", `{"hearingDate":"2024-06-01","comments":"Courtroom 4"}`); err != nil {
+					panic(err)
+				}
+				if _, err := n.Submit(channel, "benchclerk", benchClerk, "NotifyLawyer", "CASE_002", `{"notificationType":"HEARING","message":"Hearing on 1 June"}`); err != nil {
+					panic(err)
+				}
+			},
+			Caller:   benchClerk,
+			Function: "GetCaseProvenance",
+			Args:     []string{"CASE_002"},
+			Check: func(t *testing.T, _ *mockstub.Network, payload []byte) {
+				// seeding bypasses the ledger history, so only the two transactions are in it
+				var provenance casemodel.CaseProvenance
+				contracttest.Decode(t, payload, &provenance)
+				if len(provenance.Versions) != 2 {
+					t.Fatalf("versions = %+v", provenance.Versions)
+				}
+				if v := provenance.Versions[1]; !strings.Contains(contracttest.Changed(v, "history[1]").After, "NOTIFICATION_HEARING") {
+					t.Errorf("changes = %+v", v.Changes)
+				}
+			},
+		},
+		{
+			Name:     "GetCaseProvenance missing case",
+			Caller:   benchClerk,
+			Function: "GetCaseProvenance",
+			Args:     []string{"CASE_404"},
+			WantErr:  "case does not exist: CASE_404",
+		},
+		{
+			Name:     "GetRoutingConfig defaults",
+			Caller:   benchClerk,
+			Function: "GetRoutingConfig",
+			Check: func(t *testing.T, _ *mockstub.Network, payload []byte) {
+				var config casemodel.RoutingConfig
+				contracttest.Decode(t, payload, &config)
+				if route := config.Routes[casemodel.HopBenchClerkToJudge]; route != (casemodel.Route{Chaincode: "judge", Channel: casemodel.ChannelBenchClerkJudge}) {
+					t.Errorf("route = %+v", route)
+				}
+			},
+		},
+		{
+			Name:     "SetRoutingConfig",
+			Caller:   benchClerkAdmin,
+			Function: "SetRoutingConfig",
+			Args:     []string{routing},
+			Check: func(t *testing.T, n *mockstub.Network, _ []byte) {
+				payload, err := n.Evaluate(channel, "benchclerk", benchClerk, "GetRoutingConfig")
+				if err != nil {
+					t.Fatal(err)
+				}
+				var config casemodel.RoutingConfig
+				contracttest.Decode(t, payload, &config)
+				if route := config.Routes[casemodel.HopBenchClerkToJudge]; route.Chaincode != "judge-v2" {
+					t.Errorf("route = %+v", route)
+				}
+			},
+		},
+		{
+			Name:     "SetRoutingConfig without a route the contract uses",
+			Caller:   benchClerkAdmin,
+			Function: "SetRoutingConfig",
+			Args:     []string{`{"routes":{}}`},
+			WantErr:  "invalid routing config: route benchclerk->stampreporter is not configured",
+		},
+		{
+			Name:     "SetRoutingConfig by another organization",
+			Caller:   judge,
+			Function: "SetRoutingConfig",
+			Args:     []string{routing},
+			WantErr:  "access denied for SetRoutingConfig",
+		},
+	}
+	contract.Run(t, tests)
+}
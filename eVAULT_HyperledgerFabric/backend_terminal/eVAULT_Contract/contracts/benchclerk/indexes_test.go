@@ -0,0 +1,17 @@
+package main
+
+import (
+	"testing"
+
+	"casemodel/indexcheck"
+)
+
+func TestCouchDBIndexesCoverSelectors(t *testing.T) {
+	problems, err := indexcheck.Check(".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, problem := range problems {
+		t.Error(problem)
+	}
+}
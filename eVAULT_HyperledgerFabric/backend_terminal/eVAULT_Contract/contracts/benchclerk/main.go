@@ -0,0 +1,20 @@
+package main
+
+import (
+	"log"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+
+	"benchclerk/chaincode"
+)
+
+func main() {
+	benchClerkChaincode, err := contractapi.NewChaincode(chaincode.New())
+	if err != nil {
+		log.Panicf("Error creating bench clerk chaincode: %v", err)
+	}
+
+	if err := benchClerkChaincode.Start(); err != nil {
+		log.Panicf("Error starting bench clerk chaincode: %v", err)
+	}
+}
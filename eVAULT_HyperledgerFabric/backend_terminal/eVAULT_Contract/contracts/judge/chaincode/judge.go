@@ -0,0 +1,926 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+
+	"casemodel"
+	"casemodel/access"
+	"casemodel/query"
+)
+
+// The case record is shared by every eVAULT contract so that no field is lost as a
+// case moves between channels
+type (
+	Case              = casemodel.Case
+	Document          = casemodel.Document
+	DocumentSignature = casemodel.DocumentSignature
+	HistoryItem       = casemodel.HistoryItem
+	Hearing           = casemodel.Hearing
+	Judgment          = casemodel.Judgment
+	CasePage          = casemodel.CasePage
+)
+
+// JudgeContract provides functions for managing judge activities
+type JudgeContract struct {
+	contractapi.Contract
+}
+
+// accessPolicy lists the roles allowed to call each transaction. Roles from other
+// organizations appear where a transaction is invoked across channels.
+var accessPolicy = access.Policy{
+	"InitLedger":                             {access.RoleJudge},
+	"RecordJudgment":                         {access.RoleJudge},
+	"AddHearingNotes":                        {access.RoleJudge},
+	"SealCase":                               {access.RoleJudge},
+	"UnsealCase":                             {access.RoleJudge},
+	"StoreCase":                              {access.RoleBenchClerk}, // written by the bench clerk when assigning a judge
+	"GetCaseById":                            {access.RoleJudge},
+	"QueryStats":                             {access.RoleJudge},
+	"ForwardCaseToBenchClerk":                {access.RoleJudge},
+	"FetchAndStoreCaseFromBenchClerkChannel": {access.RoleJudge},
+	"GetJudgedCases":                         {access.RoleJudge, access.RoleBenchClerk}, // read by the bench clerk across channels
+	"GetJudgedCasesWithPagination":           {access.RoleJudge},
+	"GetAllowedTransitions":                  {access.RoleJudge},
+	"VerifyCaseHistory":                      {access.RoleJudge},
+	"VerifyDocument":                         {access.RoleJudge},
+	"VerifyDocumentSignatures":               {access.RoleJudge},
+	"GetCaseProvenance":                      {access.RoleJudge},
+	"GetCasePrivateDetails":                  {access.RoleJudge},
+	"SetRoutingConfig":                       {access.RoleJudge},
+	"GetRoutingConfig":                       {access.RoleJudge},
+	"SetOrgRoots":                            {access.RoleLawyer, access.RoleRegistrar, access.RoleStampReporter, access.RoleBenchClerk, access.RoleJudge},
+	"ImportOrgRoots":                         {access.RoleJudge},
+	"GetOrgRoots":                            {access.RoleLawyer, access.RoleRegistrar, access.RoleStampReporter, access.RoleBenchClerk, access.RoleJudge},
+	"ListPendingTransfers":                   {access.RoleJudge, access.RoleBenchClerk}, // read by the bench clerk when claiming transfers
+	"ClaimTransfer":                          {access.RoleBenchClerk},                   // invoked through ReceiveTransfers of the bench clerk only
+	"RetryTransfer":                          {access.RoleJudge},
+	"ReceiveTransfers":                       {access.RoleJudge},
+}
+
+// hops are the calls this contract makes to other chaincodes. A stored routing table
+// must route all of them.
+var hops = []string{
+	casemodel.HopJudgeToBenchClerk,
+}
+
+// InitLedger initializes the ledger with the routing table of the calls this contract
+// makes, or the default routes when routingConfig is empty. Run it as the init
+// transaction on every channel the contract is deployed on.
+func (s *JudgeContract) InitLedger(ctx contractapi.TransactionContextInterface, routingConfig string) error {
+	if err := access.RequireAdmin(ctx, "InitLedger"); err != nil {
+		return err
+	}
+	return casemodel.InitRouting(ctx, routingConfig, hops...)
+}
+
+// RecordJudgment records the final judgment for a case
+func (s *JudgeContract) RecordJudgment(ctx contractapi.TransactionContextInterface, caseID string, judgmentDetails string) error {
+	log.Printf("RecordJudgment called for case ID: %s", caseID)
+
+	// Get the case
+	caseJSON, err := ctx.GetStub().GetState(caseID)
+	if err != nil {
+		log.Printf("Failed to read case: %v", err)
+		return fmt.Errorf("failed to read case: %v", err)
+	}
+
+	var caseObj Case
+	if caseJSON == nil {
+		log.Printf("Case does not exist locally: %s. Trying to fetch from BenchClerk channel", caseID)
+		// Try to fetch the case from BenchClerk channel
+		fetchedCase, err := s.FetchAndStoreCaseFromBenchClerkChannel(ctx, caseID)
+		if err != nil {
+			log.Printf("Failed to fetch case from BenchClerk channel: %v", err)
+			return fmt.Errorf("failed to fetch case from BenchClerk channel: %v", err)
+		}
+		caseObj = *fetchedCase
+	} else {
+		err = json.Unmarshal(caseJSON, &caseObj)
+	}
+	if err != nil {
+		log.Printf("Failed to unmarshal case data: %v", err)
+		return err
+	}
+
+	// Initialize empty arrays if they are null
+	if caseObj.Documents == nil {
+		caseObj.Documents = make([]Document, 0)
+	}
+	if caseObj.History == nil {
+		caseObj.History = make([]HistoryItem, 0)
+	}
+	if caseObj.AssociatedLawyers == nil {
+		caseObj.AssociatedLawyers = make([]string, 0)
+	}
+	if caseObj.Hearings == nil {
+		caseObj.Hearings = make([]Hearing, 0)
+	}
+
+	// Only the judge assigned to the case may record its judgment
+	if err := access.RequireAssignedJudge(ctx, &caseObj); err != nil {
+		return err
+	}
+
+	// Parse judgment details
+	var details struct {
+		Decision  string `json:"decision"`
+		Reasoning string `json:"reasoning"`
+		JudgeID   string `json:"judgeId"`
+	}
+	err = json.Unmarshal([]byte(judgmentDetails), &details)
+	if err != nil {
+		log.Printf("Failed to unmarshal judgment details: %v", err)
+		return err
+	}
+	if details.JudgeID != "" && details.JudgeID != caseObj.AssociatedJudge {
+		return fmt.Errorf("judgment judgeId %s does not match the assigned judge %s", details.JudgeID, caseObj.AssociatedJudge)
+	}
+	details.JudgeID = caseObj.AssociatedJudge
+
+	// Get current timestamp
+	txTimestamp, err := ctx.GetStub().GetTxTimestamp()
+	if err != nil {
+		log.Printf("Failed to get transaction timestamp: %v", err)
+		return fmt.Errorf("failed to get transaction timestamp: %v", err)
+	}
+	timestamp := time.Unix(txTimestamp.Seconds, 0).Format(time.RFC3339)
+
+	// Create and add judgment
+	caseObj.Judgment = &Judgment{
+		Decision:  details.Decision,
+		Reasoning: details.Reasoning,
+		Date:      timestamp,
+		JudgeID:   details.JudgeID,
+		IssuedAt:  timestamp,
+		Status:    "FINAL",
+	}
+
+	// Update case status
+	if err := casemodel.ApplyTransition(ctx, &caseObj, casemodel.StatusJudgmentIssued); err != nil {
+		return err
+	}
+
+	// Add to history
+	caseObj.History = append(caseObj.History, HistoryItem{
+		Status:       "JUDGMENT_ISSUED",
+		Organization: "JudgesOrg",
+		Timestamp:    timestamp,
+		Comments:     fmt.Sprintf("Final judgment issued by Judge %s", details.JudgeID),
+	})
+
+	// Save updated case
+	caseObj.LastModified = timestamp
+	if err := casemodel.Seal(ctx, &caseObj); err != nil {
+		return err
+	}
+	caseJSON, err = json.Marshal(caseObj)
+	if err != nil {
+		log.Printf("Failed to marshal updated case: %v", err)
+		return err
+	}
+
+	log.Printf("Successfully recorded judgment for case ID: %s", caseID)
+	return ctx.GetStub().PutState(caseID, caseJSON)
+}
+
+// AddHearingNotes adds notes for a case hearing
+func (s *JudgeContract) AddHearingNotes(ctx contractapi.TransactionContextInterface, caseID string, hearingDetails string) error {
+	log.Printf("AddHearingNotes called for case ID: %s", caseID)
+
+	// Get the case
+	caseJSON, err := ctx.GetStub().GetState(caseID)
+	if err != nil {
+		log.Printf("Failed to read case: %v", err)
+		return fmt.Errorf("failed to read case: %v", err)
+	}
+
+	var caseObj Case
+	if caseJSON == nil {
+		log.Printf("Case does not exist locally: %s. Trying to fetch from BenchClerk channel", caseID)
+		// Try to fetch the case from BenchClerk channel
+		fetchedCase, err := s.FetchAndStoreCaseFromBenchClerkChannel(ctx, caseID)
+		if err != nil {
+			log.Printf("Failed to fetch case from BenchClerk channel: %v", err)
+			return fmt.Errorf("failed to fetch case from BenchClerk channel: %v", err)
+		}
+		caseObj = *fetchedCase
+	} else {
+		err = json.Unmarshal(caseJSON, &caseObj)
+	}
+	if err != nil {
+		log.Printf("Failed to unmarshal case data: %v", err)
+		return err
+	}
+
+	// Initialize empty arrays if they are null
+	if caseObj.Documents == nil {
+		caseObj.Documents = make([]Document, 0)
+	}
+	if caseObj.History == nil {
+		caseObj.History = make([]HistoryItem, 0)
+	}
+	if caseObj.AssociatedLawyers == nil {
+		caseObj.AssociatedLawyers = make([]string, 0)
+	}
+	if caseObj.Hearings == nil {
+		caseObj.Hearings = make([]Hearing, 0)
+	}
+
+	// Only the judge assigned to the case may add hearing notes
+	if err := access.RequireAssignedJudge(ctx, &caseObj); err != nil {
+		return err
+	}
+
+	// Parse hearing details
+	var details struct {
+		HearingDate string `json:"hearingDate"`
+		Notes       string `json:"notes"`
+	}
+	err = json.Unmarshal([]byte(hearingDetails), &details)
+	if err != nil {
+		log.Printf("Failed to unmarshal hearing details: %v", err)
+		return err
+	}
+
+	// Get current timestamp
+	txTimestamp, err := ctx.GetStub().GetTxTimestamp()
+	if err != nil {
+		log.Printf("Failed to get transaction timestamp: %v", err)
+		return fmt.Errorf("failed to get transaction timestamp: %v", err)
+	}
+	timestamp := time.Unix(txTimestamp.Seconds, 0).Format(time.RFC3339)
+
+	// Find and update the hearing
+	hearingFound := false
+	for i := range caseObj.Hearings {
+		if caseObj.Hearings[i].Date == details.HearingDate {
+			caseObj.Hearings[i].Notes = details.Notes
+			caseObj.Hearings[i].Status = "COMPLETED"
+			hearingFound = true
+			break
+		}
+	}
+
+	if !hearingFound {
+		log.Printf("Hearing not found for date: %s", details.HearingDate)
+		return fmt.Errorf("hearing not found for date: %s", details.HearingDate)
+	}
+
+	// Add to history
+	caseObj.History = append(caseObj.History, HistoryItem{
+		Status:       "HEARING_NOTES_ADDED",
+		Organization: "JudgesOrg",
+		Timestamp:    timestamp,
+		Comments:     fmt.Sprintf("Notes added for hearing on %s", details.HearingDate),
+	})
+
+	// Save updated case
+	caseObj.LastModified = timestamp
+	if err := casemodel.Seal(ctx, &caseObj); err != nil {
+		return err
+	}
+	caseJSON, err = json.Marshal(caseObj)
+	if err != nil {
+		log.Printf("Failed to marshal updated case: %v", err)
+		return err
+	}
+
+	log.Printf("Successfully added hearing notes for case ID: %s", caseID)
+	return ctx.GetStub().PutState(caseID, caseJSON)
+}
+
+// SealCase hears a case in camera. From then on only the sealing judge and the
+// identities in accessList, a JSON array of principals such as ["lawyer:L001"], see the
+// case in full; list queries show everyone else its ID, status and organization only.
+// Whoever still has to handle the case must be on the list, as handing a sealed case to
+// anyone else fails. Sealing a sealed case replaces its access list.
+//
+// The seal is stored on the judge's channel and travels with the case to wherever it is
+// handed next. The copies other contracts stored on their channels before the case was
+// sealed stay as they were. On any channel the seal only redacts what the contracts
+// return; peers of the channel hold the full case, see access.RedactSealed.
+func (s *JudgeContract) SealCase(ctx contractapi.TransactionContextInterface, caseID string, accessList string) error {
+	log.Printf("SealCase called for case ID: %s", caseID)
+
+	caseObj, err := s.readAssignedCase(ctx, caseID)
+	if err != nil {
+		return err
+	}
+
+	var principals []string
+	if err := json.Unmarshal([]byte(accessList), &principals); err != nil {
+		log.Printf("Failed to unmarshal access list: %v", err)
+		return fmt.Errorf("failed to unmarshal access list: %v", err)
+	}
+	caller, err := access.GetCaller(ctx)
+	if err != nil {
+		return err
+	}
+	caseObj.AccessList = []string{caller.Principal()}
+	listed := map[string]bool{caller.Principal(): true}
+	for _, principal := range principals {
+		if _, _, err := access.ParsePrincipal(principal); err != nil {
+			return err
+		}
+		if !listed[principal] {
+			listed[principal] = true
+			caseObj.AccessList = append(caseObj.AccessList, principal)
+		}
+	}
+	caseObj.Sealed = true
+
+	return s.storeAccessChange(ctx, caseObj, "CASE_SEALED", fmt.Sprintf("Case sealed, visible to %s", strings.Join(caseObj.AccessList, ", ")))
+}
+
+// UnsealCase makes a sealed case visible in full to every organization on its channels
+// again
+func (s *JudgeContract) UnsealCase(ctx contractapi.TransactionContextInterface, caseID string) error {
+	log.Printf("UnsealCase called for case ID: %s", caseID)
+
+	caseObj, err := s.readAssignedCase(ctx, caseID)
+	if err != nil {
+		return err
+	}
+	if !caseObj.Sealed {
+		return fmt.Errorf("case %s is not sealed", caseID)
+	}
+	caseObj.Sealed = false
+	caseObj.AccessList = nil
+
+	return s.storeAccessChange(ctx, caseObj, "CASE_UNSEALED", "Case unsealed")
+}
+
+// readAssignedCase reads a stored case the calling judge is assigned to
+func (s *JudgeContract) readAssignedCase(ctx contractapi.TransactionContextInterface, caseID string) (*Case, error) {
+	caseJSON, err := ctx.GetStub().GetState(caseID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read case: %v", err)
+	}
+	if caseJSON == nil {
+		return nil, fmt.Errorf("case does not exist: %s", caseID)
+	}
+	caseObj, err := casemodel.DecodeCase(caseJSON)
+	if err != nil {
+		return nil, err
+	}
+	if err := access.RequireAssignedJudge(ctx, caseObj); err != nil {
+		return nil, err
+	}
+	return caseObj, nil
+}
+
+// storeAccessChange records a change to who may see a case in its history and stores it
+func (s *JudgeContract) storeAccessChange(ctx contractapi.TransactionContextInterface, caseObj *Case, status string, comments string) error {
+	txTimestamp, err := ctx.GetStub().GetTxTimestamp()
+	if err != nil {
+		return fmt.Errorf("failed to get transaction timestamp: %v", err)
+	}
+	timestamp := time.Unix(txTimestamp.Seconds, 0).Format(time.RFC3339)
+
+	caseObj.History = append(caseObj.History, HistoryItem{
+		Status:       status,
+		Organization: "JudgesOrg",
+		Timestamp:    timestamp,
+		Comments:     comments,
+	})
+	caseObj.LastModified = timestamp
+	if err := casemodel.Seal(ctx, caseObj); err != nil {
+		return err
+	}
+	caseJSON, err := json.Marshal(caseObj)
+	if err != nil {
+		return fmt.Errorf("failed to marshal case: %v", err)
+	}
+
+	log.Printf("Stored %s for case ID: %s", status, caseObj.ID)
+	return ctx.GetStub().PutState(caseObj.ID, caseJSON)
+}
+
+// StoreCase stores a case submitted from another organization's chaincode
+func (s *JudgeContract) StoreCase(ctx contractapi.TransactionContextInterface, caseJSON string) error {
+	log.Printf("StoreCase called with payload length: %d bytes", len(caseJSON))
+
+	// Parse case data
+	newCase, err := casemodel.DecodeCase([]byte(caseJSON))
+	if err != nil {
+		log.Printf("Failed to decode case data: %v", err)
+		return err
+	}
+
+	log.Printf("Successfully parsed case with ID: %s, Title: %s", newCase.ID, newCase.Title)
+
+	// Verify required fields
+	if newCase.ID == "" {
+		log.Printf("Case ID is required")
+		return fmt.Errorf("case ID is required")
+	}
+
+	// Only store the case as the bench clerk handed it to the judges
+	if err := casemodel.VerifyReceived(ctx, newCase, casemodel.OrgJudges, casemodel.OrgBenchClerks); err != nil {
+		log.Printf("Case %s failed verification: %v", newCase.ID, err)
+		return err
+	}
+
+	if err := casemodel.ReceivePrivateDetails(ctx, newCase, casemodel.OrgJudges); err != nil {
+		return err
+	}
+
+	if err := casemodel.Seal(ctx, newCase); err != nil {
+		return err
+	}
+
+	// Store the case in the ledger
+	updatedCaseJSON, err := json.Marshal(newCase)
+	if err != nil {
+		log.Printf("Failed to marshal updated case: %v", err)
+		return err
+	}
+
+	err = ctx.GetStub().PutState(newCase.ID, updatedCaseJSON)
+	if err != nil {
+		log.Printf("Failed to save case: %v", err)
+		return err
+	}
+
+	log.Printf("Successfully stored case with ID: %s", newCase.ID)
+	return nil
+}
+
+// GetCaseById retrieves a specific case by its ID
+func (s *JudgeContract) GetCaseById(ctx contractapi.TransactionContextInterface, caseID string) (*Case, error) {
+	log.Printf("GetCaseById called with ID: %s", caseID)
+
+	// Get the case
+	caseJSON, err := ctx.GetStub().GetState(caseID)
+	if err != nil {
+		log.Printf("Failed to read case: %v", err)
+		return nil, fmt.Errorf("failed to read case: %v", err)
+	}
+	if caseJSON == nil {
+		log.Printf("Case not found locally: %s. Trying to fetch from BenchClerk channel", caseID)
+		// Try to fetch the case from BenchClerk channel
+		return s.FetchAndStoreCaseFromBenchClerkChannel(ctx, caseID)
+	}
+
+	var caseObj Case
+	err = json.Unmarshal(caseJSON, &caseObj)
+	if err != nil {
+		log.Printf("Failed to unmarshal case: %v", err)
+		return nil, fmt.Errorf("failed to unmarshal case: %v", err)
+	}
+
+	// Initialize empty arrays if they are null
+	if caseObj.Documents == nil {
+		caseObj.Documents = make([]Document, 0)
+	}
+	if caseObj.History == nil {
+		caseObj.History = make([]HistoryItem, 0)
+	}
+	if caseObj.AssociatedLawyers == nil {
+		caseObj.AssociatedLawyers = make([]string, 0)
+	}
+	if caseObj.Hearings == nil {
+		caseObj.Hearings = make([]Hearing, 0)
+	}
+
+	if err := access.RequireCaseView(ctx, &caseObj); err != nil {
+		return nil, err
+	}
+	log.Printf("Successfully retrieved case with ID: %s", caseID)
+	return &caseObj, nil
+}
+
+// QueryStats gets statistics for judge dashboard
+func (s *JudgeContract) QueryStats(ctx contractapi.TransactionContextInterface) (string, error) {
+	log.Printf("QueryStats called")
+
+	stats := struct {
+		PendingCases      int `json:"pendingCases"`
+		CompletedCases    int `json:"completedCases"`
+		ScheduledHearings int `json:"scheduledHearings"`
+		JudgmentsIssued   int `json:"judgmentsIssued"`
+	}{}
+
+	// Count pending cases
+	pendingIterator, err := ctx.GetStub().GetQueryResult(query.New(query.And(
+		query.Eq("status", casemodel.StatusPendingJudgeReview),
+		query.Eq("currentOrg", casemodel.OrgJudges),
+	)).String())
+	if err == nil {
+		for pendingIterator.HasNext() {
+			stats.PendingCases++
+			_, _ = pendingIterator.Next()
+		}
+		pendingIterator.Close()
+	}
+
+	// Count completed cases (judgments issued)
+	completedIterator, err := ctx.GetStub().GetQueryResult(query.New(query.Eq("status", casemodel.StatusJudgmentIssued)).String())
+	if err == nil {
+		for completedIterator.HasNext() {
+			stats.CompletedCases++
+			_, _ = completedIterator.Next()
+		}
+		completedIterator.Close()
+	}
+
+	// Count scheduled hearings. Hearings and judgments of a sealed case are only counted
+	// for callers on its access list.
+	hearingQuery := query.New(query.ElemMatch("hearings", query.Eq("status", "SCHEDULED"))).String()
+	hearingIterator, err := ctx.GetStub().GetQueryResult(hearingQuery)
+	if err == nil {
+		for hearingIterator.HasNext() {
+			response, err := hearingIterator.Next()
+			if err != nil {
+				break
+			}
+			if visible, err := access.CanViewStored(ctx, response.Value); err == nil && visible {
+				stats.ScheduledHearings++
+			}
+		}
+		hearingIterator.Close()
+	}
+
+	// Count judgments issued
+	judgmentIterator, err := ctx.GetStub().GetQueryResult(query.New(query.NotNull("judgment")).String())
+	if err == nil {
+		for judgmentIterator.HasNext() {
+			response, err := judgmentIterator.Next()
+			if err != nil {
+				break
+			}
+			if visible, err := access.CanViewStored(ctx, response.Value); err == nil && visible {
+				stats.JudgmentsIssued++
+			}
+		}
+		judgmentIterator.Close()
+	}
+
+	// Convert stats to JSON
+	statsJSON, err := json.Marshal(stats)
+	if err != nil {
+		log.Printf("Failed to marshal stats: %v", err)
+		return "", fmt.Errorf("failed to marshal stats: %v", err)
+	}
+
+	log.Printf("Statistics: %s", string(statsJSON))
+	return string(statsJSON), nil
+}
+
+// ForwardCaseToBenchClerk forwards a case with judgment to the BenchClerk's channel
+func (s *JudgeContract) ForwardCaseToBenchClerk(ctx contractapi.TransactionContextInterface, caseID string) error {
+	log.Printf("ForwardCaseToBenchClerk called for case ID: %s", caseID)
+
+	// Get the case from Judge's ledger
+	caseJSON, err := ctx.GetStub().GetState(caseID)
+	if err != nil {
+		return fmt.Errorf("failed to read case: %v", err)
+	}
+	if caseJSON == nil {
+		return fmt.Errorf("case does not exist: %s", caseID)
+	}
+
+	var caseObj Case
+	err = json.Unmarshal(caseJSON, &caseObj)
+	if err != nil {
+		return fmt.Errorf("failed to unmarshal case data: %v", err)
+	}
+
+	// Only the judge assigned to the case may forward its judgment
+	if err := access.RequireAssignedJudge(ctx, &caseObj); err != nil {
+		return err
+	}
+
+	// Verify case has a judgment
+	if caseObj.Judgment == nil || caseObj.Status != casemodel.StatusJudgmentIssued {
+		return fmt.Errorf("case must have a judgment recorded before forwarding to BenchClerk")
+	}
+
+	// Get current timestamp
+	txTimestamp, err := ctx.GetStub().GetTxTimestamp()
+	if err != nil {
+		return fmt.Errorf("failed to get transaction timestamp: %v", err)
+	}
+	timestamp := time.Unix(txTimestamp.Seconds, 0).Format(time.RFC3339)
+
+	// The lifecycle hands an issued judgment to the bench clerk
+	caseObj.CurrentOrg = casemodel.Holder(caseObj.Status)
+	caseObj.LastModified = timestamp
+
+	// Add history item
+	caseObj.History = append(caseObj.History, HistoryItem{
+		Status:       "JUDGMENT_ISSUED",
+		Organization: "JudgesOrg",
+		Timestamp:    timestamp,
+		Comments:     "Judgment issued and case forwarded to BenchClerk",
+	})
+
+	if err := casemodel.Seal(ctx, &caseObj); err != nil {
+		return err
+	}
+
+	// Marshal the updated case data
+	updatedCaseJSON, err := json.Marshal(caseObj)
+	if err != nil {
+		return fmt.Errorf("failed to marshal updated case data: %v", err)
+	}
+
+	// Update case in Judge's ledger
+	err = ctx.GetStub().PutState(caseID, updatedCaseJSON)
+	if err != nil {
+		return fmt.Errorf("failed to update case in Judge's ledger: %v", err)
+	}
+
+	// Hand the case to BenchClerk through the outbox. If BenchClerk cannot store it now,
+	// the transfer stays pending until BenchClerk claims it or it is retried.
+	transfer, err := casemodel.Handoff(ctx, casemodel.HopJudgeToBenchClerk, "StoreCase", &caseObj)
+	if err != nil {
+		return err
+	}
+	if transfer.Status == casemodel.TransferPending {
+		log.Printf("Case %s is pending in transfer %d to BenchClerk: %s", caseID, transfer.Sequence, transfer.LastError)
+		return nil
+	}
+
+	log.Printf("Successfully forwarded case %s to BenchClerk", caseID)
+	return nil
+}
+
+// FetchAndStoreCaseFromBenchClerkChannel fetches a case from BenchClerk channel when it doesn't exist locally
+func (s *JudgeContract) FetchAndStoreCaseFromBenchClerkChannel(ctx contractapi.TransactionContextInterface, caseID string) (*Case, error) {
+	log.Printf("FetchAndStoreCaseFromBenchClerkChannel called for case ID: %s", caseID)
+
+	// Invoke the benchclerk chaincode on the benchclerk-judge-channel to get the case
+	args := [][]byte{[]byte("GetCaseById"), []byte(caseID)}
+	response := casemodel.InvokeHop(ctx, casemodel.HopJudgeToBenchClerk, args)
+
+	// Check the response status - 200 is OK in contractapi
+	if response.Status != 200 {
+		errMsg := fmt.Sprintf("Failed to fetch case from BenchClerk channel: %s", string(response.Message))
+		log.Printf(errMsg)
+		return nil, fmt.Errorf(errMsg)
+	}
+
+	// Check if payload is empty
+	if response.Payload == nil || len(response.Payload) == 0 {
+		errMsg := fmt.Sprintf("Case data not found on BenchClerk channel for ID: %s", caseID)
+		log.Printf(errMsg)
+		return nil, fmt.Errorf(errMsg)
+	}
+
+	// Use the payload as the case bytes
+	caseBytes := response.Payload
+
+	// Unmarshal the case data
+	caseData, err := casemodel.DecodeCase(caseBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	// Only store the case in the state the bench clerk handed it over in
+	if err := casemodel.VerifyHandoff(ctx, caseData, casemodel.OrgBenchClerks); err != nil {
+		log.Printf("Case %s failed verification: %v", caseData.ID, err)
+		return nil, err
+	}
+
+	// Verify case is meant for Judge
+	if caseData.CurrentOrg != "JudgesOrg" {
+		return nil, fmt.Errorf("case %s is not currently assigned to JudgesOrg", caseID)
+	}
+
+	// Get current timestamp
+	txTimestamp, err := ctx.GetStub().GetTxTimestamp()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transaction timestamp: %v", err)
+	}
+	timestamp := time.Unix(txTimestamp.Seconds, 0).Format(time.RFC3339)
+
+	// Update case with transfer info
+	if err := casemodel.ApplyTransition(ctx, caseData, casemodel.StatusReceivedByJudge); err != nil {
+		return nil, err
+	}
+	caseData.LastModified = timestamp
+
+	// Add history item
+	caseData.History = append(caseData.History, HistoryItem{
+		Status:       "RECEIVED_BY_JUDGE",
+		Organization: "JudgesOrg",
+		Timestamp:    timestamp,
+		Comments:     "Case transferred from BenchClerk to Judge",
+	})
+
+	// The lawyer hands the sealed details to the judge off the ledger; keep them in the
+	// judges' private data if they match the case
+	if err := casemodel.ReceivePrivateDetails(ctx, caseData, casemodel.OrgJudges); err != nil {
+		return nil, err
+	}
+
+	if err := casemodel.Seal(ctx, caseData); err != nil {
+		return nil, err
+	}
+
+	// Store the case in Judge's ledger
+	updatedCaseBytes, err := json.Marshal(caseData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal updated case data: %v", err)
+	}
+
+	if err := ctx.GetStub().PutState(caseID, updatedCaseBytes); err != nil {
+		return nil, fmt.Errorf("failed to store case in Judge ledger: %v", err)
+	}
+
+	log.Printf("Successfully fetched and stored case %s from BenchClerk channel", caseID)
+	return caseData, nil
+}
+
+// judgedCasesQuery selects cases with a judgment waiting to be forwarded to BenchClerk
+func judgedCasesQuery() string {
+	return query.New(query.And(
+		query.Eq("status", casemodel.StatusJudgmentIssued),
+		query.Eq("currentOrg", casemodel.OrgBenchClerks),
+	)).String()
+}
+
+// GetJudgedCases returns all cases with judgment to be forwarded to BenchClerk
+func (s *JudgeContract) GetJudgedCases(ctx contractapi.TransactionContextInterface) ([]*Case, error) {
+	log.Printf("GetJudgedCases called")
+
+	// Query all cases with judgment status
+	queryString := judgedCasesQuery()
+
+	// Execute the query
+	resultsIterator, err := ctx.GetStub().GetQueryResult(queryString)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get query result: %v", err)
+	}
+	defer resultsIterator.Close()
+
+	var judgedCases []*Case
+	for resultsIterator.HasNext() {
+		queryResult, err := resultsIterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get the next query result: %v", err)
+		}
+
+		var caseObj Case
+		err = json.Unmarshal(queryResult.Value, &caseObj)
+		if err != nil {
+			return nil, fmt.Errorf("failed to unmarshal case: %v", err)
+		}
+
+		judgedCases = append(judgedCases, &caseObj)
+		log.Printf("Found judged case %s", caseObj.ID)
+	}
+	log.Printf("Returning %d judged cases", len(judgedCases))
+	return access.RedactSealed(ctx, judgedCases)
+}
+
+// GetJudgedCasesWithPagination returns one page of the cases with judgment to be forwarded to BenchClerk
+func (s *JudgeContract) GetJudgedCasesWithPagination(ctx contractapi.TransactionContextInterface, pageSize int32, bookmark string) (*CasePage, error) {
+	log.Printf("GetJudgedCasesWithPagination called with page size %d", pageSize)
+	page, err := casemodel.QueryCasesWithPagination(ctx, judgedCasesQuery(), pageSize, bookmark)
+	if err != nil {
+		return nil, err
+	}
+	return access.RedactSealedPage(ctx, page)
+}
+
+// GetAllowedTransitions returns the status changes the caller's organization can make on a case right now
+func (s *JudgeContract) GetAllowedTransitions(ctx contractapi.TransactionContextInterface, caseID string) ([]casemodel.Transition, error) {
+	return casemodel.GetAllowedTransitions(ctx, caseID)
+}
+
+// VerifyCaseHistory recomputes the hash chain of a case's history and reports the first
+// entry that does not verify
+func (s *JudgeContract) VerifyCaseHistory(ctx contractapi.TransactionContextInterface, caseID string) (*casemodel.HistoryVerification, error) {
+	return casemodel.VerifyCaseHistory(ctx, caseID)
+}
+
+// VerifyDocument checks whether a file with the hex SHA-256 contentHash is the one a
+// case document was uploaded with
+func (s *JudgeContract) VerifyDocument(ctx contractapi.TransactionContextInterface, caseID string, documentID string, contentHash string) (*casemodel.DocumentVerification, error) {
+	if err := access.RequireStoredCaseView(ctx, caseID); err != nil {
+		return nil, err
+	}
+	return casemodel.VerifyDocument(ctx, caseID, documentID, contentHash)
+}
+
+// VerifyDocumentSignatures rechecks every signature on a case's documents
+func (s *JudgeContract) VerifyDocumentSignatures(ctx contractapi.TransactionContextInterface, caseID string) (*casemodel.SignatureVerification, error) {
+	if err := access.RequireStoredCaseView(ctx, caseID); err != nil {
+		return nil, err
+	}
+	return casemodel.VerifyDocumentSignatures(ctx, caseID)
+}
+
+// GetCaseProvenance returns every committed version of a case on this channel with the
+// fields each transaction changed
+func (s *JudgeContract) GetCaseProvenance(ctx contractapi.TransactionContextInterface, caseID string) (*casemodel.CaseProvenance, error) {
+	if err := access.RequireStoredCaseView(ctx, caseID); err != nil {
+		return nil, err
+	}
+	return casemodel.GetCaseProvenance(ctx, caseID)
+}
+
+// GetCasePrivateDetails returns the client name, description and document hashes of a
+// case from the judges' private data
+func (s *JudgeContract) GetCasePrivateDetails(ctx contractapi.TransactionContextInterface, caseID string) (*casemodel.CasePrivateDetails, error) {
+	if err := access.RequireStoredCaseView(ctx, caseID); err != nil {
+		return nil, err
+	}
+	return casemodel.GetCasePrivateDetails(ctx, caseID, casemodel.OrgJudges)
+}
+
+// SetRoutingConfig replaces the chaincode and channel of each call this contract makes,
+// as stored by InitLedger. Only an administrator may change the routes.
+func (s *JudgeContract) SetRoutingConfig(ctx contractapi.TransactionContextInterface, config string) error {
+	if err := access.RequireAdmin(ctx, "SetRoutingConfig"); err != nil {
+		return err
+	}
+	return casemodel.PutRouting(ctx, config, hops...)
+}
+
+// GetRoutingConfig returns the routing table this contract uses
+func (s *JudgeContract) GetRoutingConfig(ctx contractapi.TransactionContextInterface) (*casemodel.RoutingConfig, error) {
+	return casemodel.GetRouting(ctx)
+}
+
+// SetOrgRoots replaces the root CA certificates trusted for an organization on this
+// channel, which signer certificates are checked against. Only an administrator of that
+// organization may change them.
+func (s *JudgeContract) SetOrgRoots(ctx contractapi.TransactionContextInterface, org string, certificates string) error {
+	if err := access.RequireAdmin(ctx, "SetOrgRoots"); err != nil {
+		return err
+	}
+	return casemodel.PutOrgRoots(ctx, org, certificates)
+}
+
+// ImportOrgRoots copies the root CA certificates trusted for an organization that has not
+// joined this channel from the chaincode routed for hop, where that organization's
+// administrator stored them. Only an administrator may import them.
+func (s *JudgeContract) ImportOrgRoots(ctx contractapi.TransactionContextInterface, org string, hop string) error {
+	if err := access.RequireAdmin(ctx, "ImportOrgRoots"); err != nil {
+		return err
+	}
+	return casemodel.ImportOrgRoots(ctx, org, hop)
+}
+
+// GetOrgRoots returns the PEM root CA certificates trusted for an organization on this
+// channel, or an empty string when none are
+func (s *JudgeContract) GetOrgRoots(ctx contractapi.TransactionContextInterface, org string) (string, error) {
+	return casemodel.GetOrgRoots(ctx, org)
+}
+
+// ListPendingTransfers returns the cases handed to other organizations that they have
+// not claimed yet, with the sealed cases the caller may not see redacted
+func (s *JudgeContract) ListPendingTransfers(ctx contractapi.TransactionContextInterface) ([]*casemodel.Transfer, error) {
+	transfers, err := casemodel.ListPendingTransfers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return access.RedactSealedTransfers(ctx, transfers)
+}
+
+// ClaimTransfer acknowledges a pending transfer for the organization receiving the case
+// and returns it
+func (s *JudgeContract) ClaimTransfer(ctx contractapi.TransactionContextInterface, sequence uint64) (*casemodel.Transfer, error) {
+	return casemodel.ClaimTransfer(ctx, sequence)
+}
+
+// RetryTransfer delivers a pending transfer again
+func (s *JudgeContract) RetryTransfer(ctx contractapi.TransactionContextInterface, sequence uint64) (*casemodel.Transfer, error) {
+	t, err := casemodel.RetryTransfer(ctx, sequence)
+	if err != nil {
+		return nil, err
+	}
+	transfers, err := access.RedactSealedTransfers(ctx, []*casemodel.Transfer{t})
+	if err != nil {
+		return nil, err
+	}
+	return transfers[0], nil
+}
+
+// ReceiveTransfers claims the cases handed to the judge on this channel that were not
+// delivered when they were sent, and stores them. It returns the IDs of the cases received.
+func (s *JudgeContract) ReceiveTransfers(ctx contractapi.TransactionContextInterface) ([]string, error) {
+	store := func(caseJSON string) error {
+		return s.StoreCase(ctx, caseJSON)
+	}
+	return casemodel.ReceiveTransfers(ctx, store, casemodel.HopJudgeToBenchClerk)
+}
+
+// New returns the judge contract with its access policy applied and the status changes
+// of each transaction emitted together once it succeeds
+func New() *JudgeContract {
+	contract := new(JudgeContract)
+	contract.BeforeTransaction = accessPolicy.BeforeTransaction
+	contract.TransactionContextHandler = new(casemodel.TransactionContext)
+	contract.AfterTransaction = casemodel.EmitStatusChanges
+	return contract
+}
@@ -0,0 +1,907 @@
+package chaincode
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/hyperledger/fabric-protos-go/peer"
+
+	"casemodel"
+	"casemodel/mockstub"
+	"casemodel/mockstub/contracttest"
+)
+
+const channel = "benchclerk-judge-channel"
+
+var start = time.Date(2024, time.May, 15, 10, 0, 0, 0, time.UTC)
+
+// stamp is how the contract formats the timestamp of the first transaction
+var stamp = time.Unix(start.Unix(), 0).Format(time.RFC3339)
+
+var (
+	benchClerk = mockstub.Identity{MSPID: "BenchClerksOrgMSP", Name: "benchclerk1", Attrs: map[string]string{"role": "benchclerk"}}
+	judgeJ001  = mockstub.Identity{MSPID: "JudgesOrgMSP", Name: "judge1", Attrs: map[string]string{"role": "judge", "judgeId": "J001"}}
+	judgeAdmin = mockstub.Identity{MSPID: "JudgesOrgMSP", Name: "judgeadmin", Attrs: map[string]string{"role": "judge", "judgeId": "J001", "admin": "true"}}
+	judgeJ002  = mockstub.Identity{MSPID: "JudgesOrgMSP", Name: "judge2", Attrs: map[string]string{"role": "judge", "judgeId": "J002"}}
+)
+
+// contract is how every test deploys the judge contract
+var contract = &contracttest.Contract{
+	Name:     "judge",
+	Channels: []string{channel},
+	New:      func() contractapi.ContractInterface { return New() },
+	Start:    start,
+	Admin:    judgeAdmin,
+	Client:   judgeJ001,
+	// the organization that hands cases to the judge
+	Trusted: []string{casemodel.OrgBenchClerks},
+	Deploy: func(n *mockstub.Network) {
+		n.DefineCollection(channel, "judge", casemodel.CollectionSealedDetails, "JudgesOrgMSP")
+	},
+}
+
+// newCase returns a case in the given status held by org and assigned to judge J001
+func newCase(id string, status string, org string) *Case {
+	c := contracttest.NewCase(id, status, org)
+	c.AssociatedJudge = "J001"
+	return c
+}
+
+// stored reads a case from the judge's ledger
+func stored(t *testing.T, n *mockstub.Network, id string) *Case {
+	t.Helper()
+	value := n.GetState(channel, "judge", id)
+	if value == nil {
+		t.Fatalf("case %s is not on the ledger", id)
+	}
+	c, err := casemodel.DecodeCase(value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+// ids lists the IDs of cases
+func ids(cases []*Case) string {
+	var list []string
+	for _, c := range cases {
+		list = append(list, c.ID)
+	}
+	return strings.Join(list, ",")
+}
+
+// hashOf returns the hex SHA-256 of a private data record
+func hashOf(record string) string {
+	sum := sha256.Sum256([]byte(record))
+	return hex.EncodeToString(sum[:])
+}
+
+// lastHistory returns a case's latest history entry
+func lastHistory(c *Case) HistoryItem {
+	if len(c.History) == 0 {
+		return HistoryItem{}
+	}
+	return c.History[len(c.History)-1]
+}
+
+func TestJudgeContract(t *testing.T) {
+	pending := newCase("CASE_001", casemodel.StatusPendingJudgeReview, casemodel.OrgJudges)
+	pending.Hearings = []Hearing{{Date: "2024-06-01", Time: "10:30", Location: "Courtroom 4", Status: "SCHEDULED"}}
+	received := newCase("CASE_002", casemodel.StatusReceivedByJudge, casemodel.OrgJudges)
+	issued := newCase("CASE_003", casemodel.StatusJudgmentIssued, casemodel.OrgBenchClerks)
+	issued.AssociatedJudge = "J001"
+	issued.Judgment = &Judgment{Decision: "Suit decreed", JudgeID: "J001", Status: "FINAL"}
+	unjudged := newCase("CASE_004", casemodel.StatusJudgmentIssued, casemodel.OrgBenchClerks)
+	heldByClerk := newCase("CASE_005", casemodel.StatusValidatedByStampReporter, casemodel.OrgBenchClerks)
+
+	// cases as the bench clerk hands them over
+	fromBenchClerk := contract.Sealed(pending, benchClerk, casemodel.ChannelBenchClerkJudge)
+	edited := contract.Sealed(pending, benchClerk, casemodel.ChannelBenchClerkJudge)
+	edited.AssociatedJudge = "J002"
+	// withDetails carries the hash of the sealed details the lawyer recorded
+	sealedRecord := `{"caseId":"CASE_001","clientName":"Ravi Sharma","description":"Boundary wall"}`
+	withDetails := *pending
+	withDetails.PrivateHashes = map[string]string{casemodel.CollectionSealedDetails: hashOf(sealedRecord)}
+	detailsFromBenchClerk := contract.Sealed(&withDetails, benchClerk, casemodel.ChannelBenchClerkJudge)
+
+	var sent, rerouted, retried []*Case
+
+	// transfers waiting in the bench clerk's outbox
+	forJudge := &casemodel.Transfer{Sequence: 2, CaseID: "CASE_001", Hop: casemodel.HopBenchClerkToJudge, Function: "StoreCase", From: casemodel.OrgBenchClerks, To: casemodel.OrgJudges, Case: fromBenchClerk, Status: casemodel.TransferPending}
+	forBenchClerk := &casemodel.Transfer{Sequence: 1, CaseID: "CASE_005", To: casemodel.OrgBenchClerks, Case: heldByClerk, Status: casemodel.TransferPending}
+	detailsForJudge := *forJudge
+	detailsForJudge.Case = detailsFromBenchClerk
+
+	// chained is pending as the contract stores it, with its history chained; rewritten has
+	// an entry edited afterwards
+	withHistory := *pending
+	withHistory.History = []HistoryItem{{Status: "CREATED", Comments: "filed"}, {Status: pending.Status, Comments: "recorded"}}
+	chained := contract.Sealed(&withHistory, judgeJ001, channel)
+	rewritten := contract.Sealed(&withHistory, judgeJ001, channel)
+	rewritten.History[0].Comments = "withdrawn"
+
+	// cases heard in camera, visible in full to judge J001 only
+	sealedPending := *pending
+	sealedPending.Sealed, sealedPending.AccessList = true, []string{"judge:J001"}
+	sealedIssued := *issued
+	sealedIssued.Sealed, sealedIssued.AccessList = true, []string{"judge:J001"}
+
+	tests := []contracttest.TxTest{
+		{
+			Name:     "InitLedger",
+			Caller:   judgeAdmin,
+			Function: "InitLedger",
+			Args:     []string{""},
+		},
+		{
+			Name:     "InitLedger without a route the contract uses",
+			Caller:   judgeAdmin,
+			Function: "InitLedger",
+			Args:     []string{`{"routes":{}}`},
+			WantErr:  "invalid routing config: route judge->benchclerk is not configured",
+		},
+		{
+			Name:     "InitLedger by a judge who is not an administrator",
+			Caller:   judgeJ001,
+			Function: "InitLedger",
+			Args:     []string{""},
+			WantErr:  "access denied for InitLedger",
+		},
+		{
+			Name:     "SetRoutingConfig by a judge who is not an administrator",
+			Caller:   judgeJ001,
+			Function: "SetRoutingConfig",
+			Args:     []string{`{"routes":{}}`},
+			WantErr:  "access denied for SetRoutingConfig",
+		},
+		{
+			Name:     "SetOrgRoots by a judge who is not an administrator",
+			Caller:   judgeJ001,
+			Function: "SetOrgRoots",
+			Args:     []string{casemodel.OrgStampReporters, mockstub.RootCertificate("StampReportersOrgMSP")},
+			WantErr:  "access denied for SetOrgRoots",
+		},
+		{
+			Name:     "SetOrgRoots of an unknown organization",
+			Caller:   judgeAdmin,
+			Function: "SetOrgRoots",
+			Args:     []string{"CourtsOrg", mockstub.RootCertificate("StampReportersOrgMSP")},
+			WantErr:  `unknown organization "CourtsOrg"`,
+		},
+		{
+			Name:     "SetOrgRoots of another organization",
+			Caller:   judgeAdmin,
+			Function: "SetOrgRoots",
+			Args:     []string{casemodel.OrgLawyers, mockstub.RootCertificate("LawyersOrgMSP")},
+			WantErr:  "a member of JudgesOrg cannot set the root certificates of LawyersOrg",
+		},
+		{
+			Name:     "RecordJudgment",
+			Seed:     []*Case{pending},
+			Caller:   judgeJ001,
+			Function: "RecordJudgment",
+			Args:     []string{"CASE_001", `{"decision":"Suit decreed","reasoning":"Title proved"}`},
+			Check: func(t *testing.T, n *mockstub.Network, _ []byte) {
+				c := stored(t, n, "CASE_001")
+				if c.Status != casemodel.StatusJudgmentIssued || c.CurrentOrg != casemodel.OrgBenchClerks {
+					t.Errorf("case is %s at %s", c.Status, c.CurrentOrg)
+				}
+				want := Judgment{Decision: "Suit decreed", Reasoning: "Title proved", Date: stamp, JudgeID: "J001", IssuedAt: stamp, Status: "FINAL"}
+				if c.Judgment == nil || *c.Judgment != want {
+					t.Errorf("judgment = %+v, want %+v", c.Judgment, want)
+				}
+				if got := lastHistory(c).Comments; got != "Final judgment issued by Judge J001" {
+					t.Errorf("history comment = %q", got)
+				}
+			},
+		},
+		{
+			Name:     "RecordJudgment after receipt",
+			Seed:     []*Case{received},
+			Caller:   judgeJ001,
+			Function: "RecordJudgment",
+			Args:     []string{"CASE_002", `{"decision":"Dismissed","judgeId":"J001"}`},
+			Check: func(t *testing.T, n *mockstub.Network, _ []byte) {
+				if c := stored(t, n, "CASE_002"); c.Status != casemodel.StatusJudgmentIssued {
+					t.Errorf("case is %s", c.Status)
+				}
+			},
+		},
+		{
+			Name:     "RecordJudgment fetches from bench clerk",
+			Peers:    map[string]mockstub.ChaincodeFunc{"benchclerk": contracttest.Fake(map[string]peer.Response{"GetCaseById": shim.Success(contracttest.MustJSON(fromBenchClerk))})},
+			Caller:   judgeJ001,
+			Function: "RecordJudgment",
+			Args:     []string{"CASE_001", `{"decision":"Suit decreed"}`},
+			Check: func(t *testing.T, n *mockstub.Network, _ []byte) {
+				c := stored(t, n, "CASE_001")
+				if c.Status != casemodel.StatusJudgmentIssued || len(c.History) != 2 || c.History[0].Status != "RECEIVED_BY_JUDGE" {
+					t.Errorf("case = %+v", c)
+				}
+			},
+		},
+		{
+			Name:     "RecordJudgment by another judge",
+			Seed:     []*Case{pending},
+			Caller:   judgeJ002,
+			Function: "RecordJudgment",
+			Args:     []string{"CASE_001", `{"decision":"Suit decreed"}`},
+			WantErr:  "case is not assigned to this judge",
+		},
+		{
+			Name:     "RecordJudgment for another judge",
+			Seed:     []*Case{pending},
+			Caller:   judgeJ001,
+			Function: "RecordJudgment",
+			Args:     []string{"CASE_001", `{"decision":"Suit decreed","judgeId":"J002"}`},
+			WantErr:  "judgment judgeId J002 does not match the assigned judge J001",
+		},
+		{
+			Name:     "RecordJudgment twice",
+			Seed:     []*Case{issued},
+			Caller:   judgeJ001,
+			Function: "RecordJudgment",
+			Args:     []string{"CASE_003", `{"decision":"Suit decreed"}`},
+			WantErr:  "cannot move from JUDGMENT_ISSUED to JUDGMENT_ISSUED",
+		},
+		{
+			Name:     "RecordJudgment by bench clerk",
+			Seed:     []*Case{pending},
+			Caller:   benchClerk,
+			Function: "RecordJudgment",
+			Args:     []string{"CASE_001", `{"decision":"Suit decreed"}`},
+			WantErr:  "access denied for RecordJudgment",
+		},
+		{
+			Name:     "AddHearingNotes",
+			Seed:     []*Case{pending},
+			Caller:   judgeJ001,
+			Function: "AddHearingNotes",
+			Args:     []string{"CASE_001", `{"hearingDate":"2024-06-01","notes":"Arguments heard"}`},
+			Check: func(t *testing.T, n *mockstub.Network, _ []byte) {
+				c := stored(t, n, "CASE_001")
+				if h := c.Hearings[0]; h.Notes != "Arguments heard" || h.Status != "COMPLETED" {
+					t.Errorf("hearing = %+v", h)
+				}
+				if h := lastHistory(c); h.Status != "HEARING_NOTES_ADDED" || h.Comments != "Notes added for hearing on 2024-06-01" {
+					t.Errorf("history = %+v", h)
+				}
+			},
+		},
+		{
+			Name:     "AddHearingNotes unknown hearing",
+			Seed:     []*Case{pending},
+			Caller:   judgeJ001,
+			Function: "AddHearingNotes",
+			Args:     []string{"CASE_001", `{"hearingDate":"2024-07-01","notes":"Adjourned"}`},
+			WantErr:  "hearing not found for date: 2024-07-01",
+		},
+		{
+			Name:     "AddHearingNotes by another judge",
+			Seed:     []*Case{pending},
+			Caller:   judgeJ002,
+			Function: "AddHearingNotes",
+			Args:     []string{"CASE_001", `{"hearingDate":"2024-06-01"}`},
+			WantErr:  "case is not assigned to this judge",
+		},
+		{
+			Name:     "SealCase",
+			Seed:     []*Case{pending},
+			Caller:   judgeJ001,
+			Function: "SealCase",
+			Args:     []string{"CASE_001", `["lawyer:L001","judge:J001","lawyer:L001"]`},
+			Check: func(t *testing.T, n *mockstub.Network, _ []byte) {
+				c := stored(t, n, "CASE_001")
+				if !c.Sealed || strings.Join(c.AccessList, ",") != "judge:J001,lawyer:L001" {
+					t.Errorf("sealed = %v, access list = %v", c.Sealed, c.AccessList)
+				}
+				if h := lastHistory(c); h.Status != "CASE_SEALED" || h.Comments != "Case sealed, visible to judge:J001, lawyer:L001" {
+					t.Errorf("history = %+v", h)
+				}
+			},
+		},
+		{
+			Name:     "SealCase with an invalid principal",
+			Seed:     []*Case{pending},
+			Caller:   judgeJ001,
+			Function: "SealCase",
+			Args:     []string{"CASE_001", `["L001"]`},
+			WantErr:  `invalid principal "L001"`,
+		},
+		{
+			Name:     "SealCase by another judge",
+			Seed:     []*Case{pending},
+			Caller:   judgeJ002,
+			Function: "SealCase",
+			Args:     []string{"CASE_001", `[]`},
+			WantErr:  "case is not assigned to this judge",
+		},
+		{
+			Name:     "SealCase by bench clerk",
+			Seed:     []*Case{pending},
+			Caller:   benchClerk,
+			Function: "SealCase",
+			Args:     []string{"CASE_001", `[]`},
+			WantErr:  "access denied for SealCase",
+		},
+		{
+			Name:     "UnsealCase",
+			Seed:     []*Case{&sealedPending},
+			Caller:   judgeJ001,
+			Function: "UnsealCase",
+			Args:     []string{"CASE_001"},
+			Check: func(t *testing.T, n *mockstub.Network, _ []byte) {
+				c := stored(t, n, "CASE_001")
+				if c.Sealed || len(c.AccessList) != 0 || lastHistory(c).Status != "CASE_UNSEALED" {
+					t.Errorf("case = %+v", c)
+				}
+			},
+		},
+		{
+			Name:     "UnsealCase not sealed",
+			Seed:     []*Case{pending},
+			Caller:   judgeJ001,
+			Function: "UnsealCase",
+			Args:     []string{"CASE_001"},
+			WantErr:  "case CASE_001 is not sealed",
+		},
+		{
+			Name:     "StoreCase by bench clerk",
+			Caller:   benchClerk,
+			Function: "StoreCase",
+			Args:     []string{string(contracttest.MustJSON(fromBenchClerk))},
+			Check: func(t *testing.T, n *mockstub.Network, _ []byte) {
+				if c := stored(t, n, "CASE_001"); c.AssociatedJudge != "J001" {
+					t.Errorf("case = %+v", c)
+				}
+			},
+		},
+		{
+			Name:     "StoreCase held by another organization",
+			Caller:   benchClerk,
+			Function: "StoreCase",
+			Args:     []string{string(contracttest.MustJSON(contract.Sealed(heldByClerk, benchClerk, casemodel.ChannelBenchClerkJudge)))},
+			WantErr:  "case CASE_005 is not currently assigned to JudgesOrg",
+		},
+		{
+			Name:     "StoreCase not handed over by the bench clerk",
+			Caller:   benchClerk,
+			Function: "StoreCase",
+			Args:     []string{string(contracttest.MustJSON(pending))},
+			WantErr:  "case CASE_001 has no hash chain",
+		},
+		{
+			Name:     "StoreCase behind the stored copy",
+			Seed:     []*Case{newCase("CASE_001", casemodel.StatusJudgmentIssued, casemodel.OrgBenchClerks)},
+			Caller:   benchClerk,
+			Function: "StoreCase",
+			Args:     []string{string(contracttest.MustJSON(fromBenchClerk))},
+			WantErr:  "case CASE_001 cannot move from JUDGMENT_ISSUED to PENDING_JUDGE_REVIEW",
+		},
+		{
+			Name:     "StoreCase by judge",
+			Caller:   judgeJ001,
+			Function: "StoreCase",
+			Args:     []string{string(contracttest.MustJSON(fromBenchClerk))},
+			WantErr:  "access denied for StoreCase",
+		},
+		{
+			Name:     "GetCaseById",
+			Seed:     []*Case{pending},
+			Caller:   judgeJ001,
+			Function: "GetCaseById",
+			Args:     []string{"CASE_001"},
+			Check: func(t *testing.T, _ *mockstub.Network, payload []byte) {
+				var c Case
+				contracttest.Decode(t, payload, &c)
+				if c.ID != "CASE_001" || len(c.Hearings) != 1 {
+					t.Errorf("case = %+v", c)
+				}
+			},
+		},
+		{
+			Name:     "GetCaseById by bench clerk",
+			Seed:     []*Case{pending},
+			Caller:   benchClerk,
+			Function: "GetCaseById",
+			Args:     []string{"CASE_001"},
+			WantErr:  "access denied for GetCaseById",
+		},
+		{
+			Name:     "GetCaseById sealed",
+			Seed:     []*Case{&sealedPending},
+			Caller:   judgeJ002,
+			Function: "GetCaseById",
+			Args:     []string{"CASE_001"},
+			WantErr:  "case is sealed",
+		},
+		{
+			Name:     "QueryStats",
+			Seed:     []*Case{pending, received, issued},
+			Caller:   judgeJ001,
+			Function: "QueryStats",
+			Check: func(t *testing.T, _ *mockstub.Network, payload []byte) {
+				if want := `{"pendingCases":1,"completedCases":1,"scheduledHearings":1,"judgmentsIssued":1}`; string(payload) != want {
+					t.Errorf("QueryStats = %s, want %s", payload, want)
+				}
+			},
+		},
+		{
+			Name:     "QueryStats with sealed cases",
+			Seed:     []*Case{&sealedPending, received, &sealedIssued},
+			Caller:   judgeJ002,
+			Function: "QueryStats",
+			Check: func(t *testing.T, _ *mockstub.Network, payload []byte) {
+				if want := `{"pendingCases":1,"completedCases":1,"scheduledHearings":0,"judgmentsIssued":0}`; string(payload) != want {
+					t.Errorf("QueryStats = %s, want %s", payload, want)
+				}
+			},
+		},
+		{
+			Name:     "ForwardCaseToBenchClerk",
+			Seed:     []*Case{issued},
+			Peers:    map[string]mockstub.ChaincodeFunc{"benchclerk": contracttest.Recorder(&sent)},
+			Caller:   judgeJ001,
+			Function: "ForwardCaseToBenchClerk",
+			Args:     []string{"CASE_003"},
+			Check: func(t *testing.T, n *mockstub.Network, _ []byte) {
+				c := stored(t, n, "CASE_003")
+				if c.CurrentOrg != casemodel.OrgBenchClerks || lastHistory(c).Comments != "Judgment issued and case forwarded to BenchClerk" {
+					t.Errorf("case = %+v", c)
+				}
+				if len(sent) != 1 || sent[0].Judgment == nil || sent[0].Judgment.Decision != "Suit decreed" {
+					t.Errorf("bench clerk received %+v", sent)
+				}
+				if pending := contract.Outbox(t, n); len(pending) != 0 {
+					t.Errorf("pending transfers = %+v", pending)
+				}
+			},
+		},
+		{
+			Name:     "ForwardCaseToBenchClerk by another judge",
+			Seed:     []*Case{issued},
+			Caller:   judgeJ002,
+			Function: "ForwardCaseToBenchClerk",
+			Args:     []string{"CASE_003"},
+			WantErr:  "access denied for case CASE_003",
+		},
+		{
+			Name:     "ForwardCaseToBenchClerk rejected by bench clerk",
+			Seed:     []*Case{issued},
+			Peers:    map[string]mockstub.ChaincodeFunc{"benchclerk": contracttest.Fake(map[string]peer.Response{"StoreCase": shim.Error("ledger unavailable")})},
+			Caller:   judgeJ001,
+			Function: "ForwardCaseToBenchClerk",
+			Args:     []string{"CASE_003"},
+			Check: func(t *testing.T, n *mockstub.Network, _ []byte) {
+				if c := stored(t, n, "CASE_003"); lastHistory(c).Comments != "Judgment issued and case forwarded to BenchClerk" {
+					t.Errorf("case = %+v", c)
+				}
+				pending := contract.Outbox(t, n)
+				if len(pending) != 1 || pending[0].Sequence != 1 || pending[0].To != casemodel.OrgBenchClerks || pending[0].Attempts != 1 || pending[0].LastError != "ledger unavailable" {
+					t.Errorf("pending transfers = %+v", pending)
+				}
+			},
+		},
+		{
+			Name:     "ForwardCaseToBenchClerk on another channel",
+			Seed:     []*Case{issued},
+			Setup:    contract.Reroute(casemodel.HopJudgeToBenchClerk, casemodel.Route{Chaincode: "benchclerk", Channel: casemodel.ChannelBenchClerkLawyer}),
+			Caller:   judgeJ001,
+			Function: "ForwardCaseToBenchClerk",
+			Args:     []string{"CASE_003"},
+			Check: func(t *testing.T, n *mockstub.Network, _ []byte) {
+				pending := contract.Outbox(t, n)
+				if len(pending) != 1 || pending[0].Attempts != 0 || !strings.Contains(pending[0].LastError, casemodel.ChannelBenchClerkLawyer) {
+					t.Errorf("pending transfers = %+v", pending)
+				}
+			},
+		},
+		{
+			Name:     "ForwardCaseToBenchClerk without judgment",
+			Seed:     []*Case{unjudged},
+			Caller:   judgeJ001,
+			Function: "ForwardCaseToBenchClerk",
+			Args:     []string{"CASE_004"},
+			WantErr:  "case must have a judgment recorded before forwarding to BenchClerk",
+		},
+		{
+			Name:     "ForwardCaseToBenchClerk missing case",
+			Caller:   judgeJ001,
+			Function: "ForwardCaseToBenchClerk",
+			Args:     []string{"CASE_404"},
+			WantErr:  "case does not exist: CASE_404",
+		},
+		{
+			Name:     "FetchAndStoreCaseFromBenchClerkChannel",
+			Peers:    map[string]mockstub.ChaincodeFunc{"benchclerk": contracttest.Fake(map[string]peer.Response{"GetCaseById": shim.Success(contracttest.MustJSON(fromBenchClerk))})},
+			Caller:   judgeJ001,
+			Function: "FetchAndStoreCaseFromBenchClerkChannel",
+			Args:     []string{"CASE_001"},
+			Check: func(t *testing.T, n *mockstub.Network, payload []byte) {
+				var c Case
+				contracttest.Decode(t, payload, &c)
+				if c.Status != casemodel.StatusReceivedByJudge || lastHistory(&c).Status != "RECEIVED_BY_JUDGE" || c.LastModified != stamp {
+					t.Errorf("case = %+v", c)
+				}
+				if s := stored(t, n, "CASE_001"); s.Status != casemodel.StatusReceivedByJudge {
+					t.Errorf("stored case is %s", s.Status)
+				}
+			},
+		},
+		{
+			Name:     "FetchAndStoreCaseFromBenchClerkChannel with private details",
+			Peers:    map[string]mockstub.ChaincodeFunc{"benchclerk": contracttest.Fake(map[string]peer.Response{"GetCaseById": shim.Success(contracttest.MustJSON(detailsFromBenchClerk))})},
+			Caller:   judgeJ001,
+			Function: "FetchAndStoreCaseFromBenchClerkChannel",
+			Args:     []string{"CASE_001"},
+			Details:  `{"clientName":"Ravi Sharma","description":"Boundary wall","uidParty1":"P1"}`,
+			Check: func(t *testing.T, n *mockstub.Network, _ []byte) {
+				if got := string(n.GetPrivateData(channel, "judge", casemodel.CollectionSealedDetails, "CASE_001")); got != sealedRecord {
+					t.Errorf("sealed details = %s", got)
+				}
+			},
+		},
+		{
+			Name:     "FetchAndStoreCaseFromBenchClerkChannel with altered private details",
+			Peers:    map[string]mockstub.ChaincodeFunc{"benchclerk": contracttest.Fake(map[string]peer.Response{"GetCaseById": shim.Success(contracttest.MustJSON(detailsFromBenchClerk))})},
+			Caller:   judgeJ001,
+			Function: "FetchAndStoreCaseFromBenchClerkChannel",
+			Args:     []string{"CASE_001"},
+			Details:  `{"clientName":"Someone else","description":"Boundary wall"}`,
+			WantErr:  "private details for sealedDetails do not match the hash on case CASE_001",
+		},
+		{
+			Name:     "FetchAndStoreCaseFromBenchClerkChannel without its private details",
+			Peers:    map[string]mockstub.ChaincodeFunc{"benchclerk": contracttest.Fake(map[string]peer.Response{"GetCaseById": shim.Success(contracttest.MustJSON(detailsFromBenchClerk))})},
+			Caller:   judgeJ001,
+			Function: "FetchAndStoreCaseFromBenchClerkChannel",
+			Args:     []string{"CASE_001"},
+			WantErr:  "case CASE_001 carries private details for sealedDetails, pass them in the privateDetails transient field",
+		},
+		{
+			Name:     "ReceiveTransfers with private details",
+			Peers:    map[string]mockstub.ChaincodeFunc{"benchclerk": contracttest.Fake(map[string]peer.Response{"ListPendingTransfers": shim.Success(contracttest.MustJSON([]*casemodel.Transfer{&detailsForJudge})), "ClaimTransfer": shim.Success(contracttest.MustJSON(&detailsForJudge))})},
+			Caller:   judgeJ001,
+			Function: "ReceiveTransfers",
+			Details:  `[{"caseId":"CASE_009","clientName":"Someone else"},{"caseId":"CASE_001","clientName":"Ravi Sharma","description":"Boundary wall"}]`,
+			Check: func(t *testing.T, n *mockstub.Network, payload []byte) {
+				if string(payload) != `["CASE_001"]` {
+					t.Errorf("received = %s", payload)
+				}
+				if got := string(n.GetPrivateData(channel, "judge", casemodel.CollectionSealedDetails, "CASE_001")); got != sealedRecord {
+					t.Errorf("sealed details = %s", got)
+				}
+			},
+		},
+		{
+			Name:     "FetchAndStoreCaseFromBenchClerkChannel not yet forwarded",
+			Peers:    map[string]mockstub.ChaincodeFunc{"benchclerk": contracttest.Fake(map[string]peer.Response{"GetCaseById": shim.Success(contracttest.MustJSON(contract.Sealed(heldByClerk, benchClerk, casemodel.ChannelBenchClerkJudge)))})},
+			Caller:   judgeJ001,
+			Function: "FetchAndStoreCaseFromBenchClerkChannel",
+			Args:     []string{"CASE_005"},
+			WantErr:  "case CASE_005 is not currently assigned to JudgesOrg",
+		},
+		{
+			Name:     "FetchAndStoreCaseFromBenchClerkChannel edited in transit",
+			Peers:    map[string]mockstub.ChaincodeFunc{"benchclerk": contracttest.Fake(map[string]peer.Response{"GetCaseById": shim.Success(contracttest.MustJSON(edited))})},
+			Caller:   judgeJ001,
+			Function: "FetchAndStoreCaseFromBenchClerkChannel",
+			Args:     []string{"CASE_001"},
+			WantErr:  "case CASE_001 does not match the hash recorded by transaction",
+		},
+		{
+			Name:     "FetchAndStoreCaseFromBenchClerkChannel not sealed",
+			Peers:    map[string]mockstub.ChaincodeFunc{"benchclerk": contracttest.Fake(map[string]peer.Response{"GetCaseById": shim.Success(contracttest.MustJSON(pending))})},
+			Caller:   judgeJ001,
+			Function: "FetchAndStoreCaseFromBenchClerkChannel",
+			Args:     []string{"CASE_001"},
+			WantErr:  "case CASE_001 has no hash chain",
+		},
+		{
+			Name:     "FetchAndStoreCaseFromBenchClerkChannel missing case",
+			Peers:    map[string]mockstub.ChaincodeFunc{"benchclerk": contracttest.Fake(map[string]peer.Response{"GetCaseById": shim.Error("case CASE_404 does not exist")})},
+			Caller:   judgeJ001,
+			Function: "FetchAndStoreCaseFromBenchClerkChannel",
+			Args:     []string{"CASE_404"},
+			WantErr:  "Failed to fetch case from BenchClerk channel: case CASE_404 does not exist",
+		},
+		{
+			Name:     "GetJudgedCases by bench clerk",
+			Seed:     []*Case{pending, issued},
+			Caller:   benchClerk,
+			Function: "GetJudgedCases",
+			Check: func(t *testing.T, _ *mockstub.Network, payload []byte) {
+				var cases []*Case
+				contracttest.Decode(t, payload, &cases)
+				if got := ids(cases); got != "CASE_003" {
+					t.Errorf("cases = %s", got)
+				}
+			},
+		},
+		{
+			Name:     "GetJudgedCases sealed",
+			Seed:     []*Case{&sealedIssued},
+			Caller:   benchClerk,
+			Function: "GetJudgedCases",
+			Check: func(t *testing.T, _ *mockstub.Network, payload []byte) {
+				var cases []*Case
+				contracttest.Decode(t, payload, &cases)
+				if len(cases) != 1 || !cases[0].Sealed || cases[0].Status != casemodel.StatusJudgmentIssued || cases[0].Judgment != nil || cases[0].Title != "" {
+					t.Errorf("cases = %s", payload)
+				}
+			},
+		},
+		{
+			Name:     "GetJudgedCasesWithPagination",
+			Seed:     []*Case{pending, issued, unjudged},
+			Caller:   judgeJ001,
+			Function: "GetJudgedCasesWithPagination",
+			Args:     []string{"1", ""},
+			Check: func(t *testing.T, _ *mockstub.Network, payload []byte) {
+				var page CasePage
+				contracttest.Decode(t, payload, &page)
+				if ids(page.Records) != "CASE_003" || page.FetchedCount != 1 || page.Bookmark != "CASE_003" {
+					t.Errorf("page = %+v", page)
+				}
+			},
+		},
+		{
+			Name:     "GetAllowedTransitions",
+			Seed:     []*Case{pending},
+			Caller:   judgeJ001,
+			Function: "GetAllowedTransitions",
+			Args:     []string{"CASE_001"},
+			Check: func(t *testing.T, _ *mockstub.Network, payload []byte) {
+				var transitions []casemodel.Transition
+				contracttest.Decode(t, payload, &transitions)
+				if len(transitions) != 2 {
+					t.Errorf("transitions = %+v", transitions)
+				}
+			},
+		},
+		{
+			Name:     "VerifyCaseHistory",
+			Seed:     []*Case{chained},
+			Caller:   judgeJ001,
+			Function: "VerifyCaseHistory",
+			Args:     []string{"CASE_001"},
+			Check: func(t *testing.T, _ *mockstub.Network, payload []byte) {
+				var v casemodel.HistoryVerification
+				contracttest.Decode(t, payload, &v)
+				if !v.Valid || v.Chained != 2 || v.BrokenAt != -1 {
+					t.Errorf("verification = %+v", v)
+				}
+			},
+		},
+		{
+			Name:     "VerifyCaseHistory edited entry",
+			Seed:     []*Case{rewritten},
+			Caller:   judgeJ001,
+			Function: "VerifyCaseHistory",
+			Args:     []string{"CASE_001"},
+			Check: func(t *testing.T, _ *mockstub.Network, payload []byte) {
+				var v casemodel.HistoryVerification
+				contracttest.Decode(t, payload, &v)
+				if v.Valid || v.BrokenAt != 0 || v.Reason != "entry does not match its hash" {
+					t.Errorf("verification = %+v", v)
+				}
+			},
+		},
+		{
+			Name: "GetCaseProvenance",
+			Seed: []*Case{pending},
+			Setup: func(n *mockstub.Network) {
+				if _, err := n.Submit(channel, "judge", judgeJ001, "AddHearingNotes", "CASE_001", `{"hearingDate":"2024-06-01","notes":"Arguments heard"}`); err != nil {
+					panic(err)
+				}
+				if _, err := n.Submit(channel, "judge", judgeJ001, "RecordJudgment", "CASE_001", `{"decision":"Suit decreed","reasoning":"Title proved"}`); err != nil {
+					panic(err)
+				}
+			},
+			Caller:   judgeJ001,
+			Function: "GetCaseProvenance",
+			Args:     []string{"CASE_001"},
+			Check: func(t *testing.T, _ *mockstub.Network, payload []byte) {
+				// seeding bypasses the ledger history, so only the two transactions are in it
+				var provenance casemodel.CaseProvenance
+				contracttest.Decode(t, payload, &provenance)
+				if len(provenance.Versions) != 2 {
+					t.Fatalf("versions = %+v", provenance.Versions)
+				}
+				if v := provenance.Versions[1]; contracttest.Changed(v, "status").After != string(contracttest.MustJSON(casemodel.StatusJudgmentIssued)) || contracttest.Changed(v, "judgment").Before != "" {
+					t.Errorf("changes = %+v", v.Changes)
+				}
+			},
+		},
+		{
+			Name:     "GetCaseProvenance missing case",
+			Caller:   judgeJ001,
+			Function: "GetCaseProvenance",
+			Args:     []string{"CASE_404"},
+			WantErr:  "case does not exist: CASE_404",
+		},
+		{
+			Name:  "GetCasePrivateDetails",
+			Peers: map[string]mockstub.ChaincodeFunc{"benchclerk": contracttest.Fake(map[string]peer.Response{"GetCaseById": shim.Success(contracttest.MustJSON(detailsFromBenchClerk))})},
+			Setup: func(n *mockstub.Network) {
+				details := map[string][]byte{casemodel.TransientPrivateDetails: []byte(`{"clientName":"Ravi Sharma","description":"Boundary wall"}`)}
+				if _, err := n.SubmitTransient(channel, "judge", judgeJ001, details, "FetchAndStoreCaseFromBenchClerkChannel", "CASE_001"); err != nil {
+					panic(err)
+				}
+			},
+			Caller:   judgeJ001,
+			Function: "GetCasePrivateDetails",
+			Args:     []string{"CASE_001"},
+			Check: func(t *testing.T, _ *mockstub.Network, payload []byte) {
+				var details casemodel.CasePrivateDetails
+				contracttest.Decode(t, payload, &details)
+				if details.ClientName != "Ravi Sharma" || details.Description != "Boundary wall" {
+					t.Errorf("details = %+v", details)
+				}
+			},
+		},
+		{
+			Name:     "GetCasePrivateDetails by bench clerk",
+			Seed:     []*Case{pending},
+			Caller:   benchClerk,
+			Function: "GetCasePrivateDetails",
+			Args:     []string{"CASE_001"},
+			WantErr:  "access denied for GetCasePrivateDetails",
+		},
+		{
+			Name:     "GetRoutingConfig defaults",
+			Caller:   judgeJ001,
+			Function: "GetRoutingConfig",
+			Check: func(t *testing.T, _ *mockstub.Network, payload []byte) {
+				var config casemodel.RoutingConfig
+				contracttest.Decode(t, payload, &config)
+				if route := config.Routes[casemodel.HopJudgeToBenchClerk]; route != (casemodel.Route{Chaincode: "benchclerk", Channel: casemodel.ChannelBenchClerkJudge}) {
+					t.Errorf("route = %+v", route)
+				}
+			},
+		},
+		{
+			Name:     "SetRoutingConfig routes later calls",
+			Seed:     []*Case{issued},
+			Peers:    map[string]mockstub.ChaincodeFunc{"benchclerk-v2": contracttest.Recorder(&rerouted)},
+			Caller:   judgeAdmin,
+			Function: "SetRoutingConfig",
+			Args:     []string{`{"routes":{"judge->benchclerk":{"chaincode":"benchclerk-v2"}}}`},
+			Check: func(t *testing.T, n *mockstub.Network, _ []byte) {
+				if _, err := n.Submit(channel, "judge", judgeJ001, "ForwardCaseToBenchClerk", "CASE_003"); err != nil {
+					t.Fatal(err)
+				}
+				if len(rerouted) != 1 || rerouted[0].ID != "CASE_003" {
+					t.Errorf("benchclerk-v2 received %+v", rerouted)
+				}
+			},
+		},
+		{
+			Name:     "ClaimTransfer by bench clerk outside ReceiveTransfers",
+			Seed:     []*Case{issued},
+			Setup:    contract.Reroute(casemodel.HopJudgeToBenchClerk, casemodel.Route{Chaincode: "benchclerk", Channel: casemodel.ChannelBenchClerkLawyer}),
+			Caller:   judgeJ001,
+			Function: "ForwardCaseToBenchClerk",
+			Args:     []string{"CASE_003"},
+			Check: func(t *testing.T, n *mockstub.Network, _ []byte) {
+				_, err := n.Submit(channel, "judge", benchClerk, "ClaimTransfer", "1")
+				if err == nil || !strings.Contains(err.Error(), "transfer 1 can only be claimed by ReceiveTransfers of benchclerk, not ClaimTransfer of judge") {
+					t.Errorf("claim error = %v", err)
+				}
+				if pending := contract.Outbox(t, n); len(pending) != 1 || pending[0].Case.Judgment == nil {
+					t.Errorf("pending transfers = %+v", pending)
+				}
+			},
+		},
+		{
+			Name:     "ClaimTransfer by judge",
+			Caller:   judgeJ001,
+			Function: "ClaimTransfer",
+			Args:     []string{"1"},
+			WantErr:  "access denied for ClaimTransfer",
+		},
+		{
+			Name:     "ClaimTransfer missing transfer",
+			Caller:   benchClerk,
+			Function: "ClaimTransfer",
+			Args:     []string{"1"},
+			WantErr:  "transfer 1 does not exist",
+		},
+		{
+			Name: "ListPendingTransfers of a sealed case by bench clerk",
+			Seed: []*Case{issued},
+			Setup: func(n *mockstub.Network) {
+				contract.Reroute(casemodel.HopJudgeToBenchClerk, casemodel.Route{Chaincode: "benchclerk", Channel: casemodel.ChannelBenchClerkLawyer})(n)
+				if _, err := n.Submit(channel, "judge", judgeJ001, "SealCase", "CASE_003", `[]`); err != nil {
+					panic(err)
+				}
+				if _, err := n.Submit(channel, "judge", judgeJ001, "ForwardCaseToBenchClerk", "CASE_003"); err != nil {
+					panic(err)
+				}
+			},
+			Caller:   benchClerk,
+			Function: "ListPendingTransfers",
+			Check: func(t *testing.T, n *mockstub.Network, payload []byte) {
+				var transfers []*casemodel.Transfer
+				contracttest.Decode(t, payload, &transfers)
+				if len(transfers) != 1 || transfers[0].CaseID != "CASE_003" || !transfers[0].Case.Sealed || transfers[0].Case.Judgment != nil || transfers[0].Case.Title != "" {
+					t.Errorf("pending transfers = %+v", transfers)
+				}
+				if pending := contract.Outbox(t, n); len(pending) != 1 || pending[0].Case.Judgment == nil {
+					t.Errorf("sealing judge's pending transfers = %+v", pending)
+				}
+			},
+		},
+		{
+			Name: "RetryTransfer",
+			Seed: []*Case{issued},
+			Setup: func(n *mockstub.Network) {
+				n.Install("benchclerk", contracttest.Fake(map[string]peer.Response{"StoreCase": shim.Error("ledger unavailable")}))
+				if _, err := n.Submit(channel, "judge", judgeJ001, "ForwardCaseToBenchClerk", "CASE_003"); err != nil {
+					panic(err)
+				}
+				n.Install("benchclerk", contracttest.Recorder(&retried))
+			},
+			Caller:   judgeJ001,
+			Function: "RetryTransfer",
+			Args:     []string{"1"},
+			Check: func(t *testing.T, n *mockstub.Network, payload []byte) {
+				var transfer casemodel.Transfer
+				contracttest.Decode(t, payload, &transfer)
+				if transfer.Status != casemodel.TransferClaimed || transfer.Attempts != 2 || transfer.LastError != "" {
+					t.Errorf("transfer = %+v", transfer)
+				}
+				if len(retried) != 1 || retried[0].ID != "CASE_003" {
+					t.Errorf("bench clerk received %+v", retried)
+				}
+			},
+		},
+		{
+			Name:     "ReceiveTransfers",
+			Peers:    map[string]mockstub.ChaincodeFunc{"benchclerk": contracttest.Fake(map[string]peer.Response{"ListPendingTransfers": shim.Success(contracttest.MustJSON([]*casemodel.Transfer{forBenchClerk, forJudge})), "ClaimTransfer": shim.Success(contracttest.MustJSON(forJudge))})},
+			Caller:   judgeJ001,
+			Function: "ReceiveTransfers",
+			Check: func(t *testing.T, n *mockstub.Network, payload []byte) {
+				if string(payload) != `["CASE_001"]` {
+					t.Errorf("received = %s", payload)
+				}
+				if c := stored(t, n, "CASE_001"); c.Status != casemodel.StatusPendingJudgeReview {
+					t.Errorf("case = %+v", c)
+				}
+			},
+		},
+		{
+			Name:     "ReceiveTransfers by bench clerk",
+			Caller:   benchClerk,
+			Function: "ReceiveTransfers",
+			WantErr:  "access denied for ReceiveTransfers",
+		},
+		{
+			Name:     "SetRoutingConfig with an unknown route",
+			Caller:   judgeAdmin,
+			Function: "SetRoutingConfig",
+			Args:     []string{`{"routes":{"judge->benchclerk":{"chaincode":"benchclerk"},"judge->lawyer":{"chaincode":"lawyer"}}}`},
+			WantErr:  "invalid routing config: unknown route judge->lawyer",
+		},
+		{
+			Name:     "SetRoutingConfig without a route the contract uses",
+			Caller:   judgeAdmin,
+			Function: "SetRoutingConfig",
+			Args:     []string{`{"routes":{}}`},
+			WantErr:  "invalid routing config: route judge->benchclerk is not configured",
+		},
+		{
+			Name:     "SetRoutingConfig by bench clerk",
+			Caller:   benchClerk,
+			Function: "SetRoutingConfig",
+			Args:     []string{`{"routes":{"judge->benchclerk":{"chaincode":"benchclerk"}}}`},
+			WantErr:  "access denied for SetRoutingConfig",
+		},
+	}
+	contract.Run(t, tests)
+}
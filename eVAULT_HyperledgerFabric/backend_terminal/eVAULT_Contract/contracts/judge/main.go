@@ -0,0 +1,20 @@
+package main
+
+import (
+	"log"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+
+	"judge/chaincode"
+)
+
+func main() {
+	judgeChaincode, err := contractapi.NewChaincode(chaincode.New())
+	if err != nil {
+		log.Panicf("Error creating judge chaincode: %v", err)
+	}
+
+	if err := judgeChaincode.Start(); err != nil {
+		log.Panicf("Error starting judge chaincode: %v", err)
+	}
+}
@@ -0,0 +1,1565 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+
+	"casemodel"
+	"casemodel/access"
+	"casemodel/query"
+)
+
+// The case record is shared by every eVAULT contract so that no field is lost as a
+// case moves between channels
+type (
+	Case              = casemodel.Case
+	Document          = casemodel.Document
+	DocumentSignature = casemodel.DocumentSignature
+	HistoryItem       = casemodel.HistoryItem
+	Hearing           = casemodel.Hearing
+	Judgment          = casemodel.Judgment
+	CasePage          = casemodel.CasePage
+)
+
+// DefectCure is the lawyer's correction of one defect the stamp reporter raised: a new
+// version of the document the defect was found in
+type DefectCure struct {
+	DefectID string   `json:"defectId"`
+	Document Document `json:"document"`
+	Comments string   `json:"comments"`
+}
+
+// LawyerContract provides functions for managing cases
+type LawyerContract struct {
+	contractapi.Contract
+}
+
+// accessPolicy lists the roles allowed to call each transaction. Roles from other
+// organizations appear where a transaction is invoked across channels.
+var accessPolicy = access.Policy{
+	"InitLedger":                                {access.RoleLawyer},
+	"CreateCase":                                {access.RoleLawyer},
+	"CaseExists":                                {access.RoleLawyer},
+	"SubmitToRegistrar":                         {access.RoleLawyer},
+	"ResubmitToRegistrar":                       {access.RoleLawyer},
+	"GetCase":                                   {access.RoleLawyer},
+	"UpdateCaseDetails":                         {access.RoleLawyer},
+	"GetCasesByFilter":                          {access.RoleLawyer},
+	"GetCasesByFilterWithPagination":            {access.RoleLawyer},
+	"AddDocumentToCase":                         {access.RoleLawyer},
+	"GetConfirmedDecisions":                     {access.RoleLawyer},
+	"QueryStats":                                {access.RoleLawyer},
+	"ViewJudgmentDetails":                       {access.RoleLawyer},
+	"GetAllCases":                               {access.RoleLawyer},
+	"GetCasesByLawyerID":                        {access.RoleLawyer},
+	"GetAllCasesWithPagination":                 {access.RoleLawyer},
+	"GetCasesByLawyerIDWithPagination":          {access.RoleLawyer},
+	"FetchAndStoreCaseFromStampReporterChannel": {access.RoleLawyer},
+	"FetchAndStoreCaseFromBenchClerkChannel":    {access.RoleLawyer},
+	"StoreCase":                                 {access.RoleStampReporter, access.RoleBenchClerk}, // written by the stamp reporter and bench clerk when returning a case
+	"ReceiveRegistrarRejection":                 {access.RoleRegistrar},                            // written by the registrar when rejecting a case
+	"GetAllowedTransitions":                     {access.RoleLawyer},
+	"VerifyCaseHistory":                         {access.RoleLawyer},
+	"VerifyDocument":                            {access.RoleLawyer},
+	"VerifyDocumentSignatures":                  {access.RoleLawyer},
+	"ReplaceDocument":                           {access.RoleLawyer},
+	"WithdrawDocument":                          {access.RoleLawyer},
+	"GetRejectedDocuments":                      {access.RoleLawyer},
+	"GetCaseDefects":                            {access.RoleLawyer},
+	"CureDefects":                               {access.RoleLawyer},
+	"ResubmitToStampReporter":                   {access.RoleLawyer},
+	"GetCaseProvenance":                         {access.RoleLawyer},
+	"GetCasePrivateDetails":                     {access.RoleLawyer},
+	"SetRoutingConfig":                          {access.RoleLawyer},
+	"GetRoutingConfig":                          {access.RoleLawyer},
+	"SetOrgRoots":                               {access.RoleLawyer, access.RoleRegistrar, access.RoleStampReporter, access.RoleBenchClerk, access.RoleJudge},
+	"ImportOrgRoots":                            {access.RoleLawyer},
+	"GetOrgRoots":                               {access.RoleLawyer, access.RoleRegistrar, access.RoleStampReporter, access.RoleBenchClerk, access.RoleJudge},
+	"ListPendingTransfers":                      {access.RoleLawyer, access.RoleRegistrar, access.RoleStampReporter}, // read by the registrar and stamp reporter when claiming transfers
+	"ClaimTransfer":                             {access.RoleRegistrar, access.RoleStampReporter},                    // invoked through ReceiveTransfers of the registrar and stamp reporter only
+	"RetryTransfer":                             {access.RoleLawyer},
+	"ReceiveTransfers":                          {access.RoleLawyer},
+}
+
+// hops are the calls this contract makes to other chaincodes. A stored routing table
+// must route all of them.
+var hops = []string{
+	casemodel.HopLawyerToRegistrar,
+	casemodel.HopLawyerToStampReporter,
+	casemodel.HopLawyerToBenchClerk,
+}
+
+// InitLedger initializes the ledger with the routing table of the calls this contract
+// makes, or the default routes when routingConfig is empty. Run it as the init
+// transaction on every channel the contract is deployed on.
+func (s *LawyerContract) InitLedger(ctx contractapi.TransactionContextInterface, routingConfig string) error {
+	if err := access.RequireAdmin(ctx, "InitLedger"); err != nil {
+		return err
+	}
+	return casemodel.InitRouting(ctx, routingConfig, hops...)
+}
+
+// CreateCase creates a new case on the ledger
+func (s *LawyerContract) CreateCase(ctx contractapi.TransactionContextInterface, caseData string) error {
+	// Parse case data
+	var newCase Case
+	err := json.Unmarshal([]byte(caseData), &newCase)
+	if err != nil {
+		return fmt.Errorf("failed to unmarshal case data: %v", err)
+	}
+
+	// Check if case already exists
+	exists, err := s.CaseExists(ctx, newCase.ID)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return fmt.Errorf("case already exists: %s", newCase.ID)
+	}
+	if err := newCase.CheckClaimValue(); err != nil {
+		return err
+	}
+
+	// The filing lawyer is always associated with the case
+	caller, err := access.GetCaller(ctx)
+	if err != nil {
+		return err
+	}
+	filedByCaller := false
+	for _, lawyerID := range newCase.AssociatedLawyers {
+		if lawyerID == caller.LawyerID {
+			filedByCaller = true
+			break
+		}
+	}
+	if !filedByCaller {
+		newCase.AssociatedLawyers = append(newCase.AssociatedLawyers, caller.LawyerID)
+	}
+
+	// Initialize case status
+	newCase.Status = casemodel.StatusCreated
+	newCase.CurrentOrg = casemodel.OrgLawyers
+	newCase.SchemaVersion = casemodel.CurrentSchemaVersion
+	// Fees are assessed and recorded by the stamp reporter
+	newCase.Fees = nil
+
+	// Filing time comes from the ledger so that time range filters can rely on it
+	txTime, err := casemodel.TxTime(ctx)
+	if err != nil {
+		return err
+	}
+	newCase.CreatedAt = txTime.Format(time.RFC3339)
+	newCase.LastModified = newCase.CreatedAt
+
+	// Each document must anchor the file the client put in the content store
+	filed := newCase.Documents
+	newCase.Documents = make([]Document, 0, len(filed))
+	for _, doc := range filed {
+		if err := casemodel.AddDocument(&newCase, doc, newCase.CreatedAt); err != nil {
+			return err
+		}
+	}
+
+	// Party identities, client details and document hashes go to private data
+	documentIDs := make([]string, 0, len(newCase.Documents))
+	for _, doc := range newCase.Documents {
+		documentIDs = append(documentIDs, doc.ID)
+	}
+	if err := casemodel.SetPrivateDetails(ctx, &newCase, casemodel.OrgLawyers, documentIDs...); err != nil {
+		return err
+	}
+
+	if err := casemodel.Seal(ctx, &newCase); err != nil {
+		return err
+	}
+
+	// Convert to JSON and save
+	caseJSON, err := json.Marshal(newCase)
+	err = ctx.GetStub().PutState(newCase.ID, caseJSON)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// CaseExists returns true if the case with given ID exists
+func (s *LawyerContract) CaseExists(ctx contractapi.TransactionContextInterface, caseID string) (bool, error) {
+	caseJSON, err := ctx.GetStub().GetState(caseID)
+	if err != nil {
+		return false, fmt.Errorf("failed to read from world state: %v", err)
+	}
+	return caseJSON != nil, nil
+}
+
+// SubmitToRegistrar submits the case to registrar queue
+func (s *LawyerContract) SubmitToRegistrar(ctx contractapi.TransactionContextInterface, caseID string) error {
+	// Get the case
+	caseJSON, err := ctx.GetStub().GetState(caseID)
+	if err != nil {
+		return fmt.Errorf("failed to read case: %v", err)
+	}
+	if caseJSON == nil {
+		return fmt.Errorf("case does not exist: %s", caseID)
+	}
+
+	var caseObj Case
+	err = json.Unmarshal(caseJSON, &caseObj)
+	if err != nil {
+		return err
+	}
+
+	// Only a lawyer on the case may submit it
+	if err := access.RequireCaseLawyer(ctx, &caseObj); err != nil {
+		return err
+	}
+	if caseObj.Status == casemodel.StatusRejectedByRegistrar {
+		return fmt.Errorf("case %s was rejected by the registrar, use ResubmitToRegistrar", caseID)
+	}
+
+	// Get current timestamp
+	txTimestamp, err := ctx.GetStub().GetTxTimestamp()
+	if err != nil {
+		return fmt.Errorf("failed to get transaction timestamp: %v", err)
+	}
+	timestamp := time.Unix(txTimestamp.Seconds, 0).Format(time.RFC3339)
+
+	// Update case state
+	if err := casemodel.ApplyTransition(ctx, &caseObj, casemodel.StatusPendingRegistrarReview); err != nil {
+		return err
+	}
+	caseObj.LastModified = timestamp
+	caseObj.History = append(caseObj.History, HistoryItem{
+		Status:       "SUBMITTED_TO_REGISTRAR",
+		Organization: "LawyersOrg",
+		Timestamp:    timestamp,
+	})
+
+	if err := casemodel.Seal(ctx, &caseObj); err != nil {
+		return err
+	}
+
+	// Save updated case in lawyer's state
+	updatedCaseJSON, err := json.Marshal(caseObj)
+	if err != nil {
+		return err
+	}
+	err = ctx.GetStub().PutState(caseObj.ID, updatedCaseJSON)
+	if err != nil {
+		return err
+	}
+	// Hand the case to the registrar through the outbox. If the registrar cannot store it
+	// now, the transfer stays pending until the registrar claims it or it is retried.
+	transfer, err := casemodel.Handoff(ctx, casemodel.HopLawyerToRegistrar, "ReceiveCase", &caseObj)
+	if err != nil {
+		return err
+	}
+	if transfer.Status == casemodel.TransferPending {
+		log.Printf("Case %s is pending in transfer %d to the registrar: %s", caseObj.ID, transfer.Sequence, transfer.LastError)
+		return nil
+	}
+
+	log.Printf("Successfully submitted case to registrar")
+
+	return nil
+}
+
+// ResubmitToRegistrar files a case the registrar rejected again, recording what changed
+// since the rejection, and hands it back to the registrar
+func (s *LawyerContract) ResubmitToRegistrar(ctx contractapi.TransactionContextInterface, caseID string) error {
+	case_, err := s.GetCase(ctx, caseID)
+	if err != nil {
+		return err
+	}
+	if err := access.RequireCaseLawyer(ctx, case_); err != nil {
+		return err
+	}
+	if case_.Status != casemodel.StatusRejectedByRegistrar {
+		return fmt.Errorf("case %s is %s, only a case the registrar rejected can be resubmitted", caseID, case_.Status)
+	}
+
+	txTime, err := casemodel.TxTime(ctx)
+	if err != nil {
+		return err
+	}
+	timestamp := txTime.Format(time.RFC3339)
+
+	resubmission, err := casemodel.Resubmit(ctx, case_, timestamp)
+	if err != nil {
+		return err
+	}
+	if err := casemodel.ApplyTransition(ctx, case_, casemodel.StatusPendingRegistrarReview); err != nil {
+		return err
+	}
+	case_.LastModified = timestamp
+	case_.History = append(case_.History, HistoryItem{
+		Status:       "RESUBMITTED_TO_REGISTRAR",
+		Organization: "LawyersOrg",
+		Timestamp:    timestamp,
+		Comments:     fmt.Sprintf("Resubmission %d, %d fields changed since the rejection", resubmission.Attempt, len(resubmission.Changes)),
+	})
+
+	if err := casemodel.Seal(ctx, case_); err != nil {
+		return err
+	}
+	caseJSON, err := json.Marshal(case_)
+	if err != nil {
+		return err
+	}
+	if err := ctx.GetStub().PutState(caseID, caseJSON); err != nil {
+		return err
+	}
+
+	// Hand the case back to the registrar through the outbox. If the registrar cannot
+	// store it now, the transfer stays pending until it is claimed or retried.
+	transfer, err := casemodel.Handoff(ctx, casemodel.HopLawyerToRegistrar, "ReceiveCase", case_)
+	if err != nil {
+		return err
+	}
+	if transfer.Status == casemodel.TransferPending {
+		log.Printf("Case %s is pending in transfer %d to the registrar: %s", caseID, transfer.Sequence, transfer.LastError)
+		return nil
+	}
+
+	log.Printf("Successfully resubmitted case %s to the registrar", caseID)
+	return nil
+}
+
+// GetCase retrieves a case by ID
+func (s *LawyerContract) GetCase(ctx contractapi.TransactionContextInterface, caseID string) (*Case, error) {
+	caseJSON, err := ctx.GetStub().GetState(caseID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read case: %v", err)
+	}
+
+	if caseJSON == nil {
+		log.Printf("Case not found locally: %s. Attempting to fetch from BenchClerk channel", caseID)
+		// Try to fetch the case from BenchClerk channel
+		return s.FetchAndStoreCaseFromBenchClerkChannel(ctx, caseID)
+	}
+
+	var case_ Case
+	err = json.Unmarshal(caseJSON, &case_)
+	if err != nil {
+		return nil, err
+	}
+
+	// Use our helper method to initialize the case structure
+	s.initializeCaseStructure(&case_)
+
+	if err := access.RequireCaseView(ctx, &case_); err != nil {
+		return nil, err
+	}
+	return &case_, nil
+}
+
+// UpdateCaseDetails updates case metadata
+func (s *LawyerContract) UpdateCaseDetails(ctx contractapi.TransactionContextInterface, caseID string, updates string) error {
+	var updateData map[string]interface{}
+	err := json.Unmarshal([]byte(updates), &updateData)
+	if err != nil {
+		return fmt.Errorf("failed to unmarshal updates: %v", err)
+	}
+
+	// Get existing case - this will attempt to fetch from BenchClerk channel if not found locally
+	case_, err := s.GetCase(ctx, caseID)
+	if err != nil {
+		return err
+	}
+
+	// Only a lawyer on the case may change it
+	if err := access.RequireCaseLawyer(ctx, case_); err != nil {
+		return err
+	}
+
+	// Apply updates
+	if v, ok := updateData["title"]; ok {
+		case_.Title = v.(string)
+	}
+	if v, ok := updateData["description"]; ok {
+		case_.Description = v.(string)
+	}
+	if v, ok := updateData["caseSubject"]; ok {
+		case_.CaseSubject = v.(string)
+	}
+	if v, ok := updateData["clientName"]; ok {
+		case_.ClientName = v.(string)
+	}
+	if v, ok := updateData["department"]; ok {
+		case_.Department = v.(string)
+	}
+	if err := casemodel.SetPrivateDetails(ctx, case_, casemodel.OrgLawyers); err != nil {
+		return err
+	}
+
+	if err := casemodel.Seal(ctx, case_); err != nil {
+		return err
+	}
+
+	// Save updated case
+	caseJSON, err := json.Marshal(case_)
+	if err != nil {
+		return err
+	}
+	return ctx.GetStub().PutState(caseID, caseJSON)
+}
+
+// GetCasesByFilter retrieves the lawyer's cases matching a casemodel.CaseFilter
+func (s *LawyerContract) GetCasesByFilter(ctx contractapi.TransactionContextInterface, filter string) ([]*Case, error) {
+	queryString, err := filteredCasesQuery(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	resultsIterator, err := ctx.GetStub().GetQueryResult(queryString)
+	if err != nil {
+		return nil, err
+	}
+	defer resultsIterator.Close()
+
+	cases := make([]*Case, 0)
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		caseObj, err := casemodel.DecodeCase(queryResponse.Value)
+		if err != nil {
+			return nil, err
+		}
+		cases = append(cases, caseObj)
+	}
+
+	return access.RedactSealed(ctx, cases)
+}
+
+// GetCasesByFilterWithPagination retrieves one page of the lawyer's cases matching a casemodel.CaseFilter
+func (s *LawyerContract) GetCasesByFilterWithPagination(ctx contractapi.TransactionContextInterface, filter string, pageSize int32, bookmark string) (*CasePage, error) {
+	queryString, err := filteredCasesQuery(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	page, err := casemodel.QueryCasesWithPagination(ctx, queryString, pageSize, bookmark)
+	if err != nil {
+		return nil, err
+	}
+	return access.RedactSealedPage(ctx, page)
+}
+
+// filteredCasesQuery builds the CouchDB query for the lawyer's cases matching a filter
+func filteredCasesQuery(ctx contractapi.TransactionContextInterface, filter string) (string, error) {
+	caseFilter, filterSelector, err := casemodel.ResolveCaseFilter(ctx, filter)
+	if err != nil {
+		return "", err
+	}
+	return caseFilter.Sort(query.New(query.And(
+		query.Or(
+			query.Eq("currentOrg", casemodel.OrgLawyers),
+			query.Exists("associatedLawyers", true),
+		),
+		filterSelector,
+	))).String(), nil
+}
+
+// AddDocumentToCase adds a new document to a case
+func (s *LawyerContract) AddDocumentToCase(ctx contractapi.TransactionContextInterface, caseID string, document string) error {
+	var newDoc Document
+	err := json.Unmarshal([]byte(document), &newDoc)
+	if err != nil {
+		return fmt.Errorf("failed to unmarshal document: %v", err)
+	}
+
+	// Get existing case - this will attempt to fetch from BenchClerk channel if not found locally
+	case_, err := s.GetCase(ctx, caseID)
+	if err != nil {
+		return err
+	}
+
+	// Only a lawyer on the case may change it
+	if err := access.RequireCaseLawyer(ctx, case_); err != nil {
+		return err
+	}
+
+	// Get current timestamp
+	txTimestamp, err := ctx.GetStub().GetTxTimestamp()
+	if err != nil {
+		return fmt.Errorf("failed to get transaction timestamp: %v", err)
+	}
+	timestamp := time.Unix(txTimestamp.Seconds, 0).Format(time.RFC3339)
+
+	// Add document, keeping its hash in private data. The document must anchor the file
+	// the client put in the content store.
+	if err := casemodel.AddDocument(case_, newDoc, timestamp); err != nil {
+		return err
+	}
+	if err := casemodel.SetPrivateDetails(ctx, case_, casemodel.OrgLawyers, newDoc.ID); err != nil {
+		return err
+	}
+
+	if err := casemodel.Seal(ctx, case_); err != nil {
+		return err
+	}
+
+	// Save updated case
+	caseJSON, err := json.Marshal(case_)
+	if err != nil {
+		return err
+	}
+	return ctx.GetStub().PutState(caseID, caseJSON)
+}
+
+// ReplaceDocument files a new version of a document, superseding the current one. The
+// new version has an ID of its own.
+func (s *LawyerContract) ReplaceDocument(ctx contractapi.TransactionContextInterface, caseID string, documentID string, document string) error {
+	var newDoc Document
+	if err := json.Unmarshal([]byte(document), &newDoc); err != nil {
+		return fmt.Errorf("failed to unmarshal document: %v", err)
+	}
+
+	case_, err := s.GetCase(ctx, caseID)
+	if err != nil {
+		return err
+	}
+	if err := access.RequireCaseLawyer(ctx, case_); err != nil {
+		return err
+	}
+
+	txTime, err := casemodel.TxTime(ctx)
+	if err != nil {
+		return err
+	}
+	if err := casemodel.ReplaceDocument(case_, documentID, newDoc, txTime.Format(time.RFC3339)); err != nil {
+		return err
+	}
+	if err := casemodel.SetPrivateDetails(ctx, case_, casemodel.OrgLawyers, newDoc.ID); err != nil {
+		return err
+	}
+	if err := casemodel.Seal(ctx, case_); err != nil {
+		return err
+	}
+
+	// Save updated case
+	caseJSON, err := json.Marshal(case_)
+	if err != nil {
+		return err
+	}
+	return ctx.GetStub().PutState(caseID, caseJSON)
+}
+
+// WithdrawDocument withdraws a document from a case. It stays on the case, but no longer
+// counts towards the filing.
+func (s *LawyerContract) WithdrawDocument(ctx contractapi.TransactionContextInterface, caseID string, documentID string) error {
+	case_, err := s.GetCase(ctx, caseID)
+	if err != nil {
+		return err
+	}
+	if err := access.RequireCaseLawyer(ctx, case_); err != nil {
+		return err
+	}
+
+	if err := casemodel.WithdrawDocument(case_, documentID); err != nil {
+		return err
+	}
+	if err := casemodel.Seal(ctx, case_); err != nil {
+		return err
+	}
+
+	// Save updated case
+	caseJSON, err := json.Marshal(case_)
+	if err != nil {
+		return err
+	}
+	return ctx.GetStub().PutState(caseID, caseJSON)
+}
+
+// GetRejectedDocuments lists the documents of a returned case that the stamp reporter
+// rejected or sent back for correction, with the reason code for each. Replacing them
+// with ReplaceDocument leaves the accepted documents as they are.
+func (s *LawyerContract) GetRejectedDocuments(ctx contractapi.TransactionContextInterface, caseID string) ([]Document, error) {
+	case_, err := s.GetCase(ctx, caseID)
+	if err != nil {
+		return nil, err
+	}
+	return casemodel.RejectedDocuments(case_), nil
+}
+
+// GetCaseDefects lists the defects the stamp reporter raised on a case and whether each
+// has been cured
+func (s *LawyerContract) GetCaseDefects(ctx contractapi.TransactionContextInterface, caseID string) ([]casemodel.Defect, error) {
+	case_, err := s.GetCase(ctx, caseID)
+	if err != nil {
+		return nil, err
+	}
+	if case_.Defects == nil {
+		return make([]casemodel.Defect, 0), nil
+	}
+	return case_.Defects, nil
+}
+
+// CureDefects files a corrected document for each open defect of an on-hold case and
+// returns the case to the stamp reporter's queue. Every open defect must be cured.
+func (s *LawyerContract) CureDefects(ctx contractapi.TransactionContextInterface, caseID string, cureDetails string) error {
+	var details struct {
+		Comments string       `json:"comments"`
+		Cures    []DefectCure `json:"cures"`
+	}
+	if err := json.Unmarshal([]byte(cureDetails), &details); err != nil {
+		return fmt.Errorf("failed to unmarshal cure details: %v", err)
+	}
+
+	case_, err := s.GetCase(ctx, caseID)
+	if err != nil {
+		return err
+	}
+	if err := access.RequireCaseLawyer(ctx, case_); err != nil {
+		return err
+	}
+	if case_.Status != casemodel.StatusForwardedToLawyerOnHold && case_.Status != casemodel.StatusOnHoldReceived {
+		return fmt.Errorf("case %s is %s, only on-hold cases are returned with defects cured", caseID, case_.Status)
+	}
+
+	txTime, err := casemodel.TxTime(ctx)
+	if err != nil {
+		return err
+	}
+	timestamp := txTime.Format(time.RFC3339)
+
+	// File each corrected document as the next version of the one the defect was found
+	// in, keeping its hash in private data
+	cured := make([]string, 0, len(details.Cures))
+	for _, cure := range details.Cures {
+		if err := casemodel.CureDefect(case_, cure.DefectID, cure.Document, cure.Comments, timestamp); err != nil {
+			return err
+		}
+		if err := casemodel.SetPrivateDetails(ctx, case_, casemodel.OrgLawyers, cure.Document.ID); err != nil {
+			return err
+		}
+		cured = append(cured, cure.DefectID)
+	}
+	if open := casemodel.OpenDefects(case_); len(open) > 0 {
+		return fmt.Errorf("defect %s of case %s is still open", open[0].ID, caseID)
+	}
+
+	if err := casemodel.ApplyTransition(ctx, case_, casemodel.StatusPendingStampReporterReview); err != nil {
+		return err
+	}
+	case_.LastModified = timestamp
+	comments := fmt.Sprintf("Defects cured: %s", strings.Join(cured, ", "))
+	if details.Comments != "" {
+		comments = fmt.Sprintf("%s. %s", comments, details.Comments)
+	}
+	case_.History = append(case_.History, HistoryItem{
+		Status:       "DEFECTS_CURED",
+		Organization: "LawyersOrg",
+		Timestamp:    timestamp,
+		Comments:     comments,
+	})
+
+	if err := casemodel.Seal(ctx, case_); err != nil {
+		return err
+	}
+	caseJSON, err := json.Marshal(case_)
+	if err != nil {
+		return err
+	}
+	if err := ctx.GetStub().PutState(caseID, caseJSON); err != nil {
+		return err
+	}
+
+	// Hand the case back to the stamp reporter through the outbox. If the stamp reporter
+	// cannot store it now, the transfer stays pending until it is claimed or retried.
+	transfer, err := casemodel.Handoff(ctx, casemodel.HopLawyerToStampReporter, "ReceiveCuredCase", case_)
+	if err != nil {
+		return err
+	}
+	if transfer.Status == casemodel.TransferPending {
+		log.Printf("Case %s is pending in transfer %d to the stamp reporter: %s", caseID, transfer.Sequence, transfer.LastError)
+		return nil
+	}
+
+	log.Printf("Successfully returned case %s to the stamp reporter with its defects cured", caseID)
+	return nil
+}
+
+// ResubmitToStampReporter returns a case the stamp reporter rejected for review once each
+// of its rejected documents has been replaced with ReplaceDocument, or withdrawn. The
+// defects raised on the documents replaced are cured by their new versions.
+func (s *LawyerContract) ResubmitToStampReporter(ctx contractapi.TransactionContextInterface, caseID string, comments string) error {
+	case_, err := s.GetCase(ctx, caseID)
+	if err != nil {
+		return err
+	}
+	if err := access.RequireCaseLawyer(ctx, case_); err != nil {
+		return err
+	}
+	if case_.Status != casemodel.StatusForwardedToLawyerRejected && case_.Status != casemodel.StatusRejectionReceived {
+		return fmt.Errorf("case %s is %s, only cases the stamp reporter rejected are resubmitted to it", caseID, case_.Status)
+	}
+	if rejected := casemodel.RejectedDocuments(case_); len(rejected) > 0 {
+		return fmt.Errorf("document %s of case %s is %s and must be replaced before the case is resubmitted", rejected[0].ID, caseID, rejected[0].Verdict)
+	}
+
+	txTime, err := casemodel.TxTime(ctx)
+	if err != nil {
+		return err
+	}
+	timestamp := txTime.Format(time.RFC3339)
+	cured, err := casemodel.CureReplacedDefects(case_, timestamp)
+	if err != nil {
+		return err
+	}
+
+	if err := casemodel.ApplyTransition(ctx, case_, casemodel.StatusPendingStampReporterReview); err != nil {
+		return err
+	}
+	case_.LastModified = timestamp
+	history := "Case resubmitted with its rejected documents replaced"
+	if len(cured) > 0 {
+		history = fmt.Sprintf("%s, defects cured: %s", history, strings.Join(cured, ", "))
+	}
+	if comments != "" {
+		history = fmt.Sprintf("%s. %s", history, comments)
+	}
+	case_.History = append(case_.History, HistoryItem{
+		Status:       "RESUBMITTED_TO_STAMP_REPORTER",
+		Organization: "LawyersOrg",
+		Timestamp:    timestamp,
+		Comments:     history,
+	})
+
+	if err := casemodel.Seal(ctx, case_); err != nil {
+		return err
+	}
+	caseJSON, err := json.Marshal(case_)
+	if err != nil {
+		return err
+	}
+	if err := ctx.GetStub().PutState(caseID, caseJSON); err != nil {
+		return err
+	}
+
+	// Hand the case back to the stamp reporter through the outbox, as CureDefects does
+	transfer, err := casemodel.Handoff(ctx, casemodel.HopLawyerToStampReporter, "ReceiveResubmittedCase", case_)
+	if err != nil {
+		return err
+	}
+	if transfer.Status == casemodel.TransferPending {
+		log.Printf("Case %s is pending in transfer %d to the stamp reporter: %s", caseID, transfer.Sequence, transfer.LastError)
+		return nil
+	}
+
+	log.Printf("Successfully resubmitted case %s to the stamp reporter", caseID)
+	return nil
+}
+
+// GetConfirmedDecisions retrieves cases with confirmed judge decisions
+func (s *LawyerContract) GetConfirmedDecisions(ctx contractapi.TransactionContextInterface) ([]*Case, error) {
+	log.Printf("GetConfirmedDecisions called")
+
+	queryString := query.New(query.And(
+		query.Eq("status", casemodel.StatusDecisionConfirmed),
+		query.Eq("currentOrg", casemodel.OrgLawyers),
+	)).String()
+
+	resultsIterator, err := ctx.GetStub().GetQueryResult(queryString)
+	if err != nil {
+		log.Printf("Query failed: %v", err)
+		return nil, err
+	}
+	defer resultsIterator.Close()
+	var cases []*Case
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			log.Printf("Error reading next result: %v", err)
+			return nil, err
+		}
+		var case_ Case
+		err = json.Unmarshal(queryResponse.Value, &case_)
+		if err != nil {
+			log.Printf("Failed to unmarshal case: %v", err)
+			return nil, err
+		}
+
+		// Use our helper method to initialize the case structure
+		s.initializeCaseStructure(&case_)
+
+		cases = append(cases, &case_)
+	}
+
+	log.Printf("Found %d cases with confirmed decisions", len(cases))
+	return access.RedactSealed(ctx, cases)
+}
+
+// QueryStats gets statistics for lawyer dashboard
+func (s *LawyerContract) QueryStats(ctx contractapi.TransactionContextInterface) (string, error) {
+	log.Printf("QueryStats called")
+
+	stats := struct {
+		TotalCases        int `json:"totalCases"`
+		PendingCases      int `json:"pendingCases"`
+		InProgressCases   int `json:"inProgressCases"`
+		CompletedCases    int `json:"completedCases"`
+		DecisionConfirmed int `json:"decisionConfirmed"`
+	}{}
+
+	// Total cases count
+	totalIterator, err := ctx.GetStub().GetQueryResult(query.New(query.Exists("associatedLawyers", true)).String())
+	if err == nil {
+		for totalIterator.HasNext() {
+			stats.TotalCases++
+			_, _ = totalIterator.Next()
+		}
+		totalIterator.Close()
+	}
+
+	// Count pending registrar cases
+	pendingIterator, err := ctx.GetStub().GetQueryResult(query.New(query.Eq("status", casemodel.StatusPendingRegistrarReview)).String())
+	if err == nil {
+		for pendingIterator.HasNext() {
+			stats.PendingCases++
+			_, _ = pendingIterator.Next()
+		}
+		pendingIterator.Close()
+	}
+
+	// Count in progress cases
+	progressIterator, err := ctx.GetStub().GetQueryResult(query.New(query.Or(
+		query.Eq("status", casemodel.StatusVerifiedByRegistrar),
+		query.Eq("status", casemodel.StatusValidatedByStampReporter),
+		query.Eq("status", casemodel.StatusPendingJudgeReview),
+	)).String())
+	if err == nil {
+		for progressIterator.HasNext() {
+			stats.InProgressCases++
+			_, _ = progressIterator.Next()
+		}
+		progressIterator.Close()
+	}
+
+	// Count confirmed decisions
+	decisionIterator, err := ctx.GetStub().GetQueryResult(query.New(query.And(
+		query.Eq("status", casemodel.StatusDecisionConfirmed),
+		query.Eq("currentOrg", casemodel.OrgLawyers),
+	)).String())
+	if err == nil {
+		for decisionIterator.HasNext() {
+			stats.DecisionConfirmed++
+			_, _ = decisionIterator.Next()
+		}
+		decisionIterator.Close()
+	}
+
+	// Count completed cases
+	completedIterator, err := ctx.GetStub().GetQueryResult(query.New(query.In("status", casemodel.StatusDecisionConfirmed, casemodel.StatusJudgmentIssued)).String())
+	if err == nil {
+		for completedIterator.HasNext() {
+			stats.CompletedCases++
+			_, _ = completedIterator.Next()
+		}
+		completedIterator.Close()
+	}
+
+	// Convert stats to JSON
+	statsJSON, err := json.Marshal(stats)
+	if err != nil {
+		log.Printf("Failed to marshal stats: %v", err)
+		return "", fmt.Errorf("failed to marshal stats: %v", err)
+	}
+
+	log.Printf("Statistics: %s", string(statsJSON))
+	return string(statsJSON), nil
+}
+
+// ViewJudgmentDetails retrieves detailed information about a case judgment
+func (s *LawyerContract) ViewJudgmentDetails(ctx contractapi.TransactionContextInterface, caseID string) (string, error) {
+	log.Printf("ViewJudgmentDetails called for case ID: %s", caseID)
+
+	// Get the case
+	case_, err := s.GetCase(ctx, caseID)
+	if err != nil {
+		log.Printf("Failed to get case: %v", err)
+		return "", err
+	}
+
+	// Check if the case has a confirmed decision
+	if case_.Status != "DECISION_CONFIRMED" {
+		log.Printf("Case %s does not have a confirmed decision", caseID)
+		return "", fmt.Errorf("case %s does not have a confirmed decision", caseID)
+	}
+
+	// Extract judgment-related information from the case history
+	var judgmentInfo struct {
+		CaseID        string   `json:"caseId"`
+		CaseNumber    string   `json:"caseNumber"`
+		Title         string   `json:"title"`
+		Decision      string   `json:"decision"`
+		JudgeID       string   `json:"judgeId"`
+		IssuedAt      string   `json:"issuedAt"`
+		ConfirmedAt   string   `json:"confirmedAt"`
+		CurrentStatus string   `json:"currentStatus"`
+		History       []string `json:"history"`
+	}
+
+	judgmentInfo.CaseID = case_.ID
+	judgmentInfo.CaseNumber = case_.CaseNumber
+	judgmentInfo.Title = case_.Title // Get decision from Judgment field if available, otherwise use legacy Decision field
+	if case_.Judgment != nil {
+		judgmentInfo.Decision = case_.Judgment.Decision
+		judgmentInfo.JudgeID = case_.Judgment.JudgeID
+		judgmentInfo.IssuedAt = case_.Judgment.IssuedAt
+	} else {
+		judgmentInfo.Decision = case_.Decision
+		judgmentInfo.JudgeID = case_.AssociatedJudge
+	}
+	judgmentInfo.CurrentStatus = case_.Status
+
+	// Extract relevant history items
+	judgmentInfo.History = make([]string, 0)
+	for _, item := range case_.History {
+		if item.Status == "JUDGMENT_ISSUED" || item.Status == "DECISION_CONFIRMED" {
+			if item.Status == "JUDGMENT_ISSUED" {
+				judgmentInfo.IssuedAt = item.Timestamp
+			}
+			if item.Status == "DECISION_CONFIRMED" {
+				judgmentInfo.ConfirmedAt = item.Timestamp
+			}
+			judgmentInfo.History = append(judgmentInfo.History,
+				fmt.Sprintf("%s: %s - %s", item.Timestamp, item.Status, item.Comments))
+		}
+	}
+
+	// Convert to JSON
+	judgmentJSON, err := json.Marshal(judgmentInfo)
+	if err != nil {
+		log.Printf("Failed to marshal judgment info: %v", err)
+		return "", fmt.Errorf("failed to marshal judgment info: %v", err)
+	}
+
+	log.Printf("Successfully retrieved judgment details for case ID: %s", caseID)
+	return string(judgmentJSON), nil
+}
+
+// lawyerCasesQuery selects all cases accessible to the lawyer
+func lawyerCasesQuery() string {
+	return query.New(query.Or(
+		query.Eq("currentOrg", casemodel.OrgLawyers),
+		query.Exists("associatedLawyers", true),
+	)).String()
+}
+
+// casesByLawyerQuery selects the cases a lawyer created or is associated with
+func casesByLawyerQuery(lawyerID string) string {
+	return query.New(query.Or(
+		query.Includes("associatedLawyers", lawyerID),
+		query.Eq("createdBy", lawyerID),
+	)).String()
+}
+
+// GetAllCases retrieves all cases accessible to the lawyer
+func (s *LawyerContract) GetAllCases(ctx contractapi.TransactionContextInterface) ([]*Case, error) {
+	log.Printf("GetAllCases called")
+
+	// Query all cases
+	queryString := lawyerCasesQuery()
+
+	resultsIterator, err := ctx.GetStub().GetQueryResult(queryString)
+	if err != nil {
+		log.Printf("Query failed: %v", err)
+		return nil, err
+	}
+	defer resultsIterator.Close()
+
+	var cases []*Case
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			log.Printf("Error reading next result: %v", err)
+			return nil, err
+		}
+
+		var case_ Case
+		err = json.Unmarshal(queryResponse.Value, &case_)
+		if err != nil {
+			log.Printf("Failed to unmarshal case: %v", err)
+			return nil, err
+		}
+
+		// Initialize empty arrays and handle legacy data structure
+		s.initializeCaseStructure(&case_)
+		cases = append(cases, &case_)
+	}
+
+	log.Printf("Found %d cases in total", len(cases))
+	return access.RedactSealed(ctx, cases)
+}
+
+// GetCasesByLawyerID retrieves cases associated with a specific lawyer ID
+func (s *LawyerContract) GetCasesByLawyerID(ctx contractapi.TransactionContextInterface, lawyerID string) ([]*Case, error) {
+	log.Printf("GetCasesByLawyerID called for lawyer ID: %s", lawyerID)
+
+	// Create CouchDB query to find cases where the lawyer is associated
+	queryString := casesByLawyerQuery(lawyerID)
+
+	resultsIterator, err := ctx.GetStub().GetQueryResult(queryString)
+	if err != nil {
+		log.Printf("Query failed: %v", err)
+		return nil, err
+	}
+	defer resultsIterator.Close()
+
+	var cases []*Case
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			log.Printf("Error reading next result: %v", err)
+			return nil, err
+		}
+
+		var case_ Case
+		err = json.Unmarshal(queryResponse.Value, &case_)
+		if err != nil {
+			log.Printf("Failed to unmarshal case: %v", err)
+			return nil, err
+		}
+
+		// Initialize case structure
+		s.initializeCaseStructure(&case_)
+		cases = append(cases, &case_)
+	}
+
+	log.Printf("Found %d cases for lawyer ID: %s", len(cases), lawyerID)
+	return access.RedactSealed(ctx, cases)
+}
+
+// GetAllCasesWithPagination retrieves one page of the cases accessible to the lawyer
+func (s *LawyerContract) GetAllCasesWithPagination(ctx contractapi.TransactionContextInterface, pageSize int32, bookmark string) (*CasePage, error) {
+	log.Printf("GetAllCasesWithPagination called with page size %d", pageSize)
+	page, err := casemodel.QueryCasesWithPagination(ctx, lawyerCasesQuery(), pageSize, bookmark)
+	if err != nil {
+		return nil, err
+	}
+	return access.RedactSealedPage(ctx, page)
+}
+
+// GetCasesByLawyerIDWithPagination retrieves one page of the cases associated with a lawyer ID
+func (s *LawyerContract) GetCasesByLawyerIDWithPagination(ctx contractapi.TransactionContextInterface, lawyerID string, pageSize int32, bookmark string) (*CasePage, error) {
+	log.Printf("GetCasesByLawyerIDWithPagination called for lawyer ID %s with page size %d", lawyerID, pageSize)
+	page, err := casemodel.QueryCasesWithPagination(ctx, casesByLawyerQuery(lawyerID), pageSize, bookmark)
+	if err != nil {
+		return nil, err
+	}
+	return access.RedactSealedPage(ctx, page)
+}
+
+// FetchAndStoreCaseFromStampReporterChannel fetches rejected or on-hold cases from StampReporter
+func (s *LawyerContract) FetchAndStoreCaseFromStampReporterChannel(ctx contractapi.TransactionContextInterface) error {
+	log.Printf("FetchAndStoreCaseFromStampReporterChannel called")
+
+	// Invoke the GetRejectedCases function in the StampReporter chaincode to get rejected cases
+	args := [][]byte{[]byte("GetRejectedCases")}
+	response := casemodel.InvokeHop(ctx, casemodel.HopLawyerToStampReporter, args)
+
+	// Check if the StampReporter chaincode response is successful
+	if response.Status != 200 {
+		errMsg := fmt.Sprintf("Failed to fetch rejected cases from StampReporter: %s", string(response.Message))
+		log.Printf(errMsg)
+		return fmt.Errorf(errMsg)
+	}
+
+	// Parse the response payload which should be a JSON array of cases
+	if string(response.Payload) == "[]" {
+		log.Printf("No rejected cases to fetch from StampReporter")
+	} else {
+		var rejectedCases []Case
+		err := json.Unmarshal(response.Payload, &rejectedCases)
+		if err != nil {
+			return fmt.Errorf("failed to unmarshal rejected cases: %v", err)
+		}
+
+		log.Printf("Fetched %d rejected cases from StampReporter", len(rejectedCases))
+
+		// Store each rejected case in Lawyer's ledger
+		for _, caseObj := range rejectedCases {
+			if caseObj.CurrentOrg != casemodel.OrgLawyers {
+				log.Printf("Skipping case %s: status=%s, currentOrg=%s", caseObj.ID, caseObj.Status, caseObj.CurrentOrg)
+				continue
+			}
+			// Skip cases the stamp reporter did not hand over in the state received
+			if err := casemodel.VerifyHandoff(ctx, &caseObj, casemodel.OrgStampReporters); err != nil {
+				log.Printf("Skipping case %s: %v", caseObj.ID, err)
+				continue
+			}
+			if err := casemodel.ApplyTransition(ctx, &caseObj, casemodel.StatusRejectionReceived); err != nil {
+				log.Printf("Skipping case %s: %v", caseObj.ID, err)
+				continue
+			}
+
+			// A moved case is stored or the transaction fails, so that its status change is
+			// never announced without it
+			txTimestamp, err := ctx.GetStub().GetTxTimestamp()
+			if err != nil {
+				log.Printf("Failed to get transaction timestamp for case %s: %v", caseObj.ID, err)
+				return fmt.Errorf("failed to get transaction timestamp for case %s: %v", caseObj.ID, err)
+			}
+			timestamp := time.Unix(txTimestamp.Seconds, 0).Format(time.RFC3339)
+
+			caseObj.LastModified = timestamp
+
+			// Add history item
+			caseObj.History = append(caseObj.History, HistoryItem{
+				Status:       "REJECTION_RECEIVED",
+				Organization: "LawyersOrg",
+				Timestamp:    timestamp,
+				Comments:     "Case rejection received from StampReporter",
+			})
+
+			if err := casemodel.Seal(ctx, &caseObj); err != nil {
+				log.Printf("Failed to seal case %s: %v", caseObj.ID, err)
+				return fmt.Errorf("failed to seal case %s: %v", caseObj.ID, err)
+			}
+
+			// Marshal the updated case
+			caseJSON, err := json.Marshal(caseObj)
+			if err != nil {
+				log.Printf("Failed to marshal case %s: %v", caseObj.ID, err)
+				return fmt.Errorf("failed to marshal case %s: %v", caseObj.ID, err)
+			}
+
+			// Store the case in Lawyer's ledger
+			err = ctx.GetStub().PutState(caseObj.ID, caseJSON)
+			if err != nil {
+				log.Printf("Failed to store rejected case %s: %v", caseObj.ID, err)
+				return fmt.Errorf("failed to store rejected case %s: %v", caseObj.ID, err)
+			}
+
+			log.Printf("Successfully stored rejected case %s in Lawyer's ledger", caseObj.ID)
+		}
+	}
+
+	// Also fetch on-hold cases from StampReporter
+	args = [][]byte{[]byte("GetOnHoldCases")}
+	response = casemodel.InvokeHop(ctx, casemodel.HopLawyerToStampReporter, args)
+
+	// Check if the StampReporter chaincode response is successful
+	if response.Status != 200 {
+		errMsg := fmt.Sprintf("Failed to fetch on-hold cases from StampReporter: %s", string(response.Message))
+		log.Printf(errMsg)
+		return fmt.Errorf(errMsg)
+	}
+
+	// Parse the response payload which should be a JSON array of cases
+	if string(response.Payload) == "[]" {
+		log.Printf("No on-hold cases to fetch from StampReporter")
+	} else {
+		var onHoldCases []Case
+		err := json.Unmarshal(response.Payload, &onHoldCases)
+		if err != nil {
+			return fmt.Errorf("failed to unmarshal on-hold cases: %v", err)
+		}
+
+		log.Printf("Fetched %d on-hold cases from StampReporter", len(onHoldCases))
+
+		// Store each on-hold case in Lawyer's ledger
+		for _, caseObj := range onHoldCases {
+			if caseObj.CurrentOrg != casemodel.OrgLawyers {
+				log.Printf("Skipping case %s: status=%s, currentOrg=%s", caseObj.ID, caseObj.Status, caseObj.CurrentOrg)
+				continue
+			}
+			// Skip cases the stamp reporter did not hand over in the state received
+			if err := casemodel.VerifyHandoff(ctx, &caseObj, casemodel.OrgStampReporters); err != nil {
+				log.Printf("Skipping case %s: %v", caseObj.ID, err)
+				continue
+			}
+			if err := casemodel.ApplyTransition(ctx, &caseObj, casemodel.StatusOnHoldReceived); err != nil {
+				log.Printf("Skipping case %s: %v", caseObj.ID, err)
+				continue
+			}
+
+			// A moved case is stored or the transaction fails, so that its status change is
+			// never announced without it
+			txTimestamp, err := ctx.GetStub().GetTxTimestamp()
+			if err != nil {
+				log.Printf("Failed to get transaction timestamp for case %s: %v", caseObj.ID, err)
+				return fmt.Errorf("failed to get transaction timestamp for case %s: %v", caseObj.ID, err)
+			}
+			timestamp := time.Unix(txTimestamp.Seconds, 0).Format(time.RFC3339)
+
+			caseObj.LastModified = timestamp
+
+			// Add history item
+			caseObj.History = append(caseObj.History, HistoryItem{
+				Status:       "ON_HOLD_RECEIVED",
+				Organization: "LawyersOrg",
+				Timestamp:    timestamp,
+				Comments:     "Case on-hold notice received from StampReporter",
+			})
+
+			if err := casemodel.Seal(ctx, &caseObj); err != nil {
+				log.Printf("Failed to seal case %s: %v", caseObj.ID, err)
+				return fmt.Errorf("failed to seal case %s: %v", caseObj.ID, err)
+			}
+
+			// Marshal the updated case
+			caseJSON, err := json.Marshal(caseObj)
+			if err != nil {
+				log.Printf("Failed to marshal case %s: %v", caseObj.ID, err)
+				return fmt.Errorf("failed to marshal case %s: %v", caseObj.ID, err)
+			}
+
+			// Store the case in Lawyer's ledger
+			err = ctx.GetStub().PutState(caseObj.ID, caseJSON)
+			if err != nil {
+				log.Printf("Failed to store on-hold case %s: %v", caseObj.ID, err)
+				return fmt.Errorf("failed to store on-hold case %s: %v", caseObj.ID, err)
+			}
+
+			log.Printf("Successfully stored on-hold case %s in Lawyer's ledger", caseObj.ID)
+		}
+	}
+
+	return nil
+}
+
+// FetchAndStoreCaseFromBenchClerkChannel fetches a case from the BenchClerk channel when it doesn't exist locally
+func (s *LawyerContract) FetchAndStoreCaseFromBenchClerkChannel(ctx contractapi.TransactionContextInterface, caseID string) (*Case, error) {
+	log.Printf("FetchAndStoreCaseFromBenchClerkChannel called for case ID: %s", caseID)
+
+	// Invoke the benchclerk chaincode on the benchclerk-lawyer-channel to get the case
+	args := [][]byte{[]byte("GetCaseById"), []byte(caseID)}
+	response := casemodel.InvokeHop(ctx, casemodel.HopLawyerToBenchClerk, args)
+
+	// Check the response status
+	if response.Status != 200 {
+		errMsg := fmt.Sprintf("Failed to fetch case from BenchClerk channel: %s", string(response.Message))
+		log.Printf(errMsg)
+		return nil, fmt.Errorf(errMsg)
+	}
+
+	// Check if payload is empty
+	if response.Payload == nil || len(response.Payload) == 0 {
+		errMsg := fmt.Sprintf("Case data not found on BenchClerk channel for ID: %s", caseID)
+		log.Printf(errMsg)
+		return nil, fmt.Errorf(errMsg)
+	}
+
+	// Use the payload as the case bytes
+	caseBytes := response.Payload
+
+	// Unmarshal the case data
+	caseData, err := casemodel.DecodeCase(caseBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	// Only store the case in the state the bench clerk handed it over in
+	if err := casemodel.VerifyHandoff(ctx, caseData, casemodel.OrgBenchClerks); err != nil {
+		log.Printf("Case %s failed verification: %v", caseData.ID, err)
+		return nil, err
+	}
+
+	// Verify that the case is meant for Lawyer organization
+	if caseData.CurrentOrg != "LawyersOrg" {
+		return nil, fmt.Errorf("case %s is not currently assigned to LawyersOrg", caseID)
+	}
+
+	// Get current timestamp
+	txTimestamp, err := ctx.GetStub().GetTxTimestamp()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transaction timestamp: %v", err)
+	}
+	timestamp := time.Unix(txTimestamp.Seconds, 0).Format(time.RFC3339)
+
+	// Add receipt history
+	caseData.History = append(caseData.History, HistoryItem{
+		Status:       "RECEIVED_BY_LAWYER",
+		Organization: "LawyersOrg",
+		Timestamp:    timestamp,
+		Comments:     "Case retrieved from BenchClerk channel",
+	})
+
+	// Update last modified timestamp
+	caseData.LastModified = timestamp
+
+	if err := casemodel.Seal(ctx, caseData); err != nil {
+		return nil, err
+	}
+
+	// Store the case in Lawyer's ledger
+	updatedCaseBytes, err := json.Marshal(caseData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal updated case data: %v", err)
+	}
+
+	if err := ctx.GetStub().PutState(caseID, updatedCaseBytes); err != nil {
+		return nil, fmt.Errorf("failed to store case in Lawyer's ledger: %v", err)
+	}
+
+	log.Printf("Successfully fetched and stored case %s from BenchClerk channel", caseID)
+	return caseData, nil
+}
+
+// requireReceivingLawyer allows only lawyers on c to store it when they claim it through
+// ReceiveTransfers. A case delivered by another organization's transaction is not
+// submitted by a lawyer, and is left to VerifyReceived.
+func requireReceivingLawyer(ctx contractapi.TransactionContextInterface, c *Case) error {
+	caller, err := access.GetCaller(ctx)
+	if err != nil {
+		return err
+	}
+	if caller.Role != access.RoleLawyer {
+		return nil
+	}
+	return access.RequireCaseLawyer(ctx, c)
+}
+
+// StoreCase stores a case returned to the lawyer by another organization's chaincode
+func (s *LawyerContract) StoreCase(ctx contractapi.TransactionContextInterface, caseJSON string) error {
+	log.Printf("StoreCase called with payload length: %d bytes", len(caseJSON))
+
+	// Parse case data
+	newCase, err := casemodel.DecodeCase([]byte(caseJSON))
+	if err != nil {
+		log.Printf("Failed to decode case data: %v", err)
+		return err
+	}
+
+	// Verify required fields
+	if newCase.ID == "" {
+		log.Printf("Case ID is required")
+		return fmt.Errorf("case ID is required")
+	}
+
+	// Only store the case as the stamp reporter or the bench clerk returned it
+	if err := casemodel.VerifyReceived(ctx, newCase, casemodel.OrgLawyers, casemodel.OrgStampReporters, casemodel.OrgBenchClerks); err != nil {
+		log.Printf("Case %s failed verification: %v", newCase.ID, err)
+		return err
+	}
+	if err := requireReceivingLawyer(ctx, newCase); err != nil {
+		return err
+	}
+
+	if err := casemodel.Seal(ctx, newCase); err != nil {
+		return err
+	}
+
+	// Store the case in the ledger
+	updatedCaseJSON, err := json.Marshal(newCase)
+	if err != nil {
+		log.Printf("Failed to marshal updated case: %v", err)
+		return err
+	}
+
+	err = ctx.GetStub().PutState(newCase.ID, updatedCaseJSON)
+	if err != nil {
+		log.Printf("Failed to save case: %v", err)
+		return err
+	}
+
+	log.Printf("Successfully stored case with ID: %s", newCase.ID)
+	return nil
+}
+
+// ReceiveRegistrarRejection stores a case the registrar rejected and keeps a copy of it
+// for the resubmission to be compared against. The case must be awaiting registrar review
+// on the lawyer's ledger and carry the registrar's proof.
+func (s *LawyerContract) ReceiveRegistrarRejection(ctx contractapi.TransactionContextInterface, caseJSON string) error {
+	rejected, err := casemodel.DecodeCase([]byte(caseJSON))
+	if err != nil {
+		return err
+	}
+	if rejected.ID == "" {
+		return fmt.Errorf("case ID is required")
+	}
+	if rejected.Status != casemodel.StatusRejectedByRegistrar || rejected.CurrentOrg != casemodel.OrgLawyers {
+		return fmt.Errorf("invalid case status or organization: status=%s, org=%s", rejected.Status, rejected.CurrentOrg)
+	}
+
+	stored, err := s.GetCase(ctx, rejected.ID)
+	if err != nil {
+		return err
+	}
+	if stored.Status != casemodel.StatusPendingRegistrarReview {
+		return fmt.Errorf("case %s is %s, only a case awaiting registrar review can be rejected", rejected.ID, stored.Status)
+	}
+	if err := casemodel.VerifyReceived(ctx, rejected, casemodel.OrgLawyers, casemodel.OrgRegistrars); err != nil {
+		return err
+	}
+	if err := requireReceivingLawyer(ctx, stored); err != nil {
+		return err
+	}
+
+	if err := casemodel.Seal(ctx, rejected); err != nil {
+		return err
+	}
+	value, err := json.Marshal(rejected)
+	if err != nil {
+		return err
+	}
+	if err := ctx.GetStub().PutState(rejected.ID, value); err != nil {
+		return err
+	}
+	if err := casemodel.RecordRejection(ctx, rejected); err != nil {
+		return err
+	}
+
+	log.Printf("Stored case %s rejected by the registrar", rejected.ID)
+	return nil
+}
+
+// GetAllowedTransitions returns the status changes the caller's organization can make on a case right now
+func (s *LawyerContract) GetAllowedTransitions(ctx contractapi.TransactionContextInterface, caseID string) ([]casemodel.Transition, error) {
+	return casemodel.GetAllowedTransitions(ctx, caseID)
+}
+
+// VerifyCaseHistory recomputes the hash chain of a case's history and reports the first
+// entry that does not verify
+func (s *LawyerContract) VerifyCaseHistory(ctx contractapi.TransactionContextInterface, caseID string) (*casemodel.HistoryVerification, error) {
+	return casemodel.VerifyCaseHistory(ctx, caseID)
+}
+
+// VerifyDocument checks whether a file with the hex SHA-256 contentHash is the one a
+// case document was uploaded with
+func (s *LawyerContract) VerifyDocument(ctx contractapi.TransactionContextInterface, caseID string, documentID string, contentHash string) (*casemodel.DocumentVerification, error) {
+	if err := access.RequireStoredCaseView(ctx, caseID); err != nil {
+		return nil, err
+	}
+	return casemodel.VerifyDocument(ctx, caseID, documentID, contentHash)
+}
+
+// VerifyDocumentSignatures rechecks every signature on a case's documents
+func (s *LawyerContract) VerifyDocumentSignatures(ctx contractapi.TransactionContextInterface, caseID string) (*casemodel.SignatureVerification, error) {
+	if err := access.RequireStoredCaseView(ctx, caseID); err != nil {
+		return nil, err
+	}
+	return casemodel.VerifyDocumentSignatures(ctx, caseID)
+}
+
+// GetCaseProvenance returns every committed version of a case on this channel with the
+// fields each transaction changed
+func (s *LawyerContract) GetCaseProvenance(ctx contractapi.TransactionContextInterface, caseID string) (*casemodel.CaseProvenance, error) {
+	if err := access.RequireStoredCaseView(ctx, caseID); err != nil {
+		return nil, err
+	}
+	return casemodel.GetCaseProvenance(ctx, caseID)
+}
+
+// GetCasePrivateDetails returns the client name, description and document hashes of a
+// case from the lawyers' private data
+func (s *LawyerContract) GetCasePrivateDetails(ctx contractapi.TransactionContextInterface, caseID string) (*casemodel.CasePrivateDetails, error) {
+	if err := access.RequireStoredCaseView(ctx, caseID); err != nil {
+		return nil, err
+	}
+	return casemodel.GetCasePrivateDetails(ctx, caseID, casemodel.OrgLawyers)
+}
+
+// SetRoutingConfig replaces the chaincode and channel of each call this contract makes,
+// as stored by InitLedger. Only an administrator may change the routes.
+func (s *LawyerContract) SetRoutingConfig(ctx contractapi.TransactionContextInterface, config string) error {
+	if err := access.RequireAdmin(ctx, "SetRoutingConfig"); err != nil {
+		return err
+	}
+	return casemodel.PutRouting(ctx, config, hops...)
+}
+
+// GetRoutingConfig returns the routing table this contract uses
+func (s *LawyerContract) GetRoutingConfig(ctx contractapi.TransactionContextInterface) (*casemodel.RoutingConfig, error) {
+	return casemodel.GetRouting(ctx)
+}
+
+// SetOrgRoots replaces the root CA certificates trusted for an organization on this
+// channel, which signer certificates are checked against. Only an administrator of that
+// organization may change them.
+func (s *LawyerContract) SetOrgRoots(ctx contractapi.TransactionContextInterface, org string, certificates string) error {
+	if err := access.RequireAdmin(ctx, "SetOrgRoots"); err != nil {
+		return err
+	}
+	return casemodel.PutOrgRoots(ctx, org, certificates)
+}
+
+// ImportOrgRoots copies the root CA certificates trusted for an organization that has not
+// joined this channel from the chaincode routed for hop, where that organization's
+// administrator stored them. Only an administrator may import them.
+func (s *LawyerContract) ImportOrgRoots(ctx contractapi.TransactionContextInterface, org string, hop string) error {
+	if err := access.RequireAdmin(ctx, "ImportOrgRoots"); err != nil {
+		return err
+	}
+	return casemodel.ImportOrgRoots(ctx, org, hop)
+}
+
+// GetOrgRoots returns the PEM root CA certificates trusted for an organization on this
+// channel, or an empty string when none are
+func (s *LawyerContract) GetOrgRoots(ctx contractapi.TransactionContextInterface, org string) (string, error) {
+	return casemodel.GetOrgRoots(ctx, org)
+}
+
+// ListPendingTransfers returns the cases handed to other organizations that they have
+// not claimed yet, with the sealed cases the caller may not see redacted
+func (s *LawyerContract) ListPendingTransfers(ctx contractapi.TransactionContextInterface) ([]*casemodel.Transfer, error) {
+	transfers, err := casemodel.ListPendingTransfers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return access.RedactSealedTransfers(ctx, transfers)
+}
+
+// ClaimTransfer acknowledges a pending transfer for the organization receiving the case
+// and returns it
+func (s *LawyerContract) ClaimTransfer(ctx contractapi.TransactionContextInterface, sequence uint64) (*casemodel.Transfer, error) {
+	return casemodel.ClaimTransfer(ctx, sequence)
+}
+
+// RetryTransfer delivers a pending transfer again
+func (s *LawyerContract) RetryTransfer(ctx contractapi.TransactionContextInterface, sequence uint64) (*casemodel.Transfer, error) {
+	t, err := casemodel.RetryTransfer(ctx, sequence)
+	if err != nil {
+		return nil, err
+	}
+	transfers, err := access.RedactSealedTransfers(ctx, []*casemodel.Transfer{t})
+	if err != nil {
+		return nil, err
+	}
+	return transfers[0], nil
+}
+
+// ReceiveTransfers claims the cases handed to the lawyer on this channel that were not
+// delivered when they were sent, and stores them. It returns the IDs of the cases received.
+func (s *LawyerContract) ReceiveTransfers(ctx contractapi.TransactionContextInterface) ([]string, error) {
+	store := func(caseJSON string) error {
+		return s.StoreCase(ctx, caseJSON)
+	}
+	received, err := casemodel.ReceiveTransfers(ctx, store, casemodel.HopLawyerToStampReporter, casemodel.HopLawyerToBenchClerk)
+	if err != nil {
+		return nil, err
+	}
+	// Cases the registrar rejected are kept for resubmission
+	rejected := func(caseJSON string) error {
+		return s.ReceiveRegistrarRejection(ctx, caseJSON)
+	}
+	fromRegistrar, err := casemodel.ReceiveTransfers(ctx, rejected, casemodel.HopLawyerToRegistrar)
+	if err != nil {
+		return nil, err
+	}
+	return append(received, fromRegistrar...), nil
+}
+
+// initializeCaseStructure ensures all arrays in a Case are properly initialized
+func (s *LawyerContract) initializeCaseStructure(caseObj *Case) {
+	caseObj.Normalize()
+}
+
+// New returns the lawyer contract with its access policy applied and the status changes
+// of each transaction emitted together once it succeeds
+func New() *LawyerContract {
+	contract := new(LawyerContract)
+	contract.BeforeTransaction = accessPolicy.BeforeTransaction
+	contract.TransactionContextHandler = new(casemodel.TransactionContext)
+	contract.AfterTransaction = casemodel.EmitStatusChanges
+	return contract
+}
@@ -0,0 +1,1559 @@
+package chaincode
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/hyperledger/fabric-protos-go/peer"
+
+	"casemodel"
+	"casemodel/mockstub"
+	"casemodel/mockstub/contracttest"
+)
+
+const channel = "lawyer-registrar-channel"
+
+// Salts of the private records the tests file, the base64 of "sealed-details-salt" and
+// "party-identities-salt", and the same as a transient salts field
+const (
+	sealedSalt = "c2VhbGVkLWRldGFpbHMtc2FsdA=="
+	partySalt  = "cGFydHktaWRlbnRpdGllcy1zYWx0"
+	salts      = `"salts":{"sealedDetails":"` + sealedSalt + `","partyIdentities":"` + partySalt + `"}`
+)
+
+var start = time.Date(2024, time.May, 15, 10, 0, 0, 0, time.UTC)
+
+// stamp is how the contract formats the timestamp of the first transaction
+var stamp = time.Unix(start.Unix(), 0).Format(time.RFC3339)
+
+// Content hashes of the plaint filed in the tests and of its amended version
+var (
+	plaintHash  = casemodel.ContentHash([]byte("%PDF-1.4 plaint"))
+	amendedHash = casemodel.ContentHash([]byte("%PDF-1.4 amended plaint"))
+)
+
+var (
+	lawyerL001    = mockstub.Identity{MSPID: "LawyersOrgMSP", Name: "lawyer1", Attrs: map[string]string{"role": "lawyer", "lawyerId": "L001"}}
+	lawyerAdmin   = mockstub.Identity{MSPID: "LawyersOrgMSP", Name: "lawyeradmin", Attrs: map[string]string{"role": "lawyer", "lawyerId": "L001", "admin": "true"}}
+	lawyerL002    = mockstub.Identity{MSPID: "LawyersOrgMSP", Name: "lawyer2", Attrs: map[string]string{"role": "lawyer", "lawyerId": "L002"}}
+	registrar     = mockstub.Identity{MSPID: "RegistrarsOrgMSP", Name: "registrar1", Attrs: map[string]string{"role": "registrar"}}
+	stampReporter = mockstub.Identity{MSPID: "StampReportersOrgMSP", Name: "stampreporter1", Attrs: map[string]string{"role": "stampreporter"}}
+	benchClerk    = mockstub.Identity{MSPID: "BenchClerksOrgMSP", Name: "benchclerk1", Attrs: map[string]string{"role": "benchclerk"}}
+)
+
+// contract is how every test deploys the lawyer contract
+var contract = &contracttest.Contract{
+	Name:     "lawyer",
+	Channels: []string{channel},
+	New:      func() contractapi.ContractInterface { return New() },
+	Start:    start,
+	Admin:    lawyerAdmin,
+	Client:   lawyerL001,
+	// the organizations that hand cases back to the lawyer
+	Trusted: []string{casemodel.OrgRegistrars, casemodel.OrgStampReporters, casemodel.OrgBenchClerks},
+	Deploy: func(n *mockstub.Network) {
+		n.DefineCollection(channel, "lawyer", casemodel.CollectionSealedDetails, "LawyersOrgMSP")
+		n.DefineCollection(channel, "lawyer", casemodel.CollectionPartyIdentities, "LawyersOrgMSP")
+	},
+}
+
+// newCase returns a case in the given status held by org
+func newCase(id string, status string, org string, lawyers ...string) *Case {
+	return &Case{SchemaVersion: 1, ID: id, Title: "Case " + id, Status: status, CurrentOrg: org, AssociatedLawyers: lawyers}
+}
+
+// stored reads a case from the lawyer's ledger
+func stored(t *testing.T, n *mockstub.Network, id string) *Case {
+	t.Helper()
+	value := n.GetState(channel, "lawyer", id)
+	if value == nil {
+		t.Fatalf("case %s is not on the ledger", id)
+	}
+	c, err := casemodel.DecodeCase(value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+// sealedDetails reads a case's record in the lawyers' private data
+func sealedDetails(n *mockstub.Network, id string) string {
+	return string(n.GetPrivateData(channel, "lawyer", casemodel.CollectionSealedDetails, id))
+}
+
+// ids lists the IDs of cases
+func ids(cases []*Case) string {
+	var list []string
+	for _, c := range cases {
+		list = append(list, c.ID)
+	}
+	return strings.Join(list, ",")
+}
+
+func TestLawyerContract(t *testing.T) {
+	// routing moves one route to a new chaincode name and keeps the other defaults
+	routingConfig := casemodel.DefaultRouting()
+	routingConfig.Routes[casemodel.HopLawyerToRegistrar] = casemodel.Route{Chaincode: "registrar-v2", Channel: casemodel.ChannelLawyerRegistrar}
+	routing := string(contracttest.MustJSON(routingConfig))
+
+	confirmed := newCase("CASE_009", casemodel.StatusDecisionConfirmed, casemodel.OrgLawyers, "L001")
+	confirmed.Judgment = &casemodel.Judgment{Decision: "Appeal allowed", JudgeID: "J001", IssuedAt: "2024-05-01T10:00:00Z"}
+	confirmed.History = []HistoryItem{
+		{Status: "JUDGMENT_ISSUED", Timestamp: "2024-05-01T10:00:00Z", Comments: "Final judgment"},
+		{Status: "DECISION_CONFIRMED", Timestamp: "2024-05-02T10:00:00Z", Comments: "Confirmed"},
+	}
+	// edited is the bench clerk's sealed case changed after it was stored
+	edited := contract.Sealed(confirmed, benchClerk, casemodel.ChannelBenchClerkLawyer)
+	edited.Judgment = &casemodel.Judgment{Decision: "Appeal dismissed", JudgeID: "J001", IssuedAt: "2024-05-01T10:00:00Z"}
+	civil := newCase("CASE_002", casemodel.StatusCreated, casemodel.OrgLawyers, "L001")
+	civil.Department = "Civil"
+	criminal := newCase("CASE_003", casemodel.StatusCreated, casemodel.OrgLawyers, "L002")
+	criminal.Department = "Criminal"
+	// withPlaint is civil with the plaint uploaded
+	withPlaint := *civil
+	withPlaint.Documents = []Document{{ID: "DOC_1", Name: "plaint.pdf", ContentHash: plaintHash}}
+	// replaced is civil with the plaint replaced by an amended one
+	replaced := *civil
+	replaced.Documents = []Document{
+		{ID: "DOC_1", Name: "plaint.pdf", ContentHash: plaintHash, Version: 1, Status: casemodel.DocumentSuperseded},
+		{ID: "DOC_1_V2", Name: "plaint.pdf", ContentHash: amendedHash, Version: 2, SupersedesID: "DOC_1", Status: casemodel.DocumentActive},
+	}
+	// returned is a case the stamp reporter rejected: the plaint was accepted, its first
+	// affidavit rejected and replaced, and the replacement needs correction
+	returned := newCase("CASE_004", casemodel.StatusRejectionReceived, casemodel.OrgLawyers, "L001")
+	returned.Documents = []Document{
+		{ID: "DOC_1", ContentHash: plaintHash, Status: casemodel.DocumentActive, Validated: true, Verdict: casemodel.VerdictAccepted},
+		{ID: "DOC_2", ContentHash: plaintHash, Status: casemodel.DocumentSuperseded, Verdict: casemodel.VerdictRejected, ReasonCode: casemodel.ReasonUnsigned},
+		{ID: "DOC_2_V2", ContentHash: amendedHash, Status: casemodel.DocumentActive, Verdict: casemodel.VerdictNeedsCorrection, ReasonCode: casemodel.ReasonIllegible},
+	}
+	// resubmittable is returned with the affidavit that needs correction replaced, and the
+	// defect raised on it open
+	resubmittable := *returned
+	resubmittable.ID = "CASE_006"
+	resubmittable.Documents = append(append([]Document{}, returned.Documents[:2]...),
+		Document{ID: "DOC_2_V2", ContentHash: amendedHash, Status: casemodel.DocumentSuperseded, SupersedesID: "DOC_2", Verdict: casemodel.VerdictNeedsCorrection, ReasonCode: casemodel.ReasonIllegible},
+		Document{ID: "DOC_2_V3", ContentHash: plaintHash, Status: casemodel.DocumentActive, SupersedesID: "DOC_2_V2"},
+	)
+	resubmittable.Defects = []casemodel.Defect{{ID: "DEF_1", DocumentID: "DOC_2_V2", ReasonCode: casemodel.ReasonIllegible, Deadline: "2024-05-22T10:00:00Z", Status: casemodel.DefectOpen}}
+	// onHold is a case the stamp reporter put on hold with its affidavit to be corrected
+	onHold := newCase("CASE_005", casemodel.StatusOnHoldReceived, casemodel.OrgLawyers, "L001")
+	onHold.Documents = []Document{
+		{ID: "DOC_1", ContentHash: plaintHash, Version: 1, Status: casemodel.DocumentActive, Validated: true, Verdict: casemodel.VerdictAccepted},
+		{ID: "DOC_2", ContentHash: plaintHash, Version: 1, Status: casemodel.DocumentActive, Verdict: casemodel.VerdictNeedsCorrection, ReasonCode: casemodel.ReasonIllegible},
+	}
+	onHold.Defects = []casemodel.Defect{{ID: "DEF_1", DocumentID: "DOC_2", ReasonCode: casemodel.ReasonIllegible, Deadline: "2024-05-22T10:00:00Z", Status: casemodel.DefectOpen}}
+	cure := `{"comments":"Clear copy filed","cures":[{"defectId":"DEF_1","document":{"id":"DOC_2_V2","name":"affidavit.pdf","contentHash":"` + amendedHash + `"},"comments":"Rescanned"}]}`
+
+	// inCamera is criminal heard in camera with lawyer L002 on its access list
+	inCamera := *criminal
+	inCamera.Sealed, inCamera.AccessList = true, []string{"judge:J001", "lawyer:L002"}
+
+	// a transfer waiting in the bench clerk's outbox
+	fromBenchClerk := contract.Sealed(confirmed, benchClerk, casemodel.ChannelBenchClerkLawyer)
+	forLawyer := &casemodel.Transfer{Sequence: 1, CaseID: "CASE_009", Hop: casemodel.HopBenchClerkToLawyer, Function: "StoreCase", From: casemodel.OrgBenchClerks, To: casemodel.OrgLawyers, Case: fromBenchClerk, Status: casemodel.TransferPending}
+
+	// awaiting is a case waiting for registrar review; rejected is the copy the registrar
+	// hands back when rejecting it
+	awaiting := newCase("CASE_012", casemodel.StatusPendingRegistrarReview, casemodel.OrgRegistrars, "L001")
+	rejected := newCase("CASE_012", casemodel.StatusRejectedByRegistrar, casemodel.OrgLawyers, "L001")
+	rejected.History = []HistoryItem{{Status: casemodel.StatusRejectedByRegistrar, Organization: "RegistrarsOrg", Timestamp: "2024-05-14T10:00:00Z", Comments: "Cause of action not stated"}}
+	fromRegistrar := contract.Sealed(rejected, registrar, channel)
+	rejection := func(n *mockstub.Network) {
+		if _, err := n.Submit(channel, "lawyer", registrar, "ReceiveRegistrarRejection", string(contracttest.MustJSON(fromRegistrar))); err != nil {
+			panic(err)
+		}
+	}
+	forResubmission := &casemodel.Transfer{Sequence: 1, CaseID: "CASE_012", Hop: casemodel.HopRegistrarToLawyer, Function: "ReceiveRegistrarRejection", From: casemodel.OrgRegistrars, To: casemodel.OrgLawyers, Case: fromRegistrar, Status: casemodel.TransferPending}
+	noTransfers := contracttest.Fake(map[string]peer.Response{"ListPendingTransfers": shim.Success([]byte("[]"))})
+
+	var received *Case
+	registrarPeer := func(stub shim.ChaincodeStubInterface) peer.Response {
+		_, args := stub.GetFunctionAndParameters()
+		received, _ = casemodel.DecodeCase([]byte(args[0]))
+		return shim.Success(nil)
+	}
+
+	// chained is civil as the contract stores it, with its history chained; rewritten has
+	// an entry edited afterwards
+	withHistory := *civil
+	withHistory.History = []HistoryItem{{Status: "CREATED", Comments: "filed"}, {Status: civil.Status, Comments: "recorded"}}
+	chained := contract.Sealed(&withHistory, lawyerL001, channel)
+	rewritten := contract.Sealed(&withHistory, lawyerL001, channel)
+	rewritten.History[0].Comments = "withdrawn"
+
+	tests := []contracttest.TxTest{
+		{
+			Name:     "InitLedger",
+			Caller:   lawyerAdmin,
+			Function: "InitLedger",
+			Args:     []string{""},
+		},
+		{
+			Name:     "InitLedger by a lawyer who is not an administrator",
+			Caller:   lawyerL001,
+			Function: "InitLedger",
+			Args:     []string{""},
+			WantErr:  "access denied for InitLedger",
+		},
+		{
+			Name:     "SetRoutingConfig by a lawyer who is not an administrator",
+			Caller:   lawyerL001,
+			Function: "SetRoutingConfig",
+			Args:     []string{`{"routes":{}}`},
+			WantErr:  "access denied for SetRoutingConfig",
+		},
+		{
+			Name:     "SetOrgRoots by a lawyer who is not an administrator",
+			Caller:   lawyerL001,
+			Function: "SetOrgRoots",
+			Args:     []string{casemodel.OrgStampReporters, mockstub.RootCertificate("StampReportersOrgMSP")},
+			WantErr:  "access denied for SetOrgRoots",
+		},
+		{
+			Name:     "SetOrgRoots of an unknown organization",
+			Caller:   lawyerAdmin,
+			Function: "SetOrgRoots",
+			Args:     []string{"CourtsOrg", mockstub.RootCertificate("StampReportersOrgMSP")},
+			WantErr:  `unknown organization "CourtsOrg"`,
+		},
+		{
+			Name:     "SetOrgRoots of another organization",
+			Caller:   lawyerAdmin,
+			Function: "SetOrgRoots",
+			Args:     []string{casemodel.OrgJudges, mockstub.RootCertificate("JudgesOrgMSP")},
+			WantErr:  "a member of LawyersOrg cannot set the root certificates of JudgesOrg",
+		},
+		{
+			Name:     "CreateCase",
+			Caller:   lawyerL001,
+			Function: "CreateCase",
+			Args:     []string{`{"id":"CASE_001","title":"Land dispute"}`},
+			Check: func(t *testing.T, n *mockstub.Network, _ []byte) {
+				c := stored(t, n, "CASE_001")
+				if c.Status != casemodel.StatusCreated || c.CurrentOrg != casemodel.OrgLawyers {
+					t.Errorf("case is %s at %s", c.Status, c.CurrentOrg)
+				}
+				if strings.Join(c.AssociatedLawyers, ",") != "L001" {
+					t.Errorf("associated lawyers = %v, want the filing lawyer", c.AssociatedLawyers)
+				}
+				if c.CreatedAt != start.Format(time.RFC3339) || c.LastModified != c.CreatedAt {
+					t.Errorf("createdAt = %s, lastModified = %s", c.CreatedAt, c.LastModified)
+				}
+			},
+		},
+		{
+			Name:     "CreateCase with private details",
+			Caller:   lawyerL001,
+			Function: "CreateCase",
+			Args:     []string{`{"id":"CASE_001","description":"Boundary wall","documents":[{"id":"DOC_1","hash":"abc","contentHash":"` + plaintHash + `"}]}`},
+			Details:  `{"uidParty1":"P1","uidParty2":"P2","clientName":"Ravi Sharma",` + salts + `}`,
+			Check: func(t *testing.T, n *mockstub.Network, _ []byte) {
+				c := stored(t, n, "CASE_001")
+				if c.UIDParty1 != "" || c.UIDParty2 != "" || c.ClientName != "" || c.Description != "" || c.Documents[0].Hash != "" {
+					t.Errorf("private fields on the public case: %+v", c)
+				}
+				if d := c.Documents[0]; d.ContentHash != "" || d.ContentDigest != casemodel.ContentDigest(plaintHash) || d.UploadedAt != stamp || d.Version != 1 || d.Status != casemodel.DocumentActive {
+					t.Errorf("document = %+v", d)
+				}
+				if got := sealedDetails(n, "CASE_001"); got != `{"caseId":"CASE_001","clientName":"Ravi Sharma","description":"Boundary wall","documentHashes":{"DOC_1":"abc"},"contentHashes":{"DOC_1":"`+plaintHash+`"},"salts":{"sealedDetails":"`+sealedSalt+`"}}` {
+					t.Errorf("sealed details = %s", got)
+				}
+				if len(c.PrivateHashes) != 2 || c.PrivateHashes[casemodel.CollectionPartyIdentities] == "" {
+					t.Errorf("private hashes = %v", c.PrivateHashes)
+				}
+			},
+		},
+		{
+			Name:     "CreateCase with a document listed twice",
+			Caller:   lawyerL001,
+			Function: "CreateCase",
+			Args:     []string{`{"id":"CASE_001","documents":[{"id":"DOC_1","contentHash":"` + plaintHash + `"},{"id":"DOC_1","contentHash":"` + amendedHash + `"}]}`},
+			WantErr:  "document DOC_1 already exists in case CASE_001",
+		},
+		{
+			Name:     "CreateCase keeps listed lawyers",
+			Caller:   lawyerL001,
+			Function: "CreateCase",
+			Args:     []string{`{"id":"CASE_001","associatedLawyers":["L002","L001"]}`},
+			Check: func(t *testing.T, n *mockstub.Network, _ []byte) {
+				if got := strings.Join(stored(t, n, "CASE_001").AssociatedLawyers, ","); got != "L002,L001" {
+					t.Errorf("associated lawyers = %s", got)
+				}
+			},
+		},
+		{
+			Name:     "CreateCase with a claim value",
+			Caller:   lawyerL001,
+			Function: "CreateCase",
+			Args:     []string{`{"id":"CASE_001","type":"Civil","claimValue":5000000,"fees":{"paid":55000,"payments":[{"receiptRef":"GRN001","amount":55000}]}}`},
+			Check: func(t *testing.T, n *mockstub.Network, _ []byte) {
+				if c := stored(t, n, "CASE_001"); c.ClaimValue != 5000000 || c.Fees != nil {
+					t.Errorf("claim value = %d, fees = %+v", c.ClaimValue, c.Fees)
+				}
+			},
+		},
+		{
+			Name:     "CreateCase with a negative claim value",
+			Caller:   lawyerL001,
+			Function: "CreateCase",
+			Args:     []string{`{"id":"CASE_001","claimValue":-1}`},
+			WantErr:  "case CASE_001 has a negative claim value",
+		},
+		{
+			Name:     "CreateCase with a claim value too high",
+			Caller:   lawyerL001,
+			Function: "CreateCase",
+			Args:     []string{`{"id":"CASE_001","claimValue":900000000000000000}`},
+			WantErr:  "case CASE_001 has a claim value above 1000000000000000 paise",
+		},
+		{
+			Name:     "CreateCase duplicate",
+			Seed:     []*Case{civil},
+			Caller:   lawyerL001,
+			Function: "CreateCase",
+			Args:     []string{`{"id":"CASE_002"}`},
+			WantErr:  "case already exists: CASE_002",
+		},
+		{
+			Name:     "CreateCase invalid JSON",
+			Caller:   lawyerL001,
+			Function: "CreateCase",
+			Args:     []string{`{`},
+			WantErr:  "failed to unmarshal case data",
+		},
+		{
+			Name:     "CreateCase by registrar",
+			Caller:   registrar,
+			Function: "CreateCase",
+			Args:     []string{`{"id":"CASE_001"}`},
+			WantErr:  "access denied for CreateCase",
+		},
+		{
+			Name:     "CaseExists",
+			Seed:     []*Case{civil},
+			Caller:   lawyerL001,
+			Function: "CaseExists",
+			Args:     []string{"CASE_002"},
+			Check: func(t *testing.T, _ *mockstub.Network, payload []byte) {
+				if string(payload) != "true" {
+					t.Errorf("CaseExists = %s", payload)
+				}
+			},
+		},
+		{
+			Name:     "CaseExists missing",
+			Caller:   lawyerL001,
+			Function: "CaseExists",
+			Args:     []string{"CASE_404"},
+			Check: func(t *testing.T, _ *mockstub.Network, payload []byte) {
+				if string(payload) != "false" {
+					t.Errorf("CaseExists = %s", payload)
+				}
+			},
+		},
+		{
+			Name:     "SubmitToRegistrar",
+			Seed:     []*Case{civil},
+			Peers:    map[string]mockstub.ChaincodeFunc{"registrar": registrarPeer},
+			Caller:   lawyerL001,
+			Function: "SubmitToRegistrar",
+			Args:     []string{"CASE_002"},
+			Check: func(t *testing.T, n *mockstub.Network, _ []byte) {
+				c := stored(t, n, "CASE_002")
+				if c.Status != casemodel.StatusPendingRegistrarReview || c.CurrentOrg != casemodel.OrgRegistrars {
+					t.Errorf("case is %s at %s", c.Status, c.CurrentOrg)
+				}
+				if len(c.History) != 1 || c.History[0].Status != "SUBMITTED_TO_REGISTRAR" || c.History[0].Timestamp != stamp {
+					t.Errorf("history = %+v", c.History)
+				}
+				if received == nil || received.Status != casemodel.StatusPendingRegistrarReview {
+					t.Errorf("registrar received %+v", received)
+				}
+				if pending := contract.Outbox(t, n); len(pending) != 0 {
+					t.Errorf("pending transfers = %+v", pending)
+				}
+				events := n.Events()
+				if len(events) != 1 || events[0].Name != casemodel.StatusChangedEventName(casemodel.OrgRegistrars) {
+					t.Errorf("events = %+v", events)
+				}
+			},
+		},
+		{
+			Name: "SubmitToRegistrar rejected by registrar",
+			Seed: []*Case{civil},
+			Peers: map[string]mockstub.ChaincodeFunc{"registrar": contracttest.Fake(map[string]peer.Response{
+				"ReceiveCase": shim.Error("invalid case status or organization"),
+			})},
+			Caller:   lawyerL001,
+			Function: "SubmitToRegistrar",
+			Args:     []string{"CASE_002"},
+			Check: func(t *testing.T, n *mockstub.Network, _ []byte) {
+				if c := stored(t, n, "CASE_002"); c.Status != casemodel.StatusPendingRegistrarReview {
+					t.Errorf("case is %s", c.Status)
+				}
+				pending := contract.Outbox(t, n)
+				if len(pending) != 1 || pending[0].Function != "ReceiveCase" || pending[0].To != casemodel.OrgRegistrars || pending[0].LastError != "invalid case status or organization" {
+					t.Errorf("pending transfers = %+v", pending)
+				}
+			},
+		},
+		{
+			Name: "SubmitToRegistrar claimed outside ReceiveTransfers",
+			Seed: []*Case{civil},
+			Peers: map[string]mockstub.ChaincodeFunc{"registrar": contracttest.Fake(map[string]peer.Response{
+				"ReceiveCase": shim.Error("ledger unavailable"),
+			})},
+			Setup: func(n *mockstub.Network) {
+				if _, err := n.Submit(channel, "lawyer", lawyerL001, "SubmitToRegistrar", "CASE_002"); err != nil {
+					panic(err)
+				}
+			},
+			Caller:   registrar,
+			Function: "ClaimTransfer",
+			Args:     []string{"1"},
+			WantErr:  "transfer 1 can only be claimed by ReceiveTransfers of registrar, not ClaimTransfer of lawyer",
+		},
+		{
+			Name:     "SubmitToRegistrar rejected case",
+			Seed:     []*Case{rejected},
+			Caller:   lawyerL001,
+			Function: "SubmitToRegistrar",
+			Args:     []string{"CASE_012"},
+			WantErr:  "case CASE_012 was rejected by the registrar, use ResubmitToRegistrar",
+		},
+		{
+			Name:     "ReceiveRegistrarRejection",
+			Seed:     []*Case{awaiting},
+			Caller:   registrar,
+			Function: "ReceiveRegistrarRejection",
+			Args:     []string{string(contracttest.MustJSON(fromRegistrar))},
+			Check: func(t *testing.T, n *mockstub.Network, _ []byte) {
+				// the link of the receiving transaction follows the registrar's
+				if c := stored(t, n, "CASE_012"); c.Status != casemodel.StatusRejectedByRegistrar || c.CurrentOrg != casemodel.OrgLawyers || len(c.HashChain) != 2 || c.HashChain[1].Previous != fromRegistrar.HashChain[0].Hash {
+					t.Errorf("case = %+v", c)
+				}
+			},
+		},
+		{
+			Name:     "ReceiveRegistrarRejection of case not awaiting review",
+			Seed:     []*Case{civil},
+			Caller:   registrar,
+			Function: "ReceiveRegistrarRejection",
+			Args:     []string{string(contracttest.MustJSON(newCase("CASE_002", casemodel.StatusRejectedByRegistrar, casemodel.OrgLawyers, "L001")))},
+			WantErr:  "case CASE_002 is CREATED, only a case awaiting registrar review can be rejected",
+		},
+		{
+			Name:     "ReceiveRegistrarRejection not rejected",
+			Seed:     []*Case{awaiting},
+			Caller:   registrar,
+			Function: "ReceiveRegistrarRejection",
+			Args:     []string{string(contracttest.MustJSON(awaiting))},
+			WantErr:  "invalid case status or organization: status=PENDING_REGISTRAR_REVIEW, org=RegistrarsOrg",
+		},
+		{
+			Name:     "ReceiveRegistrarRejection unsealed",
+			Seed:     []*Case{awaiting},
+			Caller:   registrar,
+			Function: "ReceiveRegistrarRejection",
+			Args:     []string{string(contracttest.MustJSON(rejected))},
+			WantErr:  "case CASE_012 has no hash chain",
+		},
+		{
+			Name:     "ReceiveRegistrarRejection by lawyer",
+			Seed:     []*Case{awaiting},
+			Caller:   lawyerL001,
+			Function: "ReceiveRegistrarRejection",
+			Args:     []string{string(contracttest.MustJSON(fromRegistrar))},
+			WantErr:  "access denied for ReceiveRegistrarRejection",
+		},
+		{
+			Name:  "ResubmitToRegistrar",
+			Seed:  []*Case{awaiting},
+			Peers: map[string]mockstub.ChaincodeFunc{"registrar": registrarPeer},
+			Setup: func(n *mockstub.Network) {
+				rejection(n)
+				if _, err := n.Submit(channel, "lawyer", lawyerL001, "UpdateCaseDetails", "CASE_012", `{"title":"Sharma vs Patel"}`); err != nil {
+					panic(err)
+				}
+				received = nil
+			},
+			Caller:   lawyerL001,
+			Function: "ResubmitToRegistrar",
+			Args:     []string{"CASE_012"},
+			Check: func(t *testing.T, n *mockstub.Network, _ []byte) {
+				c := stored(t, n, "CASE_012")
+				if c.Status != casemodel.StatusPendingRegistrarReview || c.CurrentOrg != casemodel.OrgRegistrars || c.ResubmissionCount != 1 {
+					t.Errorf("case is %s at %s after %d resubmissions", c.Status, c.CurrentOrg, c.ResubmissionCount)
+				}
+				if len(c.Resubmissions) != 1 {
+					t.Fatalf("resubmissions = %+v", c.Resubmissions)
+				}
+				r := c.Resubmissions[0]
+				if r.Attempt != 1 || r.RejectionReason != "Cause of action not stated" || r.RejectedAt != "2024-05-14T10:00:00Z" || len(r.Changes) != 1 || r.Changes[0].Field != "title" || r.Changes[0].After != `"Sharma vs Patel"` {
+					t.Errorf("resubmission = %+v", r)
+				}
+				if last := c.History[len(c.History)-1]; last.Status != "RESUBMITTED_TO_REGISTRAR" || last.Comments != "Resubmission 1, 1 fields changed since the rejection" {
+					t.Errorf("history = %+v", c.History)
+				}
+				if received == nil || received.ResubmissionCount != 1 || received.Status != casemodel.StatusPendingRegistrarReview {
+					t.Errorf("registrar received %+v", received)
+				}
+			},
+		},
+		{
+			Name:     "ResubmitToRegistrar unchanged",
+			Seed:     []*Case{awaiting},
+			Setup:    rejection,
+			Caller:   lawyerL001,
+			Function: "ResubmitToRegistrar",
+			Args:     []string{"CASE_012"},
+			WantErr:  "case CASE_012 has not changed since the registrar rejected it",
+		},
+		{
+			Name:     "ResubmitToRegistrar not rejected",
+			Seed:     []*Case{awaiting},
+			Caller:   lawyerL001,
+			Function: "ResubmitToRegistrar",
+			Args:     []string{"CASE_012"},
+			WantErr:  "case CASE_012 is PENDING_REGISTRAR_REVIEW, only a case the registrar rejected can be resubmitted",
+		},
+		{
+			Name:     "ResubmitToRegistrar by lawyer not on case",
+			Seed:     []*Case{rejected},
+			Caller:   lawyerL002,
+			Function: "ResubmitToRegistrar",
+			Args:     []string{"CASE_012"},
+			WantErr:  "access denied for case CASE_012",
+		},
+		{
+			Name:     "ClaimTransfer by bench clerk",
+			Caller:   benchClerk,
+			Function: "ClaimTransfer",
+			Args:     []string{"1"},
+			WantErr:  "access denied for ClaimTransfer",
+		},
+		{
+			Name: "RetryTransfer",
+			Seed: []*Case{civil},
+			Setup: func(n *mockstub.Network) {
+				n.Install("registrar", contracttest.Fake(map[string]peer.Response{"ReceiveCase": shim.Error("ledger unavailable")}))
+				if _, err := n.Submit(channel, "lawyer", lawyerL001, "SubmitToRegistrar", "CASE_002"); err != nil {
+					panic(err)
+				}
+				received = nil
+				n.Install("registrar", mockstub.ChaincodeFunc(registrarPeer))
+			},
+			Caller:   lawyerL001,
+			Function: "RetryTransfer",
+			Args:     []string{"1"},
+			Check: func(t *testing.T, n *mockstub.Network, payload []byte) {
+				var transfer casemodel.Transfer
+				contracttest.Decode(t, payload, &transfer)
+				if transfer.Status != casemodel.TransferClaimed || transfer.Attempts != 2 {
+					t.Errorf("transfer = %s", payload)
+				}
+				if received == nil || received.ID != "CASE_002" {
+					t.Errorf("registrar received %+v", received)
+				}
+			},
+		},
+		{
+			Name:     "RetryTransfer by registrar",
+			Caller:   registrar,
+			Function: "RetryTransfer",
+			Args:     []string{"1"},
+			WantErr:  "access denied for RetryTransfer",
+		},
+		{
+			Name:     "SubmitToRegistrar by another lawyer",
+			Seed:     []*Case{civil},
+			Caller:   lawyerL002,
+			Function: "SubmitToRegistrar",
+			Args:     []string{"CASE_002"},
+			WantErr:  "lawyer is not associated with the case",
+		},
+		{
+			Name:     "SubmitToRegistrar twice",
+			Seed:     []*Case{newCase("CASE_002", casemodel.StatusPendingRegistrarReview, casemodel.OrgRegistrars, "L001")},
+			Caller:   lawyerL001,
+			Function: "SubmitToRegistrar",
+			Args:     []string{"CASE_002"},
+			WantErr:  "cannot move from PENDING_REGISTRAR_REVIEW to PENDING_REGISTRAR_REVIEW",
+		},
+		{
+			Name:     "SubmitToRegistrar missing case",
+			Caller:   lawyerL001,
+			Function: "SubmitToRegistrar",
+			Args:     []string{"CASE_404"},
+			WantErr:  "case does not exist: CASE_404",
+		},
+		{
+			Name:     "GetCase",
+			Seed:     []*Case{civil},
+			Caller:   lawyerL001,
+			Function: "GetCase",
+			Args:     []string{"CASE_002"},
+			Check: func(t *testing.T, _ *mockstub.Network, payload []byte) {
+				var c Case
+				contracttest.Decode(t, payload, &c)
+				if c.ID != "CASE_002" || c.Department != "Civil" {
+					t.Errorf("GetCase = %+v", c)
+				}
+			},
+		},
+		{
+			Name: "GetCase fetches from bench clerk",
+			Peers: map[string]mockstub.ChaincodeFunc{"benchclerk": contracttest.Fake(map[string]peer.Response{
+				"GetCaseById": shim.Success(contracttest.MustJSON(contract.Sealed(confirmed, benchClerk, casemodel.ChannelBenchClerkLawyer))),
+			})},
+			Caller:   lawyerL001,
+			Function: "GetCase",
+			Args:     []string{"CASE_009"},
+			Check: func(t *testing.T, n *mockstub.Network, _ []byte) {
+				history := stored(t, n, "CASE_009").History
+				if last := history[len(history)-1]; last.Status != "RECEIVED_BY_LAWYER" {
+					t.Errorf("last history entry = %+v", last)
+				}
+			},
+		},
+		{
+			Name: "GetCase from bench clerk not held by lawyers",
+			Peers: map[string]mockstub.ChaincodeFunc{"benchclerk": contracttest.Fake(map[string]peer.Response{
+				"GetCaseById": shim.Success(contracttest.MustJSON(contract.Sealed(newCase("CASE_009", casemodel.StatusJudgmentIssued, casemodel.OrgBenchClerks), benchClerk, casemodel.ChannelBenchClerkLawyer))),
+			})},
+			Caller:   lawyerL001,
+			Function: "GetCase",
+			Args:     []string{"CASE_009"},
+			WantErr:  "case CASE_009 is not currently assigned to LawyersOrg",
+		},
+		{
+			Name:     "GetCase sealed",
+			Seed:     []*Case{&inCamera},
+			Caller:   lawyerL001,
+			Function: "GetCase",
+			Args:     []string{"CASE_003"},
+			WantErr:  "case is sealed",
+		},
+		{
+			Name:     "GetCase missing everywhere",
+			Peers:    map[string]mockstub.ChaincodeFunc{"benchclerk": contracttest.Fake(map[string]peer.Response{"GetCaseById": shim.Error("case CASE_404 does not exist")})},
+			Caller:   lawyerL001,
+			Function: "GetCase",
+			Args:     []string{"CASE_404"},
+			WantErr:  "Failed to fetch case from BenchClerk channel: case CASE_404 does not exist",
+		},
+		{
+			Name:     "UpdateCaseDetails",
+			Seed:     []*Case{civil},
+			Caller:   lawyerL001,
+			Function: "UpdateCaseDetails",
+			Args:     []string{"CASE_002", `{"title":"Boundary dispute","clientName":"A. Kumar","department":"Revenue"}`},
+			Details:  `{` + salts + `}`,
+			Check: func(t *testing.T, n *mockstub.Network, _ []byte) {
+				c := stored(t, n, "CASE_002")
+				if c.Title != "Boundary dispute" || c.ClientName != "" || c.Department != "Revenue" {
+					t.Errorf("case = %+v", c)
+				}
+				if got := sealedDetails(n, "CASE_002"); got != `{"caseId":"CASE_002","clientName":"A. Kumar","salts":{"sealedDetails":"`+sealedSalt+`"}}` {
+					t.Errorf("sealed details = %s", got)
+				}
+			},
+		},
+		{
+			Name: "UpdateCaseDetails with private details",
+			Seed: []*Case{civil},
+			Setup: func(n *mockstub.Network) {
+				if _, err := n.SubmitTransient(channel, "lawyer", lawyerL001, map[string][]byte{casemodel.TransientPrivateDetails: []byte(`{"clientName":"A. Kumar",` + salts + `}`)}, "UpdateCaseDetails", "CASE_002", `{}`); err != nil {
+					panic(err)
+				}
+			},
+			Caller:   lawyerL001,
+			Function: "UpdateCaseDetails",
+			Args:     []string{"CASE_002", `{"department":"Revenue"}`},
+			Details:  `{"description":"Encroachment"}`,
+			Check: func(t *testing.T, n *mockstub.Network, _ []byte) {
+				c := stored(t, n, "CASE_002")
+				if got := sealedDetails(n, "CASE_002"); got != `{"caseId":"CASE_002","clientName":"A. Kumar","description":"Encroachment","salts":{"sealedDetails":"`+sealedSalt+`"}}` {
+					t.Errorf("sealed details = %s", got)
+				}
+				if c.PrivateHashes[casemodel.CollectionSealedDetails] == "" || c.Description != "" {
+					t.Errorf("case = %+v", c)
+				}
+			},
+		},
+		{
+			Name:     "UpdateCaseDetails by another lawyer",
+			Seed:     []*Case{civil},
+			Caller:   lawyerL002,
+			Function: "UpdateCaseDetails",
+			Args:     []string{"CASE_002", `{"title":"Boundary dispute"}`},
+			WantErr:  "lawyer is not associated with the case",
+		},
+		{
+			Name:     "UpdateCaseDetails invalid JSON",
+			Caller:   lawyerL001,
+			Function: "UpdateCaseDetails",
+			Args:     []string{"CASE_002", `title`},
+			WantErr:  "failed to unmarshal updates",
+		},
+		{
+			Name:     "GetCasesByFilter",
+			Seed:     []*Case{civil, criminal},
+			Caller:   lawyerL001,
+			Function: "GetCasesByFilter",
+			Args:     []string{`{"department":"Civil"}`},
+			Check: func(t *testing.T, _ *mockstub.Network, payload []byte) {
+				var cases []*Case
+				contracttest.Decode(t, payload, &cases)
+				if got := ids(cases); got != "CASE_002" {
+					t.Errorf("cases = %s", got)
+				}
+			},
+		},
+		{
+			Name:     "GetCasesByFilter invalid sort",
+			Caller:   lawyerL001,
+			Function: "GetCasesByFilter",
+			Args:     []string{`{"sortBy":"title"}`},
+			WantErr:  `cannot sort cases by "title"`,
+		},
+		{
+			Name:     "GetCasesByFilterWithPagination",
+			Seed:     []*Case{civil, criminal},
+			Caller:   lawyerL001,
+			Function: "GetCasesByFilterWithPagination",
+			Args:     []string{`{}`, "1", ""},
+			Check: func(t *testing.T, _ *mockstub.Network, payload []byte) {
+				var page CasePage
+				contracttest.Decode(t, payload, &page)
+				if ids(page.Records) != "CASE_002" || page.FetchedCount != 1 || page.Bookmark != "CASE_002" {
+					t.Errorf("page = %+v", page)
+				}
+			},
+		},
+		{
+			Name:     "AddDocumentToCase",
+			Seed:     []*Case{civil},
+			Caller:   lawyerL001,
+			Function: "AddDocumentToCase",
+			Args:     []string{"CASE_002", `{"id":"DOC_1","name":"plaint.pdf","hash":"abc","contentHash":"` + plaintHash + `","signatureHash":"forged","validated":true}`},
+			Details:  `{` + salts + `}`,
+			Check: func(t *testing.T, n *mockstub.Network, _ []byte) {
+				docs := stored(t, n, "CASE_002").Documents
+				if len(docs) != 1 || docs[0].ID != "DOC_1" || docs[0].Validated || docs[0].UploadedAt != stamp || docs[0].Hash != "" || docs[0].ContentHash != "" || docs[0].ContentDigest != casemodel.ContentDigest(plaintHash) || docs[0].SignatureHash != "" {
+					t.Errorf("documents = %+v", docs)
+				}
+				if got := sealedDetails(n, "CASE_002"); got != `{"caseId":"CASE_002","documentHashes":{"DOC_1":"abc"},"contentHashes":{"DOC_1":"`+plaintHash+`"},"salts":{"sealedDetails":"`+sealedSalt+`"}}` {
+					t.Errorf("sealed details = %s", got)
+				}
+			},
+		},
+		{
+			Name:     "AddDocumentToCase without a content hash",
+			Seed:     []*Case{civil},
+			Caller:   lawyerL001,
+			Function: "AddDocumentToCase",
+			Args:     []string{"CASE_002", `{"id":"DOC_1","name":"plaint.pdf","hash":"abc"}`},
+			WantErr:  "document DOC_1 must have a contentHash",
+		},
+		{
+			Name:     "AddDocumentToCase by another lawyer",
+			Seed:     []*Case{civil},
+			Caller:   lawyerL002,
+			Function: "AddDocumentToCase",
+			Args:     []string{"CASE_002", `{"id":"DOC_1"}`},
+			WantErr:  "lawyer is not associated with the case",
+		},
+		{
+			Name:     "AddDocumentToCase invalid JSON",
+			Caller:   lawyerL001,
+			Function: "AddDocumentToCase",
+			Args:     []string{"CASE_002", `[]`},
+			WantErr:  "failed to unmarshal document",
+		},
+		{
+			Name:     "AddDocumentToCase existing document",
+			Seed:     []*Case{&withPlaint},
+			Caller:   lawyerL001,
+			Function: "AddDocumentToCase",
+			Args:     []string{"CASE_002", `{"id":"DOC_1","contentHash":"` + amendedHash + `"}`},
+			WantErr:  "document DOC_1 already exists in case CASE_002, use ReplaceDocument",
+		},
+		{
+			Name:     "ReplaceDocument",
+			Seed:     []*Case{&withPlaint},
+			Caller:   lawyerL001,
+			Function: "ReplaceDocument",
+			Args:     []string{"CASE_002", "DOC_1", `{"id":"DOC_1_V2","name":"plaint.pdf","hash":"def","contentHash":"` + amendedHash + `"}`},
+			Details:  `{` + salts + `}`,
+			Check: func(t *testing.T, n *mockstub.Network, _ []byte) {
+				docs := stored(t, n, "CASE_002").Documents
+				if len(docs) != 2 || docs[0].Status != casemodel.DocumentSuperseded || docs[0].ContentHash != plaintHash {
+					t.Fatalf("documents = %+v", docs)
+				}
+				if v2 := docs[1]; v2.ID != "DOC_1_V2" || v2.Version != 2 || v2.SupersedesID != "DOC_1" || !v2.Active() || v2.ContentHash != "" || v2.ContentDigest != casemodel.ContentDigest(amendedHash) || v2.Hash != "" {
+					t.Errorf("new version = %+v", v2)
+				}
+				if got := sealedDetails(n, "CASE_002"); got != `{"caseId":"CASE_002","documentHashes":{"DOC_1_V2":"def"},"contentHashes":{"DOC_1_V2":"`+amendedHash+`"},"salts":{"sealedDetails":"`+sealedSalt+`"}}` {
+					t.Errorf("sealed details = %s", got)
+				}
+			},
+		},
+		{
+			Name:     "ReplaceDocument superseded version",
+			Seed:     []*Case{&replaced},
+			Caller:   lawyerL001,
+			Function: "ReplaceDocument",
+			Args:     []string{"CASE_002", "DOC_1", `{"id":"DOC_1_V3","contentHash":"` + amendedHash + `"}`},
+			WantErr:  "document DOC_1 of case CASE_002 is SUPERSEDED",
+		},
+		{
+			Name:     "ReplaceDocument by another lawyer",
+			Seed:     []*Case{&withPlaint},
+			Caller:   lawyerL002,
+			Function: "ReplaceDocument",
+			Args:     []string{"CASE_002", "DOC_1", `{"id":"DOC_1_V2","contentHash":"` + amendedHash + `"}`},
+			WantErr:  "lawyer is not associated with the case",
+		},
+		{
+			Name:     "WithdrawDocument",
+			Seed:     []*Case{&replaced},
+			Caller:   lawyerL001,
+			Function: "WithdrawDocument",
+			Args:     []string{"CASE_002", "DOC_1_V2"},
+			Check: func(t *testing.T, n *mockstub.Network, _ []byte) {
+				docs := stored(t, n, "CASE_002").Documents
+				if len(docs) != 2 || docs[0].Status != casemodel.DocumentSuperseded || docs[1].Status != casemodel.DocumentWithdrawn {
+					t.Errorf("documents = %+v", docs)
+				}
+			},
+		},
+		{
+			Name:     "WithdrawDocument superseded version",
+			Seed:     []*Case{&replaced},
+			Caller:   lawyerL001,
+			Function: "WithdrawDocument",
+			Args:     []string{"CASE_002", "DOC_1"},
+			WantErr:  "document DOC_1 of case CASE_002 is SUPERSEDED",
+		},
+		{
+			Name:     "GetRejectedDocuments",
+			Seed:     []*Case{returned},
+			Caller:   lawyerL001,
+			Function: "GetRejectedDocuments",
+			Args:     []string{"CASE_004"},
+			Check: func(t *testing.T, _ *mockstub.Network, payload []byte) {
+				var docs []Document
+				contracttest.Decode(t, payload, &docs)
+				if len(docs) != 1 || docs[0].ID != "DOC_2_V2" || docs[0].ReasonCode != casemodel.ReasonIllegible {
+					t.Errorf("documents = %+v", docs)
+				}
+			},
+		},
+		{
+			Name:     "GetCaseDefects",
+			Seed:     []*Case{onHold},
+			Caller:   lawyerL001,
+			Function: "GetCaseDefects",
+			Args:     []string{"CASE_005"},
+			Check: func(t *testing.T, _ *mockstub.Network, payload []byte) {
+				var defects []casemodel.Defect
+				contracttest.Decode(t, payload, &defects)
+				if len(defects) != 1 || defects[0].ID != "DEF_1" || defects[0].Status != casemodel.DefectOpen {
+					t.Errorf("defects = %s", payload)
+				}
+			},
+		},
+		{
+			Name:     "CureDefects",
+			Seed:     []*Case{onHold},
+			Peers:    map[string]mockstub.ChaincodeFunc{"stampreporter": contracttest.Fake(map[string]peer.Response{"ReceiveCuredCase": shim.Success(nil)})},
+			Setup:    contract.Reroute(casemodel.HopLawyerToStampReporter, casemodel.Route{Chaincode: "stampreporter"}),
+			Caller:   lawyerL001,
+			Function: "CureDefects",
+			Args:     []string{"CASE_005", cure},
+			Details:  `{` + salts + `}`,
+			Check: func(t *testing.T, n *mockstub.Network, _ []byte) {
+				c := stored(t, n, "CASE_005")
+				if c.Status != casemodel.StatusPendingStampReporterReview || c.CurrentOrg != casemodel.OrgStampReporters {
+					t.Errorf("case is %s at %s", c.Status, c.CurrentOrg)
+				}
+				if d := c.Defect("DEF_1"); d.Status != casemodel.DefectCured || d.CureDocumentID != "DOC_2_V2" || d.CureComments != "Rescanned" || d.Late {
+					t.Errorf("defect = %+v", d)
+				}
+				if doc := c.Document("DOC_2_V2"); doc == nil || doc.SupersedesID != "DOC_2" || doc.Verdict != "" {
+					t.Errorf("documents = %+v", c.Documents)
+				}
+				if h := c.History[len(c.History)-1]; h.Status != "DEFECTS_CURED" || h.Comments != "Defects cured: DEF_1. Clear copy filed" {
+					t.Errorf("history = %+v", h)
+				}
+				if pending := contract.Outbox(t, n); len(pending) != 0 {
+					t.Errorf("pending transfers = %+v", pending)
+				}
+			},
+		},
+		{
+			Name:     "CureDefects on another channel",
+			Seed:     []*Case{onHold},
+			Caller:   lawyerL001,
+			Function: "CureDefects",
+			Args:     []string{"CASE_005", cure},
+			Details:  `{` + salts + `}`,
+			Check: func(t *testing.T, n *mockstub.Network, _ []byte) {
+				pending := contract.Outbox(t, n)
+				if len(pending) != 1 || pending[0].Function != "ReceiveCuredCase" || pending[0].To != casemodel.OrgStampReporters {
+					t.Errorf("pending transfers = %+v", pending)
+				}
+			},
+		},
+		{
+			Name:     "CureDefects leaving a defect open",
+			Seed:     []*Case{onHold},
+			Caller:   lawyerL001,
+			Function: "CureDefects",
+			Args:     []string{"CASE_005", `{"cures":[]}`},
+			WantErr:  "defect DEF_1 of case CASE_005 is still open",
+		},
+		{
+			Name:     "CureDefects of a rejected case",
+			Seed:     []*Case{returned},
+			Caller:   lawyerL001,
+			Function: "CureDefects",
+			Args:     []string{"CASE_004", `{"cures":[]}`},
+			WantErr:  "case CASE_004 is REJECTION_RECEIVED, only on-hold cases are returned with defects cured",
+		},
+		{
+			Name:     "CureDefects by another lawyer",
+			Seed:     []*Case{onHold},
+			Caller:   lawyerL002,
+			Function: "CureDefects",
+			Args:     []string{"CASE_005", cure},
+			WantErr:  "lawyer is not associated with the case",
+		},
+		{
+			Name:     "ResubmitToStampReporter",
+			Seed:     []*Case{&resubmittable},
+			Peers:    map[string]mockstub.ChaincodeFunc{"stampreporter": contracttest.Fake(map[string]peer.Response{"ReceiveResubmittedCase": shim.Success(nil)})},
+			Setup:    contract.Reroute(casemodel.HopLawyerToStampReporter, casemodel.Route{Chaincode: "stampreporter"}),
+			Caller:   lawyerL001,
+			Function: "ResubmitToStampReporter",
+			Args:     []string{"CASE_006", "Clear copy filed"},
+			Check: func(t *testing.T, n *mockstub.Network, _ []byte) {
+				c := stored(t, n, "CASE_006")
+				if c.Status != casemodel.StatusPendingStampReporterReview || c.CurrentOrg != casemodel.OrgStampReporters {
+					t.Errorf("case is %s at %s", c.Status, c.CurrentOrg)
+				}
+				if d := c.Defect("DEF_1"); d.Status != casemodel.DefectCured || d.CureDocumentID != "DOC_2_V3" {
+					t.Errorf("defect = %+v", d)
+				}
+				if h := c.History[len(c.History)-1]; h.Status != "RESUBMITTED_TO_STAMP_REPORTER" || h.Comments != "Case resubmitted with its rejected documents replaced, defects cured: DEF_1. Clear copy filed" {
+					t.Errorf("history = %+v", h)
+				}
+				if pending := contract.Outbox(t, n); len(pending) != 0 {
+					t.Errorf("pending transfers = %+v", pending)
+				}
+			},
+		},
+		{
+			Name:     "ResubmitToStampReporter on another channel",
+			Seed:     []*Case{&resubmittable},
+			Caller:   lawyerL001,
+			Function: "ResubmitToStampReporter",
+			Args:     []string{"CASE_006", ""},
+			Check: func(t *testing.T, n *mockstub.Network, _ []byte) {
+				pending := contract.Outbox(t, n)
+				if len(pending) != 1 || pending[0].Function != "ReceiveResubmittedCase" || pending[0].To != casemodel.OrgStampReporters {
+					t.Errorf("pending transfers = %+v", pending)
+				}
+			},
+		},
+		{
+			Name:     "ResubmitToStampReporter with a document not replaced",
+			Seed:     []*Case{returned},
+			Caller:   lawyerL001,
+			Function: "ResubmitToStampReporter",
+			Args:     []string{"CASE_004", ""},
+			WantErr:  "document DOC_2_V2 of case CASE_004 is NEEDS_CORRECTION and must be replaced before the case is resubmitted",
+		},
+		{
+			Name:     "ResubmitToStampReporter of an on-hold case",
+			Seed:     []*Case{onHold},
+			Caller:   lawyerL001,
+			Function: "ResubmitToStampReporter",
+			Args:     []string{"CASE_005", ""},
+			WantErr:  "case CASE_005 is ON_HOLD_RECEIVED, only cases the stamp reporter rejected are resubmitted to it",
+		},
+		{
+			Name:     "ResubmitToStampReporter by another lawyer",
+			Seed:     []*Case{&resubmittable},
+			Caller:   lawyerL002,
+			Function: "ResubmitToStampReporter",
+			Args:     []string{"CASE_006", ""},
+			WantErr:  "lawyer is not associated with the case",
+		},
+		{
+			Name:     "GetConfirmedDecisions",
+			Seed:     []*Case{civil, confirmed},
+			Caller:   lawyerL001,
+			Function: "GetConfirmedDecisions",
+			Check: func(t *testing.T, _ *mockstub.Network, payload []byte) {
+				var cases []*Case
+				contracttest.Decode(t, payload, &cases)
+				if got := ids(cases); got != "CASE_009" {
+					t.Errorf("cases = %s", got)
+				}
+			},
+		},
+		{
+			Name: "QueryStats",
+			Seed: []*Case{
+				civil,
+				newCase("CASE_004", casemodel.StatusPendingRegistrarReview, casemodel.OrgRegistrars, "L001"),
+				newCase("CASE_005", casemodel.StatusPendingJudgeReview, casemodel.OrgJudges, "L001"),
+				confirmed,
+			},
+			Caller:   lawyerL001,
+			Function: "QueryStats",
+			Check: func(t *testing.T, _ *mockstub.Network, payload []byte) {
+				want := `{"totalCases":4,"pendingCases":1,"inProgressCases":1,"completedCases":1,"decisionConfirmed":1}`
+				if string(payload) != want {
+					t.Errorf("QueryStats = %s, want %s", payload, want)
+				}
+			},
+		},
+		{
+			Name:     "ViewJudgmentDetails",
+			Seed:     []*Case{confirmed},
+			Caller:   lawyerL001,
+			Function: "ViewJudgmentDetails",
+			Args:     []string{"CASE_009"},
+			Check: func(t *testing.T, _ *mockstub.Network, payload []byte) {
+				var details struct {
+					Decision    string   `json:"decision"`
+					JudgeID     string   `json:"judgeId"`
+					ConfirmedAt string   `json:"confirmedAt"`
+					History     []string `json:"history"`
+				}
+				contracttest.Decode(t, payload, &details)
+				if details.Decision != "Appeal allowed" || details.JudgeID != "J001" || details.ConfirmedAt != "2024-05-02T10:00:00Z" || len(details.History) != 2 {
+					t.Errorf("details = %+v", details)
+				}
+			},
+		},
+		{
+			Name:     "ViewJudgmentDetails before confirmation",
+			Seed:     []*Case{civil},
+			Caller:   lawyerL001,
+			Function: "ViewJudgmentDetails",
+			Args:     []string{"CASE_002"},
+			WantErr:  "case CASE_002 does not have a confirmed decision",
+		},
+		{
+			Name:     "GetAllCases",
+			Seed:     []*Case{civil, criminal, confirmed},
+			Caller:   lawyerL001,
+			Function: "GetAllCases",
+			Check: func(t *testing.T, _ *mockstub.Network, payload []byte) {
+				var cases []*Case
+				contracttest.Decode(t, payload, &cases)
+				if got := ids(cases); got != "CASE_002,CASE_003,CASE_009" {
+					t.Errorf("cases = %s", got)
+				}
+			},
+		},
+		{
+			Name:     "GetAllCases sealed",
+			Seed:     []*Case{civil, &inCamera},
+			Caller:   lawyerL001,
+			Function: "GetAllCases",
+			Check: func(t *testing.T, _ *mockstub.Network, payload []byte) {
+				var cases []*Case
+				contracttest.Decode(t, payload, &cases)
+				if len(cases) != 2 || cases[0].Department != "Civil" {
+					t.Fatalf("cases = %s", payload)
+				}
+				if stub := cases[1]; stub.ID != "CASE_003" || !stub.Sealed || stub.Status != casemodel.StatusCreated || stub.CurrentOrg != casemodel.OrgLawyers || stub.Department != "" || len(stub.AssociatedLawyers) != 0 {
+					t.Errorf("sealed case = %s", contracttest.MustJSON(stub))
+				}
+			},
+		},
+		{
+			Name:     "GetAllCases sealed by a listed lawyer",
+			Seed:     []*Case{&inCamera},
+			Caller:   lawyerL002,
+			Function: "GetAllCases",
+			Check: func(t *testing.T, _ *mockstub.Network, payload []byte) {
+				var cases []*Case
+				contracttest.Decode(t, payload, &cases)
+				if len(cases) != 1 || cases[0].Department != "Criminal" {
+					t.Errorf("cases = %s", payload)
+				}
+			},
+		},
+		{
+			Name:     "GetCasesByLawyerID",
+			Seed:     []*Case{civil, criminal, confirmed},
+			Caller:   lawyerL001,
+			Function: "GetCasesByLawyerID",
+			Args:     []string{"L002"},
+			Check: func(t *testing.T, _ *mockstub.Network, payload []byte) {
+				var cases []*Case
+				contracttest.Decode(t, payload, &cases)
+				if got := ids(cases); got != "CASE_003" {
+					t.Errorf("cases = %s", got)
+				}
+			},
+		},
+		{
+			Name:     "GetAllCasesWithPagination",
+			Seed:     []*Case{civil, criminal, confirmed},
+			Caller:   lawyerL001,
+			Function: "GetAllCasesWithPagination",
+			Args:     []string{"2", "CASE_002"},
+			Check: func(t *testing.T, _ *mockstub.Network, payload []byte) {
+				var page CasePage
+				contracttest.Decode(t, payload, &page)
+				if ids(page.Records) != "CASE_003,CASE_009" || page.Bookmark != "CASE_009" {
+					t.Errorf("page = %+v", page)
+				}
+			},
+		},
+		{
+			Name:     "GetAllCasesWithPagination invalid page size",
+			Caller:   lawyerL001,
+			Function: "GetAllCasesWithPagination",
+			Args:     []string{"0", ""},
+			WantErr:  "page size must be between 1 and 500, got 0",
+		},
+		{
+			Name:     "GetCasesByLawyerIDWithPagination",
+			Seed:     []*Case{civil, criminal, confirmed},
+			Caller:   lawyerL001,
+			Function: "GetCasesByLawyerIDWithPagination",
+			Args:     []string{"L001", "1", ""},
+			Check: func(t *testing.T, _ *mockstub.Network, payload []byte) {
+				var page CasePage
+				contracttest.Decode(t, payload, &page)
+				if ids(page.Records) != "CASE_002" || page.Bookmark != "CASE_002" {
+					t.Errorf("page = %+v", page)
+				}
+			},
+		},
+		{
+			Name: "FetchAndStoreCaseFromStampReporterChannel",
+			Peers: map[string]mockstub.ChaincodeFunc{"stampreporter": contracttest.Fake(map[string]peer.Response{
+				"GetRejectedCases": shim.Success(contracttest.MustJSON([]*Case{
+					contract.Sealed(newCase("CASE_006", casemodel.StatusRejectedByStampReporter, casemodel.OrgLawyers, "L001"), stampReporter, casemodel.ChannelStampReporterLawyer),
+					contract.Sealed(newCase("CASE_007", casemodel.StatusRejectedByStampReporter, casemodel.OrgStampReporters, "L001"), stampReporter, casemodel.ChannelStampReporterLawyer),
+					newCase("CASE_010", casemodel.StatusRejectedByStampReporter, casemodel.OrgLawyers, "L001"),
+				})),
+				"GetOnHoldCases": shim.Success(contracttest.MustJSON([]*Case{
+					contract.Sealed(newCase("CASE_008", casemodel.StatusOnHoldByStampReporter, casemodel.OrgLawyers, "L001"), stampReporter, casemodel.ChannelStampReporterLawyer),
+					contract.Sealed(newCase("CASE_011", casemodel.StatusOnHoldByStampReporter, casemodel.OrgLawyers, "L001"), benchClerk, casemodel.ChannelBenchClerkLawyer),
+				})),
+			})},
+			Caller:   lawyerL001,
+			Function: "FetchAndStoreCaseFromStampReporterChannel",
+			Check: func(t *testing.T, n *mockstub.Network, _ []byte) {
+				if c := stored(t, n, "CASE_006"); c.Status != casemodel.StatusRejectionReceived {
+					t.Errorf("rejected case is %s", c.Status)
+				}
+				if c := stored(t, n, "CASE_008"); c.Status != casemodel.StatusOnHoldReceived {
+					t.Errorf("on-hold case is %s", c.Status)
+				}
+				if n.GetState(channel, "lawyer", "CASE_007") != nil {
+					t.Error("case held by stamp reporters was stored")
+				}
+				if n.GetState(channel, "lawyer", "CASE_010") != nil || n.GetState(channel, "lawyer", "CASE_011") != nil {
+					t.Error("case not sealed by the stamp reporter was stored")
+				}
+				// One event announces both stored cases and none of the skipped ones
+				events := n.Events()
+				if len(events) != 1 || events[0].Name != casemodel.StatusChangedEventName(casemodel.OrgLawyers) {
+					t.Fatalf("events = %+v", events)
+				}
+				var changes []casemodel.StatusChangedEvent
+				contracttest.Decode(t, events[0].Payload, &changes)
+				if len(changes) != 2 || changes[0].CaseID != "CASE_006" || changes[0].ToStatus != casemodel.StatusRejectionReceived || changes[1].CaseID != "CASE_008" || changes[1].ToStatus != casemodel.StatusOnHoldReceived {
+					t.Errorf("status changes = %+v", changes)
+				}
+			},
+		},
+		{
+			Name: "FetchAndStoreCaseFromStampReporterChannel without cases",
+			Peers: map[string]mockstub.ChaincodeFunc{"stampreporter": contracttest.Fake(map[string]peer.Response{
+				"GetRejectedCases": shim.Success([]byte("[]")),
+				"GetOnHoldCases":   shim.Success([]byte("[]")),
+			})},
+			Caller:   lawyerL001,
+			Function: "FetchAndStoreCaseFromStampReporterChannel",
+			Check: func(t *testing.T, n *mockstub.Network, _ []byte) {
+				if keys := n.Keys(channel, "lawyer"); len(keys) != 1+len(contract.Trusted) {
+					t.Errorf("stored %q", keys)
+				}
+			},
+		},
+		{
+			Name:     "FetchAndStoreCaseFromStampReporterChannel unreachable",
+			Peers:    map[string]mockstub.ChaincodeFunc{"stampreporter": contracttest.Fake(nil)},
+			Caller:   lawyerL001,
+			Function: "FetchAndStoreCaseFromStampReporterChannel",
+			WantErr:  "Failed to fetch rejected cases from StampReporter",
+		},
+		{
+			Name: "FetchAndStoreCaseFromBenchClerkChannel",
+			Peers: map[string]mockstub.ChaincodeFunc{"benchclerk": contracttest.Fake(map[string]peer.Response{
+				"GetCaseById": shim.Success(contracttest.MustJSON(contract.Sealed(confirmed, benchClerk, casemodel.ChannelBenchClerkLawyer))),
+			})},
+			Caller:   lawyerL001,
+			Function: "FetchAndStoreCaseFromBenchClerkChannel",
+			Args:     []string{"CASE_009"},
+			Check: func(t *testing.T, n *mockstub.Network, payload []byte) {
+				var c Case
+				contracttest.Decode(t, payload, &c)
+				if c.ID != "CASE_009" || c.LastModified != stamp {
+					t.Errorf("case = %+v", c)
+				}
+				stored(t, n, "CASE_009")
+			},
+		},
+		{
+			Name:     "FetchAndStoreCaseFromBenchClerkChannel edited in transit",
+			Peers:    map[string]mockstub.ChaincodeFunc{"benchclerk": contracttest.Fake(map[string]peer.Response{"GetCaseById": shim.Success(contracttest.MustJSON(edited))})},
+			Caller:   lawyerL001,
+			Function: "FetchAndStoreCaseFromBenchClerkChannel",
+			Args:     []string{"CASE_009"},
+			WantErr:  "case CASE_009 does not match the hash recorded by transaction",
+		},
+		{
+			Name:     "FetchAndStoreCaseFromBenchClerkChannel sealed by another org",
+			Peers:    map[string]mockstub.ChaincodeFunc{"benchclerk": contracttest.Fake(map[string]peer.Response{"GetCaseById": shim.Success(contracttest.MustJSON(contract.Sealed(confirmed, registrar, casemodel.ChannelBenchClerkLawyer)))})},
+			Caller:   lawyerL001,
+			Function: "FetchAndStoreCaseFromBenchClerkChannel",
+			Args:     []string{"CASE_009"},
+			WantErr:  "case CASE_009 was last stored by RegistrarsOrg, not BenchClerksOrg",
+		},
+		{
+			Name:     "FetchAndStoreCaseFromBenchClerkChannel empty response",
+			Peers:    map[string]mockstub.ChaincodeFunc{"benchclerk": contracttest.Fake(map[string]peer.Response{"GetCaseById": shim.Success(nil)})},
+			Caller:   lawyerL001,
+			Function: "FetchAndStoreCaseFromBenchClerkChannel",
+			Args:     []string{"CASE_009"},
+			WantErr:  "Case data not found on BenchClerk channel for ID: CASE_009",
+		},
+		{
+			Name:     "StoreCase by bench clerk",
+			Caller:   benchClerk,
+			Function: "StoreCase",
+			Args:     []string{string(contracttest.MustJSON(fromBenchClerk))},
+			Check: func(t *testing.T, n *mockstub.Network, _ []byte) {
+				if c := stored(t, n, "CASE_009"); c.Status != casemodel.StatusDecisionConfirmed || c.Judgment == nil {
+					t.Errorf("case = %+v", c)
+				}
+			},
+		},
+		{
+			Name:     "StoreCase held by another organization",
+			Caller:   benchClerk,
+			Function: "StoreCase",
+			Args:     []string{string(contracttest.MustJSON(contract.Sealed(newCase("CASE_010", casemodel.StatusJudgmentReceived, casemodel.OrgBenchClerks, "L001"), benchClerk, casemodel.ChannelBenchClerkLawyer)))},
+			WantErr:  "case CASE_010 is not currently assigned to LawyersOrg",
+		},
+		{
+			Name:     "StoreCase not returned by another organization",
+			Caller:   benchClerk,
+			Function: "StoreCase",
+			Args:     []string{string(contracttest.MustJSON(confirmed))},
+			WantErr:  "case CASE_009 has no hash chain",
+		},
+		{
+			Name:     "StoreCase sealed by the registrar",
+			Caller:   benchClerk,
+			Function: "StoreCase",
+			Args:     []string{string(contracttest.MustJSON(contract.Sealed(confirmed, registrar, casemodel.ChannelBenchClerkLawyer)))},
+			WantErr:  "case CASE_009 was last stored by RegistrarsOrg, not StampReportersOrg",
+		},
+		{
+			Name:     "StoreCase behind the stored copy",
+			Seed:     []*Case{newCase("CASE_009", casemodel.StatusDecisionConfirmed, casemodel.OrgLawyers, "L001")},
+			Caller:   benchClerk,
+			Function: "StoreCase",
+			Args:     []string{string(contracttest.MustJSON(contract.Sealed(newCase("CASE_009", casemodel.StatusJudgmentIssued, casemodel.OrgLawyers, "L001"), benchClerk, casemodel.ChannelBenchClerkLawyer)))},
+			WantErr:  "case CASE_009 cannot move from DECISION_CONFIRMED to JUDGMENT_ISSUED",
+		},
+		{
+			Name:     "StoreCase by lawyer",
+			Caller:   lawyerL001,
+			Function: "StoreCase",
+			Args:     []string{string(contracttest.MustJSON(fromBenchClerk))},
+			WantErr:  "access denied for StoreCase",
+		},
+		{
+			Name:     "StoreCase by registrar",
+			Caller:   registrar,
+			Function: "StoreCase",
+			Args:     []string{string(contracttest.MustJSON(fromBenchClerk))},
+			WantErr:  "access denied for StoreCase",
+		},
+		{
+			Name:     "ReceiveTransfers from outboxes on other channels",
+			Peers:    map[string]mockstub.ChaincodeFunc{"registrar": noTransfers},
+			Caller:   lawyerL001,
+			Function: "ReceiveTransfers",
+			Check: func(t *testing.T, _ *mockstub.Network, payload []byte) {
+				if string(payload) != "[]" {
+					t.Errorf("received = %s", payload)
+				}
+			},
+		},
+		{
+			Name: "ReceiveTransfers from bench clerk",
+			Peers: map[string]mockstub.ChaincodeFunc{"benchclerk": contracttest.Fake(map[string]peer.Response{
+				"ListPendingTransfers": shim.Success(contracttest.MustJSON([]*casemodel.Transfer{forLawyer})),
+				"ClaimTransfer":        shim.Success(contracttest.MustJSON(forLawyer)),
+			}), "registrar": noTransfers},
+			Setup:    contract.Reroute(casemodel.HopLawyerToBenchClerk, casemodel.Route{Chaincode: "benchclerk"}),
+			Caller:   lawyerL001,
+			Function: "ReceiveTransfers",
+			Check: func(t *testing.T, n *mockstub.Network, payload []byte) {
+				if string(payload) != `["CASE_009"]` {
+					t.Errorf("received = %s", payload)
+				}
+				if c := stored(t, n, "CASE_009"); c.Judgment == nil || c.Judgment.Decision != "Appeal allowed" {
+					t.Errorf("case = %+v", c)
+				}
+			},
+		},
+		{
+			Name: "ReceiveTransfers by a lawyer not on the case",
+			Peers: map[string]mockstub.ChaincodeFunc{"benchclerk": contracttest.Fake(map[string]peer.Response{
+				"ListPendingTransfers": shim.Success(contracttest.MustJSON([]*casemodel.Transfer{forLawyer})),
+				"ClaimTransfer":        shim.Success(contracttest.MustJSON(forLawyer)),
+			}), "registrar": noTransfers},
+			Setup:    contract.Reroute(casemodel.HopLawyerToBenchClerk, casemodel.Route{Chaincode: "benchclerk"}),
+			Caller:   lawyerL002,
+			Function: "ReceiveTransfers",
+			WantErr:  "access denied for case CASE_009",
+		},
+		{
+			Name: "ReceiveTransfers from registrar",
+			Seed: []*Case{awaiting},
+			Peers: map[string]mockstub.ChaincodeFunc{"registrar": contracttest.Fake(map[string]peer.Response{
+				"ListPendingTransfers": shim.Success(contracttest.MustJSON([]*casemodel.Transfer{forResubmission})),
+				"ClaimTransfer":        shim.Success(contracttest.MustJSON(forResubmission)),
+			})},
+			Caller:   lawyerL001,
+			Function: "ReceiveTransfers",
+			Check: func(t *testing.T, n *mockstub.Network, payload []byte) {
+				if string(payload) != `["CASE_012"]` {
+					t.Errorf("received = %s", payload)
+				}
+				if c := stored(t, n, "CASE_012"); c.Status != casemodel.StatusRejectedByRegistrar {
+					t.Errorf("case is %s", c.Status)
+				}
+			},
+		},
+		{
+			Name: "ReceiveTransfers from registrar by lawyer not on case",
+			Seed: []*Case{awaiting},
+			Peers: map[string]mockstub.ChaincodeFunc{"registrar": contracttest.Fake(map[string]peer.Response{
+				"ListPendingTransfers": shim.Success(contracttest.MustJSON([]*casemodel.Transfer{forResubmission})),
+				"ClaimTransfer":        shim.Success(contracttest.MustJSON(forResubmission)),
+			})},
+			Caller:   lawyerL002,
+			Function: "ReceiveTransfers",
+			WantErr:  "access denied for case CASE_012",
+		},
+		{
+			Name:     "ReceiveTransfers by registrar",
+			Caller:   registrar,
+			Function: "ReceiveTransfers",
+			WantErr:  "access denied for ReceiveTransfers",
+		},
+		{
+			Name:     "GetAllowedTransitions",
+			Seed:     []*Case{civil},
+			Caller:   lawyerL001,
+			Function: "GetAllowedTransitions",
+			Args:     []string{"CASE_002"},
+			Check: func(t *testing.T, _ *mockstub.Network, payload []byte) {
+				var transitions []casemodel.Transition
+				contracttest.Decode(t, payload, &transitions)
+				if len(transitions) != 1 || transitions[0].To != casemodel.StatusPendingRegistrarReview {
+					t.Errorf("transitions = %+v", transitions)
+				}
+			},
+		},
+		{
+			Name:     "VerifyCaseHistory",
+			Seed:     []*Case{chained},
+			Caller:   lawyerL001,
+			Function: "VerifyCaseHistory",
+			Args:     []string{"CASE_002"},
+			Check: func(t *testing.T, _ *mockstub.Network, payload []byte) {
+				var v casemodel.HistoryVerification
+				contracttest.Decode(t, payload, &v)
+				if !v.Valid || v.Chained != 2 || v.BrokenAt != -1 {
+					t.Errorf("verification = %+v", v)
+				}
+			},
+		},
+		{
+			Name:     "VerifyCaseHistory edited entry",
+			Seed:     []*Case{rewritten},
+			Caller:   lawyerL001,
+			Function: "VerifyCaseHistory",
+			Args:     []string{"CASE_002"},
+			Check: func(t *testing.T, _ *mockstub.Network, payload []byte) {
+				var v casemodel.HistoryVerification
+				contracttest.Decode(t, payload, &v)
+				if v.Valid || v.BrokenAt != 0 || v.Reason != "entry does not match its hash" {
+					t.Errorf("verification = %+v", v)
+				}
+			},
+		},
+		{
+			Name:     "VerifyDocument",
+			Seed:     []*Case{&withPlaint},
+			Caller:   lawyerL001,
+			Function: "VerifyDocument",
+			Args:     []string{"CASE_002", "DOC_1", plaintHash},
+			Check: func(t *testing.T, _ *mockstub.Network, payload []byte) {
+				var v casemodel.DocumentVerification
+				contracttest.Decode(t, payload, &v)
+				if !v.Match || v.ContentHash != plaintHash {
+					t.Errorf("verification = %+v", v)
+				}
+			},
+		},
+		{
+			Name:     "VerifyDocument altered file",
+			Seed:     []*Case{&withPlaint},
+			Caller:   lawyerL001,
+			Function: "VerifyDocument",
+			Args:     []string{"CASE_002", "DOC_1", casemodel.ContentHash([]byte("%PDF-1.4 altered"))},
+			Check: func(t *testing.T, _ *mockstub.Network, payload []byte) {
+				var v casemodel.DocumentVerification
+				contracttest.Decode(t, payload, &v)
+				if v.Match || v.ContentHash != plaintHash {
+					t.Errorf("verification = %+v", v)
+				}
+			},
+		},
+		{
+			Name: "GetCaseProvenance",
+			Seed: []*Case{civil},
+			Setup: func(n *mockstub.Network) {
+				if _, err := n.Submit(channel, "lawyer", lawyerL001, "UpdateCaseDetails", "CASE_002", `{"title":"Boundary dispute"}`); err != nil {
+					panic(err)
+				}
+				if _, err := n.Submit(channel, "lawyer", lawyerL001, "UpdateCaseDetails", "CASE_002", `{"title":"Boundary and access dispute"}`); err != nil {
+					panic(err)
+				}
+			},
+			Caller:   lawyerL001,
+			Function: "GetCaseProvenance",
+			Args:     []string{"CASE_002"},
+			Check: func(t *testing.T, _ *mockstub.Network, payload []byte) {
+				// seeding bypasses the ledger history, so only the two transactions are in it
+				var provenance casemodel.CaseProvenance
+				contracttest.Decode(t, payload, &provenance)
+				if len(provenance.Versions) != 2 {
+					t.Fatalf("versions = %+v", provenance.Versions)
+				}
+				if v := provenance.Versions[1]; contracttest.Changed(v, "title") != (casemodel.FieldChange{Field: "title", Before: `"Boundary dispute"`, After: `"Boundary and access dispute"`}) {
+					t.Errorf("changes = %+v", v.Changes)
+				}
+			},
+		},
+		{
+			Name:     "GetCaseProvenance missing case",
+			Caller:   lawyerL001,
+			Function: "GetCaseProvenance",
+			Args:     []string{"CASE_404"},
+			WantErr:  "case does not exist: CASE_404",
+		},
+		{
+			Name: "GetCasePrivateDetails",
+			Setup: func(n *mockstub.Network) {
+				details := map[string][]byte{casemodel.TransientPrivateDetails: []byte(`{"uidParty1":"P1","clientName":"Ravi Sharma","documentHashes":{"DOC_1":"abc"},` + salts + `}`)}
+				if _, err := n.SubmitTransient(channel, "lawyer", lawyerL001, details, "CreateCase", `{"id":"CASE_001"}`); err != nil {
+					panic(err)
+				}
+			},
+			Caller:   lawyerL001,
+			Function: "GetCasePrivateDetails",
+			Args:     []string{"CASE_001"},
+			Check: func(t *testing.T, _ *mockstub.Network, payload []byte) {
+				// the lawyer keeps every collection, so that it can pass them on
+				var details casemodel.CasePrivateDetails
+				contracttest.Decode(t, payload, &details)
+				if details.ClientName != "Ravi Sharma" || details.DocumentHashes["DOC_1"] != "abc" || details.UIDParty1 != "P1" {
+					t.Errorf("details = %+v", details)
+				}
+			},
+		},
+		{
+			Name:     "GetCasePrivateDetails by registrar",
+			Seed:     []*Case{civil},
+			Caller:   registrar,
+			Function: "GetCasePrivateDetails",
+			Args:     []string{"CASE_002"},
+			WantErr:  "access denied for GetCasePrivateDetails",
+		},
+		{
+			Name:     "GetAllowedTransitions missing case",
+			Caller:   lawyerL001,
+			Function: "GetAllowedTransitions",
+			Args:     []string{"CASE_404"},
+			WantErr:  "case does not exist: CASE_404",
+		},
+		{
+			Name:     "GetRoutingConfig defaults",
+			Caller:   lawyerL001,
+			Function: "GetRoutingConfig",
+			Check: func(t *testing.T, _ *mockstub.Network, payload []byte) {
+				var config casemodel.RoutingConfig
+				contracttest.Decode(t, payload, &config)
+				if route := config.Routes[casemodel.HopLawyerToRegistrar]; route != (casemodel.Route{Chaincode: "registrar", Channel: casemodel.ChannelLawyerRegistrar}) {
+					t.Errorf("route = %+v", route)
+				}
+			},
+		},
+		{
+			Name:     "SetRoutingConfig",
+			Caller:   lawyerAdmin,
+			Function: "SetRoutingConfig",
+			Args:     []string{routing},
+			Check: func(t *testing.T, n *mockstub.Network, _ []byte) {
+				payload, err := n.Evaluate(channel, "lawyer", lawyerL001, "GetRoutingConfig")
+				if err != nil {
+					t.Fatal(err)
+				}
+				var config casemodel.RoutingConfig
+				contracttest.Decode(t, payload, &config)
+				if route := config.Routes[casemodel.HopLawyerToRegistrar]; route.Chaincode != "registrar-v2" {
+					t.Errorf("route = %+v", route)
+				}
+			},
+		},
+		{
+			Name:     "SetRoutingConfig without a route the contract uses",
+			Caller:   lawyerAdmin,
+			Function: "SetRoutingConfig",
+			Args:     []string{`{"routes":{}}`},
+			WantErr:  "invalid routing config: route lawyer->registrar is not configured",
+		},
+		{
+			Name:     "SetRoutingConfig by another organization",
+			Caller:   registrar,
+			Function: "SetRoutingConfig",
+			Args:     []string{routing},
+			WantErr:  "access denied for SetRoutingConfig",
+		},
+	}
+	contract.Run(t, tests)
+}
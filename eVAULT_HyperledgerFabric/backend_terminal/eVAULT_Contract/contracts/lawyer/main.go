@@ -0,0 +1,20 @@
+package main
+
+import (
+	"log"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+
+	"lawyer/chaincode"
+)
+
+func main() {
+	lawyerChaincode, err := contractapi.NewChaincode(chaincode.New())
+	if err != nil {
+		log.Panicf("Error creating lawyer chaincode: %v", err)
+	}
+
+	if err := lawyerChaincode.Start(); err != nil {
+		log.Panicf("Error starting lawyer chaincode: %v", err)
+	}
+}
@@ -0,0 +1,1173 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+
+	"casemodel"
+	"casemodel/access"
+	"casemodel/query"
+)
+
+// The case record is shared by every eVAULT contract so that no field is lost as a
+// case moves between channels
+type (
+	Case              = casemodel.Case
+	Document          = casemodel.Document
+	DocumentSignature = casemodel.DocumentSignature
+	HistoryItem       = casemodel.HistoryItem
+	CasePage          = casemodel.CasePage
+)
+
+// RegistrarContract provides functions for managing case assignments
+type RegistrarContract struct {
+	contractapi.Contract
+}
+
+// accessPolicy lists the roles allowed to call each transaction. Roles from other
+// organizations appear where a transaction is invoked across channels.
+var accessPolicy = access.Policy{
+	"InitLedger":                         {access.RoleRegistrar},
+	"VerifyCase":                         {access.RoleRegistrar},
+	"AssignToStampReporter":              {access.RoleRegistrar},
+	"ReceiveCase":                        {access.RoleLawyer}, // submitted by the lawyer through SubmitToRegistrar
+	"GetPendingCases":                    {access.RoleRegistrar},
+	"GetVerifiedCases":                   {access.RoleRegistrar},
+	"GetPendingCasesWithPagination":      {access.RoleRegistrar},
+	"GetVerifiedCasesWithPagination":     {access.RoleRegistrar},
+	"GetAllState":                        {access.RoleRegistrar},
+	"QueryStats":                         {access.RoleRegistrar},
+	"TestJSONParsing":                    {access.RoleRegistrar},
+	"GetCaseById":                        {access.RoleRegistrar, access.RoleStampReporter}, // read by the stamp reporter across channels
+	"GetAllowedTransitions":              {access.RoleRegistrar},
+	"VerifyCaseHistory":                  {access.RoleRegistrar},
+	"VerifyDocument":                     {access.RoleRegistrar},
+	"VerifyDocumentSignatures":           {access.RoleRegistrar},
+	"GetCaseProvenance":                  {access.RoleRegistrar},
+	"GetCasePrivateDetails":              {access.RoleRegistrar},
+	"FetchAndStoreCaseFromLawyerChannel": {access.RoleRegistrar},
+	"SetRoutingConfig":                   {access.RoleRegistrar},
+	"GetRoutingConfig":                   {access.RoleRegistrar},
+	"SetOrgRoots":                        {access.RoleLawyer, access.RoleRegistrar, access.RoleStampReporter, access.RoleBenchClerk, access.RoleJudge},
+	"ImportOrgRoots":                     {access.RoleRegistrar},
+	"GetOrgRoots":                        {access.RoleLawyer, access.RoleRegistrar, access.RoleStampReporter, access.RoleBenchClerk, access.RoleJudge},
+	"ReceiveTransfers":                   {access.RoleRegistrar},
+	"ListPendingTransfers":               {access.RoleRegistrar, access.RoleLawyer}, // read by the lawyer when claiming transfers
+	"ClaimTransfer":                      {access.RoleLawyer},                       // invoked through ReceiveTransfers of the lawyer only
+	"RetryTransfer":                      {access.RoleRegistrar},
+}
+
+// hops are the calls this contract makes to other chaincodes. A stored routing table
+// must route all of them.
+var hops = []string{
+	casemodel.HopRegistrarToLawyerChannel,
+	casemodel.HopRegistrarToStampReporterChannel,
+	casemodel.HopRegistrarToStampReporter,
+	casemodel.HopRegistrarToLawyer,
+}
+
+// InitLedger initializes the ledger with the routing table of the calls this contract
+// makes, or the default routes when routingConfig is empty. Run it as the init
+// transaction on every channel the contract is deployed on.
+func (s *RegistrarContract) InitLedger(ctx contractapi.TransactionContextInterface, routingConfig string) error {
+	if err := access.RequireAdmin(ctx, "InitLedger"); err != nil {
+		return err
+	}
+	return casemodel.InitRouting(ctx, routingConfig, hops...)
+}
+
+// VerifyCase performs basic verification of the case. A rejected case is handed back to
+// the lawyer, who may resubmit it through ResubmitToRegistrar.
+func (s *RegistrarContract) VerifyCase(ctx contractapi.TransactionContextInterface, caseID string, verificationDetails string) error {
+	// Get the case
+	caseJSON, err := ctx.GetStub().GetState(caseID)
+	if err != nil {
+		return fmt.Errorf("failed to read case: %v", err)
+	}
+	if caseJSON == nil {
+		return fmt.Errorf("case does not exist: %s", caseID)
+	}
+
+	var caseObj Case
+	err = json.Unmarshal(caseJSON, &caseObj)
+	if err != nil {
+		return err
+	}
+
+	// Parse verification details
+	var details struct {
+		IsVerified bool   `json:"isVerified"`
+		Comments   string `json:"comments"`
+		Department string `json:"department"`
+	}
+	err = json.Unmarshal([]byte(verificationDetails), &details)
+	if err != nil {
+		return err
+	}
+
+	// Get current timestamp
+	txTimestamp, err := ctx.GetStub().GetTxTimestamp()
+	if err != nil {
+		return fmt.Errorf("failed to get transaction timestamp: %v", err)
+	}
+	timestamp := time.Unix(txTimestamp.Seconds, 0).Format(time.RFC3339)
+	// Update case status based on verification
+	if details.IsVerified {
+		if err := casemodel.ApplyTransition(ctx, &caseObj, casemodel.StatusVerifiedByRegistrar); err != nil {
+			return err
+		}
+		caseObj.Department = details.Department
+		// Keep the case in RegistrarsOrg until randomly assigned to a stamp reporter via AssignToStampReporter
+	} else {
+		if err := casemodel.ApplyTransition(ctx, &caseObj, casemodel.StatusRejectedByRegistrar); err != nil {
+			return err
+		}
+	}
+
+	// Add to history
+	caseObj.History = append(caseObj.History, HistoryItem{
+		Status:       caseObj.Status,
+		Organization: "RegistrarsOrg",
+		Timestamp:    timestamp,
+		Comments:     details.Comments,
+	})
+
+	// Save updated case
+	caseObj.LastModified = timestamp
+	if err := casemodel.Seal(ctx, &caseObj); err != nil {
+		return err
+	}
+	caseJSON, err = json.Marshal(caseObj)
+	if err != nil {
+		return err
+	}
+	if err := ctx.GetStub().PutState(caseID, caseJSON); err != nil {
+		return err
+	}
+	if details.IsVerified {
+		return nil
+	}
+
+	// Hand the rejected case back to the lawyer through the outbox. If the lawyer cannot
+	// store it now, the transfer stays pending until it is claimed or retried.
+	transfer, err := casemodel.Handoff(ctx, casemodel.HopRegistrarToLawyer, "ReceiveRegistrarRejection", &caseObj)
+	if err != nil {
+		return err
+	}
+	if transfer.Status == casemodel.TransferPending {
+		log.Printf("Case %s is pending in transfer %d to the lawyer: %s", caseID, transfer.Sequence, transfer.LastError)
+		return nil
+	}
+
+	log.Printf("Successfully returned rejected case %s to the lawyer", caseID)
+	return nil
+}
+
+// AssignToStampReporter assigns the case to a stamp reporter through random allocation
+func (s *RegistrarContract) AssignToStampReporter(ctx contractapi.TransactionContextInterface, caseID string) error {
+	// Get the case
+	caseJSON, err := ctx.GetStub().GetState(caseID)
+	if err != nil {
+		return fmt.Errorf("failed to read case: %v", err)
+	}
+	if caseJSON == nil {
+		return fmt.Errorf("case does not exist: %s", caseID)
+	}
+
+	var caseObj Case
+	err = json.Unmarshal(caseJSON, &caseObj)
+	if err != nil {
+		return err
+	}
+
+	// Check if case is verified
+	if err := casemodel.ApplyTransition(ctx, &caseObj, casemodel.StatusPendingStampReporterReview); err != nil {
+		return err
+	}
+
+	// In a real system, random allocation logic would be implemented here
+	log.Printf("Randomly assigning case %s to stamp reporters organization", caseID)
+
+	// Get current timestamp
+	txTimestamp, err := ctx.GetStub().GetTxTimestamp()
+	if err != nil {
+		return fmt.Errorf("failed to get transaction timestamp: %v", err)
+	}
+	timestamp := time.Unix(txTimestamp.Seconds, 0).Format(time.RFC3339)
+	// Add history item for the assignment
+	caseObj.History = append(caseObj.History, HistoryItem{
+		Status:       "ASSIGNED_TO_STAMP_REPORTER",
+		Organization: "RegistrarsOrg",
+		Timestamp:    timestamp,
+		Comments:     "Case assigned for document validation through random allocation",
+	})
+
+	// Save updated case
+	caseObj.LastModified = timestamp
+	if err := casemodel.Seal(ctx, &caseObj); err != nil {
+		return err
+	}
+	caseJSON, err = json.Marshal(caseObj)
+	if err != nil {
+		return err
+	}
+
+	return ctx.GetStub().PutState(caseID, caseJSON)
+}
+
+// ReceiveCase handles a case submission from a lawyer. A case the registrar already holds
+// is only accepted again as the next resubmission of a rejected case.
+func (s *RegistrarContract) ReceiveCase(ctx contractapi.TransactionContextInterface, caseJSON string) error {
+	log.Printf("ReceiveCase called with payload length: %d bytes", len(caseJSON))
+
+	// Parse case data
+	newCase, err := casemodel.DecodeCase([]byte(caseJSON))
+	if err != nil {
+		log.Printf("Failed to decode case data: %v", err)
+		log.Printf("Raw JSON (first 200 chars): %.200s", caseJSON)
+
+		// Try to determine if it's a data format issue
+		if len(caseJSON) > 0 && caseJSON[0] == '{' {
+			log.Printf("JSON appears to be an object, possible structure mismatch")
+		} else {
+			log.Printf("JSON may not be properly formatted")
+		}
+
+		return err
+	}
+	log.Printf("Successfully parsed case with ID: %s, Title: %s", newCase.ID, newCase.Title)
+
+	// Verify required fields
+	if newCase.ID == "" {
+		log.Printf("Case ID is required")
+		return fmt.Errorf("case ID is required")
+	}
+
+	// Verify the case status
+	if newCase.Status != casemodel.StatusPendingRegistrarReview || newCase.CurrentOrg != casemodel.OrgRegistrars {
+		log.Printf("Invalid case status or organization: status=%s, org=%s", newCase.Status, newCase.CurrentOrg)
+		return fmt.Errorf("invalid case status or organization: status=%s, org=%s", newCase.Status, newCase.CurrentOrg)
+	}
+
+	existing, err := ctx.GetStub().GetState(newCase.ID)
+	if err != nil {
+		return fmt.Errorf("failed to read case: %v", err)
+	}
+	comments := "Case received from lawyer for review"
+	// A lawyer submitting the case must be on it, on the registrar's copy once there is one
+	onCase := newCase
+	if existing != nil {
+		stored, err := casemodel.DecodeCase(existing)
+		if err != nil {
+			return err
+		}
+		if stored.Status != casemodel.StatusRejectedByRegistrar {
+			return fmt.Errorf("case %s is already %s, only a case the registrar rejected can be resubmitted", newCase.ID, stored.Status)
+		}
+		if newCase.ResubmissionCount != stored.ResubmissionCount+1 {
+			return fmt.Errorf("case %s is resubmission %d, expected resubmission %d", newCase.ID, newCase.ResubmissionCount, stored.ResubmissionCount+1)
+		}
+		comments = fmt.Sprintf("Case resubmitted by lawyer for review, attempt %d", newCase.ResubmissionCount)
+		onCase = stored
+	}
+	if err := casemodel.VerifyReceived(ctx, newCase, casemodel.OrgRegistrars, casemodel.OrgLawyers); err != nil {
+		return err
+	}
+	caller, err := access.GetCaller(ctx)
+	if err != nil {
+		return err
+	}
+	if caller.Role == access.RoleLawyer {
+		if err := access.RequireCaseLawyer(ctx, onCase); err != nil {
+			return err
+		}
+	}
+
+	log.Printf("Case validation passed, proceeding with save. ID: %s", newCase.ID)
+
+	// Keep the party identities the lawyer passed along in the registrar's private data
+	if err := casemodel.ReceivePrivateDetails(ctx, newCase, casemodel.OrgRegistrars); err != nil {
+		log.Printf("Failed to store private details of case %s: %v", newCase.ID, err)
+		return err
+	}
+
+	// Get current timestamp
+	txTimestamp, err := ctx.GetStub().GetTxTimestamp()
+	if err != nil {
+		return fmt.Errorf("failed to get transaction timestamp: %v", err)
+	}
+	timestamp := time.Unix(txTimestamp.Seconds, 0).Format(time.RFC3339)
+	// Add history item
+	newCase.History = append(newCase.History, HistoryItem{
+		Status:       "RECEIVED_FROM_LAWYER",
+		Organization: "RegistrarsOrg",
+		Timestamp:    timestamp,
+		Comments:     comments,
+	})
+	if err := casemodel.Seal(ctx, newCase); err != nil {
+		return err
+	}
+	// Save case state
+	updatedCaseJSON, err := json.Marshal(newCase)
+	if err != nil {
+		log.Printf("Failed to marshal updated case: %v", err)
+		return err
+	}
+
+	log.Printf("Saving case to state. ID: %s, JSON: %s", newCase.ID, updatedCaseJSON)
+	err = ctx.GetStub().PutState(newCase.ID, updatedCaseJSON)
+	if err != nil {
+		log.Printf("Failed to save case: %v", err)
+		return err
+	}
+
+	log.Printf("Successfully saved case. ID: %s", newCase.ID)
+	return nil
+}
+
+// GetPendingCases retrieves cases pending registrar action
+func (s *RegistrarContract) GetPendingCases(ctx contractapi.TransactionContextInterface, filter string) ([]*Case, error) {
+	log.Printf("GetPendingCases called with filter: %s", filter)
+
+	// Check if the filter might be a case ID (doesn't start with '{')
+	if len(filter) > 0 && filter[0] != '{' {
+		log.Printf("Filter appears to be a case ID, retrieving specific case")
+		caseObj, err := s.readCase(ctx, filter)
+		if err != nil {
+			return nil, err
+		}
+		return access.RedactSealed(ctx, []*Case{caseObj})
+	}
+
+	queryString, err := pendingCasesQuery(ctx, filter)
+	if err != nil {
+		log.Printf("Failed to build query: %v", err)
+		return nil, err
+	}
+
+	log.Printf("Executing CouchDB query: %s", queryString)
+	resultsIterator, err := ctx.GetStub().GetQueryResult(queryString)
+	if err != nil {
+		log.Printf("Failed to execute query: %v", err)
+		return nil, fmt.Errorf("failed to execute query: %v", err)
+	}
+	defer resultsIterator.Close()
+
+	var cases []*Case
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			log.Printf("Error reading next result: %v", err)
+			continue
+		}
+
+		log.Printf("Found matching case with key %s: %s", queryResponse.Key, queryResponse.Value)
+		var caseObj Case
+		err = json.Unmarshal(queryResponse.Value, &caseObj)
+		if err != nil {
+			log.Printf("Failed to unmarshal case %s: %v", queryResponse.Key, err)
+			continue
+		}
+
+		// Initialize empty arrays if they are null
+		if caseObj.Documents == nil {
+			caseObj.Documents = make([]Document, 0)
+		}
+		if caseObj.History == nil {
+			caseObj.History = make([]HistoryItem, 0)
+		}
+		if caseObj.AssociatedLawyers == nil {
+			caseObj.AssociatedLawyers = make([]string, 0)
+		}
+
+		cases = append(cases, &caseObj)
+	}
+
+	log.Printf("Returning %d pending cases", len(cases))
+	return access.RedactSealed(ctx, cases)
+}
+
+// GetPendingCasesWithPagination retrieves one page of the cases pending registrar action.
+// Unlike GetPendingCases the filter must be a JSON filter, not a case ID.
+func (s *RegistrarContract) GetPendingCasesWithPagination(ctx contractapi.TransactionContextInterface, filter string, pageSize int32, bookmark string) (*CasePage, error) {
+	log.Printf("GetPendingCasesWithPagination called with filter %s and page size %d", filter, pageSize)
+
+	queryString, err := pendingCasesQuery(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	page, err := casemodel.QueryCasesWithPagination(ctx, queryString, pageSize, bookmark)
+	if err != nil {
+		return nil, err
+	}
+	return access.RedactSealedPage(ctx, page)
+}
+
+// pendingCasesQuery builds the CouchDB query for cases pending registrar review
+// from a casemodel.CaseFilter
+func pendingCasesQuery(ctx contractapi.TransactionContextInterface, filter string) (string, error) {
+	caseFilter, filterSelector, err := casemodel.ResolveCaseFilter(ctx, filter)
+	if err != nil {
+		return "", err
+	}
+	return caseFilter.Sort(query.New(query.And(
+		query.Eq("status", casemodel.StatusPendingRegistrarReview),
+		query.Eq("currentOrg", casemodel.OrgRegistrars),
+		filterSelector,
+	))).String(), nil
+}
+
+// GetVerifiedCases retrieves cases that have been verified and need stamp reporter assignment
+func (s *RegistrarContract) GetVerifiedCases(ctx contractapi.TransactionContextInterface, filter string) ([]*Case, error) {
+	queryString, err := verifiedCasesQuery(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	resultsIterator, err := ctx.GetStub().GetQueryResult(queryString)
+	if err != nil {
+		return nil, err
+	}
+	defer resultsIterator.Close()
+
+	var cases []*Case
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+		var caseObj Case
+		err = json.Unmarshal(queryResponse.Value, &caseObj)
+		if err != nil {
+			return nil, err
+		}
+
+		// Initialize empty arrays if they are null
+		if caseObj.Documents == nil {
+			caseObj.Documents = make([]Document, 0)
+		}
+		if caseObj.History == nil {
+			caseObj.History = make([]HistoryItem, 0)
+		}
+		if caseObj.AssociatedLawyers == nil {
+			caseObj.AssociatedLawyers = make([]string, 0)
+		}
+
+		cases = append(cases, &caseObj)
+	}
+
+	return access.RedactSealed(ctx, cases)
+}
+
+// GetVerifiedCasesWithPagination retrieves one page of the verified cases awaiting stamp reporter assignment
+func (s *RegistrarContract) GetVerifiedCasesWithPagination(ctx contractapi.TransactionContextInterface, filter string, pageSize int32, bookmark string) (*CasePage, error) {
+	queryString, err := verifiedCasesQuery(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	page, err := casemodel.QueryCasesWithPagination(ctx, queryString, pageSize, bookmark)
+	if err != nil {
+		return nil, err
+	}
+	return access.RedactSealedPage(ctx, page)
+}
+
+// verifiedCasesQuery builds the CouchDB query for verified cases from a
+// casemodel.CaseFilter
+func verifiedCasesQuery(ctx contractapi.TransactionContextInterface, filter string) (string, error) {
+	caseFilter, filterSelector, err := casemodel.ResolveCaseFilter(ctx, filter)
+	if err != nil {
+		return "", err
+	}
+	return caseFilter.Sort(query.New(query.And(
+		query.Eq("status", casemodel.StatusVerifiedByRegistrar),
+		query.Eq("currentOrg", casemodel.OrgRegistrars),
+		filterSelector,
+	))).String(), nil
+}
+
+// GetAllState retrieves all data in the state for debugging
+func (s *RegistrarContract) GetAllState(ctx contractapi.TransactionContextInterface) (string, error) {
+	// Get all keys in the state
+	iterator, err := ctx.GetStub().GetStateByRange("", "")
+	if err != nil {
+		return "", fmt.Errorf("failed to get state range: %v", err)
+	}
+	defer iterator.Close()
+
+	var allData []map[string]interface{}
+	for iterator.HasNext() {
+		response, err := iterator.Next()
+		if err != nil {
+			continue
+		}
+
+		var valueMap map[string]interface{}
+		if err = json.Unmarshal(response.Value, &valueMap); err != nil {
+			// If unmarshal fails, include raw data
+			allData = append(allData, map[string]interface{}{
+				"key":       response.Key,
+				"raw_value": string(response.Value),
+			})
+		} else {
+			// Sealed cases are shown redacted to callers not on their access list
+			if sealed, _ := valueMap["sealed"].(bool); sealed {
+				if valueMap, err = visibleCaseState(ctx, response.Value); err != nil {
+					return "", err
+				}
+			}
+			// Add key to the value map
+			valueMap["key"] = response.Key
+			allData = append(allData, valueMap)
+		}
+	}
+
+	// Convert to JSON
+	dataJSON, err := json.Marshal(allData)
+	if err != nil {
+		return "", err
+	}
+
+	return string(dataJSON), nil
+}
+
+// visibleCaseState returns a stored case as the caller may see it, as a JSON object
+func visibleCaseState(ctx contractapi.TransactionContextInterface, value []byte) (map[string]interface{}, error) {
+	caseObj, err := casemodel.DecodeCase(value)
+	if err != nil {
+		return nil, err
+	}
+	visible, err := access.RedactSealed(ctx, []*Case{caseObj})
+	if err != nil {
+		return nil, err
+	}
+	visibleJSON, err := json.Marshal(visible[0])
+	if err != nil {
+		return nil, err
+	}
+	var valueMap map[string]interface{}
+	if err := json.Unmarshal(visibleJSON, &valueMap); err != nil {
+		return nil, err
+	}
+	return valueMap, nil
+}
+
+// QueryStats gets statistics for registrar dashboard
+func (s *RegistrarContract) QueryStats(ctx contractapi.TransactionContextInterface) (string, error) {
+	stats := struct {
+		PendingCases     int `json:"pendingCases"`
+		VerifiedCases    int `json:"verifiedCases"`
+		RejectedCases    int `json:"rejectedCases"`
+		AssignedToStamp  int `json:"assignedToStamp"`
+		TransferredCases int `json:"transferredCases"`
+	}{}
+
+	// Count pending cases
+	pendingIterator, err := ctx.GetStub().GetQueryResult(query.New(query.And(
+		query.Eq("status", casemodel.StatusPendingRegistrarReview),
+		query.Eq("currentOrg", casemodel.OrgRegistrars),
+	)).String())
+	if err == nil {
+		for pendingIterator.HasNext() {
+			stats.PendingCases++
+			_, _ = pendingIterator.Next()
+		}
+		pendingIterator.Close()
+	}
+	// Count verified cases
+	verifiedIterator, err := ctx.GetStub().GetQueryResult(query.New(query.And(
+		query.Eq("status", casemodel.StatusVerifiedByRegistrar),
+		query.Eq("currentOrg", casemodel.OrgRegistrars),
+	)).String())
+	if err == nil {
+		for verifiedIterator.HasNext() {
+			stats.VerifiedCases++
+			_, _ = verifiedIterator.Next()
+		}
+		verifiedIterator.Close()
+	}
+
+	// Also count cases that have been transferred but show as verified in this channel
+	transferredIterator, err := ctx.GetStub().GetQueryResult(query.New(query.And(
+		query.Eq("status", casemodel.StatusTransferredToStampReporter),
+		query.Eq("currentOrg", casemodel.OrgStampReporters),
+	)).String())
+	if err == nil {
+		for transferredIterator.HasNext() {
+			stats.TransferredCases++
+			_, _ = transferredIterator.Next()
+		}
+		transferredIterator.Close()
+	}
+
+	// Count rejected cases
+	rejectedIterator, err := ctx.GetStub().GetQueryResult(query.New(query.And(
+		query.Eq("status", casemodel.StatusRejectedByRegistrar),
+		query.Eq("currentOrg", casemodel.OrgLawyers),
+	)).String())
+	if err == nil {
+		for rejectedIterator.HasNext() {
+			stats.RejectedCases++
+			_, _ = rejectedIterator.Next()
+		}
+		rejectedIterator.Close()
+	}
+	// Count cases assigned/transferred to stamp reporter
+	// We need to check both channels
+	// First check the current channel
+	assignedIterator, err := ctx.GetStub().GetQueryResult(query.New(query.And(
+		query.Eq("status", casemodel.StatusPendingStampReporterReview),
+		query.Eq("currentOrg", casemodel.OrgStampReporters),
+	)).String())
+	if err == nil {
+		for assignedIterator.HasNext() {
+			stats.AssignedToStamp++
+			_, _ = assignedIterator.Next()
+		}
+		assignedIterator.Close()
+	} // Also count transferred cases in this channel
+	transferredIterator3, err := ctx.GetStub().GetQueryResult(query.New(query.And(
+		query.Eq("status", casemodel.StatusTransferredToStampReporter),
+		query.Eq("currentOrg", casemodel.OrgStampReporters),
+	)).String())
+	if err == nil {
+		for transferredIterator3.HasNext() {
+			stats.TransferredCases++
+			_, _ = transferredIterator3.Next()
+		}
+		transferredIterator3.Close()
+	}
+
+	// If we're on the lawyer-registrar channel, check for cross-channel invocations to the lawyer channel
+	crossChannelArgs := [][]byte{[]byte("QueryStats"), []byte("{}")}
+	crossChannelResponse := casemodel.InvokeHop(ctx, casemodel.HopRegistrarToStampReporterChannel, crossChannelArgs)
+	if crossChannelResponse.Status == 200 {
+		// Try to parse the cross-channel stats
+		var crossStats struct {
+			AssignedToStamp  int `json:"assignedToStamp"`
+			TransferredCases int `json:"transferredCases"`
+		}
+		if err := json.Unmarshal(crossChannelResponse.Payload, &crossStats); err == nil {
+			// Add the cross-channel stats to our count
+			log.Printf("Found %d assigned cases and %d transferred cases in registrar-stampreporter-channel",
+				crossStats.AssignedToStamp, crossStats.TransferredCases)
+			stats.AssignedToStamp += crossStats.AssignedToStamp
+		}
+	}
+
+	// Convert stats to JSON
+	statsJSON, err := json.Marshal(stats)
+	if err != nil {
+		return "", err
+	}
+
+	return string(statsJSON), nil
+}
+
+// TestJSONParsing is a test function to verify JSON parsing functionality
+func (s *RegistrarContract) TestJSONParsing(ctx contractapi.TransactionContextInterface, caseJSON string) (string, error) {
+	log.Printf("TestJSONParsing called with JSON: %s", caseJSON)
+
+	var caseObj Case
+	err := json.Unmarshal([]byte(caseJSON), &caseObj)
+	if err != nil {
+		log.Printf("Failed to parse JSON: %v", err)
+		return "", fmt.Errorf("failed to parse JSON: %v", err)
+	}
+
+	log.Printf("Successfully parsed JSON into Case object: %+v", caseObj)
+	return fmt.Sprintf("Successfully parsed case with ID: %s, Title: %s", caseObj.ID, caseObj.Title), nil
+}
+
+// GetCaseById retrieves a specific case by its ID
+func (s *RegistrarContract) GetCaseById(ctx contractapi.TransactionContextInterface, caseID string) (*Case, error) {
+	log.Printf("GetCaseById called with ID: %s", caseID)
+
+	caseObj, err := s.readCase(ctx, caseID)
+	if err != nil {
+		return nil, err
+	}
+	if err := access.RequireCaseView(ctx, caseObj); err != nil {
+		return nil, err
+	}
+
+	log.Printf("Successfully retrieved case with ID: %s", caseID)
+	return caseObj, nil
+}
+
+// readCase reads a stored case without checking whether the caller may see it
+func (s *RegistrarContract) readCase(ctx contractapi.TransactionContextInterface, caseID string) (*Case, error) {
+	// Get the case
+	caseJSON, err := ctx.GetStub().GetState(caseID)
+	if err != nil {
+		log.Printf("Failed to read case: %v", err)
+		return nil, fmt.Errorf("failed to read case: %v", err)
+	}
+	if caseJSON == nil {
+		log.Printf("Case not found: %s", caseID)
+		return nil, fmt.Errorf("case not found: %s", caseID)
+	}
+
+	var caseObj Case
+	err = json.Unmarshal(caseJSON, &caseObj)
+	if err != nil {
+		log.Printf("Failed to unmarshal case: %v", err)
+		return nil, fmt.Errorf("failed to unmarshal case: %v", err)
+	}
+
+	// Initialize empty arrays if they are null
+	if caseObj.Documents == nil {
+		caseObj.Documents = make([]Document, 0)
+	}
+	if caseObj.History == nil {
+		caseObj.History = make([]HistoryItem, 0)
+	}
+	if caseObj.AssociatedLawyers == nil {
+		caseObj.AssociatedLawyers = make([]string, 0)
+	}
+
+	return &caseObj, nil
+}
+
+// syncCase stores a copy of a case the registrar updated on another channel, pulled from
+// that channel's outbox by ReceiveTransfers. The copy must have been last stored by the
+// registrar, and a copy that would move the case backwards in its lifecycle is rejected.
+func (s *RegistrarContract) syncCase(ctx contractapi.TransactionContextInterface, caseJSON string) error {
+	log.Printf("syncCase called with payload length: %d bytes", len(caseJSON))
+
+	caller, err := access.GetCaller(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Parse the incoming case
+	incoming, err := casemodel.DecodeCase([]byte(caseJSON))
+	if err != nil {
+		return err
+	}
+	if incoming.ID == "" {
+		return fmt.Errorf("case ID is required")
+	}
+	if err := casemodel.VerifyHandoff(ctx, incoming, casemodel.OrgRegistrars); err != nil {
+		return err
+	}
+	source := incoming.HashChain[len(incoming.HashChain)-1].Channel
+
+	// Compare with the copy on this channel
+	existingJSON, err := ctx.GetStub().GetState(incoming.ID)
+	if err != nil {
+		return fmt.Errorf("failed to read case: %v", err)
+	}
+	if existingJSON == nil {
+		return fmt.Errorf("case does not exist: %s", incoming.ID)
+	}
+	existing, err := casemodel.DecodeCase(existingJSON)
+	if err != nil {
+		return err
+	}
+	if err := casemodel.CheckProgress(existing, incoming.Status); err != nil {
+		return err
+	}
+	if len(incoming.History) < len(existing.History) {
+		return fmt.Errorf("sync of case %s would drop %d history entries", incoming.ID, len(existing.History)-len(incoming.History))
+	}
+	for i := range existing.History {
+		if incoming.History[i] != existing.History[i] {
+			return fmt.Errorf("sync of case %s would rewrite history entry %d", incoming.ID, i)
+		}
+	}
+
+	// Get current timestamp
+	txTimestamp, err := ctx.GetStub().GetTxTimestamp()
+	if err != nil {
+		return fmt.Errorf("failed to get transaction timestamp: %v", err)
+	}
+	timestamp := time.Unix(txTimestamp.Seconds, 0).Format(time.RFC3339)
+
+	// Record who performed the sync
+	incoming.History = append(incoming.History, HistoryItem{
+		Status:       "CASE_SYNCED",
+		Organization: caller.Org,
+		Timestamp:    timestamp,
+		Comments:     fmt.Sprintf("Case synced from %s by %s", source, caller.Name),
+	})
+	incoming.LastModified = timestamp
+
+	if err := casemodel.Seal(ctx, incoming); err != nil {
+		return err
+	}
+
+	updatedCaseJSON, err := json.Marshal(incoming)
+	if err != nil {
+		return fmt.Errorf("failed to marshal case: %v", err)
+	}
+	if err := ctx.GetStub().PutState(incoming.ID, updatedCaseJSON); err != nil {
+		return fmt.Errorf("failed to update case: %v", err)
+	}
+
+	log.Printf("Case %s synced from %s", incoming.ID, source)
+	return nil
+}
+
+// GetAllowedTransitions returns the status changes the caller's organization can make on a case right now
+func (s *RegistrarContract) GetAllowedTransitions(ctx contractapi.TransactionContextInterface, caseID string) ([]casemodel.Transition, error) {
+	return casemodel.GetAllowedTransitions(ctx, caseID)
+}
+
+// VerifyCaseHistory recomputes the hash chain of a case's history and reports the first
+// entry that does not verify
+func (s *RegistrarContract) VerifyCaseHistory(ctx contractapi.TransactionContextInterface, caseID string) (*casemodel.HistoryVerification, error) {
+	return casemodel.VerifyCaseHistory(ctx, caseID)
+}
+
+// VerifyDocument checks whether a file with the hex SHA-256 contentHash is the one a
+// case document was uploaded with
+func (s *RegistrarContract) VerifyDocument(ctx contractapi.TransactionContextInterface, caseID string, documentID string, contentHash string) (*casemodel.DocumentVerification, error) {
+	if err := access.RequireStoredCaseView(ctx, caseID); err != nil {
+		return nil, err
+	}
+	return casemodel.VerifyDocument(ctx, caseID, documentID, contentHash)
+}
+
+// VerifyDocumentSignatures rechecks every signature on a case's documents
+func (s *RegistrarContract) VerifyDocumentSignatures(ctx contractapi.TransactionContextInterface, caseID string) (*casemodel.SignatureVerification, error) {
+	if err := access.RequireStoredCaseView(ctx, caseID); err != nil {
+		return nil, err
+	}
+	return casemodel.VerifyDocumentSignatures(ctx, caseID)
+}
+
+// GetCaseProvenance returns every committed version of a case on this channel with the
+// fields each transaction changed
+func (s *RegistrarContract) GetCaseProvenance(ctx contractapi.TransactionContextInterface, caseID string) (*casemodel.CaseProvenance, error) {
+	if err := access.RequireStoredCaseView(ctx, caseID); err != nil {
+		return nil, err
+	}
+	return casemodel.GetCaseProvenance(ctx, caseID)
+}
+
+// GetCasePrivateDetails returns the party identities of a case from the registrar's
+// private data
+func (s *RegistrarContract) GetCasePrivateDetails(ctx contractapi.TransactionContextInterface, caseID string) (*casemodel.CasePrivateDetails, error) {
+	if err := access.RequireStoredCaseView(ctx, caseID); err != nil {
+		return nil, err
+	}
+	return casemodel.GetCasePrivateDetails(ctx, caseID, casemodel.OrgRegistrars)
+}
+
+// SetRoutingConfig replaces the chaincode and channel of each call this contract makes,
+// as stored by InitLedger. Only an administrator may change the routes.
+func (s *RegistrarContract) SetRoutingConfig(ctx contractapi.TransactionContextInterface, config string) error {
+	if err := access.RequireAdmin(ctx, "SetRoutingConfig"); err != nil {
+		return err
+	}
+	return casemodel.PutRouting(ctx, config, hops...)
+}
+
+// GetRoutingConfig returns the routing table this contract uses
+func (s *RegistrarContract) GetRoutingConfig(ctx contractapi.TransactionContextInterface) (*casemodel.RoutingConfig, error) {
+	return casemodel.GetRouting(ctx)
+}
+
+// SetOrgRoots replaces the root CA certificates trusted for an organization on this
+// channel, which signer certificates are checked against. Only an administrator of that
+// organization may change them.
+func (s *RegistrarContract) SetOrgRoots(ctx contractapi.TransactionContextInterface, org string, certificates string) error {
+	if err := access.RequireAdmin(ctx, "SetOrgRoots"); err != nil {
+		return err
+	}
+	return casemodel.PutOrgRoots(ctx, org, certificates)
+}
+
+// ImportOrgRoots copies the root CA certificates trusted for an organization that has not
+// joined this channel from the chaincode routed for hop, where that organization's
+// administrator stored them. Only an administrator may import them.
+func (s *RegistrarContract) ImportOrgRoots(ctx contractapi.TransactionContextInterface, org string, hop string) error {
+	if err := access.RequireAdmin(ctx, "ImportOrgRoots"); err != nil {
+		return err
+	}
+	return casemodel.ImportOrgRoots(ctx, org, hop)
+}
+
+// GetOrgRoots returns the PEM root CA certificates trusted for an organization on this
+// channel, or an empty string when none are
+func (s *RegistrarContract) GetOrgRoots(ctx contractapi.TransactionContextInterface, org string) (string, error) {
+	return casemodel.GetOrgRoots(ctx, org)
+}
+
+// ReceiveTransfers claims the cases handed to the registrar on this channel that were not
+// delivered when they were sent, and stores them. It returns the IDs of the cases received.
+func (s *RegistrarContract) ReceiveTransfers(ctx contractapi.TransactionContextInterface) ([]string, error) {
+	store := func(caseJSON string) error {
+		return s.ReceiveCase(ctx, caseJSON)
+	}
+	received, err := casemodel.ReceiveTransfers(ctx, store, casemodel.HopRegistrarToLawyer)
+	if err != nil {
+		return nil, err
+	}
+	// Cases the registrar moved on from the registrar-stampreporter-channel are synced
+	sync := func(caseJSON string) error {
+		return s.syncCase(ctx, caseJSON)
+	}
+	synced, err := casemodel.ReceiveTransfers(ctx, sync, casemodel.HopRegistrarToStampReporterChannel)
+	if err != nil {
+		return nil, err
+	}
+	return append(received, synced...), nil
+}
+
+// ListPendingTransfers returns the cases handed to other organizations that they have
+// not claimed yet, with the sealed cases the caller may not see redacted
+func (s *RegistrarContract) ListPendingTransfers(ctx contractapi.TransactionContextInterface) ([]*casemodel.Transfer, error) {
+	transfers, err := casemodel.ListPendingTransfers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return access.RedactSealedTransfers(ctx, transfers)
+}
+
+// ClaimTransfer acknowledges a pending transfer for the organization receiving the case
+// and returns it
+func (s *RegistrarContract) ClaimTransfer(ctx contractapi.TransactionContextInterface, sequence uint64) (*casemodel.Transfer, error) {
+	return casemodel.ClaimTransfer(ctx, sequence)
+}
+
+// RetryTransfer delivers a pending transfer again
+func (s *RegistrarContract) RetryTransfer(ctx contractapi.TransactionContextInterface, sequence uint64) (*casemodel.Transfer, error) {
+	t, err := casemodel.RetryTransfer(ctx, sequence)
+	if err != nil {
+		return nil, err
+	}
+	transfers, err := access.RedactSealedTransfers(ctx, []*casemodel.Transfer{t})
+	if err != nil {
+		return nil, err
+	}
+	return transfers[0], nil
+}
+
+// New returns the registrar contract with its access policy applied and the status changes
+// of each transaction emitted together once it succeeds
+func New() *RegistrarContract {
+	contract := new(RegistrarContract)
+	contract.BeforeTransaction = accessPolicy.BeforeTransaction
+	contract.TransactionContextHandler = new(casemodel.TransactionContext)
+	contract.AfterTransaction = casemodel.EmitStatusChanges
+	return contract
+}
+
+// FetchAndStoreCaseFromLawyerChannel fetches a case from lawyer-registrar-channel and stores it on registrar-stampreporter-channel
+// in a single transaction, then assigns it to stamp reporter
+func (s *RegistrarContract) FetchAndStoreCaseFromLawyerChannel(ctx contractapi.TransactionContextInterface, caseID string) error {
+	log.Printf("FetchAndStoreCaseFromLawyerChannel called for case ID: %s", caseID)
+
+	// Get case directly from the chain to see if it exists before making cross-channel calls
+	existingCase, _ := ctx.GetStub().GetState(caseID)
+	if existingCase != nil {
+		log.Printf("Case %s already exists on registrar-stampreporter-channel, proceeding with assignment", caseID)
+		// If case exists, just assign it to stamp reporter
+		return s.AssignToStampReporter(ctx, caseID)
+	}
+
+	// Instead of using GetState which returns just a string, we'll create a custom function for cross-channel
+	// that returns an array to meet the expected schema
+	args := [][]byte{[]byte("GetCaseById"), []byte(caseID)}
+
+	// Step 1: Read from the lawyer-registrar-channel
+	log.Printf("Invoking GetCaseById on lawyer-registrar-channel for case ID: %s", caseID)
+	response := casemodel.InvokeHop(ctx, casemodel.HopRegistrarToLawyerChannel, args)
+
+	// Check the response status - 200 is OK in contractapi
+	if response.Status != 200 {
+		errMsg := fmt.Sprintf("Failed to fetch case from lawyer-registrar-channel: %s", string(response.Message))
+		log.Printf(errMsg)
+		return fmt.Errorf(errMsg)
+	}
+	// Check if payload is empty
+	if response.Payload == nil || len(response.Payload) == 0 {
+		errMsg := fmt.Sprintf("Case data not found on lawyer-registrar-channel for ID: %s", caseID)
+		log.Printf(errMsg)
+		return fmt.Errorf(errMsg)
+	}
+
+	log.Printf("Successfully received response from lawyer-registrar-channel, response length: %d bytes", len(response.Payload))
+	// Parse the response
+	decoded, err := casemodel.DecodeCase(response.Payload)
+	if err != nil {
+		log.Printf("Failed to decode case data: %v", err)
+		return err
+	}
+
+	// Only store the case in the state the registrar handed it over in
+	if err := casemodel.VerifyHandoff(ctx, decoded, casemodel.OrgRegistrars); err != nil {
+		log.Printf("Case %s failed verification: %v", decoded.ID, err)
+		return err
+	}
+	caseObj := *decoded
+
+	log.Printf("Successfully parsed case with ID: %s, Title: %s", caseObj.ID, caseObj.Title)
+
+	// Ensure case has a valid ID before proceeding
+	if caseObj.ID == "" {
+		errMsg := "Invalid case data: Missing ID field"
+		log.Printf(errMsg)
+		return fmt.Errorf(errMsg)
+	}
+
+	// Only cases the registrar has verified on the lawyer channel can be transferred
+	if err := casemodel.CheckTransition(&caseObj, casemodel.StatusPendingStampReporterReview, casemodel.OrgRegistrars); err != nil {
+		log.Printf("Case %s cannot be transferred: %v", caseID, err)
+		return err
+	}
+	// Update lawyer-registrar-channel with the transferred status
+	log.Printf("Updating case status on lawyer-registrar-channel to TRANSFERRED_TO_STAMPREPORTER")
+
+	// Create a new history item for the transfer
+	txTimestampForTransfer, err := ctx.GetStub().GetTxTimestamp()
+	if err != nil {
+		log.Printf("Failed to get transaction timestamp: %v", err)
+		return fmt.Errorf("failed to get transaction timestamp: %v", err)
+	}
+	timestampForTransfer := time.Unix(txTimestampForTransfer.Seconds, 0).Format(time.RFC3339)
+	// Direct modification of the case object
+	lawyerChannelCase := caseObj
+	if err := casemodel.ApplyTransition(ctx, &lawyerChannelCase, casemodel.StatusTransferredToStampReporter); err != nil {
+		return err
+	}
+	lawyerChannelCase.LastModified = timestampForTransfer
+
+	// Add history item for the transfer
+	lawyerChannelCase.History = append(lawyerChannelCase.History, HistoryItem{
+		Status:       "TRANSFERRED_TO_STAMPREPORTER",
+		Organization: "RegistrarsOrg",
+		Timestamp:    timestampForTransfer,
+		Comments:     "Case transferred to registrar-stampreporter-channel",
+	})
+
+	// Writes to the lawyer-registrar-channel made from here would be discarded, so the
+	// update is left in the outbox for ReceiveTransfers on that channel to pull
+	if err := casemodel.Seal(ctx, &lawyerChannelCase); err != nil {
+		return err
+	}
+	syncTransfer, err := casemodel.Sync(ctx, casemodel.HopRegistrarToLawyerChannel, &lawyerChannelCase)
+	if err != nil {
+		return err
+	}
+	log.Printf("Case %s left in transfer %d for lawyer-registrar-channel with status TRANSFERRED_TO_STAMPREPORTER", caseObj.ID, syncTransfer.Sequence)
+
+	// Update organization for this channel; the case keeps its verified status
+	caseObj.CurrentOrg = casemodel.OrgRegistrars
+
+	// Add a history entry about the transfer
+	txTimestamp, err := ctx.GetStub().GetTxTimestamp()
+	if err != nil {
+		log.Printf("Failed to get transaction timestamp: %v", err)
+		return fmt.Errorf("failed to get transaction timestamp: %v", err)
+	}
+	timestamp := time.Unix(txTimestamp.Seconds, 0).Format(time.RFC3339)
+
+	// Initialize any null arrays to avoid null pointer exceptions
+	if caseObj.Documents == nil {
+		caseObj.Documents = make([]Document, 0)
+	}
+	if caseObj.History == nil {
+		caseObj.History = make([]HistoryItem, 0)
+	}
+	if caseObj.AssociatedLawyers == nil {
+		caseObj.AssociatedLawyers = make([]string, 0)
+	}
+
+	historyItem := HistoryItem{
+		Status:       "VERIFIED_BY_REGISTRAR",
+		Organization: "RegistrarsOrg",
+		Timestamp:    timestamp,
+		Comments:     "Case transferred from lawyer-registrar-channel to registrar-stampreporter-channel",
+	}
+
+	caseObj.History = append(caseObj.History, historyItem)
+
+	// Update last modified time
+	caseObj.LastModified = timestamp
+	if err := casemodel.Seal(ctx, &caseObj); err != nil {
+		return err
+	}
+	// Step 2: Store the case on this channel (registrar-stampreporter-channel)
+	updatedCaseJSON, err := json.Marshal(caseObj)
+	if err != nil {
+		log.Printf("Failed to marshal updated case: %v", err)
+		return fmt.Errorf("failed to marshal updated case: %v", err)
+	}
+
+	log.Printf("Storing case %s on registrar-stampreporter-channel", caseID)
+	// Store the case on the current channel
+	err = ctx.GetStub().PutState(caseID, updatedCaseJSON)
+	if err != nil {
+		log.Printf("Failed to store case on current channel: %v", err)
+		return fmt.Errorf("failed to store case on current channel: %v", err)
+	}
+
+	// Give it a moment to be committed
+	log.Printf("Case stored, proceeding with assignment")
+	// For debugging, log the JSON being stored
+	log.Printf("Stored case JSON: %s", string(updatedCaseJSON))
+
+	log.Printf("Case %s successfully stored on registrar-stampreporter-channel", caseID)
+
+	// Now assign it to stamp reporter - instead of calling AssignToStampReporter, we'll implement the logic here
+	// to avoid any issues with the transaction context
+	// We can use the caseObj we already have since we've already parsed it
+
+	// Check if case is verified
+	if err := casemodel.ApplyTransition(ctx, &caseObj, casemodel.StatusPendingStampReporterReview); err != nil {
+		log.Printf("Case %s cannot be assigned: %v", caseID, err)
+		return err
+	}
+
+	// In a real system, random allocation logic would be implemented here
+	log.Printf("Randomly assigning case %s to stamp reporters organization", caseID)
+
+	// Get current timestamp again for the assignment
+	txTimestamp, err = ctx.GetStub().GetTxTimestamp()
+	if err != nil {
+		return fmt.Errorf("failed to get transaction timestamp: %v", err)
+	}
+	timestampForAssignment := time.Unix(txTimestamp.Seconds, 0).Format(time.RFC3339)
+
+	// Add history item for the assignment
+	caseObj.History = append(caseObj.History, HistoryItem{
+		Status:       "ASSIGNED_TO_STAMP_REPORTER",
+		Organization: "RegistrarsOrg",
+		Timestamp:    timestampForAssignment,
+		Comments:     "Case assigned for document validation through random allocation",
+	})
+
+	// Save updated case with stamp reporter assignment
+	caseObj.LastModified = timestampForAssignment
+	if err := casemodel.Seal(ctx, &caseObj); err != nil {
+		return err
+	}
+	assignedCaseJSON, err := json.Marshal(caseObj)
+	if err != nil {
+		log.Printf("Failed to marshal assigned case: %v", err)
+		return fmt.Errorf("failed to marshal assigned case: %v", err)
+	}
+
+	// Update the state
+	err = ctx.GetStub().PutState(caseID, assignedCaseJSON)
+	if err != nil {
+		log.Printf("Failed to store assigned case: %v", err)
+		return fmt.Errorf("failed to store assigned case: %v", err)
+	}
+
+	// Hand the assigned case to the stamp reporter, through the outbox if it cannot be delivered
+	transfer, err := casemodel.Handoff(ctx, casemodel.HopRegistrarToStampReporter, "StoreCase", &caseObj)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Case %s successfully transferred and assigned to stamp reporter in transfer %d", caseID, transfer.Sequence)
+	return nil
+}
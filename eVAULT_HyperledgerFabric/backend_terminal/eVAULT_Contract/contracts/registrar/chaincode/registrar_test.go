@@ -0,0 +1,986 @@
+package chaincode
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/hyperledger/fabric-protos-go/peer"
+
+	"casemodel"
+	"casemodel/mockstub"
+	"casemodel/mockstub/contracttest"
+)
+
+const (
+	lawyerChannel        = "lawyer-registrar-channel"
+	stampReporterChannel = "registrar-stampreporter-channel"
+)
+
+var start = time.Date(2024, time.May, 15, 10, 0, 0, 0, time.UTC)
+
+// stamp is how the contract formats the timestamp of the first transaction
+var stamp = time.Unix(start.Unix(), 0).Format(time.RFC3339)
+
+var (
+	lawyer         = mockstub.Identity{MSPID: "LawyersOrgMSP", Name: "lawyer1", Attrs: map[string]string{"role": "lawyer", "lawyerId": "L001"}}
+	otherLawyer    = mockstub.Identity{MSPID: "LawyersOrgMSP", Name: "lawyer2", Attrs: map[string]string{"role": "lawyer", "lawyerId": "L002"}}
+	registrar      = mockstub.Identity{MSPID: "RegistrarsOrgMSP", Name: "registrar1", Attrs: map[string]string{"role": "registrar"}}
+	registrarAdmin = mockstub.Identity{MSPID: "RegistrarsOrgMSP", Name: "registraradmin", Attrs: map[string]string{"role": "registrar", "admin": "true"}}
+	stampReporter  = mockstub.Identity{MSPID: "StampReportersOrgMSP", Name: "stampreporter1", Attrs: map[string]string{"role": "stampreporter"}}
+)
+
+// contract is how every test deploys the registrar contract
+var contract = &contracttest.Contract{
+	Name:     "registrar",
+	Channels: []string{lawyerChannel, stampReporterChannel},
+	New:      func() contractapi.ContractInterface { return New() },
+	Start:    start,
+	Admin:    registrarAdmin,
+	Client:   registrar,
+	// the lawyers' roots check the cases they submit, the registrars' own roots the copies
+	// they sync between their channels
+	Trusted: []string{casemodel.OrgLawyers, casemodel.OrgRegistrars},
+	Deploy: func(n *mockstub.Network) {
+		n.DefineCollection(lawyerChannel, "registrar", casemodel.CollectionPartyIdentities, "RegistrarsOrgMSP")
+	},
+}
+
+// stored reads a case from the registrar's ledger on channel
+func stored(t *testing.T, n *mockstub.Network, channel string, id string) *Case {
+	t.Helper()
+	value := n.GetState(channel, "registrar", id)
+	if value == nil {
+		t.Fatalf("case %s is not on %s", id, channel)
+	}
+	c, err := casemodel.DecodeCase(value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+// ids lists the IDs of cases
+func ids(cases []*Case) string {
+	var list []string
+	for _, c := range cases {
+		list = append(list, c.ID)
+	}
+	return strings.Join(list, ",")
+}
+
+// hashOf returns the hex SHA-256 of a private data record
+func hashOf(record string) string {
+	sum := sha256.Sum256([]byte(record))
+	return hex.EncodeToString(sum[:])
+}
+
+// lastHistory returns the status of a case's latest history entry
+func lastHistory(c *Case) string {
+	if len(c.History) == 0 {
+		return ""
+	}
+	return c.History[len(c.History)-1].Status
+}
+
+func TestRegistrarContract(t *testing.T) {
+	// routing moves one route to a new chaincode name and keeps the other defaults
+	routingConfig := casemodel.DefaultRouting()
+	routingConfig.Routes[casemodel.HopRegistrarToStampReporter] = casemodel.Route{Chaincode: "stampreporter-v2", Channel: casemodel.ChannelRegistrarStampReporter}
+	routing := string(contracttest.MustJSON(routingConfig))
+
+	pending := contracttest.NewCase("CASE_001", casemodel.StatusPendingRegistrarReview, casemodel.OrgRegistrars)
+	pending.Department = "Civil"
+	// withParties carries the hash of the party identities the lawyer recorded
+	parties := `{"caseId":"CASE_001","uidParty1":"P1","uidParty2":"P2"}`
+	withParties := *pending
+	withParties.PrivateHashes = map[string]string{casemodel.CollectionPartyIdentities: hashOf(parties)}
+	verified := contracttest.NewCase("CASE_002", casemodel.StatusVerifiedByRegistrar, casemodel.OrgRegistrars)
+	rejected := contracttest.NewCase("CASE_003", casemodel.StatusRejectedByRegistrar, casemodel.OrgLawyers)
+	pendingCriminal := contracttest.NewCase("CASE_004", casemodel.StatusPendingRegistrarReview, casemodel.OrgRegistrars)
+	pendingCriminal.Department = "Criminal"
+	// inCamera is pendingCriminal sealed for another registrar
+	inCamera := *pendingCriminal
+	inCamera.Sealed, inCamera.AccessList = true, []string{"judge:J001", "registrar:registrar2"}
+
+	// resubmitted is rejected filed again by the lawyer
+	resubmitted := contracttest.NewCase("CASE_003", casemodel.StatusPendingRegistrarReview, casemodel.OrgRegistrars)
+	resubmitted.ResubmissionCount = 1
+
+	// submitted cases as the lawyer seals them; forged is edited after the lawyer sealed it
+	submitted := contract.Sealed(pending, lawyer, lawyerChannel)
+	submittedWithParties := contract.Sealed(&withParties, lawyer, lawyerChannel)
+	resubmittedSealed := contract.Sealed(resubmitted, lawyer, lawyerChannel)
+	forged := *submitted
+	forged.Department = "Revenue"
+
+	// a transfer waiting in the lawyer's outbox
+	forRegistrar := &casemodel.Transfer{Sequence: 1, CaseID: "CASE_001", Hop: casemodel.HopLawyerToRegistrar, Function: "ReceiveCase", From: casemodel.OrgLawyers, To: casemodel.OrgRegistrars, Case: submitted, Status: casemodel.TransferPending}
+
+	var stampReporterCalls []*Case
+	stampReporterPeer := contracttest.Recorder(&stampReporterCalls)
+
+	// chained is verified as the contract stores it, with its history chained; rewritten has
+	// an entry edited afterwards
+	withHistory := *verified
+	withHistory.History = []HistoryItem{{Status: "CREATED", Comments: "filed"}, {Status: verified.Status, Comments: "recorded"}}
+	chained := contract.Sealed(&withHistory, registrar, lawyerChannel)
+	rewritten := contract.Sealed(&withHistory, registrar, lawyerChannel)
+	rewritten.History[0].Comments = "withdrawn"
+
+	tests := []contracttest.TxTest{
+		{
+			Name:     "InitLedger",
+			Caller:   registrarAdmin,
+			Function: "InitLedger",
+			Args:     []string{""},
+		},
+		{
+			Name:     "InitLedger by a registrar who is not an administrator",
+			Caller:   registrar,
+			Function: "InitLedger",
+			Args:     []string{""},
+			WantErr:  "access denied for InitLedger",
+		},
+		{
+			Name:     "SetRoutingConfig by a registrar who is not an administrator",
+			Caller:   registrar,
+			Function: "SetRoutingConfig",
+			Args:     []string{`{"routes":{}}`},
+			WantErr:  "access denied for SetRoutingConfig",
+		},
+		{
+			Name:     "SetOrgRoots by a registrar who is not an administrator",
+			Caller:   registrar,
+			Function: "SetOrgRoots",
+			Args:     []string{casemodel.OrgStampReporters, mockstub.RootCertificate("StampReportersOrgMSP")},
+			WantErr:  "access denied for SetOrgRoots",
+		},
+		{
+			Name:     "SetOrgRoots of an unknown organization",
+			Caller:   registrarAdmin,
+			Function: "SetOrgRoots",
+			Args:     []string{"CourtsOrg", mockstub.RootCertificate("StampReportersOrgMSP")},
+			WantErr:  `unknown organization "CourtsOrg"`,
+		},
+		{
+			Name:     "SetOrgRoots of another organization",
+			Caller:   registrarAdmin,
+			Function: "SetOrgRoots",
+			Args:     []string{casemodel.OrgJudges, mockstub.RootCertificate("JudgesOrgMSP")},
+			WantErr:  "a member of RegistrarsOrg cannot set the root certificates of JudgesOrg",
+		},
+		{
+			Name:     "VerifyCase verified",
+			Seed:     []*Case{pending},
+			Caller:   registrar,
+			Function: "VerifyCase",
+			Args:     []string{"CASE_001", `{"isVerified":true,"comments":"Complete","department":"Revenue"}`},
+			Check: func(t *testing.T, n *mockstub.Network, _ []byte) {
+				c := stored(t, n, lawyerChannel, "CASE_001")
+				if c.Status != casemodel.StatusVerifiedByRegistrar || c.CurrentOrg != casemodel.OrgRegistrars || c.Department != "Revenue" {
+					t.Errorf("case = %+v", c)
+				}
+				if lastHistory(c) != casemodel.StatusVerifiedByRegistrar || c.LastModified != stamp {
+					t.Errorf("history = %+v, lastModified = %s", c.History, c.LastModified)
+				}
+			},
+		},
+		{
+			Name:     "VerifyCase rejected",
+			Seed:     []*Case{pending},
+			Caller:   registrar,
+			Function: "VerifyCase",
+			Args:     []string{"CASE_001", `{"isVerified":false,"comments":"Missing affidavit"}`},
+			Check: func(t *testing.T, n *mockstub.Network, _ []byte) {
+				c := stored(t, n, lawyerChannel, "CASE_001")
+				if c.Status != casemodel.StatusRejectedByRegistrar || c.CurrentOrg != casemodel.OrgLawyers || c.Department != "Civil" {
+					t.Errorf("case = %+v", c)
+				}
+				// The lawyer is not installed, so the case waits in the outbox
+				pending := contract.Outbox(t, n)
+				if len(pending) != 1 || pending[0].Function != "ReceiveRegistrarRejection" || pending[0].To != casemodel.OrgLawyers || pending[0].Case.Status != casemodel.StatusRejectedByRegistrar {
+					t.Errorf("pending transfers = %+v", pending)
+				}
+			},
+		},
+		{
+			Name:     "VerifyCase rejected handed to lawyer",
+			Seed:     []*Case{pending},
+			Peers:    map[string]mockstub.ChaincodeFunc{"lawyer": contracttest.Fake(map[string]peer.Response{"ReceiveRegistrarRejection": shim.Success(nil)})},
+			Caller:   registrar,
+			Function: "VerifyCase",
+			Args:     []string{"CASE_001", `{"isVerified":false,"comments":"Missing affidavit"}`},
+			Check: func(t *testing.T, n *mockstub.Network, _ []byte) {
+				if pending := contract.Outbox(t, n); len(pending) != 0 {
+					t.Errorf("pending transfers = %+v", pending)
+				}
+			},
+		},
+		{
+			Name: "ClaimTransfer by lawyer outside ReceiveTransfers",
+			Seed: []*Case{pending},
+			Setup: func(n *mockstub.Network) {
+				if _, err := n.Submit(lawyerChannel, "registrar", registrar, "VerifyCase", "CASE_001", `{"isVerified":false}`); err != nil {
+					panic(err)
+				}
+			},
+			Caller:   lawyer,
+			Function: "ClaimTransfer",
+			Args:     []string{"1"},
+			WantErr:  "transfer 1 can only be claimed by ReceiveTransfers of lawyer, not ClaimTransfer of registrar",
+		},
+		{
+			Name:     "ClaimTransfer by stamp reporter",
+			Caller:   stampReporter,
+			Function: "ClaimTransfer",
+			Args:     []string{"1"},
+			WantErr:  "access denied for ClaimTransfer",
+		},
+		{
+			Name:     "VerifyCase not pending",
+			Seed:     []*Case{verified},
+			Caller:   registrar,
+			Function: "VerifyCase",
+			Args:     []string{"CASE_002", `{"isVerified":true}`},
+			WantErr:  "case CASE_002 cannot move from VERIFIED_BY_REGISTRAR to VERIFIED_BY_REGISTRAR by RegistrarsOrg",
+		},
+		{
+			Name:     "VerifyCase missing case",
+			Caller:   registrar,
+			Function: "VerifyCase",
+			Args:     []string{"CASE_404", `{"isVerified":true}`},
+			WantErr:  "case does not exist: CASE_404",
+		},
+		{
+			Name:     "VerifyCase by lawyer",
+			Seed:     []*Case{pending},
+			Caller:   lawyer,
+			Function: "VerifyCase",
+			Args:     []string{"CASE_001", `{"isVerified":true}`},
+			WantErr:  "access denied for VerifyCase",
+		},
+		{
+			Name:     "AssignToStampReporter",
+			Seed:     []*Case{verified},
+			Caller:   registrar,
+			Function: "AssignToStampReporter",
+			Args:     []string{"CASE_002"},
+			Check: func(t *testing.T, n *mockstub.Network, _ []byte) {
+				c := stored(t, n, lawyerChannel, "CASE_002")
+				if c.Status != casemodel.StatusPendingStampReporterReview || c.CurrentOrg != casemodel.OrgStampReporters || lastHistory(c) != "ASSIGNED_TO_STAMP_REPORTER" {
+					t.Errorf("case = %+v", c)
+				}
+			},
+		},
+		{
+			Name:     "AssignToStampReporter before verification",
+			Seed:     []*Case{pending},
+			Caller:   registrar,
+			Function: "AssignToStampReporter",
+			Args:     []string{"CASE_001"},
+			WantErr:  "cannot move from PENDING_REGISTRAR_REVIEW to PENDING_STAMP_REPORTER_REVIEW",
+		},
+		{
+			Name:     "ReceiveCase",
+			Caller:   lawyer,
+			Function: "ReceiveCase",
+			Args:     []string{string(contracttest.MustJSON(submitted))},
+			Check: func(t *testing.T, n *mockstub.Network, _ []byte) {
+				if c := stored(t, n, lawyerChannel, "CASE_001"); lastHistory(c) != "RECEIVED_FROM_LAWYER" {
+					t.Errorf("history = %+v", c.History)
+				}
+			},
+		},
+		{
+			Name:     "ReceiveCase with private details",
+			Caller:   lawyer,
+			Function: "ReceiveCase",
+			Args:     []string{string(contracttest.MustJSON(submittedWithParties))},
+			Details:  `{"uidParty1":"P1","uidParty2":"P2","clientName":"Ravi Sharma"}`,
+			Check: func(t *testing.T, n *mockstub.Network, _ []byte) {
+				if got := string(n.GetPrivateData(lawyerChannel, "registrar", casemodel.CollectionPartyIdentities, "CASE_001")); got != parties {
+					t.Errorf("party identities = %s", got)
+				}
+			},
+		},
+		{
+			Name:     "ReceiveCase with altered private details",
+			Caller:   lawyer,
+			Function: "ReceiveCase",
+			Args:     []string{string(contracttest.MustJSON(submittedWithParties))},
+			Details:  `{"uidParty1":"P1","uidParty2":"P3"}`,
+			WantErr:  "private details for partyIdentities do not match the hash on case CASE_001",
+		},
+		{
+			Name:     "ReceiveCase resubmitted",
+			Seed:     []*Case{rejected},
+			Caller:   lawyer,
+			Function: "ReceiveCase",
+			Args:     []string{string(contracttest.MustJSON(resubmittedSealed))},
+			Check: func(t *testing.T, n *mockstub.Network, _ []byte) {
+				c := stored(t, n, lawyerChannel, "CASE_003")
+				if c.Status != casemodel.StatusPendingRegistrarReview || c.ResubmissionCount != 1 {
+					t.Errorf("case = %+v", c)
+				}
+				if last := c.History[len(c.History)-1]; last.Status != "RECEIVED_FROM_LAWYER" || last.Comments != "Case resubmitted by lawyer for review, attempt 1" {
+					t.Errorf("history = %+v", c.History)
+				}
+			},
+		},
+		{
+			Name:     "ReceiveCase resubmitted out of turn",
+			Seed:     []*Case{rejected},
+			Caller:   lawyer,
+			Function: "ReceiveCase",
+			Args:     []string{string(contracttest.MustJSON(contracttest.NewCase("CASE_003", casemodel.StatusPendingRegistrarReview, casemodel.OrgRegistrars)))},
+			WantErr:  "case CASE_003 is resubmission 0, expected resubmission 1",
+		},
+		{
+			Name:     "ReceiveCase unsealed",
+			Caller:   lawyer,
+			Function: "ReceiveCase",
+			Args:     []string{string(contracttest.MustJSON(pending))},
+			WantErr:  "case CASE_001 has no hash chain",
+		},
+		{
+			Name:     "ReceiveCase forged",
+			Caller:   lawyer,
+			Function: "ReceiveCase",
+			Args:     []string{string(contracttest.MustJSON(&forged))},
+			WantErr:  "case CASE_001 does not match the hash recorded by transaction",
+		},
+		{
+			Name:     "ReceiveCase by a lawyer not on the case",
+			Caller:   otherLawyer,
+			Function: "ReceiveCase",
+			Args:     []string{string(contracttest.MustJSON(submitted))},
+			WantErr:  "lawyer is not associated with the case",
+		},
+		{
+			Name:     "ReceiveCase already under review",
+			Seed:     []*Case{pending},
+			Caller:   lawyer,
+			Function: "ReceiveCase",
+			Args:     []string{string(contracttest.MustJSON(pending))},
+			WantErr:  "case CASE_001 is already PENDING_REGISTRAR_REVIEW, only a case the registrar rejected can be resubmitted",
+		},
+		{
+			Name:     "ReceiveCase not submitted",
+			Caller:   lawyer,
+			Function: "ReceiveCase",
+			Args:     []string{string(contracttest.MustJSON(contracttest.NewCase("CASE_001", casemodel.StatusCreated, casemodel.OrgLawyers)))},
+			WantErr:  "invalid case status or organization: status=CREATED, org=LawyersOrg",
+		},
+		{
+			Name:     "ReceiveCase without ID",
+			Caller:   lawyer,
+			Function: "ReceiveCase",
+			Args:     []string{`{"status":"PENDING_REGISTRAR_REVIEW","currentOrg":"RegistrarsOrg"}`},
+			WantErr:  "case ID is required",
+		},
+		{
+			Name:     "ReceiveCase invalid JSON",
+			Caller:   lawyer,
+			Function: "ReceiveCase",
+			Args:     []string{`[`},
+			WantErr:  "failed to unmarshal case data",
+		},
+		{
+			Name:     "ReceiveCase by registrar",
+			Caller:   registrar,
+			Function: "ReceiveCase",
+			Args:     []string{string(contracttest.MustJSON(pending))},
+			WantErr:  "access denied for ReceiveCase",
+		},
+		{
+			Name: "ReceiveTransfers",
+			Peers: map[string]mockstub.ChaincodeFunc{"lawyer": contracttest.Fake(map[string]peer.Response{
+				"ListPendingTransfers": shim.Success(contracttest.MustJSON([]*casemodel.Transfer{forRegistrar})),
+				"ClaimTransfer":        shim.Success(contracttest.MustJSON(forRegistrar)),
+			})},
+			Caller:   registrar,
+			Function: "ReceiveTransfers",
+			Check: func(t *testing.T, n *mockstub.Network, payload []byte) {
+				if string(payload) != `["CASE_001"]` {
+					t.Errorf("received = %s", payload)
+				}
+				if c := stored(t, n, lawyerChannel, "CASE_001"); lastHistory(c) != "RECEIVED_FROM_LAWYER" {
+					t.Errorf("history = %+v", c.History)
+				}
+			},
+		},
+		{
+			Name: "ReceiveTransfers claim refused",
+			Peers: map[string]mockstub.ChaincodeFunc{"lawyer": contracttest.Fake(map[string]peer.Response{
+				"ListPendingTransfers": shim.Success(contracttest.MustJSON([]*casemodel.Transfer{forRegistrar})),
+				"ClaimTransfer":        shim.Error("transfer 1 was already claimed by RegistrarsOrg"),
+			})},
+			Caller:   registrar,
+			Function: "ReceiveTransfers",
+			WantErr:  "failed to claim transfer 1 from lawyer: transfer 1 was already claimed by RegistrarsOrg",
+		},
+		{
+			Name:     "ReceiveTransfers by lawyer",
+			Caller:   lawyer,
+			Function: "ReceiveTransfers",
+			WantErr:  "access denied for ReceiveTransfers",
+		},
+		{
+			Name:     "GetPendingCases",
+			Seed:     []*Case{pending, verified, pendingCriminal},
+			Caller:   registrar,
+			Function: "GetPendingCases",
+			Args:     []string{`{"department":"Criminal"}`},
+			Check: func(t *testing.T, _ *mockstub.Network, payload []byte) {
+				var cases []*Case
+				contracttest.Decode(t, payload, &cases)
+				if got := ids(cases); got != "CASE_004" {
+					t.Errorf("cases = %s", got)
+				}
+			},
+		},
+		{
+			Name:     "GetPendingCases by case ID",
+			Seed:     []*Case{pending, verified},
+			Caller:   registrar,
+			Function: "GetPendingCases",
+			Args:     []string{"CASE_002"},
+			Check: func(t *testing.T, _ *mockstub.Network, payload []byte) {
+				var cases []*Case
+				contracttest.Decode(t, payload, &cases)
+				if got := ids(cases); got != "CASE_002" {
+					t.Errorf("cases = %s", got)
+				}
+			},
+		},
+		{
+			Name:     "GetPendingCases sealed",
+			Seed:     []*Case{pending, &inCamera},
+			Caller:   registrar,
+			Function: "GetPendingCases",
+			Args:     []string{""},
+			Check: func(t *testing.T, _ *mockstub.Network, payload []byte) {
+				var cases []*Case
+				contracttest.Decode(t, payload, &cases)
+				if len(cases) != 2 || cases[0].Department != "Civil" {
+					t.Fatalf("cases = %s", payload)
+				}
+				if stub := cases[1]; stub.ID != "CASE_004" || !stub.Sealed || stub.Status != casemodel.StatusPendingRegistrarReview || stub.Department != "" || stub.AccessList != nil {
+					t.Errorf("sealed case = %s", contracttest.MustJSON(stub))
+				}
+			},
+		},
+		{
+			Name:     "GetPendingCases sealed by case ID",
+			Seed:     []*Case{&inCamera},
+			Caller:   registrar,
+			Function: "GetPendingCases",
+			Args:     []string{"CASE_004"},
+			Check: func(t *testing.T, _ *mockstub.Network, payload []byte) {
+				var cases []*Case
+				contracttest.Decode(t, payload, &cases)
+				if len(cases) != 1 || !cases[0].Sealed || cases[0].Department != "" {
+					t.Errorf("cases = %s", payload)
+				}
+			},
+		},
+		{
+			Name:     "GetPendingCases unknown case ID",
+			Caller:   registrar,
+			Function: "GetPendingCases",
+			Args:     []string{"CASE_404"},
+			WantErr:  "case not found: CASE_404",
+		},
+		{
+			Name:     "GetPendingCasesWithPagination",
+			Seed:     []*Case{pending, verified, pendingCriminal},
+			Caller:   registrar,
+			Function: "GetPendingCasesWithPagination",
+			Args:     []string{"", "1", "CASE_001"},
+			Check: func(t *testing.T, _ *mockstub.Network, payload []byte) {
+				var page CasePage
+				contracttest.Decode(t, payload, &page)
+				if ids(page.Records) != "CASE_004" || page.Bookmark != "CASE_004" {
+					t.Errorf("page = %+v", page)
+				}
+			},
+		},
+		{
+			Name:     "GetPendingCasesWithPagination invalid filter",
+			Caller:   registrar,
+			Function: "GetPendingCasesWithPagination",
+			Args:     []string{"CASE_001", "1", ""},
+			WantErr:  "failed to unmarshal filter",
+		},
+		{
+			Name:     "GetVerifiedCases",
+			Seed:     []*Case{pending, verified},
+			Caller:   registrar,
+			Function: "GetVerifiedCases",
+			Args:     []string{""},
+			Check: func(t *testing.T, _ *mockstub.Network, payload []byte) {
+				var cases []*Case
+				contracttest.Decode(t, payload, &cases)
+				if got := ids(cases); got != "CASE_002" {
+					t.Errorf("cases = %s", got)
+				}
+			},
+		},
+		{
+			Name:     "GetVerifiedCasesWithPagination",
+			Seed:     []*Case{pending, verified},
+			Caller:   registrar,
+			Function: "GetVerifiedCasesWithPagination",
+			Args:     []string{"{}", "10", ""},
+			Check: func(t *testing.T, _ *mockstub.Network, payload []byte) {
+				var page CasePage
+				contracttest.Decode(t, payload, &page)
+				if ids(page.Records) != "CASE_002" || page.FetchedCount != 1 {
+					t.Errorf("page = %+v", page)
+				}
+			},
+		},
+		{
+			Name: "GetAllState",
+			Seed: []*Case{pending},
+			Setup: func(n *mockstub.Network) {
+				n.PutState(lawyerChannel, "registrar", "note", []byte("not json"))
+			},
+			Caller:   registrar,
+			Function: "GetAllState",
+			Check: func(t *testing.T, _ *mockstub.Network, payload []byte) {
+				var state []map[string]interface{}
+				contracttest.Decode(t, payload, &state)
+				if len(state) != 2 || state[0]["key"] != "CASE_001" || state[0]["status"] != casemodel.StatusPendingRegistrarReview || state[1]["raw_value"] != "not json" {
+					t.Errorf("state = %v", state)
+				}
+			},
+		},
+		{
+			Name:     "GetAllState sealed",
+			Seed:     []*Case{&inCamera},
+			Caller:   registrar,
+			Function: "GetAllState",
+			Check: func(t *testing.T, _ *mockstub.Network, payload []byte) {
+				var state []map[string]interface{}
+				contracttest.Decode(t, payload, &state)
+				if len(state) != 1 || state[0]["key"] != "CASE_004" || state[0]["sealed"] != true || state[0]["department"] != "" || state[0]["accessList"] != nil {
+					t.Errorf("state = %v", state)
+				}
+			},
+		},
+		{
+			Name: "QueryStats",
+			Seed: []*Case{pending, verified, rejected},
+			Setup: func(n *mockstub.Network) {
+				contract.Seed(n, stampReporterChannel, contracttest.NewCase("CASE_005", casemodel.StatusPendingStampReporterReview, casemodel.OrgStampReporters))
+			},
+			Caller:   registrar,
+			Function: "QueryStats",
+			Check: func(t *testing.T, _ *mockstub.Network, payload []byte) {
+				want := `{"pendingCases":1,"verifiedCases":1,"rejectedCases":1,"assignedToStamp":1,"transferredCases":0}`
+				if string(payload) != want {
+					t.Errorf("QueryStats = %s, want %s", payload, want)
+				}
+			},
+		},
+		{
+			Name:     "TestJSONParsing",
+			Caller:   registrar,
+			Function: "TestJSONParsing",
+			Args:     []string{`{"id":"CASE_001","title":"Land dispute"}`},
+			Check: func(t *testing.T, _ *mockstub.Network, payload []byte) {
+				if want := "Successfully parsed case with ID: CASE_001, Title: Land dispute"; string(payload) != want {
+					t.Errorf("TestJSONParsing = %s", payload)
+				}
+			},
+		},
+		{
+			Name:     "TestJSONParsing invalid JSON",
+			Caller:   registrar,
+			Function: "TestJSONParsing",
+			Args:     []string{`{"id":`},
+			WantErr:  "failed to parse JSON",
+		},
+		{
+			Name:     "GetCaseById by stamp reporter",
+			Channel:  stampReporterChannel,
+			Seed:     []*Case{verified},
+			Caller:   stampReporter,
+			Function: "GetCaseById",
+			Args:     []string{"CASE_002"},
+			Check: func(t *testing.T, _ *mockstub.Network, payload []byte) {
+				var c Case
+				contracttest.Decode(t, payload, &c)
+				if c.ID != "CASE_002" || c.Status != casemodel.StatusVerifiedByRegistrar {
+					t.Errorf("case = %+v", c)
+				}
+			},
+		},
+		{
+			Name:     "GetCaseById missing case",
+			Caller:   registrar,
+			Function: "GetCaseById",
+			Args:     []string{"CASE_404"},
+			WantErr:  "case not found: CASE_404",
+		},
+		{
+			Name:     "GetAllowedTransitions",
+			Seed:     []*Case{verified},
+			Caller:   registrar,
+			Function: "GetAllowedTransitions",
+			Args:     []string{"CASE_002"},
+			Check: func(t *testing.T, _ *mockstub.Network, payload []byte) {
+				var transitions []casemodel.Transition
+				contracttest.Decode(t, payload, &transitions)
+				if len(transitions) != 2 || transitions[0].To != casemodel.StatusTransferredToStampReporter || transitions[1].To != casemodel.StatusPendingStampReporterReview {
+					t.Errorf("transitions = %+v", transitions)
+				}
+			},
+		},
+		{
+			Name:     "VerifyCaseHistory",
+			Seed:     []*Case{chained},
+			Caller:   registrar,
+			Function: "VerifyCaseHistory",
+			Args:     []string{"CASE_002"},
+			Check: func(t *testing.T, _ *mockstub.Network, payload []byte) {
+				var v casemodel.HistoryVerification
+				contracttest.Decode(t, payload, &v)
+				if !v.Valid || v.Chained != 2 || v.BrokenAt != -1 {
+					t.Errorf("verification = %+v", v)
+				}
+			},
+		},
+		{
+			Name:     "VerifyCaseHistory edited entry",
+			Seed:     []*Case{rewritten},
+			Caller:   registrar,
+			Function: "VerifyCaseHistory",
+			Args:     []string{"CASE_002"},
+			Check: func(t *testing.T, _ *mockstub.Network, payload []byte) {
+				var v casemodel.HistoryVerification
+				contracttest.Decode(t, payload, &v)
+				if v.Valid || v.BrokenAt != 0 || v.Reason != "entry does not match its hash" {
+					t.Errorf("verification = %+v", v)
+				}
+			},
+		},
+		{
+			Name: "GetCaseProvenance",
+			Setup: func(n *mockstub.Network) {
+				if _, err := n.Submit(lawyerChannel, "registrar", lawyer, "ReceiveCase", string(contracttest.MustJSON(submitted))); err != nil {
+					panic(err)
+				}
+				if _, err := n.Submit(lawyerChannel, "registrar", registrar, "VerifyCase", "CASE_001", `{"isVerified":true,"comments":"Complete","department":"Revenue"}`); err != nil {
+					panic(err)
+				}
+			},
+			Caller:   registrar,
+			Function: "GetCaseProvenance",
+			Args:     []string{"CASE_001"},
+			Check: func(t *testing.T, _ *mockstub.Network, payload []byte) {
+				// seeding bypasses the ledger history, so only the two transactions are in it
+				var provenance casemodel.CaseProvenance
+				contracttest.Decode(t, payload, &provenance)
+				if len(provenance.Versions) != 2 {
+					t.Fatalf("versions = %+v", provenance.Versions)
+				}
+				if v := provenance.Versions[1]; contracttest.Changed(v, "status") != (casemodel.FieldChange{Field: "status", Before: string(contracttest.MustJSON(casemodel.StatusPendingRegistrarReview)), After: string(contracttest.MustJSON(casemodel.StatusVerifiedByRegistrar))}) {
+					t.Errorf("changes = %+v", v.Changes)
+				}
+			},
+		},
+		{
+			Name:     "GetCaseProvenance missing case",
+			Caller:   registrar,
+			Function: "GetCaseProvenance",
+			Args:     []string{"CASE_404"},
+			WantErr:  "case does not exist: CASE_404",
+		},
+		{
+			Name: "GetCasePrivateDetails",
+			Setup: func(n *mockstub.Network) {
+				details := map[string][]byte{casemodel.TransientPrivateDetails: []byte(`{"uidParty1":"P1","uidParty2":"P2"}`)}
+				if _, err := n.SubmitTransient(lawyerChannel, "registrar", lawyer, details, "ReceiveCase", string(contracttest.MustJSON(submittedWithParties))); err != nil {
+					panic(err)
+				}
+			},
+			Caller:   registrar,
+			Function: "GetCasePrivateDetails",
+			Args:     []string{"CASE_001"},
+			Check: func(t *testing.T, _ *mockstub.Network, payload []byte) {
+				var details casemodel.CasePrivateDetails
+				if err := json.Unmarshal(payload, &details); err != nil {
+					t.Fatal(err)
+				}
+				if details.UIDParty1 != "P1" || details.UIDParty2 != "P2" || details.ClientName != "" {
+					t.Errorf("details = %+v", details)
+				}
+			},
+		},
+		{
+			Name:     "GetCasePrivateDetails by lawyer",
+			Seed:     []*Case{pending},
+			Caller:   lawyer,
+			Function: "GetCasePrivateDetails",
+			Args:     []string{"CASE_001"},
+			WantErr:  "access denied for GetCasePrivateDetails",
+		},
+		{
+			Name:    "FetchAndStoreCaseFromLawyerChannel",
+			Channel: stampReporterChannel,
+			Setup: func(n *mockstub.Network) {
+				contract.Seed(n, lawyerChannel, contract.Sealed(verified, registrar, lawyerChannel))
+			},
+			Peers: map[string]mockstub.ChaincodeFunc{
+				"stampreporter": stampReporterPeer,
+				"lawyer":        contracttest.Fake(map[string]peer.Response{"ListPendingTransfers": shim.Success([]byte("[]"))}),
+			},
+			Caller:   registrar,
+			Function: "FetchAndStoreCaseFromLawyerChannel",
+			Args:     []string{"CASE_002"},
+			Check: func(t *testing.T, n *mockstub.Network, _ []byte) {
+				c := stored(t, n, stampReporterChannel, "CASE_002")
+				if c.Status != casemodel.StatusPendingStampReporterReview || c.CurrentOrg != casemodel.OrgStampReporters || lastHistory(c) != "ASSIGNED_TO_STAMP_REPORTER" {
+					t.Errorf("case = %+v", c)
+				}
+				if len(stampReporterCalls) != 1 || stampReporterCalls[0].Status != casemodel.StatusPendingStampReporterReview {
+					t.Errorf("stamp reporter received %+v", stampReporterCalls)
+				}
+				// A write to the lawyer channel from here would be dropped, so the update
+				// waits in the outbox until the registrar pulls it on that channel
+				if c := stored(t, n, lawyerChannel, "CASE_002"); c.Status != casemodel.StatusVerifiedByRegistrar {
+					t.Errorf("lawyer channel copy is %s", c.Status)
+				}
+				payload, err := n.Evaluate(stampReporterChannel, "registrar", registrar, "ListPendingTransfers")
+				if err != nil {
+					t.Fatal(err)
+				}
+				var transfers []*casemodel.Transfer
+				contracttest.Decode(t, payload, &transfers)
+				if len(transfers) != 1 || transfers[0].To != casemodel.OrgRegistrars || transfers[0].Channel != lawyerChannel || transfers[0].Case.Status != casemodel.StatusTransferredToStampReporter {
+					t.Fatalf("transfers = %+v", transfers)
+				}
+				// Both transitions are announced in the transaction's one event
+				events := n.Events()
+				if len(events) != 1 || events[0].Name != casemodel.StatusChangedEventName(casemodel.OrgStampReporters) {
+					t.Fatalf("events = %+v", events)
+				}
+				var changes []casemodel.StatusChangedEvent
+				contracttest.Decode(t, events[0].Payload, &changes)
+				if len(changes) != 2 || changes[0].ToStatus != casemodel.StatusTransferredToStampReporter || changes[1].ToStatus != casemodel.StatusPendingStampReporterReview {
+					t.Errorf("status changes = %+v", changes)
+				}
+
+				payload, err = n.Submit(lawyerChannel, "registrar", registrar, "ReceiveTransfers")
+				if err != nil {
+					t.Fatal(err)
+				}
+				if string(payload) != `["CASE_002"]` {
+					t.Errorf("received = %s", payload)
+				}
+				if c := stored(t, n, lawyerChannel, "CASE_002"); c.Status != casemodel.StatusTransferredToStampReporter || lastHistory(c) != "CASE_SYNCED" {
+					t.Errorf("lawyer channel copy = %+v", c)
+				}
+				payload, err = n.Submit(stampReporterChannel, "registrar", registrar, "RetryTransfer", "1")
+				if err != nil {
+					t.Fatal(err)
+				}
+				var retried casemodel.Transfer
+				contracttest.Decode(t, payload, &retried)
+				if retried.Status != casemodel.TransferClaimed || retried.ClaimedBy != casemodel.OrgRegistrars {
+					t.Errorf("retried = %+v", retried)
+				}
+			},
+		},
+		{
+			Name:    "FetchAndStoreCaseFromLawyerChannel refused by the stamp reporter",
+			Channel: stampReporterChannel,
+			Setup: func(n *mockstub.Network) {
+				contract.Seed(n, lawyerChannel, contract.Sealed(verified, registrar, lawyerChannel))
+			},
+			Peers: map[string]mockstub.ChaincodeFunc{
+				"stampreporter": contracttest.Fake(map[string]peer.Response{"StoreCase": shim.Error("case CASE_002 has no hash chain")}),
+				"lawyer":        contracttest.Fake(map[string]peer.Response{"ListPendingTransfers": shim.Success([]byte("[]"))}),
+			},
+			Caller:   registrar,
+			Function: "FetchAndStoreCaseFromLawyerChannel",
+			Args:     []string{"CASE_002"},
+			Check: func(t *testing.T, n *mockstub.Network, _ []byte) {
+				// the handoff waits in the outbox beside the lawyer channel's copy
+				payload, err := n.Evaluate(stampReporterChannel, "registrar", registrar, "ListPendingTransfers")
+				if err != nil {
+					t.Fatal(err)
+				}
+				var transfers []*casemodel.Transfer
+				contracttest.Decode(t, payload, &transfers)
+				if len(transfers) != 2 || transfers[1].Sequence != 2 || transfers[1].To != casemodel.OrgStampReporters || transfers[1].Function != "StoreCase" || transfers[1].LastError != "case CASE_002 has no hash chain" {
+					t.Fatalf("transfers = %+v", transfers)
+				}
+			},
+		},
+		{
+			Name:     "FetchAndStoreCaseFromLawyerChannel already transferred",
+			Channel:  stampReporterChannel,
+			Seed:     []*Case{verified},
+			Caller:   registrar,
+			Function: "FetchAndStoreCaseFromLawyerChannel",
+			Args:     []string{"CASE_002"},
+			Check: func(t *testing.T, n *mockstub.Network, _ []byte) {
+				if c := stored(t, n, stampReporterChannel, "CASE_002"); c.Status != casemodel.StatusPendingStampReporterReview {
+					t.Errorf("case is %s", c.Status)
+				}
+			},
+		},
+		{
+			Name:    "FetchAndStoreCaseFromLawyerChannel not verified",
+			Channel: stampReporterChannel,
+			Setup: func(n *mockstub.Network) {
+				contract.Seed(n, lawyerChannel, contract.Sealed(pending, registrar, lawyerChannel))
+			},
+			Caller:   registrar,
+			Function: "FetchAndStoreCaseFromLawyerChannel",
+			Args:     []string{"CASE_001"},
+			WantErr:  "case CASE_001 cannot move from PENDING_REGISTRAR_REVIEW to PENDING_STAMP_REPORTER_REVIEW by RegistrarsOrg",
+		},
+		{
+			Name:    "FetchAndStoreCaseFromLawyerChannel marked verified by the lawyer",
+			Channel: stampReporterChannel,
+			Setup: func(n *mockstub.Network) {
+				contract.Seed(n, lawyerChannel, contract.Sealed(verified, lawyer, lawyerChannel))
+			},
+			Caller:   registrar,
+			Function: "FetchAndStoreCaseFromLawyerChannel",
+			Args:     []string{"CASE_002"},
+			WantErr:  "case CASE_002 was last stored by LawyersOrg, not RegistrarsOrg",
+		},
+		{
+			Name:     "FetchAndStoreCaseFromLawyerChannel not sealed",
+			Channel:  stampReporterChannel,
+			Setup:    func(n *mockstub.Network) { contract.Seed(n, lawyerChannel, verified) },
+			Caller:   registrar,
+			Function: "FetchAndStoreCaseFromLawyerChannel",
+			Args:     []string{"CASE_002"},
+			WantErr:  "case CASE_002 has no hash chain",
+		},
+		{
+			Name:     "FetchAndStoreCaseFromLawyerChannel missing case",
+			Channel:  stampReporterChannel,
+			Caller:   registrar,
+			Function: "FetchAndStoreCaseFromLawyerChannel",
+			Args:     []string{"CASE_404"},
+			WantErr:  "Failed to fetch case from lawyer-registrar-channel: case not found: CASE_404",
+		},
+		{
+			Name:     "GetRoutingConfig defaults",
+			Caller:   registrar,
+			Function: "GetRoutingConfig",
+			Check: func(t *testing.T, _ *mockstub.Network, payload []byte) {
+				var config casemodel.RoutingConfig
+				contracttest.Decode(t, payload, &config)
+				if route := config.Routes[casemodel.HopRegistrarToStampReporter]; route != (casemodel.Route{Chaincode: "stampreporter", Channel: casemodel.ChannelRegistrarStampReporter}) {
+					t.Errorf("route = %+v", route)
+				}
+			},
+		},
+		{
+			Name:     "SetRoutingConfig",
+			Caller:   registrarAdmin,
+			Function: "SetRoutingConfig",
+			Args:     []string{routing},
+			Check: func(t *testing.T, n *mockstub.Network, _ []byte) {
+				payload, err := n.Evaluate(lawyerChannel, "registrar", registrar, "GetRoutingConfig")
+				if err != nil {
+					t.Fatal(err)
+				}
+				var config casemodel.RoutingConfig
+				contracttest.Decode(t, payload, &config)
+				if route := config.Routes[casemodel.HopRegistrarToStampReporter]; route.Chaincode != "stampreporter-v2" {
+					t.Errorf("route = %+v", route)
+				}
+			},
+		},
+		{
+			Name:     "SetRoutingConfig without a route the contract uses",
+			Caller:   registrarAdmin,
+			Function: "SetRoutingConfig",
+			Args:     []string{`{"routes":{}}`},
+			WantErr:  "invalid routing config: route registrar->registrar@lawyer is not configured",
+		},
+		{
+			Name:     "SetRoutingConfig by another organization",
+			Caller:   lawyer,
+			Function: "SetRoutingConfig",
+			Args:     []string{routing},
+			WantErr:  "access denied for SetRoutingConfig",
+		},
+	}
+	contract.Run(t, tests)
+}
+
+// TestSyncCase stores copies of a case as ReceiveTransfers pulls them from the registrar's
+// outbox on registrar-stampreporter-channel
+func TestSyncCase(t *testing.T) {
+	existing := contracttest.NewCase("CASE_002", casemodel.StatusVerifiedByRegistrar, casemodel.OrgRegistrars)
+	existing.History = []HistoryItem{{Status: casemodel.StatusVerifiedByRegistrar, Organization: "RegistrarsOrg"}}
+	existing = contract.Sealed(existing, registrar, lawyerChannel)
+
+	transferred := *existing
+	transferred.Status = casemodel.StatusTransferredToStampReporter
+	transferred.CurrentOrg = casemodel.OrgStampReporters
+	transferred.History = append(append([]HistoryItem(nil), existing.History...), HistoryItem{Status: "TRANSFERRED_TO_STAMPREPORTER"})
+
+	stale := *existing
+	stale.Status = casemodel.StatusPendingRegistrarReview
+
+	rewritten := transferred
+	rewritten.History = []HistoryItem{{Status: "FORGED"}, {Status: "TRANSFERRED_TO_STAMPREPORTER"}}
+
+	tests := []struct {
+		name     string
+		incoming *Case
+		wantErr  string
+	}{
+		{"forward sync", contract.Sealed(&transferred, registrar, stampReporterChannel), ""},
+		{"not sealed after the change", &transferred, "case CASE_002 does not match the hash recorded by transaction"},
+		{"stored by another org", contract.Sealed(&transferred, stampReporter, stampReporterChannel), "case CASE_002 was last stored by StampReportersOrg, not RegistrarsOrg"},
+		{"stale copy", contract.Sealed(&stale, registrar, stampReporterChannel), "cannot move from VERIFIED_BY_REGISTRAR to PENDING_REGISTRAR_REVIEW"},
+		{"rewritten history", contract.Sealed(&rewritten, registrar, stampReporterChannel), "would rewrite history entry 0"},
+		{"unknown case", contract.Sealed(contracttest.NewCase("CASE_404", casemodel.StatusVerifiedByRegistrar, casemodel.OrgRegistrars), registrar, stampReporterChannel), "case does not exist: CASE_404"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n := contract.NewNetwork(t, nil)
+			contract.Seed(n, lawyerChannel, existing)
+			stub, err := n.NewTransaction(mockstub.Proposal{Channel: lawyerChannel, Chaincode: "registrar", Identity: registrar, Function: "ReceiveTransfers"})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = New().syncCase(stub.Context(), string(contracttest.MustJSON(tt.incoming)))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := n.Commit(stub); err != nil {
+				t.Fatal(err)
+			}
+			c := stored(t, n, lawyerChannel, "CASE_002")
+			if c.Status != casemodel.StatusTransferredToStampReporter || len(c.History) != 3 || lastHistory(c) != "CASE_SYNCED" {
+				t.Errorf("case = %+v", c)
+			}
+		})
+	}
+}
@@ -0,0 +1,20 @@
+package main
+
+import (
+	"log"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+
+	"registrar/chaincode"
+)
+
+func main() {
+	registrarChaincode, err := contractapi.NewChaincode(chaincode.New())
+	if err != nil {
+		log.Panicf("Error creating registrar chaincode: %v", err)
+	}
+
+	if err := registrarChaincode.Start(); err != nil {
+		log.Panicf("Error starting registrar chaincode: %v", err)
+	}
+}
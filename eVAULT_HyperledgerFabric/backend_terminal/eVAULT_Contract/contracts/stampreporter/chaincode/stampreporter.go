@@ -0,0 +1,1304 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+
+	"casemodel"
+	"casemodel/access"
+	"casemodel/query"
+)
+
+// The case record is shared by every eVAULT contract so that no field is lost as a
+// case moves between channels
+type (
+	Case              = casemodel.Case
+	Document          = casemodel.Document
+	DocumentSignature = casemodel.DocumentSignature
+	HistoryItem       = casemodel.HistoryItem
+	CasePage          = casemodel.CasePage
+)
+
+// ValidationRequest represents the stamp reporter's verdict on one document. Verdict is
+// casemodel.VerdictAccepted, VerdictRejected or VerdictNeedsCorrection; a document that
+// is not accepted needs a ReasonCode. A document that needs correction raises a defect the
+// lawyer must cure by CureBy, an RFC 3339 time defaulting to casemodel.DefaultCurePeriod
+// from now. An accepted document is signed: Signature is a base64 ECDSA or Ed25519
+// signature over the raw SHA-256 that the document's anchor encodes, not over its content
+// hash. The anchor is the content digest, or the content hash for documents uploaded
+// before version 14, see casemodel.Document.Anchor. It is made with the key of the stamp
+// reporter's certificate or with the registered signing key KeyFingerprint names.
+type ValidationRequest struct {
+	DocumentID     string `json:"documentId"`
+	Verdict        string `json:"verdict"`
+	ReasonCode     string `json:"reasonCode,omitempty" metadata:",optional"`
+	Signature      string `json:"signature,omitempty" metadata:",optional"`
+	KeyFingerprint string `json:"keyFingerprint,omitempty" metadata:",optional"`
+	CureBy         string `json:"cureBy,omitempty" metadata:",optional"`
+	Comments       string `json:"comments"`
+}
+
+// StampReporterContract provides functions for document validation
+type StampReporterContract struct {
+	contractapi.Contract
+}
+
+// accessPolicy lists the roles allowed to call each transaction. Roles from other
+// organizations appear where a transaction is invoked across channels.
+var accessPolicy = access.Policy{
+	"InitLedger":                            {access.RoleStampReporter},
+	"ValidateDocuments":                     {access.RoleStampReporter},
+	"GetPendingCases":                       {access.RoleStampReporter},
+	"GetPendingCasesWithPagination":         {access.RoleStampReporter},
+	"GetCaseById":                           {access.RoleStampReporter, access.RoleBenchClerk}, // read by the bench clerk across channels
+	"QueryStats":                            {access.RoleStampReporter},
+	"ForwardCaseToBenchClerk":               {access.RoleStampReporter},
+	"ForwardCaseToLawyer":                   {access.RoleStampReporter},
+	"GetRejectedCases":                      {access.RoleStampReporter, access.RoleLawyer}, // read by the lawyer across channels
+	"GetOnHoldCases":                        {access.RoleStampReporter, access.RoleLawyer}, // read by the lawyer across channels
+	"StoreCase":                             {access.RoleRegistrar},                        // written by the registrar when a case is assigned
+	"ReceiveCuredCase":                      {access.RoleStampReporter, access.RoleLawyer}, // submitted by the lawyer through CureDefects
+	"ReceiveResubmittedCase":                {access.RoleStampReporter, access.RoleLawyer}, // submitted by the lawyer through ResubmitToStampReporter
+	"FetchAndStoreCaseFromRegistrarChannel": {access.RoleStampReporter},
+	"SyncCaseAcrossChannels":                {access.RoleStampReporter},
+	"GetAllPendingCasesFromRegistrar":       {access.RoleStampReporter},
+	"GetAllowedTransitions":                 {access.RoleStampReporter},
+	"VerifyCaseHistory":                     {access.RoleStampReporter},
+	"VerifyDocument":                        {access.RoleStampReporter},
+	"VerifyDocumentSignatures":              {access.RoleStampReporter},
+	"RegisterSigningKey":                    {access.RoleStampReporter},
+	"SetFeeSchedule":                        {access.RoleStampReporter},
+	"GetFeeSchedule":                        {access.RoleStampReporter},
+	"CalculateCourtFee":                     {access.RoleStampReporter},
+	"RecordFeePayment":                      {access.RoleStampReporter},
+	"GetCaseDefects":                        {access.RoleStampReporter},
+	"GetCaseProvenance":                     {access.RoleStampReporter},
+	"SetRoutingConfig":                      {access.RoleStampReporter},
+	"GetRoutingConfig":                      {access.RoleStampReporter},
+	"SetOrgRoots":                           {access.RoleLawyer, access.RoleRegistrar, access.RoleStampReporter, access.RoleBenchClerk, access.RoleJudge},
+	"ImportOrgRoots":                        {access.RoleStampReporter},
+	"GetOrgRoots":                           {access.RoleLawyer, access.RoleRegistrar, access.RoleStampReporter, access.RoleBenchClerk, access.RoleJudge},
+	"ListPendingTransfers":                  {access.RoleStampReporter, access.RoleBenchClerk, access.RoleLawyer}, // read by the bench clerk and lawyer when claiming transfers
+	"ClaimTransfer":                         {access.RoleBenchClerk, access.RoleLawyer},                           // invoked through ReceiveTransfers of the bench clerk and lawyer only
+	"RetryTransfer":                         {access.RoleStampReporter},
+	"ReceiveTransfers":                      {access.RoleStampReporter},
+}
+
+// hops are the calls this contract makes to other chaincodes. A stored routing table
+// must route all of them.
+var hops = []string{
+	casemodel.HopStampReporterToRegistrar,
+	casemodel.HopStampReporterToLawyer,
+	casemodel.HopStampReporterToBenchClerk,
+}
+
+// InitLedger initializes the ledger with the routing table of the calls this contract
+// makes, or the default routes when routingConfig is empty. Run it as the init
+// transaction on every channel the contract is deployed on.
+func (s *StampReporterContract) InitLedger(ctx contractapi.TransactionContextInterface, routingConfig string) error {
+	if err := access.RequireAdmin(ctx, "InitLedger"); err != nil {
+		return err
+	}
+	return casemodel.InitRouting(ctx, routingConfig, hops...)
+}
+
+// ValidateDocuments records the stamp reporter's verdict on each of a case's documents,
+// signing the accepted ones. The case is rejected if any active document is rejected,
+// put on hold if any needs correction and validated otherwise.
+func (s *StampReporterContract) ValidateDocuments(ctx contractapi.TransactionContextInterface, caseID string, validationDetails string) error {
+	log.Printf("ValidateDocuments called for case ID: %s", caseID)
+
+	// Get the case
+	var caseObj Case
+	var err error
+
+	// Try to get the case from the local ledger
+	caseJSON, err := ctx.GetStub().GetState(caseID)
+	if err != nil {
+		log.Printf("Failed to read case: %v", err)
+		return fmt.Errorf("failed to read case: %v", err)
+	}
+
+	if caseJSON == nil {
+		// Case doesn't exist locally, try to fetch it from registrar-stampreporter-channel
+		log.Printf("Case %s not found locally, attempting to fetch from registrar-stampreporter-channel", caseID)
+		fetchedCase, err := s.FetchAndStoreCaseFromRegistrarChannel(ctx, caseID)
+		if err != nil {
+			log.Printf("Failed to fetch case from registrar: %v", err)
+			return fmt.Errorf("case does not exist: %s", caseID)
+		}
+		caseObj = *fetchedCase
+	} else {
+		// Case exists locally, unmarshal it
+		err = json.Unmarshal(caseJSON, &caseObj)
+		if err != nil {
+			log.Printf("Failed to unmarshal case data: %v", err)
+			return err
+		}
+	}
+
+	// Initialize empty arrays if they are null
+	if caseObj.Documents == nil {
+		caseObj.Documents = make([]Document, 0)
+	}
+	if caseObj.History == nil {
+		caseObj.History = make([]HistoryItem, 0)
+	}
+	if caseObj.AssociatedLawyers == nil {
+		caseObj.AssociatedLawyers = make([]string, 0)
+	}
+
+	// Parse validation details
+	var details struct {
+		Comments        string              `json:"comments"`
+		Validations     []ValidationRequest `json:"validations"`
+		StampReporterID string              `json:"stampReporterId"`
+		RejectionReason string              `json:"rejectionReason,omitempty"`
+	}
+	err = json.Unmarshal([]byte(validationDetails), &details)
+	if err != nil {
+		return err
+	}
+
+	// Get current timestamp
+	txTimestamp, err := ctx.GetStub().GetTxTimestamp()
+	if err != nil {
+		return fmt.Errorf("failed to get transaction timestamp: %v", err)
+	}
+	timestamp := time.Unix(txTimestamp.Seconds, 0).Format(time.RFC3339)
+
+	// Record the verdict on each document. Only the latest active version of a document
+	// is validated; superseded and withdrawn ones stay as they were.
+	for _, validation := range details.Validations {
+		doc := caseObj.Document(validation.DocumentID)
+		if doc == nil {
+			return fmt.Errorf("document not found: %s", validation.DocumentID)
+		}
+		if err := casemodel.RecordVerdict(doc, validation.Verdict, validation.ReasonCode, validation.Comments); err != nil {
+			return err
+		}
+		if validation.CureBy != "" && validation.Verdict != casemodel.VerdictNeedsCorrection {
+			return fmt.Errorf("document %s is %s, only documents that need correction have a cure deadline", doc.ID, validation.Verdict)
+		}
+		if validation.Verdict != casemodel.VerdictAccepted {
+			if validation.Signature != "" {
+				return fmt.Errorf("document %s is %s, only accepted documents are signed", doc.ID, validation.Verdict)
+			}
+			if validation.Verdict == casemodel.VerdictNeedsCorrection {
+				if _, err := casemodel.RaiseDefect(&caseObj, doc, validation.CureBy, timestamp); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		// The signature is only added to the history if it verifies over the content hash
+		newSignature := DocumentSignature{
+			SignatureHash:   validation.Signature,
+			StampReporterID: details.StampReporterID,
+			Timestamp:       timestamp,
+			Comments:        validation.Comments,
+		}
+		if err := casemodel.SignDocument(ctx, doc, newSignature, validation.KeyFingerprint); err != nil {
+			return err
+		}
+	}
+
+	// The case status follows from the verdicts on its active documents
+	status, err := casemodel.ReviewStatus(&caseObj)
+	if err != nil {
+		return err
+	}
+	if err := casemodel.ApplyTransition(ctx, &caseObj, status); err != nil {
+		return err
+	}
+
+	// Add to history, naming the documents the lawyer has to correct
+	historyComment := details.Comments
+	if status != casemodel.StatusValidatedByStampReporter {
+		historyComment = fmt.Sprintf("Documents returned: %s", casemodel.RejectionSummary(&caseObj))
+		if details.RejectionReason != "" {
+			historyComment = fmt.Sprintf("Rejected: %s. %s", details.RejectionReason, historyComment)
+		}
+	}
+
+	caseObj.History = append(caseObj.History, HistoryItem{
+		Status:       caseObj.Status,
+		Organization: "StampReportersOrg",
+		Timestamp:    timestamp,
+		Comments:     fmt.Sprintf("%s (by Stamp Reporter %s)", historyComment, details.StampReporterID),
+	})
+
+	// Save updated case
+	caseObj.LastModified = timestamp
+	if err := casemodel.Seal(ctx, &caseObj); err != nil {
+		return err
+	}
+	caseJSON, err = json.Marshal(caseObj)
+	if err != nil {
+		return err
+	}
+
+	// Store the case in StampReporter's ledger
+	err = ctx.GetStub().PutState(caseID, caseJSON)
+	if err != nil {
+		log.Printf("Failed to store validated case in StampReporter's ledger: %v", err)
+		return err
+	}
+	log.Printf("Successfully stored validated case %s in StampReporter's ledger", caseID)
+
+	return nil
+}
+
+// pendingCasesQuery selects cases pending stamp reporter validation
+func pendingCasesQuery() string {
+	return query.New(query.And(
+		query.Eq("status", casemodel.StatusPendingStampReporterReview),
+		query.Eq("currentOrg", casemodel.OrgStampReporters),
+	)).String()
+}
+
+// GetPendingCases retrieves cases pending stamp reporter validation
+func (s *StampReporterContract) GetPendingCases(ctx contractapi.TransactionContextInterface) ([]*Case, error) {
+	log.Printf("GetPendingCases called")
+
+	queryString := pendingCasesQuery()
+
+	log.Printf("Executing query: %s", queryString)
+	resultsIterator, err := ctx.GetStub().GetQueryResult(queryString)
+	if err != nil {
+		log.Printf("Query failed: %v", err)
+		return nil, err
+	}
+	defer resultsIterator.Close()
+
+	var cases []*Case
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			log.Printf("Error reading next result: %v", err)
+			return nil, err
+		}
+
+		var caseObj Case
+		err = json.Unmarshal(queryResponse.Value, &caseObj)
+		if err != nil {
+			log.Printf("Failed to unmarshal case: %v", err)
+			return nil, err
+		}
+
+		// Initialize empty arrays if they are null
+		if caseObj.Documents == nil {
+			caseObj.Documents = make([]Document, 0)
+		}
+		if caseObj.History == nil {
+			caseObj.History = make([]HistoryItem, 0)
+		}
+		if caseObj.AssociatedLawyers == nil {
+			caseObj.AssociatedLawyers = make([]string, 0)
+		}
+
+		cases = append(cases, &caseObj)
+	}
+	return access.RedactSealed(ctx, cases)
+}
+
+// GetPendingCasesWithPagination retrieves one page of the cases pending stamp reporter validation
+func (s *StampReporterContract) GetPendingCasesWithPagination(ctx contractapi.TransactionContextInterface, pageSize int32, bookmark string) (*CasePage, error) {
+	log.Printf("GetPendingCasesWithPagination called with page size %d", pageSize)
+	page, err := casemodel.QueryCasesWithPagination(ctx, pendingCasesQuery(), pageSize, bookmark)
+	if err != nil {
+		return nil, err
+	}
+	return access.RedactSealedPage(ctx, page)
+}
+
+// GetCaseById retrieves a specific case by its ID
+func (s *StampReporterContract) GetCaseById(ctx contractapi.TransactionContextInterface, caseID string) (*Case, error) {
+	log.Printf("GetCaseById called with ID: %s", caseID)
+
+	// Get the case locally first
+	caseJSON, err := ctx.GetStub().GetState(caseID)
+	if err != nil {
+		log.Printf("Failed to read case: %v", err)
+		return nil, fmt.Errorf("failed to read case: %v", err)
+	}
+
+	// If case doesn't exist locally, try to fetch it from registrar-stampreporter-channel
+	if caseJSON == nil {
+		log.Printf("Case %s not found locally, attempting to fetch from registrar-stampreporter-channel", caseID)
+		return s.FetchAndStoreCaseFromRegistrarChannel(ctx, caseID)
+	}
+
+	var caseObj Case
+	err = json.Unmarshal(caseJSON, &caseObj)
+	if err != nil {
+		log.Printf("Failed to unmarshal case: %v", err)
+		return nil, fmt.Errorf("failed to unmarshal case: %v", err)
+	}
+
+	// Initialize empty arrays if they are null
+	if caseObj.Documents == nil {
+		caseObj.Documents = make([]Document, 0)
+	}
+	if caseObj.History == nil {
+		caseObj.History = make([]HistoryItem, 0)
+	}
+	if caseObj.AssociatedLawyers == nil {
+		caseObj.AssociatedLawyers = make([]string, 0)
+	}
+
+	if err := access.RequireCaseView(ctx, &caseObj); err != nil {
+		return nil, err
+	}
+	log.Printf("Successfully retrieved case with ID: %s", caseID)
+	return &caseObj, nil
+}
+
+// QueryStats gets statistics for stamp reporter dashboard
+func (s *StampReporterContract) QueryStats(ctx contractapi.TransactionContextInterface) (string, error) {
+	log.Printf("QueryStats called")
+
+	stats := struct {
+		PendingCases   int `json:"pendingCases"`
+		ValidatedCases int `json:"validatedCases"`
+		RejectedCases  int `json:"rejectedCases"`
+	}{}
+
+	// Count pending cases
+	pendingIterator, err := ctx.GetStub().GetQueryResult(query.New(query.And(
+		query.Eq("status", casemodel.StatusPendingStampReporterReview),
+		query.Eq("currentOrg", casemodel.OrgStampReporters),
+	)).String())
+	if err == nil {
+		for pendingIterator.HasNext() {
+			stats.PendingCases++
+			_, _ = pendingIterator.Next()
+		}
+		pendingIterator.Close()
+	}
+
+	// Count validated cases
+	validatedIterator, err := ctx.GetStub().GetQueryResult(query.New(query.And(
+		query.Eq("status", casemodel.StatusValidatedByStampReporter),
+		query.Eq("currentOrg", casemodel.OrgBenchClerks),
+	)).String())
+	if err == nil {
+		for validatedIterator.HasNext() {
+			stats.ValidatedCases++
+			_, _ = validatedIterator.Next()
+		}
+		validatedIterator.Close()
+	}
+
+	// Count rejected cases
+	rejectedIterator, err := ctx.GetStub().GetQueryResult(query.New(query.And(
+		query.Eq("status", casemodel.StatusRejectedByStampReporter),
+		query.Eq("currentOrg", casemodel.OrgLawyers),
+	)).String())
+	if err == nil {
+		for rejectedIterator.HasNext() {
+			stats.RejectedCases++
+			_, _ = rejectedIterator.Next()
+		}
+		rejectedIterator.Close()
+	}
+
+	// Convert stats to JSON
+	statsJSON, err := json.Marshal(stats)
+	if err != nil {
+		log.Printf("Failed to marshal stats: %v", err)
+		return "", fmt.Errorf("failed to marshal stats: %v", err)
+	}
+
+	log.Printf("Statistics: %s", string(statsJSON))
+	return string(statsJSON), nil
+}
+
+// ForwardCaseToBenchClerk fetches a case from registrar-stampreporter-channel and forwards it to BenchClerk's channel
+func (s *StampReporterContract) ForwardCaseToBenchClerk(ctx contractapi.TransactionContextInterface, caseID string) error {
+	log.Printf("ForwardCaseToBenchClerk called for case ID: %s", caseID)
+
+	// Try to get the case from Stamp Reporter's ledger first
+	caseJSON, err := ctx.GetStub().GetState(caseID)
+	if err != nil {
+		return fmt.Errorf("failed to read case: %v", err)
+	}
+
+	var caseObj Case
+	if caseJSON == nil {
+		// Case doesn't exist locally, try to fetch it from registrar-stampreporter-channel
+		log.Printf("Case %s not found locally, attempting to fetch from registrar-stampreporter-channel", caseID)
+		fetchedCase, err := s.FetchAndStoreCaseFromRegistrarChannel(ctx, caseID)
+		if err != nil {
+			return fmt.Errorf("failed to fetch case from registrar-stampreporter-channel: %v", err)
+		}
+		caseObj = *fetchedCase
+	} else {
+		// Case exists locally, unmarshal it
+		err = json.Unmarshal(caseJSON, &caseObj)
+		if err != nil {
+			return fmt.Errorf("failed to unmarshal case data: %v", err)
+		}
+	}
+
+	// Verify case status - only forward cases that have been validated
+	if err := casemodel.ApplyTransition(ctx, &caseObj, casemodel.StatusForwardedToBenchClerk); err != nil {
+		return err
+	}
+
+	// The court fee and stamp duty must be paid in full before the case is listed
+	if err := casemodel.RequireFeesPaid(ctx, &caseObj); err != nil {
+		return err
+	}
+
+	// Get current timestamp
+	txTimestamp, err := ctx.GetStub().GetTxTimestamp()
+	if err != nil {
+		return fmt.Errorf("failed to get transaction timestamp: %v", err)
+	}
+	timestamp := time.Unix(txTimestamp.Seconds, 0).Format(time.RFC3339)
+
+	caseObj.LastModified = timestamp
+
+	// Add history item
+	caseObj.History = append(caseObj.History, HistoryItem{
+		Status:       "FORWARDED_TO_BENCHCLERK",
+		Organization: "StampReportersOrg",
+		Timestamp:    timestamp,
+		Comments:     "Case validated and forwarded to BenchClerk",
+	})
+
+	if err := casemodel.Seal(ctx, &caseObj); err != nil {
+		return err
+	}
+
+	// Marshal the updated case data
+	updatedCaseJSON, err := json.Marshal(caseObj)
+	if err != nil {
+		return fmt.Errorf("failed to marshal updated case data: %v", err)
+	}
+
+	// Update case in Stamp Reporter's ledger
+	err = ctx.GetStub().PutState(caseID, updatedCaseJSON)
+	if err != nil {
+		return fmt.Errorf("failed to update case in Stamp Reporter's ledger: %v", err)
+	}
+
+	// Hand the case to BenchClerk through the outbox. If BenchClerk cannot store it now, the
+	// transfer stays pending until BenchClerk claims it or it is retried.
+	transfer, err := casemodel.Handoff(ctx, casemodel.HopStampReporterToBenchClerk, "StoreCase", &caseObj)
+	if err != nil {
+		return err
+	}
+	if transfer.Status == casemodel.TransferPending {
+		log.Printf("Case %s is pending in transfer %d to BenchClerk: %s", caseID, transfer.Sequence, transfer.LastError)
+		return nil
+	}
+
+	log.Printf("Successfully forwarded case %s to BenchClerk", caseID)
+	return nil
+}
+
+// ForwardCaseToLawyer forwards a rejected or on-hold case from StampReporter to Lawyer
+func (s *StampReporterContract) ForwardCaseToLawyer(ctx contractapi.TransactionContextInterface, caseID string) error {
+	log.Printf("ForwardCaseToLawyer called for case ID: %s", caseID)
+
+	// Try to get the case from Stamp Reporter's ledger first
+	caseJSON, err := ctx.GetStub().GetState(caseID)
+	if err != nil {
+		return fmt.Errorf("failed to read case: %v", err)
+	}
+
+	var caseObj Case
+	if caseJSON == nil {
+		// Case doesn't exist locally, try to fetch it from registrar-stampreporter-channel
+		log.Printf("Case %s not found locally, attempting to fetch from registrar-stampreporter-channel", caseID)
+		fetchedCase, err := s.FetchAndStoreCaseFromRegistrarChannel(ctx, caseID)
+		if err != nil {
+			return fmt.Errorf("failed to fetch case from registrar-stampreporter-channel: %v", err)
+		}
+		caseObj = *fetchedCase
+	} else {
+		// Case exists locally, unmarshal it
+		err = json.Unmarshal(caseJSON, &caseObj)
+		if err != nil {
+			return fmt.Errorf("failed to unmarshal case data: %v", err)
+		}
+	}
+
+	// Only cases that have been rejected or put on hold can be forwarded to the lawyer
+	nextStatus := casemodel.StatusForwardedToLawyerRejected
+	if caseObj.Status == casemodel.StatusOnHoldByStampReporter {
+		nextStatus = casemodel.StatusForwardedToLawyerOnHold
+	}
+	if err := casemodel.ApplyTransition(ctx, &caseObj, nextStatus); err != nil {
+		return err
+	}
+
+	// Get current timestamp
+	txTimestamp, err := ctx.GetStub().GetTxTimestamp()
+	if err != nil {
+		return fmt.Errorf("failed to get transaction timestamp: %v", err)
+	}
+	timestamp := time.Unix(txTimestamp.Seconds, 0).Format(time.RFC3339)
+
+	caseObj.LastModified = timestamp
+
+	// Add history item
+	caseObj.History = append(caseObj.History, HistoryItem{
+		Status:       caseObj.Status,
+		Organization: "StampReportersOrg",
+		Timestamp:    timestamp,
+		Comments:     fmt.Sprintf("Case %s and forwarded to Lawyer", caseObj.Status),
+	})
+
+	if err := casemodel.Seal(ctx, &caseObj); err != nil {
+		return err
+	}
+
+	// Marshal the updated case data
+	updatedCaseJSON, err := json.Marshal(caseObj)
+	if err != nil {
+		return fmt.Errorf("failed to marshal updated case data: %v", err)
+	}
+
+	// Update case in Stamp Reporter's ledger
+	err = ctx.GetStub().PutState(caseID, updatedCaseJSON)
+	if err != nil {
+		return fmt.Errorf("failed to update case in Stamp Reporter's ledger: %v", err)
+	}
+
+	// Hand the case to Lawyer through the outbox. If Lawyer cannot store it now, the
+	// transfer stays pending until Lawyer claims it or it is retried.
+	transfer, err := casemodel.Handoff(ctx, casemodel.HopStampReporterToLawyer, "StoreCase", &caseObj)
+	if err != nil {
+		return err
+	}
+	if transfer.Status == casemodel.TransferPending {
+		log.Printf("Case %s is pending in transfer %d to Lawyer: %s", caseID, transfer.Sequence, transfer.LastError)
+		return nil
+	}
+
+	log.Printf("Successfully forwarded case %s to Lawyer", caseID)
+	return nil
+}
+
+// GetRejectedCases returns all rejected cases to be forwarded to Lawyer
+func (s *StampReporterContract) GetRejectedCases(ctx contractapi.TransactionContextInterface) ([]*Case, error) {
+	log.Printf("GetRejectedCases called")
+
+	// Query all rejected cases that should be forwarded to Lawyer
+	queryString := query.New(query.And(
+		query.Eq("status", casemodel.StatusRejectedByStampReporter),
+		query.Eq("currentOrg", casemodel.OrgLawyers),
+	)).String()
+
+	// Execute the query
+	resultsIterator, err := ctx.GetStub().GetQueryResult(queryString)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get query result: %v", err)
+	}
+	defer resultsIterator.Close()
+
+	rejectedCases := make([]*Case, 0)
+	for resultsIterator.HasNext() {
+		queryResult, err := resultsIterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get the next query result: %v", err)
+		}
+
+		var caseObj Case
+		err = json.Unmarshal(queryResult.Value, &caseObj)
+		if err != nil {
+			return nil, fmt.Errorf("failed to unmarshal case: %v", err)
+		}
+
+		rejectedCases = append(rejectedCases, &caseObj)
+		log.Printf("Found rejected case %s", caseObj.ID)
+	}
+
+	log.Printf("Returning %d rejected cases", len(rejectedCases))
+	return access.RedactSealed(ctx, rejectedCases)
+}
+
+// GetOnHoldCases returns all on-hold cases to be forwarded to Lawyer
+func (s *StampReporterContract) GetOnHoldCases(ctx contractapi.TransactionContextInterface) ([]*Case, error) {
+	log.Printf("GetOnHoldCases called")
+
+	// Query all on-hold cases that should be forwarded to Lawyer
+	queryString := query.New(query.And(
+		query.Eq("status", casemodel.StatusOnHoldByStampReporter),
+		query.Eq("currentOrg", casemodel.OrgLawyers),
+	)).String()
+
+	// Execute the query
+	resultsIterator, err := ctx.GetStub().GetQueryResult(queryString)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get query result: %v", err)
+	}
+	defer resultsIterator.Close()
+
+	onHoldCases := make([]*Case, 0)
+	for resultsIterator.HasNext() {
+		queryResult, err := resultsIterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get the next query result: %v", err)
+		}
+
+		var caseObj Case
+		err = json.Unmarshal(queryResult.Value, &caseObj)
+		if err != nil {
+			return nil, fmt.Errorf("failed to unmarshal case: %v", err)
+		}
+
+		onHoldCases = append(onHoldCases, &caseObj)
+		log.Printf("Found on-hold case %s", caseObj.ID)
+	}
+
+	log.Printf("Returning %d on-hold cases", len(onHoldCases))
+	return access.RedactSealed(ctx, onHoldCases)
+}
+
+// StoreCase stores a case submitted from another organization's chaincode
+func (s *StampReporterContract) StoreCase(ctx contractapi.TransactionContextInterface, caseJSON string) error {
+	log.Printf("StoreCase called with payload length: %d bytes", len(caseJSON))
+
+	// Parse case data
+	newCase, err := casemodel.DecodeCase([]byte(caseJSON))
+	if err != nil {
+		log.Printf("Failed to decode case data: %v", err)
+		return err
+	}
+
+	log.Printf("Successfully parsed case with ID: %s, Title: %s", newCase.ID, newCase.Title)
+
+	// Verify required fields
+	if newCase.ID == "" {
+		log.Printf("Case ID is required")
+		return fmt.Errorf("case ID is required")
+	}
+
+	// Only store the case as the registrar assigned it to the stamp reporters
+	if err := casemodel.VerifyReceived(ctx, newCase, casemodel.OrgStampReporters, casemodel.OrgRegistrars); err != nil {
+		log.Printf("Case %s failed verification: %v", newCase.ID, err)
+		return err
+	}
+
+	if err := casemodel.Seal(ctx, newCase); err != nil {
+		return err
+	}
+
+	// Store the case in the ledger
+	updatedCaseJSON, err := json.Marshal(newCase)
+	if err != nil {
+		log.Printf("Failed to marshal updated case: %v", err)
+		return err
+	}
+
+	err = ctx.GetStub().PutState(newCase.ID, updatedCaseJSON)
+	if err != nil {
+		log.Printf("Failed to save case: %v", err)
+		return err
+	}
+
+	log.Printf("Successfully stored case with ID: %s", newCase.ID)
+	return nil
+}
+
+// ReceiveCuredCase stores an on-hold case the lawyer returns for review with its defects
+// cured. Every defect the stamp reporter left open must be cured on the case received,
+// and the stamp reporter's copy takes nothing else from it, see casemodel.ApplyCures.
+func (s *StampReporterContract) ReceiveCuredCase(ctx contractapi.TransactionContextInterface, caseJSON string) error {
+	newCase, stored, err := s.returnedCase(ctx, caseJSON, "only on-hold cases are returned with defects cured",
+		casemodel.StatusOnHoldByStampReporter, casemodel.StatusForwardedToLawyerOnHold)
+	if err != nil {
+		return err
+	}
+	newCase, cured, err := casemodel.ApplyCures(stored, newCase)
+	if err != nil {
+		return err
+	}
+
+	txTime, err := casemodel.TxTime(ctx)
+	if err != nil {
+		return err
+	}
+	newCase.History = append(newCase.History, HistoryItem{
+		Status:       "RECEIVED_FROM_LAWYER",
+		Organization: "StampReportersOrg",
+		Timestamp:    txTime.Format(time.RFC3339),
+		Comments:     fmt.Sprintf("Case returned for review with defects cured: %s", strings.Join(cured, ", ")),
+	})
+	if err := casemodel.Seal(ctx, newCase); err != nil {
+		return err
+	}
+	updatedCaseJSON, err := json.Marshal(newCase)
+	if err != nil {
+		return fmt.Errorf("failed to marshal case: %v", err)
+	}
+	if err := ctx.GetStub().PutState(newCase.ID, updatedCaseJSON); err != nil {
+		return fmt.Errorf("failed to store case: %v", err)
+	}
+
+	log.Printf("Received case %s with defects cured", newCase.ID)
+	return nil
+}
+
+// ReceiveResubmittedCase stores a rejected case the lawyer resubmits for review with its
+// rejected documents replaced. The stamp reporter's copy takes only the new versions of
+// those documents from it, see casemodel.ApplyReplacements.
+func (s *StampReporterContract) ReceiveResubmittedCase(ctx contractapi.TransactionContextInterface, caseJSON string) error {
+	newCase, stored, err := s.returnedCase(ctx, caseJSON, "only rejected cases are resubmitted",
+		casemodel.StatusRejectedByStampReporter, casemodel.StatusForwardedToLawyerRejected)
+	if err != nil {
+		return err
+	}
+	newCase, replaced, err := casemodel.ApplyReplacements(stored, newCase)
+	if err != nil {
+		return err
+	}
+
+	txTime, err := casemodel.TxTime(ctx)
+	if err != nil {
+		return err
+	}
+	newCase.History = append(newCase.History, HistoryItem{
+		Status:       "RECEIVED_FROM_LAWYER",
+		Organization: "StampReportersOrg",
+		Timestamp:    txTime.Format(time.RFC3339),
+		Comments:     fmt.Sprintf("Case resubmitted for review with rejected documents replaced: %s", strings.Join(replaced, ", ")),
+	})
+	if err := casemodel.Seal(ctx, newCase); err != nil {
+		return err
+	}
+	updatedCaseJSON, err := json.Marshal(newCase)
+	if err != nil {
+		return fmt.Errorf("failed to marshal case: %v", err)
+	}
+	if err := ctx.GetStub().PutState(newCase.ID, updatedCaseJSON); err != nil {
+		return fmt.Errorf("failed to store case: %v", err)
+	}
+
+	log.Printf("Received resubmitted case %s", newCase.ID)
+	return nil
+}
+
+// returnedCase decodes a case the lawyer returns for review and reads the stamp reporter's
+// copy of it. Only a case this stamp reporter left in one of statuses can come back, and
+// only from a lawyer on it; notReturned explains the error otherwise.
+func (s *StampReporterContract) returnedCase(ctx contractapi.TransactionContextInterface, caseJSON string, notReturned string, statuses ...string) (*Case, *Case, error) {
+	newCase, err := casemodel.DecodeCase([]byte(caseJSON))
+	if err != nil {
+		return nil, nil, err
+	}
+	if newCase.Status != casemodel.StatusPendingStampReporterReview || newCase.CurrentOrg != casemodel.OrgStampReporters {
+		return nil, nil, fmt.Errorf("invalid case status or organization: status=%s, org=%s", newCase.Status, newCase.CurrentOrg)
+	}
+
+	storedJSON, err := ctx.GetStub().GetState(newCase.ID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read case: %v", err)
+	}
+	if storedJSON == nil {
+		return nil, nil, fmt.Errorf("case does not exist: %s", newCase.ID)
+	}
+	stored, err := casemodel.DecodeCase(storedJSON)
+	if err != nil {
+		return nil, nil, err
+	}
+	returnable := false
+	for _, status := range statuses {
+		returnable = returnable || stored.Status == status
+	}
+	if !returnable {
+		return nil, nil, fmt.Errorf("case %s is %s, %s", newCase.ID, stored.Status, notReturned)
+	}
+	if err := casemodel.VerifyReceived(ctx, newCase, casemodel.OrgStampReporters, casemodel.OrgLawyers); err != nil {
+		return nil, nil, err
+	}
+	caller, err := access.GetCaller(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	if caller.Role == access.RoleLawyer {
+		if err := access.RequireCaseLawyer(ctx, stored); err != nil {
+			return nil, nil, err
+		}
+	}
+	return newCase, stored, nil
+}
+
+// FetchAndStoreCaseFromRegistrarChannel fetches a case from registrar-stampreporter-channel if it doesn't exist locally
+func (s *StampReporterContract) FetchAndStoreCaseFromRegistrarChannel(ctx contractapi.TransactionContextInterface, caseID string) (*Case, error) {
+	log.Printf("FetchAndStoreCaseFromRegistrarChannel called for case ID: %s", caseID)
+
+	// Check if case exists locally first
+	localCase, err := ctx.GetStub().GetState(caseID)
+	if err == nil && localCase != nil {
+		log.Printf("Case %s exists locally, no need to fetch from registrar channel", caseID)
+		var caseObj Case
+		err = json.Unmarshal(localCase, &caseObj)
+		if err != nil {
+			return nil, fmt.Errorf("failed to unmarshal local case: %v", err)
+		}
+		return &caseObj, nil
+	}
+
+	log.Printf("Case %s not found locally, fetching from registrar-stampreporter-channel", caseID)
+
+	// Invoke the GetCaseById function in the registrar chaincode
+	args := [][]byte{[]byte("GetCaseById"), []byte(caseID)}
+	response := casemodel.InvokeHop(ctx, casemodel.HopStampReporterToRegistrar, args)
+
+	// Check the response status
+	if response.Status != 200 {
+		errMsg := fmt.Sprintf("Failed to fetch case from registrar channel: %s", string(response.Message))
+		log.Printf(errMsg)
+		return nil, fmt.Errorf(errMsg)
+	}
+
+	// Check if payload is empty
+	if response.Payload == nil || len(response.Payload) == 0 {
+		errMsg := fmt.Sprintf("Case data not found on registrar-stampreporter-channel for ID: %s", caseID)
+		log.Printf(errMsg)
+		return nil, fmt.Errorf(errMsg)
+	}
+
+	log.Printf("Successfully fetched case from registrar-stampreporter-channel, response length: %d bytes", len(response.Payload))
+
+	// Parse the response
+	caseObj, err := casemodel.DecodeCase(response.Payload)
+	if err != nil {
+		log.Printf("Failed to decode case data: %v", err)
+		return nil, err
+	}
+
+	// Only store the case in the state the registrar handed it over in
+	if err := casemodel.VerifyHandoff(ctx, caseObj, casemodel.OrgRegistrars); err != nil {
+		log.Printf("Case %s failed verification: %v", caseObj.ID, err)
+		return nil, err
+	}
+
+	log.Printf("Successfully parsed case with ID: %s, Title: %s", caseObj.ID, caseObj.Title)
+	// Ensure the case belongs to StampReportersOrg (fix the organization if needed)
+	if caseObj.CurrentOrg != "StampReportersOrg" {
+		log.Printf("Changing case organization from %s to StampReportersOrg", caseObj.CurrentOrg)
+		caseObj.CurrentOrg = "StampReportersOrg"
+
+		// Get current timestamp
+		txTimestamp, err := ctx.GetStub().GetTxTimestamp()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get transaction timestamp: %v", err)
+		}
+		timestamp := time.Unix(txTimestamp.Seconds, 0).Format(time.RFC3339)
+
+		// Add history item for the cross-channel transfer
+		caseObj.History = append(caseObj.History, HistoryItem{
+			Status:       "TRANSFERRED_TO_STAMPREPORTER",
+			Organization: "StampReportersOrg",
+			Timestamp:    timestamp,
+			Comments:     "Case transferred from registrar-stampreporter-channel to stampreporter-benchclerk-channel",
+		})
+
+		caseObj.LastModified = timestamp
+	}
+
+	if err := casemodel.Seal(ctx, caseObj); err != nil {
+		return nil, err
+	}
+
+	// Store the case locally
+	caseJSON, err := json.Marshal(caseObj)
+	if err != nil {
+		log.Printf("Failed to marshal case: %v", err)
+		return nil, fmt.Errorf("failed to marshal case: %v", err)
+	}
+
+	// Store the case in Stamp Reporter's ledger
+	err = ctx.GetStub().PutState(caseID, caseJSON)
+	if err != nil {
+		log.Printf("Failed to store case: %v", err)
+		return nil, fmt.Errorf("failed to store case: %v", err)
+	}
+
+	log.Printf("Successfully stored case %s in Stamp Reporter's ledger", caseID)
+	return caseObj, nil
+}
+
+// SyncCaseAcrossChannels ensures that a case is available in both registrar-stampreporter-channel and stampreporter-benchclerk-channel
+// This helps resolve cross-channel communication issues
+func (s *StampReporterContract) SyncCaseAcrossChannels(ctx contractapi.TransactionContextInterface, caseID string) error {
+	log.Printf("SyncCaseAcrossChannels called for case ID: %s", caseID)
+
+	// Try to get the case from the local ledger
+	caseJSON, err := ctx.GetStub().GetState(caseID)
+	if err != nil {
+		return fmt.Errorf("failed to read case: %v", err)
+	}
+
+	var caseObj Case
+	if caseJSON == nil {
+		// Case doesn't exist locally, try to fetch it from registrar-stampreporter-channel
+		log.Printf("Case %s not found locally, attempting to fetch from registrar-stampreporter-channel", caseID)
+		fetchedCase, err := s.FetchAndStoreCaseFromRegistrarChannel(ctx, caseID)
+		if err != nil {
+			return fmt.Errorf("failed to fetch case from registrar-stampreporter-channel: %v", err)
+		}
+		caseObj = *fetchedCase
+	} else {
+		// Case exists locally, unmarshal it
+		err = json.Unmarshal(caseJSON, &caseObj)
+		if err != nil {
+			return fmt.Errorf("failed to unmarshal case data: %v", err)
+		}
+	}
+
+	// Get current timestamp
+	txTimestamp, err := ctx.GetStub().GetTxTimestamp()
+	if err != nil {
+		return fmt.Errorf("failed to get transaction timestamp: %v", err)
+	}
+	timestamp := time.Unix(txTimestamp.Seconds, 0).Format(time.RFC3339)
+
+	// Add a history item to track this sync operation
+	caseObj.History = append(caseObj.History, HistoryItem{
+		Status:       "CASE_SYNCED",
+		Organization: "StampReportersOrg",
+		Timestamp:    timestamp,
+		Comments:     "Case synchronized between registrar-stampreporter-channel and stampreporter-benchclerk-channel",
+	})
+
+	caseObj.LastModified = timestamp
+
+	if err := casemodel.Seal(ctx, &caseObj); err != nil {
+		return err
+	}
+
+	// Marshal the updated case data
+	updatedCaseJSON, err := json.Marshal(caseObj)
+	if err != nil {
+		return fmt.Errorf("failed to marshal updated case data: %v", err)
+	}
+
+	// Update case in Stamp Reporter's ledger
+	err = ctx.GetStub().PutState(caseID, updatedCaseJSON)
+	if err != nil {
+		return fmt.Errorf("failed to update case in Stamp Reporter's ledger: %v", err)
+	}
+
+	log.Printf("Successfully synchronized case %s across channels", caseID)
+	return nil
+}
+
+// GetAllPendingCasesFromRegistrar fetches all cases from registrar-stampreporter-channel that need attention from StampReporter
+func (s *StampReporterContract) GetAllPendingCasesFromRegistrar(ctx contractapi.TransactionContextInterface) error {
+	log.Printf("GetAllPendingCasesFromRegistrar called")
+
+	// Invoke the registrar chaincode to get all cases pending stamp reporter review
+	args := [][]byte{[]byte("GetCasesForStampReporter")}
+	response := casemodel.InvokeHop(ctx, casemodel.HopStampReporterToRegistrar, args)
+
+	// Check the response status
+	if response.Status != 200 {
+		errMsg := fmt.Sprintf("Failed to fetch cases from registrar channel: %s", string(response.Message))
+		log.Printf(errMsg)
+		return fmt.Errorf(errMsg)
+	}
+
+	// Check if payload is empty
+	if response.Payload == nil || len(response.Payload) == 0 || string(response.Payload) == "[]" {
+		log.Printf("No pending cases found for StampReporter on registrar-stampreporter-channel")
+		return nil
+	}
+
+	// Parse the response which should contain an array of cases
+	var cases []Case
+	err := json.Unmarshal(response.Payload, &cases)
+	if err != nil {
+		return fmt.Errorf("failed to unmarshal cases data: %v", err)
+	}
+
+	log.Printf("Fetched %d cases from registrar-stampreporter-channel", len(cases))
+
+	// Get current timestamp
+	txTimestamp, err := ctx.GetStub().GetTxTimestamp()
+	if err != nil {
+		return fmt.Errorf("failed to get transaction timestamp: %v", err)
+	}
+	timestamp := time.Unix(txTimestamp.Seconds, 0).Format(time.RFC3339)
+
+	// Store each case in the local ledger
+	for _, caseObj := range cases {
+		// Skip cases the registrar did not hand over in the state received
+		if err := casemodel.VerifyHandoff(ctx, &caseObj, casemodel.OrgRegistrars); err != nil {
+			log.Printf("Skipping case %s: %v", caseObj.ID, err)
+			continue
+		}
+		// Ensure the case belongs to StampReportersOrg
+		caseObj.CurrentOrg = "StampReportersOrg"
+
+		// Add history item for the cross-channel transfer if not already added
+		transferFound := false
+		for _, history := range caseObj.History {
+			if history.Status == "TRANSFERRED_TO_STAMPREPORTER" {
+				transferFound = true
+				break
+			}
+		}
+
+		if !transferFound {
+			caseObj.History = append(caseObj.History, HistoryItem{
+				Status:       "TRANSFERRED_TO_STAMPREPORTER",
+				Organization: "StampReportersOrg",
+				Timestamp:    timestamp,
+				Comments:     "Case transferred from registrar-stampreporter-channel to stampreporter-benchclerk-channel",
+			})
+		}
+
+		caseObj.LastModified = timestamp
+
+		if err := casemodel.Seal(ctx, &caseObj); err != nil {
+			log.Printf("Failed to seal case %s: %v", caseObj.ID, err)
+			continue
+		}
+
+		// Store the case locally
+		caseJSON, err := json.Marshal(caseObj)
+		if err != nil {
+			log.Printf("Failed to marshal case %s: %v", caseObj.ID, err)
+			continue
+		}
+
+		// Store the case in Stamp Reporter's ledger
+		err = ctx.GetStub().PutState(caseObj.ID, caseJSON)
+		if err != nil {
+			log.Printf("Failed to store case %s: %v", caseObj.ID, err)
+			continue
+		}
+
+		log.Printf("Successfully stored case %s in Stamp Reporter's ledger", caseObj.ID)
+	}
+
+	return nil
+}
+
+// SetFeeSchedule stores the court fee and stamp duty schedule for a case type, see
+// casemodel.FeeSchedule. Amounts are in paise. Only an administrator may change the fees.
+func (s *StampReporterContract) SetFeeSchedule(ctx contractapi.TransactionContextInterface, schedule string) (*casemodel.FeeSchedule, error) {
+	if err := access.RequireAdmin(ctx, "SetFeeSchedule"); err != nil {
+		return nil, err
+	}
+	return casemodel.PutFeeSchedule(ctx, schedule)
+}
+
+// GetFeeSchedule returns the court fee and stamp duty schedule for a case type
+func (s *StampReporterContract) GetFeeSchedule(ctx contractapi.TransactionContextInterface, caseType string) (*casemodel.FeeSchedule, error) {
+	return casemodel.GetFeeSchedule(ctx, caseType)
+}
+
+// CalculateCourtFee assesses the court fee and stamp duty due on a case from the
+// schedule for its type and claim value, and what is left to pay
+func (s *StampReporterContract) CalculateCourtFee(ctx contractapi.TransactionContextInterface, caseID string) (*casemodel.FeeAssessment, error) {
+	caseObj, err := s.GetCaseById(ctx, caseID)
+	if err != nil {
+		return nil, err
+	}
+	return casemodel.AssessFees(ctx, caseObj)
+}
+
+// RecordFeePayment records a court fee or stamp duty payment of amount paise against a
+// case under review, with the reference of its receipt
+func (s *StampReporterContract) RecordFeePayment(ctx contractapi.TransactionContextInterface, caseID string, receiptRef string, amount int64) (*casemodel.FeeAssessment, error) {
+	caseObj, err := s.GetCaseById(ctx, caseID)
+	if err != nil {
+		return nil, err
+	}
+	if caseObj.Status != casemodel.StatusPendingStampReporterReview && caseObj.Status != casemodel.StatusValidatedByStampReporter {
+		return nil, fmt.Errorf("case %s is %s, fees can only be recorded before it is forwarded to the bench clerk", caseID, caseObj.Status)
+	}
+
+	assessment, err := casemodel.RecordFeePayment(ctx, caseObj, receiptRef, amount)
+	if err != nil {
+		return nil, err
+	}
+
+	txTime, err := casemodel.TxTime(ctx)
+	if err != nil {
+		return nil, err
+	}
+	caseObj.LastModified = txTime.Format(time.RFC3339)
+	if err := casemodel.Seal(ctx, caseObj); err != nil {
+		return nil, err
+	}
+	caseJSON, err := json.Marshal(caseObj)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal case: %v", err)
+	}
+	if err := ctx.GetStub().PutState(caseID, caseJSON); err != nil {
+		return nil, fmt.Errorf("failed to store case: %v", err)
+	}
+	return assessment, nil
+}
+
+// GetCaseDefects lists the defects raised on a case, showing which ones the lawyer has
+// cured and with which corrected document
+func (s *StampReporterContract) GetCaseDefects(ctx contractapi.TransactionContextInterface, caseID string) ([]casemodel.Defect, error) {
+	caseObj, err := s.GetCaseById(ctx, caseID)
+	if err != nil {
+		return nil, err
+	}
+	if caseObj.Defects == nil {
+		return make([]casemodel.Defect, 0), nil
+	}
+	return caseObj.Defects, nil
+}
+
+// GetAllowedTransitions returns the status changes the caller's organization can make on a case right now
+func (s *StampReporterContract) GetAllowedTransitions(ctx contractapi.TransactionContextInterface, caseID string) ([]casemodel.Transition, error) {
+	return casemodel.GetAllowedTransitions(ctx, caseID)
+}
+
+// VerifyCaseHistory recomputes the hash chain of a case's history and reports the first
+// entry that does not verify
+func (s *StampReporterContract) VerifyCaseHistory(ctx contractapi.TransactionContextInterface, caseID string) (*casemodel.HistoryVerification, error) {
+	return casemodel.VerifyCaseHistory(ctx, caseID)
+}
+
+// VerifyDocument checks whether a file with the hex SHA-256 contentHash is the one a
+// case document was uploaded with
+func (s *StampReporterContract) VerifyDocument(ctx contractapi.TransactionContextInterface, caseID string, documentID string, contentHash string) (*casemodel.DocumentVerification, error) {
+	if err := access.RequireStoredCaseView(ctx, caseID); err != nil {
+		return nil, err
+	}
+	return casemodel.VerifyDocument(ctx, caseID, documentID, contentHash)
+}
+
+// VerifyDocumentSignatures rechecks every signature on a case's documents
+func (s *StampReporterContract) VerifyDocumentSignatures(ctx contractapi.TransactionContextInterface, caseID string) (*casemodel.SignatureVerification, error) {
+	if err := access.RequireStoredCaseView(ctx, caseID); err != nil {
+		return nil, err
+	}
+	return casemodel.VerifyDocumentSignatures(ctx, caseID)
+}
+
+// RegisterSigningKey registers a PEM public key the caller can sign documents with in
+// place of the key of their certificate
+func (s *StampReporterContract) RegisterSigningKey(ctx contractapi.TransactionContextInterface, publicKey string) (*casemodel.SigningKey, error) {
+	return casemodel.RegisterSigningKey(ctx, publicKey)
+}
+
+// GetCaseProvenance returns every committed version of a case on this channel with the
+// fields each transaction changed
+func (s *StampReporterContract) GetCaseProvenance(ctx contractapi.TransactionContextInterface, caseID string) (*casemodel.CaseProvenance, error) {
+	if err := access.RequireStoredCaseView(ctx, caseID); err != nil {
+		return nil, err
+	}
+	return casemodel.GetCaseProvenance(ctx, caseID)
+}
+
+// SetRoutingConfig replaces the chaincode and channel of each call this contract makes,
+// as stored by InitLedger. Only an administrator may change the routes.
+func (s *StampReporterContract) SetRoutingConfig(ctx contractapi.TransactionContextInterface, config string) error {
+	if err := access.RequireAdmin(ctx, "SetRoutingConfig"); err != nil {
+		return err
+	}
+	return casemodel.PutRouting(ctx, config, hops...)
+}
+
+// GetRoutingConfig returns the routing table this contract uses
+func (s *StampReporterContract) GetRoutingConfig(ctx contractapi.TransactionContextInterface) (*casemodel.RoutingConfig, error) {
+	return casemodel.GetRouting(ctx)
+}
+
+// SetOrgRoots replaces the root CA certificates trusted for an organization on this
+// channel, which signer certificates are checked against. Only an administrator of that
+// organization may change them.
+func (s *StampReporterContract) SetOrgRoots(ctx contractapi.TransactionContextInterface, org string, certificates string) error {
+	if err := access.RequireAdmin(ctx, "SetOrgRoots"); err != nil {
+		return err
+	}
+	return casemodel.PutOrgRoots(ctx, org, certificates)
+}
+
+// ImportOrgRoots copies the root CA certificates trusted for an organization that has not
+// joined this channel from the chaincode routed for hop, where that organization's
+// administrator stored them. Only an administrator may import them.
+func (s *StampReporterContract) ImportOrgRoots(ctx contractapi.TransactionContextInterface, org string, hop string) error {
+	if err := access.RequireAdmin(ctx, "ImportOrgRoots"); err != nil {
+		return err
+	}
+	return casemodel.ImportOrgRoots(ctx, org, hop)
+}
+
+// GetOrgRoots returns the PEM root CA certificates trusted for an organization on this
+// channel, or an empty string when none are
+func (s *StampReporterContract) GetOrgRoots(ctx contractapi.TransactionContextInterface, org string) (string, error) {
+	return casemodel.GetOrgRoots(ctx, org)
+}
+
+// ListPendingTransfers returns the cases handed to other organizations that they have
+// not claimed yet, with the sealed cases the caller may not see redacted
+func (s *StampReporterContract) ListPendingTransfers(ctx contractapi.TransactionContextInterface) ([]*casemodel.Transfer, error) {
+	transfers, err := casemodel.ListPendingTransfers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return access.RedactSealedTransfers(ctx, transfers)
+}
+
+// ClaimTransfer acknowledges a pending transfer for the organization receiving the case
+// and returns it
+func (s *StampReporterContract) ClaimTransfer(ctx contractapi.TransactionContextInterface, sequence uint64) (*casemodel.Transfer, error) {
+	return casemodel.ClaimTransfer(ctx, sequence)
+}
+
+// RetryTransfer delivers a pending transfer again
+func (s *StampReporterContract) RetryTransfer(ctx contractapi.TransactionContextInterface, sequence uint64) (*casemodel.Transfer, error) {
+	t, err := casemodel.RetryTransfer(ctx, sequence)
+	if err != nil {
+		return nil, err
+	}
+	transfers, err := access.RedactSealedTransfers(ctx, []*casemodel.Transfer{t})
+	if err != nil {
+		return nil, err
+	}
+	return transfers[0], nil
+}
+
+// ReceiveTransfers claims the cases the lawyer returned with defects cured or resubmitted
+// after a rejection on this channel that were not delivered when they were sent, and
+// stores them. It returns the IDs of the cases received.
+func (s *StampReporterContract) ReceiveTransfers(ctx contractapi.TransactionContextInterface) ([]string, error) {
+	store := func(caseJSON string) error {
+		// The stamp reporter's copy tells whether the case was rejected or put on hold
+		returned, err := casemodel.DecodeCase([]byte(caseJSON))
+		if err != nil {
+			return err
+		}
+		storedJSON, err := ctx.GetStub().GetState(returned.ID)
+		if err != nil {
+			return fmt.Errorf("failed to read case: %v", err)
+		}
+		if stored, err := casemodel.DecodeCase(storedJSON); err == nil && (stored.Status == casemodel.StatusRejectedByStampReporter || stored.Status == casemodel.StatusForwardedToLawyerRejected) {
+			return s.ReceiveResubmittedCase(ctx, caseJSON)
+		}
+		return s.ReceiveCuredCase(ctx, caseJSON)
+	}
+	return casemodel.ReceiveTransfers(ctx, store, casemodel.HopStampReporterToLawyer)
+}
+
+// New returns the stamp reporter contract with its access policy applied and the status changes
+// of each transaction emitted together once it succeeds
+func New() *StampReporterContract {
+	contract := new(StampReporterContract)
+	contract.BeforeTransaction = accessPolicy.BeforeTransaction
+	contract.TransactionContextHandler = new(casemodel.TransactionContext)
+	contract.AfterTransaction = casemodel.EmitStatusChanges
+	return contract
+}
@@ -0,0 +1,1478 @@
+package chaincode
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/hyperledger/fabric-protos-go/peer"
+
+	"casemodel"
+	"casemodel/mockstub"
+	"casemodel/mockstub/contracttest"
+)
+
+const channel = "registrar-stampreporter-channel"
+
+var start = time.Date(2024, time.May, 15, 10, 0, 0, 0, time.UTC)
+
+// stamp is how the contract formats the timestamp of the first transaction
+var stamp = time.Unix(start.Unix(), 0).Format(time.RFC3339)
+
+// plaintHash is the content hash of the plaint filed in the tests
+var plaintHash = casemodel.ContentHash([]byte("%PDF-1.4 plaint"))
+
+// civilFees charges civil cases 1% of claims up to ₹1,00,000 and ₹1,000 plus 0.5% of the
+// rest above that, with ₹50 stamp duty. CASE_002's ₹50,000 claim owes ₹550.
+const civilFees = `{"caseType":"Civil","slabs":[{"upTo":10000000,"fixed":0,"rate":100},{"upTo":0,"fixed":100000,"rate":50}],"stampDuty":5000}`
+
+// Keys the stamp reporter signs documents with: the one its certificate is issued for and
+// one it registers. Nobody registered otherKey.
+var (
+	stampReporterKey, _                  = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	otherKey, _                          = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	registeredPublic, registeredKey, _   = ed25519.GenerateKey(rand.Reader)
+	registeredPEM, registeredFingerprint = registeredKeyPEM()
+)
+
+// The stamp reporter's signatures over the plaint's content hash with each of its keys
+var (
+	plaintSignature = signECDSA(stampReporterKey, plaintHash)
+	plaintEd25519   = signEd25519(registeredKey, plaintHash)
+)
+
+var (
+	lawyer             = mockstub.Identity{MSPID: "LawyersOrgMSP", Name: "lawyer1", Attrs: map[string]string{"role": "lawyer", "lawyerId": "L001"}}
+	lawyerAdmin        = mockstub.Identity{MSPID: "LawyersOrgMSP", Name: "lawyeradmin", Attrs: map[string]string{"role": "lawyer", "lawyerId": "L000", "admin": "true"}}
+	registrar          = mockstub.Identity{MSPID: "RegistrarsOrgMSP", Name: "registrar1", Attrs: map[string]string{"role": "registrar"}}
+	stampReporter      = mockstub.Identity{MSPID: "StampReportersOrgMSP", Name: "stampreporter1", Attrs: map[string]string{"role": "stampreporter"}, Key: stampReporterKey}
+	stampReporterAdmin = mockstub.Identity{MSPID: "StampReportersOrgMSP", Name: "stampreporteradmin", Attrs: map[string]string{"role": "stampreporter", "admin": "true"}}
+	benchClerk         = mockstub.Identity{MSPID: "BenchClerksOrgMSP", Name: "benchclerk1", Attrs: map[string]string{"role": "benchclerk"}}
+)
+
+// signECDSA returns a base64 signature over the digest a document's anchor encodes
+func signECDSA(key *ecdsa.PrivateKey, anchor string) string {
+	digest, _ := hex.DecodeString(anchor)
+	sig, err := ecdsa.SignASN1(rand.Reader, key, digest)
+	if err != nil {
+		panic(err)
+	}
+	return base64.StdEncoding.EncodeToString(sig)
+}
+
+// signEd25519 returns a base64 signature over the digest a document's anchor encodes
+func signEd25519(key ed25519.PrivateKey, anchor string) string {
+	digest, _ := hex.DecodeString(anchor)
+	return base64.StdEncoding.EncodeToString(ed25519.Sign(key, digest))
+}
+
+// registerKey registers registeredPEM for the stamp reporter
+func registerKey(n *mockstub.Network) {
+	if _, err := n.Submit(channel, "stampreporter", stampReporter, "RegisterSigningKey", registeredPEM); err != nil {
+		panic(err)
+	}
+}
+
+// trustRoots stores the root CA of the stamp reporters as the one their signer
+// certificates are checked against
+func trustRoots(n *mockstub.Network) {
+	if _, err := n.Submit(channel, "stampreporter", stampReporterAdmin, "SetOrgRoots", casemodel.OrgStampReporters, mockstub.RootCertificate("StampReportersOrgMSP")); err != nil {
+		panic(err)
+	}
+}
+
+// registeredKeyPEM returns the public key the stamp reporter registers and its fingerprint
+func registeredKeyPEM() (string, string) {
+	der, _ := x509.MarshalPKIXPublicKey(registeredPublic)
+	sum := sha256.Sum256(der)
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})), hex.EncodeToString(sum[:])
+}
+
+// contract is how every test deploys the stamp reporter contract
+var contract = &contracttest.Contract{
+	Name:     "stampreporter",
+	Channels: []string{channel},
+	New:      func() contractapi.ContractInterface { return New() },
+	Start:    start,
+	Admin:    stampReporterAdmin,
+	Client:   stampReporter,
+	// the organizations that hand cases to the stamp reporter; tests that need the stamp
+	// reporters' own roots call trustRoots
+	Trusted: []string{casemodel.OrgRegistrars, casemodel.OrgLawyers},
+}
+
+// stored reads a case from the stamp reporter's ledger
+func stored(t *testing.T, n *mockstub.Network, id string) *Case {
+	t.Helper()
+	value := n.GetState(channel, "stampreporter", id)
+	if value == nil {
+		t.Fatalf("case %s is not on the ledger", id)
+	}
+	c, err := casemodel.DecodeCase(value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+// ids lists the IDs of cases
+func ids(cases []*Case) string {
+	var list []string
+	for _, c := range cases {
+		list = append(list, c.ID)
+	}
+	return strings.Join(list, ",")
+}
+
+// lastHistory returns a case's latest history entry
+func lastHistory(c *Case) HistoryItem {
+	if len(c.History) == 0 {
+		return HistoryItem{}
+	}
+	return c.History[len(c.History)-1]
+}
+
+// feesPaid stores the civil fee schedule and records full payment of CASE_002's fees
+func feesPaid(n *mockstub.Network) {
+	administered("SetFeeSchedule", civilFees)(n)
+	submitted("RecordFeePayment", "CASE_002", "GRN001", "55000")(n)
+}
+
+// submitted returns a setup that submits a transaction as the stamp reporter
+func submitted(function string, args ...string) func(n *mockstub.Network) {
+	return func(n *mockstub.Network) {
+		if _, err := n.Submit(channel, "stampreporter", stampReporter, function, args...); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// administered returns a setup that submits a transaction as the stamp reporters'
+// administrator
+func administered(function string, args ...string) func(n *mockstub.Network) {
+	return func(n *mockstub.Network) {
+		if _, err := n.Submit(channel, "stampreporter", stampReporterAdmin, function, args...); err != nil {
+			panic(err)
+		}
+	}
+}
+
+func TestStampReporterContract(t *testing.T) {
+	// routing moves one route to a new chaincode name and keeps the other defaults
+	routingConfig := casemodel.DefaultRouting()
+	routingConfig.Routes[casemodel.HopStampReporterToBenchClerk] = casemodel.Route{Chaincode: "benchclerk-v2", Channel: casemodel.ChannelStampReporterBenchClerk}
+	routing := string(contracttest.MustJSON(routingConfig))
+
+	pending := contracttest.NewCase("CASE_001", casemodel.StatusPendingStampReporterReview, casemodel.OrgStampReporters)
+	pending.Documents = []Document{{ID: "DOC_1", Name: "plaint.pdf", ContentHash: plaintHash, SignatureHistory: []DocumentSignature{}}}
+	// amended is pending with its plaint replaced by a second version
+	amended := *pending
+	amended.Documents = []Document{
+		{ID: "DOC_1", Name: "plaint.pdf", ContentHash: plaintHash, Version: 1, Status: casemodel.DocumentSuperseded},
+		{ID: "DOC_1_V2", Name: "plaint.pdf", ContentHash: plaintHash, Version: 2, SupersedesID: "DOC_1", Status: casemodel.DocumentActive},
+	}
+	// twoDocuments is pending with an affidavit filed alongside the plaint
+	twoDocuments := *pending
+	twoDocuments.Documents = []Document{pending.Documents[0], {ID: "DOC_2", Name: "affidavit.pdf", ContentHash: casemodel.ContentHash([]byte("%PDF-1.4 affidavit"))}}
+	validated := contracttest.NewCase("CASE_002", casemodel.StatusValidatedByStampReporter, casemodel.OrgBenchClerks)
+	validated.Type, validated.ClaimValue = "Civil", 5000000
+	// selfPaid is validated with a payment on the case that was never recorded
+	selfPaid := *validated
+	selfPaid.Fees = &casemodel.FeeAssessment{CaseID: "CASE_002", Paid: 55000, Payments: []casemodel.FeePayment{{ReceiptRef: "GRN999", Amount: 55000}}}
+	rejected := contracttest.NewCase("CASE_003", casemodel.StatusRejectedByStampReporter, casemodel.OrgLawyers)
+	onHold := contracttest.NewCase("CASE_004", casemodel.StatusOnHoldByStampReporter, casemodel.OrgLawyers)
+	assigned := contracttest.NewCase("CASE_005", casemodel.StatusPendingStampReporterReview, casemodel.OrgRegistrars)
+	// inCamera is pending heard in camera, visible in full to judge J001 only
+	inCamera := *pending
+	inCamera.Sealed, inCamera.AccessList = true, []string{"judge:J001"}
+
+	// held is on hold with its affidavit to be corrected, and cured is the case the lawyer
+	// returns with a corrected affidavit, sealed by the lawyer
+	held := twoDocuments
+	held.Status, held.CurrentOrg = casemodel.StatusForwardedToLawyerOnHold, casemodel.OrgLawyers
+	held.Documents = []Document{
+		{ID: "DOC_1", Name: "plaint.pdf", ContentHash: plaintHash, Version: 1, Status: casemodel.DocumentActive, Verdict: casemodel.VerdictAccepted},
+		{ID: "DOC_2", Name: "affidavit.pdf", ContentHash: plaintHash, Version: 1, Status: casemodel.DocumentActive, Verdict: casemodel.VerdictNeedsCorrection, ReasonCode: casemodel.ReasonIllegible},
+	}
+	held.Defects = []casemodel.Defect{{ID: "DEF_1", DocumentID: "DOC_2", ReasonCode: casemodel.ReasonIllegible, Deadline: "2024-05-22T10:00:00Z", Status: casemodel.DefectOpen}}
+	returned := func(change func(c *Case)) Case {
+		c, err := casemodel.DecodeCase(contracttest.MustJSON(held))
+		if err != nil {
+			panic(err)
+		}
+		if err := casemodel.CureDefect(c, "DEF_1", Document{ID: "DOC_2_V2", Name: "affidavit.pdf", ContentHash: plaintHash}, "Clean scan", "2024-05-20T10:00:00Z"); err != nil {
+			panic(err)
+		}
+		c.Status, c.CurrentOrg = casemodel.StatusPendingStampReporterReview, casemodel.OrgStampReporters
+		change(c)
+		return *contract.Sealed(c, lawyer, casemodel.ChannelStampReporterLawyer)
+	}
+	cured := returned(func(*Case) {})
+	// uncured is returned with the affidavit replaced but its defect left open, and
+	// repriced with its claim value changed as well
+	uncured := returned(func(c *Case) { c.Defects[0].Status = casemodel.DefectOpen })
+	repriced := returned(func(c *Case) { c.ClaimValue = 100 })
+	unsealedCure := cured
+	unsealedCure.HashChain, unsealedCure.Proof = nil, nil
+
+	// rejectedHere was rejected for its affidavit, and resubmitted is the case the lawyer
+	// resubmits with a new affidavit, sealed by the lawyer
+	rejectedHere := twoDocuments
+	rejectedHere.Status, rejectedHere.CurrentOrg = casemodel.StatusForwardedToLawyerRejected, casemodel.OrgLawyers
+	rejectedHere.Documents = []Document{
+		{ID: "DOC_1", Name: "plaint.pdf", ContentHash: plaintHash, Version: 1, Status: casemodel.DocumentActive, Verdict: casemodel.VerdictAccepted},
+		{ID: "DOC_2", Name: "affidavit.pdf", ContentHash: plaintHash, Version: 1, Status: casemodel.DocumentActive, Verdict: casemodel.VerdictRejected, ReasonCode: casemodel.ReasonNotAttested},
+	}
+	resubmit := func(replace bool) Case {
+		c, err := casemodel.DecodeCase(contracttest.MustJSON(rejectedHere))
+		if err != nil {
+			panic(err)
+		}
+		if replace {
+			if err := casemodel.ReplaceDocument(c, "DOC_2", Document{ID: "DOC_2_V2", Name: "affidavit.pdf", ContentHash: plaintHash}, "2024-05-20T10:00:00Z"); err != nil {
+				panic(err)
+			}
+		}
+		c.Status, c.CurrentOrg = casemodel.StatusPendingStampReporterReview, casemodel.OrgStampReporters
+		return *contract.Sealed(c, lawyer, casemodel.ChannelStampReporterLawyer)
+	}
+	resubmitted := resubmit(true)
+	unreplaced := resubmit(false)
+
+	var sent, retried []*Case
+	fromRegistrar := contract.Sealed(assigned, registrar, casemodel.ChannelRegistrarStampReporter)
+	registrarPeer := contracttest.Fake(map[string]peer.Response{"GetCaseById": shim.Success(contracttest.MustJSON(fromRegistrar))})
+
+	// chained is pending as the contract stores it, with its history chained; rewritten has
+	// an entry edited afterwards
+	withHistory := *pending
+	withHistory.History = []HistoryItem{{Status: "CREATED", Comments: "filed"}, {Status: pending.Status, Comments: "recorded"}}
+	chained := contract.Sealed(&withHistory, stampReporter, channel)
+	rewritten := contract.Sealed(&withHistory, stampReporter, channel)
+	rewritten.History[0].Comments = "withdrawn"
+
+	tests := []contracttest.TxTest{
+		{
+			Name:     "InitLedger",
+			Caller:   stampReporterAdmin,
+			Function: "InitLedger",
+			Args:     []string{""},
+		},
+		{
+			Name:     "InitLedger by a stamp reporter who is not an administrator",
+			Caller:   stampReporter,
+			Function: "InitLedger",
+			Args:     []string{""},
+			WantErr:  "access denied for InitLedger",
+		},
+		{
+			Name:     "SetRoutingConfig by a stamp reporter who is not an administrator",
+			Caller:   stampReporter,
+			Function: "SetRoutingConfig",
+			Args:     []string{`{"routes":{}}`},
+			WantErr:  "access denied for SetRoutingConfig",
+		},
+		{
+			Name:     "SetOrgRoots by a stamp reporter who is not an administrator",
+			Caller:   stampReporter,
+			Function: "SetOrgRoots",
+			Args:     []string{casemodel.OrgStampReporters, mockstub.RootCertificate("StampReportersOrgMSP")},
+			WantErr:  "access denied for SetOrgRoots",
+		},
+		{
+			Name:     "SetOrgRoots of an unknown organization",
+			Caller:   stampReporterAdmin,
+			Function: "SetOrgRoots",
+			Args:     []string{"CourtsOrg", mockstub.RootCertificate("StampReportersOrgMSP")},
+			WantErr:  `unknown organization "CourtsOrg"`,
+		},
+		{
+			Name:     "SetOrgRoots of another organization",
+			Caller:   stampReporterAdmin,
+			Function: "SetOrgRoots",
+			Args:     []string{casemodel.OrgJudges, mockstub.RootCertificate("JudgesOrgMSP")},
+			WantErr:  "a member of StampReportersOrg cannot set the root certificates of JudgesOrg",
+		},
+		{
+			Name:     "SetOrgRoots by another organization's administrator",
+			Caller:   lawyerAdmin,
+			Function: "SetOrgRoots",
+			Args:     []string{casemodel.OrgLawyers, mockstub.RootCertificate("LawyersOrgMSP")},
+			Check: func(t *testing.T, n *mockstub.Network, _ []byte) {
+				payload, err := n.Evaluate(channel, "stampreporter", stampReporter, "GetOrgRoots", casemodel.OrgLawyers)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if string(payload) != mockstub.RootCertificate("LawyersOrgMSP") {
+					t.Errorf("roots = %s", payload)
+				}
+			},
+		},
+		{
+			Name: "ImportOrgRoots",
+			Peers: map[string]mockstub.ChaincodeFunc{"registrar": contracttest.Fake(map[string]peer.Response{
+				"GetOrgRoots": shim.Success([]byte(mockstub.RootCertificate("JudgesOrgMSP"))),
+			})},
+			Caller:   stampReporterAdmin,
+			Function: "ImportOrgRoots",
+			Args:     []string{casemodel.OrgJudges, casemodel.HopStampReporterToRegistrar},
+			Check: func(t *testing.T, n *mockstub.Network, _ []byte) {
+				if got := string(n.GetState(channel, "stampreporter", casemodel.OrgRootsKey(casemodel.OrgJudges))); got != mockstub.RootCertificate("JudgesOrgMSP") {
+					t.Errorf("roots = %s", got)
+				}
+			},
+		},
+		{
+			Name:     "ImportOrgRoots by a stamp reporter who is not an administrator",
+			Caller:   stampReporter,
+			Function: "ImportOrgRoots",
+			Args:     []string{casemodel.OrgJudges, casemodel.HopStampReporterToRegistrar},
+			WantErr:  "access denied for ImportOrgRoots",
+		},
+		{
+			Name:     "ValidateDocuments valid",
+			Seed:     []*Case{pending},
+			Caller:   stampReporter,
+			Function: "ValidateDocuments",
+			Args:     []string{"CASE_001", `{"comments":"Stamped","stampReporterId":"SR001","validations":[{"documentId":"DOC_1","verdict":"ACCEPTED","signature":"` + plaintSignature + `","comments":"Court fee paid"}]}`},
+			Check: func(t *testing.T, n *mockstub.Network, _ []byte) {
+				c := stored(t, n, "CASE_001")
+				if c.Status != casemodel.StatusValidatedByStampReporter || c.CurrentOrg != casemodel.OrgBenchClerks {
+					t.Errorf("case is %s at %s", c.Status, c.CurrentOrg)
+				}
+				doc := c.Documents[0]
+				if !doc.Validated || doc.SignatureHash != plaintSignature || doc.ContentHash != plaintHash || len(doc.SignatureHistory) != 1 {
+					t.Fatalf("document = %+v", doc)
+				}
+				if sig := doc.SignatureHistory[0]; sig.StampReporterID != "SR001" || sig.Timestamp != stamp || sig.Algorithm != casemodel.SignatureECDSA || sig.SignerFingerprint == "" || sig.PublicKey == "" {
+					t.Errorf("signature = %+v", sig)
+				}
+				if got := lastHistory(c).Comments; got != "Stamped (by Stamp Reporter SR001)" {
+					t.Errorf("history comment = %q", got)
+				}
+			},
+		},
+		{
+			Name:     "ValidateDocuments rejected",
+			Seed:     []*Case{pending},
+			Caller:   stampReporter,
+			Function: "ValidateDocuments",
+			Args:     []string{"CASE_001", `{"stampReporterId":"SR001","rejectionReason":"Unsigned vakalatnama","validations":[{"documentId":"DOC_1","verdict":"REJECTED","reasonCode":"UNSIGNED","comments":"Vakalatnama not signed"}]}`},
+			Check: func(t *testing.T, n *mockstub.Network, _ []byte) {
+				c := stored(t, n, "CASE_001")
+				if c.Status != casemodel.StatusRejectedByStampReporter || c.CurrentOrg != casemodel.OrgLawyers {
+					t.Errorf("case is %s at %s", c.Status, c.CurrentOrg)
+				}
+				doc := c.Documents[0]
+				if doc.Validated || doc.Verdict != casemodel.VerdictRejected || doc.ReasonCode != casemodel.ReasonUnsigned || doc.VerdictComments != "Vakalatnama not signed" || len(doc.SignatureHistory) != 0 {
+					t.Errorf("document = %+v", doc)
+				}
+				if got := lastHistory(c).Comments; got != "Rejected: Unsigned vakalatnama. Documents returned: DOC_1 REJECTED (UNSIGNED) (by Stamp Reporter SR001)" {
+					t.Errorf("history comment = %q", got)
+				}
+			},
+		},
+		{
+			Name:     "ValidateDocuments needs correction",
+			Seed:     []*Case{&twoDocuments},
+			Caller:   stampReporter,
+			Function: "ValidateDocuments",
+			Args:     []string{"CASE_001", `{"stampReporterId":"SR001","validations":[{"documentId":"DOC_1","verdict":"ACCEPTED","signature":"` + plaintSignature + `"},{"documentId":"DOC_2","verdict":"NEEDS_CORRECTION","reasonCode":"ILLEGIBLE"}]}`},
+			Check: func(t *testing.T, n *mockstub.Network, _ []byte) {
+				c := stored(t, n, "CASE_001")
+				if c.Status != casemodel.StatusOnHoldByStampReporter || c.CurrentOrg != casemodel.OrgLawyers {
+					t.Errorf("case is %s at %s", c.Status, c.CurrentOrg)
+				}
+				if docs := c.Documents; !docs[0].Validated || docs[0].Verdict != casemodel.VerdictAccepted || docs[1].Validated || docs[1].Verdict != casemodel.VerdictNeedsCorrection {
+					t.Errorf("documents = %+v", docs)
+				}
+				if got := lastHistory(c).Comments; got != "Documents returned: DOC_2 NEEDS_CORRECTION (ILLEGIBLE) (by Stamp Reporter SR001)" {
+					t.Errorf("history comment = %q", got)
+				}
+				due := start.Add(casemodel.DefaultCurePeriod).Format(time.RFC3339)
+				if len(c.Defects) != 1 || c.Defects[0].ID != "DEF_1" || c.Defects[0].DocumentID != "DOC_2" || c.Defects[0].Status != casemodel.DefectOpen || c.Defects[0].Deadline != due {
+					t.Errorf("defects = %+v", c.Defects)
+				}
+			},
+		},
+		{
+			Name:     "ValidateDocuments needs correction by a deadline",
+			Seed:     []*Case{&twoDocuments},
+			Caller:   stampReporter,
+			Function: "ValidateDocuments",
+			Args:     []string{"CASE_001", `{"stampReporterId":"SR001","validations":[{"documentId":"DOC_1","verdict":"ACCEPTED","signature":"` + plaintSignature + `"},{"documentId":"DOC_2","verdict":"NEEDS_CORRECTION","reasonCode":"ILLEGIBLE","comments":"Page 3 unreadable","cureBy":"2024-05-17T10:00:00Z"}]}`},
+			Check: func(t *testing.T, n *mockstub.Network, _ []byte) {
+				c := stored(t, n, "CASE_001")
+				if len(c.Defects) != 1 || c.Defects[0].Deadline != "2024-05-17T10:00:00Z" || c.Defects[0].Description != "Page 3 unreadable" {
+					t.Errorf("defects = %+v", c.Defects)
+				}
+			},
+		},
+		{
+			Name:     "ValidateDocuments with a deadline on an accepted document",
+			Seed:     []*Case{pending},
+			Caller:   stampReporter,
+			Function: "ValidateDocuments",
+			Args:     []string{"CASE_001", `{"stampReporterId":"SR001","validations":[{"documentId":"DOC_1","verdict":"ACCEPTED","signature":"` + plaintSignature + `","cureBy":"2024-05-17T10:00:00Z"}]}`},
+			WantErr:  "document DOC_1 is ACCEPTED, only documents that need correction have a cure deadline",
+		},
+		{
+			Name:     "ValidateDocuments rejection outweighs correction",
+			Seed:     []*Case{&twoDocuments},
+			Caller:   stampReporter,
+			Function: "ValidateDocuments",
+			Args:     []string{"CASE_001", `{"stampReporterId":"SR001","validations":[{"documentId":"DOC_1","verdict":"NEEDS_CORRECTION","reasonCode":"NOT_ATTESTED"},{"documentId":"DOC_2","verdict":"REJECTED","reasonCode":"WRONG_DOCUMENT_TYPE"}]}`},
+			Check: func(t *testing.T, n *mockstub.Network, _ []byte) {
+				if c := stored(t, n, "CASE_001"); c.Status != casemodel.StatusRejectedByStampReporter {
+					t.Errorf("case is %s", c.Status)
+				}
+			},
+		},
+		{
+			Name:     "ValidateDocuments without a verdict for every document",
+			Seed:     []*Case{&twoDocuments},
+			Caller:   stampReporter,
+			Function: "ValidateDocuments",
+			Args:     []string{"CASE_001", `{"stampReporterId":"SR001","validations":[{"documentId":"DOC_1","verdict":"ACCEPTED","signature":"` + plaintSignature + `"}]}`},
+			WantErr:  "document DOC_2 of case CASE_001 has no verdict",
+		},
+		{
+			Name:     "ValidateDocuments rejected without a reason code",
+			Seed:     []*Case{pending},
+			Caller:   stampReporter,
+			Function: "ValidateDocuments",
+			Args:     []string{"CASE_001", `{"stampReporterId":"SR001","validations":[{"documentId":"DOC_1","verdict":"REJECTED"}]}`},
+			WantErr:  `document DOC_1 is REJECTED and needs a reason code, got ""`,
+		},
+		{
+			Name:     "ValidateDocuments signs a rejected document",
+			Seed:     []*Case{pending},
+			Caller:   stampReporter,
+			Function: "ValidateDocuments",
+			Args:     []string{"CASE_001", `{"stampReporterId":"SR001","validations":[{"documentId":"DOC_1","verdict":"REJECTED","reasonCode":"UNSIGNED","signature":"` + plaintSignature + `"}]}`},
+			WantErr:  "document DOC_1 is REJECTED, only accepted documents are signed",
+		},
+		{
+			Name:     "ValidateDocuments latest version",
+			Seed:     []*Case{&amended},
+			Caller:   stampReporter,
+			Function: "ValidateDocuments",
+			Args:     []string{"CASE_001", `{"stampReporterId":"SR001","validations":[{"documentId":"DOC_1_V2","verdict":"ACCEPTED","signature":"` + plaintSignature + `"}]}`},
+			Check: func(t *testing.T, n *mockstub.Network, _ []byte) {
+				docs := stored(t, n, "CASE_001").Documents
+				if docs[0].Validated || !docs[1].Validated || docs[1].SignatureHash != plaintSignature {
+					t.Errorf("documents = %+v", docs)
+				}
+			},
+		},
+		{
+			Name:     "ValidateDocuments superseded version",
+			Seed:     []*Case{&amended},
+			Caller:   stampReporter,
+			Function: "ValidateDocuments",
+			Args:     []string{"CASE_001", `{"stampReporterId":"SR001","validations":[{"documentId":"DOC_1","verdict":"ACCEPTED","signature":"` + plaintSignature + `"}]}`},
+			WantErr:  "document DOC_1 is SUPERSEDED, only the latest active version of a document can be validated",
+		},
+		{
+			Name:     "ValidateDocuments signed by another key",
+			Seed:     []*Case{pending},
+			Caller:   stampReporter,
+			Function: "ValidateDocuments",
+			Args:     []string{"CASE_001", `{"stampReporterId":"SR001","validations":[{"documentId":"DOC_1","verdict":"ACCEPTED","signature":"` + signECDSA(otherKey, plaintHash) + `"}]}`},
+			WantErr:  "signature on document DOC_1 is invalid: ECDSA signature does not match the content hash",
+		},
+		{
+			Name:     "ValidateDocuments without a signature",
+			Seed:     []*Case{pending},
+			Caller:   stampReporter,
+			Function: "ValidateDocuments",
+			Args:     []string{"CASE_001", `{"stampReporterId":"SR001","validations":[{"documentId":"DOC_1","verdict":"ACCEPTED","signatureHash":"sig-1"}]}`},
+			WantErr:  "signature on document DOC_1 is invalid: signature is not base64",
+		},
+		{
+			Name:     "ValidateDocuments with a registered key",
+			Seed:     []*Case{pending},
+			Setup:    registerKey,
+			Caller:   stampReporter,
+			Function: "ValidateDocuments",
+			Args:     []string{"CASE_001", `{"stampReporterId":"SR001","validations":[{"documentId":"DOC_1","verdict":"ACCEPTED","signature":"` + plaintEd25519 + `","keyFingerprint":"` + registeredFingerprint + `"}]}`},
+			Check: func(t *testing.T, n *mockstub.Network, _ []byte) {
+				sig := stored(t, n, "CASE_001").Documents[0].SignatureHistory[0]
+				if sig.Algorithm != casemodel.SignatureEd25519 || sig.SignerFingerprint != registeredFingerprint || sig.PublicKey != registeredPEM {
+					t.Errorf("signature = %+v", sig)
+				}
+			},
+		},
+		{
+			Name:     "ValidateDocuments with an unregistered key",
+			Seed:     []*Case{pending},
+			Caller:   stampReporter,
+			Function: "ValidateDocuments",
+			Args:     []string{"CASE_001", `{"stampReporterId":"SR001","validations":[{"documentId":"DOC_1","verdict":"ACCEPTED","signature":"` + plaintEd25519 + `","keyFingerprint":"` + registeredFingerprint + `"}]}`},
+			WantErr:  "signing key " + registeredFingerprint + " is not registered",
+		},
+		{
+			Name:     "RegisterSigningKey",
+			Caller:   stampReporter,
+			Function: "RegisterSigningKey",
+			Args:     []string{registeredPEM},
+			Check: func(t *testing.T, _ *mockstub.Network, payload []byte) {
+				var key casemodel.SigningKey
+				contracttest.Decode(t, payload, &key)
+				if key.Fingerprint != registeredFingerprint || key.Algorithm != casemodel.SignatureEd25519 || key.RegisteredAt != stamp {
+					t.Errorf("key = %+v", key)
+				}
+			},
+		},
+		{
+			Name:     "RegisterSigningKey by a lawyer",
+			Caller:   lawyer,
+			Function: "RegisterSigningKey",
+			Args:     []string{registeredPEM},
+			WantErr:  "access denied",
+		},
+		{
+			Name: "VerifyDocumentSignatures",
+			Seed: []*Case{pending},
+			Setup: func(n *mockstub.Network) {
+				trustRoots(n)
+				registerKey(n)
+				_, err := n.Submit(channel, "stampreporter", stampReporter, "ValidateDocuments", "CASE_001",
+					`{"stampReporterId":"SR001","validations":[{"documentId":"DOC_1","verdict":"ACCEPTED","signature":"`+plaintSignature+`"},{"documentId":"DOC_1","verdict":"ACCEPTED","signature":"`+plaintEd25519+`","keyFingerprint":"`+registeredFingerprint+`"}]}`)
+				if err != nil {
+					panic(err)
+				}
+			},
+			Caller:   stampReporter,
+			Function: "VerifyDocumentSignatures",
+			Args:     []string{"CASE_001"},
+			Check: func(t *testing.T, _ *mockstub.Network, payload []byte) {
+				var v casemodel.SignatureVerification
+				contracttest.Decode(t, payload, &v)
+				if !v.Valid || len(v.Signatures) != 2 || v.Signatures[1].SignerFingerprint != registeredFingerprint {
+					t.Errorf("verification = %+v", v)
+				}
+			},
+		},
+		{
+			Name: "VerifyDocumentSignatures of a self-signed stamp reporter",
+			Seed: []*Case{pending},
+			Setup: func(n *mockstub.Network) {
+				trustRoots(n)
+				forger := stampReporter
+				forger.Key, forger.SelfSigned = otherKey, true
+				_, err := n.Submit(channel, "stampreporter", forger, "ValidateDocuments", "CASE_001",
+					`{"stampReporterId":"SR001","validations":[{"documentId":"DOC_1","verdict":"ACCEPTED","signature":"`+signECDSA(otherKey, plaintHash)+`"}]}`)
+				if err != nil {
+					panic(err)
+				}
+			},
+			Caller:   stampReporter,
+			Function: "VerifyDocumentSignatures",
+			Args:     []string{"CASE_001"},
+			Check: func(t *testing.T, _ *mockstub.Network, payload []byte) {
+				var v casemodel.SignatureVerification
+				contracttest.Decode(t, payload, &v)
+				if v.Valid || len(v.Signatures) != 1 || !strings.Contains(v.Signatures[0].Reason, "was not issued by StampReportersOrg") {
+					t.Errorf("verification = %+v", v)
+				}
+			},
+		},
+		{
+			Name: "VerifyDocumentSignatures without trusted roots",
+			Seed: []*Case{pending},
+			Setup: func(n *mockstub.Network) {
+				_, err := n.Submit(channel, "stampreporter", stampReporter, "ValidateDocuments", "CASE_001",
+					`{"stampReporterId":"SR001","validations":[{"documentId":"DOC_1","verdict":"ACCEPTED","signature":"`+plaintSignature+`"}]}`)
+				if err != nil {
+					panic(err)
+				}
+			},
+			Caller:   stampReporter,
+			Function: "VerifyDocumentSignatures",
+			Args:     []string{"CASE_001"},
+			Check: func(t *testing.T, _ *mockstub.Network, payload []byte) {
+				var v casemodel.SignatureVerification
+				contracttest.Decode(t, payload, &v)
+				if v.Valid || len(v.Signatures) != 1 || v.Signatures[0].Reason != "no root certificates are trusted for StampReportersOrg on this channel" {
+					t.Errorf("verification = %+v", v)
+				}
+			},
+		},
+		{
+			Name:     "ValidateDocuments unknown document",
+			Seed:     []*Case{pending},
+			Caller:   stampReporter,
+			Function: "ValidateDocuments",
+			Args:     []string{"CASE_001", `{"validations":[{"documentId":"DOC_9"}]}`},
+			WantErr:  "document not found: DOC_9",
+		},
+		{
+			Name:     "ValidateDocuments twice",
+			Seed:     []*Case{validated},
+			Caller:   stampReporter,
+			Function: "ValidateDocuments",
+			Args:     []string{"CASE_002", `{}`},
+			WantErr:  "cannot move from VALIDATED_BY_STAMP_REPORTER to VALIDATED_BY_STAMP_REPORTER",
+		},
+		{
+			Name:     "ValidateDocuments fetches from registrar",
+			Peers:    map[string]mockstub.ChaincodeFunc{"registrar": registrarPeer},
+			Caller:   stampReporter,
+			Function: "ValidateDocuments",
+			Args:     []string{"CASE_005", `{}`},
+			Check: func(t *testing.T, n *mockstub.Network, _ []byte) {
+				if c := stored(t, n, "CASE_005"); c.Status != casemodel.StatusValidatedByStampReporter {
+					t.Errorf("case is %s", c.Status)
+				}
+			},
+		},
+		{
+			Name:     "ValidateDocuments missing case",
+			Peers:    map[string]mockstub.ChaincodeFunc{"registrar": contracttest.Fake(map[string]peer.Response{"GetCaseById": shim.Error("case not found: CASE_404")})},
+			Caller:   stampReporter,
+			Function: "ValidateDocuments",
+			Args:     []string{"CASE_404", `{}`},
+			WantErr:  "case does not exist: CASE_404",
+		},
+		{
+			Name:     "ValidateDocuments by registrar",
+			Seed:     []*Case{pending},
+			Caller:   registrar,
+			Function: "ValidateDocuments",
+			Args:     []string{"CASE_001", `{}`},
+			WantErr:  "access denied for ValidateDocuments",
+		},
+		{
+			Name:     "GetPendingCases",
+			Seed:     []*Case{pending, validated},
+			Caller:   stampReporter,
+			Function: "GetPendingCases",
+			Check: func(t *testing.T, _ *mockstub.Network, payload []byte) {
+				var cases []*Case
+				contracttest.Decode(t, payload, &cases)
+				if got := ids(cases); got != "CASE_001" {
+					t.Errorf("cases = %s", got)
+				}
+			},
+		},
+		{
+			Name:     "GetPendingCases sealed",
+			Seed:     []*Case{&inCamera},
+			Caller:   stampReporter,
+			Function: "GetPendingCases",
+			Check: func(t *testing.T, _ *mockstub.Network, payload []byte) {
+				var cases []*Case
+				contracttest.Decode(t, payload, &cases)
+				if len(cases) != 1 || !cases[0].Sealed || cases[0].Status != casemodel.StatusPendingStampReporterReview || len(cases[0].Documents) != 0 {
+					t.Errorf("cases = %s", payload)
+				}
+			},
+		},
+		{
+			Name:     "GetCaseById sealed",
+			Seed:     []*Case{&inCamera},
+			Caller:   stampReporter,
+			Function: "GetCaseById",
+			Args:     []string{"CASE_001"},
+			WantErr:  "case is sealed",
+		},
+		{
+			Name:     "GetPendingCasesWithPagination",
+			Seed:     []*Case{pending, validated},
+			Caller:   stampReporter,
+			Function: "GetPendingCasesWithPagination",
+			Args:     []string{"5", ""},
+			Check: func(t *testing.T, _ *mockstub.Network, payload []byte) {
+				var page CasePage
+				contracttest.Decode(t, payload, &page)
+				if ids(page.Records) != "CASE_001" || page.FetchedCount != 1 || page.Bookmark != "CASE_001" {
+					t.Errorf("page = %+v", page)
+				}
+			},
+		},
+		{
+			Name:     "GetCaseById by bench clerk",
+			Seed:     []*Case{validated},
+			Caller:   benchClerk,
+			Function: "GetCaseById",
+			Args:     []string{"CASE_002"},
+			Check: func(t *testing.T, _ *mockstub.Network, payload []byte) {
+				var c Case
+				contracttest.Decode(t, payload, &c)
+				if c.ID != "CASE_002" || c.CurrentOrg != casemodel.OrgBenchClerks {
+					t.Errorf("case = %+v", c)
+				}
+			},
+		},
+		{
+			Name:     "GetCaseById fetches from registrar",
+			Peers:    map[string]mockstub.ChaincodeFunc{"registrar": registrarPeer},
+			Caller:   stampReporter,
+			Function: "GetCaseById",
+			Args:     []string{"CASE_005"},
+			Check: func(t *testing.T, n *mockstub.Network, _ []byte) {
+				c := stored(t, n, "CASE_005")
+				if c.CurrentOrg != casemodel.OrgStampReporters || lastHistory(c).Status != "TRANSFERRED_TO_STAMPREPORTER" {
+					t.Errorf("case = %+v", c)
+				}
+			},
+		},
+		{
+			Name:     "QueryStats",
+			Seed:     []*Case{pending, validated, rejected, onHold},
+			Caller:   stampReporter,
+			Function: "QueryStats",
+			Check: func(t *testing.T, _ *mockstub.Network, payload []byte) {
+				if want := `{"pendingCases":1,"validatedCases":1,"rejectedCases":1}`; string(payload) != want {
+					t.Errorf("QueryStats = %s, want %s", payload, want)
+				}
+			},
+		},
+		{
+			Name:  "ForwardCaseToBenchClerk",
+			Seed:  []*Case{validated},
+			Peers: map[string]mockstub.ChaincodeFunc{"benchclerk": contracttest.Recorder(&sent)},
+			Setup: func(n *mockstub.Network) {
+				feesPaid(n)
+				contract.Reroute(casemodel.HopStampReporterToBenchClerk, casemodel.Route{Chaincode: "benchclerk"})(n)
+			},
+			Caller:   stampReporter,
+			Function: "ForwardCaseToBenchClerk",
+			Args:     []string{"CASE_002"},
+			Check: func(t *testing.T, n *mockstub.Network, _ []byte) {
+				c := stored(t, n, "CASE_002")
+				if c.Status != casemodel.StatusForwardedToBenchClerk || lastHistory(c).Status != "FORWARDED_TO_BENCHCLERK" {
+					t.Errorf("case = %+v", c)
+				}
+				if len(sent) != 1 || sent[0].Status != casemodel.StatusForwardedToBenchClerk {
+					t.Errorf("bench clerk received %+v", sent)
+				}
+				if pending := contract.Outbox(t, n); len(pending) != 0 {
+					t.Errorf("pending transfers = %+v", pending)
+				}
+			},
+		},
+		{
+			Name:  "ForwardCaseToBenchClerk rejected by bench clerk",
+			Seed:  []*Case{validated},
+			Peers: map[string]mockstub.ChaincodeFunc{"benchclerk": contracttest.Fake(map[string]peer.Response{"StoreCase": shim.Error("ledger unavailable")})},
+			Setup: func(n *mockstub.Network) {
+				feesPaid(n)
+				contract.Reroute(casemodel.HopStampReporterToBenchClerk, casemodel.Route{Chaincode: "benchclerk"})(n)
+			},
+			Caller:   stampReporter,
+			Function: "ForwardCaseToBenchClerk",
+			Args:     []string{"CASE_002"},
+			Check: func(t *testing.T, n *mockstub.Network, _ []byte) {
+				if c := stored(t, n, "CASE_002"); c.Status != casemodel.StatusForwardedToBenchClerk {
+					t.Errorf("case is %s", c.Status)
+				}
+				pending := contract.Outbox(t, n)
+				if len(pending) != 1 || pending[0].To != casemodel.OrgBenchClerks || pending[0].Attempts != 1 || pending[0].LastError != "ledger unavailable" {
+					t.Errorf("pending transfers = %+v", pending)
+				}
+			},
+		},
+		{
+			Name:     "ForwardCaseToBenchClerk on another channel",
+			Seed:     []*Case{validated},
+			Setup:    feesPaid,
+			Caller:   stampReporter,
+			Function: "ForwardCaseToBenchClerk",
+			Args:     []string{"CASE_002"},
+			Check: func(t *testing.T, n *mockstub.Network, _ []byte) {
+				pending := contract.Outbox(t, n)
+				if len(pending) != 1 || pending[0].CaseID != "CASE_002" || pending[0].Attempts != 0 || !strings.Contains(pending[0].LastError, casemodel.ChannelStampReporterBenchClerk) {
+					t.Errorf("pending transfers = %+v", pending)
+				}
+			},
+		},
+		{
+			Name: "ForwardCaseToBenchClerk with fees unpaid",
+			Seed: []*Case{validated},
+			Setup: func(n *mockstub.Network) {
+				administered("SetFeeSchedule", civilFees)(n)
+				submitted("RecordFeePayment", "CASE_002", "GRN001", "50000")(n)
+			},
+			Caller:   stampReporter,
+			Function: "ForwardCaseToBenchClerk",
+			Args:     []string{"CASE_002"},
+			WantErr:  "case CASE_002 has 5000 paise of court fee and stamp duty unpaid",
+		},
+		{
+			Name:     "ForwardCaseToBenchClerk with fees paid only on the case",
+			Seed:     []*Case{&selfPaid},
+			Setup:    administered("SetFeeSchedule", civilFees),
+			Caller:   stampReporter,
+			Function: "ForwardCaseToBenchClerk",
+			Args:     []string{"CASE_002"},
+			WantErr:  "case CASE_002 has 55000 paise of court fee and stamp duty unpaid",
+		},
+		{
+			Name:     "ForwardCaseToBenchClerk without a fee schedule",
+			Seed:     []*Case{validated},
+			Caller:   stampReporter,
+			Function: "ForwardCaseToBenchClerk",
+			Args:     []string{"CASE_002"},
+			WantErr:  `no fee schedule for case type "Civil"`,
+		},
+		{
+			Name:     "ForwardCaseToBenchClerk before validation",
+			Seed:     []*Case{pending},
+			Caller:   stampReporter,
+			Function: "ForwardCaseToBenchClerk",
+			Args:     []string{"CASE_001"},
+			WantErr:  "cannot move from PENDING_STAMP_REPORTER_REVIEW to FORWARDED_TO_BENCHCLERK",
+		},
+		{
+			Name:     "ForwardCaseToLawyer rejected case",
+			Seed:     []*Case{rejected},
+			Peers:    map[string]mockstub.ChaincodeFunc{"lawyer": contracttest.Fake(map[string]peer.Response{"StoreCase": shim.Success(nil)})},
+			Caller:   stampReporter,
+			Function: "ForwardCaseToLawyer",
+			Args:     []string{"CASE_003"},
+			Check: func(t *testing.T, n *mockstub.Network, _ []byte) {
+				if c := stored(t, n, "CASE_003"); c.Status != casemodel.StatusForwardedToLawyerRejected || c.CurrentOrg != casemodel.OrgLawyers {
+					t.Errorf("case is %s at %s", c.Status, c.CurrentOrg)
+				}
+			},
+		},
+		{
+			Name:     "ForwardCaseToLawyer on-hold case",
+			Seed:     []*Case{onHold},
+			Peers:    map[string]mockstub.ChaincodeFunc{"lawyer": contracttest.Fake(map[string]peer.Response{"StoreCase": shim.Success(nil)})},
+			Caller:   stampReporter,
+			Function: "ForwardCaseToLawyer",
+			Args:     []string{"CASE_004"},
+			Check: func(t *testing.T, n *mockstub.Network, _ []byte) {
+				if c := stored(t, n, "CASE_004"); c.Status != casemodel.StatusForwardedToLawyerOnHold {
+					t.Errorf("case is %s", c.Status)
+				}
+			},
+		},
+		{
+			Name:     "ForwardCaseToLawyer rejected by lawyer",
+			Seed:     []*Case{rejected},
+			Peers:    map[string]mockstub.ChaincodeFunc{"lawyer": contracttest.Fake(nil)},
+			Setup:    contract.Reroute(casemodel.HopStampReporterToLawyer, casemodel.Route{Chaincode: "lawyer"}),
+			Caller:   stampReporter,
+			Function: "ForwardCaseToLawyer",
+			Args:     []string{"CASE_003"},
+			Check: func(t *testing.T, n *mockstub.Network, _ []byte) {
+				pending := contract.Outbox(t, n)
+				if len(pending) != 1 || pending[0].To != casemodel.OrgLawyers || pending[0].LastError != "Function StoreCase not found" {
+					t.Errorf("pending transfers = %+v", pending)
+				}
+			},
+		},
+		{
+			Name: "ListPendingTransfers by bench clerk",
+			Seed: []*Case{validated, rejected},
+			Setup: func(n *mockstub.Network) {
+				feesPaid(n)
+				submitted("ForwardCaseToBenchClerk", "CASE_002")(n)
+				submitted("ForwardCaseToLawyer", "CASE_003")(n)
+			},
+			Caller:   benchClerk,
+			Function: "ListPendingTransfers",
+			Check: func(t *testing.T, _ *mockstub.Network, payload []byte) {
+				var transfers []*casemodel.Transfer
+				contracttest.Decode(t, payload, &transfers)
+				if len(transfers) != 2 || transfers[0].To != casemodel.OrgBenchClerks || transfers[1].To != casemodel.OrgLawyers {
+					t.Errorf("transfers = %s", payload)
+				}
+			},
+		},
+		{
+			Name:     "ClaimTransfer by lawyer outside ReceiveTransfers",
+			Seed:     []*Case{rejected},
+			Setup:    submitted("ForwardCaseToLawyer", "CASE_003"),
+			Caller:   lawyer,
+			Function: "ClaimTransfer",
+			Args:     []string{"1"},
+			WantErr:  "transfer 1 can only be claimed by ReceiveTransfers of lawyer, not ClaimTransfer of stampreporter",
+		},
+		{
+			Name:     "ClaimTransfer addressed to the lawyer by bench clerk",
+			Seed:     []*Case{rejected},
+			Setup:    submitted("ForwardCaseToLawyer", "CASE_003"),
+			Caller:   benchClerk,
+			Function: "ClaimTransfer",
+			Args:     []string{"1"},
+			WantErr:  "transfer 1 is addressed to LawyersOrg, not BenchClerksOrg",
+		},
+		{
+			Name:     "ClaimTransfer by registrar",
+			Caller:   registrar,
+			Function: "ClaimTransfer",
+			Args:     []string{"1"},
+			WantErr:  "access denied for ClaimTransfer",
+		},
+		{
+			Name:  "RetryTransfer after rerouting",
+			Seed:  []*Case{validated},
+			Peers: map[string]mockstub.ChaincodeFunc{"benchclerk": contracttest.Recorder(&retried)},
+			Setup: func(n *mockstub.Network) {
+				feesPaid(n)
+				submitted("ForwardCaseToBenchClerk", "CASE_002")(n)
+				contract.Reroute(casemodel.HopStampReporterToBenchClerk, casemodel.Route{Chaincode: "benchclerk"})(n)
+			},
+			Caller:   stampReporter,
+			Function: "RetryTransfer",
+			Args:     []string{"1"},
+			Check: func(t *testing.T, _ *mockstub.Network, payload []byte) {
+				var transfer casemodel.Transfer
+				contracttest.Decode(t, payload, &transfer)
+				if transfer.Status != casemodel.TransferClaimed || transfer.Attempts != 1 {
+					t.Errorf("transfer = %s", payload)
+				}
+				if len(retried) != 1 || retried[0].ID != "CASE_002" {
+					t.Errorf("bench clerk received %+v", retried)
+				}
+			},
+		},
+		{
+			Name:     "RetryTransfer by bench clerk",
+			Caller:   benchClerk,
+			Function: "RetryTransfer",
+			Args:     []string{"1"},
+			WantErr:  "access denied for RetryTransfer",
+		},
+		{
+			Name:     "ForwardCaseToLawyer validated case",
+			Seed:     []*Case{validated},
+			Caller:   stampReporter,
+			Function: "ForwardCaseToLawyer",
+			Args:     []string{"CASE_002"},
+			WantErr:  "cannot move from VALIDATED_BY_STAMP_REPORTER to FORWARDED_TO_LAWYER_REJECTED",
+		},
+		{
+			Name:     "GetRejectedCases by lawyer",
+			Seed:     []*Case{pending, rejected, onHold},
+			Caller:   lawyer,
+			Function: "GetRejectedCases",
+			Check: func(t *testing.T, _ *mockstub.Network, payload []byte) {
+				var cases []*Case
+				contracttest.Decode(t, payload, &cases)
+				if got := ids(cases); got != "CASE_003" {
+					t.Errorf("cases = %s", got)
+				}
+			},
+		},
+		{
+			Name:     "GetOnHoldCases by lawyer",
+			Seed:     []*Case{pending, rejected, onHold},
+			Caller:   lawyer,
+			Function: "GetOnHoldCases",
+			Check: func(t *testing.T, _ *mockstub.Network, payload []byte) {
+				var cases []*Case
+				contracttest.Decode(t, payload, &cases)
+				if got := ids(cases); got != "CASE_004" {
+					t.Errorf("cases = %s", got)
+				}
+			},
+		},
+		{
+			Name:     "GetOnHoldCases with none on hold",
+			Seed:     []*Case{pending, rejected},
+			Caller:   lawyer,
+			Function: "GetOnHoldCases",
+			Check: func(t *testing.T, _ *mockstub.Network, payload []byte) {
+				if string(payload) != "[]" {
+					t.Errorf("payload = %s", payload)
+				}
+			},
+		},
+		{
+			Name:     "StoreCase by registrar",
+			Caller:   registrar,
+			Function: "StoreCase",
+			Args:     []string{string(contracttest.MustJSON(contract.Sealed(contracttest.NewCase("CASE_006", casemodel.StatusPendingStampReporterReview, casemodel.OrgStampReporters), registrar, casemodel.ChannelRegistrarStampReporter)))},
+			Check: func(t *testing.T, n *mockstub.Network, _ []byte) {
+				stored(t, n, "CASE_006")
+			},
+		},
+		{
+			Name:     "StoreCase held by another organization",
+			Caller:   registrar,
+			Function: "StoreCase",
+			Args:     []string{string(contracttest.MustJSON(fromRegistrar))},
+			WantErr:  "case CASE_005 is not currently assigned to StampReportersOrg",
+		},
+		{
+			Name:     "StoreCase not handed over by the registrar",
+			Caller:   registrar,
+			Function: "StoreCase",
+			Args:     []string{string(contracttest.MustJSON(contracttest.NewCase("CASE_006", casemodel.StatusPendingStampReporterReview, casemodel.OrgStampReporters)))},
+			WantErr:  "case CASE_006 has no hash chain",
+		},
+		{
+			Name:     "StoreCase by stamp reporter",
+			Caller:   stampReporter,
+			Function: "StoreCase",
+			Args:     []string{string(contracttest.MustJSON(contract.Sealed(contracttest.NewCase("CASE_006", casemodel.StatusPendingStampReporterReview, casemodel.OrgStampReporters), registrar, casemodel.ChannelRegistrarStampReporter)))},
+			WantErr:  "access denied for StoreCase",
+		},
+		{
+			Name:     "StoreCase by lawyer",
+			Caller:   lawyer,
+			Function: "StoreCase",
+			Args:     []string{string(contracttest.MustJSON(pending))},
+			WantErr:  "access denied for StoreCase",
+		},
+		{
+			Name:     "ReceiveCuredCase",
+			Seed:     []*Case{&held},
+			Caller:   lawyer,
+			Function: "ReceiveCuredCase",
+			Args:     []string{string(contracttest.MustJSON(cured))},
+			Check: func(t *testing.T, n *mockstub.Network, _ []byte) {
+				c := stored(t, n, "CASE_001")
+				if c.Status != casemodel.StatusPendingStampReporterReview || c.Document("DOC_2_V2") == nil {
+					t.Errorf("case = %+v", c)
+				}
+				if h := lastHistory(c); h.Status != "RECEIVED_FROM_LAWYER" || h.Comments != "Case returned for review with defects cured: DEF_1" {
+					t.Errorf("history = %+v", h)
+				}
+			},
+		},
+		{
+			Name:     "ReceiveCuredCase with a defect still open",
+			Seed:     []*Case{&held},
+			Caller:   lawyer,
+			Function: "ReceiveCuredCase",
+			Args:     []string{string(contracttest.MustJSON(uncured))},
+			WantErr:  "defect DEF_1 of case CASE_001 is not cured",
+		},
+		{
+			Name:     "ReceiveCuredCase with its claim value changed",
+			Seed:     []*Case{&held},
+			Caller:   lawyer,
+			Function: "ReceiveCuredCase",
+			Args:     []string{string(contracttest.MustJSON(repriced))},
+			WantErr:  "case CASE_001 changes claimValue, only defect cures may change an on-hold case",
+		},
+		{
+			Name:     "ReceiveCuredCase unsealed",
+			Seed:     []*Case{&held},
+			Caller:   lawyer,
+			Function: "ReceiveCuredCase",
+			Args:     []string{string(contracttest.MustJSON(unsealedCure))},
+			WantErr:  "case CASE_001 has no hash chain",
+		},
+		{
+			Name:     "ReceiveCuredCase not on hold",
+			Seed:     []*Case{pending},
+			Caller:   lawyer,
+			Function: "ReceiveCuredCase",
+			Args:     []string{string(contracttest.MustJSON(cured))},
+			WantErr:  "case CASE_001 is PENDING_STAMP_REPORTER_REVIEW, only on-hold cases are returned with defects cured",
+		},
+		{
+			Name:     "ReceiveCuredCase still on hold",
+			Seed:     []*Case{&held},
+			Caller:   lawyer,
+			Function: "ReceiveCuredCase",
+			Args:     []string{string(contracttest.MustJSON(held))},
+			WantErr:  "invalid case status or organization: status=FORWARDED_TO_LAWYER_ON_HOLD, org=LawyersOrg",
+		},
+		{
+			Name:     "ReceiveTransfers",
+			Seed:     []*Case{&held},
+			Peers:    map[string]mockstub.ChaincodeFunc{"lawyer": contracttest.Fake(map[string]peer.Response{"ListPendingTransfers": shim.Success(contracttest.MustJSON([]*casemodel.Transfer{{Sequence: 1, CaseID: "CASE_001", To: casemodel.OrgStampReporters}})), "ClaimTransfer": shim.Success(contracttest.MustJSON(casemodel.Transfer{Sequence: 1, CaseID: "CASE_001", To: casemodel.OrgStampReporters, Case: &cured}))})},
+			Setup:    contract.Reroute(casemodel.HopStampReporterToLawyer, casemodel.Route{Chaincode: "lawyer"}),
+			Caller:   stampReporter,
+			Function: "ReceiveTransfers",
+			Check: func(t *testing.T, n *mockstub.Network, payload []byte) {
+				if string(payload) != `["CASE_001"]` {
+					t.Errorf("received = %s", payload)
+				}
+				if c := stored(t, n, "CASE_001"); c.Status != casemodel.StatusPendingStampReporterReview {
+					t.Errorf("case is %s", c.Status)
+				}
+			},
+		},
+		{
+			Name:     "ReceiveResubmittedCase",
+			Seed:     []*Case{&rejectedHere},
+			Caller:   lawyer,
+			Function: "ReceiveResubmittedCase",
+			Args:     []string{string(contracttest.MustJSON(resubmitted))},
+			Check: func(t *testing.T, n *mockstub.Network, _ []byte) {
+				c := stored(t, n, "CASE_001")
+				if c.Status != casemodel.StatusPendingStampReporterReview || c.Document("DOC_2").Status != casemodel.DocumentSuperseded || c.Document("DOC_2_V2") == nil {
+					t.Errorf("case = %+v", c)
+				}
+				if h := lastHistory(c); h.Status != "RECEIVED_FROM_LAWYER" || h.Comments != "Case resubmitted for review with rejected documents replaced: DOC_2" {
+					t.Errorf("history = %+v", h)
+				}
+			},
+		},
+		{
+			Name:     "ReceiveResubmittedCase with a document not replaced",
+			Seed:     []*Case{&rejectedHere},
+			Caller:   lawyer,
+			Function: "ReceiveResubmittedCase",
+			Args:     []string{string(contracttest.MustJSON(unreplaced))},
+			WantErr:  "document DOC_2 of case CASE_001 is REJECTED and was not replaced",
+		},
+		{
+			Name:     "ReceiveResubmittedCase of an on-hold case",
+			Seed:     []*Case{&held},
+			Caller:   lawyer,
+			Function: "ReceiveResubmittedCase",
+			Args:     []string{string(contracttest.MustJSON(resubmitted))},
+			WantErr:  "case CASE_001 is FORWARDED_TO_LAWYER_ON_HOLD, only rejected cases are resubmitted",
+		},
+		{
+			Name:     "ReceiveCuredCase of a rejected case",
+			Seed:     []*Case{&rejectedHere},
+			Caller:   lawyer,
+			Function: "ReceiveCuredCase",
+			Args:     []string{string(contracttest.MustJSON(resubmitted))},
+			WantErr:  "case CASE_001 is FORWARDED_TO_LAWYER_REJECTED, only on-hold cases are returned with defects cured",
+		},
+		{
+			Name:     "ReceiveTransfers of a resubmitted case",
+			Seed:     []*Case{&rejectedHere},
+			Peers:    map[string]mockstub.ChaincodeFunc{"lawyer": contracttest.Fake(map[string]peer.Response{"ListPendingTransfers": shim.Success(contracttest.MustJSON([]*casemodel.Transfer{{Sequence: 1, CaseID: "CASE_001", To: casemodel.OrgStampReporters}})), "ClaimTransfer": shim.Success(contracttest.MustJSON(casemodel.Transfer{Sequence: 1, CaseID: "CASE_001", To: casemodel.OrgStampReporters, Case: &resubmitted}))})},
+			Setup:    contract.Reroute(casemodel.HopStampReporterToLawyer, casemodel.Route{Chaincode: "lawyer"}),
+			Caller:   stampReporter,
+			Function: "ReceiveTransfers",
+			Check: func(t *testing.T, n *mockstub.Network, _ []byte) {
+				if c := stored(t, n, "CASE_001"); c.Status != casemodel.StatusPendingStampReporterReview || c.Document("DOC_2_V2") == nil {
+					t.Errorf("case = %+v", c)
+				}
+			},
+		},
+		{
+			Name:     "GetCaseDefects",
+			Seed:     []*Case{&cured},
+			Caller:   stampReporter,
+			Function: "GetCaseDefects",
+			Args:     []string{"CASE_001"},
+			Check: func(t *testing.T, _ *mockstub.Network, payload []byte) {
+				var defects []casemodel.Defect
+				contracttest.Decode(t, payload, &defects)
+				if len(defects) != 1 || defects[0].Status != casemodel.DefectCured || defects[0].CureDocumentID != "DOC_2_V2" {
+					t.Errorf("defects = %s", payload)
+				}
+			},
+		},
+		{
+			Name:     "FetchAndStoreCaseFromRegistrarChannel local case",
+			Seed:     []*Case{pending},
+			Caller:   stampReporter,
+			Function: "FetchAndStoreCaseFromRegistrarChannel",
+			Args:     []string{"CASE_001"},
+			Check: func(t *testing.T, n *mockstub.Network, payload []byte) {
+				var c Case
+				contracttest.Decode(t, payload, &c)
+				if c.ID != "CASE_001" || len(c.History) != 0 {
+					t.Errorf("case = %+v", c)
+				}
+			},
+		},
+		{
+			Name:     "FetchAndStoreCaseFromRegistrarChannel sealed by another org",
+			Peers:    map[string]mockstub.ChaincodeFunc{"registrar": contracttest.Fake(map[string]peer.Response{"GetCaseById": shim.Success(contracttest.MustJSON(contract.Sealed(assigned, lawyer, casemodel.ChannelRegistrarStampReporter)))})},
+			Caller:   stampReporter,
+			Function: "FetchAndStoreCaseFromRegistrarChannel",
+			Args:     []string{"CASE_005"},
+			WantErr:  "case CASE_005 was last stored by LawyersOrg, not RegistrarsOrg",
+		},
+		{
+			Name:     "FetchAndStoreCaseFromRegistrarChannel missing case",
+			Peers:    map[string]mockstub.ChaincodeFunc{"registrar": contracttest.Fake(map[string]peer.Response{"GetCaseById": shim.Error("case not found: CASE_404")})},
+			Caller:   stampReporter,
+			Function: "FetchAndStoreCaseFromRegistrarChannel",
+			Args:     []string{"CASE_404"},
+			WantErr:  "Failed to fetch case from registrar channel: case not found: CASE_404",
+		},
+		{
+			Name:     "SyncCaseAcrossChannels",
+			Seed:     []*Case{pending},
+			Caller:   stampReporter,
+			Function: "SyncCaseAcrossChannels",
+			Args:     []string{"CASE_001"},
+			Check: func(t *testing.T, n *mockstub.Network, _ []byte) {
+				if c := stored(t, n, "CASE_001"); lastHistory(c).Status != "CASE_SYNCED" || c.LastModified != stamp {
+					t.Errorf("case = %+v", c)
+				}
+			},
+		},
+		{
+			Name:     "SyncCaseAcrossChannels missing case",
+			Peers:    map[string]mockstub.ChaincodeFunc{"registrar": contracttest.Fake(nil)},
+			Caller:   stampReporter,
+			Function: "SyncCaseAcrossChannels",
+			Args:     []string{"CASE_404"},
+			WantErr:  "failed to fetch case from registrar-stampreporter-channel",
+		},
+		{
+			Name: "GetAllPendingCasesFromRegistrar",
+			Peers: map[string]mockstub.ChaincodeFunc{"registrar": contracttest.Fake(map[string]peer.Response{
+				"GetCasesForStampReporter": shim.Success(contracttest.MustJSON([]*Case{fromRegistrar, contracttest.NewCase("CASE_007", casemodel.StatusPendingStampReporterReview, casemodel.OrgRegistrars)})),
+			})},
+			Caller:   stampReporter,
+			Function: "GetAllPendingCasesFromRegistrar",
+			Check: func(t *testing.T, n *mockstub.Network, _ []byte) {
+				c := stored(t, n, "CASE_005")
+				if c.CurrentOrg != casemodel.OrgStampReporters || lastHistory(c).Status != "TRANSFERRED_TO_STAMPREPORTER" {
+					t.Errorf("case = %+v", c)
+				}
+				if n.GetState(channel, "stampreporter", "CASE_007") != nil {
+					t.Error("case without a hash chain was stored")
+				}
+			},
+		},
+		{
+			Name:     "GetAllPendingCasesFromRegistrar unavailable",
+			Peers:    map[string]mockstub.ChaincodeFunc{"registrar": contracttest.Fake(nil)},
+			Caller:   stampReporter,
+			Function: "GetAllPendingCasesFromRegistrar",
+			WantErr:  "Failed to fetch cases from registrar channel: Function GetCasesForStampReporter not found",
+		},
+		{
+			Name:     "GetAllowedTransitions",
+			Seed:     []*Case{pending},
+			Caller:   stampReporter,
+			Function: "GetAllowedTransitions",
+			Args:     []string{"CASE_001"},
+			Check: func(t *testing.T, _ *mockstub.Network, payload []byte) {
+				var transitions []casemodel.Transition
+				contracttest.Decode(t, payload, &transitions)
+				if len(transitions) != 3 {
+					t.Errorf("transitions = %+v", transitions)
+				}
+			},
+		},
+		{
+			Name:     "VerifyCaseHistory",
+			Seed:     []*Case{chained},
+			Caller:   stampReporter,
+			Function: "VerifyCaseHistory",
+			Args:     []string{"CASE_001"},
+			Check: func(t *testing.T, _ *mockstub.Network, payload []byte) {
+				var v casemodel.HistoryVerification
+				contracttest.Decode(t, payload, &v)
+				if !v.Valid || v.Chained != 2 || v.BrokenAt != -1 {
+					t.Errorf("verification = %+v", v)
+				}
+			},
+		},
+		{
+			Name:     "VerifyCaseHistory edited entry",
+			Seed:     []*Case{rewritten},
+			Caller:   stampReporter,
+			Function: "VerifyCaseHistory",
+			Args:     []string{"CASE_001"},
+			Check: func(t *testing.T, _ *mockstub.Network, payload []byte) {
+				var v casemodel.HistoryVerification
+				contracttest.Decode(t, payload, &v)
+				if v.Valid || v.BrokenAt != 0 || v.Reason != "entry does not match its hash" {
+					t.Errorf("verification = %+v", v)
+				}
+			},
+		},
+		{
+			Name: "GetCaseProvenance",
+			Seed: []*Case{pending},
+			Setup: func(n *mockstub.Network) {
+				if _, err := n.Submit(channel, "stampreporter", stampReporter, "SyncCaseAcrossChannels", "CASE_001"); err != nil {
+					panic(err)
+				}
+				if _, err := n.Submit(channel, "stampreporter", stampReporter, "ValidateDocuments", "CASE_001", `{"stampReporterId":"SR001","rejectionReason":"Unsigned vakalatnama","validations":[{"documentId":"DOC_1","verdict":"REJECTED","reasonCode":"UNSIGNED"}]}`); err != nil {
+					panic(err)
+				}
+			},
+			Caller:   stampReporter,
+			Function: "GetCaseProvenance",
+			Args:     []string{"CASE_001"},
+			Check: func(t *testing.T, _ *mockstub.Network, payload []byte) {
+				// seeding bypasses the ledger history, so only the two transactions are in it
+				var provenance casemodel.CaseProvenance
+				contracttest.Decode(t, payload, &provenance)
+				if len(provenance.Versions) != 2 {
+					t.Fatalf("versions = %+v", provenance.Versions)
+				}
+				if v := provenance.Versions[1]; contracttest.Changed(v, "status").After != string(contracttest.MustJSON(casemodel.StatusRejectedByStampReporter)) {
+					t.Errorf("changes = %+v", v.Changes)
+				}
+			},
+		},
+		{
+			Name:     "GetCaseProvenance missing case",
+			Caller:   stampReporter,
+			Function: "GetCaseProvenance",
+			Args:     []string{"CASE_404"},
+			WantErr:  "case does not exist: CASE_404",
+		},
+		{
+			Name:     "GetRoutingConfig defaults",
+			Caller:   stampReporter,
+			Function: "GetRoutingConfig",
+			Check: func(t *testing.T, _ *mockstub.Network, payload []byte) {
+				var config casemodel.RoutingConfig
+				contracttest.Decode(t, payload, &config)
+				if route := config.Routes[casemodel.HopStampReporterToBenchClerk]; route != (casemodel.Route{Chaincode: "benchclerk", Channel: casemodel.ChannelStampReporterBenchClerk}) {
+					t.Errorf("route = %+v", route)
+				}
+			},
+		},
+		{
+			Name:     "SetRoutingConfig",
+			Caller:   stampReporterAdmin,
+			Function: "SetRoutingConfig",
+			Args:     []string{routing},
+			Check: func(t *testing.T, n *mockstub.Network, _ []byte) {
+				payload, err := n.Evaluate(channel, "stampreporter", stampReporter, "GetRoutingConfig")
+				if err != nil {
+					t.Fatal(err)
+				}
+				var config casemodel.RoutingConfig
+				contracttest.Decode(t, payload, &config)
+				if route := config.Routes[casemodel.HopStampReporterToBenchClerk]; route.Chaincode != "benchclerk-v2" {
+					t.Errorf("route = %+v", route)
+				}
+			},
+		},
+		{
+			Name:     "SetRoutingConfig without a route the contract uses",
+			Caller:   stampReporterAdmin,
+			Function: "SetRoutingConfig",
+			Args:     []string{`{"routes":{}}`},
+			WantErr:  "invalid routing config: route stampreporter->registrar is not configured",
+		},
+		{
+			Name:     "SetFeeSchedule",
+			Caller:   stampReporterAdmin,
+			Function: "SetFeeSchedule",
+			Args:     []string{civilFees},
+			Check: func(t *testing.T, n *mockstub.Network, _ []byte) {
+				payload, err := n.Evaluate(channel, "stampreporter", stampReporter, "GetFeeSchedule", "Civil")
+				if err != nil {
+					t.Fatal(err)
+				}
+				var schedule casemodel.FeeSchedule
+				contracttest.Decode(t, payload, &schedule)
+				if len(schedule.Slabs) != 2 || schedule.StampDuty != 5000 || schedule.UpdatedAt != stamp {
+					t.Errorf("schedule = %+v", schedule)
+				}
+			},
+		},
+		{
+			Name:     "SetFeeSchedule with an open-ended middle slab",
+			Caller:   stampReporterAdmin,
+			Function: "SetFeeSchedule",
+			Args:     []string{`{"caseType":"Civil","slabs":[{"upTo":0,"rate":100},{"upTo":10000000,"rate":50}]}`},
+			WantErr:  "slab 0 of the Civil fee schedule has no upper bound",
+		},
+		{
+			Name:     "SetFeeSchedule by a stamp reporter who is not an administrator",
+			Caller:   stampReporter,
+			Function: "SetFeeSchedule",
+			Args:     []string{civilFees},
+			WantErr:  "access denied for SetFeeSchedule",
+		},
+		{
+			Name:     "SetFeeSchedule by lawyer",
+			Caller:   lawyer,
+			Function: "SetFeeSchedule",
+			Args:     []string{civilFees},
+			WantErr:  "access denied for SetFeeSchedule",
+		},
+		{
+			Name:     "GetFeeSchedule missing",
+			Caller:   stampReporter,
+			Function: "GetFeeSchedule",
+			Args:     []string{"Criminal"},
+			WantErr:  `no fee schedule for case type "Criminal"`,
+		},
+		{
+			Name:     "CalculateCourtFee",
+			Seed:     []*Case{validated},
+			Setup:    administered("SetFeeSchedule", civilFees),
+			Caller:   stampReporter,
+			Function: "CalculateCourtFee",
+			Args:     []string{"CASE_002"},
+			Check: func(t *testing.T, _ *mockstub.Network, payload []byte) {
+				var assessment casemodel.FeeAssessment
+				contracttest.Decode(t, payload, &assessment)
+				if assessment.CourtFee != 50000 || assessment.Total != 55000 || assessment.Balance != 55000 || len(assessment.Payments) != 0 {
+					t.Errorf("assessment = %s", payload)
+				}
+			},
+		},
+		{
+			Name:     "RecordFeePayment",
+			Seed:     []*Case{validated},
+			Setup:    administered("SetFeeSchedule", civilFees),
+			Caller:   stampReporter,
+			Function: "RecordFeePayment",
+			Args:     []string{"CASE_002", "GRN001", "20000"},
+			Check: func(t *testing.T, n *mockstub.Network, _ []byte) {
+				c := stored(t, n, "CASE_002")
+				if c.Fees == nil || c.Fees.Paid != 20000 || c.Fees.Balance != 35000 || len(c.Fees.Payments) != 1 || c.Fees.Payments[0].ReceiptRef != "GRN001" {
+					t.Errorf("fees = %+v", c.Fees)
+				}
+			},
+		},
+		{
+			Name: "RecordFeePayment with a receipt already recorded",
+			Seed: []*Case{validated},
+			Setup: func(n *mockstub.Network) {
+				administered("SetFeeSchedule", civilFees)(n)
+				submitted("RecordFeePayment", "CASE_002", "GRN001", "20000")(n)
+			},
+			Caller:   stampReporter,
+			Function: "RecordFeePayment",
+			Args:     []string{"CASE_002", "GRN001", "20000"},
+			WantErr:  "receipt GRN001 is already recorded against case CASE_002",
+		},
+		{
+			Name:     "RecordFeePayment of nothing",
+			Seed:     []*Case{validated},
+			Setup:    administered("SetFeeSchedule", civilFees),
+			Caller:   stampReporter,
+			Function: "RecordFeePayment",
+			Args:     []string{"CASE_002", "GRN001", "0"},
+			WantErr:  "fee payment GRN001 must have a positive amount",
+		},
+		{
+			Name:     "RecordFeePayment on a rejected case",
+			Seed:     []*Case{rejected},
+			Caller:   stampReporter,
+			Function: "RecordFeePayment",
+			Args:     []string{"CASE_003", "GRN001", "20000"},
+			WantErr:  "case CASE_003 is REJECTED_BY_STAMP_REPORTER, fees can only be recorded before it is forwarded",
+		},
+		{
+			Name:     "RecordFeePayment by lawyer",
+			Caller:   lawyer,
+			Function: "RecordFeePayment",
+			Args:     []string{"CASE_002", "GRN001", "20000"},
+			WantErr:  "access denied for RecordFeePayment",
+		},
+		{
+			Name:     "SetRoutingConfig by another organization",
+			Caller:   benchClerk,
+			Function: "SetRoutingConfig",
+			Args:     []string{routing},
+			WantErr:  "access denied for SetRoutingConfig",
+		},
+	}
+	contract.Run(t, tests)
+}
@@ -0,0 +1,20 @@
+package main
+
+import (
+	"log"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+
+	"stampreporter/chaincode"
+)
+
+func main() {
+	stampReporterChaincode, err := contractapi.NewChaincode(chaincode.New())
+	if err != nil {
+		log.Panicf("Error creating stamp reporter chaincode: %v", err)
+	}
+
+	if err := stampReporterChaincode.Start(); err != nil {
+		log.Panicf("Error starting stamp reporter chaincode: %v", err)
+	}
+}
@@ -0,0 +1,44 @@
+// Command simulate runs a case through the whole eVAULT workflow on an in-memory network
+// and prints the judgment the lawyer reads at the end.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"time"
+
+	"simulator"
+)
+
+func main() {
+	topologyPath := flag.String("topology", "../../MICROFAB.txt", "Microfab configuration describing the network")
+	caseID := flag.String("case", "CASE_001", "ID of the case to file")
+	verbose := flag.Bool("v", false, "show the contracts' logs")
+	flag.Parse()
+
+	if !*verbose {
+		log.SetOutput(io.Discard)
+	}
+
+	topology, err := simulator.ReadTopology(*topologyPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	s, err := simulator.New(topology, time.Now())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	s.Trace = os.Stdout
+
+	judgment, err := s.Run(simulator.HappyPath(*caseID)...)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	fmt.Printf("\njudgment: %s\n", judgment)
+}
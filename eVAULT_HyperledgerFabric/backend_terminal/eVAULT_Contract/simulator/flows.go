@@ -0,0 +1,307 @@
+package simulator
+
+import (
+	"crypto/ecdsa"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+
+	"casemodel"
+	"casemodel/mockstub"
+)
+
+// salts are the random salts of the records the lawyer files a case's private details in,
+// one for each collection. Each goes wherever the record of its collection goes.
+var salts = func() map[string]string {
+	salts, err := casemodel.NewSalts()
+	if err != nil {
+		panic(err)
+	}
+	return salts
+}()
+
+// Private details the lawyer passes with a filing, kept out of the public case
+var (
+	filingDetails  = fmt.Sprintf(`{"uidParty1":"UID-4471","uidParty2":"UID-9032","clientName":"A. Kumar","description":"Boundary wall encroaching on plot 12","salts":{"sealedDetails":%q,"partyIdentities":%q}}`, salts[casemodel.CollectionSealedDetails], salts[casemodel.CollectionPartyIdentities])
+	partyDetails   = fmt.Sprintf(`{"uidParty1":"UID-4471","uidParty2":"UID-9032","salts":{"partyIdentities":%q}}`, salts[casemodel.CollectionPartyIdentities])
+	documentHashes = `{"documentHashes":{"DOC_1":"9f2c"}}`
+)
+
+// sealedDetails are the lawyer's sealed details of a filed case, which the lawyer reads
+// back with GetCasePrivateDetails and hands the judge off the ledger. They hold the
+// plaint's content hash, which the public case only carries the digest of.
+var sealedDetails = fmt.Sprintf(`{"clientName":"A. Kumar","description":"Boundary wall encroaching on plot 12","documentHashes":{"DOC_1":"9f2c"},"contentHashes":{"DOC_1":%q},"salts":{"sealedDetails":%q}}`, casemodel.ContentHash(Plaint), salts[casemodel.CollectionSealedDetails])
+
+// CivilFees is the court fee schedule the stamp reporter keeps for civil cases: 1% of
+// claims up to ₹1,00,000 and ₹1,000 plus 0.5% of the rest, with ₹50 stamp duty. The
+// ₹50,000 claim of a filed case owes ₹550. Amounts are in paise.
+const CivilFees = `{"caseType":"Civil","slabs":[{"upTo":10000000,"fixed":0,"rate":100},{"upTo":0,"fixed":100000,"rate":50}],"stampDuty":5000}`
+
+// Plaint is the file the lawyer files with every case. Its content digest is anchored on
+// the ledger with the document.
+var Plaint = []byte("%PDF-1.4 plaint: boundary wall encroaching on plot 12")
+
+// CorrectedPlaint is the plaint the lawyer files to cure a defect the stamp reporter raised
+var CorrectedPlaint = []byte("%PDF-1.4 plaint: boundary wall encroaching on plot 12, signed on every page")
+
+// StampedPlaint is the plaint the lawyer files in place of one the stamp reporter rejected
+// for an insufficient court fee
+var StampedPlaint = []byte("%PDF-1.4 plaint: boundary wall encroaching on plot 12, court fee stamps affixed")
+
+// Vakalatnama is the authorisation the lawyer files when resubmitting a case the registrar
+// rejected for lacking it
+var Vakalatnama = []byte("%PDF-1.4 vakalatnama: A. Kumar authorises advocate L001")
+
+// Sign returns the base64 signature a stamp reporter submits for a file: an ECDSA
+// signature over the SHA-256 its content digest encodes, see casemodel.Document.Anchor
+func Sign(key *ecdsa.PrivateKey, content []byte) string {
+	digest, _ := hex.DecodeString(casemodel.ContentDigest(casemodel.ContentHash(content)))
+	sig, err := ecdsa.SignASN1(rand.Reader, key, digest)
+	if err != nil {
+		panic(fmt.Sprintf("failed to sign: %v", err))
+	}
+	return base64.StdEncoding.EncodeToString(sig)
+}
+
+// Step is one transaction sent by a client
+type Step struct {
+	Name      string
+	Channel   string
+	Chaincode string
+	Identity  mockstub.Identity
+	Function  string
+	Args      []string
+	Private   string // casemodel.CasePrivateDetails passed in the transient field
+	Evaluate  bool   // run as a query, without committing
+}
+
+// Run sends steps in order and returns the payload of the last one. It stops at the
+// first step that fails.
+func (s *Simulator) Run(steps ...Step) ([]byte, error) {
+	var payload []byte
+	for _, step := range steps {
+		var err error
+		if step.Evaluate {
+			payload, err = s.Evaluate(step.Channel, step.Chaincode, step.Identity, step.Function, step.Args...)
+		} else if step.Private != "" {
+			transient := map[string][]byte{casemodel.TransientPrivateDetails: []byte(step.Private)}
+			payload, err = s.SubmitTransient(step.Channel, step.Chaincode, step.Identity, transient, step.Function, step.Args...)
+		} else {
+			payload, err = s.Submit(step.Channel, step.Chaincode, step.Identity, step.Function, step.Args...)
+		}
+		if err != nil {
+			s.trace("FAIL %s: %v", step.Name, err)
+			return nil, fmt.Errorf("%s: %v", step.Name, err)
+		}
+		s.trace("ok   %s (%s.%s on %s)", step.Name, step.Chaincode, step.Function, step.Channel)
+	}
+	return payload, nil
+}
+
+func (s *Simulator) trace(format string, args ...interface{}) {
+	if s.Trace != nil {
+		fmt.Fprintf(s.Trace, format+"\n", args...)
+	}
+}
+
+// File creates a case with one document and submits it to the registrar, passing the
+// party identities on for the registrar's private data
+func File(caseID string) []Step {
+	return []Step{
+		{
+			Name: "lawyer files the case", Channel: LawyerRegistrarChannel, Chaincode: "lawyer", Identity: Lawyer,
+			Function: "CreateCase",
+			Args:     []string{fmt.Sprintf(`{"id":%q,"caseNumber":"CS/%s","title":"Land dispute","type":"Civil","caseSubject":"Property","claimValue":5000000}`, caseID, caseID)},
+			Private:  filingDetails,
+		},
+		{
+			Name: "lawyer attaches the plaint", Channel: LawyerRegistrarChannel, Chaincode: "lawyer", Identity: Lawyer,
+			Function: "AddDocumentToCase",
+			Args:     []string{caseID, fmt.Sprintf(`{"id":"DOC_1","name":"plaint.pdf","type":"PLAINT","contentHash":%q}`, casemodel.ContentHash(Plaint))},
+			Private:  documentHashes,
+		},
+		{
+			Name: "lawyer submits to the registrar", Channel: LawyerRegistrarChannel, Chaincode: "lawyer", Identity: Lawyer,
+			Function: "SubmitToRegistrar", Args: []string{caseID}, Private: partyDetails,
+		},
+	}
+}
+
+// Registration files a case, has the registrar verify it and assigns it to the stamp reporter
+func Registration(caseID string) []Step {
+	return append(File(caseID),
+		Step{
+			Name: "registrar verifies the case", Channel: LawyerRegistrarChannel, Chaincode: "registrar", Identity: Registrar,
+			Function: "VerifyCase", Args: []string{caseID, `{"isVerified":true,"comments":"Filing complete","department":"Civil"}`},
+		},
+		Step{
+			Name: "registrar assigns the case to the stamp reporter", Channel: RegistrarStampReporterChannel, Chaincode: "registrar", Identity: Registrar,
+			Function: "FetchAndStoreCaseFromLawyerChannel", Args: []string{caseID},
+		},
+		Step{
+			Name: "registrar syncs its copy on the lawyer channel", Channel: LawyerRegistrarChannel, Chaincode: "registrar", Identity: Registrar,
+			Function: "ReceiveTransfers",
+		},
+	)
+}
+
+// Validation has the stamp reporter validate a registered case's documents, record the
+// fees paid on it and hand it to the bench clerk
+func Validation(caseID string) []Step {
+	return []Step{
+		{
+			Name: "stamp reporter validates the documents", Channel: StampReporterBenchClerkChannel, Chaincode: "stampreporter", Identity: StampReporter,
+			Function: "ValidateDocuments",
+			Args:     []string{caseID, fmt.Sprintf(`{"comments":"Court fee paid","stampReporterId":"SR001","validations":[{"documentId":"DOC_1","verdict":"ACCEPTED","signature":%q}]}`, Sign(StampReporterKey, Plaint))},
+		},
+		{
+			Name: "stamp reporters' administrator sets the civil fee schedule", Channel: StampReporterBenchClerkChannel, Chaincode: "stampreporter", Identity: administrator(StampReporter),
+			Function: "SetFeeSchedule", Args: []string{CivilFees},
+		},
+		{
+			Name: "stamp reporter records the fee receipt", Channel: StampReporterBenchClerkChannel, Chaincode: "stampreporter", Identity: StampReporter,
+			Function: "RecordFeePayment", Args: []string{caseID, "GRN/" + caseID, "55000"},
+		},
+		{
+			Name: "stamp reporter forwards the case to the bench clerk", Channel: StampReporterBenchClerkChannel, Chaincode: "stampreporter", Identity: StampReporter,
+			Function: "ForwardCaseToBenchClerk", Args: []string{caseID},
+		},
+	}
+}
+
+// Adjudication has the bench clerk list a validated case before the judge, the judge
+// decide it, and the decision return to the lawyer, who reads it
+func Adjudication(caseID string) []Step {
+	return []Step{
+		{
+			Name: "bench clerk assigns the judge", Channel: BenchClerkJudgeChannel, Chaincode: "benchclerk", Identity: BenchClerk,
+			Function: "ForwardToJudge", Args: []string{caseID, `{"judgeId":"J001","comments":"Listed for hearing"}`},
+		},
+		{
+			Name: "bench clerk schedules the hearing", Channel: BenchClerkJudgeChannel, Chaincode: "benchclerk", Identity: BenchClerk,
+			Function: "UpdateHearingDetails", Args: []string{caseID, `{"hearingDate":"2024-06-03","comments":"Courtroom 4"}`},
+		},
+		{
+			Name: "judge records the judgment", Channel: BenchClerkJudgeChannel, Chaincode: "judge", Identity: Judge,
+			Function: "RecordJudgment", Args: []string{caseID, `{"decision":"Suit decreed","reasoning":"Title proved by registered sale deed"}`},
+			Private: sealedDetails,
+		},
+		{
+			Name: "judge returns the case to the bench clerk", Channel: BenchClerkJudgeChannel, Chaincode: "judge", Identity: Judge,
+			Function: "ForwardCaseToBenchClerk", Args: []string{caseID},
+		},
+		{
+			Name: "bench clerk collects the judgment", Channel: BenchClerkLawyerChannel, Chaincode: "benchclerk", Identity: BenchClerk,
+			Function: "FetchAndStoreCaseFromJudgeChannel",
+		},
+		{
+			Name: "bench clerk forwards the decision to the lawyer", Channel: BenchClerkLawyerChannel, Chaincode: "benchclerk", Identity: BenchClerk,
+			Function: "ForwardCaseToLawyer", Args: []string{caseID},
+		},
+		{
+			Name: "lawyer reads the judgment", Channel: BenchClerkLawyerChannel, Chaincode: "lawyer", Identity: Lawyer,
+			Function: "ViewJudgmentDetails", Args: []string{caseID}, Evaluate: true,
+		},
+	}
+}
+
+// HappyPath takes a case from filing to the lawyer reading its judgment
+func HappyPath(caseID string) []Step {
+	steps := Registration(caseID)
+	steps = append(steps, Validation(caseID)...)
+	return append(steps, Adjudication(caseID)...)
+}
+
+// RegistrarRejection files a case that the registrar rejects
+func RegistrarRejection(caseID string) []Step {
+	return append(File(caseID), Step{
+		Name: "registrar rejects the case", Channel: LawyerRegistrarChannel, Chaincode: "registrar", Identity: Registrar,
+		Function: "VerifyCase", Args: []string{caseID, `{"isVerified":false,"comments":"Vakalatnama missing"}`},
+	})
+}
+
+// Resubmission files a case that the registrar rejects for a missing vakalatnama, has the
+// lawyer attach it and resubmit the case, and the registrar verify it
+func Resubmission(caseID string) []Step {
+	return append(RegistrarRejection(caseID),
+		Step{
+			Name: "lawyer attaches the vakalatnama", Channel: LawyerRegistrarChannel, Chaincode: "lawyer", Identity: Lawyer,
+			Function: "AddDocumentToCase",
+			Args:     []string{caseID, fmt.Sprintf(`{"id":"DOC_2","name":"vakalatnama.pdf","type":"VAKALATNAMA","contentHash":%q}`, casemodel.ContentHash(Vakalatnama))},
+			Private:  `{"documentHashes":{"DOC_2":"7a31"}}`,
+		},
+		Step{
+			Name: "lawyer resubmits to the registrar", Channel: LawyerRegistrarChannel, Chaincode: "lawyer", Identity: Lawyer,
+			Function: "ResubmitToRegistrar", Args: []string{caseID}, Private: partyDetails,
+		},
+		Step{
+			Name: "registrar verifies the resubmitted case", Channel: LawyerRegistrarChannel, Chaincode: "registrar", Identity: Registrar,
+			Function: "VerifyCase", Args: []string{caseID, `{"isVerified":true,"comments":"Vakalatnama filed","department":"Civil"}`},
+		},
+	)
+}
+
+// StampReporterRejection registers a case that the stamp reporter rejects, and has the
+// lawyer collect the rejection
+func StampReporterRejection(caseID string) []Step {
+	return append(Registration(caseID),
+		Step{
+			Name: "stamp reporter rejects the documents", Channel: StampReporterLawyerChannel, Chaincode: "stampreporter", Identity: StampReporter,
+			Function: "ValidateDocuments", Args: []string{caseID, `{"stampReporterId":"SR001","rejectionReason":"Insufficient court fee","validations":[{"documentId":"DOC_1","verdict":"REJECTED","reasonCode":"COURT_FEE_DEFICIT"}]}`},
+		},
+		Step{
+			Name: "lawyer collects returned cases", Channel: StampReporterLawyerChannel, Chaincode: "lawyer", Identity: Lawyer,
+			Function: "FetchAndStoreCaseFromStampReporterChannel",
+		},
+	)
+}
+
+// StampReporterResubmission registers a case that the stamp reporter rejects, has the
+// lawyer collect the rejection, replace the rejected plaint and resubmit the case, and the
+// stamp reporter accept the new plaint
+func StampReporterResubmission(caseID string) []Step {
+	return append(StampReporterRejection(caseID),
+		Step{
+			Name: "lawyer replaces the rejected plaint", Channel: StampReporterLawyerChannel, Chaincode: "lawyer", Identity: Lawyer,
+			Function: "ReplaceDocument",
+			Args:     []string{caseID, "DOC_1", fmt.Sprintf(`{"id":"DOC_1_V2","name":"plaint.pdf","type":"PLAINT","contentHash":%q}`, casemodel.ContentHash(StampedPlaint))},
+			Private:  fmt.Sprintf(`{"documentHashes":{"DOC_1_V2":"c5d0"},"salts":{"sealedDetails":%q}}`, salts[casemodel.CollectionSealedDetails]),
+		},
+		Step{
+			Name: "lawyer resubmits to the stamp reporter", Channel: StampReporterLawyerChannel, Chaincode: "lawyer", Identity: Lawyer,
+			Function: "ResubmitToStampReporter", Args: []string{caseID, "Court fee stamps affixed"},
+		},
+		Step{
+			Name: "stamp reporter validates the new plaint", Channel: StampReporterLawyerChannel, Chaincode: "stampreporter", Identity: StampReporter,
+			Function: "ValidateDocuments",
+			Args:     []string{caseID, fmt.Sprintf(`{"comments":"Court fee paid","stampReporterId":"SR001","validations":[{"documentId":"DOC_1_V2","verdict":"ACCEPTED","signature":%q}]}`, Sign(StampReporterKey, StampedPlaint))},
+		},
+	)
+}
+
+// DefectCuring registers a case that the stamp reporter puts on hold with a defect in the
+// plaint, has the lawyer cure it with a corrected plaint and the stamp reporter accept it
+func DefectCuring(caseID string) []Step {
+	return append(Registration(caseID),
+		Step{
+			Name: "stamp reporter sends the plaint back for correction", Channel: StampReporterLawyerChannel, Chaincode: "stampreporter", Identity: StampReporter,
+			Function: "ValidateDocuments", Args: []string{caseID, `{"stampReporterId":"SR001","validations":[{"documentId":"DOC_1","verdict":"NEEDS_CORRECTION","reasonCode":"UNSIGNED","comments":"Plaint not signed on every page"}]}`},
+		},
+		Step{
+			Name: "stamp reporter forwards the case to the lawyer", Channel: StampReporterLawyerChannel, Chaincode: "stampreporter", Identity: StampReporter,
+			Function: "ForwardCaseToLawyer", Args: []string{caseID},
+		},
+		Step{
+			Name: "lawyer cures the defect", Channel: StampReporterLawyerChannel, Chaincode: "lawyer", Identity: Lawyer,
+			Function: "CureDefects",
+			Args:     []string{caseID, fmt.Sprintf(`{"cures":[{"defectId":"DEF_1","document":{"id":"DOC_1_V2","name":"plaint.pdf","type":"PLAINT","contentHash":%q},"comments":"Signed on every page"}]}`, casemodel.ContentHash(CorrectedPlaint))},
+			Private:  fmt.Sprintf(`{"documentHashes":{"DOC_1_V2":"4b1e"},"salts":{"sealedDetails":%q}}`, salts[casemodel.CollectionSealedDetails]),
+		},
+		Step{
+			Name: "stamp reporter validates the corrected plaint", Channel: StampReporterLawyerChannel, Chaincode: "stampreporter", Identity: StampReporter,
+			Function: "ValidateDocuments",
+			Args:     []string{caseID, fmt.Sprintf(`{"comments":"Defects cured","stampReporterId":"SR001","validations":[{"documentId":"DOC_1_V2","verdict":"ACCEPTED","signature":%q}]}`, Sign(StampReporterKey, CorrectedPlaint))},
+		},
+	)
+}
@@ -0,0 +1,252 @@
+// Package simulator runs the five eVAULT contracts together on an in-memory network laid
+// out like the Microfab network in MICROFAB.txt, so the whole case workflow can be
+// tested and demonstrated without a live network:
+//
+//   - each organization's chaincode is installed only on the channels it has joined
+//   - a client can only submit on a channel its organization has joined
+//   - a call to a channel that is not in the topology fails, as it would on a peer
+//
+// The network follows Fabric's rules for calls between chaincodes (see casemodel/mockstub):
+// writes made by a chaincode invoked on another channel are dropped. Flows therefore
+// have each organization act on the channel it shares with the organization it hands
+// the case to, and pull the case from the previous organization's channel.
+package simulator
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+
+	benchclerk "benchclerk/chaincode"
+	"casemodel"
+	"casemodel/access"
+	"casemodel/mockstub"
+	judge "judge/chaincode"
+	lawyer "lawyer/chaincode"
+	registrar "registrar/chaincode"
+	stampreporter "stampreporter/chaincode"
+)
+
+// Channels of the Microfab network
+const (
+	LawyerRegistrarChannel         = casemodel.ChannelLawyerRegistrar
+	RegistrarStampReporterChannel  = casemodel.ChannelRegistrarStampReporter
+	StampReporterLawyerChannel     = casemodel.ChannelStampReporterLawyer
+	StampReporterBenchClerkChannel = casemodel.ChannelStampReporterBenchClerk
+	BenchClerkJudgeChannel         = casemodel.ChannelBenchClerkJudge
+	BenchClerkLawyerChannel        = casemodel.ChannelBenchClerkLawyer
+)
+
+// deployments is the chaincode each organization deploys, and the member who initializes it
+var deployments = []struct {
+	org       string
+	chaincode string
+	contract  func() contractapi.ContractInterface
+	admin     mockstub.Identity
+}{
+	{casemodel.OrgLawyers, "lawyer", func() contractapi.ContractInterface { return lawyer.New() }, administrator(Lawyer)},
+	{casemodel.OrgRegistrars, "registrar", func() contractapi.ContractInterface { return registrar.New() }, administrator(Registrar)},
+	{casemodel.OrgStampReporters, "stampreporter", func() contractapi.ContractInterface { return stampreporter.New() }, administrator(StampReporter)},
+	{casemodel.OrgBenchClerks, "benchclerk", func() contractapi.ContractInterface { return benchclerk.New() }, administrator(BenchClerk)},
+	{casemodel.OrgJudges, "judge", func() contractapi.ContractInterface { return judge.New() }, administrator(Judge)},
+}
+
+// StampReporterKey is the key the stamp reporter's certificate is issued for, which it
+// signs documents with
+var StampReporterKey, _ = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+
+// Client identities, one per organization, with the attributes their CA enrolls
+var (
+	Lawyer        = mockstub.Identity{MSPID: "LawyersOrgMSP", Name: "lawyer1", Attrs: map[string]string{access.AttrRole: access.RoleLawyer, access.AttrLawyerID: "L001"}}
+	Registrar     = mockstub.Identity{MSPID: "RegistrarsOrgMSP", Name: "registrar1", Attrs: map[string]string{access.AttrRole: access.RoleRegistrar}}
+	StampReporter = mockstub.Identity{MSPID: "StampReportersOrgMSP", Name: "stampreporter1", Attrs: map[string]string{access.AttrRole: access.RoleStampReporter}, Key: StampReporterKey}
+	BenchClerk    = mockstub.Identity{MSPID: "BenchClerksOrgMSP", Name: "benchclerk1", Attrs: map[string]string{access.AttrRole: access.RoleBenchClerk}}
+	Judge         = mockstub.Identity{MSPID: "JudgesOrgMSP", Name: "judge1", Attrs: map[string]string{access.AttrRole: access.RoleJudge, access.AttrJudgeID: "J001"}}
+)
+
+// administrator returns an administrator of id's organization with the same attributes
+func administrator(id mockstub.Identity) mockstub.Identity {
+	attrs := map[string]string{access.AttrAdmin: "true"}
+	for name, value := range id.Attrs {
+		attrs[name] = value
+	}
+	return mockstub.Identity{MSPID: id.MSPID, Name: id.Name + "-admin", Attrs: attrs}
+}
+
+// Simulator is an in-memory eVAULT network
+type Simulator struct {
+	*mockstub.Network
+	Topology *Topology
+	// Trace receives a line for each step Run submits, when set
+	Trace io.Writer
+}
+
+// New deploys every organization's chaincode on the channels it has joined in topology
+// and initializes it with the default routes. Clients seal the cases their transactions
+// hand off. The network's clock starts at start.
+func New(topology *Topology, start time.Time) (*Simulator, error) {
+	s := &Simulator{Network: mockstub.NewNetwork(start), Topology: topology}
+	s.SignWrites = casemodel.SignCaseSeals
+	for _, d := range deployments {
+		channels := topology.ChannelsOf(d.org)
+		if len(channels) == 0 {
+			return nil, fmt.Errorf("organization %s has not joined any channel", d.org)
+		}
+		chaincode, err := contractapi.NewChaincode(d.contract())
+		if err != nil {
+			return nil, fmt.Errorf("failed to create %s chaincode: %v", d.chaincode, err)
+		}
+		s.Install(d.chaincode, chaincode, channels...)
+		// Each chaincode defines the private data collections of its organization, as in
+		// its collections_config.json
+		for _, collection := range casemodel.PrivateCollections {
+			for _, org := range collection.Orgs {
+				if org != d.org {
+					continue
+				}
+				for _, channel := range channels {
+					s.DefineCollection(channel, d.chaincode, collection.Name, org+"MSP")
+				}
+			}
+		}
+	}
+	s.Route = s.route
+	admins := make(map[string]mockstub.Identity)
+	for _, d := range deployments {
+		admins[d.org] = d.admin
+	}
+	for _, d := range deployments {
+		for _, channel := range topology.ChannelsOf(d.org) {
+			if _, err := s.Submit(channel, d.chaincode, d.admin, "InitLedger", ""); err != nil {
+				return nil, fmt.Errorf("failed to initialize %s on %s: %v", d.chaincode, channel, err)
+			}
+			// each member's administrator has its CA trusted on the channel, as on a real network
+			for _, org := range topology.Channel(channel).Organizations {
+				if _, err := s.Submit(channel, d.chaincode, admins[org], "SetOrgRoots", org, mockstub.RootCertificate(org+"MSP")); err != nil {
+					return nil, fmt.Errorf("failed to trust the CA of %s on %s: %v", org, channel, err)
+				}
+			}
+		}
+	}
+	// the CAs of organizations that have not joined a channel are imported from one they have
+	for _, d := range deployments {
+		for _, channel := range topology.ChannelsOf(d.org) {
+			for _, org := range casemodel.Organizations() {
+				hop := s.rootsHop(d.org, org)
+				if topology.Channel(channel).HasMember(org) || hop == "" {
+					continue
+				}
+				if _, err := s.Submit(channel, d.chaincode, d.admin, "ImportOrgRoots", org, hop); err != nil {
+					return nil, fmt.Errorf("failed to import the CA of %s on %s: %v", org, channel, err)
+				}
+			}
+		}
+	}
+	s.SetNow(start)
+	return s, nil
+}
+
+// rootsHop returns a default route from's chaincode can read org's roots through, one to a
+// channel both have joined, or "" if there is none
+func (s *Simulator) rootsHop(from string, org string) string {
+	routes := casemodel.DefaultRouting().Routes
+	hops := make([]string, 0, len(routes))
+	for hop := range routes {
+		hops = append(hops, hop)
+	}
+	sort.Strings(hops)
+	for _, hop := range hops {
+		channel := s.Topology.Channel(routes[hop].Channel)
+		if channel == nil || !channel.HasMember(from) || !channel.HasMember(org) {
+			continue
+		}
+		if _, err := s.Installed(routes[hop].Chaincode, routes[hop].Channel); err == nil {
+			return hop
+		}
+	}
+	return ""
+}
+
+// route finds the chaincode for a call, failing on channels that are not in the topology
+func (s *Simulator) route(name string, channel string) (shim.Chaincode, error) {
+	if s.Topology.Channel(channel) == nil {
+		return nil, fmt.Errorf("channel %s does not exist", channel)
+	}
+	return s.Installed(name, channel)
+}
+
+// Submit sends a transaction as id, whose organization must have joined channel
+func (s *Simulator) Submit(channel string, chaincode string, id mockstub.Identity, function string, args ...string) ([]byte, error) {
+	if err := s.checkMember(channel, id); err != nil {
+		return nil, err
+	}
+	return s.Network.Submit(channel, chaincode, id, function, args...)
+}
+
+// SubmitTransient sends a transaction with transient data as id, whose organization must
+// have joined channel
+func (s *Simulator) SubmitTransient(channel string, chaincode string, id mockstub.Identity, transient map[string][]byte, function string, args ...string) ([]byte, error) {
+	if err := s.checkMember(channel, id); err != nil {
+		return nil, err
+	}
+	return s.Network.SubmitTransient(channel, chaincode, id, transient, function, args...)
+}
+
+// Evaluate runs a query as id, whose organization must have joined channel
+func (s *Simulator) Evaluate(channel string, chaincode string, id mockstub.Identity, function string, args ...string) ([]byte, error) {
+	if err := s.checkMember(channel, id); err != nil {
+		return nil, err
+	}
+	return s.Network.Evaluate(channel, chaincode, id, function, args...)
+}
+
+// checkMember fails unless id's organization has joined channel
+func (s *Simulator) checkMember(channel string, id mockstub.Identity) error {
+	c := s.Topology.Channel(channel)
+	if c == nil {
+		return fmt.Errorf("channel %s does not exist", channel)
+	}
+	org := casemodel.OrgFromMSPID(id.MSPID)
+	if !c.HasMember(org) {
+		return fmt.Errorf("%s is not a member of channel %s", org, channel)
+	}
+	return nil
+}
+
+// Case returns the committed copy of a case held by chaincode on channel
+func (s *Simulator) Case(channel string, chaincode string, caseID string) (*casemodel.Case, error) {
+	value := s.GetState(channel, chaincode, caseID)
+	if value == nil {
+		return nil, fmt.Errorf("case %s is not in the %s ledger on %s", caseID, chaincode, channel)
+	}
+	return casemodel.DecodeCase(value)
+}
+
+// PutCase writes a case into chaincode's ledger on channel, for states no transaction
+// can reach yet. The case is sealed in a transaction submitted by id, so it verifies
+// when another organization fetches it.
+func (s *Simulator) PutCase(channel string, chaincode string, id mockstub.Identity, c *casemodel.Case) error {
+	stub, err := s.NewTransaction(mockstub.Proposal{Channel: channel, Chaincode: chaincode, Identity: id, Function: "PutCase"})
+	if err != nil {
+		return err
+	}
+	if err := casemodel.Seal(stub.Context(), c); err != nil {
+		return err
+	}
+	value, err := json.Marshal(c)
+	if err != nil {
+		return fmt.Errorf("failed to marshal case: %v", err)
+	}
+	if err := stub.PutState(c.ID, value); err != nil {
+		return err
+	}
+	return s.Commit(stub)
+}
@@ -0,0 +1,441 @@
+package simulator
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"casemodel"
+	"casemodel/contentstore"
+)
+
+func TestMain(m *testing.M) {
+	log.SetOutput(io.Discard)
+	os.Exit(m.Run())
+}
+
+// newSimulator returns a simulator laid out like the Microfab network
+func newSimulator(t *testing.T) *Simulator {
+	t.Helper()
+	topology, err := ReadTopology("../../MICROFAB.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := New(topology, time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func run(t *testing.T, s *Simulator, steps ...Step) []byte {
+	t.Helper()
+	payload, err := s.Run(steps...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return payload
+}
+
+func caseOf(t *testing.T, s *Simulator, channel string, chaincode string, caseID string) *casemodel.Case {
+	t.Helper()
+	c, err := s.Case(channel, chaincode, caseID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestTopology(t *testing.T) {
+	s := newSimulator(t)
+	tests := []struct {
+		org      string
+		channels []string
+	}{
+		{casemodel.OrgLawyers, []string{LawyerRegistrarChannel, StampReporterLawyerChannel, BenchClerkLawyerChannel}},
+		{casemodel.OrgRegistrars, []string{LawyerRegistrarChannel, RegistrarStampReporterChannel}},
+		{casemodel.OrgStampReporters, []string{RegistrarStampReporterChannel, StampReporterLawyerChannel, StampReporterBenchClerkChannel}},
+		{casemodel.OrgBenchClerks, []string{StampReporterBenchClerkChannel, BenchClerkJudgeChannel, BenchClerkLawyerChannel}},
+		{casemodel.OrgJudges, []string{BenchClerkJudgeChannel}},
+	}
+	for _, tt := range tests {
+		if got := s.Topology.ChannelsOf(tt.org); !sameSet(got, tt.channels) {
+			t.Errorf("ChannelsOf(%s) = %v, want %v", tt.org, got, tt.channels)
+		}
+	}
+}
+
+func sameSet(a, b []string) bool {
+	set := make(map[string]int)
+	for _, s := range a {
+		set[s]++
+	}
+	for _, s := range b {
+		set[s]--
+	}
+	for _, n := range set {
+		if n != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func TestParseTopologyUnknownOrganization(t *testing.T) {
+	_, err := ParseTopology([]byte(`{"endorsing_organizations":[{"name":"LawyersOrg"}],"channels":[{"name":"c","endorsing_organizations":["LawyersOrg","JudgesOrg"]}]}`))
+	if err == nil || !strings.Contains(err.Error(), "channel c has unknown organization JudgesOrg") {
+		t.Errorf("err = %v", err)
+	}
+}
+
+func TestRouting(t *testing.T) {
+	tests := []struct {
+		name    string
+		step    Step
+		wantErr string
+	}{
+		{
+			name:    "unknown channel",
+			step:    Step{Name: "submit", Channel: "lawyer-stampreporter-channel", Chaincode: "lawyer", Identity: Lawyer, Function: "GetAllCases"},
+			wantErr: "channel lawyer-stampreporter-channel does not exist",
+		},
+		{
+			name:    "chaincode not installed on channel",
+			step:    Step{Name: "submit", Channel: LawyerRegistrarChannel, Chaincode: "judge", Identity: Lawyer, Function: "GetAllCases"},
+			wantErr: "chaincode judge is not defined on channel lawyer-registrar-channel",
+		},
+		{
+			name:    "client not a member of channel",
+			step:    Step{Name: "submit", Channel: LawyerRegistrarChannel, Chaincode: "lawyer", Identity: Judge, Function: "GetAllCases", Evaluate: true},
+			wantErr: "JudgesOrg is not a member of channel lawyer-registrar-channel",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := newSimulator(t).Run(tt.step)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("err = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDefaultRoutesResolve(t *testing.T) {
+	s := newSimulator(t)
+	orgs := make(map[string]string)
+	for _, d := range deployments {
+		orgs[d.chaincode] = d.org
+	}
+	for hop, route := range casemodel.DefaultRouting().Routes {
+		caller := strings.Split(hop, "->")[0]
+		channel := s.Topology.Channel(route.Channel)
+		if channel == nil {
+			t.Errorf("%s: channel %s does not exist", hop, route.Channel)
+			continue
+		}
+		if !channel.HasMember(orgs[caller]) {
+			t.Errorf("%s: %s is not a member of %s", hop, orgs[caller], route.Channel)
+		}
+		if _, err := s.Installed(route.Chaincode, route.Channel); err != nil {
+			t.Errorf("%s: %v", hop, err)
+		}
+	}
+}
+
+func TestHappyPath(t *testing.T) {
+	s := newSimulator(t)
+	payload := run(t, s, HappyPath("CASE_001")...)
+
+	var judgment map[string]interface{}
+	if err := json.Unmarshal(payload, &judgment); err != nil {
+		t.Fatalf("failed to decode judgment %s: %v", payload, err)
+	}
+	for field, want := range map[string]string{
+		"decision":      "Suit decreed",
+		"judgeId":       "J001",
+		"currentStatus": casemodel.StatusDecisionConfirmed,
+	} {
+		if got := judgment[field]; got != want {
+			t.Errorf("%s = %v, want %q", field, got, want)
+		}
+	}
+
+	tests := []struct {
+		channel   string
+		chaincode string
+		status    string
+	}{
+		{LawyerRegistrarChannel, "registrar", casemodel.StatusTransferredToStampReporter},
+		{StampReporterBenchClerkChannel, "stampreporter", casemodel.StatusForwardedToBenchClerk},
+		{BenchClerkJudgeChannel, "judge", casemodel.StatusJudgmentIssued},
+		{BenchClerkLawyerChannel, "benchclerk", casemodel.StatusDecisionConfirmed},
+		{BenchClerkLawyerChannel, "lawyer", casemodel.StatusDecisionConfirmed},
+	}
+	for _, tt := range tests {
+		if c := caseOf(t, s, tt.channel, tt.chaincode, "CASE_001"); c.Status != tt.status {
+			t.Errorf("%s@%s status = %s, want %s", tt.chaincode, tt.channel, c.Status, tt.status)
+		}
+	}
+
+	// the fees recorded by the stamp reporter travel with the case
+	if c := caseOf(t, s, BenchClerkLawyerChannel, "lawyer", "CASE_001"); c.Fees == nil || c.Fees.Total != 55000 || c.Fees.Balance != 0 {
+		t.Errorf("fees = %+v", c.Fees)
+	}
+
+	// every handoff on the path was delivered, so no outbox holds a pending transfer
+	outboxes := []Step{
+		{Name: "lawyer outbox", Channel: LawyerRegistrarChannel, Chaincode: "lawyer", Identity: Lawyer},
+		{Name: "stamp reporter outbox", Channel: StampReporterBenchClerkChannel, Chaincode: "stampreporter", Identity: StampReporter},
+		{Name: "judge outbox", Channel: BenchClerkJudgeChannel, Chaincode: "judge", Identity: Judge},
+		{Name: "bench clerk outbox", Channel: BenchClerkLawyerChannel, Chaincode: "benchclerk", Identity: BenchClerk},
+	}
+	for _, step := range outboxes {
+		step.Function, step.Evaluate = "ListPendingTransfers", true
+		if payload := run(t, s, step); string(payload) != "[]" {
+			t.Errorf("%s = %s", step.Name, payload)
+		}
+	}
+
+	// the lawyer's final copy carries the history written on every channel, all of it chained
+	payload = run(t, s, Step{
+		Name: "lawyer verifies the case history", Channel: BenchClerkLawyerChannel, Chaincode: "lawyer", Identity: Lawyer,
+		Function: "VerifyCaseHistory", Args: []string{"CASE_001"}, Evaluate: true,
+	})
+	var history casemodel.HistoryVerification
+	if err := json.Unmarshal(payload, &history); err != nil {
+		t.Fatalf("failed to decode history verification %s: %v", payload, err)
+	}
+	if !history.Valid || history.Entries < 5 || history.Chained != history.Entries {
+		t.Errorf("history verification = %+v", history)
+	}
+
+	// the filing's private details stayed off the public case on every channel; the lawyer
+	// holds all of them, and the registrar and the judge each hold their own part
+	for _, tt := range tests {
+		c := caseOf(t, s, tt.channel, tt.chaincode, "CASE_001")
+		if c.UIDParty1 != "" || c.ClientName != "" || c.Description != "" || len(c.PrivateHashes) != 2 {
+			t.Errorf("%s@%s case = %+v", tt.chaincode, tt.channel, c)
+		}
+	}
+	lawyerDetails := run(t, s, Step{
+		Name: "lawyer reads the sealed details", Channel: LawyerRegistrarChannel, Chaincode: "lawyer", Identity: Lawyer,
+		Function: "GetCasePrivateDetails", Args: []string{"CASE_001"}, Evaluate: true,
+	})
+	registrarDetails := run(t, s, Step{
+		Name: "registrar reads the party identities", Channel: LawyerRegistrarChannel, Chaincode: "registrar", Identity: Registrar,
+		Function: "GetCasePrivateDetails", Args: []string{"CASE_001"}, Evaluate: true,
+	})
+	judgeDetails := run(t, s, Step{
+		Name: "judge reads the sealed details", Channel: BenchClerkJudgeChannel, Chaincode: "judge", Identity: Judge,
+		Function: "GetCasePrivateDetails", Args: []string{"CASE_001"}, Evaluate: true,
+	})
+	sealedSalt, partySalt := salts[casemodel.CollectionSealedDetails], salts[casemodel.CollectionPartyIdentities]
+	if string(lawyerDetails) != `{"caseId":"CASE_001","uidParty1":"UID-4471","uidParty2":"UID-9032","clientName":"A. Kumar","description":"Boundary wall encroaching on plot 12","documentHashes":{"DOC_1":"9f2c"},"contentHashes":{"DOC_1":"`+casemodel.ContentHash(Plaint)+`"},"salts":{"partyIdentities":"`+partySalt+`","sealedDetails":"`+sealedSalt+`"}}` {
+		t.Errorf("lawyer's private details = %s", lawyerDetails)
+	}
+	if string(judgeDetails) != `{"caseId":"CASE_001","clientName":"A. Kumar","description":"Boundary wall encroaching on plot 12","documentHashes":{"DOC_1":"9f2c"},"contentHashes":{"DOC_1":"`+casemodel.ContentHash(Plaint)+`"},"salts":{"sealedDetails":"`+sealedSalt+`"}}` {
+		t.Errorf("judge's private details = %s", judgeDetails)
+	}
+	if string(registrarDetails) != `{"caseId":"CASE_001","uidParty1":"UID-4471","uidParty2":"UID-9032","salts":{"partyIdentities":"`+partySalt+`"}}` {
+		t.Errorf("registrar's private details = %s", registrarDetails)
+	}
+
+	// the plaint kept in the content store, fetched by the content hash in the judge's
+	// sealed details, is the file the judge's copy anchors
+	store, err := contentstore.NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Put(bytes.NewReader(Plaint)); err != nil {
+		t.Fatal(err)
+	}
+	var sealed casemodel.CasePrivateDetails
+	if err := json.Unmarshal(judgeDetails, &sealed); err != nil {
+		t.Fatal(err)
+	}
+	if c := caseOf(t, s, BenchClerkJudgeChannel, "judge", "CASE_001"); c.Documents[0].ContentHash != "" {
+		t.Errorf("judge's copy carries the plaint's content hash: %+v", c.Documents[0])
+	}
+	r, err := store.Get(sealed.ContentHashes["DOC_1"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	content, err := io.ReadAll(r)
+	r.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	payload = run(t, s, Step{
+		Name: "judge verifies the plaint", Channel: BenchClerkJudgeChannel, Chaincode: "judge", Identity: Judge,
+		Function: "VerifyDocument", Args: []string{"CASE_001", "DOC_1", casemodel.ContentHash(content)}, Evaluate: true,
+	})
+	var verification casemodel.DocumentVerification
+	if err := json.Unmarshal(payload, &verification); err != nil {
+		t.Fatalf("failed to decode document verification %s: %v", payload, err)
+	}
+	if !verification.Match {
+		t.Errorf("document verification = %+v", verification)
+	}
+}
+
+func TestRegistrarRejection(t *testing.T) {
+	s := newSimulator(t)
+	run(t, s, RegistrarRejection("CASE_002")...)
+
+	c := caseOf(t, s, LawyerRegistrarChannel, "registrar", "CASE_002")
+	if c.Status != casemodel.StatusRejectedByRegistrar || c.CurrentOrg != casemodel.OrgLawyers {
+		t.Errorf("case = %s held by %s", c.Status, c.CurrentOrg)
+	}
+	if lawyerCopy := caseOf(t, s, LawyerRegistrarChannel, "lawyer", "CASE_002"); lawyerCopy.Status != casemodel.StatusRejectedByRegistrar {
+		t.Errorf("lawyer copy = %s", lawyerCopy.Status)
+	}
+
+	_, err := s.Run(Step{
+		Name: "registrar assigns the case", Channel: RegistrarStampReporterChannel, Chaincode: "registrar", Identity: Registrar,
+		Function: "FetchAndStoreCaseFromLawyerChannel", Args: []string{"CASE_002"},
+	})
+	if err == nil || !strings.Contains(err.Error(), "cannot move from REJECTED_BY_REGISTRAR") {
+		t.Errorf("err = %v", err)
+	}
+}
+
+func TestResubmission(t *testing.T) {
+	s := newSimulator(t)
+	run(t, s, Resubmission("CASE_002")...)
+
+	c := caseOf(t, s, LawyerRegistrarChannel, "registrar", "CASE_002")
+	if c.Status != casemodel.StatusVerifiedByRegistrar || c.ResubmissionCount != 1 {
+		t.Errorf("registrar copy = %s after %d resubmissions", c.Status, c.ResubmissionCount)
+	}
+	if len(c.Resubmissions) != 1 {
+		t.Fatalf("resubmissions = %+v", c.Resubmissions)
+	}
+	r := c.Resubmissions[0]
+	if r.RejectionReason != "Vakalatnama missing" || len(r.Changes) == 0 || r.Changes[0].Field != "documents[1]" {
+		t.Errorf("resubmission = %+v", r)
+	}
+
+	// A second resubmission needs another rejection
+	_, err := s.Run(Step{
+		Name: "lawyer resubmits again", Channel: LawyerRegistrarChannel, Chaincode: "lawyer", Identity: Lawyer,
+		Function: "ResubmitToRegistrar", Args: []string{"CASE_002"},
+	})
+	if err == nil || !strings.Contains(err.Error(), "only a case the registrar rejected can be resubmitted") {
+		t.Errorf("err = %v", err)
+	}
+}
+
+func TestReturnedToLawyer(t *testing.T) {
+	reject := StampReporterRejection("CASE_003")
+	rejectStep := reject[len(reject)-2]
+	holdStep := Step{
+		Name: "stamp reporter sends the plaint back for correction", Channel: StampReporterLawyerChannel, Chaincode: "stampreporter", Identity: StampReporter,
+		Function: "ValidateDocuments", Args: []string{"CASE_003", `{"stampReporterId":"SR001","validations":[{"documentId":"DOC_1","verdict":"NEEDS_CORRECTION","reasonCode":"ILLEGIBLE"}]}`},
+	}
+
+	tests := []struct {
+		name    string
+		steps   []Step
+		finally Step
+		want    string
+	}{
+		{
+			name:  "rejection collected by the lawyer",
+			steps: reject[:len(reject)-1],
+			finally: Step{
+				Name: "lawyer collects returned cases", Channel: StampReporterLawyerChannel, Chaincode: "lawyer", Identity: Lawyer,
+				Function: "FetchAndStoreCaseFromStampReporterChannel",
+			},
+			want: casemodel.StatusRejectionReceived,
+		},
+		{
+			name:  "rejection forwarded to the lawyer",
+			steps: append(Registration("CASE_003"), rejectStep),
+			finally: Step{
+				Name: "stamp reporter forwards the case to the lawyer", Channel: StampReporterLawyerChannel, Chaincode: "stampreporter", Identity: StampReporter,
+				Function: "ForwardCaseToLawyer", Args: []string{"CASE_003"},
+			},
+			want: casemodel.StatusForwardedToLawyerRejected,
+		},
+		{
+			name:  "on-hold collected by the lawyer",
+			steps: append(Registration("CASE_003"), holdStep),
+			finally: Step{
+				Name: "lawyer collects returned cases", Channel: StampReporterLawyerChannel, Chaincode: "lawyer", Identity: Lawyer,
+				Function: "FetchAndStoreCaseFromStampReporterChannel",
+			},
+			want: casemodel.StatusOnHoldReceived,
+		},
+		{
+			name:  "on-hold forwarded to the lawyer",
+			steps: append(Registration("CASE_003"), holdStep),
+			finally: Step{
+				Name: "stamp reporter forwards the case to the lawyer", Channel: StampReporterLawyerChannel, Chaincode: "stampreporter", Identity: StampReporter,
+				Function: "ForwardCaseToLawyer", Args: []string{"CASE_003"},
+			},
+			want: casemodel.StatusForwardedToLawyerOnHold,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newSimulator(t)
+			run(t, s, tt.steps...)
+			run(t, s, tt.finally)
+
+			c := caseOf(t, s, StampReporterLawyerChannel, "lawyer", "CASE_003")
+			if c.Status != tt.want || c.CurrentOrg != casemodel.OrgLawyers {
+				t.Errorf("lawyer copy = %s held by %s, want %s", c.Status, c.CurrentOrg, tt.want)
+			}
+			if !reflect.DeepEqual(c.AssociatedLawyers, []string{"L001"}) {
+				t.Errorf("lawyers = %v", c.AssociatedLawyers)
+			}
+			if docs := casemodel.RejectedDocuments(c); len(docs) != 1 || docs[0].ID != "DOC_1" {
+				t.Errorf("returned documents = %+v", docs)
+			}
+		})
+	}
+}
+
+func TestStampReporterResubmission(t *testing.T) {
+	s := newSimulator(t)
+	run(t, s, StampReporterResubmission("CASE_005")...)
+
+	c := caseOf(t, s, StampReporterLawyerChannel, "stampreporter", "CASE_005")
+	if c.Status != casemodel.StatusValidatedByStampReporter {
+		t.Errorf("stamp reporter copy = %s", c.Status)
+	}
+	if doc := c.Document("DOC_1_V2"); doc == nil || !doc.Validated || doc.SupersedesID != "DOC_1" {
+		t.Errorf("documents = %+v", c.Documents)
+	}
+	if old := c.Document("DOC_1"); old.Status != casemodel.DocumentSuperseded || old.Verdict != casemodel.VerdictRejected {
+		t.Errorf("rejected plaint = %+v", old)
+	}
+	if lawyerCopy := caseOf(t, s, StampReporterLawyerChannel, "lawyer", "CASE_005"); lawyerCopy.Status != casemodel.StatusPendingStampReporterReview || len(casemodel.RejectedDocuments(lawyerCopy)) != 0 {
+		t.Errorf("lawyer copy = %s with %+v", lawyerCopy.Status, lawyerCopy.Documents)
+	}
+}
+
+func TestDefectCuring(t *testing.T) {
+	s := newSimulator(t)
+	run(t, s, DefectCuring("CASE_004")...)
+
+	c := caseOf(t, s, StampReporterLawyerChannel, "stampreporter", "CASE_004")
+	if c.Status != casemodel.StatusValidatedByStampReporter {
+		t.Errorf("stamp reporter copy = %s", c.Status)
+	}
+	if len(c.Defects) != 1 || c.Defects[0].Status != casemodel.DefectCured || c.Defects[0].CureDocumentID != "DOC_1_V2" {
+		t.Errorf("defects = %+v", c.Defects)
+	}
+	if doc := c.Document("DOC_1_V2"); doc == nil || !doc.Validated || doc.SupersedesID != "DOC_1" {
+		t.Errorf("documents = %+v", c.Documents)
+	}
+	if lawyerCopy := caseOf(t, s, StampReporterLawyerChannel, "lawyer", "CASE_004"); lawyerCopy.Status != casemodel.StatusPendingStampReporterReview {
+		t.Errorf("lawyer copy = %s", lawyerCopy.Status)
+	}
+}
@@ -0,0 +1,87 @@
+package simulator
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// Topology is the set of organizations and channels of a Microfab network, as
+// configured in MICROFAB.txt
+type Topology struct {
+	Organizations []Organization `json:"endorsing_organizations"`
+	Channels      []Channel      `json:"channels"`
+}
+
+// Organization is an endorsing organization of the network
+type Organization struct {
+	Name string `json:"name"`
+}
+
+// Channel is a channel and the organizations that have joined it
+type Channel struct {
+	Name          string   `json:"name"`
+	Organizations []string `json:"endorsing_organizations"`
+}
+
+// ReadTopology reads a Microfab configuration file
+func ReadTopology(path string) (*Topology, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read topology: %v", err)
+	}
+	return ParseTopology(data)
+}
+
+// ParseTopology parses a Microfab configuration and checks that every channel member is
+// one of its organizations
+func ParseTopology(data []byte) (*Topology, error) {
+	var topology Topology
+	if err := json.Unmarshal(data, &topology); err != nil {
+		return nil, fmt.Errorf("failed to parse topology: %v", err)
+	}
+
+	orgs := make(map[string]bool)
+	for _, org := range topology.Organizations {
+		orgs[org.Name] = true
+	}
+	for _, channel := range topology.Channels {
+		for _, org := range channel.Organizations {
+			if !orgs[org] {
+				return nil, fmt.Errorf("channel %s has unknown organization %s", channel.Name, org)
+			}
+		}
+	}
+	return &topology, nil
+}
+
+// Channel returns the channel with the given name, or nil if there is none
+func (t *Topology) Channel(name string) *Channel {
+	for i := range t.Channels {
+		if t.Channels[i].Name == name {
+			return &t.Channels[i]
+		}
+	}
+	return nil
+}
+
+// ChannelsOf returns the names of the channels org has joined
+func (t *Topology) ChannelsOf(org string) []string {
+	var channels []string
+	for _, channel := range t.Channels {
+		if channel.HasMember(org) {
+			channels = append(channels, channel.Name)
+		}
+	}
+	return channels
+}
+
+// HasMember reports whether org has joined the channel
+func (c *Channel) HasMember(org string) bool {
+	for _, member := range c.Organizations {
+		if member == org {
+			return true
+		}
+	}
+	return false
+}